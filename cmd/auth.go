@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/linuxsuren/api-testing/pkg/oauth"
+	fakeruntime "github.com/linuxsuren/go-fake-runtime"
+	"github.com/spf13/cobra"
+)
+
+type authOption struct {
+	store     string
+	profile   oauth.Profile
+	listen    string
+	noBrowser bool
+	timeout   time.Duration
+	execer    fakeruntime.Execer
+}
+
+func createAuthCmd(execer fakeruntime.Execer) (c *cobra.Command) {
+	opt := &authOption{execer: execer}
+	c = &cobra.Command{
+		Use:   "auth",
+		Short: "Login by the OAuth2 authorization code flow with PKCE, the tokens are cached for the run command",
+	}
+	c.PersistentFlags().StringVarP(&opt.store, "store", "", oauth.DefaultStoreFile(), "The file which caches the tokens")
+
+	login := &cobra.Command{
+		Use:   "login <name>",
+		Short: "Login in the browser, then cache the tokens with the name",
+		Example: `atest auth login dev --issuer https://idp.example.com/realms/test --client-id api-testing --scope 'openid offline_access'
+atest run -p sample.yaml --auth dev`,
+		Args: cobra.ExactArgs(1),
+		RunE: opt.runLogin,
+	}
+	flags := login.Flags()
+	flags.StringVarP(&opt.profile.Issuer, "issuer", "", "", "The issuer to discover the authorization and token endpoints")
+	flags.StringVarP(&opt.profile.AuthURL, "auth-url", "", "", "The authorization endpoint, it's discovered from the issuer if it's empty")
+	flags.StringVarP(&opt.profile.TokenURL, "token-url", "", "", "The token endpoint, it's discovered from the issuer if it's empty")
+	flags.StringVarP(&opt.profile.ClientID, "client-id", "", "", "The client ID")
+	flags.StringVarP(&opt.profile.ClientSecret, "client-secret", "", "", "The client secret, it's optional for the public clients")
+	flags.StringVarP(&opt.profile.Scope, "scope", "", "", "The scope, add offline_access to get the refresh token if the server requires it")
+	flags.StringVarP(&opt.listen, "listen", "", "127.0.0.1:0",
+		"The address of the local callback server, the redirect URI is http://<listen>/callback. It's a random port by default")
+	flags.BoolVarP(&opt.noBrowser, "no-browser", "", false, "Print the authorization URL only instead of opening the browser")
+	flags.DurationVarP(&opt.timeout, "timeout", "", 5*time.Minute, "The timeout of waiting for the login")
+	_ = login.MarkFlagRequired("client-id")
+
+	c.AddCommand(login, &cobra.Command{
+		Use:   "token <name>",
+		Short: "Print the access token, it's refreshed if it's expired",
+		Args:  cobra.ExactArgs(1),
+		RunE:  opt.runToken,
+	}, &cobra.Command{
+		Use:   "logout <name>",
+		Short: "Remove the cached tokens",
+		Args:  cobra.ExactArgs(1),
+		RunE:  opt.runLogout,
+	}, &cobra.Command{
+		Use:   "list",
+		Short: "List the names of the cached tokens",
+		Args:  cobra.NoArgs,
+		RunE:  opt.runList,
+	})
+	return
+}
+
+func (o *authOption) runLogin(cmd *cobra.Command, args []string) (err error) {
+	ctx, cancel := context.WithTimeout(cmd.Context(), o.timeout)
+	defer cancel()
+
+	var token *oauth.Token
+	if token, err = oauth.Login(ctx, &o.profile, o.listen, func(authURL string) error {
+		cmd.Println("open the URL in the browser if it's not opened automatically:")
+		cmd.Println(authURL)
+		if !o.noBrowser {
+			if browserErr := openBrowser(o.execer, authURL); browserErr != nil {
+				cmd.Println("failed to open the browser", browserErr)
+			}
+		}
+		return nil
+	}); err != nil {
+		return
+	}
+
+	if err = oauth.NewStore(o.store).Save(args[0], &oauth.Session{Profile: o.profile, Token: *token}); err == nil {
+		cmd.Printf("login succeeded, use the token by: atest run --auth %s\n", args[0])
+	}
+	return
+}
+
+func (o *authOption) runToken(cmd *cobra.Command, args []string) (err error) {
+	var token *oauth.Token
+	if token, err = oauth.NewStore(o.store).AccessToken(cmd.Context(), args[0]); err == nil {
+		cmd.Println(token.AccessToken)
+	}
+	return
+}
+
+func (o *authOption) runLogout(cmd *cobra.Command, args []string) (err error) {
+	return oauth.NewStore(o.store).Delete(args[0])
+}
+
+func (o *authOption) runList(cmd *cobra.Command, args []string) (err error) {
+	var names []string
+	if names, err = oauth.NewStore(o.store).Names(); err == nil {
+		for _, name := range names {
+			cmd.Println(name)
+		}
+	}
+	return
+}
+
+// openBrowser opens the URL by the default browser of the OS
+func openBrowser(execer fakeruntime.Execer, url string) error {
+	switch execer.OS() {
+	case "darwin":
+		return execer.RunCommand("open", url)
+	case "windows":
+		return execer.RunCommand("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		return execer.RunCommand("xdg-open", url)
+	}
+}
+
+// setAuthContext puts the access token into the context of the templates, e.g. {{.auth.accessToken}}
+func (o *runOption) setAuthContext(ctx context.Context, dataContext map[string]interface{}) (err error) {
+	if o.tokenStore == nil {
+		return
+	}
+
+	var token *oauth.Token
+	if token, err = o.tokenStore.AccessToken(ctx, o.auth); err != nil {
+		return
+	}
+	dataContext["auth"] = map[string]string{
+		"accessToken": token.AccessToken,
+		"tokenType":   token.TokenType,
+		"idToken":     token.IDToken,
+	}
+	return
+}
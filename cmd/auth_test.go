@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/h2non/gock"
+	"github.com/linuxsuren/api-testing/pkg/limit"
+	"github.com/linuxsuren/api-testing/pkg/oauth"
+	fakeruntime "github.com/linuxsuren/go-fake-runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthCmd(t *testing.T) {
+	store := filepath.Join(t.TempDir(), "auth.json")
+	assert.Nil(t, oauth.NewStore(store).Save("dev", &oauth.Session{
+		Token: oauth.Token{AccessToken: "fake", Expiry: time.Now().Add(time.Hour)},
+	}))
+
+	run := func(args ...string) (string, error) {
+		buf := new(bytes.Buffer)
+		root := NewRootCmd(fakeruntime.FakeExecer{}, NewFakeGRPCServer())
+		root.SetOut(buf)
+		root.SetArgs(append([]string{"auth"}, append(args, "--store", store)...))
+		err := root.Execute()
+		return buf.String(), err
+	}
+
+	output, err := run("list")
+	assert.Nil(t, err)
+	assert.Equal(t, "dev\n", output)
+
+	output, err = run("token", "dev")
+	assert.Nil(t, err)
+	assert.Equal(t, "fake\n", output)
+
+	_, err = run("logout", "dev")
+	assert.Nil(t, err)
+
+	_, err = run("token", "dev")
+	assert.EqualError(t, err, `no session named "dev", please login first: atest auth login dev`)
+
+	_, err = run("login", "dev")
+	assert.EqualError(t, err, `required flag(s) "client-id" not set`)
+
+	_, err = run("login", "dev", "--client-id", "atest", "--timeout", "1ms")
+	assert.EqualError(t, err, "the issuer is required if the auth URL or token URL is empty")
+}
+
+func TestRunSuiteWithAuth(t *testing.T) {
+	defer gock.Off()
+
+	dir := t.TempDir()
+	store := oauth.NewStore(filepath.Join(dir, "auth.json"))
+	assert.Nil(t, store.Save("dev", &oauth.Session{
+		Token: oauth.Token{AccessToken: "fake", TokenType: "Bearer"},
+	}))
+	suiteFile := filepath.Join(dir, "suite.yaml")
+	assert.Nil(t, os.WriteFile(suiteFile, []byte(`name: auth
+items:
+- name: me
+  request:
+    api: http://foo/me
+    header:
+      Authorization: "{{.auth.tokenType}} {{.auth.accessToken}}"
+`), 0644))
+
+	gock.New(urlFoo).Get("/me").MatchHeader("Authorization", "^Bearer fake$").Reply(http.StatusOK).JSON("{}")
+
+	opt := newDiskCardRunOption()
+	opt.requestTimeout = 30 * time.Second
+	opt.limiter = limit.NewDefaultRateLimiter(0, 0)
+	opt.auth = "dev"
+	opt.tokenStore = store
+	err := opt.runSuite(suiteFile, getDefaultContext(), context.TODO(), make(chan struct{}, 1))
+	assert.Nil(t, err)
+
+	opt.auth = "fake"
+	err = opt.runSuite(suiteFile, getDefaultContext(), context.TODO(), make(chan struct{}, 1))
+	assert.NotNil(t, err)
+}
+
+func TestOpenBrowser(t *testing.T) {
+	for _, goos := range []string{"darwin", "windows", "linux"} {
+		assert.Nil(t, openBrowser(fakeruntime.FakeExecer{ExpectOS: goos}, "http://foo"))
+	}
+	assert.NotNil(t, openBrowser(fakeruntime.FakeExecer{ExpectError: errors.New("fake")}, "http://foo"))
+}
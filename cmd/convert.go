@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/generator"
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/spf13/cobra"
+)
+
+type convertOption struct {
+	from   string
+	source string
+	output string
+}
+
+func createConvertCmd() (c *cobra.Command) {
+	opt := &convertOption{}
+	c = &cobra.Command{
+		Use:   "convert",
+		Short: "Convert the collections of other tools to be the test suite",
+		Example: `atest convert --from insomnia -p insomnia.json
+atest convert --from thunder -p thunder-collection.json -o suite.yaml
+atest convert --from curl 'curl -X POST https://foo.com/api/users -d "{}"'
+atest convert --from curl -p commands.txt`,
+		PreRunE: opt.preRunE,
+		RunE:    opt.runE,
+	}
+
+	flags := c.Flags()
+	flags.StringVarP(&opt.from, "from", "", "", fmt.Sprintf("The format of the source, supported: %v",
+		generator.GetTestSuiteImporterNames()))
+	flags.StringVarP(&opt.source, "source", "p", "", "The source file path")
+	flags.StringVarP(&opt.output, "output", "o", "", "The output file path, print it if it's empty")
+	_ = c.MarkFlagRequired("from")
+	return
+}
+
+func (o *convertOption) preRunE(cmd *cobra.Command, args []string) (err error) {
+	if o.source == "" && len(args) == 0 {
+		err = fmt.Errorf("the source file or content is required")
+	}
+	return
+}
+
+func (o *convertOption) runE(cmd *cobra.Command, args []string) (err error) {
+	var importer generator.TestSuiteImporter
+	if importer, err = generator.GetTestSuiteImporter(o.from); err != nil {
+		return
+	}
+
+	var data []byte
+	if o.source == "" {
+		// the content could be passed as the arguments, such as a curl command
+		data = []byte(strings.Join(args, " "))
+	} else if data, err = os.ReadFile(o.source); err != nil {
+		return
+	}
+
+	var suite *testing.TestSuite
+	if suite, err = convertWithWarnings(cmd, importer, data); err != nil {
+		return
+	}
+
+	var result string
+	if result, err = generator.ToYAML(suite); err != nil {
+		return
+	}
+
+	if o.output == "" {
+		cmd.Print(result)
+	} else {
+		err = os.WriteFile(o.output, []byte(result), 0644)
+	}
+	return
+}
+
+// convertWithWarnings converts the data, and prints the warnings of the parts which are not converted
+func convertWithWarnings(cmd *cobra.Command, importer generator.TestSuiteImporter, data []byte) (
+	suite *testing.TestSuite, err error) {
+	warner, ok := importer.(generator.TestSuiteImporterWithWarnings)
+	if !ok {
+		suite, err = importer.Convert(data)
+		return
+	}
+
+	var warnings []string
+	if suite, warnings, err = warner.ConvertWithWarnings(data); err == nil {
+		for _, warning := range warnings {
+			cmd.PrintErrln("warning:", warning)
+		}
+	}
+	return
+}
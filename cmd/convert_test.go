@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	fakeruntime "github.com/linuxsuren/go-fake-runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertCmd(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "suite.yaml")
+
+	tests := []struct {
+		name   string
+		args   []string
+		verify func(*testing.T, string, error)
+	}{{
+		name: "print the test suite",
+		args: []string{"--from", "insomnia", "-p", "testdata/insomnia.json"},
+		verify: func(t *testing.T, result string, err error) {
+			assert.Nil(t, err)
+			assert.Contains(t, result, "name: create user")
+		},
+	}, {
+		name: "write to file",
+		args: []string{"--from", "insomnia", "-p", "testdata/insomnia.json", "-o", output},
+		verify: func(t *testing.T, result string, err error) {
+			assert.Nil(t, err)
+			data, err := os.ReadFile(output)
+			assert.Nil(t, err)
+			assert.Contains(t, string(data), "name: login")
+		},
+	}, {
+		name: "curl command from the arguments",
+		args: []string{"--from", "curl", `curl -X POST https://foo.com/users -d "{}"`},
+		verify: func(t *testing.T, result string, err error) {
+			assert.Nil(t, err)
+			assert.Contains(t, result, "name: POST /users")
+		},
+	}, {
+		name: "not supported format",
+		args: []string{"--from", "fake", "-p", "testdata/insomnia.json"},
+		verify: func(t *testing.T, result string, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "source is missing",
+		args: []string{"--from", "insomnia"},
+		verify: func(t *testing.T, result string, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "invalid source",
+		args: []string{"--from", "thunder", "-p", simpleSuite},
+		verify: func(t *testing.T, result string, err error) {
+			assert.NotNil(t, err)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			root := NewRootCmd(fakeruntime.FakeExecer{ExpectOS: "linux"}, NewFakeGRPCServer())
+			root.SetOut(buf)
+			root.SetArgs(append([]string{"convert"}, tt.args...))
+			err := root.Execute()
+			tt.verify(t, buf.String(), err)
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/linuxsuren/api-testing/pkg/limit"
+	"github.com/spf13/cobra"
+)
+
+type ctlOption struct {
+	action string
+	qps    int32
+	burst  int32
+}
+
+func createCtlCmd() (c *cobra.Command) {
+	c = &cobra.Command{
+		Use:   "ctl",
+		Short: "Control a running test which has a run id",
+	}
+
+	for _, action := range []struct {
+		name, short string
+	}{
+		{"pause", "Pause sending the requests of the running test"},
+		{"resume", "Resume sending the requests of the running test"},
+		{"status", "Print the status of the running test"},
+	} {
+		opt := &ctlOption{action: action.name}
+		c.AddCommand(&cobra.Command{
+			Use:     action.name + " <run-id>",
+			Short:   action.short,
+			Example: "atest ctl " + action.name + " 7ffcgd3k",
+			Args:    cobra.ExactArgs(1),
+			RunE:    opt.runE,
+		})
+	}
+
+	opt := &ctlOption{action: "qps"}
+	qpsCmd := &cobra.Command{
+		Use:     "qps <run-id>",
+		Short:   "Change the QPS and burst of the running test",
+		Example: "atest ctl qps 7ffcgd3k --qps 20 --burst 40",
+		Args:    cobra.ExactArgs(1),
+		PreRunE: opt.preRunE,
+		RunE:    opt.runE,
+	}
+	flags := qpsCmd.Flags()
+	flags.Int32VarP(&opt.qps, "qps", "", 0, "The new QPS, keep the current one if it's zero")
+	flags.Int32VarP(&opt.burst, "burst", "", 0, "The new burst, keep the current one if it's zero")
+	c.AddCommand(qpsCmd)
+	return
+}
+
+func (o *ctlOption) preRunE(cmd *cobra.Command, args []string) (err error) {
+	if o.qps < 0 || o.burst < 0 || o.qps == 0 && o.burst == 0 {
+		err = fmt.Errorf("a positive --qps or --burst is required")
+	}
+	return
+}
+
+func (o *ctlOption) runE(cmd *cobra.Command, args []string) (err error) {
+	method := http.MethodPost
+	if o.action == "status" {
+		method = http.MethodGet
+	}
+
+	api := "http://atest/" + o.action
+	if o.action == "qps" {
+		api = fmt.Sprintf("%s?qps=%d&burst=%d", api, o.qps, o.burst)
+	}
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(cmd.Context(), method, api, nil); err != nil {
+		return
+	}
+
+	var resp *http.Response
+	if resp, err = newControlClient(args[0]).Do(req); err != nil {
+		err = fmt.Errorf("failed to connect the run %s, is it still running? %v", args[0], err)
+		return
+	}
+	defer resp.Body.Close()
+
+	var data []byte
+	if data, err = io.ReadAll(resp.Body); err == nil {
+		if resp.StatusCode != http.StatusOK {
+			err = fmt.Errorf("failed to %s the run %s, %s", o.action, args[0], string(data))
+		} else {
+			cmd.Print(string(data))
+		}
+	}
+	return
+}
+
+// controlSocket returns the unix socket path of the run control server
+func controlSocket(runID string) string {
+	return filepath.Join(os.TempDir(), fmt.Sprintf("atest-%s.sock", runID))
+}
+
+func newControlClient(runID string) *http.Client {
+	socket := controlSocket(runID)
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+}
+
+// startControlServer serves the control API of a run through a unix socket
+func startControlServer(runID string, limiter limit.RateLimiter) (server *http.Server, err error) {
+	socket := controlSocket(runID)
+	_ = os.Remove(socket)
+
+	var listener net.Listener
+	if listener, err = net.Listen("unix", socket); err != nil {
+		return
+	}
+
+	server = &http.Server{Handler: newControlHandler(limiter)}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	return
+}
+
+func newControlHandler(limiter limit.RateLimiter) http.Handler {
+	mux := http.NewServeMux()
+	writeStatus := func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"paused": limiter.Paused(),
+			"qps":    limiter.QPS(),
+			"burst":  limiter.Burst(),
+		})
+	}
+	action := func(do func()) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			if req.Method != http.MethodPost {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			do()
+			writeStatus(w)
+		}
+	}
+
+	mux.HandleFunc("/pause", action(limiter.Pause))
+	mux.HandleFunc("/resume", action(limiter.Resume))
+	mux.HandleFunc("/qps", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		query := req.URL.Query()
+		qps, qpsErr := parseNonNegative(query.Get("qps"))
+		burst, burstErr := parseNonNegative(query.Get("burst"))
+		if qpsErr != nil || burstErr != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("the qps and burst should be non-negative integers"))
+			return
+		}
+		limiter.SetQPS(qps, burst)
+		writeStatus(w)
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, req *http.Request) {
+		writeStatus(w)
+	})
+	return mux
+}
+
+func parseNonNegative(text string) (val int32, err error) {
+	if text == "" {
+		return
+	}
+
+	var num int64
+	if num, err = strconv.ParseInt(text, 10, 32); err == nil && num < 0 {
+		err = fmt.Errorf("%d is negative", num)
+	}
+	val = int32(num)
+	return
+}
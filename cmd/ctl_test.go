@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/linuxsuren/api-testing/pkg/limit"
+	fakeruntime "github.com/linuxsuren/go-fake-runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCtlCmd(t *testing.T) {
+	limiter := limit.NewDefaultRateLimiter(0, 0)
+	defer limiter.Stop()
+
+	server, err := startControlServer("ctl-test", limiter)
+	assert.Nil(t, err)
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		args   []string
+		verify func(*testing.T, string, error)
+	}{{
+		name: "pause",
+		args: []string{"pause", "ctl-test"},
+		verify: func(t *testing.T, output string, err error) {
+			assert.Nil(t, err)
+			assert.Contains(t, output, `"paused":true`)
+			assert.True(t, limiter.Paused())
+		},
+	}, {
+		name: "status",
+		args: []string{"status", "ctl-test"},
+		verify: func(t *testing.T, output string, err error) {
+			assert.Nil(t, err)
+			assert.Contains(t, output, `"paused":true`)
+		},
+	}, {
+		name: "resume",
+		args: []string{"resume", "ctl-test"},
+		verify: func(t *testing.T, output string, err error) {
+			assert.Nil(t, err)
+			assert.Contains(t, output, `"paused":false`)
+			assert.False(t, limiter.Paused())
+		},
+	}, {
+		name: "change the qps",
+		args: []string{"qps", "ctl-test", "--qps", "20"},
+		verify: func(t *testing.T, output string, err error) {
+			assert.Nil(t, err)
+			assert.Contains(t, output, `"qps":20`)
+			assert.Equal(t, int32(20), limiter.QPS())
+		},
+	}, {
+		name: "change the burst",
+		args: []string{"qps", "ctl-test", "--burst", "100"},
+		verify: func(t *testing.T, output string, err error) {
+			assert.Nil(t, err)
+			assert.Equal(t, int32(20), limiter.QPS())
+			assert.GreaterOrEqual(t, limiter.Burst(), int32(100))
+		},
+	}, {
+		name: "lack of the qps and burst",
+		args: []string{"qps", "ctl-test"},
+		verify: func(t *testing.T, output string, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "not running",
+		args: []string{"pause", "not-exist"},
+		verify: func(t *testing.T, output string, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "lack of the run id",
+		args: []string{"pause"},
+		verify: func(t *testing.T, output string, err error) {
+			assert.NotNil(t, err)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			root := NewRootCmd(fakeruntime.FakeExecer{ExpectOS: "linux"}, NewFakeGRPCServer())
+			root.SetOut(buf)
+			root.SetArgs(append([]string{"ctl"}, tt.args...))
+			err := root.Execute()
+			tt.verify(t, buf.String(), err)
+		})
+	}
+}
+
+func TestControlHandler(t *testing.T) {
+	limiter := limit.NewDefaultRateLimiter(1, 1)
+	defer limiter.Stop()
+	handler := newControlHandler(limiter)
+
+	tests := []struct {
+		method string
+		target string
+		status int
+	}{
+		{http.MethodGet, "/pause", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/qps?qps=1", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/qps?qps=-1", http.StatusBadRequest},
+		{http.MethodPost, "/qps?burst=fake", http.StatusBadRequest},
+		{http.MethodPost, "/qps?qps=3", http.StatusOK},
+		{http.MethodGet, "/status", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.target, nil))
+			assert.Equal(t, tt.status, recorder.Code)
+		})
+	}
+	assert.Equal(t, int32(3), limiter.QPS())
+}
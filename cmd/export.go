@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/linuxsuren/api-testing/pkg/generator"
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/spf13/cobra"
+)
+
+type exportOption struct {
+	format string
+	suite  string
+	output string
+}
+
+func createExportCmd() (c *cobra.Command) {
+	opt := &exportOption{}
+	c = &cobra.Command{
+		Use:   "export",
+		Short: "Export the test suite to be other formats",
+		Example: `atest export --format postman -p sample.yaml -o collection.json
+atest export --format postman-env -p sample.yaml -o environment.json
+atest export --format k6 -p sample.yaml -o script.js
+atest export --format helm-test -p sample.yaml -o chart/templates/tests/atest.yaml`,
+		RunE: opt.runE,
+	}
+
+	flags := c.Flags()
+	flags.StringVarP(&opt.format, "format", "", "", fmt.Sprintf("The target format, supported: %v",
+		generator.GetTestSuiteConverterNames()))
+	flags.StringVarP(&opt.suite, "pattern", "p", "", "The test suite file path")
+	flags.StringVarP(&opt.output, "output", "o", "", "The output file path, print it if it's empty")
+	_ = c.MarkFlagRequired("format")
+	_ = c.MarkFlagRequired("pattern")
+	return
+}
+
+func (o *exportOption) runE(cmd *cobra.Command, args []string) (err error) {
+	var converter generator.TestSuiteConverter
+	if converter, err = generator.GetTestSuiteConverter(o.format); err != nil {
+		return
+	}
+
+	var suite *testing.TestSuite
+	if suite, err = testing.Parse(o.suite); err != nil {
+		return
+	}
+
+	var result string
+	if result, err = converter.Convert(suite); err != nil {
+		return
+	}
+
+	if o.output == "" {
+		cmd.Println(result)
+	} else {
+		err = os.WriteFile(o.output, []byte(result), 0644)
+	}
+	return
+}
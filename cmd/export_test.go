@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	fakeruntime "github.com/linuxsuren/go-fake-runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportCmd(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "collection.json")
+
+	tests := []struct {
+		name   string
+		args   []string
+		verify func(*testing.T, string, error)
+	}{{
+		name: "postman collection",
+		args: []string{"--format", "postman", "-p", "testdata/param-suite.yaml"},
+		verify: func(t *testing.T, result string, err error) {
+			assert.Nil(t, err)
+			assert.Contains(t, result, `"raw": "{{server}}/bar"`)
+		},
+	}, {
+		name: "postman environment to file",
+		args: []string{"--format", "postman-env", "-p", "testdata/param-suite.yaml", "-o", output},
+		verify: func(t *testing.T, result string, err error) {
+			assert.Nil(t, err)
+			data, err := os.ReadFile(output)
+			assert.Nil(t, err)
+			assert.Contains(t, string(data), `"key": "server"`)
+		},
+	}, {
+		name: "k6 script",
+		args: []string{"--format", "k6", "-p", "testdata/param-suite.yaml"},
+		verify: func(t *testing.T, result string, err error) {
+			assert.Nil(t, err)
+			assert.Contains(t, result, `import http from 'k6/http';`)
+		},
+	}, {
+		name: "helm test hook",
+		args: []string{"--format", "helm-test", "-p", "testdata/param-suite.yaml"},
+		verify: func(t *testing.T, result string, err error) {
+			assert.Nil(t, err)
+			assert.Contains(t, result, `helm.sh/hook: test`)
+		},
+	}, {
+		name: "not supported format",
+		args: []string{"--format", "fake", "-p", simpleSuite},
+		verify: func(t *testing.T, result string, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "invalid test suite",
+		args: []string{"--format", "postman", "-p", "testdata/invalid-schema.yaml"},
+		verify: func(t *testing.T, result string, err error) {
+			assert.NotNil(t, err)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			root := NewRootCmd(fakeruntime.FakeExecer{ExpectOS: "linux"}, NewFakeGRPCServer())
+			root.SetOut(buf)
+			root.SetArgs(append([]string{"export"}, tt.args...))
+			err := root.Execute()
+			tt.verify(t, buf.String(), err)
+		})
+	}
+}
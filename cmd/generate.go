@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/generator"
+	"github.com/linuxsuren/api-testing/pkg/runner"
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/spf13/cobra"
+)
+
+func createGenerateCmd() (c *cobra.Command) {
+	c = &cobra.Command{
+		Use:   "generate",
+		Short: "Generate the test cases from the existing ones",
+	}
+	c.AddCommand(createGenerateNegativesCmd(), createGenerateOIDCCmd())
+	return
+}
+
+type generateNegativesOption struct {
+	suite   string
+	output  string
+	openAPI string
+}
+
+func createGenerateNegativesCmd() (c *cobra.Command) {
+	opt := &generateNegativesOption{}
+	c = &cobra.Command{
+		Use:   "negatives",
+		Short: "Generate the negative test cases from the positive ones",
+		Long: `Generate the negative test cases from the positive ones, such as:
+missing fields, wrong types, oversized strings of the JSON body, and invalid auth.
+Only the required fields are missing if the request schema is found in the OpenAPI spec`,
+		Example: `atest generate negatives -p sample.yaml -o sample-negatives.yaml`,
+		RunE:    opt.runE,
+	}
+
+	flags := c.Flags()
+	flags.StringVarP(&opt.suite, "pattern", "p", "", "The test suite file path")
+	flags.StringVarP(&opt.output, "output", "o", "", "The output file path, print it if it's empty")
+	flags.StringVarP(&opt.openAPI, "openapi", "", "", "The OpenAPI 3 (or Swagger 2) spec file which has the request schemas")
+	_ = c.MarkFlagRequired("pattern")
+	return
+}
+
+func (o *generateNegativesOption) runE(cmd *cobra.Command, args []string) (err error) {
+	var suite *testing.TestSuite
+	if suite, err = testing.Parse(o.suite); err != nil {
+		return
+	}
+
+	var requestSchema generator.RequestSchemaFunc
+	if o.openAPI != "" {
+		var spec *runner.OpenAPISpec
+		if spec, err = runner.LoadOpenAPISpec(o.openAPI); err != nil {
+			return
+		}
+		requestSchema = openAPIRequestSchema(spec, suite.API)
+	}
+
+	var result string
+	if result, err = generator.ToYAML(generator.GenerateNegativeCases(suite, requestSchema)); err != nil {
+		return
+	}
+
+	if o.output == "" {
+		cmd.Print(result)
+	} else {
+		err = os.WriteFile(o.output, []byte(result), 0644)
+	}
+	return
+}
+
+// openAPIRequestSchema finds the request schema of the test case by its path under the API of the suite
+func openAPIRequestSchema(spec *runner.OpenAPISpec, suiteAPI string) generator.RequestSchemaFunc {
+	return func(testCase testing.TestCase) string {
+		api := runner.JoinAPI(suiteAPI, testCase.Request.API)
+		if suiteAPI == "" || !strings.HasPrefix(api, suiteAPI) {
+			return ""
+		}
+
+		method := testCase.Request.Method
+		if method == "" {
+			method = http.MethodGet
+		}
+		// the path of a unix socket API is separated by a colon
+		return spec.RequestSchema(method, strings.TrimPrefix(strings.TrimPrefix(api, suiteAPI), ":"))
+	}
+}
+
+type generateOIDCOption struct {
+	generator.OIDCSuiteOptions
+	output string
+}
+
+func createGenerateOIDCCmd() (c *cobra.Command) {
+	opt := &generateOIDCOption{}
+	c = &cobra.Command{
+		Use:   "oidc",
+		Short: "Generate the conformance test suite of an OpenID Connect issuer",
+		Long: `Generate the conformance test suite of an OpenID Connect issuer, it exercises the discovery,
+JWKS, token and userinfo endpoints. The client secret and password are read from the environment
+variables OIDC_CLIENT_SECRET and OIDC_PASSWORD when the suite runs`,
+		Example: `atest generate oidc --issuer https://idp.example.com/realms/test --client-id api-testing -o oidc.yaml`,
+		RunE:    opt.runE,
+	}
+
+	flags := c.Flags()
+	flags.StringVarP(&opt.Issuer, "issuer", "", "", "The issuer of the OpenID Connect provider")
+	flags.StringVarP(&opt.ClientID, "client-id", "", "api-testing", "The client ID of the token requests")
+	flags.StringVarP(&opt.Username, "username", "", "", "The user of the password grant, the userinfo endpoint is verified with its token")
+	flags.StringVarP(&opt.output, "output", "o", "", "The output file path, print it if it's empty")
+	_ = c.MarkFlagRequired("issuer")
+	return
+}
+
+func (o *generateOIDCOption) runE(cmd *cobra.Command, args []string) (err error) {
+	var result string
+	if result, err = generator.ToYAML(generator.GenerateOIDCSuite(o.OIDCSuiteOptions)); err != nil {
+		return
+	}
+
+	if o.output == "" {
+		cmd.Print(result)
+	} else {
+		err = os.WriteFile(o.output, []byte(result), 0644)
+	}
+	return
+}
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/linuxsuren/api-testing/pkg/limit"
+	fakeruntime "github.com/linuxsuren/go-fake-runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateNegativesCmd(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "negatives.yaml")
+
+	tests := []struct {
+		name   string
+		args   []string
+		verify func(*testing.T, string, error)
+	}{{
+		name: "print the negative cases",
+		args: []string{"-p", "testdata/body-suite.yaml"},
+		verify: func(t *testing.T, result string, err error) {
+			assert.Nil(t, err)
+			assert.Contains(t, result, "name: create-missing-name")
+			assert.Contains(t, result, "name: create-invalid-auth")
+		},
+	}, {
+		name: "write to file",
+		args: []string{"-p", "testdata/body-suite.yaml", "-o", output},
+		verify: func(t *testing.T, result string, err error) {
+			assert.Nil(t, err)
+			data, err := os.ReadFile(output)
+			assert.Nil(t, err)
+			assert.Contains(t, string(data), "name: create-wrong-type-name")
+		},
+	}, {
+		name: "only the required fields are missing",
+		args: []string{"-p", "testdata/body-suite.yaml", "--openapi", "testdata/users-openapi.yaml"},
+		verify: func(t *testing.T, result string, err error) {
+			assert.Nil(t, err)
+			assert.NotContains(t, result, "name: create-missing-name")
+			assert.Contains(t, result, "name: create-wrong-type-name")
+		},
+	}, {
+		name: "OpenAPI spec not found",
+		args: []string{"-p", "testdata/body-suite.yaml", "--openapi", "testdata/fake.yaml"},
+		verify: func(t *testing.T, result string, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "invalid test suite",
+		args: []string{"-p", "testdata/invalid-schema.yaml"},
+		verify: func(t *testing.T, result string, err error) {
+			assert.NotNil(t, err)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			root := NewRootCmd(fakeruntime.FakeExecer{ExpectOS: "linux"}, NewFakeGRPCServer())
+			root.SetOut(buf)
+			root.SetArgs(append([]string{"generate", "negatives"}, tt.args...))
+			err := root.Execute()
+			tt.verify(t, buf.String(), err)
+		})
+	}
+}
+
+func TestGenerateOIDCCmd(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if !assert.Nil(t, err) {
+		return
+	}
+	signJWT := func(claims map[string]interface{}) string {
+		claimsData, _ := json.Marshal(claims)
+		input := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","kid":"test"}`)) + "." +
+			base64.RawURLEncoding.EncodeToString(claimsData)
+		hashed := sha256.Sum256([]byte(input))
+		signature, _ := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+		return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+	}
+
+	var issuer string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                issuer,
+			"authorization_endpoint":                issuer + "/auth",
+			"token_endpoint":                        issuer + "/token",
+			"userinfo_endpoint":                     issuer + "/userinfo",
+			"jwks_uri":                              issuer + "/jwks",
+			"response_types_supported":              []string{"code", "id_token"},
+			"subject_types_supported":               []string{"public"},
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, req *http.Request) {
+		clientID, clientSecret, _ := req.BasicAuth()
+		if clientID != "api-testing" || clientSecret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+			return
+		}
+
+		exp := time.Now().Add(time.Hour).Unix()
+		token := map[string]interface{}{
+			"access_token": signJWT(map[string]interface{}{"iss": issuer, "sub": req.FormValue("username"), "exp": exp}),
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		}
+		if req.FormValue("grant_type") == "password" && req.FormValue("password") == "pass" {
+			token["id_token"] = signJWT(map[string]interface{}{
+				"iss": issuer, "sub": req.FormValue("username"), "aud": clientID, "exp": exp,
+			})
+		}
+		_ = json.NewEncoder(w).Encode(token)
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, req *http.Request) {
+		if !strings.HasPrefix(req.Header.Get("Authorization"), "Bearer ey") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"sub": "rick"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	issuer = server.URL
+
+	t.Setenv("OIDC_CLIENT_SECRET", "secret")
+	t.Setenv("OIDC_PASSWORD", "pass")
+	suiteFile := filepath.Join(t.TempDir(), "oidc.yaml")
+
+	root := NewRootCmd(fakeruntime.FakeExecer{ExpectOS: "linux"}, NewFakeGRPCServer())
+	root.SetArgs([]string{"generate", "oidc", "--issuer", issuer, "--username", "rick", "-o", suiteFile})
+	if !assert.Nil(t, root.Execute()) {
+		return
+	}
+	data, err := os.ReadFile(suiteFile)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "name: userinfo")
+
+	opt := newDiskCardRunOption()
+	opt.requestTimeout = 30 * time.Second
+	opt.limiter = limit.NewDefaultRateLimiter(0, 0)
+	assert.Nil(t, opt.runSuite(suiteFile, getDefaultContext(), context.TODO(), make(chan struct{}, 1)))
+
+	buf := new(bytes.Buffer)
+	root = NewRootCmd(fakeruntime.FakeExecer{ExpectOS: "linux"}, NewFakeGRPCServer())
+	root.SetOut(buf)
+	root.SetArgs([]string{"generate", "oidc", "--issuer", issuer})
+	assert.Nil(t, root.Execute())
+	assert.Contains(t, buf.String(), "grantType: client_credentials")
+	assert.NotContains(t, buf.String(), "userinfo")
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/linuxsuren/api-testing/pkg/generator"
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/spf13/cobra"
+)
+
+type graphOption struct {
+	suite  string
+	format string
+	output string
+}
+
+func createGraphCmd() (c *cobra.Command) {
+	opt := &graphOption{}
+	c = &cobra.Command{
+		Use:   "graph",
+		Short: "Print the data-flow dependencies graph of the test cases",
+		Example: `atest graph -p sample.yaml --format dot | dot -Tsvg -o graph.svg
+atest graph -p sample.yaml --format mermaid`,
+		RunE: opt.runE,
+	}
+
+	flags := c.Flags()
+	flags.StringVarP(&opt.suite, "pattern", "p", "", "The test suite file path")
+	flags.StringVarP(&opt.format, "format", "", "dot", "The graph format, supported: dot, mermaid")
+	flags.StringVarP(&opt.output, "output", "o", "", "The output file path, print it if it's empty")
+	_ = c.MarkFlagRequired("pattern")
+	return
+}
+
+func (o *graphOption) runE(cmd *cobra.Command, args []string) (err error) {
+	var suite *testing.TestSuite
+	if suite, err = testing.Parse(o.suite); err != nil {
+		return
+	}
+
+	var result string
+	switch o.format {
+	case "dot":
+		result = generator.ToDotGraph(suite)
+	case "mermaid":
+		result = generator.ToMermaidGraph(suite)
+	default:
+		err = fmt.Errorf("not supported graph format: '%s'", o.format)
+		return
+	}
+
+	if o.output == "" {
+		cmd.Print(result)
+	} else {
+		err = os.WriteFile(o.output, []byte(result), 0644)
+	}
+	return
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	fakeruntime "github.com/linuxsuren/go-fake-runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraphCmd(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "graph.dot")
+
+	tests := []struct {
+		name   string
+		args   []string
+		verify func(*testing.T, string, error)
+	}{{
+		name: "dot format",
+		args: []string{"-p", simpleSuite},
+		verify: func(t *testing.T, result string, err error) {
+			assert.Nil(t, err)
+			assert.Contains(t, result, `digraph "Simple" {`)
+		},
+	}, {
+		name: "mermaid format to file",
+		args: []string{"-p", simpleSuite, "--format", "mermaid", "-o", output},
+		verify: func(t *testing.T, result string, err error) {
+			assert.Nil(t, err)
+			data, err := os.ReadFile(output)
+			assert.Nil(t, err)
+			assert.Contains(t, string(data), `case0["bar"]`)
+		},
+	}, {
+		name: "not supported format",
+		args: []string{"-p", simpleSuite, "--format", "fake"},
+		verify: func(t *testing.T, result string, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "invalid test suite",
+		args: []string{"-p", "testdata/invalid-schema.yaml"},
+		verify: func(t *testing.T, result string, err error) {
+			assert.NotNil(t, err)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			root := NewRootCmd(fakeruntime.FakeExecer{ExpectOS: "linux"}, NewFakeGRPCServer())
+			root.SetOut(buf)
+			root.SetArgs(append([]string{"graph"}, tt.args...))
+			err := root.Execute()
+			tt.verify(t, buf.String(), err)
+		})
+	}
+}
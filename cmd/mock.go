@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/linuxsuren/api-testing/pkg/mock"
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/spf13/cobra"
+)
+
+type mockOption struct {
+	pattern string
+	openAPI string
+	port    int
+}
+
+func createMockCmd() (c *cobra.Command) {
+	opt := &mockOption{}
+	c = &cobra.Command{
+		Use:   "mock",
+		Short: "Run a mock server which serves the expected responses of the test cases",
+		Long: `Run a mock server which serves the expected responses of the test cases.
+The requests which don't match any test cases will be served with the examples of the OpenAPI spec if it's provided.`,
+		Example: `atest mock -p sample.yaml
+atest mock -p sample.yaml --openapi swagger.json --port 8080`,
+		RunE: opt.runE,
+	}
+
+	flags := c.Flags()
+	flags.StringVarP(&opt.pattern, "pattern", "p", "test-suite-*.yaml",
+		"The file pattern of the test suites")
+	flags.StringVarP(&opt.openAPI, "openapi", "", "",
+		"The OpenAPI (or Swagger) spec file, its examples will be served when no test case matches")
+	flags.IntVarP(&opt.port, "port", "", 6060, "The port of the mock server")
+	return
+}
+
+func (o *mockOption) runE(cmd *cobra.Command, args []string) (err error) {
+	var handler http.Handler
+	if handler, err = o.getHandler(); err != nil {
+		return
+	}
+
+	address := fmt.Sprintf(":%d", o.port)
+	cmd.Println("mock server listening at", address)
+	err = http.ListenAndServe(address, handler)
+	return
+}
+
+func (o *mockOption) getHandler() (handler http.Handler, err error) {
+	var fallback http.Handler
+	if o.openAPI != "" {
+		var data []byte
+		if data, err = os.ReadFile(o.openAPI); err != nil {
+			return
+		}
+
+		if fallback, err = mock.NewOpenAPIHandler(data); err != nil {
+			err = fmt.Errorf("failed to parse the OpenAPI spec '%s', %v", o.openAPI, err)
+			return
+		}
+	}
+
+	var files []string
+	if files, err = filepath.Glob(o.pattern); err != nil {
+		return
+	}
+
+	var suites []*testing.TestSuite
+	for _, file := range files {
+		var suite *testing.TestSuite
+		if suite, err = testing.Parse(file); err != nil {
+			return
+		}
+		suites = append(suites, suite)
+	}
+	handler, err = mock.NewSuiteHandler(suites, fallback)
+	return
+}
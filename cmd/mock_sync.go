@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/generator"
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/spf13/cobra"
+)
+
+type mockSyncOption struct {
+	pattern string
+	server  string
+	reset   bool
+	dryRun  bool
+}
+
+func createMockSyncCmd() (c *cobra.Command) {
+	opt := &mockSyncOption{}
+	c = &cobra.Command{
+		Use:   "mock-sync",
+		Short: "Convert the expect of test cases into WireMock mappings, and push them to a WireMock server",
+		Example: `atest mock-sync -p sample.yaml --server http://localhost:8080
+atest mock-sync -p sample.yaml --dry-run`,
+		RunE: opt.runE,
+	}
+
+	flags := c.Flags()
+	flags.StringVarP(&opt.pattern, "pattern", "p", "test-suite-*.yaml",
+		"The file pattern of the test suites")
+	flags.StringVarP(&opt.server, "server", "", "http://localhost:8080", "The address of the WireMock server")
+	flags.BoolVarP(&opt.reset, "reset", "", false, "Remove all the existing mappings before pushing")
+	flags.BoolVarP(&opt.dryRun, "dry-run", "", false, "Print the mappings instead of pushing them")
+	return
+}
+
+func (o *mockSyncOption) runE(cmd *cobra.Command, args []string) (err error) {
+	var files []string
+	if files, err = filepath.Glob(o.pattern); err != nil {
+		return
+	}
+
+	var converter generator.TestSuiteConverter
+	if converter, err = generator.GetTestSuiteConverter("wiremock"); err != nil {
+		return
+	}
+
+	server := strings.TrimSuffix(o.server, "/")
+	if o.reset && !o.dryRun {
+		if err = postToWireMock(server+"/__admin/mappings/reset", ""); err != nil {
+			return
+		}
+	}
+
+	for _, file := range files {
+		var suite *testing.TestSuite
+		if suite, err = testing.Parse(file); err != nil {
+			return
+		}
+
+		var mappings string
+		if mappings, err = converter.Convert(suite); err != nil {
+			return
+		}
+
+		if o.dryRun {
+			cmd.Println(mappings)
+			continue
+		}
+
+		if err = postToWireMock(server+"/__admin/mappings/import", mappings); err != nil {
+			err = fmt.Errorf("failed to push the mappings of '%s', %v", file, err)
+			return
+		}
+		cmd.Printf("pushed %d mappings from '%s'\n", len(suite.Items), file)
+	}
+	return
+}
+
+func postToWireMock(api, body string) (err error) {
+	var resp *http.Response
+	if resp, err = http.Post(api, "application/json", strings.NewReader(body)); err == nil {
+		defer resp.Body.Close()
+		if resp.StatusCode >= http.StatusBadRequest {
+			data, _ := io.ReadAll(resp.Body)
+			err = fmt.Errorf("unexpected status code %d, %s", resp.StatusCode, string(data))
+		}
+	}
+	return
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/h2non/gock"
+	"github.com/linuxsuren/api-testing/pkg/util"
+	fakeruntime "github.com/linuxsuren/go-fake-runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMockSyncCmd(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		prepare func()
+		verify  func(*testing.T, string, error)
+	}{{
+		name: "dry run",
+		args: []string{"-p", simpleSuite, "--dry-run"},
+		verify: func(t *testing.T, output string, err error) {
+			assert.Nil(t, err)
+			assert.Contains(t, output, `"urlPath": "/bar"`)
+		},
+	}, {
+		name: "push to the server",
+		args: []string{"-p", simpleSuite, "--server", urlWireMock, "--reset"},
+		prepare: func() {
+			gock.New(urlWireMock).Post("/__admin/mappings/reset").Reply(http.StatusOK)
+			gock.New(urlWireMock).Post("/__admin/mappings/import").Reply(http.StatusOK)
+		},
+		verify: func(t *testing.T, output string, err error) {
+			assert.Nil(t, err)
+			assert.Contains(t, output, "pushed 1 mappings")
+		},
+	}, {
+		name: "server error",
+		args: []string{"-p", simpleSuite, "--server", urlWireMock},
+		prepare: func() {
+			gock.New(urlWireMock).Post("/__admin/mappings/import").Reply(http.StatusBadRequest)
+		},
+		verify: func(t *testing.T, output string, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "invalid test suite",
+		args: []string{"-p", "testdata/invalid-schema.yaml", "--dry-run"},
+		verify: func(t *testing.T, output string, err error) {
+			assert.NotNil(t, err)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer gock.Clean()
+			util.MakeSureNotNil(tt.prepare)()
+
+			buf := new(bytes.Buffer)
+			root := NewRootCmd(fakeruntime.FakeExecer{ExpectOS: "linux"}, NewFakeGRPCServer())
+			root.SetOut(buf)
+			root.SetArgs(append([]string{"mock-sync"}, tt.args...))
+			err := root.Execute()
+			tt.verify(t, buf.String(), err)
+		})
+	}
+}
+
+const urlWireMock = "http://wiremock"
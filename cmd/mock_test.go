@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMockHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		opt    *mockOption
+		verify func(*testing.T, http.Handler, error)
+	}{{
+		name: "test suite only",
+		opt:  &mockOption{pattern: simpleSuite},
+		verify: func(t *testing.T, handler http.Handler, err error) {
+			assert.Nil(t, err)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/fake", nil))
+			assert.Equal(t, http.StatusNotFound, recorder.Code)
+		},
+	}, {
+		name: "with OpenAPI spec",
+		opt:  &mockOption{pattern: simpleSuite, openAPI: "testdata/openapi.yaml"},
+		verify: func(t *testing.T, handler http.Handler, err error) {
+			assert.Nil(t, err)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/version", nil))
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.JSONEq(t, `{"version":"v0.0.1"}`, recorder.Body.String())
+		},
+	}, {
+		name: "OpenAPI spec not found",
+		opt:  &mockOption{pattern: simpleSuite, openAPI: "testdata/fake.yaml"},
+		verify: func(t *testing.T, handler http.Handler, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "invalid OpenAPI spec",
+		opt:  &mockOption{pattern: simpleSuite, openAPI: simpleSuite},
+		verify: func(t *testing.T, handler http.Handler, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "invalid test suite",
+		opt:  &mockOption{pattern: "testdata/invalid-schema.yaml"},
+		verify: func(t *testing.T, handler http.Handler, err error) {
+			assert.NotNil(t, err)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, err := tt.opt.getHandler()
+			tt.verify(t, handler, err)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/linuxsuren/api-testing/pkg/oci"
+	"github.com/spf13/cobra"
+)
+
+type pkgOption struct {
+	username  string
+	password  string
+	plainHTTP bool
+	output    string
+}
+
+func createPkgCmd() (c *cobra.Command) {
+	opt := &pkgOption{}
+	c = &cobra.Command{
+		Use:   "pkg",
+		Short: "Push or pull the packages of the test suites against an OCI registry",
+	}
+
+	push := &cobra.Command{
+		Use:   "push <dir> <reference>",
+		Short: "Package the directory of the test suites, then push it to the registry",
+		Long: `Package the directory of the test suites, then push it to the registry.
+The metadata file ` + oci.MetadataFile + ` is required in the directory, such as:
+
+name: keycloak-smoke
+version: v1.0.0
+description: The smoke tests of Keycloak
+suites:
+  - login.yaml`,
+		Example: `atest pkg push ./keycloak ghcr.io/linuxsuren/keycloak-smoke:v1.0.0
+atest pkg push ./keycloak localhost:5000/keycloak-smoke --plain-http`,
+		Args: cobra.ExactArgs(2),
+		RunE: opt.runPush,
+	}
+	pull := &cobra.Command{
+		Use:   "pull <reference>",
+		Short: "Pull the package of the test suites from the registry, then extract it",
+		Example: `atest pkg pull ghcr.io/linuxsuren/keycloak-smoke:v1.0.0
+atest pkg pull ghcr.io/linuxsuren/keycloak-smoke:v1.0.0 -o suites/keycloak`,
+		Args: cobra.ExactArgs(1),
+		RunE: opt.runPull,
+	}
+	pull.Flags().StringVarP(&opt.output, "output", "o", "", "The directory to extract the package, default is the name of the package")
+
+	for _, cmd := range []*cobra.Command{push, pull} {
+		flags := cmd.Flags()
+		flags.StringVarP(&opt.username, "username", "u", os.Getenv("ATEST_REGISTRY_USERNAME"),
+			"The username of the registry, default is $ATEST_REGISTRY_USERNAME")
+		flags.StringVarP(&opt.password, "password", "", os.Getenv("ATEST_REGISTRY_PASSWORD"),
+			"The password or token of the registry, default is $ATEST_REGISTRY_PASSWORD")
+		flags.BoolVarP(&opt.plainHTTP, "plain-http", "", false, "Talk to the registry over HTTP instead of HTTPS")
+		c.AddCommand(cmd)
+	}
+	return
+}
+
+func (o *pkgOption) runPush(cmd *cobra.Command, args []string) (err error) {
+	var ref oci.Reference
+	if ref, err = oci.ParseReference(args[1]); err != nil {
+		return
+	}
+
+	var data []byte
+	var metadata *oci.Metadata
+	if data, metadata, err = oci.Pack(args[0]); err != nil {
+		return
+	}
+
+	var digest string
+	if digest, err = oci.NewClient(o.username, o.password, o.plainHTTP).Push(ref, data, metadata); err == nil {
+		cmd.Printf("pushed %s with %d suites, digest: %s\n", ref, len(metadata.Suites), digest)
+	}
+	return
+}
+
+func (o *pkgOption) runPull(cmd *cobra.Command, args []string) (err error) {
+	var ref oci.Reference
+	if ref, err = oci.ParseReference(args[0]); err != nil {
+		return
+	}
+
+	var data []byte
+	var metadata *oci.Metadata
+	if data, metadata, err = oci.NewClient(o.username, o.password, o.plainHTTP).Pull(ref); err != nil {
+		return
+	}
+
+	output := o.output
+	if output == "" {
+		// the name comes from the registry, so it's not allowed to be a path
+		if output = filepath.Base(metadata.Name); output != metadata.Name || output == "." || output == ".." {
+			err = fmt.Errorf("invalid package name %q, please set the output directory", metadata.Name)
+			return
+		}
+	}
+	if err = oci.Unpack(data, output); err == nil {
+		cmd.Printf("pulled %s into %s, suites: %v\n", ref, output, metadata.Suites)
+	}
+	return
+}
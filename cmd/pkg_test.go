@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	fakeruntime "github.com/linuxsuren/go-fake-runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPkgCmd(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		expect string
+	}{{
+		name:   "invalid reference",
+		args:   []string{"pkg", "push", "testdata", "keycloak-smoke"},
+		expect: "the registry is required",
+	}, {
+		name:   "no metadata",
+		args:   []string{"pkg", "push", "testdata", "localhost:5000/keycloak-smoke"},
+		expect: "atest-pkg.yaml",
+	}, {
+		name:   "invalid reference to pull",
+		args:   []string{"pkg", "pull", "keycloak-smoke"},
+		expect: "the registry is required",
+	}, {
+		name:   "cannot connect the registry",
+		args:   []string{"pkg", "pull", "127.0.0.1:1/keycloak-smoke", "--plain-http"},
+		expect: "127.0.0.1:1",
+	}, {
+		name:   "lack of the arguments",
+		args:   []string{"pkg", "push", "testdata"},
+		expect: "accepts 2 arg(s)",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := NewRootCmd(fakeruntime.FakeExecer{}, NewFakeGRPCServer())
+			root.SetOut(&bytes.Buffer{})
+			root.SetArgs(tt.args)
+			err := root.Execute()
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expect)
+			}
+		})
+	}
+}
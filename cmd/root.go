@@ -8,7 +8,8 @@ import (
 	"github.com/spf13/cobra"
 )
 
-// NewRootCmd creates the root command
+// NewRootCmd creates the root command, the gRPC server will be created
+// with the options of the server command if it's nil
 func NewRootCmd(execer fakeruntime.Execer, gRPCServer gRPCServer) (c *cobra.Command) {
 	c = &cobra.Command{
 		Use:   "atest",
@@ -19,7 +20,12 @@ func NewRootCmd(execer fakeruntime.Execer, gRPCServer gRPCServer) (c *cobra.Comm
 	c.AddCommand(createInitCommand(execer),
 		createRunCommand(), createSampleCmd(),
 		createServerCmd(gRPCServer), createJSONSchemaCmd(),
-		createServiceCommand(execer))
+		createServiceCommand(execer), createMockSyncCmd(),
+		createVerifyPactCmd(), createConvertCmd(),
+		createExportCmd(), createMockCmd(),
+		createGenerateCmd(), createGraphCmd(),
+		createCtlCmd(), createPkgCmd(),
+		createAuthCmd(execer))
 	return
 }
 
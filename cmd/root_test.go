@@ -31,6 +31,70 @@ func TestSetRelativeDir(t *testing.T) {
 		verify: func(t *testing.T, testCase *atesting.TestCase) {
 			assert.Equal(t, "a/b/deploy.yaml", testCase.Prepare.Kubernetes[0])
 		},
+	}, {
+		name: "the dir of the exec step",
+		args: args{
+			configFile: "a/b/c.yaml",
+			testcase: &atesting.TestCase{
+				Request: atesting.Request{
+					Exec: &atesting.ExecRequest{Command: "ls"},
+				},
+			},
+		},
+		verify: func(t *testing.T, testCase *atesting.TestCase) {
+			assert.Equal(t, "a/b", testCase.Request.Exec.Dir)
+		},
+	}, {
+		name: "the absolute dir of the exec step",
+		args: args{
+			configFile: "a/b/c.yaml",
+			testcase: &atesting.TestCase{
+				Request: atesting.Request{
+					Exec: &atesting.ExecRequest{Command: "ls", Dir: "/tmp"},
+				},
+			},
+		},
+		verify: func(t *testing.T, testCase *atesting.TestCase) {
+			assert.Equal(t, "/tmp", testCase.Request.Exec.Dir)
+		},
+	}, {
+		name: "the local files of the prepare steps",
+		args: args{
+			configFile: "a/b/c.yaml",
+			testcase: &atesting.TestCase{
+				Prepare: atesting.Prepare{
+					Files: []atesting.FileStep{{BodyFromFile: "orders.csv"}, {BodyFromFile: "/tmp/users.csv"}, {Body: "id"}},
+				},
+			},
+		},
+		verify: func(t *testing.T, testCase *atesting.TestCase) {
+			assert.Equal(t, "a/b/orders.csv", testCase.Prepare.Files[0].BodyFromFile)
+			assert.Equal(t, "/tmp/users.csv", testCase.Prepare.Files[1].BodyFromFile)
+			assert.Equal(t, "", testCase.Prepare.Files[2].BodyFromFile)
+		},
+	}, {
+		name: "the schema file",
+		args: args{
+			configFile: "a/b/c.yaml",
+			testcase: &atesting.TestCase{
+				Expect: atesting.Response{SchemaFromFile: "schemas/projects.json", XSDFromFile: "/tmp/price.xsd"},
+			},
+		},
+		verify: func(t *testing.T, testCase *atesting.TestCase) {
+			assert.Equal(t, "a/b/schemas/projects.json", testCase.Expect.SchemaFromFile)
+			assert.Equal(t, "/tmp/price.xsd", testCase.Expect.XSDFromFile)
+		},
+	}, {
+		name: "the XML schema file",
+		args: args{
+			configFile: "a/b/c.yaml",
+			testcase: &atesting.TestCase{
+				Expect: atesting.Response{XSDFromFile: "schemas/price.xsd"},
+			},
+		},
+		verify: func(t *testing.T, testCase *atesting.TestCase) {
+			assert.Equal(t, "a/b/schemas/price.xsd", testCase.Expect.XSDFromFile)
+		},
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -40,6 +104,27 @@ func TestSetRelativeDir(t *testing.T) {
 	}
 }
 
+func TestSetRelativeTransportDir(t *testing.T) {
+	setRelativeTransportDir("a/b/c.yaml", nil)
+
+	transport := &atesting.Transport{Negotiate: &atesting.NegotiateAuth{Keytab: "rick.keytab"}}
+	setRelativeTransportDir("a/b/c.yaml", transport)
+	assert.Equal(t, "a/b/rick.keytab", transport.Negotiate.Keytab)
+
+	transport = &atesting.Transport{Negotiate: &atesting.NegotiateAuth{Keytab: "/etc/rick.keytab"}}
+	setRelativeTransportDir("a/b/c.yaml", transport)
+	assert.Equal(t, "/etc/rick.keytab", transport.Negotiate.Keytab)
+}
+
+func TestSetRelativeJOSEDir(t *testing.T) {
+	setRelativeJOSEDir("a/b/c.yaml", nil)
+
+	keys := &atesting.JOSE{Key: "private.pem", VerifyKey: "/etc/jwks.json"}
+	setRelativeJOSEDir("a/b/c.yaml", keys)
+	assert.Equal(t, "a/b/private.pem", keys.Key)
+	assert.Equal(t, "/etc/jwks.json", keys.VerifyKey)
+}
+
 func TestCreateRunCommand(t *testing.T) {
 	cmd := createRunCommand()
 	assert.Equal(t, "run", cmd.Use)
@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"path"
 	"path/filepath"
@@ -10,12 +13,16 @@ import (
 	"sync"
 	"time"
 
+	"github.com/linuxsuren/api-testing/pkg/generator"
 	"github.com/linuxsuren/api-testing/pkg/limit"
+	"github.com/linuxsuren/api-testing/pkg/oauth"
 	"github.com/linuxsuren/api-testing/pkg/render"
 	"github.com/linuxsuren/api-testing/pkg/runner"
 	"github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/linuxsuren/api-testing/pkg/util"
 	"github.com/spf13/cobra"
 	"golang.org/x/sync/semaphore"
+	"gopkg.in/yaml.v3"
 )
 
 type runOption struct {
@@ -33,14 +40,38 @@ type runOption struct {
 	reportWriter       runner.ReportResultWriter
 	report             string
 	reportIgnore       bool
+	terraformOutput    io.Writer
 	level              string
 	caseItems          []string
+	learn              bool
+	learnWrite         bool
+	learnOutput        io.Writer
+	trace              string
+	runID              string
+	stopOn             string
+	stopOnInterval     time.Duration
+	stopCondition      *runner.StopCondition
+	breach             string
+	targets            []string
+	targetList         []runTarget
+	target             string
+	shadow             string
+	shadowIgnores      []string
+	shadowDiffs        []runner.ShadowDiff
+	shadowLock         sync.Mutex
+	auth               string
+	authStore          string
+	tokenStore         *oauth.Store
+	updateSnapshots    bool
+	openAPI            string
+	openAPISpec        *runner.OpenAPISpec
 }
 
 func newDefaultRunOption() *runOption {
 	return &runOption{
 		reporter:     runner.NewMemoryTestReporter(),
 		reportWriter: runner.NewResultWriter(os.Stdout),
+		learnOutput:  os.Stdout,
 	}
 }
 
@@ -48,6 +79,7 @@ func newDiskCardRunOption() *runOption {
 	return &runOption{
 		reporter:     runner.NewDiscardTestReporter(),
 		reportWriter: runner.NewDiscardResultWriter(),
+		learnOutput:  io.Discard,
 	}
 }
 
@@ -58,6 +90,15 @@ func createRunCommand() (cmd *cobra.Command) {
 		Use:     "run",
 		Aliases: []string{"r"},
 		Example: `atest run -p sample.yaml
+atest run -p sample.yaml --learn --learn-write
+atest run -p sample.yaml --duration 1m --thread 3 --trace trace.json
+atest run -p sample.yaml --duration 10m --run-id load && atest ctl pause load
+atest run -p sample.yaml --duration 10m --stop-on 'p95>800ms || errorRate>5%'
+atest run -p sample.yaml --targets prod=https://a.com,canary=https://b.com
+atest run -p sample.yaml --shadow https://b.com --shadow-ignore 'data/*/updatedAt'
+atest run -p sample.yaml --auth dev
+atest run -p sample.yaml --update-snapshots
+atest run -p sample.yaml --openapi swagger.yaml
 See also https://github.com/LinuxSuRen/api-testing/tree/master/sample`,
 		Short:   "Run the test suite",
 		PreRunE: opt.preRunE,
@@ -76,7 +117,32 @@ See also https://github.com/LinuxSuRen/api-testing/tree/master/sample`,
 	flags.Int64VarP(&opt.thread, "thread", "", 1, "Threads of the execution")
 	flags.Int32VarP(&opt.qps, "qps", "", 5, "QPS")
 	flags.Int32VarP(&opt.burst, "burst", "", 5, "burst")
-	flags.StringVarP(&opt.report, "report", "", "", "The type of target report. Supported: markdown, md, discard, std")
+	flags.StringVarP(&opt.report, "report", "", "", "The type of target report. Supported: markdown, md, discard, std, terraform")
+	flags.StringVarP(&opt.trace, "trace", "", "",
+		"The file path of the Chrome trace format timeline of the run, it could be viewed in Perfetto")
+	flags.BoolVarP(&opt.learn, "learn", "", false,
+		"Infer the JSON schema from the responses of the test cases which lack the body expectations")
+	flags.BoolVarP(&opt.learnWrite, "learn-write", "", false,
+		"Write the inferred JSON schema back into the test suite files, print them only if it's false")
+	flags.StringVarP(&opt.runID, "run-id", "", "",
+		"The id of the duration run which could be controlled by the ctl command, it will be generated if it's empty")
+	flags.StringVarP(&opt.stopOn, "stop-on", "", "",
+		"Abort the duration run once the metrics meet the condition, e.g. 'p95>800ms || errorRate>5%'. "+
+			"Supported metrics: count, errors, errorRate, qps, avg, max, min, p50, p90, p95, p99")
+	flags.DurationVarP(&opt.stopOnInterval, "stop-on-interval", "", time.Second, "The interval of evaluating the stop condition")
+	flags.StringSliceVarP(&opt.targets, "targets", "", nil,
+		"Run the test cases against all the targets (name=API), then compare the status, latency and body side by side")
+	flags.StringVarP(&opt.shadow, "shadow", "", "",
+		"Send each request to the shadow API at the same time, then report the differences of the responses")
+	flags.StringSliceVarP(&opt.shadowIgnores, "shadow-ignore", "", nil,
+		"The body fields which are ignored when comparing with the shadow, e.g. data/*/updatedAt")
+	flags.StringVarP(&opt.auth, "auth", "", "",
+		"The name of the cached tokens of 'atest auth login', the access token is available in the templates, e.g. {{.auth.accessToken}}")
+	flags.StringVarP(&opt.authStore, "auth-store", "", oauth.DefaultStoreFile(), "The file which caches the tokens")
+	flags.BoolVarP(&opt.updateSnapshots, "update-snapshots", "", false,
+		"Write the outputs of the test cases into their golden files instead of comparing with them")
+	flags.StringVarP(&opt.openAPI, "openapi", "", "",
+		"The OpenAPI (or Swagger) spec file, the responses are validated by the schemas of the matched operations")
 	return
 }
 
@@ -90,10 +156,45 @@ func (o *runOption) preRunE(cmd *cobra.Command, args []string) (err error) {
 		o.reportWriter = runner.NewDiscardResultWriter()
 	case "", "std":
 		o.reportWriter = runner.NewResultWriter(writer)
+	case "terraform":
+		// only the result is written to the stdout, the others go to the stderr
+		o.reportWriter = runner.NewDiscardResultWriter()
+		o.terraformOutput = writer
+		cmd.SetOut(cmd.ErrOrStderr())
 	default:
 		err = fmt.Errorf("not supported report type: '%s'", o.report)
 	}
 
+	if o.learn && o.duration > 0 {
+		err = fmt.Errorf("--learn cannot work together with --duration")
+	}
+	if err == nil && o.stopOn != "" {
+		if o.duration <= 0 {
+			err = fmt.Errorf("--stop-on only works together with --duration")
+		} else if o.stopOnInterval <= 0 {
+			err = fmt.Errorf("--stop-on-interval should be positive")
+		} else {
+			o.stopCondition, err = runner.NewStopCondition(o.stopOn)
+		}
+	}
+	if err == nil && o.shadow != "" && len(o.targets) > 0 {
+		err = fmt.Errorf("--shadow cannot work together with --targets")
+	}
+	o.shadow = strings.TrimSuffix(o.shadow, "/")
+	if err == nil && len(o.targets) > 0 {
+		if o.duration > 0 || o.learn {
+			err = fmt.Errorf("--targets cannot work together with --duration or --learn")
+		} else {
+			o.targetList, err = parseRunTargets(o.targets)
+		}
+	}
+	if err == nil && o.openAPI != "" {
+		o.openAPISpec, err = runner.LoadOpenAPISpec(o.openAPI)
+	}
+	if o.auth != "" {
+		o.tokenStore = oauth.NewStore(o.authStore)
+	}
+	o.learnOutput = writer
 	o.caseItems = args
 	return
 }
@@ -108,14 +209,50 @@ func (o *runOption) runE(cmd *cobra.Command, args []string) (err error) {
 		o.limiter.Stop()
 	}()
 
+	if o.terraformOutput != nil {
+		// the failures are in the result, the check blocks or the postconditions of Terraform decide what to do
+		defer func() {
+			results, _ := o.reporter.ExportAllReportResults()
+			err = runner.WriteTerraformResult(o.terraformOutput, results, err)
+		}()
+	}
+
+	if o.duration > 0 {
+		if o.runID == "" {
+			o.runID = util.String(8)
+		}
+
+		var server *http.Server
+		if server, err = startControlServer(o.runID, o.limiter); err != nil {
+			return
+		}
+		defer server.Close()
+		cmd.Printf("run id: %s, pause it with: atest ctl pause %s\n", o.runID, o.runID)
+	}
+
+	if len(o.targetList) > 0 {
+		if files, err = filepath.Glob(o.pattern); err == nil {
+			err = o.runTargets(cmd.OutOrStdout(), files)
+		}
+		return
+	}
+
 	if files, err = filepath.Glob(o.pattern); err == nil {
 		for i := range files {
 			item := files[i]
-			if err = o.runSuiteWithDuration(item); err != nil {
+			if err = o.runSuiteWithDuration(item); err != nil || o.breach != "" {
 				break
 			}
 		}
 	}
+	if err == nil && o.breach != "" {
+		err = fmt.Errorf("aborted since the stop condition '%s' was met", o.stopCondition)
+	}
+
+	if o.trace != "" {
+		traceErr := o.writeTrace()
+		println(cmd, traceErr, "failed to write the trace", traceErr)
+	}
 
 	if o.reportIgnore {
 		return
@@ -129,6 +266,22 @@ func (o *runOption) runE(cmd *cobra.Command, args []string) (err error) {
 		println(cmd, outputErr, "failed to Output all reports", outputErr)
 	}
 	println(cmd, reportErr, "failed to export all reports", reportErr)
+	if o.shadow != "" {
+		shadowErr := runner.WriteShadowReport(cmd.OutOrStdout(), o.shadowDiffs)
+		println(cmd, shadowErr, "failed to write the shadow report", shadowErr)
+	}
+	if o.breach != "" {
+		cmd.Println(o.breach)
+	}
+	return
+}
+
+func (o *runOption) writeTrace() (err error) {
+	var file *os.File
+	if file, err = os.Create(o.trace); err == nil {
+		defer file.Close()
+		err = runner.WriteChromeTrace(file, o.reporter.GetAllRecords())
+	}
 	return
 }
 
@@ -136,12 +289,24 @@ func (o *runOption) runSuiteWithDuration(suite string) (err error) {
 	sem := semaphore.NewWeighted(o.thread)
 	stop := false
 	var timeout *time.Ticker
+	acquireCtx := o.context
 	if o.duration > 0 {
 		timeout = time.NewTicker(o.duration)
+
+		// stop waiting for an idle thread when the duration is over, the threads might be paused
+		var cancel context.CancelFunc
+		acquireCtx, cancel = context.WithTimeout(o.context, o.duration)
+		defer cancel()
 	} else {
 		// make sure having a valid timer
 		timeout = time.NewTicker(time.Second)
 	}
+	var checkStop <-chan time.Time
+	if o.stopCondition != nil {
+		ticker := time.NewTicker(o.stopOnInterval)
+		defer ticker.Stop()
+		checkStop = ticker.C
+	}
 	errChannel := make(chan error, 10*o.thread)
 	stopSingal := make(chan struct{}, 1)
 	var wait sync.WaitGroup
@@ -151,12 +316,20 @@ func (o *runOption) runSuiteWithDuration(suite string) (err error) {
 		case <-timeout.C:
 			stop = true
 			stopSingal <- struct{}{}
+			// release the routines which are waiting for resuming
+			o.limiter.Resume()
+		case <-checkStop:
+			if o.stopConditionMet() {
+				stop = true
+				stopSingal <- struct{}{}
+				o.limiter.Resume()
+			}
 		case err = <-errChannel:
 			if err != nil {
 				stop = true
 			}
 		default:
-			if err := sem.Acquire(o.context, 1); err != nil {
+			if err := sem.Acquire(acquireCtx, 1); err != nil {
 				continue
 			}
 			wait.Add(1)
@@ -187,12 +360,44 @@ func (o *runOption) runSuiteWithDuration(suite string) (err error) {
 	return
 }
 
+// stopConditionMet evaluates the stop condition with the metrics of the records so far,
+// the breach point will be recorded
+func (o *runOption) stopConditionMet() bool {
+	metrics := runner.ComputeMetrics(o.reporter.GetAllRecords())
+	if ok, err := o.stopCondition.Match(metrics); err != nil || !ok {
+		return false
+	}
+
+	o.breach = fmt.Sprintf("breach: '%s' was met at %s, %s", o.stopCondition,
+		time.Since(o.startTime).Round(time.Millisecond), metrics)
+	return true
+}
+
 func (o *runOption) runSuite(suite string, dataContext map[string]interface{}, ctx context.Context, stopSingal chan struct{}) (err error) {
 	var testSuite *testing.TestSuite
 	if testSuite, err = testing.Parse(suite); err != nil {
 		return
 	}
 
+	if testSuite.Param != nil {
+		dataContext["param"] = testSuite.Param
+	}
+	if err = o.setAuthContext(ctx, dataContext); err != nil {
+		return
+	}
+
+	var teardown func() error
+	if teardown, err = runner.SetupSuite(ctx, testSuite, o.caseItems, dataContext); err != nil {
+		return
+	}
+	defer func() {
+		if tearErr := teardown(); tearErr != nil && err == nil {
+			err = tearErr
+		}
+	}()
+	setRelativeTransportDir(suite, testSuite.Transport)
+	setRelativeJOSEDir(suite, testSuite.JOSE)
+
 	var result string
 	if result, err = render.Render("base api", testSuite.API, dataContext); err == nil {
 		testSuite.API = result
@@ -201,15 +406,24 @@ func (o *runOption) runSuite(suite string, dataContext map[string]interface{}, c
 		return
 	}
 
+	if o.target != "" {
+		retarget(testSuite, o.target)
+	}
+
+	learned := map[string]string{}
 	for _, testCase := range testSuite.Items {
 		if !testCase.InScope(o.caseItems) {
 			continue
 		}
 
 		// reuse the API prefix
-		if strings.HasPrefix(testCase.Request.API, "/") {
-			testCase.Request.API = fmt.Sprintf("%s%s", testSuite.API, testCase.Request.API)
-		}
+		testCase.Request.API = runner.JoinAPI(testSuite.API, testCase.Request.API)
+
+		// the files of the test case are relative to the suite file, the ones of the suite are set already
+		setRelativeTransportDir(suite, testCase.Request.Transport)
+		setRelativeJOSEDir(suite, testCase.Expect.JOSE)
+		testCase.InheritSuite(testSuite)
+		o.setOpenAPISchema(&testCase, testSuite.API)
 
 		var output interface{}
 		select {
@@ -221,9 +435,19 @@ func (o *runOption) runSuite(suite string, dataContext map[string]interface{}, c
 
 			ctxWithTimeout, _ := context.WithTimeout(ctx, o.requestTimeout)
 
-			simpleRunner := runner.NewSimpleTestCaseRunner()
-			simpleRunner.WithTestReporter(o.reporter)
-			if output, err = simpleRunner.RunTestCase(&testCase, dataContext, ctxWithTimeout); err != nil && !o.requestIgnoreError {
+			var runCase caseRunner = o.runTestCase
+			if testCase.Idempotency != nil {
+				runCase = o.runIdempotentTestCase
+			} else if testCase.Concurrency > 1 {
+				runCase = o.runConcurrentTestCase
+			}
+			if testCase.Snapshot != nil {
+				runCase = o.withSnapshot(runCase)
+			}
+			if testCase.Expect.Golden != "" {
+				runCase = o.withGolden(runCase)
+			}
+			if output, err = runCase(&testCase, testSuite.API, dataContext, ctxWithTimeout); err != nil && !o.requestIgnoreError {
 				err = fmt.Errorf("failed to run '%s', %v", testCase.Name, err)
 				return
 			} else {
@@ -231,10 +455,128 @@ func (o *runOption) runSuite(suite string, dataContext map[string]interface{}, c
 			}
 		}
 		dataContext[testCase.Name] = output
+
+		if o.learn && output != nil && lackBodyExpectation(testCase.Expect) {
+			if learned[testCase.Name], err = generator.InferJSONSchema(output); err != nil {
+				return
+			}
+		}
+	}
+
+	if len(learned) > 0 {
+		err = o.writeLearnedSchemas(suite, learned)
 	}
 	return
 }
 
+// lackBodyExpectation returns true if none of the expectations verifies the response body
+func lackBodyExpectation(expect testing.Response) bool {
+	return expect.Body == "" && expect.Schema == "" && expect.SchemaFromFile == "" &&
+		expect.XSD == "" && expect.XSDFromFile == "" &&
+		len(expect.BodyFieldsExpect) == 0 && len(expect.Verify) == 0 &&
+		len(expect.JSONPath) == 0 && len(expect.XPath) == 0 && len(expect.HTML) == 0 &&
+		len(expect.BodyRegexp) == 0 && len(expect.BodyContains) == 0 && len(expect.BodyNotContains) == 0 &&
+		len(expect.Arrays) == 0 && len(expect.GraphQLErrors) == 0 && expect.JSONRPCError == nil &&
+		expect.BodySHA256 == "" && expect.BodyMD5 == "" && expect.Golden == "" && expect.Protobuf == nil &&
+		len(expect.Verifiers) == 0
+}
+
+// writeLearnedSchemas writes the inferred schemas back into the test suite file,
+// or prints them if the writing is not confirmed
+func (o *runOption) writeLearnedSchemas(suiteFile string, schemas map[string]string) (err error) {
+	if !o.learnWrite {
+		for name, schema := range schemas {
+			fmt.Fprintf(o.learnOutput, "inferred schema of '%s':\n%s\n", name, schema)
+		}
+		return
+	}
+
+	// read it again due to the test suite was rendered
+	var data []byte
+	if data, err = os.ReadFile(suiteFile); err != nil {
+		return
+	}
+
+	if data, err = insertLearnedSchemas(data, schemas); err == nil {
+		if err = os.WriteFile(suiteFile, data, 0644); err == nil {
+			fmt.Fprintf(o.learnOutput, "wrote %d inferred schemas into '%s'\n", len(schemas), suiteFile)
+		}
+	}
+	return
+}
+
+// insertLearnedSchemas inserts the schemas into the expect of the test cases. The lines are located by the
+// YAML nodes, then the other parts of the test suite, such as the comments and the order of the fields, are kept
+func insertLearnedSchemas(data []byte, schemas map[string]string) (result []byte, err error) {
+	root := &yaml.Node{}
+	if err = yaml.Unmarshal(data, root); err != nil {
+		return
+	}
+
+	// the lines which are inserted before the zero-based line number
+	inserts := map[int][]string{}
+	if items := yamlMappingValue(root, "items"); items != nil {
+		for _, item := range items.Content {
+			name := yamlMappingValue(item, "name")
+			if name == nil {
+				continue
+			}
+			schema, ok := schemas[name.Value]
+			if !ok {
+				continue
+			}
+
+			var line, indent int
+			var lines []string
+			if expect := yamlMappingValue(item, "expect"); expect != nil && expect.Kind == yaml.MappingNode &&
+				expect.Style&yaml.FlowStyle == 0 && len(expect.Content) > 0 {
+				line, indent = expect.Content[0].Line, expect.Content[0].Column-1
+			} else if expect == nil && len(item.Content) >= 4 {
+				// the first field might follow the dash of the sequence, then the expect goes before the second one
+				line, indent = item.Content[2].Line, item.Content[2].Column-1
+				lines = append(lines, strings.Repeat(" ", indent)+"expect:")
+				indent += 2
+			} else {
+				err = fmt.Errorf("failed to write the schema of '%s', the expect should be a block mapping", name.Value)
+				return
+			}
+
+			lines = append(lines, strings.Repeat(" ", indent)+"schema: |")
+			for _, text := range strings.Split(schema, "\n") {
+				lines = append(lines, strings.Repeat(" ", indent+2)+text)
+			}
+			inserts[line-1] = lines
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	for i, text := range strings.SplitAfter(string(data), "\n") {
+		for _, insert := range inserts[i] {
+			buf.WriteString(insert + "\n")
+		}
+		buf.WriteString(text)
+	}
+	result = buf.Bytes()
+	return
+}
+
+// yamlMappingValue returns the value of the key in the mapping node, it's nil if the key does not exist
+func yamlMappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
 func getDefaultContext() map[string]interface{} {
 	return map[string]interface{}{}
 }
@@ -245,4 +587,49 @@ func setRelativeDir(configFile string, testcase *testing.TestCase) {
 	for i := range testcase.Prepare.Kubernetes {
 		testcase.Prepare.Kubernetes[i] = path.Join(dir, testcase.Prepare.Kubernetes[i])
 	}
+
+	// the command runs in the directory of the suite file by default
+	if execRequest := testcase.Request.Exec; execRequest != nil && !filepath.IsAbs(execRequest.Dir) {
+		execRequest.Dir = path.Join(dir, execRequest.Dir)
+	}
+
+	for i, file := range testcase.Prepare.Files {
+		if file.BodyFromFile != "" && !filepath.IsAbs(file.BodyFromFile) {
+			testcase.Prepare.Files[i].BodyFromFile = path.Join(dir, file.BodyFromFile)
+		}
+	}
+
+	if schema := testcase.Expect.SchemaFromFile; schema != "" && !filepath.IsAbs(schema) {
+		testcase.Expect.SchemaFromFile = path.Join(dir, schema)
+	}
+	if xsd := testcase.Expect.XSDFromFile; xsd != "" && !filepath.IsAbs(xsd) {
+		testcase.Expect.XSDFromFile = path.Join(dir, xsd)
+	}
+	if golden := testcase.Expect.Golden; golden != "" && !filepath.IsAbs(golden) {
+		testcase.Expect.Golden = path.Join(dir, golden)
+	}
+}
+
+// setRelativeTransportDir makes the keytab relative to the suite file. The transport of the suite
+// is shared by its test cases, so it's called once for each transport instead of each test case
+func setRelativeTransportDir(configFile string, transport *testing.Transport) {
+	if transport == nil || transport.Negotiate == nil {
+		return
+	}
+	if keytab := transport.Negotiate.Keytab; keytab != "" && !filepath.IsAbs(keytab) {
+		transport.Negotiate.Keytab = path.Join(filepath.Dir(configFile), keytab)
+	}
+}
+
+// setRelativeJOSEDir makes the key files relative to the suite file, it's called once for each JOSE like the transport
+func setRelativeJOSEDir(configFile string, keys *testing.JOSE) {
+	if keys == nil {
+		return
+	}
+	if keys.Key != "" && !filepath.IsAbs(keys.Key) {
+		keys.Key = path.Join(filepath.Dir(configFile), keys.Key)
+	}
+	if keys.VerifyKey != "" && !filepath.IsAbs(keys.VerifyKey) {
+		keys.VerifyKey = path.Join(filepath.Dir(configFile), keys.VerifyKey)
+	}
 }
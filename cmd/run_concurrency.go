@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+// runConcurrentTestCase sends the copies of the test case at the same time, then verifies the number
+// of the succeeded ones. The output of the first succeeded copy is returned.
+func (o *runOption) runConcurrentTestCase(testCase *testing.TestCase, suiteAPI string, dataContext map[string]interface{},
+	ctx context.Context) (output interface{}, err error) {
+	concurrency := testCase.Concurrency
+	expected := testCase.Expect.Successes
+	if expected <= 0 {
+		expected = concurrency
+	}
+	if expected > concurrency {
+		err = fmt.Errorf("the successes %d is greater than the concurrency %d", expected, concurrency)
+		return
+	}
+
+	copies := make([]testing.TestCase, concurrency)
+	for i := range copies {
+		if copies[i], err = copyTestCase(testCase); err != nil {
+			return
+		}
+	}
+
+	outputs := make([]interface{}, concurrency)
+	errs := make([]error, concurrency)
+	start := make(chan struct{})
+	var wait sync.WaitGroup
+	for i := range copies {
+		wait.Add(1)
+		go func(i int) {
+			defer wait.Done()
+			// fire all the requests at the same time
+			<-start
+			outputs[i], errs[i] = o.runTestCase(&copies[i], suiteAPI, dataContext, ctx)
+		}(i)
+	}
+	close(start)
+	wait.Wait()
+
+	succeeded := 0
+	failures := map[string]int{}
+	for i := range errs {
+		if errs[i] != nil {
+			failures[errs[i].Error()]++
+			continue
+		}
+		if succeeded == 0 {
+			output = outputs[i]
+		}
+		succeeded++
+	}
+
+	if succeeded != expected {
+		err = fmt.Errorf("expect %d of the %d concurrent requests succeed, actual %d%s",
+			expected, concurrency, succeeded, concurrentFailures(failures))
+	}
+	return
+}
+
+// concurrentFailures groups the same errors, it's hard to read them one by one
+func concurrentFailures(failures map[string]int) string {
+	if len(failures) == 0 {
+		return ""
+	}
+
+	messages := make([]string, 0, len(failures))
+	for message, count := range failures {
+		messages = append(messages, fmt.Sprintf("%d x %s", count, message))
+	}
+	sort.Strings(messages)
+	return ", failures: " + strings.Join(messages, "; ")
+}
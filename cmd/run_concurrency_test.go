@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/h2non/gock"
+	"github.com/linuxsuren/api-testing/pkg/limit"
+	atesting "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunConcurrentTestCase(t *testing.T) {
+	// send the requests to the real server instead of the mocks of the other tests
+	gock.Off()
+
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// only the first payment is accepted
+		if req.URL.Path == "/ping" || atomic.AddInt32(&count, 1) == 1 {
+			w.WriteHeader(http.StatusCreated)
+		} else {
+			w.WriteHeader(http.StatusConflict)
+		}
+		_, _ = w.Write([]byte(`{"id": "1"}`))
+	}))
+	defer server.Close()
+
+	opt := newDiskCardRunOption()
+	opt.requestTimeout = 30 * time.Second
+	opt.limiter = limit.NewDefaultRateLimiter(0, 0)
+
+	tests := []struct {
+		name      string
+		testCase  atesting.TestCase
+		expectErr string
+	}{{
+		name: "exactly one succeeds",
+		testCase: atesting.TestCase{
+			Concurrency: 10,
+			Request:     atesting.Request{API: server.URL, Method: http.MethodPost},
+			Expect:      atesting.Response{StatusCode: http.StatusCreated, Successes: 1},
+		},
+	}, {
+		name: "all succeed",
+		testCase: atesting.TestCase{
+			Concurrency: 5,
+			Request:     atesting.Request{API: server.URL + "/ping"},
+			Expect:      atesting.Response{StatusCode: http.StatusCreated},
+		},
+	}, {
+		name: "not all succeed",
+		testCase: atesting.TestCase{
+			Concurrency: 3,
+			Request:     atesting.Request{API: server.URL, Method: http.MethodPost},
+			Expect:      atesting.Response{StatusCode: http.StatusCreated},
+		},
+		expectErr: "expect 3 of the 3 concurrent requests succeed, actual 1, failures: 2 x ",
+	}, {
+		name: "invalid successes",
+		testCase: atesting.TestCase{
+			Concurrency: 2,
+			Expect:      atesting.Response{Successes: 3},
+		},
+		expectErr: "the successes 3 is greater than the concurrency 2",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&count, 0)
+			output, err := opt.runConcurrentTestCase(&tt.testCase, "", getDefaultContext(), context.TODO())
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+				assert.Equal(t, map[string]interface{}{"id": "1"}, output)
+			} else if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expectErr)
+			}
+		})
+	}
+}
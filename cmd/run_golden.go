@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/linuxsuren/api-testing/pkg/runner"
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+// withGolden compares the output of the test case with its golden file, the file is recorded by the first run
+func (o *runOption) withGolden(run caseRunner) caseRunner {
+	return func(testCase *testing.TestCase, suiteAPI string, dataContext map[string]interface{},
+		ctx context.Context) (output interface{}, err error) {
+		if output, err = run(testCase, suiteAPI, dataContext, ctx); err == nil {
+			err = runner.VerifyGolden(testCase.Name, testCase.Expect.Golden, testCase.Expect.GoldenIgnores, output,
+				o.updateSnapshots)
+		}
+		return
+	}
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/h2non/gock"
+	"github.com/linuxsuren/api-testing/pkg/limit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunSuiteWithGolden(t *testing.T) {
+	defer gock.Off()
+
+	dir := t.TempDir()
+	suiteFile := filepath.Join(dir, "suite.yaml")
+	assert.Nil(t, os.WriteFile(suiteFile, []byte(`name: golden
+api: http://foo
+items:
+- name: users
+  request:
+    api: /users
+  expect:
+    golden: testdata/users.json
+    goldenIgnores:
+    - '*/createdAt'
+`), 0644))
+
+	opt := newDiskCardRunOption()
+	opt.requestTimeout = 30 * time.Second
+	opt.limiter = limit.NewDefaultRateLimiter(0, 0)
+	run := func(body string) error {
+		gock.New(urlFoo).Get("/users").Reply(http.StatusOK).JSON(body)
+		return opt.runSuite(suiteFile, getDefaultContext(), context.TODO(), make(chan struct{}, 1))
+	}
+
+	assert.Nil(t, run(`[{"name": "rick", "createdAt": "2024-01-01"}]`))
+	assert.FileExists(t, filepath.Join(dir, "testdata", "users.json"))
+	assert.Nil(t, run(`[{"name": "rick", "createdAt": "2024-01-02"}]`))
+
+	err := run(`[{"name": "morty", "createdAt": "2024-01-02"}]`)
+	assert.Contains(t, err.Error(), `0/name: "rick" != "morty"`)
+
+	opt.updateSnapshots = true
+	assert.Nil(t, run(`[{"name": "morty", "createdAt": "2024-01-02"}]`))
+	opt.updateSnapshots = false
+	assert.Nil(t, run(`[{"name": "morty", "createdAt": "2024-01-03"}]`))
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/PaesslerAG/jsonpath"
+	"github.com/linuxsuren/api-testing/pkg/render"
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/linuxsuren/api-testing/pkg/util"
+)
+
+const (
+	semanticsExactlyOnce  = "exactly-once"
+	semanticsAtLeastOnce  = "at-least-once"
+	defaultIdempotencyKey = "Idempotency-Key"
+)
+
+// runIdempotentTestCase sends the concurrent copies of the test case with the same idempotency key,
+// then verifies the number of the created resources by the follow-up query
+func (o *runOption) runIdempotentTestCase(testCase *testing.TestCase, suiteAPI string, dataContext map[string]interface{},
+	ctx context.Context) (output interface{}, err error) {
+	idempotency := testCase.Idempotency
+	semantics := idempotency.Semantics
+	if semantics == "" {
+		semantics = semanticsExactlyOnce
+	}
+	if semantics != semanticsExactlyOnce && semantics != semanticsAtLeastOnce {
+		err = fmt.Errorf("not supported semantics: '%s', supported: %s, %s", semantics, semanticsExactlyOnce, semanticsAtLeastOnce)
+		return
+	}
+
+	var key string
+	if key, err = render.Render("idempotency key", idempotency.Key, dataContext); err != nil {
+		return
+	}
+	if key == "" {
+		key = util.String(16)
+	}
+	header := idempotency.Header
+	if header == "" {
+		header = defaultIdempotencyKey
+	}
+	if testCase.Request.Header == nil {
+		testCase.Request.Header = map[string]string{}
+	}
+	testCase.Request.Header[header] = key
+
+	// the duplicated requests are required
+	if testCase.Concurrency < 2 {
+		testCase.Concurrency = 2
+	}
+	if output, err = o.runConcurrentTestCase(testCase, suiteAPI, dataContext, ctx); err != nil || idempotency.Query == nil {
+		return
+	}
+
+	var count int
+	if count, err = o.countIdempotentResources(testCase, suiteAPI, key, dataContext, ctx); err != nil {
+		return
+	}
+	if (semantics == semanticsExactlyOnce && count != 1) || (semantics == semanticsAtLeastOnce && count < 1) {
+		err = fmt.Errorf("expect %s, but %d resources were created by the %d requests with the key %s",
+			semantics, count, testCase.Concurrency, key)
+	}
+	return
+}
+
+// countIdempotentResources sends the query, then counts the resources in the response
+func (o *runOption) countIdempotentResources(testCase *testing.TestCase, suiteAPI, key string,
+	dataContext map[string]interface{}, ctx context.Context) (count int, err error) {
+	// the data context is shared by the following test cases
+	queryContext := map[string]interface{}{}
+	for k, v := range dataContext {
+		queryContext[k] = v
+	}
+	queryContext["idempotencyKey"] = key
+
+	query := testing.TestCase{
+		Name:    testCase.Name + " query",
+		Request: *testCase.Idempotency.Query,
+	}
+	if strings.HasPrefix(query.Request.API, "/") {
+		query.Request.API = suiteAPI + query.Request.API
+	}
+
+	var output interface{}
+	if output, err = o.runTestCase(&query, suiteAPI, queryContext, ctx); err != nil {
+		err = fmt.Errorf("failed to query the resources, %v", err)
+		return
+	}
+
+	if path := testCase.Idempotency.Count; path != "" {
+		if output, err = jsonpath.Get(path, output); err != nil {
+			err = fmt.Errorf("failed to get the JSONPath %s, %v", path, err)
+			return
+		}
+	}
+
+	switch val := output.(type) {
+	case []interface{}:
+		count = len(val)
+	case float64:
+		count = int(val)
+	default:
+		err = fmt.Errorf("the resources should be an array or a number, but it's %v", val)
+	}
+	return
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/h2non/gock"
+	"github.com/linuxsuren/api-testing/pkg/limit"
+	atesting "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunIdempotentTestCase(t *testing.T) {
+	// send the requests to the real server instead of the mocks of the other tests
+	gock.Off()
+
+	var lock sync.Mutex
+	payments := map[string][]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		switch req.Method {
+		case http.MethodPost:
+			key := req.Header.Get("Idempotency-Key") + req.Header.Get("X-Request-Id")
+			// the broken endpoint ignores the key
+			if len(payments[key]) == 0 || req.URL.Path == "/broken" {
+				payments[key] = append(payments[key], "1")
+			}
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id": "1"}`))
+		default:
+			payments := payments[req.URL.Query().Get("key")]
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"items": payments, "total": len(payments)})
+		}
+	}))
+	defer server.Close()
+
+	opt := newDiskCardRunOption()
+	opt.requestTimeout = 30 * time.Second
+	opt.limiter = limit.NewDefaultRateLimiter(0, 0)
+
+	tests := []struct {
+		name      string
+		testCase  atesting.TestCase
+		expectErr string
+	}{{
+		name: "exactly once",
+		testCase: atesting.TestCase{
+			Concurrency: 5,
+			Request:     atesting.Request{API: "/payments", Method: http.MethodPost},
+			Expect:      atesting.Response{StatusCode: http.StatusCreated},
+			Idempotency: &atesting.Idempotency{
+				Query: &atesting.Request{API: "/payments?key={{.idempotencyKey}}"},
+				Count: "$.items",
+			},
+		},
+	}, {
+		name: "at least once with the custom key",
+		testCase: atesting.TestCase{
+			Request: atesting.Request{API: "/broken", Method: http.MethodPost},
+			Expect:  atesting.Response{StatusCode: http.StatusCreated},
+			Idempotency: &atesting.Idempotency{
+				Header:    "X-Request-Id",
+				Key:       "order-{{.param.id}}",
+				Semantics: "at-least-once",
+				Query:     &atesting.Request{API: "/payments?key={{.idempotencyKey}}"},
+				Count:     "$.total",
+			},
+		},
+	}, {
+		name: "duplicated resources",
+		testCase: atesting.TestCase{
+			Request: atesting.Request{API: "/broken", Method: http.MethodPost},
+			Expect:  atesting.Response{StatusCode: http.StatusCreated},
+			Idempotency: &atesting.Idempotency{
+				Query: &atesting.Request{API: "/payments?key={{.idempotencyKey}}"},
+				Count: "$.total",
+			},
+		},
+		expectErr: "expect exactly-once, but 2 resources were created by the 2 requests with the key ",
+	}, {
+		name: "not a number",
+		testCase: atesting.TestCase{
+			Request:     atesting.Request{API: "/payments", Method: http.MethodPost},
+			Expect:      atesting.Response{StatusCode: http.StatusCreated},
+			Idempotency: &atesting.Idempotency{Query: &atesting.Request{API: "/payments"}},
+		},
+		expectErr: "the resources should be an array or a number",
+	}, {
+		name: "unknown semantics",
+		testCase: atesting.TestCase{
+			Idempotency: &atesting.Idempotency{Semantics: "at-most-once"},
+		},
+		expectErr: "not supported semantics: 'at-most-once', supported: exactly-once, at-least-once",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.testCase.Request.API = server.URL + tt.testCase.Request.API
+			dataContext := getDefaultContext()
+			dataContext["param"] = map[string]string{"id": "1"}
+			output, err := opt.runIdempotentTestCase(&tt.testCase, server.URL, dataContext, context.TODO())
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+				assert.Equal(t, map[string]interface{}{"id": "1"}, output)
+			} else if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expectErr)
+			}
+		})
+	}
+}
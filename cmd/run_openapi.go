@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+// setOpenAPISchema sets the schema of the matched operation in the OpenAPI spec as the expected one,
+// the test cases which have their own schemas, or are not under the API of the suite, are not changed
+func (o *runOption) setOpenAPISchema(testCase *testing.TestCase, suiteAPI string) {
+	if o.openAPISpec == nil || testCase.Expect.Schema != "" || testCase.Expect.SchemaFromFile != "" ||
+		suiteAPI == "" || !strings.HasPrefix(testCase.Request.API, suiteAPI) {
+		return
+	}
+
+	method := testCase.Request.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	statusCode := testCase.Expect.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	// the path of a unix socket API is separated by a colon
+	path := strings.TrimPrefix(strings.TrimPrefix(testCase.Request.API, suiteAPI), ":")
+	testCase.Expect.Schema = o.openAPISpec.ResponseSchema(method, path, statusCode)
+}
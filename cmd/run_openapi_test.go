@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/h2non/gock"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunWithOpenAPI(t *testing.T) {
+	spec := filepath.Join(t.TempDir(), "openapi.yaml")
+	assert.Nil(t, os.WriteFile(spec, []byte(`openapi: 3.0.0
+paths:
+  /bar:
+    get:
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [name]
+`), 0644))
+
+	tests := []struct {
+		name      string
+		body      string
+		args      []string
+		expectErr bool
+	}{{
+		name: "valid",
+		body: `{"name": "rick"}`,
+		args: []string{"--openapi", spec},
+	}, {
+		name:      "invalid",
+		body:      `{"id": 1}`,
+		args:      []string{"--openapi", spec},
+		expectErr: true,
+	}, {
+		name:      "spec not found",
+		args:      []string{"--openapi", "fake.yaml"},
+		expectErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer gock.Clean()
+			if tt.body != "" {
+				gock.New(urlFoo).Get("/bar").Reply(http.StatusOK).JSON(tt.body)
+			}
+
+			root := &cobra.Command{Use: "root"}
+			root.AddCommand(createRunCommand())
+			root.SetArgs(append([]string{"run", "-p", simpleSuite, "--report", "discard"}, tt.args...))
+			err := root.Execute()
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/linuxsuren/api-testing/pkg/runner"
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+// runTestCase runs the test case, and sends it to the shadow API at the same time if it's set
+func (o *runOption) runTestCase(testCase *testing.TestCase, suiteAPI string, dataContext map[string]interface{},
+	ctx context.Context) (output interface{}, err error) {
+	if o.shadow == "" || suiteAPI == "" || !strings.HasPrefix(testCase.Request.API, suiteAPI) {
+		caseRunner := runner.GetTestCaseRunner(testCase)
+		caseRunner.WithTestReporter(o.reporter)
+		return caseRunner.RunTestCase(testCase, dataContext, ctx)
+	}
+
+	var shadowCase testing.TestCase
+	if shadowCase, err = copyTestCase(testCase); err != nil {
+		return
+	}
+	shadowCase.Request.API = o.shadow + strings.TrimPrefix(testCase.Request.API, suiteAPI)
+	// the shadow only sends the request, the prepare and clean steps and the expectations belong to the primary.
+	// The compression is kept, the body should be decoded like the primary one
+	shadowCase.Prepare, shadowCase.Clean = testing.Prepare{}, testing.Clean{}
+	shadowCase.Expect = testing.Response{Compression: shadowCase.Expect.Compression}
+
+	primaryReporter, shadowReporter := runner.NewMemoryTestReporter(), runner.NewMemoryTestReporter()
+	var wait sync.WaitGroup
+	wait.Add(1)
+	go func() {
+		defer wait.Done()
+		// the failures of the shadow are ignored, only the responses are compared
+		_, _ = runner.GetTestCaseRunner(&shadowCase).WithTestReporter(shadowReporter).
+			RunTestCase(&shadowCase, dataContext, ctx)
+	}()
+
+	caseRunner := runner.GetTestCaseRunner(testCase)
+	caseRunner.WithTestReporter(primaryReporter)
+	output, err = caseRunner.RunTestCase(testCase, dataContext, ctx)
+	wait.Wait()
+
+	primaryRecords, shadowRecords := primaryReporter.GetAllRecords(), shadowReporter.GetAllRecords()
+	for _, record := range primaryRecords {
+		o.reporter.PutRecord(record)
+	}
+	if len(primaryRecords) == 1 && len(shadowRecords) == 1 {
+		if differences := runner.DiffRecords(primaryRecords[0], shadowRecords[0], o.shadowIgnores); len(differences) > 0 {
+			o.shadowLock.Lock()
+			o.shadowDiffs = append(o.shadowDiffs, runner.ShadowDiff{Name: testCase.Name, Differences: differences})
+			o.shadowLock.Unlock()
+		}
+	}
+	return
+}
+
+// copyTestCase deep copies the test case, it's safe to render them separately
+func copyTestCase(testCase *testing.TestCase) (result testing.TestCase, err error) {
+	var data []byte
+	if data, err = json.Marshal(testCase); err == nil {
+		err = json.Unmarshal(data, &result)
+	}
+	return
+}
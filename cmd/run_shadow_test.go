@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/h2non/gock"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunShadow(t *testing.T) {
+	defer gock.Clean()
+	gock.New(urlFoo).Get("/bar").Reply(http.StatusOK).JSON(`{"name": "a", "id": 1}`)
+	gock.New("http://shadow").Get("/bar").Reply(http.StatusOK).JSON(`{"name": "b", "id": 2}`)
+
+	buf := new(bytes.Buffer)
+	root := &cobra.Command{Use: "root"}
+	root.AddCommand(createRunCommand())
+	root.SetOut(buf)
+	root.SetArgs([]string{"run", "-p", simpleSuite, "--shadow", "http://shadow/", "--shadow-ignore", "id"})
+
+	err := root.Execute()
+	assert.Nil(t, err)
+	assert.Contains(t, buf.String(), "found differences of 1 test cases between the primary and shadow")
+	assert.Contains(t, buf.String(), `name: "a" != "b"`)
+	assert.NotContains(t, buf.String(), "id: 1 != 2")
+
+	root = &cobra.Command{Use: "root"}
+	root.AddCommand(createRunCommand())
+	root.SetArgs([]string{"run", "-p", simpleSuite, "--shadow", "http://shadow", "--targets", "prod=http://prod"})
+	assert.NotNil(t, root.Execute())
+}
+
+func TestRunShadowPrepareOnce(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake kubectl is a shell script")
+	}
+	defer gock.Clean()
+	gock.New(urlFoo).Get("/bar").Reply(http.StatusOK).JSON(`{"name": "a"}`)
+	gock.New("http://shadow").Get("/bar").Reply(http.StatusOK).JSON(`{"name": "a"}`)
+
+	// the fake kubectl records the calls
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "kubectl"), []byte("#!/bin/sh\necho \"$@\" >> "+calls+"\n"), 0755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	suite := filepath.Join(dir, "suite.yaml")
+	assert.Nil(t, os.WriteFile(suite, []byte(`name: shadow
+api: http://foo
+items:
+- name: bar
+  prepare:
+    kubernetes: [deploy.yaml]
+  request:
+    api: /bar
+  expect:
+    bodyFieldsExpect:
+      name: a
+  clean:
+    cleanPrepare: true
+`), 0644))
+
+	root := &cobra.Command{Use: "root"}
+	root.AddCommand(createRunCommand())
+	root.SetOut(new(bytes.Buffer))
+	root.SetArgs([]string{"run", "-p", suite, "--shadow", "http://shadow"})
+	assert.Nil(t, root.Execute())
+	assert.True(t, gock.IsDone())
+
+	data, err := os.ReadFile(calls)
+	assert.Nil(t, err)
+	assert.Equal(t, "apply -f "+filepath.Join(dir, "deploy.yaml")+"\ndelete -f "+filepath.Join(dir, "deploy.yaml")+"\n", string(data))
+}
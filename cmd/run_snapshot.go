@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/runner"
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+type caseRunner func(testCase *testing.TestCase, suiteAPI string, dataContext map[string]interface{},
+	ctx context.Context) (output interface{}, err error)
+
+// withSnapshot records the response of the read endpoint before and after running the test case,
+// then verifies the differences between them
+func (o *runOption) withSnapshot(run caseRunner) caseRunner {
+	return func(testCase *testing.TestCase, suiteAPI string, dataContext map[string]interface{},
+		ctx context.Context) (output interface{}, err error) {
+		var before, after interface{}
+		if before, err = o.takeSnapshot(testCase, suiteAPI, dataContext, ctx); err != nil {
+			return
+		}
+		if output, err = run(testCase, suiteAPI, dataContext, ctx); err != nil {
+			return
+		}
+		if after, err = o.takeSnapshot(testCase, suiteAPI, dataContext, ctx); err != nil {
+			return
+		}
+
+		var diff *runner.SnapshotDiff
+		if diff, err = runner.DiffSnapshots(testCase.Snapshot, before, after); err != nil {
+			err = fmt.Errorf("case: %s, %v", testCase.Name, err)
+			return
+		}
+		if expect := testCase.Expect.Snapshot; expect != nil {
+			err = runner.VerifySnapshot(testCase.Name, expect, before, after, diff)
+		}
+		return
+	}
+}
+
+func (o *runOption) takeSnapshot(testCase *testing.TestCase, suiteAPI string, dataContext map[string]interface{},
+	ctx context.Context) (output interface{}, err error) {
+	var snapshotCase testing.TestCase
+	// the request is rendered for each snapshot
+	if snapshotCase, err = copyTestCase(&testing.TestCase{
+		Name:    testCase.Name + " snapshot",
+		Request: testCase.Snapshot.Request,
+	}); err != nil {
+		return
+	}
+	if strings.HasPrefix(snapshotCase.Request.API, "/") {
+		snapshotCase.Request.API = suiteAPI + snapshotCase.Request.API
+	}
+
+	if output, err = o.runTestCase(&snapshotCase, suiteAPI, dataContext, ctx); err != nil {
+		err = fmt.Errorf("failed to take the snapshot, %v", err)
+	}
+	return
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/h2non/gock"
+	"github.com/linuxsuren/api-testing/pkg/limit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunSuiteWithSnapshot(t *testing.T) {
+	defer gock.Off()
+
+	suiteFile := filepath.Join(t.TempDir(), "suite.yaml")
+	assert.Nil(t, os.WriteFile(suiteFile, []byte(`name: snapshot
+api: http://foo
+items:
+- name: create
+  request:
+    api: /projects
+    method: POST
+    body: '{"name": "b"}'
+  snapshot:
+    request:
+      api: /projects
+    items: $.items
+    key: id
+  expect:
+    statusCode: 201
+    snapshot:
+      added: 1
+      removed: 0
+      verify:
+      - added[0].name == "b"
+`), 0644))
+
+	opt := newDiskCardRunOption()
+	opt.requestTimeout = 30 * time.Second
+	opt.limiter = limit.NewDefaultRateLimiter(0, 0)
+
+	gock.New(urlFoo).Get("/projects").Reply(http.StatusOK).JSON(`{"items": [{"id": 1, "name": "a"}]}`)
+	gock.New(urlFoo).Post("/projects").Reply(http.StatusCreated).JSON(`{"id": 2}`)
+	gock.New(urlFoo).Get("/projects").Reply(http.StatusOK).JSON(`{"items": [{"id": 1, "name": "a"}, {"id": 2, "name": "b"}]}`)
+	err := opt.runSuite(suiteFile, getDefaultContext(), context.TODO(), make(chan struct{}, 1))
+	assert.Nil(t, err)
+	assert.True(t, gock.IsDone())
+
+	// the item was not created
+	gock.New(urlFoo).Get("/projects").Times(2).Reply(http.StatusOK).JSON(`{"items": [{"id": 1, "name": "a"}]}`)
+	gock.New(urlFoo).Post("/projects").Reply(http.StatusCreated).JSON(`{"id": 2}`)
+	err = opt.runSuite(suiteFile, getDefaultContext(), context.TODO(), make(chan struct{}, 1))
+	assert.EqualError(t, err, "failed to run 'create', case: create, expect 1 added items in the snapshot, actual 0, []")
+
+	// failed to take the snapshot
+	gock.New(urlFoo).Get("/projects").Reply(http.StatusInternalServerError)
+	err = opt.runSuite(suiteFile, getDefaultContext(), context.TODO(), make(chan struct{}, 1))
+	assert.Contains(t, err.Error(), "failed to take the snapshot")
+}
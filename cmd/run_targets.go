@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/runner"
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+type runTarget struct {
+	name string
+	api  string
+}
+
+// parseRunTargets parses the targets in the format of name=API
+func parseRunTargets(targets []string) (result []runTarget, err error) {
+	names := map[string]bool{}
+	for _, target := range targets {
+		name, api, ok := strings.Cut(target, "=")
+		if !ok || name == "" || api == "" {
+			err = fmt.Errorf("invalid target '%s', it should be name=API", target)
+			return
+		}
+		if names[name] {
+			err = fmt.Errorf("duplicated target name '%s'", name)
+			return
+		}
+		if _, err = url.Parse(api); err != nil {
+			return
+		}
+
+		names[name] = true
+		result = append(result, runTarget{name: name, api: strings.TrimSuffix(api, "/")})
+	}
+	return
+}
+
+// runTargets runs all the test suites against each target, then writes the comparison report.
+// The failed test cases don't stop the run, they are part of the comparison
+func (o *runOption) runTargets(writer io.Writer, files []string) (err error) {
+	reporter, ignoreError := o.reporter, o.requestIgnoreError
+	defer func() {
+		o.reporter, o.requestIgnoreError, o.target = reporter, ignoreError, ""
+	}()
+	o.requestIgnoreError = true
+
+	var results []runner.TargetRecords
+	for _, target := range o.targetList {
+		o.target = target.api
+		o.reporter = runner.NewMemoryTestReporter()
+		for _, file := range files {
+			if err = o.runSuite(file, getDefaultContext(), o.context, make(chan struct{}, 1)); err != nil {
+				err = fmt.Errorf("failed to run against target '%s', %v", target.name, err)
+				return
+			}
+		}
+		results = append(results, runner.TargetRecords{
+			Name:    target.name,
+			Records: o.reporter.GetAllRecords(),
+		})
+	}
+	err = runner.WriteComparison(writer, results)
+	return
+}
+
+// retarget replaces the API prefix of the test suite and its test cases with the target
+func retarget(suite *testing.TestSuite, target string) {
+	for i := range suite.Items {
+		request := &suite.Items[i].Request
+		if suite.API != "" && strings.HasPrefix(request.API, suite.API) {
+			request.API = target + strings.TrimPrefix(request.API, suite.API)
+		}
+	}
+	suite.API = target
+}
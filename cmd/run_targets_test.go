@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/h2non/gock"
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRunTargets(t *testing.T) {
+	targets, err := parseRunTargets([]string{"prod=https://a.com/", "canary=https://b.com"})
+	assert.Nil(t, err)
+	assert.Equal(t, []runTarget{{name: "prod", api: "https://a.com"}, {name: "canary", api: "https://b.com"}}, targets)
+
+	for _, invalid := range [][]string{{"prod"}, {"=https://a.com"}, {"prod="}, {"a=http://a", "a=http://b"}} {
+		_, err = parseRunTargets(invalid)
+		assert.NotNil(t, err, invalid)
+	}
+}
+
+func TestRetarget(t *testing.T) {
+	suite := &atest.TestSuite{
+		API: "http://foo",
+		Items: []atest.TestCase{{
+			Request: atest.Request{API: "http://foo/bar"},
+		}, {
+			Request: atest.Request{API: "/bar"},
+		}, {
+			Request: atest.Request{API: "http://other/bar"},
+		}},
+	}
+	retarget(suite, "http://canary")
+	assert.Equal(t, "http://canary", suite.API)
+	assert.Equal(t, "http://canary/bar", suite.Items[0].Request.API)
+	assert.Equal(t, "/bar", suite.Items[1].Request.API)
+	assert.Equal(t, "http://other/bar", suite.Items[2].Request.API)
+}
+
+func TestRunTargets(t *testing.T) {
+	defer gock.Clean()
+	gock.New("http://prod").Get("/bar").Reply(http.StatusOK).JSON(`{"name": "a"}`)
+	gock.New("http://canary").Get("/bar").Reply(http.StatusOK).JSON(`{"name": "b"}`)
+
+	buf := new(bytes.Buffer)
+	root := &cobra.Command{Use: "root"}
+	root.AddCommand(createRunCommand())
+	root.SetOut(buf)
+	root.SetArgs([]string{"run", "-p", simpleSuite, "--targets", "prod=http://prod,canary=http://canary"})
+
+	err := root.Execute()
+	assert.Nil(t, err)
+	assert.Contains(t, buf.String(), "| Case | prod | canary |")
+	assert.Contains(t, buf.String(), "body diff")
+	assert.Contains(t, buf.String(), `-  "name": "a"`)
+
+	root = &cobra.Command{Use: "root"}
+	root.AddCommand(createRunCommand())
+	root.SetArgs([]string{"run", "-p", simpleSuite, "--targets", "prod"})
+	assert.NotNil(t, root.Execute())
+}
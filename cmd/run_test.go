@@ -4,12 +4,17 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"net"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/h2non/gock"
 	"github.com/linuxsuren/api-testing/pkg/limit"
+	atesting "github.com/linuxsuren/api-testing/pkg/testing"
 	"github.com/linuxsuren/api-testing/pkg/util"
 	fakeruntime "github.com/linuxsuren/go-fake-runtime"
 	"github.com/spf13/cobra"
@@ -41,6 +46,16 @@ func TestRunSuite(t *testing.T) {
 				Reply(http.StatusOK)
 		},
 		hasError: true,
+	}, {
+		name:      "with the suite parameters",
+		suiteFile: "testdata/param-suite.yaml",
+		prepare: func() {
+			gock.New(urlFoo).
+				Get("/bar").
+				Reply(http.StatusOK).
+				JSON("{}")
+		},
+		hasError: false,
 	}, {
 		name:      "not found file",
 		suiteFile: "testdata/fake.yaml",
@@ -62,6 +77,149 @@ func TestRunSuite(t *testing.T) {
 	}
 }
 
+func TestRunSuiteUnixSocket(t *testing.T) {
+	dir := t.TempDir()
+	socket := filepath.Join(dir, "app.sock")
+	lis, err := net.Listen("unix", socket)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer lis.Close()
+	go http.Serve(lis, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/health" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		w.Write([]byte("{}"))
+	}))
+
+	// the path of the test case is joined to the socket of the suite
+	suiteFile := filepath.Join(dir, "suite.yaml")
+	assert.Nil(t, os.WriteFile(suiteFile, []byte(`name: unix
+api: unix://`+socket+`
+items:
+- name: health
+  request:
+    api: /v1/health
+`), 0644))
+
+	opt := newDiskCardRunOption()
+	opt.requestTimeout = 30 * time.Second
+	opt.limiter = limit.NewDefaultRateLimiter(0, 0)
+	err = opt.runSuite(suiteFile, getDefaultContext(), context.TODO(), make(chan struct{}, 1))
+	assert.Nil(t, err)
+}
+
+func TestRunSuiteLearn(t *testing.T) {
+	data, err := os.ReadFile(simpleSuite)
+	assert.Nil(t, err)
+
+	for _, write := range []bool{false, true} {
+		suiteFile := filepath.Join(t.TempDir(), "suite.yaml")
+		assert.Nil(t, os.WriteFile(suiteFile, data, 0644))
+
+		gock.New(urlFoo).Get("/bar").Reply(http.StatusOK).JSON(`{"name": "linuxsuren"}`)
+
+		buf := new(bytes.Buffer)
+		opt := newDiskCardRunOption()
+		opt.requestTimeout = 30 * time.Second
+		opt.limiter = limit.NewDefaultRateLimiter(0, 0)
+		opt.learn = true
+		opt.learnWrite = write
+		opt.learnOutput = buf
+
+		err = opt.runSuite(suiteFile, getDefaultContext(), context.TODO(), make(chan struct{}, 1))
+		assert.Nil(t, err)
+		gock.Clean()
+
+		result, err := os.ReadFile(suiteFile)
+		assert.Nil(t, err)
+		if write {
+			assert.Contains(t, buf.String(), "wrote 1 inferred schemas")
+			assert.Contains(t, string(result), `"type": "string"`)
+		} else {
+			assert.Contains(t, buf.String(), "inferred schema of 'bar'")
+			assert.Equal(t, string(data), string(result))
+		}
+	}
+}
+
+func TestLackBodyExpectation(t *testing.T) {
+	assert.True(t, lackBodyExpectation(atesting.Response{StatusCode: http.StatusOK}))
+	assert.False(t, lackBodyExpectation(atesting.Response{Schema: "{}"}))
+	assert.False(t, lackBodyExpectation(atesting.Response{JSONPath: map[string]interface{}{"$.id": 1}}))
+	assert.False(t, lackBodyExpectation(atesting.Response{BodyContains: []string{"id"}}))
+	assert.False(t, lackBodyExpectation(atesting.Response{Arrays: map[string]*atesting.ArrayExpect{"$.items": {}}}))
+	assert.False(t, lackBodyExpectation(atesting.Response{Golden: "testdata/golden.json"}))
+}
+
+func TestInsertLearnedSchemas(t *testing.T) {
+	tests := []struct {
+		name   string
+		suite  string
+		expect string
+		hasErr bool
+	}{{
+		name: "without the expect",
+		suite: `# the comments are kept
+items:
+- name: foo
+  request:
+    api: /foo # the API
+`,
+		expect: `# the comments are kept
+items:
+- name: foo
+  expect:
+    schema: |
+      {
+        "type": "object"
+      }
+  request:
+    api: /foo # the API
+`,
+	}, {
+		name: "with the expect",
+		suite: `items:
+  - request:
+      api: /foo
+    name: foo
+    expect:
+      statusCode: 200
+  - name: bar
+`,
+		expect: `items:
+  - request:
+      api: /foo
+    name: foo
+    expect:
+      schema: |
+        {
+          "type": "object"
+        }
+      statusCode: 200
+  - name: bar
+`,
+	}, {
+		name: "flow expect",
+		suite: `items:
+- name: foo
+  expect: {statusCode: 200}
+`,
+		hasErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := insertLearnedSchemas([]byte(tt.suite), map[string]string{
+				"foo": "{\n  \"type\": \"object\"\n}",
+			})
+			assert.Equal(t, tt.hasErr, err != nil, err)
+			if !tt.hasErr {
+				assert.Equal(t, tt.expect, string(result))
+			}
+		})
+	}
+}
+
 func TestRunCommand(t *testing.T) {
 	fooPrepare := func() {
 		gock.New(urlFoo).Get("/bar").Reply(http.StatusOK).JSON("{}")
@@ -90,9 +248,21 @@ func TestRunCommand(t *testing.T) {
 		name:    "report ignore",
 		args:    []string{"-p", simpleSuite, "--report-ignore"},
 		prepare: fooPrepare,
+	}, {
+		name:    "with trace",
+		args:    []string{"-p", simpleSuite, "--trace", filepath.Join(os.TempDir(), "atest-trace.json")},
+		prepare: fooPrepare,
 	}, {
 		name: "specify a test case",
 		args: []string{"-p", simpleSuite, "fake"},
+	}, {
+		name: "abort since the stop condition is met",
+		args: []string{"-p", simpleSuite, "--duration", "1m", "--request-ignore-error",
+			"--stop-on", "errorRate>50%", "--stop-on-interval", "100ms"},
+		prepare: func() {
+			gock.New(urlFoo).Get("/bar").Persist().Reply(http.StatusInternalServerError)
+		},
+		hasErr: true,
 	}, {
 		name:   "invalid api",
 		args:   []string{"-p", "testdata/invalid-api.yaml"},
@@ -117,6 +287,47 @@ func TestRunCommand(t *testing.T) {
 	}
 }
 
+func TestRunWithTerraformReport(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func()
+		expect  string
+	}{{
+		name: "passed",
+		prepare: func() {
+			gock.New(urlFoo).Get("/bar").Reply(http.StatusOK).JSON("{}")
+		},
+		expect: `{"count":"1","error":"","errors":"0","passed":"true"}`,
+	}, {
+		name: "failed",
+		prepare: func() {
+			gock.New(urlFoo).Get("/bar").Reply(http.StatusInternalServerError)
+		},
+		expect: `"passed":"false"`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the persisted mocks of the other tests are not expected
+			gock.Flush()
+			defer gock.Clean()
+			tt.prepare()
+
+			stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+			root := &cobra.Command{Use: "root"}
+			root.AddCommand(createRunCommand())
+			root.SetOut(stdout)
+			root.SetErr(stderr)
+			root.SetArgs([]string{"run", "-p", simpleSuite, "--report", "terraform"})
+
+			err := root.Execute()
+			assert.Nil(t, err)
+			assert.Equal(t, 1, strings.Count(stdout.String(), "\n"), stdout.String())
+			assert.Contains(t, stdout.String(), tt.expect)
+			assert.Contains(t, stderr.String(), "consume:")
+		})
+	}
+}
+
 func TestRootCmd(t *testing.T) {
 	c := NewRootCmd(fakeruntime.FakeExecer{ExpectOS: "linux"}, NewFakeGRPCServer())
 	assert.NotNil(t, c)
@@ -173,6 +384,16 @@ func TestPreRunE(t *testing.T) {
 			assert.Nil(t, err)
 			assert.NotNil(t, ro.reportWriter)
 		},
+	}, {
+		name: "terraform report",
+		opt: &runOption{
+			report: "terraform",
+		},
+		verify: func(t *testing.T, ro *runOption, err error) {
+			assert.Nil(t, err)
+			assert.NotNil(t, ro.reportWriter)
+			assert.NotNil(t, ro.terraformOutput)
+		},
 	}, {
 		name: "invalid report",
 		opt: &runOption{
@@ -182,6 +403,45 @@ func TestPreRunE(t *testing.T) {
 			assert.NotNil(t, err)
 			assert.Nil(t, ro.reportWriter)
 		},
+	}, {
+		name: "learn with duration",
+		opt: &runOption{
+			learn:    true,
+			duration: time.Minute,
+		},
+		verify: func(t *testing.T, ro *runOption, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "stop condition",
+		opt: &runOption{
+			stopOn:         "p95>800ms || errorRate>5%",
+			stopOnInterval: time.Second,
+			duration:       time.Minute,
+		},
+		verify: func(t *testing.T, ro *runOption, err error) {
+			assert.Nil(t, err)
+			assert.NotNil(t, ro.stopCondition)
+		},
+	}, {
+		name: "stop condition without duration",
+		opt: &runOption{
+			stopOn:         "p95>800ms",
+			stopOnInterval: time.Second,
+		},
+		verify: func(t *testing.T, ro *runOption, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "invalid stop condition",
+		opt: &runOption{
+			stopOn:         "fake>1",
+			stopOnInterval: time.Second,
+			duration:       time.Minute,
+		},
+		verify: func(t *testing.T, ro *runOption, err error) {
+			assert.NotNil(t, err)
+		},
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -206,3 +466,44 @@ func TestPrinter(t *testing.T) {
 
 const urlFoo = "http://foo"
 const simpleSuite = "testdata/simple-suite.yaml"
+
+func TestRunSuiteWithIdentities(t *testing.T) {
+	defer gock.Off()
+
+	suiteFile := filepath.Join(t.TempDir(), "suite.yaml")
+	assert.Nil(t, os.WriteFile(suiteFile, []byte(`name: identity
+identities:
+- name: alice
+  provider: http
+  username: alice
+  create:
+    api: http://foo/users
+    body: '{"name": "{{.username}}"}'
+  delete:
+    api: http://foo/users/{{.id}}
+items:
+- name: me
+  request:
+    api: http://foo/me
+    header:
+      X-User: "{{.identity.alice.id}}"
+`), 0644))
+
+	gock.New(urlFoo).Post("/users").Reply(http.StatusCreated).JSON(`{"id": "1"}`)
+	gock.New(urlFoo).Get("/me").MatchHeader("X-User", "^1$").Reply(http.StatusOK).JSON("{}")
+	gock.New(urlFoo).Delete("/users/1").Reply(http.StatusNoContent)
+
+	opt := newDiskCardRunOption()
+	opt.requestTimeout = 30 * time.Second
+	opt.limiter = limit.NewDefaultRateLimiter(0, 0)
+	err := opt.runSuite(suiteFile, getDefaultContext(), context.TODO(), make(chan struct{}, 1))
+	assert.Nil(t, err)
+	assert.True(t, gock.IsDone())
+
+	// failed to delete the identity
+	gock.New(urlFoo).Post("/users").Reply(http.StatusCreated).JSON(`{"id": "1"}`)
+	gock.New(urlFoo).Get("/me").Reply(http.StatusOK).JSON("{}")
+	gock.New(urlFoo).Delete("/users/1").Reply(http.StatusInternalServerError)
+	err = opt.runSuite(suiteFile, getDefaultContext(), context.TODO(), make(chan struct{}, 1))
+	assert.Contains(t, err.Error(), "failed to delete the identities")
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/h2non/gock"
+	"github.com/linuxsuren/api-testing/pkg/limit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunSuiteWithTunnel(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake agent is a shell script")
+	}
+	defer gock.Off()
+
+	dir := t.TempDir()
+	agent := filepath.Join(dir, "ngrok")
+	assert.Nil(t, os.WriteFile(agent, []byte(`#!/bin/sh
+echo '{"msg":"started tunnel","url":"https://abc.ngrok.app"}'
+sleep 30
+`), 0755))
+
+	suiteFile := filepath.Join(dir, "suite.yaml")
+	assert.Nil(t, os.WriteFile(suiteFile, []byte(`name: tunnel
+api: http://foo
+tunnel:
+  provider: ngrok
+  port: 9090
+  command: `+agent+`
+items:
+- name: subscribe
+  request:
+    api: /subscriptions
+    method: POST
+    body: '{"callback": "{{.tunnel.url}}/callback"}'
+`), 0644))
+
+	opt := newDiskCardRunOption()
+	opt.requestTimeout = 30 * time.Second
+	opt.limiter = limit.NewDefaultRateLimiter(0, 0)
+
+	gock.New(urlFoo).Post("/subscriptions").BodyString(`{"callback": "https://abc.ngrok.app/callback"}`).
+		Reply(http.StatusOK).JSON(`{}`)
+	err := opt.runSuite(suiteFile, getDefaultContext(), context.TODO(), make(chan struct{}, 1))
+	assert.Nil(t, err)
+	assert.True(t, gock.IsDone())
+}
@@ -2,13 +2,17 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net"
+	"net/http"
+	"time"
 
 	"github.com/linuxsuren/api-testing/pkg/server"
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
 )
 
 func createServerCmd(gRPCServer gRPCServer) (c *cobra.Command) {
@@ -16,18 +20,45 @@ func createServerCmd(gRPCServer gRPCServer) (c *cobra.Command) {
 	c = &cobra.Command{
 		Use:   "server",
 		Short: "Run as a server mode",
-		RunE:  opt.runE,
+		Example: `atest server --port 7070
+atest server --keepalive-time 30s --max-recv-msg-size 16777216 --call-timeout 5m
+atest server --http-port 8080`,
+		RunE: opt.runE,
 	}
 	flags := c.Flags()
 	flags.IntVarP(&opt.port, "port", "p", 7070, "The RPC server port")
+	flags.IntVarP(&opt.httpPort, "http-port", "", -1,
+		"The HTTP server port of the REST routes, such as POST /runs, the HTTP server is disabled if it's negative")
 	flags.BoolVarP(&opt.printProto, "print-proto", "", false, "Print the proto content and exit")
+	flags.DurationVarP(&opt.keepaliveTime, "keepalive-time", "", 0,
+		"The interval of pinging the idle clients, use the gRPC default value if it's zero")
+	flags.DurationVarP(&opt.keepaliveTimeout, "keepalive-timeout", "", 0,
+		"The timeout of waiting for the ping ack, use the gRPC default value if it's zero")
+	flags.DurationVarP(&opt.keepaliveMinTime, "keepalive-min-time", "", 0,
+		"The minimum interval of the client pings, use the gRPC default value if it's zero")
+	flags.IntVarP(&opt.maxRecvMsgSize, "max-recv-msg-size", "", 0,
+		"The max message size in bytes the server can receive, use the gRPC default value (4MB) if it's zero")
+	flags.IntVarP(&opt.maxSendMsgSize, "max-send-msg-size", "", 0,
+		"The max message size in bytes the server can send, use the gRPC default value if it's zero")
+	flags.DurationVarP(&opt.callTimeout, "call-timeout", "", 0,
+		"The deadline of each call, there is no deadline if it's zero")
+	flags.BoolVarP(&opt.allowExec, "allow-exec", "", false,
+		"Allow the test cases which run the local commands, such as the exec request, signer and verifier")
 	return
 }
 
 type serverOption struct {
-	gRPCServer gRPCServer
-	port       int
-	printProto bool
+	gRPCServer       gRPCServer
+	port             int
+	httpPort         int
+	printProto       bool
+	keepaliveTime    time.Duration
+	keepaliveTimeout time.Duration
+	keepaliveMinTime time.Duration
+	maxRecvMsgSize   int
+	maxSendMsgSize   int
+	callTimeout      time.Duration
+	allowExec        bool
 }
 
 func (o *serverOption) runE(cmd *cobra.Command, args []string) (err error) {
@@ -45,12 +76,64 @@ func (o *serverOption) runE(cmd *cobra.Command, args []string) (err error) {
 	}
 
 	s := o.gRPCServer
-	server.RegisterRunnerServer(s, server.NewRemoteServer())
+	if s == nil {
+		s = grpc.NewServer(o.getServerOptions()...)
+	}
+	remoteServer := server.NewRemoteServer(o.allowExec)
+	server.RegisterRunnerServer(s, remoteServer)
+
+	if o.httpPort >= 0 {
+		var httpLis net.Listener
+		if httpLis, err = net.Listen("tcp", fmt.Sprintf(":%d", o.httpPort)); err != nil {
+			lis.Close()
+			return
+		}
+		httpServer := &http.Server{Handler: server.NewHTTPHandler(remoteServer)}
+		defer httpServer.Close()
+		log.Printf("HTTP server listening at %v", httpLis.Addr())
+		go httpServer.Serve(httpLis)
+	}
 	log.Printf("server listening at %v", lis.Addr())
 	s.Serve(lis)
 	return
 }
 
+// getServerOptions returns the gRPC server options according to the flags
+func (o *serverOption) getServerOptions() (opts []grpc.ServerOption) {
+	if o.keepaliveTime > 0 || o.keepaliveTimeout > 0 {
+		opts = append(opts, grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:    o.keepaliveTime,
+			Timeout: o.keepaliveTimeout,
+		}))
+	}
+	if o.keepaliveMinTime > 0 {
+		opts = append(opts, grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             o.keepaliveMinTime,
+			PermitWithoutStream: true,
+		}))
+	}
+	if o.maxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(o.maxRecvMsgSize))
+	}
+	if o.maxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(o.maxSendMsgSize))
+	}
+	if o.callTimeout > 0 {
+		opts = append(opts, grpc.UnaryInterceptor(deadlineInterceptor(o.callTimeout)))
+	}
+	return
+}
+
+// deadlineInterceptor sets the deadline of each call if the client does not set a shorter one
+func deadlineInterceptor(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (resp interface{}, err error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
 type gRPCServer interface {
 	Serve(lis net.Listener) error
 	grpc.ServiceRegistrar
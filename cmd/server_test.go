@@ -2,11 +2,14 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"strings"
 	"testing"
+	"time"
 
 	fakeruntime "github.com/linuxsuren/go-fake-runtime"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
 )
 
 func TestPrintProto(t *testing.T) {
@@ -45,3 +48,29 @@ func TestPrintProto(t *testing.T) {
 		})
 	}
 }
+
+func TestGetServerOptions(t *testing.T) {
+	opt := &serverOption{}
+	assert.Empty(t, opt.getServerOptions())
+
+	opt = &serverOption{
+		keepaliveTime:    time.Second,
+		keepaliveMinTime: time.Second,
+		maxRecvMsgSize:   1024,
+		maxSendMsgSize:   1024,
+		callTimeout:      time.Second,
+	}
+	assert.Equal(t, 5, len(opt.getServerOptions()))
+}
+
+func TestDeadlineInterceptor(t *testing.T) {
+	interceptor := deadlineInterceptor(time.Minute)
+	_, err := interceptor(context.TODO(), nil, &grpc.UnaryServerInfo{},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			deadline, ok := ctx.Deadline()
+			assert.True(t, ok)
+			assert.True(t, time.Until(deadline) <= time.Minute)
+			return nil, nil
+		})
+	assert.Nil(t, err)
+}
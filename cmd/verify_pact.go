@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/linuxsuren/api-testing/pkg/generator"
+	"github.com/linuxsuren/api-testing/pkg/runner"
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/spf13/cobra"
+)
+
+type verifyPactOption struct {
+	pact           string
+	base           string
+	requestTimeout time.Duration
+	level          string
+}
+
+func createVerifyPactCmd() (c *cobra.Command) {
+	opt := &verifyPactOption{}
+	c = &cobra.Command{
+		Use:     "verify-pact",
+		Short:   "Verify the provider against the interactions of a Pact file",
+		Example: `atest verify-pact --pact consumer.json --base https://provider`,
+		RunE:    opt.runE,
+	}
+
+	flags := c.Flags()
+	flags.StringVarP(&opt.pact, "pact", "", "", "The Pact file path")
+	flags.StringVarP(&opt.base, "base", "", "", "The base address of the provider")
+	flags.DurationVarP(&opt.requestTimeout, "request-timeout", "", time.Minute, "Timeout for per request")
+	flags.StringVarP(&opt.level, "level", "l", "info", "Set the output log level")
+	_ = c.MarkFlagRequired("pact")
+	_ = c.MarkFlagRequired("base")
+	return
+}
+
+func (o *verifyPactOption) runE(cmd *cobra.Command, args []string) (err error) {
+	var data []byte
+	if data, err = os.ReadFile(o.pact); err != nil {
+		return
+	}
+
+	var importer generator.TestSuiteImporter
+	if importer, err = generator.GetTestSuiteImporter("pact"); err != nil {
+		return
+	}
+
+	var suite *testing.TestSuite
+	if suite, err = convertWithWarnings(cmd, importer, data); err != nil {
+		return
+	}
+
+	base := strings.TrimSuffix(o.base, "/")
+	failed := 0
+	for _, testCase := range suite.Items {
+		testCase.Request.API = base + testCase.Request.API
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), o.requestTimeout)
+		simpleRunner := runner.NewSimpleTestCaseRunner().
+			WithOutputWriter(cmd.OutOrStdout()).
+			WithWriteLevel(o.level)
+		_, runErr := simpleRunner.RunTestCase(&testCase, map[string]interface{}{}, ctx)
+		cancel()
+
+		if runErr == nil {
+			cmd.Printf("PASS: %s\n", testCase.Name)
+		} else {
+			failed++
+			cmd.Printf("FAIL: %s, %v\n", testCase.Name, runErr)
+		}
+	}
+
+	cmd.Printf("verified %d interactions of %s, %d failed\n", len(suite.Items), suite.Name, failed)
+	if failed > 0 {
+		err = fmt.Errorf("%d interactions failed to verify", failed)
+	}
+	return
+}
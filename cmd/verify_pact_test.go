@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/h2non/gock"
+	"github.com/linuxsuren/api-testing/pkg/util"
+	fakeruntime "github.com/linuxsuren/go-fake-runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyPactCmd(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		prepare func()
+		verify  func(*testing.T, string, error)
+	}{{
+		name: "all interactions passed",
+		args: []string{"--pact", "testdata/pact.json", "--base", urlFoo},
+		prepare: func() {
+			gock.New(urlFoo).Get("/users/1").MatchParam("fields", "name").
+				Reply(http.StatusOK).
+				JSON(`{"id":1,"name":"linuxsuren","profile":{"age":18,"email":"a@b.com"}}`)
+			gock.New(urlFoo).Post("/users").
+				Reply(http.StatusCreated).SetHeader("Location", "/users/2").JSON(`{"id":3}`)
+		},
+		verify: func(t *testing.T, output string, err error) {
+			assert.Nil(t, err)
+			assert.Contains(t, output, "PASS: get user")
+			assert.Contains(t, output, "PASS: create user")
+			assert.Contains(t, output, "warning: the provider states of 'get user' should be set up before running it: user 1 exists")
+		},
+	}, {
+		name: "some interactions failed",
+		args: []string{"--pact", "testdata/pact.json", "--base", urlFoo},
+		prepare: func() {
+			gock.New(urlFoo).Get("/users/1").
+				Reply(http.StatusOK).JSON(`{"id":1,"name":"rick"}`)
+			gock.New(urlFoo).Post("/users").
+				Reply(http.StatusCreated).SetHeader("Location", "/users/2").JSON(`{"id":2}`)
+		},
+		verify: func(t *testing.T, output string, err error) {
+			assert.NotNil(t, err)
+			assert.Contains(t, output, "FAIL: get user")
+		},
+	}, {
+		name: "pact file not found",
+		args: []string{"--pact", "testdata/fake.json", "--base", urlFoo},
+		verify: func(t *testing.T, output string, err error) {
+			assert.NotNil(t, err)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer gock.Clean()
+			util.MakeSureNotNil(tt.prepare)()
+
+			buf := new(bytes.Buffer)
+			root := NewRootCmd(fakeruntime.FakeExecer{ExpectOS: "linux"}, NewFakeGRPCServer())
+			root.SetOut(buf)
+			root.SetErr(buf)
+			root.SetArgs(append([]string{"verify-pact"}, tt.args...))
+			err := root.Execute()
+			tt.verify(t, buf.String(), err)
+		})
+	}
+}
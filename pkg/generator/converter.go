@@ -0,0 +1,38 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+// TestSuiteConverter converts a test suite to another format
+type TestSuiteConverter interface {
+	Convert(*testing.TestSuite) (string, error)
+}
+
+var converters = map[string]TestSuiteConverter{}
+
+// RegisterTestSuiteConverter registers a converter with the format name
+func RegisterTestSuiteConverter(format string, converter TestSuiteConverter) {
+	converters[format] = converter
+}
+
+// GetTestSuiteConverter returns the converter of the format
+func GetTestSuiteConverter(format string) (converter TestSuiteConverter, err error) {
+	var ok bool
+	if converter, ok = converters[format]; !ok {
+		err = fmt.Errorf("not supported format: '%s', supported: %v", format, GetTestSuiteConverterNames())
+	}
+	return
+}
+
+// GetTestSuiteConverterNames returns all the supported format names
+func GetTestSuiteConverterNames() (names []string) {
+	for name := range converters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
@@ -0,0 +1,19 @@
+package generator_test
+
+import (
+	"testing"
+
+	"github.com/linuxsuren/api-testing/pkg/generator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTestSuiteConverter(t *testing.T) {
+	converter, err := generator.GetTestSuiteConverter("wiremock")
+	assert.Nil(t, err)
+	assert.NotNil(t, converter)
+
+	_, err = generator.GetTestSuiteConverter("fake")
+	assert.NotNil(t, err)
+
+	assert.Contains(t, generator.GetTestSuiteConverterNames(), "wiremock")
+}
@@ -0,0 +1,342 @@
+package generator
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/linuxsuren/api-testing/pkg/util"
+)
+
+type curlImporter struct{}
+
+// NewCurlImporter creates an importer which converts the curl commands to a test suite,
+// each line (the backslash continuations are supported) is a curl command
+func NewCurlImporter() TestSuiteImporter {
+	return &curlImporter{}
+}
+
+// Convert converts the curl commands to be test cases
+func (c *curlImporter) Convert(data []byte) (suite *testing.TestSuite, err error) {
+	var commands [][]string
+	if commands, err = splitShellCommands(string(data)); err != nil {
+		return
+	}
+
+	suite = &testing.TestSuite{
+		Name:  "curl",
+		Items: []testing.TestCase{},
+	}
+	for _, command := range commands {
+		var testCase testing.TestCase
+		if testCase, err = curlToTestCase(command); err != nil {
+			return
+		}
+		suite.Items = append(suite.Items, testCase)
+	}
+
+	if len(suite.Items) == 0 {
+		err = fmt.Errorf("no curl command found")
+	}
+	uniqueNames(suite)
+	return
+}
+
+func curlToTestCase(args []string) (testCase testing.TestCase, err error) {
+	if len(args) == 0 || args[0] != "curl" {
+		err = fmt.Errorf("not a curl command: %s", strings.Join(args, " "))
+		return
+	}
+
+	request := &testCase.Request
+	var data []string
+	var head, get bool
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+
+		name, val, hasVal := arg, "", false
+		if strings.HasPrefix(arg, "--") {
+			if index := strings.Index(arg, "="); index > 0 {
+				name, val, hasVal = arg[:index], arg[index+1:], true
+			}
+		} else if strings.HasPrefix(arg, "-") && len(arg) > 2 && curlShortOptionsWithValue[arg[:2]] {
+			// the value could be next to the short option, such as: -XPOST
+			name, val, hasVal = arg[:2], arg[2:], true
+		}
+
+		if curlOptionsWithValue[name] && !hasVal {
+			if i+1 >= len(args) {
+				err = fmt.Errorf("option %s requires a value", name)
+				return
+			}
+			i++
+			val = args[i]
+		}
+
+		switch name {
+		case "-X", "--request":
+			request.Method = strings.ToUpper(val)
+		case "-H", "--header":
+			if key, value, ok := strings.Cut(val, ":"); ok {
+				request.Header = util.MakeSureNotNil(request.Header)
+				request.Header[strings.TrimSpace(key)] = strings.TrimSpace(value)
+			}
+		case "-d", "--data", "--data-ascii", "--data-binary":
+			if val, err = readCurlData(val, name == "--data-binary"); err != nil {
+				return
+			}
+			data = append(data, val)
+		case "--data-raw":
+			data = append(data, val)
+		case "--data-urlencode":
+			if data, err = appendURLEncodedData(data, val); err != nil {
+				return
+			}
+		case "--json":
+			if val, err = readCurlData(val, true); err != nil {
+				return
+			}
+			data = append(data, val)
+			request.Header = util.MakeSureNotNil(request.Header)
+			request.Header[util.ContentType] = "application/json"
+			request.Header["Accept"] = "application/json"
+		case "-F", "--form", "--form-string":
+			if key, value, ok := strings.Cut(val, "="); ok {
+				request.Form = util.MakeSureNotNil(request.Form)
+				request.Form[key] = value
+			}
+		case "-u", "--user":
+			request.Header = util.MakeSureNotNil(request.Header)
+			request.Header["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(val))
+		case "-A", "--user-agent":
+			request.Header = util.MakeSureNotNil(request.Header)
+			request.Header["User-Agent"] = val
+		case "-b", "--cookie":
+			request.Header = util.MakeSureNotNil(request.Header)
+			request.Header["Cookie"] = val
+		case "-e", "--referer":
+			request.Header = util.MakeSureNotNil(request.Header)
+			request.Header["Referer"] = val
+		case "-I", "--head":
+			head = true
+		case "-G", "--get":
+			get = true
+		case "--url":
+			request.API = val
+		default:
+			if strings.HasPrefix(arg, "-") {
+				// ignore the options which don't affect the request, such as: -k, -s, -L, --compressed
+				continue
+			}
+			request.API = arg
+		}
+	}
+
+	if request.API == "" {
+		err = fmt.Errorf("the URL is missing in the curl command")
+		return
+	}
+
+	if len(request.Form) > 0 {
+		request.Header = util.MakeSureNotNil(request.Header)
+		request.Header[util.ContentType] = util.MultiPartFormData
+	}
+
+	if len(data) > 0 {
+		if get {
+			request.API = appendQuery(request.API, strings.Join(data, "&"))
+		} else {
+			request.Header = util.MakeSureNotNil(request.Header)
+			contentType, ok := request.Header[util.ContentType]
+			if !ok {
+				contentType = util.Form
+				request.Header[util.ContentType] = contentType
+			}
+
+			// only the form fields are separated by '&', the others (such as the JSON parts) are concatenated
+			separator := ""
+			if strings.HasPrefix(contentType, util.Form) {
+				separator = "&"
+			}
+			request.Body = strings.Join(data, separator)
+		}
+	}
+
+	switch {
+	case request.Method != "":
+	case head:
+		request.Method = http.MethodHead
+	case !get && (len(data) > 0 || len(request.Form) > 0):
+		request.Method = http.MethodPost
+	default:
+		request.Method = http.MethodGet
+	}
+
+	testCase.Name = request.Method + " " + request.API
+	if u, parseErr := url.Parse(request.API); parseErr == nil && u.Path != "" {
+		testCase.Name = request.Method + " " + u.Path
+	}
+	return
+}
+
+// readCurlData returns the data of the option, it's read from the file if it starts with '@'.
+// The carriage returns and newlines of the file are stripped unless it's binary, the same as curl
+func readCurlData(val string, binary bool) (data string, err error) {
+	if !strings.HasPrefix(val, "@") {
+		data = val
+		return
+	}
+
+	var content []byte
+	if content, err = readCurlFile(val[1:]); err == nil {
+		data = string(content)
+		if !binary {
+			data = strings.NewReplacer("\r", "", "\n", "").Replace(data)
+		}
+	}
+	return
+}
+
+// appendURLEncodedData appends the data of --data-urlencode, the formats are:
+// content, =content, name=content, @file and name@file
+func appendURLEncodedData(data []string, val string) (result []string, err error) {
+	var name, content string
+	if index := strings.IndexAny(val, "=@"); index < 0 {
+		content = val
+	} else if val[index] == '=' {
+		name, content = val[:index], val[index+1:]
+	} else {
+		var file []byte
+		if file, err = readCurlFile(val[index+1:]); err != nil {
+			return
+		}
+		name, content = val[:index], string(file)
+	}
+
+	if name != "" {
+		result = append(data, name+"="+url.QueryEscape(content))
+	} else {
+		result = append(data, url.QueryEscape(content))
+	}
+	return
+}
+
+func readCurlFile(name string) (data []byte, err error) {
+	if name == "-" {
+		err = fmt.Errorf("reading the data from stdin is not supported")
+		return
+	}
+	if data, err = os.ReadFile(name); err != nil {
+		err = fmt.Errorf("failed to read the data file %s, %v", name, err)
+	}
+	return
+}
+
+var curlOptionsWithValue = map[string]bool{
+	"-X": true, "--request": true,
+	"-H": true, "--header": true,
+	"-d": true, "--data": true, "--data-raw": true, "--data-binary": true, "--data-ascii": true,
+	"--data-urlencode": true, "--json": true,
+	"-F": true, "--form": true, "--form-string": true,
+	"-u": true, "--user": true,
+	"-A": true, "--user-agent": true,
+	"-b": true, "--cookie": true,
+	"-e": true, "--referer": true,
+	"-o": true, "--output": true,
+	"-m": true, "--max-time": true, "--connect-timeout": true,
+	"-x": true, "--proxy": true,
+	"--url": true, "--cacert": true, "--cert": true, "--key": true,
+}
+
+var curlShortOptionsWithValue = map[string]bool{
+	"-X": true, "-H": true, "-d": true, "-F": true, "-u": true, "-A": true,
+	"-b": true, "-e": true, "-o": true, "-m": true, "-x": true,
+}
+
+// splitShellCommands splits the text into commands and their arguments like a shell does,
+// the quotes, escapes, line continuations and comments are supported
+func splitShellCommands(text string) (commands [][]string, err error) {
+	var args []string
+	var current strings.Builder
+	var inWord bool
+	var quote rune
+
+	endWord := func() {
+		if inWord {
+			args = append(args, current.String())
+			current.Reset()
+			inWord = false
+		}
+	}
+	endCommand := func() {
+		endWord()
+		if len(args) > 0 {
+			commands = append(commands, args)
+			args = nil
+		}
+	}
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		char := runes[i]
+		switch {
+		case quote == '\'':
+			if char == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(char)
+			}
+		case quote == '"':
+			if char == '"' {
+				quote = 0
+			} else if char == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`\n", runes[i+1]) {
+				i++
+				if runes[i] != '\n' {
+					current.WriteRune(runes[i])
+				}
+			} else {
+				current.WriteRune(char)
+			}
+		case char == '\'' || char == '"':
+			quote = char
+			inWord = true
+		case char == '\\':
+			if i+1 < len(runes) {
+				i++
+				if runes[i] == '\r' && i+1 < len(runes) && runes[i+1] == '\n' {
+					i++
+				}
+				if runes[i] != '\n' {
+					current.WriteRune(runes[i])
+					inWord = true
+				}
+			}
+		case char == '#' && !inWord:
+			for i+1 < len(runes) && runes[i+1] != '\n' {
+				i++
+			}
+		case char == '\n' || char == ';':
+			endCommand()
+		case char == ' ' || char == '\t' || char == '\r':
+			endWord()
+		default:
+			current.WriteRune(char)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		err = fmt.Errorf("unterminated quote %q", quote)
+		return
+	}
+	endCommand()
+	return
+}
+
+func init() {
+	RegisterTestSuiteImporter("curl", NewCurlImporter())
+}
@@ -0,0 +1,31 @@
+// generated by atest from the test suite: {{.Suite.Name}}
+import http from 'k6/http';
+import { check, group } from 'k6';
+
+export const options = {
+  thresholds: {
+    checks: ['rate==1.0'],
+    http_req_failed: ['rate<0.01'],
+  },
+};
+
+const param = {{toJS .Param}};
+
+export default function () {
+{{- range .Requests}}
+  group({{toJS .Name}}, function () {
+    const res = http.request({{toJS .Method}}, {{.URL}}, {{.Body}}, {
+      headers: {
+{{- range $key, $val := .Header}}
+        {{toJS $key}}: {{$val}},
+{{- end}}
+      },
+    });
+    check(res, {
+{{- range .Checks}}
+      {{toJS .Name}}: (r) => {{.Expression}},
+{{- end}}
+    });
+  });
+{{- end}}
+}
@@ -0,0 +1,2 @@
+// Package generator provides the converters between the test suite and other formats
+package generator
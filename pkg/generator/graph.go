@@ -0,0 +1,124 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+// CaseDependency represents a test case consumes the output of another one
+type CaseDependency struct {
+	From   string
+	To     string
+	Fields []string
+}
+
+var (
+	templateActionReg = regexp.MustCompile(`\{\{(.*?)\}\}`)
+	fieldReferenceReg = regexp.MustCompile(`(?:^|[^\w.)\]])\.([A-Za-z_]\w*)((?:\.[A-Za-z_]\w*)*)`)
+	indexReferenceReg = regexp.MustCompile(`index\s+\.\s+"([^"]+)"`)
+)
+
+// GetCaseDependencies returns the data-flow dependencies between the test cases,
+// a test case depends on the previous ones whose outputs are referenced in its request templates
+func GetCaseDependencies(suite *testing.TestSuite) (dependencies []CaseDependency) {
+	known := map[string]bool{}
+	for _, testCase := range suite.Items {
+		fields := map[string]map[string]bool{}
+		for _, text := range requestTemplates(testCase.Request) {
+			for _, action := range templateActionReg.FindAllStringSubmatch(text, -1) {
+				for _, ref := range fieldReferenceReg.FindAllStringSubmatch(action[1], -1) {
+					addReference(fields, known, ref[1], strings.TrimPrefix(ref[2], "."))
+				}
+				for _, ref := range indexReferenceReg.FindAllStringSubmatch(action[1], -1) {
+					addReference(fields, known, ref[1], "")
+				}
+			}
+		}
+
+		var froms []string
+		for from := range fields {
+			froms = append(froms, from)
+		}
+		sort.Strings(froms)
+		for _, from := range froms {
+			dependency := CaseDependency{From: from, To: testCase.Name}
+			for field := range fields[from] {
+				if field != "" {
+					dependency.Fields = append(dependency.Fields, field)
+				}
+			}
+			sort.Strings(dependency.Fields)
+			dependencies = append(dependencies, dependency)
+		}
+		known[testCase.Name] = true
+	}
+	return
+}
+
+func addReference(fields map[string]map[string]bool, known map[string]bool, name, field string) {
+	if !known[name] {
+		return
+	}
+	if fields[name] == nil {
+		fields[name] = map[string]bool{}
+	}
+	fields[name][field] = true
+}
+
+func requestTemplates(request testing.Request) (texts []string) {
+	texts = append(texts, request.API, request.Body)
+	for _, key := range sortedKeys(request.Header) {
+		texts = append(texts, request.Header[key])
+	}
+	for _, key := range sortedKeys(request.Form) {
+		texts = append(texts, request.Form[key])
+	}
+	return
+}
+
+// ToDotGraph outputs the dependencies between the test cases as a Graphviz graph
+func ToDotGraph(suite *testing.TestSuite) string {
+	buf := new(strings.Builder)
+	fmt.Fprintf(buf, "digraph %s {\n", toJS(suite.Name))
+	buf.WriteString("  rankdir=LR;\n")
+	for _, testCase := range suite.Items {
+		fmt.Fprintf(buf, "  %s;\n", toJS(testCase.Name))
+	}
+	for _, dependency := range GetCaseDependencies(suite) {
+		fmt.Fprintf(buf, "  %s -> %s", toJS(dependency.From), toJS(dependency.To))
+		if len(dependency.Fields) > 0 {
+			fmt.Fprintf(buf, " [label=%s]", toJS(strings.Join(dependency.Fields, ", ")))
+		}
+		buf.WriteString(";\n")
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+// ToMermaidGraph outputs the dependencies between the test cases as a Mermaid flowchart
+func ToMermaidGraph(suite *testing.TestSuite) string {
+	ids := map[string]string{}
+	buf := new(strings.Builder)
+	buf.WriteString("graph LR\n")
+	for i, testCase := range suite.Items {
+		ids[testCase.Name] = fmt.Sprintf("case%d", i)
+		fmt.Fprintf(buf, "  %s[%s]\n", ids[testCase.Name], mermaidText(testCase.Name))
+	}
+	for _, dependency := range GetCaseDependencies(suite) {
+		if len(dependency.Fields) > 0 {
+			fmt.Fprintf(buf, "  %s -->|%s| %s\n", ids[dependency.From],
+				mermaidText(strings.Join(dependency.Fields, ", ")), ids[dependency.To])
+		} else {
+			fmt.Fprintf(buf, "  %s --> %s\n", ids[dependency.From], ids[dependency.To])
+		}
+	}
+	return buf.String()
+}
+
+func mermaidText(text string) string {
+	return `"` + strings.ReplaceAll(text, `"`, "#quot;") + `"`
+}
@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaseGraph(t *testing.T) {
+	suite := &atest.TestSuite{
+		Name: "gitlab",
+		Items: []atest.TestCase{{
+			Name: "login",
+			Request: atest.Request{
+				API:  "/login",
+				Body: `{"user": "{{env "USER"}}"}`,
+			},
+		}, {
+			Name: "projects",
+			Request: atest.Request{
+				API: "/projects?user={{.param.user}}",
+				Header: map[string]string{
+					"Authorization": "Bearer {{.login.token}}",
+				},
+			},
+		}, {
+			Name: "project",
+			Request: atest.Request{
+				API: "/projects/{{(index .projects 0).id}}/{{.login.user.name}}",
+				Header: map[string]string{
+					"Authorization": `Bearer {{.login.token}}`,
+				},
+			},
+		}, {
+			Name: "with space",
+			Request: atest.Request{
+				API: `/projects/{{(index . "project").id}}`,
+			},
+		}},
+	}
+
+	assert.Equal(t, []CaseDependency{
+		{From: "login", To: "projects", Fields: []string{"token"}},
+		{From: "login", To: "project", Fields: []string{"token", "user.name"}},
+		{From: "projects", To: "project"},
+		{From: "project", To: "with space"},
+	}, GetCaseDependencies(suite))
+
+	assert.Equal(t, `digraph "gitlab" {
+  rankdir=LR;
+  "login";
+  "projects";
+  "project";
+  "with space";
+  "login" -> "projects" [label="token"];
+  "login" -> "project" [label="token, user.name"];
+  "projects" -> "project";
+  "project" -> "with space";
+}
+`, ToDotGraph(suite))
+
+	assert.Equal(t, `graph LR
+  case0["login"]
+  case1["projects"]
+  case2["project"]
+  case3["with space"]
+  case0 -->|"token"| case1
+  case0 -->|"token, user.name"| case2
+  case1 --> case2
+  case2 --> case3
+`, ToMermaidGraph(suite))
+}
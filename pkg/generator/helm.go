@@ -0,0 +1,76 @@
+package generator
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"text/template"
+
+	_ "embed"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+// helmTestImage is the image which runs the test suite in the cluster
+const helmTestImage = "ghcr.io/linuxsuren/api-testing:latest"
+
+type helmTestConverter struct{}
+
+// NewHelmTestConverter creates a converter which outputs the Helm test hook,
+// it's a ConfigMap of the test suite and a Pod which runs the suite
+func NewHelmTestConverter() TestSuiteConverter {
+	return &helmTestConverter{}
+}
+
+// Convert converts the test suite to be the manifests of the Helm test hook
+func (c *helmTestConverter) Convert(suite *testing.TestSuite) (result string, err error) {
+	var content string
+	if content, err = ToYAML(suite); err != nil {
+		return
+	}
+
+	// the templates of the suite are escaped, they're rendered by atest instead of Helm
+	content = strings.NewReplacer("{{", `{{"{{"}}`, "}}", `{{"}}"}}`).Replace(strings.TrimRight(content, "\n"))
+	lines := strings.Split(content, "\n")
+	for i := range lines {
+		lines[i] = "    " + lines[i]
+	}
+
+	// the delimiters differ from the Helm ones
+	var tpl *template.Template
+	if tpl, err = template.New("helm-test").Delims("[[", "]]").Parse(helmTestManifests); err != nil {
+		return
+	}
+
+	buf := new(bytes.Buffer)
+	if err = tpl.Execute(buf, map[string]interface{}{
+		"Suite":   suite,
+		"Name":    toResourceName(suite.Name),
+		"Image":   helmTestImage,
+		"Content": strings.Join(lines, "\n"),
+	}); err == nil {
+		result = buf.String()
+	}
+	return
+}
+
+var invalidResourceNameReg = regexp.MustCompile(`[^a-z0-9]+`)
+
+// toResourceName converts the name to be a part of the Kubernetes resource name
+func toResourceName(name string) string {
+	name = strings.Trim(invalidResourceNameReg.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if len(name) > 40 {
+		name = strings.TrimRight(name[:40], "-")
+	}
+	if name == "" {
+		name = "suite"
+	}
+	return name
+}
+
+//go:embed data/helm-test.yaml
+var helmTestManifests string
+
+func init() {
+	RegisterTestSuiteConverter("helm-test", NewHelmTestConverter())
+}
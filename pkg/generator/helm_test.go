@@ -0,0 +1,41 @@
+package generator
+
+import (
+	"net/http"
+	"testing"
+
+	_ "embed"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHelmTestConverter(t *testing.T) {
+	suite := &atest.TestSuite{
+		Name: "Users API",
+		API:  `http://{{env "RELEASE_NAME"}}-web:8080`,
+		Items: []atest.TestCase{{
+			Name: "list",
+			Request: atest.Request{
+				API: "/users",
+			},
+			Expect: atest.Response{
+				StatusCode: http.StatusOK,
+			},
+		}},
+	}
+
+	result, err := NewHelmTestConverter().Convert(suite)
+	assert.Nil(t, err)
+	assert.Equal(t, expectedHelmTest, result)
+}
+
+func TestToResourceName(t *testing.T) {
+	assert.Equal(t, "users-api", toResourceName("Users API"))
+	assert.Equal(t, "a-b", toResourceName("--a_b--"))
+	assert.Equal(t, "suite", toResourceName("中文"))
+	assert.Equal(t, 40, len(toResourceName("abcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyz")))
+}
+
+//go:embed testdata/helm-test.yaml
+var expectedHelmTest string
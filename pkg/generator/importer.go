@@ -0,0 +1,97 @@
+package generator
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+// TestSuiteImporter converts the data of another format to a test suite
+type TestSuiteImporter interface {
+	Convert(data []byte) (*testing.TestSuite, error)
+}
+
+// TestSuiteImporterWithWarnings reports the parts of the data which could not be converted
+type TestSuiteImporterWithWarnings interface {
+	ConvertWithWarnings(data []byte) (*testing.TestSuite, []string, error)
+}
+
+var importers = map[string]TestSuiteImporter{}
+
+// RegisterTestSuiteImporter registers an importer with the format name
+func RegisterTestSuiteImporter(format string, importer TestSuiteImporter) {
+	importers[format] = importer
+}
+
+// GetTestSuiteImporter returns the importer of the format
+func GetTestSuiteImporter(format string) (importer TestSuiteImporter, err error) {
+	var ok bool
+	if importer, ok = importers[format]; !ok {
+		err = fmt.Errorf("not supported format: '%s', supported: %v", format, GetTestSuiteImporterNames())
+	}
+	return
+}
+
+// GetTestSuiteImporterNames returns all the supported format names
+func GetTestSuiteImporterNames() (names []string) {
+	for name := range importers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+var variableReg = regexp.MustCompile(`\{\{\s*(?:_\.)?([\w.-]+)\s*\}\}`)
+
+// convertVariables converts the variables like {{name}} or {{ _.name }} to be the environment variables
+func convertVariables(text string) string {
+	return variableReg.ReplaceAllString(text, `{{env "$1"}}`)
+}
+
+// appendQuery appends the encoded query to the API which might already have a query
+func appendQuery(api, query string) string {
+	if query == "" {
+		return api
+	}
+	if strings.Contains(api, "?") {
+		return api + "&" + query
+	}
+	return api + "?" + query
+}
+
+// encodeQuery encodes the query in order, the templates will be kept as they are
+func encodeQuery(query map[string]string) string {
+	var keys []string
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		val := query[key]
+		if !strings.Contains(val, "{{") {
+			val = url.QueryEscape(val)
+		}
+		pairs = append(pairs, url.QueryEscape(key)+"="+val)
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uniqueNames makes sure all the test case names are unique by adding a number suffix
+func uniqueNames(suite *testing.TestSuite) {
+	names := map[string]int{}
+	for i := range suite.Items {
+		name := suite.Items[i].Name
+		if count, ok := names[name]; ok {
+			names[name] = count + 1
+			suite.Items[i].Name = fmt.Sprintf("%s-%d", name, count+1)
+		} else {
+			names[name] = 0
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package generator
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "embed"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/linuxsuren/api-testing/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTestSuiteImporter(t *testing.T) {
+	for _, name := range []string{"pact", "insomnia", "thunder", "curl"} {
+		importer, err := GetTestSuiteImporter(name)
+		assert.Nil(t, err)
+		assert.NotNil(t, importer)
+	}
+
+	_, err := GetTestSuiteImporter("fake")
+	assert.NotNil(t, err)
+}
+
+func TestInsomniaImporter(t *testing.T) {
+	suite, err := NewInsomniaImporter().Convert([]byte(insomniaExportForTest))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	assert.Equal(t, &atest.TestSuite{
+		Name: "Users",
+		Items: []atest.TestCase{{
+			Name:  "create user",
+			Group: "admin",
+			Request: atest.Request{
+				API:    `{{env "base_url"}}/users?dryRun=true`,
+				Method: http.MethodPost,
+				Header: map[string]string{
+					"Authorization": `Bearer {{env "token"}}`,
+				},
+				Body: `{"name": "{{env "user"}}"}`,
+			},
+		}, {
+			Name: "login",
+			Request: atest.Request{
+				API:    "https://foo.com/login",
+				Method: http.MethodPost,
+				Header: map[string]string{
+					util.ContentType: util.Form,
+				},
+				Form: map[string]string{
+					"username": "admin",
+				},
+			},
+		}},
+	}, suite)
+
+	_, err = NewInsomniaImporter().Convert([]byte("fake"))
+	assert.NotNil(t, err)
+}
+
+func TestThunderImporter(t *testing.T) {
+	suite, err := NewThunderImporter().Convert([]byte(thunderCollectionForTest))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	assert.Equal(t, &atest.TestSuite{
+		Name: "Users",
+		Items: []atest.TestCase{{
+			Name:  "get user",
+			Group: "admin",
+			Request: atest.Request{
+				API:    `{{env "base"}}/users/1?fields=name`,
+				Method: http.MethodGet,
+			},
+			Expect: atest.Response{
+				StatusCode: http.StatusOK,
+			},
+		}, {
+			Name: "get user-1",
+			Request: atest.Request{
+				API:    "https://foo.com/login",
+				Method: http.MethodPost,
+				Header: map[string]string{
+					"Accept":         "application/json",
+					util.ContentType: util.Form,
+				},
+				Form: map[string]string{
+					"username": "admin",
+				},
+			},
+		}},
+	}, suite)
+
+	_, err = NewThunderImporter().Convert([]byte("fake"))
+	assert.NotNil(t, err)
+}
+
+func TestCurlImporter(t *testing.T) {
+	suite, err := NewCurlImporter().Convert([]byte(curlCommandsForTest))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	assert.Equal(t, &atest.TestSuite{
+		Name: "curl",
+		Items: []atest.TestCase{{
+			Name: "POST /api/users",
+			Request: atest.Request{
+				API:    "https://foo.com/api/users?dryRun=true",
+				Method: http.MethodPost,
+				Header: map[string]string{
+					util.ContentType: "application/json",
+					"Authorization":  `Bearer {{env "token"}}`,
+				},
+				Body: `{"name": "linuxsuren"}`,
+			},
+		}, {
+			Name: "GET /api/users",
+			Request: atest.Request{
+				API:    "https://foo.com/api/users?page=1",
+				Method: http.MethodGet,
+				Header: map[string]string{
+					"Authorization": "Basic YWRtaW46c2VjcmV0",
+				},
+			},
+		}, {
+			Name: "POST /api/login",
+			Request: atest.Request{
+				API:    "https://foo.com/api/login",
+				Method: http.MethodPost,
+				Header: map[string]string{
+					util.ContentType: util.MultiPartFormData,
+				},
+				Form: map[string]string{
+					"username": "admin",
+					"password": "secret",
+				},
+			},
+		}, {
+			Name: "DELETE /api/users/1",
+			Request: atest.Request{
+				API:    "https://foo.com/api/users/1",
+				Method: http.MethodDelete,
+			},
+		}},
+	}, suite)
+
+	suite, err = NewCurlImporter().Convert([]byte(`curl -d "a=b" http://foo.com/bar; curl -d a=c http://foo.com/bar`))
+	if assert.Nil(t, err) && assert.Equal(t, 2, len(suite.Items)) {
+		assert.Equal(t, "POST /bar", suite.Items[0].Name)
+		assert.Equal(t, util.Form, suite.Items[0].Request.Header[util.ContentType])
+		assert.Equal(t, "POST /bar-1", suite.Items[1].Name)
+	}
+
+	// the data of the files, and the JSON parts are not joined by '&'
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "user.json"), []byte("{\n  \"name\": \"linuxsuren\"\n}\n"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "name.txt"), []byte("linux suren"), 0644))
+	suite, err = NewCurlImporter().Convert([]byte(`curl -H 'Content-Type: application/json' -d @` + filepath.Join(dir, "user.json") + ` http://foo.com/users
+curl --data-binary @` + filepath.Join(dir, "user.json") + ` -H 'Content-Type: application/json' http://foo.com/users
+curl --json '{"name":' --json '"linuxsuren"}' http://foo.com/users
+curl -d a=b --data @` + filepath.Join(dir, "name.txt") + ` --data-urlencode name@` + filepath.Join(dir, "name.txt") + ` --data-raw @c http://foo.com/users`))
+	if assert.Nil(t, err) && assert.Equal(t, 4, len(suite.Items)) {
+		assert.Equal(t, `{  "name": "linuxsuren"}`, suite.Items[0].Request.Body)
+		assert.Equal(t, "{\n  \"name\": \"linuxsuren\"\n}\n", suite.Items[1].Request.Body)
+		assert.Equal(t, `{"name":"linuxsuren"}`, suite.Items[2].Request.Body)
+		assert.Equal(t, "a=b&linux suren&name=linux+suren&@c", suite.Items[3].Request.Body)
+		assert.Equal(t, util.Form, suite.Items[3].Request.Header[util.ContentType])
+	}
+
+	for _, invalid := range []string{"", "wget http://foo.com", "curl -X", "curl -s", `curl 'http://foo.com`,
+		"curl -d @- http://foo.com", "curl -d @" + filepath.Join(dir, "fake.json") + " http://foo.com",
+		"curl --data-urlencode name@" + filepath.Join(dir, "fake.txt") + " http://foo.com"} {
+		_, err = NewCurlImporter().Convert([]byte(invalid))
+		assert.NotNil(t, err, invalid)
+	}
+}
+
+func TestEncodeQuery(t *testing.T) {
+	assert.Equal(t, "a=1&b={{.name}}&c=d+e", encodeQuery(map[string]string{
+		"c": "d e",
+		"a": "1",
+		"b": "{{.name}}",
+	}))
+	assert.Equal(t, "/foo?a=1&b=2", appendQuery("/foo?a=1", "b=2"))
+	assert.Equal(t, "/foo", appendQuery("/foo", ""))
+}
+
+//go:embed testdata/insomnia.json
+var insomniaExportForTest string
+
+//go:embed testdata/curl.txt
+var curlCommandsForTest string
+
+//go:embed testdata/thunder.json
+var thunderCollectionForTest string
@@ -0,0 +1,114 @@
+package generator
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/linuxsuren/api-testing/pkg/util"
+)
+
+type insomniaImporter struct{}
+
+// NewInsomniaImporter creates an importer which converts the Insomnia export file to a test suite
+func NewInsomniaImporter() TestSuiteImporter {
+	return &insomniaImporter{}
+}
+
+type insomniaExport struct {
+	Resources []insomniaResource `json:"resources"`
+}
+
+type insomniaResource struct {
+	ID         string             `json:"_id"`
+	Type       string             `json:"_type"`
+	ParentID   string             `json:"parentId"`
+	Name       string             `json:"name"`
+	URL        string             `json:"url"`
+	Method     string             `json:"method"`
+	Body       insomniaBody       `json:"body"`
+	Headers    []insomniaKeyValue `json:"headers"`
+	Parameters []insomniaKeyValue `json:"parameters"`
+}
+
+type insomniaBody struct {
+	MimeType string             `json:"mimeType"`
+	Text     string             `json:"text"`
+	Params   []insomniaKeyValue `json:"params"`
+}
+
+type insomniaKeyValue struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled"`
+}
+
+// Convert converts the requests of an Insomnia export file to be test cases
+func (i *insomniaImporter) Convert(data []byte) (suite *testing.TestSuite, err error) {
+	export := &insomniaExport{}
+	if err = json.Unmarshal(data, export); err != nil {
+		return
+	}
+
+	suite = &testing.TestSuite{Items: []testing.TestCase{}}
+	groups := map[string]string{}
+	for _, resource := range export.Resources {
+		switch resource.Type {
+		case "workspace":
+			if suite.Name == "" {
+				suite.Name = resource.Name
+			}
+		case "request_group":
+			groups[resource.ID] = resource.Name
+		}
+	}
+
+	for _, resource := range export.Resources {
+		if resource.Type != "request" {
+			continue
+		}
+
+		testCase := testing.TestCase{
+			Name:  resource.Name,
+			Group: groups[resource.ParentID],
+			Request: testing.Request{
+				API:    convertVariables(resource.URL),
+				Method: strings.ToUpper(resource.Method),
+				Header: insomniaKeyValues(resource.Headers),
+			},
+		}
+
+		testCase.Request.API = appendQuery(testCase.Request.API, encodeQuery(insomniaKeyValues(resource.Parameters)))
+
+		switch resource.Body.MimeType {
+		case "":
+		case util.Form, util.MultiPartFormData:
+			testCase.Request.Form = insomniaKeyValues(resource.Body.Params)
+			testCase.Request.Header = util.MakeSureNotNil(testCase.Request.Header)
+			testCase.Request.Header[util.ContentType] = resource.Body.MimeType
+		default:
+			testCase.Request.Body = convertVariables(resource.Body.Text)
+		}
+		suite.Items = append(suite.Items, testCase)
+	}
+	uniqueNames(suite)
+	return
+}
+
+func insomniaKeyValues(pairs []insomniaKeyValue) (result map[string]string) {
+	for _, pair := range pairs {
+		if pair.Disabled || pair.Name == "" {
+			continue
+		}
+
+		if result == nil {
+			result = map[string]string{}
+		}
+		result[pair.Name] = convertVariables(pair.Value)
+	}
+	return
+}
+
+func init() {
+	RegisterTestSuiteImporter("insomnia", NewInsomniaImporter())
+}
@@ -0,0 +1,154 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"text/template"
+
+	_ "embed"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/linuxsuren/api-testing/pkg/util"
+)
+
+type k6Converter struct{}
+
+// NewK6Converter creates a converter which outputs the k6 script
+func NewK6Converter() TestSuiteConverter {
+	return &k6Converter{}
+}
+
+type k6Request struct {
+	Name   string
+	Method string
+	URL    string
+	Body   string
+	Header map[string]string
+	Checks []k6Check
+}
+
+type k6Check struct {
+	Name       string
+	Expression string
+}
+
+// Convert converts the test suite to be a k6 script
+func (c *k6Converter) Convert(suite *testing.TestSuite) (result string, err error) {
+	var requests []k6Request
+	for _, testCase := range suite.Items {
+		requests = append(requests, toK6Request(suite, testCase))
+	}
+
+	var tpl *template.Template
+	if tpl, err = template.New("k6").Funcs(template.FuncMap{
+		"toJS": toJS,
+	}).Parse(k6Script); err != nil {
+		return
+	}
+
+	param := suite.Param
+	if param == nil {
+		param = map[string]string{}
+	}
+
+	buf := new(bytes.Buffer)
+	if err = tpl.Execute(buf, map[string]interface{}{
+		"Suite":    suite,
+		"Param":    param,
+		"Requests": requests,
+	}); err == nil {
+		result = buf.String()
+	}
+	return
+}
+
+func toK6Request(suite *testing.TestSuite, testCase testing.TestCase) (request k6Request) {
+	request = k6Request{
+		Name:   testCase.Name,
+		Method: emptyThenDefault(testCase.Request.Method, http.MethodGet),
+		URL:    toK6String(FullAPI(suite, testCase.Request.API)),
+		Header: map[string]string{},
+	}
+
+	for key, val := range testCase.Request.Header {
+		request.Header[key] = toK6String(val)
+	}
+
+	if len(testCase.Request.Form) > 0 {
+		var pairs []string
+		for _, key := range sortedKeys(testCase.Request.Form) {
+			pairs = append(pairs, fmt.Sprintf("%s: %s", toJS(key), toK6String(testCase.Request.Form[key])))
+		}
+		request.Body = fmt.Sprintf("{%s}", strings.Join(pairs, ", "))
+		if testCase.Request.Header[util.ContentType] == util.MultiPartFormData {
+			// k6 sets the multipart content type with the boundary by itself
+			delete(request.Header, util.ContentType)
+		}
+	} else if testCase.Request.Body != "" {
+		request.Body = toK6String(testCase.Request.Body)
+	} else {
+		request.Body = "null"
+	}
+
+	expect := testCase.Expect
+	statusCode := expect.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	request.Checks = append(request.Checks, k6Check{
+		Name:       fmt.Sprintf("%s: status code is %d", testCase.Name, statusCode),
+		Expression: fmt.Sprintf("r.status === %d", statusCode),
+	})
+
+	for _, key := range sortedKeys(expect.Header) {
+		request.Checks = append(request.Checks, k6Check{
+			Name:       fmt.Sprintf("%s: header %s", testCase.Name, key),
+			Expression: fmt.Sprintf("r.headers[%s] === %s", toJS(http.CanonicalHeaderKey(key)), toJS(expect.Header[key])),
+		})
+	}
+
+	if expect.Body != "" {
+		request.Checks = append(request.Checks, k6Check{
+			Name:       fmt.Sprintf("%s: body", testCase.Name),
+			Expression: fmt.Sprintf("r.body === %s", toJS(strings.TrimSpace(expect.Body))),
+		})
+	}
+
+	var fields []string
+	for key := range expect.BodyFieldsExpect {
+		fields = append(fields, key)
+	}
+	sort.Strings(fields)
+	for _, key := range fields {
+		request.Checks = append(request.Checks, k6Check{
+			Name: fmt.Sprintf("%s: field %s", testCase.Name, key),
+			Expression: fmt.Sprintf("JSON.stringify(r.json(%s)) === JSON.stringify(%s)",
+				toJS(strings.ReplaceAll(key, "/", ".")), toJS(expect.BodyFieldsExpect[key])),
+		})
+	}
+	return
+}
+
+// toK6String converts the text to be a JavaScript template literal,
+// the environment variables and parameters will be converted as well
+func toK6String(text string) string {
+	text = strings.NewReplacer("\\", "\\\\", "`", "\\`", "${", "\\${").Replace(text)
+	text = goTemplateVariableReg.ReplaceAllStringFunc(text, func(match string) string {
+		groups := goTemplateVariableReg.FindStringSubmatch(match)
+		if groups[1] != "" {
+			return fmt.Sprintf("${__ENV[%s]}", toJS(groups[1]))
+		}
+		return fmt.Sprintf("${param[%s]}", toJS(groups[2]))
+	})
+	return "`" + text + "`"
+}
+
+//go:embed data/k6.js
+var k6Script string
+
+func init() {
+	RegisterTestSuiteConverter("k6", NewK6Converter())
+}
@@ -0,0 +1,65 @@
+package generator
+
+import (
+	"net/http"
+	"testing"
+
+	_ "embed"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/linuxsuren/api-testing/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestK6Converter(t *testing.T) {
+	suite := &atest.TestSuite{
+		Name: "users",
+		API:  "{{.param.server}}",
+		Param: map[string]string{
+			"server": "http://localhost",
+		},
+		Items: []atest.TestCase{{
+			Name: "create",
+			Request: atest.Request{
+				API:    "/users",
+				Method: http.MethodPost,
+				Header: map[string]string{
+					"Authorization": `Bearer {{env "TOKEN"}}`,
+				},
+				Body: "{\"name\": \"`${name}`\"}",
+			},
+			Expect: atest.Response{
+				StatusCode: http.StatusCreated,
+				Header: map[string]string{
+					"location": "/users/1",
+				},
+				Body: "created",
+				BodyFieldsExpect: map[string]interface{}{
+					"owner/name": "linuxsuren",
+				},
+			},
+		}, {
+			Name: "upload",
+			Request: atest.Request{
+				API: "https://foo.com/upload",
+				Header: map[string]string{
+					util.ContentType: util.MultiPartFormData,
+				},
+				Form: map[string]string{
+					"name": "foo",
+				},
+			},
+		}},
+	}
+
+	result, err := NewK6Converter().Convert(suite)
+	assert.Nil(t, err)
+	assert.Equal(t, expectedK6Script, result)
+
+	result, err = NewK6Converter().Convert(&atest.TestSuite{Name: "empty"})
+	assert.Nil(t, err)
+	assert.Contains(t, result, "const param = {};")
+}
+
+//go:embed testdata/k6.js
+var expectedK6Script string
@@ -0,0 +1,172 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+// OversizedStringLength is the length of the oversized strings in the negative test cases
+const OversizedStringLength = 10240
+
+// RequestSchemaFunc returns the JSON schema of the request body of the test case, it's empty if it's unknown
+type RequestSchemaFunc func(testCase testing.TestCase) string
+
+// GenerateNegativeCases derives the negative test cases from the positive ones, such as:
+// missing fields, wrong types, oversized strings and invalid auth. The missing fields are the required ones
+// of the request schema, or all the fields of the body if the schema is unknown
+func GenerateNegativeCases(suite *testing.TestSuite, requestSchema RequestSchemaFunc) (result *testing.TestSuite) {
+	result = &testing.TestSuite{
+		Name:  suite.Name + "-negatives",
+		API:   suite.API,
+		Param: suite.Param,
+		Items: []testing.TestCase{},
+	}
+
+	for _, testCase := range suite.Items {
+		var schema string
+		if requestSchema != nil {
+			schema = requestSchema(testCase)
+		}
+		result.Items = append(result.Items, negativeBodyCases(testCase, schema)...)
+
+		if auth := testCase.Request.Header["Authorization"]; auth != "" {
+			negative := negativeCase(testCase, "invalid-auth", http.StatusUnauthorized)
+			negative.Request.Header["Authorization"] = invalidAuth(auth)
+			result.Items = append(result.Items, negative)
+		}
+	}
+	uniqueNames(result)
+	return
+}
+
+func negativeBodyCases(testCase testing.TestCase, schema string) (cases []testing.TestCase) {
+	body := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(testCase.Request.Body), &body); err != nil || len(body) == 0 {
+		return
+	}
+	required, knownSchema := requiredFields(schema)
+
+	var fields []string
+	for key := range body {
+		fields = append(fields, key)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		if !knownSchema || required[field] {
+			cases = append(cases, withBody(negativeCase(testCase, "missing-"+field, http.StatusBadRequest),
+				body, field, nil))
+		}
+
+		cases = append(cases, withBody(negativeCase(testCase, "wrong-type-"+field, http.StatusBadRequest),
+			body, field, wrongTypeValue(body[field])))
+
+		if _, ok := body[field].(string); ok {
+			cases = append(cases, withBody(negativeCase(testCase, "oversized-"+field, http.StatusBadRequest),
+				body, field, strings.Repeat("a", OversizedStringLength)))
+		}
+	}
+	return
+}
+
+// requiredFields returns the required fields of the object schema, the reference of the root schema is resolved,
+// such as: #/components/schemas/User. The schema is unknown if it's empty or invalid
+func requiredFields(schema string) (fields map[string]bool, known bool) {
+	document := map[string]interface{}{}
+	if schema == "" || json.Unmarshal([]byte(schema), &document) != nil {
+		return
+	}
+
+	object := document
+	if ref, ok := object["$ref"].(string); ok && strings.HasPrefix(ref, "#/") {
+		for _, key := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			object, _ = object[key].(map[string]interface{})
+		}
+	}
+	if object == nil {
+		return
+	}
+
+	known = true
+	fields = map[string]bool{}
+	required, _ := object["required"].([]interface{})
+	for _, field := range required {
+		if name, ok := field.(string); ok {
+			fields[name] = true
+		}
+	}
+	return
+}
+
+// negativeCase copies the request of the test case, and expects the status code only
+func negativeCase(testCase testing.TestCase, suffix string, statusCode int) (negative testing.TestCase) {
+	negative = testing.TestCase{
+		Name:    fmt.Sprintf("%s-%s", testCase.Name, suffix),
+		Group:   testCase.Group,
+		Request: testCase.Request,
+		Expect: testing.Response{
+			StatusCode: statusCode,
+		},
+	}
+	negative.Request.Header = copyMap(testCase.Request.Header)
+	negative.Request.Query = copyMap(testCase.Request.Query)
+	negative.Request.Form = copyMap(testCase.Request.Form)
+	return
+}
+
+// withBody sets the body with the field replaced, the field will be removed if the value is nil
+func withBody(testCase testing.TestCase, body map[string]interface{}, field string, value interface{}) testing.TestCase {
+	newBody := map[string]interface{}{}
+	for key, val := range body {
+		newBody[key] = val
+	}
+	if value == nil {
+		delete(newBody, field)
+	} else {
+		newBody[field] = value
+	}
+
+	data, _ := json.Marshal(newBody)
+	testCase.Request.Body = string(data)
+	return testCase
+}
+
+func wrongTypeValue(value interface{}) interface{} {
+	switch value.(type) {
+	case string:
+		return 12345
+	case float64:
+		return "not-a-number"
+	case bool:
+		return "not-a-boolean"
+	case []interface{}:
+		return "not-an-array"
+	case map[string]interface{}:
+		return "not-an-object"
+	default:
+		return []string{"unexpected"}
+	}
+}
+
+func invalidAuth(auth string) string {
+	if index := strings.Index(auth, " "); index > 0 {
+		return auth[:index] + " invalid-credential"
+	}
+	return "invalid-credential"
+}
+
+func copyMap(items map[string]string) (result map[string]string) {
+	if items == nil {
+		return
+	}
+	result = map[string]string{}
+	for key, val := range items {
+		result[key] = val
+	}
+	return
+}
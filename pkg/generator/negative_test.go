@@ -0,0 +1,112 @@
+package generator
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateNegativeCases(t *testing.T) {
+	suite := &atest.TestSuite{
+		Name: "users",
+		API:  "http://foo",
+		Items: []atest.TestCase{{
+			Name:  "create",
+			Group: "admin",
+			Request: atest.Request{
+				API:    "/users",
+				Method: http.MethodPost,
+				Header: map[string]string{
+					"Authorization": "Bearer {{env \"token\"}}",
+				},
+				Body: `{"name": "linuxsuren", "age": 18}`,
+			},
+			Expect: atest.Response{
+				StatusCode: http.StatusCreated,
+				Body:       "created",
+			},
+		}, {
+			Name: "list",
+			Request: atest.Request{
+				API: "/users",
+			},
+		}},
+	}
+
+	result := GenerateNegativeCases(suite, nil)
+	assert.Equal(t, "users-negatives", result.Name)
+	assert.Equal(t, "http://foo", result.API)
+
+	var names []string
+	for _, item := range result.Items {
+		names = append(names, item.Name)
+	}
+	assert.Equal(t, []string{"create-missing-age", "create-wrong-type-age", "create-missing-name",
+		"create-wrong-type-name", "create-oversized-name", "create-invalid-auth"}, names)
+
+	assert.Equal(t, `{"name":"linuxsuren"}`, result.Items[0].Request.Body)
+	assert.Equal(t, atest.Response{StatusCode: http.StatusBadRequest}, result.Items[0].Expect)
+	assert.Equal(t, "admin", result.Items[0].Group)
+	assert.Equal(t, `{"age":"not-a-number","name":"linuxsuren"}`, result.Items[1].Request.Body)
+	assert.Equal(t, `{"age":18,"name":12345}`, result.Items[3].Request.Body)
+	assert.True(t, strings.Contains(result.Items[4].Request.Body, strings.Repeat("a", OversizedStringLength)))
+
+	invalidAuth := result.Items[5]
+	assert.Equal(t, "Bearer invalid-credential", invalidAuth.Request.Header["Authorization"])
+	assert.Equal(t, http.StatusUnauthorized, invalidAuth.Expect.StatusCode)
+	assert.Equal(t, `{"name": "linuxsuren", "age": 18}`, invalidAuth.Request.Body)
+
+	// the original test suite should not be changed
+	assert.Equal(t, "Bearer {{env \"token\"}}", suite.Items[0].Request.Header["Authorization"])
+}
+
+func TestGenerateNegativeCasesWithSchema(t *testing.T) {
+	suite := &atest.TestSuite{
+		Items: []atest.TestCase{{
+			Name: "create",
+			Request: atest.Request{
+				API:  "/users",
+				Body: `{"name": "linuxsuren", "age": 18}`,
+			},
+		}},
+	}
+
+	tests := []struct {
+		name   string
+		schema string
+		expect []string
+	}{{
+		name:   "required fields",
+		schema: `{"type": "object", "required": ["name"]}`,
+		expect: []string{"create-wrong-type-age", "create-missing-name", "create-wrong-type-name", "create-oversized-name"},
+	}, {
+		name:   "referenced schema",
+		schema: `{"$ref": "#/components/schemas/User", "components": {"schemas": {"User": {"required": ["age"]}}}}`,
+		expect: []string{"create-missing-age", "create-wrong-type-age", "create-wrong-type-name", "create-oversized-name"},
+	}, {
+		name:   "no required fields",
+		schema: `{"type": "object"}`,
+		expect: []string{"create-wrong-type-age", "create-wrong-type-name", "create-oversized-name"},
+	}, {
+		name:   "unknown schema",
+		schema: "",
+		expect: []string{"create-missing-age", "create-wrong-type-age", "create-missing-name",
+			"create-wrong-type-name", "create-oversized-name"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := GenerateNegativeCases(suite, func(testCase atest.TestCase) string {
+				return tt.schema
+			})
+
+			var names []string
+			for _, item := range result.Items {
+				names = append(names, item.Name)
+			}
+			assert.Equal(t, tt.expect, names)
+		})
+	}
+}
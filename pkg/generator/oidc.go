@@ -0,0 +1,122 @@
+package generator
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/linuxsuren/api-testing/pkg/util"
+)
+
+// OIDCSuiteOptions are the options of the OpenID Connect conformance suite, the client secret and the password
+// are read from the environment variables OIDC_CLIENT_SECRET and OIDC_PASSWORD when the suite runs
+type OIDCSuiteOptions struct {
+	Issuer   string
+	ClientID string
+	// Username enables the password grant, then the userinfo endpoint is verified with the access token of the user
+	Username string
+}
+
+// oidcDiscoverySchema has the required fields of the discovery document, see also
+// https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderMetadata
+const oidcDiscoverySchema = `{
+  "type": "object",
+  "required": ["issuer", "authorization_endpoint", "token_endpoint", "jwks_uri",
+    "response_types_supported", "subject_types_supported", "id_token_signing_alg_values_supported"]
+}`
+
+// GenerateOIDCSuite generates the test suite which exercises the discovery, JWKS, token and userinfo endpoints
+// of the issuer with the standard assertions, the cases refer to the endpoints of the discovery document
+func GenerateOIDCSuite(options OIDCSuiteOptions) (suite *testing.TestSuite) {
+	param := map[string]string{"clientID": options.ClientID}
+	oidcRequest := &testing.OIDCRequest{
+		GrantType:    "client_credentials",
+		ClientID:     "{{.param.clientID}}",
+		ClientSecret: `{{env "OIDC_CLIENT_SECRET"}}`,
+		Scope:        "openid",
+	}
+	if options.Username != "" {
+		param["username"] = options.Username
+		oidcRequest.GrantType = "password"
+		oidcRequest.Username = "{{.param.username}}"
+		oidcRequest.Password = `{{env "OIDC_PASSWORD"}}`
+	}
+
+	suite = &testing.TestSuite{
+		Name:  "oidc-conformance",
+		API:   strings.TrimSuffix(options.Issuer, "/"),
+		Param: param,
+		Items: []testing.TestCase{{
+			Name: "discovery",
+			Request: testing.Request{
+				API: "/.well-known/openid-configuration",
+			},
+			Expect: testing.Response{
+				StatusCode:       http.StatusOK,
+				ContentType:      "application/json",
+				Schema:           oidcDiscoverySchema,
+				BodyFieldsExpect: map[string]interface{}{"issuer": options.Issuer},
+				Verify: []string{
+					`"code" in data.response_types_supported`,
+					`"RS256" in data.id_token_signing_alg_values_supported`,
+				},
+			},
+		}, {
+			Name: "jwks",
+			Request: testing.Request{
+				API: "{{.discovery.jwks_uri}}",
+			},
+			Expect: testing.Response{
+				StatusCode: http.StatusOK,
+				Arrays:     map[string]*testing.ArrayExpect{"$.keys": {Length: "gte 1"}},
+				Verify: []string{
+					`all(data.keys, {.kty in ["RSA", "EC", "OKP"]})`,
+					// the private parts of the keys should never be published
+					`all(data.keys, {.d == nil && .p == nil && .q == nil})`,
+				},
+			},
+		}, {
+			Name: "token",
+			Request: testing.Request{
+				API:  options.Issuer,
+				OIDC: oidcRequest,
+			},
+			Expect: testing.Response{
+				Verify: []string{
+					`data.token.token_type in ["Bearer", "bearer"]`,
+					`data.token.expires_in > 0`,
+				},
+			},
+		}, {
+			Name: "invalid-client",
+			Request: testing.Request{
+				API:    "{{.discovery.token_endpoint}}",
+				Method: http.MethodPost,
+				Header: map[string]string{
+					util.ContentType: util.Form,
+					"Authorization":  `Basic {{b64enc (print .param.clientID ":invalid-secret")}}`,
+				},
+				Form: map[string]string{"grant_type": "client_credentials"},
+			},
+			Expect: testing.Response{
+				StatusCode: http.StatusUnauthorized,
+				Verify:     []string{`data.error in ["invalid_client", "unauthorized_client"]`},
+			},
+		}},
+	}
+
+	if options.Username != "" {
+		suite.Items = append(suite.Items, testing.TestCase{
+			Name: "userinfo",
+			Request: testing.Request{
+				API:    "{{.discovery.userinfo_endpoint}}",
+				Header: map[string]string{"Authorization": "Bearer {{.token.token.access_token}}"},
+			},
+			Expect: testing.Response{
+				StatusCode: http.StatusOK,
+				Verify:     []string{`data.sub != nil && data.sub != ""`},
+			},
+		})
+	}
+	return
+}
@@ -0,0 +1,43 @@
+package generator
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateOIDCSuite(t *testing.T) {
+	tests := []struct {
+		name      string
+		options   OIDCSuiteOptions
+		grantType string
+		cases     []string
+	}{{
+		name:      "client credentials",
+		options:   OIDCSuiteOptions{Issuer: "https://idp.example.com/realms/test/", ClientID: "api-testing"},
+		grantType: "client_credentials",
+		cases:     []string{"discovery", "jwks", "token", "invalid-client"},
+	}, {
+		name:      "password",
+		options:   OIDCSuiteOptions{Issuer: "https://idp.example.com", ClientID: "api-testing", Username: "rick"},
+		grantType: "password",
+		cases:     []string{"discovery", "jwks", "token", "invalid-client", "userinfo"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suite := GenerateOIDCSuite(tt.options)
+			assert.Equal(t, "oidc-conformance", suite.Name)
+			assert.NotContains(t, suite.API, "realms/test/")
+			assert.Equal(t, tt.options.ClientID, suite.Param["clientID"])
+
+			var names []string
+			for _, item := range suite.Items {
+				names = append(names, item.Name)
+			}
+			assert.Equal(t, tt.cases, names)
+			assert.Equal(t, tt.grantType, suite.Items[2].Request.OIDC.GrantType)
+			assert.Equal(t, http.StatusUnauthorized, suite.Items[3].Expect.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+type pactImporter struct{}
+
+// NewPactImporter creates an importer which converts the Pact file to a test suite
+func NewPactImporter() TestSuiteImporter {
+	return &pactImporter{}
+}
+
+type pactFile struct {
+	Consumer     pactParticipant   `json:"consumer"`
+	Provider     pactParticipant   `json:"provider"`
+	Interactions []pactInteraction `json:"interactions"`
+}
+
+type pactParticipant struct {
+	Name string `json:"name"`
+}
+
+type pactInteraction struct {
+	Description   string       `json:"description"`
+	ProviderState string       `json:"providerState"`
+	Request       pactRequest  `json:"request"`
+	Response      pactResponse `json:"response"`
+	// ProviderStates is the one of Pact v3, there might be multiple states
+	ProviderStates []pactProviderState `json:"providerStates"`
+}
+
+type pactProviderState struct {
+	Name string `json:"name"`
+}
+
+type pactRequest struct {
+	Method  string                 `json:"method"`
+	Path    string                 `json:"path"`
+	Query   json.RawMessage        `json:"query"`
+	Headers map[string]interface{} `json:"headers"`
+	Body    interface{}            `json:"body"`
+}
+
+type pactResponse struct {
+	Status        int                    `json:"status"`
+	Headers       map[string]interface{} `json:"headers"`
+	Body          interface{}            `json:"body"`
+	MatchingRules map[string]interface{} `json:"matchingRules"`
+}
+
+// Convert converts the interactions of a Pact file to be test cases
+func (p *pactImporter) Convert(data []byte) (suite *testing.TestSuite, err error) {
+	suite, _, err = p.ConvertWithWarnings(data)
+	return
+}
+
+// ConvertWithWarnings converts the interactions, the provider states are not converted but reported as the warnings
+func (p *pactImporter) ConvertWithWarnings(data []byte) (suite *testing.TestSuite, warnings []string, err error) {
+	pact := &pactFile{}
+	if err = json.Unmarshal(data, pact); err != nil {
+		return
+	}
+
+	suite = &testing.TestSuite{
+		Name:  fmt.Sprintf("%s-%s", pact.Consumer.Name, pact.Provider.Name),
+		Items: []testing.TestCase{},
+	}
+	for _, interaction := range pact.Interactions {
+		var testCase testing.TestCase
+		if testCase, err = interaction.toTestCase(); err != nil {
+			err = fmt.Errorf("failed to convert interaction '%s', %v", interaction.Description, err)
+			return
+		}
+		suite.Items = append(suite.Items, testCase)
+
+		if states := interaction.providerStates(); len(states) > 0 {
+			warnings = append(warnings, fmt.Sprintf("the provider states of '%s' should be set up before running it: %s",
+				interaction.Description, strings.Join(states, ", ")))
+		}
+	}
+	return
+}
+
+// providerStates returns the state names of both Pact v2 and v3
+func (i pactInteraction) providerStates() (states []string) {
+	if i.ProviderState != "" {
+		states = append(states, i.ProviderState)
+	}
+	for _, state := range i.ProviderStates {
+		states = append(states, state.Name)
+	}
+	return
+}
+
+func (i pactInteraction) toTestCase() (testCase testing.TestCase, err error) {
+	testCase.Name = i.Description
+	testCase.Request = testing.Request{
+		API:    i.Request.Path,
+		Method: strings.ToUpper(i.Request.Method),
+		Header: pactHeaders(i.Request.Headers),
+	}
+
+	var query string
+	if query, err = pactQuery(i.Request.Query); err != nil {
+		return
+	}
+	testCase.Request.API = appendQuery(testCase.Request.API, query)
+
+	if testCase.Request.Body, err = pactBody(i.Request.Body); err != nil {
+		return
+	}
+
+	testCase.Expect = testing.Response{
+		StatusCode: i.Response.Status,
+		Header:     pactHeaders(i.Response.Headers),
+	}
+
+	ignored := pactMatchingRulePaths(i.Response.MatchingRules)
+	switch body := i.Response.Body.(type) {
+	case map[string]interface{}:
+		fields := map[string]interface{}{}
+		flattenFields("", body, fields)
+		for key := range fields {
+			for _, path := range ignored {
+				if path == "" || key == path || strings.HasPrefix(key, path+"/") {
+					delete(fields, key)
+				}
+			}
+		}
+		if len(fields) > 0 {
+			testCase.Expect.BodyFieldsExpect = fields
+		}
+	case []interface{}:
+		// the elements are generated by the matchers if there are matching rules, such as eachLike
+		array := &testing.ArrayExpect{Length: len(body), Contains: body}
+		if len(ignored) > 0 {
+			array = &testing.ArrayExpect{Length: "gte 1"}
+			if len(body) == 0 {
+				array.Length = 0
+			}
+		}
+		testCase.Expect.Arrays = map[string]*testing.ArrayExpect{"$": array}
+	case string:
+		testCase.Expect.Body = body
+	}
+	return
+}
+
+// pactQuery supports the query string of Pact v2, and the query map of Pact v3
+func pactQuery(raw json.RawMessage) (query string, err error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return
+	}
+
+	if err = json.Unmarshal(raw, &query); err == nil {
+		return
+	}
+
+	values := map[string][]string{}
+	if err = json.Unmarshal(raw, &values); err == nil {
+		query = url.Values(values).Encode()
+	}
+	return
+}
+
+func pactHeaders(headers map[string]interface{}) (result map[string]string) {
+	if len(headers) == 0 {
+		return
+	}
+
+	result = map[string]string{}
+	for key, val := range headers {
+		switch v := val.(type) {
+		case []interface{}:
+			var items []string
+			for _, item := range v {
+				items = append(items, fmt.Sprintf("%v", item))
+			}
+			result[key] = strings.Join(items, ", ")
+		default:
+			result[key] = fmt.Sprintf("%v", v)
+		}
+	}
+	return
+}
+
+func pactBody(body interface{}) (result string, err error) {
+	switch b := body.(type) {
+	case nil:
+	case string:
+		result = b
+	default:
+		var data []byte
+		if data, err = json.Marshal(b); err == nil {
+			result = string(data)
+		}
+	}
+	return
+}
+
+// pactMatchingRulePaths returns the body paths which have the matching rules,
+// the values of them should not be compared literally
+func pactMatchingRulePaths(rules map[string]interface{}) (paths []string) {
+	var keys []string
+	for key, val := range rules {
+		if key == "body" {
+			// Pact v3
+			if bodyRules, ok := val.(map[string]interface{}); ok {
+				for bodyKey := range bodyRules {
+					keys = append(keys, strings.TrimPrefix(bodyKey, "$"))
+				}
+			}
+		} else if strings.HasPrefix(key, "$.body") {
+			// Pact v2
+			keys = append(keys, strings.TrimPrefix(key, "$.body"))
+		}
+	}
+
+	for _, key := range keys {
+		key = strings.TrimPrefix(key, ".")
+		if index := strings.IndexAny(key, "[*"); index >= 0 {
+			key = key[:index]
+		}
+		paths = append(paths, strings.TrimSuffix(strings.ReplaceAll(key, ".", "/"), "/"))
+	}
+	sort.Strings(paths)
+	return
+}
+
+// flattenFields flattens the nested map to be the path style keys, e.g. a/b
+func flattenFields(prefix string, object map[string]interface{}, fields map[string]interface{}) {
+	for key, val := range object {
+		path := key
+		if prefix != "" {
+			path = prefix + "/" + key
+		}
+
+		if nested, ok := val.(map[string]interface{}); ok && len(nested) > 0 {
+			flattenFields(path, nested, fields)
+		} else {
+			fields[path] = val
+		}
+	}
+}
+
+func init() {
+	RegisterTestSuiteImporter("pact", NewPactImporter())
+}
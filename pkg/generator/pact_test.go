@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"net/http"
+	"testing"
+
+	_ "embed"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPactImporter(t *testing.T) {
+	suite, err := NewPactImporter().Convert([]byte(pactFileForTest))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	assert.Equal(t, "web-users", suite.Name)
+	assert.Equal(t, []atest.TestCase{{
+		Name: "get user",
+		Request: atest.Request{
+			API:    "/users/1?fields=name",
+			Method: http.MethodGet,
+			Header: map[string]string{
+				"Accept": "application/json",
+			},
+		},
+		Expect: atest.Response{
+			StatusCode: http.StatusOK,
+			BodyFieldsExpect: map[string]interface{}{
+				"name":        "linuxsuren",
+				"id":          float64(1),
+				"profile/age": float64(18),
+			},
+		},
+	}, {
+		Name: "create user",
+		Request: atest.Request{
+			API:    "/users?dryRun=true",
+			Method: http.MethodPost,
+			Body:   `{"name":"linuxsuren"}`,
+		},
+		Expect: atest.Response{
+			StatusCode: http.StatusCreated,
+			Header: map[string]string{
+				"Location": "/users/2",
+			},
+		},
+	}, {
+		Name: "list users",
+		Request: atest.Request{
+			API:    "/users",
+			Method: http.MethodGet,
+		},
+		Expect: atest.Response{
+			StatusCode: http.StatusOK,
+			Arrays: map[string]*atest.ArrayExpect{"$": {
+				Length: 2,
+				Contains: []interface{}{
+					map[string]interface{}{"id": float64(1)},
+					map[string]interface{}{"id": float64(2)},
+				},
+			}},
+		},
+	}, {
+		Name: "search users",
+		Request: atest.Request{
+			API:    "/users/search",
+			Method: http.MethodGet,
+		},
+		Expect: atest.Response{
+			StatusCode: http.StatusOK,
+			Arrays:     map[string]*atest.ArrayExpect{"$": {Length: "gte 1"}},
+		},
+	}}, suite.Items)
+
+	_, warnings, err := NewPactImporter().(TestSuiteImporterWithWarnings).ConvertWithWarnings([]byte(pactFileForTest))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"the provider states of 'get user' should be set up before running it: user 1 exists",
+		"the provider states of 'list users' should be set up before running it: user 1 exists, user 2 exists",
+	}, warnings)
+
+	_, err = NewPactImporter().Convert([]byte("fake"))
+	assert.NotNil(t, err)
+
+	_, err = NewPactImporter().Convert([]byte(`{"interactions":[{"request":{"query":1}}]}`))
+	assert.NotNil(t, err)
+}
+
+//go:embed testdata/pact.json
+var pactFileForTest string
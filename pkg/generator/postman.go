@@ -0,0 +1,251 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/linuxsuren/api-testing/pkg/util"
+)
+
+type postmanConverter struct{}
+
+// NewPostmanConverter creates a converter which outputs the Postman collection
+func NewPostmanConverter() TestSuiteConverter {
+	return &postmanConverter{}
+}
+
+type postmanEnvironmentConverter struct{}
+
+// NewPostmanEnvironmentConverter creates a converter which outputs the Postman environment
+// from the parameters of the test suite
+func NewPostmanEnvironmentConverter() TestSuiteConverter {
+	return &postmanEnvironmentConverter{}
+}
+
+const postmanCollectionSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+type postmanCollection struct {
+	Info     postmanInfo       `json:"info"`
+	Item     []postmanItem     `json:"item"`
+	Variable []postmanKeyValue `json:"variable,omitempty"`
+}
+
+type postmanInfo struct {
+	Name   string `json:"name"`
+	Schema string `json:"schema"`
+}
+
+type postmanItem struct {
+	Name    string         `json:"name"`
+	Request postmanRequest `json:"request"`
+	Event   []postmanEvent `json:"event,omitempty"`
+}
+
+type postmanRequest struct {
+	Method string            `json:"method"`
+	Header []postmanKeyValue `json:"header"`
+	URL    postmanURL        `json:"url"`
+	Body   *postmanBody      `json:"body,omitempty"`
+}
+
+type postmanURL struct {
+	Raw string `json:"raw"`
+}
+
+type postmanBody struct {
+	Mode       string            `json:"mode"`
+	Raw        string            `json:"raw,omitempty"`
+	URLEncoded []postmanKeyValue `json:"urlencoded,omitempty"`
+	FormData   []postmanKeyValue `json:"formdata,omitempty"`
+}
+
+type postmanKeyValue struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Enabled *bool  `json:"enabled,omitempty"`
+}
+
+type postmanEvent struct {
+	Listen string        `json:"listen"`
+	Script postmanScript `json:"script"`
+}
+
+type postmanScript struct {
+	Type string   `json:"type"`
+	Exec []string `json:"exec"`
+}
+
+type postmanEnvironment struct {
+	Name   string            `json:"name"`
+	Values []postmanKeyValue `json:"values"`
+}
+
+// Convert converts the test suite to be a Postman collection
+func (c *postmanConverter) Convert(suite *testing.TestSuite) (result string, err error) {
+	collection := postmanCollection{
+		Info: postmanInfo{
+			Name:   suite.Name,
+			Schema: postmanCollectionSchema,
+		},
+		Item: []postmanItem{},
+	}
+
+	for _, key := range sortedKeys(suite.Param) {
+		collection.Variable = append(collection.Variable, postmanKeyValue{
+			Key:   key,
+			Value: suite.Param[key],
+		})
+	}
+
+	for _, testCase := range suite.Items {
+		collection.Item = append(collection.Item, toPostmanItem(suite, testCase))
+	}
+
+	var data []byte
+	if data, err = json.MarshalIndent(collection, "", "  "); err == nil {
+		result = string(data)
+	}
+	return
+}
+
+// Convert converts the parameters of the test suite to be a Postman environment
+func (c *postmanEnvironmentConverter) Convert(suite *testing.TestSuite) (result string, err error) {
+	enabled := true
+	env := postmanEnvironment{
+		Name:   suite.Name,
+		Values: []postmanKeyValue{},
+	}
+	for _, key := range sortedKeys(suite.Param) {
+		env.Values = append(env.Values, postmanKeyValue{
+			Key:     key,
+			Value:   suite.Param[key],
+			Enabled: &enabled,
+		})
+	}
+
+	var data []byte
+	if data, err = json.MarshalIndent(env, "", "  "); err == nil {
+		result = string(data)
+	}
+	return
+}
+
+func toPostmanItem(suite *testing.TestSuite, testCase testing.TestCase) (item postmanItem) {
+	request := testCase.Request
+	item.Name = testCase.Name
+	item.Request = postmanRequest{
+		Method: emptyThenDefault(request.Method, http.MethodGet),
+		Header: []postmanKeyValue{},
+		URL:    postmanURL{Raw: toPostmanVariables(FullAPI(suite, request.API))},
+	}
+
+	for _, key := range sortedKeys(request.Header) {
+		item.Request.Header = append(item.Request.Header, postmanKeyValue{
+			Key:   key,
+			Value: toPostmanVariables(request.Header[key]),
+		})
+	}
+
+	if len(request.Form) > 0 {
+		var pairs []postmanKeyValue
+		for _, key := range sortedKeys(request.Form) {
+			pairs = append(pairs, postmanKeyValue{
+				Key:   key,
+				Value: toPostmanVariables(request.Form[key]),
+				Type:  "text",
+			})
+		}
+
+		if request.Header[util.ContentType] == util.MultiPartFormData {
+			item.Request.Body = &postmanBody{Mode: "formdata", FormData: pairs}
+		} else {
+			item.Request.Body = &postmanBody{Mode: "urlencoded", URLEncoded: pairs}
+		}
+	} else if request.Body != "" {
+		item.Request.Body = &postmanBody{Mode: "raw", Raw: toPostmanVariables(request.Body)}
+	}
+
+	if script := toPostmanTestScript(testCase.Expect); len(script) > 0 {
+		item.Event = []postmanEvent{{
+			Listen: "test",
+			Script: postmanScript{
+				Type: "text/javascript",
+				Exec: script,
+			},
+		}}
+	}
+	return
+}
+
+func toPostmanTestScript(expect testing.Response) (script []string) {
+	statusCode := expect.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	script = append(script, fmt.Sprintf(`pm.test("status code is %d", function () {`, statusCode),
+		fmt.Sprintf(`    pm.response.to.have.status(%d);`, statusCode), "});")
+
+	for _, key := range sortedKeys(expect.Header) {
+		script = append(script, fmt.Sprintf(`pm.test("header %s", function () {`, key),
+			fmt.Sprintf(`    pm.response.to.have.header(%s, %s);`, toJS(key), toJS(expect.Header[key])), "});")
+	}
+
+	if expect.Body != "" {
+		script = append(script, `pm.test("body", function () {`,
+			fmt.Sprintf(`    pm.expect(pm.response.text()).to.eql(%s);`, toJS(strings.TrimSpace(expect.Body))), "});")
+	}
+
+	var fields []string
+	for key := range expect.BodyFieldsExpect {
+		fields = append(fields, key)
+	}
+	sort.Strings(fields)
+	for _, key := range fields {
+		accessor := "pm.response.json()"
+		for _, field := range strings.Split(key, "/") {
+			accessor += fmt.Sprintf("[%s]", toJS(field))
+		}
+		script = append(script, fmt.Sprintf(`pm.test("field %s", function () {`, key),
+			fmt.Sprintf(`    pm.expect(%s).to.eql(%s);`, accessor, toJS(expect.BodyFieldsExpect[key])), "});")
+	}
+	return
+}
+
+var goTemplateVariableReg = regexp.MustCompile(`\{\{\s*(?:env\s+"([\w.-]+)"|\.param\.([\w.-]+))\s*\}\}`)
+
+// toPostmanVariables converts the environment variables and parameters to be the Postman variables
+func toPostmanVariables(text string) string {
+	return goTemplateVariableReg.ReplaceAllString(text, "{{$1$2}}")
+}
+
+// FullAPI returns the API with the prefix of the test suite if it's a relative path
+func FullAPI(suite *testing.TestSuite, api string) string {
+	if strings.HasPrefix(api, "/") {
+		api = strings.TrimSuffix(strings.TrimSpace(suite.API), "/") + api
+	}
+	return api
+}
+
+func toJS(val interface{}) string {
+	data, _ := json.Marshal(val)
+	return string(data)
+}
+
+func sortedKeys(items map[string]string) (keys []string) {
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return
+}
+
+func init() {
+	RegisterTestSuiteConverter("postman", NewPostmanConverter())
+	RegisterTestSuiteConverter("postman-env", NewPostmanEnvironmentConverter())
+}
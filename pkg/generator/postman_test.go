@@ -0,0 +1,82 @@
+package generator
+
+import (
+	"net/http"
+	"testing"
+
+	_ "embed"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/linuxsuren/api-testing/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostmanConverter(t *testing.T) {
+	suite := &atest.TestSuite{
+		Name: "users",
+		API:  "{{.param.server}}/",
+		Param: map[string]string{
+			"server": "http://localhost",
+			"token":  "fake",
+		},
+		Items: []atest.TestCase{{
+			Name: "create",
+			Request: atest.Request{
+				API:    "/users",
+				Method: http.MethodPost,
+				Header: map[string]string{
+					"Authorization": `Bearer {{env "TOKEN"}}`,
+				},
+				Body: `{"name": "linuxsuren"}`,
+			},
+			Expect: atest.Response{
+				StatusCode: http.StatusCreated,
+				Header: map[string]string{
+					"Location": "/users/1",
+				},
+				BodyFieldsExpect: map[string]interface{}{
+					"owner/name": "linuxsuren",
+				},
+			},
+		}, {
+			Name: "login",
+			Request: atest.Request{
+				API:    "https://foo.com/login",
+				Method: http.MethodPost,
+				Header: map[string]string{
+					util.ContentType: util.Form,
+				},
+				Form: map[string]string{
+					"username": "{{.param.token}}",
+				},
+			},
+			Expect: atest.Response{
+				Body: "ok",
+			},
+		}, {
+			Name: "upload",
+			Request: atest.Request{
+				API: "https://foo.com/upload",
+				Header: map[string]string{
+					util.ContentType: util.MultiPartFormData,
+				},
+				Form: map[string]string{
+					"name": "foo",
+				},
+			},
+		}},
+	}
+
+	result, err := NewPostmanConverter().Convert(suite)
+	assert.Nil(t, err)
+	assert.JSONEq(t, expectedPostmanCollection, result)
+
+	result, err = NewPostmanEnvironmentConverter().Convert(suite)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"name":"users","values":[
+		{"key":"server","value":"http://localhost","enabled":true},
+		{"key":"token","value":"fake","enabled":true}]}`, result)
+}
+
+//go:embed testdata/postman.json
+var expectedPostmanCollection string
@@ -0,0 +1,53 @@
+package generator
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// InferJSONSchema infers a JSON schema from the value which is decoded from a JSON document
+func InferJSONSchema(value interface{}) (schema string, err error) {
+	var data []byte
+	if data, err = json.MarshalIndent(inferSchema(value), "", "  "); err == nil {
+		schema = string(data)
+	}
+	return
+}
+
+func inferSchema(value interface{}) map[string]interface{} {
+	switch val := value.(type) {
+	case map[string]interface{}:
+		properties := map[string]interface{}{}
+		var required []string
+		for key, item := range val {
+			properties[key] = inferSchema(item)
+			required = append(required, key)
+		}
+		sort.Strings(required)
+
+		schema := map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+		}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	case []interface{}:
+		schema := map[string]interface{}{
+			"type": "array",
+		}
+		if len(val) > 0 {
+			schema["items"] = inferSchema(val[0])
+		}
+		return schema
+	case string:
+		return map[string]interface{}{"type": "string"}
+	case float64, int, int64:
+		return map[string]interface{}{"type": "number"}
+	case bool:
+		return map[string]interface{}{"type": "boolean"}
+	default:
+		return map[string]interface{}{"type": "null"}
+	}
+}
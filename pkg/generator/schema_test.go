@@ -0,0 +1,38 @@
+package generator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInferJSONSchema(t *testing.T) {
+	var value interface{}
+	err := json.Unmarshal([]byte(`{"name": "linuxsuren", "age": 18, "admin": true,
+		"tags": ["a"], "empty": [], "address": {"city": null}}`), &value)
+	assert.Nil(t, err)
+
+	schema, err := InferJSONSchema(value)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{
+  "type": "object",
+  "required": ["address", "admin", "age", "empty", "name", "tags"],
+  "properties": {
+    "name": {"type": "string"},
+    "age": {"type": "number"},
+    "admin": {"type": "boolean"},
+    "tags": {"type": "array", "items": {"type": "string"}},
+    "empty": {"type": "array"},
+    "address": {
+      "type": "object",
+      "required": ["city"],
+      "properties": {"city": {"type": "null"}}
+    }
+  }
+}`, schema)
+
+	schema, err = InferJSONSchema(map[string]interface{}{})
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"type": "object", "properties": {}}`, schema)
+}
@@ -0,0 +1,37 @@
+// generated by atest from the test suite: users
+import http from 'k6/http';
+import { check, group } from 'k6';
+
+export const options = {
+  thresholds: {
+    checks: ['rate==1.0'],
+    http_req_failed: ['rate<0.01'],
+  },
+};
+
+const param = {"server":"http://localhost"};
+
+export default function () {
+  group("create", function () {
+    const res = http.request("POST", `${param["server"]}/users`, `{"name": "\`\${name}\`"}`, {
+      headers: {
+        "Authorization": `Bearer ${__ENV["TOKEN"]}`,
+      },
+    });
+    check(res, {
+      "create: status code is 201": (r) => r.status === 201,
+      "create: header location": (r) => r.headers["Location"] === "/users/1",
+      "create: body": (r) => r.body === "created",
+      "create: field owner/name": (r) => JSON.stringify(r.json("owner.name")) === JSON.stringify("linuxsuren"),
+    });
+  });
+  group("upload", function () {
+    const res = http.request("GET", `https://foo.com/upload`, {"name": `foo`}, {
+      headers: {
+      },
+    });
+    check(res, {
+      "upload: status code is 200": (r) => r.status === 200,
+    });
+  });
+}
@@ -0,0 +1,146 @@
+package generator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/linuxsuren/api-testing/pkg/util"
+)
+
+type thunderImporter struct{}
+
+// NewThunderImporter creates an importer which converts the Thunder Client collection to a test suite
+func NewThunderImporter() TestSuiteImporter {
+	return &thunderImporter{}
+}
+
+type thunderCollection struct {
+	CollectionName string           `json:"collectionName"`
+	Folders        []thunderFolder  `json:"folders"`
+	Requests       []thunderRequest `json:"requests"`
+}
+
+type thunderFolder struct {
+	ID   string `json:"_id"`
+	Name string `json:"name"`
+}
+
+type thunderRequest struct {
+	ContainerID string             `json:"containerId"`
+	Name        string             `json:"name"`
+	URL         string             `json:"url"`
+	Method      string             `json:"method"`
+	Headers     []thunderKeyValue  `json:"headers"`
+	Params      []thunderKeyValue  `json:"params"`
+	Body        thunderBody        `json:"body"`
+	Tests       []thunderAssertion `json:"tests"`
+}
+
+type thunderKeyValue struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	IsPath   bool   `json:"isPath"`
+	Disabled bool   `json:"isDisabled"`
+}
+
+type thunderBody struct {
+	Type  string            `json:"type"`
+	Raw   string            `json:"raw"`
+	Form  []thunderKeyValue `json:"form"`
+	Files []thunderKeyValue `json:"files"`
+}
+
+type thunderAssertion struct {
+	Type   string `json:"type"`
+	Custom string `json:"custom"`
+	Action string `json:"action"`
+	Value  string `json:"value"`
+}
+
+// Convert converts the requests of a Thunder Client collection to be test cases
+func (t *thunderImporter) Convert(data []byte) (suite *testing.TestSuite, err error) {
+	collection := &thunderCollection{}
+	if err = json.Unmarshal(data, collection); err != nil {
+		return
+	}
+
+	folders := map[string]string{}
+	for _, folder := range collection.Folders {
+		folders[folder.ID] = folder.Name
+	}
+
+	suite = &testing.TestSuite{
+		Name:  collection.CollectionName,
+		Items: []testing.TestCase{},
+	}
+	for _, request := range collection.Requests {
+		testCase := testing.TestCase{
+			Name:  request.Name,
+			Group: folders[request.ContainerID],
+			Request: testing.Request{
+				API:    convertVariables(request.URL),
+				Method: strings.ToUpper(request.Method),
+				Header: thunderKeyValues(request.Headers),
+			},
+		}
+
+		// the query parameters are part of the URL already, only the path parameters need to be replaced
+		for _, param := range request.Params {
+			if param.IsPath && !param.Disabled {
+				testCase.Request.API = strings.ReplaceAll(testCase.Request.API, "{"+param.Name+"}",
+					url.PathEscape(param.Value))
+			}
+		}
+
+		switch request.Body.Type {
+		case "formencoded":
+			testCase.Request.Form = thunderKeyValues(request.Body.Form)
+			testCase.Request.Header = util.MakeSureNotNil(testCase.Request.Header)
+			testCase.Request.Header[util.ContentType] = util.Form
+		case "formdata":
+			testCase.Request.Form = thunderKeyValues(request.Body.Form)
+			testCase.Request.Header = util.MakeSureNotNil(testCase.Request.Header)
+			testCase.Request.Header[util.ContentType] = util.MultiPartFormData
+		default:
+			testCase.Request.Body = convertVariables(request.Body.Raw)
+		}
+
+		for _, assertion := range request.Tests {
+			if assertion.Type == "res-code" && assertion.Action == "equal" {
+				if code, codeErr := strconv.Atoi(assertion.Value); codeErr == nil {
+					testCase.Expect.StatusCode = code
+				}
+			} else if assertion.Type == "res-body" && assertion.Action == "equal" {
+				testCase.Expect.Body = assertion.Value
+			}
+		}
+		if testCase.Request.Method == "" {
+			testCase.Request.Method = http.MethodGet
+		}
+		suite.Items = append(suite.Items, testCase)
+	}
+	uniqueNames(suite)
+	return
+}
+
+func thunderKeyValues(pairs []thunderKeyValue) (result map[string]string) {
+	for _, pair := range pairs {
+		if pair.Disabled || pair.Name == "" {
+			continue
+		}
+
+		if result == nil {
+			result = map[string]string{}
+		}
+		result[pair.Name] = convertVariables(pair.Value)
+	}
+	return
+}
+
+func init() {
+	RegisterTestSuiteImporter("thunder", NewThunderImporter())
+}
@@ -0,0 +1,199 @@
+package generator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/linuxsuren/api-testing/pkg/util"
+)
+
+type wireMockConverter struct{}
+
+// NewWireMockConverter creates a converter which outputs the WireMock mappings
+func NewWireMockConverter() TestSuiteConverter {
+	return &wireMockConverter{}
+}
+
+type wireMockMappings struct {
+	Mappings []wireMockMapping `json:"mappings"`
+}
+
+type wireMockMapping struct {
+	Name     string           `json:"name,omitempty"`
+	Request  wireMockRequest  `json:"request"`
+	Response wireMockResponse `json:"response"`
+}
+
+type wireMockRequest struct {
+	Method          string                     `json:"method"`
+	URLPath         string                     `json:"urlPath,omitempty"`
+	URLPathPattern  string                     `json:"urlPathPattern,omitempty"`
+	QueryParameters map[string]wireMockMatcher `json:"queryParameters,omitempty"`
+}
+
+type wireMockMatcher struct {
+	EqualTo string `json:"equalTo,omitempty"`
+	Matches string `json:"matches,omitempty"`
+}
+
+type wireMockResponse struct {
+	Status  int               `json:"status"`
+	Body    string            `json:"body,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// Convert converts the expect part of all test cases to be the WireMock mappings
+func (c *wireMockConverter) Convert(suite *testing.TestSuite) (result string, err error) {
+	mappings := wireMockMappings{Mappings: []wireMockMapping{}}
+	for _, item := range suite.Items {
+		var mapping wireMockMapping
+		if mapping, err = toWireMockMapping(suite, item); err != nil {
+			return
+		}
+		mappings.Mappings = append(mappings.Mappings, mapping)
+	}
+
+	var data []byte
+	if data, err = json.MarshalIndent(mappings, "", "  "); err == nil {
+		result = string(data)
+	}
+	return
+}
+
+func toWireMockMapping(suite *testing.TestSuite, testcase testing.TestCase) (mapping wireMockMapping, err error) {
+	// the path prefix of the test suite API is part of the URL path as well
+	path, query := SplitAPI(FullAPI(suite, testcase.Request.API))
+	for key, val := range testcase.Request.Query {
+		query[key] = []string{val}
+	}
+
+	mapping.Name = testcase.Name
+	mapping.Request.Method = emptyThenDefault(testcase.Request.Method, http.MethodGet)
+	if templateReg.MatchString(path) {
+		mapping.Request.URLPathPattern = ToPathPattern(path)
+	} else {
+		mapping.Request.URLPath = path
+	}
+
+	if len(query) > 0 {
+		mapping.Request.QueryParameters = map[string]wireMockMatcher{}
+		for key := range query {
+			val := query.Get(key)
+			if templateReg.MatchString(val) {
+				mapping.Request.QueryParameters[key] = wireMockMatcher{Matches: ToPathPattern(val)}
+			} else {
+				mapping.Request.QueryParameters[key] = wireMockMatcher{EqualTo: val}
+			}
+		}
+	}
+
+	mapping.Response.Status = testcase.Expect.StatusCode
+	if mapping.Response.Status == 0 {
+		mapping.Response.Status = http.StatusOK
+	}
+	if len(testcase.Expect.Header) > 0 {
+		mapping.Response.Headers = map[string]string{}
+		for key, val := range testcase.Expect.Header {
+			mapping.Response.Headers[key] = val
+		}
+	}
+
+	if mapping.Response.Body, err = ExpectedBody(testcase.Expect); err == nil && mapping.Response.Body != "" &&
+		testcase.Expect.Body == "" {
+		if mapping.Response.Headers == nil {
+			mapping.Response.Headers = map[string]string{}
+		}
+		mapping.Response.Headers[util.ContentType] = "application/json"
+	}
+	return
+}
+
+// SplitAPI returns the path and query of an API which might be a relative path or contains templates
+func SplitAPI(api string) (path string, query url.Values) {
+	path = strings.TrimSpace(api)
+	if index := strings.Index(path, "://"); index >= 0 {
+		path = path[index+3:]
+		if index = strings.Index(path, "/"); index >= 0 {
+			path = path[index:]
+		} else {
+			path = "/"
+		}
+	} else if strings.HasPrefix(path, "{{") {
+		// the prefix template is the server address, such as: {{.server}}/users
+		if index := strings.Index(path, "}}"); index >= 0 {
+			path = path[index+2:]
+		}
+	}
+
+	query = url.Values{}
+	if index := strings.Index(path, "?"); index >= 0 {
+		if values, err := url.ParseQuery(path[index+1:]); err == nil {
+			query = values
+		}
+		path = path[:index]
+	}
+
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return
+}
+
+// ExpectedBody returns the expected body, or builds a JSON object from the expected fields
+func ExpectedBody(expect testing.Response) (body string, err error) {
+	if expect.Body != "" || len(expect.BodyFieldsExpect) == 0 {
+		body = strings.TrimSpace(expect.Body)
+		return
+	}
+
+	object := map[string]interface{}{}
+	for key, val := range expect.BodyFieldsExpect {
+		current := object
+		fields := strings.Split(key, "/")
+		for i, field := range fields {
+			if i == len(fields)-1 {
+				current[field] = val
+				break
+			}
+
+			next, ok := current[field].(map[string]interface{})
+			if !ok {
+				next = map[string]interface{}{}
+				current[field] = next
+			}
+			current = next
+		}
+	}
+
+	var data []byte
+	if data, err = json.Marshal(object); err == nil {
+		body = string(data)
+	}
+	return
+}
+
+var templateReg = regexp.MustCompile(`\{\{.*?\}\}`)
+
+// ToPathPattern converts the path which might contain templates to be a regular expression
+func ToPathPattern(path string) string {
+	parts := templateReg.Split(path, -1)
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	return strings.Join(parts, "[^/]+")
+}
+
+func emptyThenDefault(val, defVal string) string {
+	if strings.TrimSpace(val) == "" {
+		val = defVal
+	}
+	return val
+}
+
+func init() {
+	RegisterTestSuiteConverter("wiremock", NewWireMockConverter())
+}
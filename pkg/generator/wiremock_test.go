@@ -0,0 +1,66 @@
+package generator
+
+import (
+	"net/http"
+	"testing"
+
+	_ "embed"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWireMockConverter(t *testing.T) {
+	suite := &atest.TestSuite{
+		Name: "simple",
+		API:  "http://localhost/api/v4",
+		Items: []atest.TestCase{{
+			Name: "projects",
+			Request: atest.Request{
+				API: "/projects?page=1",
+			},
+			Expect: atest.Response{
+				Body: `[{"id": 1}]`,
+				Header: map[string]string{
+					"Content-Type": "application/json",
+				},
+			},
+		}, {
+			Name: "project",
+			Request: atest.Request{
+				API:    "http://localhost/api/v4/projects/{{(index .projects 0).id}}",
+				Method: http.MethodPut,
+				Query: map[string]string{
+					"user": "{{.user}}",
+				},
+			},
+			Expect: atest.Response{
+				StatusCode: http.StatusCreated,
+				BodyFieldsExpect: map[string]interface{}{
+					"name":         "linuxsuren",
+					"owner/number": 1,
+				},
+			},
+		}},
+	}
+
+	result, err := NewWireMockConverter().Convert(suite)
+	assert.Nil(t, err)
+	assert.JSONEq(t, expectedWireMockMappings, result)
+}
+
+func TestSplitAPI(t *testing.T) {
+	path, query := SplitAPI("https://foo.com")
+	assert.Equal(t, "/", path)
+	assert.Empty(t, query)
+
+	path, query = SplitAPI("foo?name=bar")
+	assert.Equal(t, "/foo", path)
+	assert.Equal(t, "bar", query.Get("name"))
+
+	path, _ = SplitAPI("{{.server}}/users/{{.name}}")
+	assert.Equal(t, "/users/{{.name}}", path)
+}
+
+//go:embed testdata/wiremock.json
+var expectedWireMockMappings string
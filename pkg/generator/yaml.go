@@ -0,0 +1,60 @@
+package generator
+
+import (
+	"encoding/json"
+
+	"github.com/ghodss/yaml"
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+// ToYAML marshals the test suite to be YAML, the empty fields will be omitted
+func ToYAML(suite *testing.TestSuite) (result string, err error) {
+	var data []byte
+	if data, err = json.Marshal(suite); err != nil {
+		return
+	}
+
+	var object interface{}
+	if err = json.Unmarshal(data, &object); err != nil {
+		return
+	}
+
+	if data, err = json.Marshal(pruneEmpty(object)); err == nil {
+		if data, err = yaml.JSONToYAML(data); err == nil {
+			result = string(data)
+		}
+	}
+	return
+}
+
+func pruneEmpty(object interface{}) interface{} {
+	switch val := object.(type) {
+	case map[string]interface{}:
+		for key, item := range val {
+			if item = pruneEmpty(item); isEmpty(item) {
+				delete(val, key)
+			} else {
+				val[key] = item
+			}
+		}
+	case []interface{}:
+		for i := range val {
+			val[i] = pruneEmpty(val[i])
+		}
+	}
+	return object
+}
+
+func isEmpty(object interface{}) bool {
+	switch val := object.(type) {
+	case nil:
+		return true
+	case string:
+		return val == ""
+	case map[string]interface{}:
+		return len(val) == 0
+	case []interface{}:
+		return len(val) == 0
+	}
+	return false
+}
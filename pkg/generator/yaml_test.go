@@ -0,0 +1,38 @@
+package generator
+
+import (
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToYAML(t *testing.T) {
+	result, err := ToYAML(&atest.TestSuite{
+		Name: "simple",
+		Items: []atest.TestCase{{
+			Name: "foo",
+			Request: atest.Request{
+				API: "/foo",
+			},
+		}},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, `items:
+- name: foo
+  request:
+    api: /foo
+name: simple
+`, result)
+}
+
+func TestUniqueNames(t *testing.T) {
+	suite := &atest.TestSuite{
+		Items: []atest.TestCase{{Name: "foo"}, {Name: "bar"}, {Name: "foo"}, {Name: "foo"}},
+	}
+	uniqueNames(suite)
+	assert.Equal(t, "foo", suite.Items[0].Name)
+	assert.Equal(t, "bar", suite.Items[1].Name)
+	assert.Equal(t, "foo-1", suite.Items[2].Name)
+	assert.Equal(t, "foo-2", suite.Items[3].Name)
+}
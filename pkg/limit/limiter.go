@@ -10,6 +10,11 @@ type RateLimiter interface {
 	Accept()
 	Stop()
 	Burst() int32
+	Pause()
+	Resume()
+	Paused() bool
+	QPS() int32
+	SetQPS(qps, burst int32)
 }
 
 type defaultRateLimiter struct {
@@ -17,6 +22,7 @@ type defaultRateLimiter struct {
 	burst     int32
 	lastToken time.Time
 	singal    chan struct{}
+	resume    chan struct{}
 	mu        sync.Mutex
 }
 
@@ -50,12 +56,20 @@ func (r *defaultRateLimiter) resver() (delay time.Duration, ok bool) {
 		r.Setburst(r.Burst() - 1)
 		ok = true
 	} else {
-		delay = time.Second / time.Duration(r.qps)
+		delay = time.Second / time.Duration(r.QPS())
 	}
 	return
 }
 
 func (r *defaultRateLimiter) Accept() {
+	// block until it's resumed
+	r.mu.Lock()
+	resume := r.resume
+	r.mu.Unlock()
+	if resume != nil {
+		<-resume
+	}
+
 	delay, ok := r.resver()
 	if ok {
 		return
@@ -73,13 +87,59 @@ func (r *defaultRateLimiter) Setburst(burst int32) {
 	r.burst = burst
 }
 
+// QPS returns the current QPS
+func (r *defaultRateLimiter) QPS() int32 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.qps
+}
+
+// SetQPS changes the QPS and burst on the fly, the non-positive values will be ignored
+func (r *defaultRateLimiter) SetQPS(qps, burst int32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if qps > 0 {
+		r.qps = qps
+	}
+	if burst > 0 {
+		r.burst = burst
+	}
+}
+
 func (r *defaultRateLimiter) Burst() int32 {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	return r.burst
 }
 
+// Pause makes Accept block until Resume is called
+func (r *defaultRateLimiter) Pause() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.resume == nil {
+		r.resume = make(chan struct{})
+	}
+}
+
+// Resume releases the callers which are blocked by Pause
+func (r *defaultRateLimiter) Resume() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.resume != nil {
+		close(r.resume)
+		r.resume = nil
+	}
+}
+
+// Paused indicates if the limiter is paused
+func (r *defaultRateLimiter) Paused() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.resume != nil
+}
+
 func (r *defaultRateLimiter) Stop() {
+	r.Resume()
 	r.singal <- struct{}{}
 }
 
@@ -87,7 +147,7 @@ func (r *defaultRateLimiter) updateBurst() {
 	for {
 		select {
 		case <-time.After(time.Second):
-			r.Setburst(r.Burst() + r.qps)
+			r.Setburst(r.Burst() + r.QPS())
 		case <-r.singal:
 			return
 		}
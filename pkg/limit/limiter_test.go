@@ -25,3 +25,57 @@ func TestXxx(t *testing.T) {
 	}
 	assert.True(t, num <= 10)
 }
+
+func TestPauseAndResume(t *testing.T) {
+	limiter := NewDefaultRateLimiter(100, 100)
+	defer limiter.Stop()
+	assert.False(t, limiter.Paused())
+
+	limiter.Pause()
+	limiter.Pause()
+	assert.True(t, limiter.Paused())
+
+	accepted := make(chan struct{})
+	go func() {
+		limiter.Accept()
+		close(accepted)
+	}()
+
+	select {
+	case <-accepted:
+		t.Fatal("should be blocked when the limiter is paused")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	limiter.Resume()
+	assert.False(t, limiter.Paused())
+	select {
+	case <-accepted:
+	case <-time.After(time.Second):
+		t.Fatal("should be released after resumed")
+	}
+}
+
+func TestStopWhenPaused(t *testing.T) {
+	limiter := NewDefaultRateLimiter(1, 1)
+	limiter.Pause()
+	limiter.Stop()
+	assert.False(t, limiter.Paused())
+	limiter.Accept()
+}
+
+func TestSetQPS(t *testing.T) {
+	limiter := NewDefaultRateLimiter(1, 2)
+	defer limiter.Stop()
+	assert.Equal(t, int32(1), limiter.QPS())
+	assert.Equal(t, int32(2), limiter.Burst())
+
+	limiter.SetQPS(10, 20)
+	assert.Equal(t, int32(10), limiter.QPS())
+	assert.Equal(t, int32(20), limiter.Burst())
+
+	// the non-positive values are ignored
+	limiter.SetQPS(0, -1)
+	assert.Equal(t, int32(10), limiter.QPS())
+	assert.Equal(t, int32(20), limiter.Burst())
+}
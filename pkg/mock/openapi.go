@@ -0,0 +1,308 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/linuxsuren/api-testing/pkg/util"
+)
+
+type openAPIHandler struct {
+	spec       *openAPISpec
+	operations []openAPIOperationRoute
+}
+
+type openAPISpec struct {
+	Paths       map[string]map[string]json.RawMessage `json:"paths"`
+	Definitions map[string]*openAPISchema             `json:"definitions"`
+	Components  struct {
+		Schemas map[string]*openAPISchema `json:"schemas"`
+	} `json:"components"`
+}
+
+type openAPIOperation struct {
+	Responses map[string]openAPIResponse `json:"responses"`
+}
+
+type openAPIResponse struct {
+	// Content is the media types of OpenAPI 3
+	Content map[string]openAPIMediaType `json:"content"`
+	// Examples and Schema are the fields of Swagger 2
+	Examples map[string]interface{} `json:"examples"`
+	Schema   *openAPISchema         `json:"schema"`
+}
+
+type openAPIMediaType struct {
+	Example  interface{}               `json:"example"`
+	Examples map[string]openAPIExample `json:"examples"`
+	Schema   *openAPISchema            `json:"schema"`
+}
+
+type openAPIExample struct {
+	Value interface{} `json:"value"`
+}
+
+type openAPISchema struct {
+	Ref        string                    `json:"$ref"`
+	Type       string                    `json:"type"`
+	Format     string                    `json:"format"`
+	Example    interface{}               `json:"example"`
+	Default    interface{}               `json:"default"`
+	Enum       []interface{}             `json:"enum"`
+	Properties map[string]*openAPISchema `json:"properties"`
+	Items      *openAPISchema            `json:"items"`
+	AllOf      []*openAPISchema          `json:"allOf"`
+	OneOf      []*openAPISchema          `json:"oneOf"`
+	AnyOf      []*openAPISchema          `json:"anyOf"`
+}
+
+type openAPIOperationRoute struct {
+	method    string
+	path      *regexp.Regexp
+	operation openAPIOperation
+}
+
+// NewOpenAPIHandler creates a HTTP handler which serves the examples of the OpenAPI (or Swagger) spec,
+// the examples will be generated from the schema if there are no examples
+func NewOpenAPIHandler(data []byte) (handler http.Handler, err error) {
+	if data, err = yaml.YAMLToJSON(data); err != nil {
+		return
+	}
+
+	spec := &openAPISpec{}
+	if err = json.Unmarshal(data, spec); err != nil {
+		return
+	}
+	if len(spec.Paths) == 0 {
+		err = fmt.Errorf("no paths found in the OpenAPI spec")
+		return
+	}
+
+	h := &openAPIHandler{spec: spec}
+	for path, operations := range spec.Paths {
+		for method, raw := range operations {
+			// skip the fields which are not operations, such as: parameters, summary
+			if !openAPIMethods[method] {
+				continue
+			}
+
+			var operation openAPIOperation
+			if err = json.Unmarshal(raw, &operation); err != nil {
+				return
+			}
+			h.operations = append(h.operations, openAPIOperationRoute{
+				method:    strings.ToUpper(method),
+				path:      regexp.MustCompile("^" + toPathRegexp(path) + "$"),
+				operation: operation,
+			})
+		}
+	}
+	// the static paths have higher priority than the parameterized ones, such as: /users/me and /users/{id}
+	sort.SliceStable(h.operations, func(i, j int) bool {
+		return strings.Count(h.operations[i].path.String(), "[^/]+") < strings.Count(h.operations[j].path.String(), "[^/]+")
+	})
+	handler = h
+	return
+}
+
+var openAPIMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true,
+	"options": true, "head": true, "patch": true, "trace": true,
+}
+
+var pathParamReg = regexp.MustCompile(`\{[^/{}]+\}`)
+
+func toPathRegexp(path string) string {
+	parts := pathParamReg.Split(path, -1)
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	return strings.Join(parts, "[^/]+")
+}
+
+// ServeHTTP serves the example of the first matched operation
+func (h *openAPIHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	for _, route := range h.operations {
+		if route.method != req.Method || !route.path.MatchString(req.URL.Path) {
+			continue
+		}
+
+		status, contentType, example := h.example(route.operation)
+		if contentType != "" {
+			w.Header().Set(util.ContentType, contentType)
+		}
+		w.WriteHeader(status)
+
+		if text, ok := example.(string); ok && !strings.Contains(contentType, "json") {
+			_, _ = w.Write([]byte(text))
+		} else if example != nil {
+			_ = json.NewEncoder(w).Encode(example)
+		}
+		return
+	}
+	writeNotFound(w, req)
+}
+
+// example returns the example of the first successful response
+func (h *openAPIHandler) example(operation openAPIOperation) (status int, contentType string, example interface{}) {
+	code := preferredStatusCode(operation.Responses)
+	if status, _ = strconv.Atoi(code); status == 0 {
+		status = http.StatusOK
+	}
+
+	response, ok := operation.Responses[code]
+	if !ok {
+		return
+	}
+
+	if len(response.Content) > 0 {
+		contentType = preferredContentType(response.Content)
+		media := response.Content[contentType]
+		switch {
+		case media.Example != nil:
+			example = media.Example
+		case len(media.Examples) > 0:
+			var names []string
+			for name := range media.Examples {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			example = media.Examples[names[0]].Value
+		default:
+			example = h.exampleOfSchema(media.Schema, 0)
+		}
+	} else if len(response.Examples) > 0 {
+		var types []string
+		for key := range response.Examples {
+			types = append(types, key)
+		}
+		sort.Strings(types)
+		contentType = types[0]
+		example = response.Examples[contentType]
+	} else if response.Schema != nil {
+		contentType = "application/json"
+		example = h.exampleOfSchema(response.Schema, 0)
+	}
+	return
+}
+
+func preferredStatusCode(responses map[string]openAPIResponse) (code string) {
+	var codes []string
+	for key := range responses {
+		codes = append(codes, key)
+	}
+	sort.Strings(codes)
+
+	for _, key := range codes {
+		if strings.HasPrefix(key, "2") {
+			return key
+		}
+	}
+	if _, ok := responses["default"]; ok {
+		return "default"
+	}
+	if len(codes) > 0 {
+		code = codes[0]
+	}
+	return
+}
+
+func preferredContentType(content map[string]openAPIMediaType) string {
+	var types []string
+	for key := range content {
+		types = append(types, key)
+	}
+	sort.Strings(types)
+
+	for _, key := range types {
+		if strings.Contains(key, "json") {
+			return key
+		}
+	}
+	return types[0]
+}
+
+const maxSchemaDepth = 10
+
+// exampleOfSchema generates an example from the schema
+func (h *openAPIHandler) exampleOfSchema(schema *openAPISchema, depth int) interface{} {
+	if schema == nil || depth > maxSchemaDepth {
+		return nil
+	}
+
+	if schema.Ref != "" {
+		return h.exampleOfSchema(h.resolveRef(schema.Ref), depth+1)
+	}
+
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case schema.Default != nil:
+		return schema.Default
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	case len(schema.OneOf) > 0:
+		return h.exampleOfSchema(schema.OneOf[0], depth+1)
+	case len(schema.AnyOf) > 0:
+		return h.exampleOfSchema(schema.AnyOf[0], depth+1)
+	case len(schema.AllOf) > 0:
+		object := map[string]interface{}{}
+		for _, item := range schema.AllOf {
+			if fields, ok := h.exampleOfSchema(item, depth+1).(map[string]interface{}); ok {
+				for key, val := range fields {
+					object[key] = val
+				}
+			}
+		}
+		return object
+	}
+
+	switch schema.Type {
+	case "array":
+		return []interface{}{h.exampleOfSchema(schema.Items, depth+1)}
+	case "string":
+		return exampleOfString(schema.Format)
+	case "integer":
+		return 0
+	case "number":
+		return 0.0
+	case "boolean":
+		return true
+	default:
+		object := map[string]interface{}{}
+		for key, property := range schema.Properties {
+			object[key] = h.exampleOfSchema(property, depth+1)
+		}
+		return object
+	}
+}
+
+func (h *openAPIHandler) resolveRef(ref string) *openAPISchema {
+	name := ref[strings.LastIndex(ref, "/")+1:]
+	if strings.HasPrefix(ref, "#/definitions/") {
+		return h.spec.Definitions[name]
+	}
+	return h.spec.Components.Schemas[name]
+}
+
+func exampleOfString(format string) string {
+	switch format {
+	case "date":
+		return "2006-01-02"
+	case "date-time":
+		return "2006-01-02T15:04:05Z"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "00000000-0000-0000-0000-000000000000"
+	case "uri", "url":
+		return "https://example.com"
+	}
+	return "string"
+}
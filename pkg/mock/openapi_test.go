@@ -0,0 +1,110 @@
+package mock
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	_ "embed"
+
+	"github.com/linuxsuren/api-testing/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenAPIHandler(t *testing.T) {
+	handler, err := NewOpenAPIHandler([]byte(openAPISpecForTest))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		status      int
+		contentType string
+		body        string
+	}{{
+		name:        "example",
+		method:      http.MethodGet,
+		path:        "/users",
+		status:      http.StatusOK,
+		contentType: "application/json",
+		body:        `[{"name":"linuxsuren"}]`,
+	}, {
+		name:        "generated from the schema",
+		method:      http.MethodPost,
+		path:        "/users",
+		status:      http.StatusCreated,
+		contentType: "application/json",
+		body:        `{"age":0,"email":"user@example.com","name":"string","roles":["admin"]}`,
+	}, {
+		name:        "named examples of the default response",
+		method:      http.MethodGet,
+		path:        "/users/linuxsuren",
+		status:      http.StatusOK,
+		contentType: "application/json",
+		body:        `{"name":"admin"}`,
+	}, {
+		name:        "static path first",
+		method:      http.MethodGet,
+		path:        "/users/me",
+		status:      http.StatusOK,
+		contentType: "text/plain",
+		body:        "me",
+	}, {
+		name:        "not found",
+		method:      http.MethodDelete,
+		path:        "/users",
+		status:      http.StatusNotFound,
+		contentType: "application/json",
+		body:        `{"message":"no mock found for DELETE /users"}`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.path, nil))
+			assert.Equal(t, tt.status, recorder.Code)
+			assert.Equal(t, tt.contentType, recorder.Header().Get(util.ContentType))
+			if tt.contentType == "application/json" {
+				assert.JSONEq(t, tt.body, recorder.Body.String())
+			} else {
+				assert.Equal(t, tt.body, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestSwaggerExamples(t *testing.T) {
+	handler, err := NewOpenAPIHandler([]byte(`{
+		"swagger": "2.0",
+		"paths": {"/version": {"get": {"responses": {"200": {"examples": {"application/json": {"version": "v1"}}}}}},
+			"/health": {"get": {"responses": {"200": {"schema": {"$ref": "#/definitions/Health"}}}}}},
+		"definitions": {"Health": {"type": "object", "properties": {"ok": {"type": "boolean"}}}}
+	}`))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/version", nil))
+	assert.JSONEq(t, `{"version":"v1"}`, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.JSONEq(t, `{"ok":true}`, recorder.Body.String())
+}
+
+func TestInvalidOpenAPISpec(t *testing.T) {
+	_, err := NewOpenAPIHandler([]byte("fake"))
+	assert.NotNil(t, err)
+
+	_, err = NewOpenAPIHandler([]byte(`{"paths": {}}`))
+	assert.NotNil(t, err)
+
+	_, err = NewOpenAPIHandler([]byte(`{"paths": {"/": {"get": []}}}`))
+	assert.NotNil(t, err)
+}
+
+//go:embed testdata/openapi.yaml
+var openAPISpecForTest string
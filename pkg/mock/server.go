@@ -0,0 +1,122 @@
+// Package mock provides a HTTP server which serves the expected responses of the test cases
+package mock
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+
+	"github.com/linuxsuren/api-testing/pkg/generator"
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/linuxsuren/api-testing/pkg/util"
+)
+
+type suiteHandler struct {
+	routes   []route
+	fallback http.Handler
+}
+
+type route struct {
+	method string
+	path   *regexp.Regexp
+	query  map[string]*regexp.Regexp
+	status int
+	header map[string]string
+	body   string
+}
+
+// NewSuiteHandler creates a HTTP handler which serves the expected responses of the test cases,
+// the requests which don't match any test cases will be passed to the fallback handler
+func NewSuiteHandler(suites []*testing.TestSuite, fallback http.Handler) (handler http.Handler, err error) {
+	h := &suiteHandler{fallback: fallback}
+	for _, suite := range suites {
+		for _, testCase := range suite.Items {
+			var r route
+			if r, err = toRoute(suite, testCase); err != nil {
+				return
+			}
+			h.routes = append(h.routes, r)
+		}
+	}
+	handler = h
+	return
+}
+
+func toRoute(suite *testing.TestSuite, testCase testing.TestCase) (r route, err error) {
+	path, query := generator.SplitAPI(generator.FullAPI(suite, testCase.Request.API))
+	for key, val := range testCase.Request.Query {
+		query.Set(key, val)
+	}
+
+	r = route{
+		method: testCase.Request.Method,
+		path:   regexp.MustCompile("^" + generator.ToPathPattern(path) + "$"),
+		query:  map[string]*regexp.Regexp{},
+		status: testCase.Expect.StatusCode,
+		header: map[string]string{},
+	}
+	if r.method == "" {
+		r.method = http.MethodGet
+	}
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	for key := range query {
+		r.query[key] = regexp.MustCompile("^" + generator.ToPathPattern(query.Get(key)) + "$")
+	}
+	for key, val := range testCase.Expect.Header {
+		r.header[key] = val
+	}
+
+	if r.body, err = generator.ExpectedBody(testCase.Expect); err == nil &&
+		testCase.Expect.Body == "" && r.body != "" {
+		if _, ok := r.header[util.ContentType]; !ok {
+			r.header[util.ContentType] = "application/json"
+		}
+	}
+	return
+}
+
+func (r route) match(req *http.Request) bool {
+	if r.method != req.Method || !r.path.MatchString(req.URL.Path) {
+		return false
+	}
+
+	query := req.URL.Query()
+	for key, pattern := range r.query {
+		if !pattern.MatchString(query.Get(key)) {
+			return false
+		}
+	}
+	return true
+}
+
+// ServeHTTP serves the response of the first matched test case
+func (h *suiteHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	for _, r := range h.routes {
+		if !r.match(req) {
+			continue
+		}
+
+		for key, val := range r.header {
+			w.Header().Set(key, val)
+		}
+		w.WriteHeader(r.status)
+		_, _ = w.Write([]byte(r.body))
+		return
+	}
+
+	if h.fallback != nil {
+		h.fallback.ServeHTTP(w, req)
+		return
+	}
+	writeNotFound(w, req)
+}
+
+func writeNotFound(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set(util.ContentType, "application/json")
+	w.WriteHeader(http.StatusNotFound)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"message": "no mock found for " + req.Method + " " + req.URL.Path,
+	})
+}
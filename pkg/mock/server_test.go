@@ -0,0 +1,91 @@
+package mock
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/linuxsuren/api-testing/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuiteHandler(t *testing.T) {
+	fallback := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	suites := []*atest.TestSuite{{
+		API: "http://foo.com/api/v1",
+		Items: []atest.TestCase{{
+			Request: atest.Request{
+				API: "{{.server}}/users/{{.name}}?type=admin",
+			},
+			Expect: atest.Response{
+				Header: map[string]string{
+					"Server": "atest",
+				},
+				BodyFieldsExpect: map[string]interface{}{
+					"name": "linuxsuren",
+				},
+			},
+		}, {
+			Request: atest.Request{
+				API:    "/users",
+				Method: http.MethodPost,
+			},
+			Expect: atest.Response{
+				StatusCode: http.StatusCreated,
+				Body:       "created",
+			},
+		}},
+	}}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		verify func(*testing.T, *httptest.ResponseRecorder)
+	}{{
+		name:   "match the templates",
+		method: http.MethodGet,
+		path:   "/users/linuxsuren?type=admin",
+		verify: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, "atest", recorder.Header().Get("Server"))
+			assert.Equal(t, "application/json", recorder.Header().Get(util.ContentType))
+			assert.Equal(t, `{"name":"linuxsuren"}`, recorder.Body.String())
+		},
+	}, {
+		name:   "match the method",
+		method: http.MethodPost,
+		path:   "/api/v1/users",
+		verify: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			assert.Equal(t, http.StatusCreated, recorder.Code)
+			assert.Equal(t, "created", recorder.Body.String())
+		},
+	}, {
+		name:   "query does not match",
+		method: http.MethodGet,
+		path:   "/users/linuxsuren?type=guest",
+		verify: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			assert.Equal(t, http.StatusTeapot, recorder.Code)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, err := NewSuiteHandler(suites, fallback)
+			assert.Nil(t, err)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.path, nil))
+			tt.verify(t, recorder)
+		})
+	}
+
+	handler, err := NewSuiteHandler(suites, nil)
+	assert.Nil(t, err)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, "/users", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
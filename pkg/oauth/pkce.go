@@ -0,0 +1,271 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/linuxsuren/api-testing/pkg/util"
+)
+
+// discoveryPath is the path of the OpenID Connect discovery document of an issuer
+const discoveryPath = "/.well-known/openid-configuration"
+
+// callbackPath is the path of the redirect URI which is served by the local callback server
+const callbackPath = "/callback"
+
+// Profile is the OAuth2 client of the authorization code flow,
+// the endpoints are discovered from the issuer if they're empty
+type Profile struct {
+	Issuer       string `json:"issuer,omitempty"`
+	AuthURL      string `json:"authURL,omitempty"`
+	TokenURL     string `json:"tokenURL,omitempty"`
+	ClientID     string `json:"clientID"`
+	ClientSecret string `json:"clientSecret,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// Token is the token response, the expiry is calculated from the expires_in when it's received
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	IDToken      string    `json:"id_token,omitempty"`
+	ExpiresIn    int64     `json:"expires_in,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+// expiryDelta makes the token be refreshed a bit earlier than it expires
+const expiryDelta = 30 * time.Second
+
+// Valid returns true if the access token is not expired, the token never expires if it has no expiry
+func (t *Token) Valid() bool {
+	return t != nil && t.AccessToken != "" && (t.Expiry.IsZero() || time.Now().Add(expiryDelta).Before(t.Expiry))
+}
+
+// Discover sets the authorization and token endpoints from the discovery document of the issuer
+func (p *Profile) Discover(ctx context.Context) (err error) {
+	if p.AuthURL != "" && p.TokenURL != "" {
+		return
+	}
+	if p.Issuer == "" {
+		err = fmt.Errorf("the issuer is required if the auth URL or token URL is empty")
+		return
+	}
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodGet,
+		strings.TrimSuffix(p.Issuer, "/")+discoveryPath, nil); err != nil {
+		return
+	}
+
+	var data []byte
+	if data, err = do(req); err != nil {
+		err = fmt.Errorf("failed to discover the issuer %s, %v", p.Issuer, err)
+		return
+	}
+
+	discovery := struct {
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+	}{}
+	if err = json.Unmarshal(data, &discovery); err != nil {
+		return
+	}
+	p.AuthURL = emptyThenDefault(p.AuthURL, discovery.AuthorizationEndpoint)
+	p.TokenURL = emptyThenDefault(p.TokenURL, discovery.TokenEndpoint)
+	if p.AuthURL == "" || p.TokenURL == "" {
+		err = fmt.Errorf("the authorization or token endpoint is not found in the discovery document")
+	}
+	return
+}
+
+// Login runs the authorization code flow with PKCE. The callback server listens on the address until the
+// authorization response is received, and the browser is opened with the authorization URL
+func Login(ctx context.Context, profile *Profile, listen string, openBrowser func(string) error) (token *Token, err error) {
+	if err = profile.Discover(ctx); err != nil {
+		return
+	}
+
+	var listener net.Listener
+	if listener, err = net.Listen("tcp", listen); err != nil {
+		return
+	}
+	defer listener.Close()
+
+	host, _, _ := net.SplitHostPort(listen)
+	redirectURI := fmt.Sprintf("http://%s%s", net.JoinHostPort(emptyThenDefault(host, "localhost"),
+		fmt.Sprint(listener.Addr().(*net.TCPAddr).Port)), callbackPath)
+
+	var verifier, state string
+	if verifier, err = randomString(); err != nil {
+		return
+	}
+	if state, err = randomString(); err != nil {
+		return
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+
+	var authURL *url.URL
+	if authURL, err = url.Parse(profile.AuthURL); err != nil {
+		return
+	}
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", profile.ClientID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("state", state)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+	if profile.Scope != "" {
+		query.Set("scope", profile.Scope)
+	}
+	authURL.RawQuery = query.Encode()
+
+	codes := make(chan string, 1)
+	errs := make(chan error, 1)
+	server := &http.Server{Handler: newCallbackHandler(state, codes, errs)}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Close()
+
+	if err = openBrowser(authURL.String()); err != nil {
+		return
+	}
+
+	var code string
+	select {
+	case code = <-codes:
+	case err = <-errs:
+		return
+	case <-ctx.Done():
+		err = fmt.Errorf("no authorization response was received, %v", ctx.Err())
+		return
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURI)
+	form.Set("code_verifier", verifier)
+	token, err = requestToken(ctx, profile, form)
+	return
+}
+
+// Refresh acquires a new access token by the refresh token, the refresh token is kept if there's no new one
+func Refresh(ctx context.Context, profile *Profile, refreshToken string) (token *Token, err error) {
+	if refreshToken == "" {
+		err = fmt.Errorf("no refresh token")
+		return
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", refreshToken)
+	if token, err = requestToken(ctx, profile, form); err == nil && token.RefreshToken == "" {
+		token.RefreshToken = refreshToken
+	}
+	return
+}
+
+// newCallbackHandler receives the authorization response, the state must be the same as the one of the request
+func newCallbackHandler(state string, codes chan<- string, errs chan<- error) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(callbackPath, func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		switch {
+		case query.Get("error") != "":
+			errs <- fmt.Errorf("authorization failed, %s: %s", query.Get("error"), query.Get("error_description"))
+			http.Error(w, "Login failed, please check the terminal.", http.StatusBadRequest)
+		case query.Get("state") != state:
+			// it might be a forged request, so keep waiting for the real one
+			http.Error(w, "Invalid state.", http.StatusBadRequest)
+		case query.Get("code") == "":
+			errs <- fmt.Errorf("no authorization code in the callback")
+			http.Error(w, "No authorization code.", http.StatusBadRequest)
+		default:
+			select {
+			case codes <- query.Get("code"):
+			default:
+			}
+			_, _ = w.Write([]byte("Login succeeded, you can close the window now."))
+		}
+	})
+	return mux
+}
+
+// requestToken sends the form to the token endpoint, the client secret is sent by the client_secret_basic
+func requestToken(ctx context.Context, profile *Profile, form url.Values) (token *Token, err error) {
+	if profile.ClientSecret == "" {
+		form.Set("client_id", profile.ClientID)
+	}
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodPost, profile.TokenURL,
+		strings.NewReader(form.Encode())); err != nil {
+		return
+	}
+	req.Header.Set(util.ContentType, util.Form)
+	if profile.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(profile.ClientID), url.QueryEscape(profile.ClientSecret))
+	}
+
+	var data []byte
+	if data, err = do(req); err != nil {
+		err = fmt.Errorf("failed to request the token, %v", err)
+		return
+	}
+
+	token = &Token{}
+	if err = json.Unmarshal(data, token); err != nil {
+		err = fmt.Errorf("not a valid token response, %v", err)
+		return
+	}
+	if token.AccessToken == "" {
+		err = fmt.Errorf("no access token in the token response")
+		return
+	}
+	if token.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return
+}
+
+func do(req *http.Request) (data []byte, err error) {
+	var resp *http.Response
+	if resp, err = http.DefaultClient.Do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if data, err = io.ReadAll(resp.Body); err == nil && resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("unexpected status code %d, %s", resp.StatusCode, string(data))
+	}
+	return
+}
+
+// randomString returns 32 random bytes in the base64url encoding, it's the recommended code verifier of RFC 7636
+func randomString() (text string, err error) {
+	data := make([]byte, 32)
+	if _, err = rand.Read(data); err == nil {
+		text = base64.RawURLEncoding.EncodeToString(data)
+	}
+	return
+}
+
+func emptyThenDefault(val, defVal string) string {
+	if val == "" {
+		val = defVal
+	}
+	return val
+}
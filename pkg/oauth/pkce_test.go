@@ -0,0 +1,141 @@
+package oauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogin(t *testing.T) {
+	server := newFakeIssuer(t)
+	defer server.Close()
+
+	// the browser follows the redirection to the callback server
+	browser := func(authURL string) (err error) {
+		go func() {
+			if resp, err := http.Get(authURL); err == nil {
+				resp.Body.Close()
+			}
+		}()
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	t.Run("normal", func(t *testing.T) {
+		profile := &Profile{Issuer: server.URL, ClientID: "atest", Scope: "openid"}
+		token, err := Login(ctx, profile, "127.0.0.1:0", browser)
+		if assert.Nil(t, err) {
+			assert.Equal(t, "access-1", token.AccessToken)
+			assert.Equal(t, "refresh-1", token.RefreshToken)
+			assert.True(t, token.Valid())
+		}
+		assert.Equal(t, server.URL+"/authorize", profile.AuthURL)
+		assert.Equal(t, server.URL+"/token", profile.TokenURL)
+
+		token, err = Refresh(ctx, profile, token.RefreshToken)
+		if assert.Nil(t, err) {
+			assert.Equal(t, "access-2", token.AccessToken)
+			assert.Equal(t, "refresh-1", token.RefreshToken)
+		}
+	})
+
+	t.Run("denied", func(t *testing.T) {
+		profile := &Profile{Issuer: server.URL, ClientID: "denied"}
+		_, err := Login(ctx, profile, "127.0.0.1:0", browser)
+		assert.EqualError(t, err, "authorization failed, access_denied: the user denied")
+	})
+
+	t.Run("not open the browser", func(t *testing.T) {
+		profile := &Profile{Issuer: server.URL, ClientID: "atest"}
+		_, err := Login(ctx, profile, "127.0.0.1:0", func(string) error {
+			return fmt.Errorf("no browser")
+		})
+		assert.EqualError(t, err, "no browser")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		timeoutCtx, timeoutCancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer timeoutCancel()
+
+		profile := &Profile{Issuer: server.URL, ClientID: "atest"}
+		_, err := Login(timeoutCtx, profile, "127.0.0.1:0", func(string) error { return nil })
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), "no authorization response was received")
+		}
+	})
+
+	t.Run("no issuer", func(t *testing.T) {
+		_, err := Login(ctx, &Profile{AuthURL: server.URL + "/authorize"}, "127.0.0.1:0", browser)
+		assert.EqualError(t, err, "the issuer is required if the auth URL or token URL is empty")
+	})
+
+	t.Run("no refresh token", func(t *testing.T) {
+		_, err := Refresh(ctx, &Profile{}, "")
+		assert.EqualError(t, err, "no refresh token")
+	})
+}
+
+// newFakeIssuer approves the client atest, the code verifier must match the code challenge
+func newFakeIssuer(t *testing.T) *httptest.Server {
+	var challenge string
+	refreshed := 0
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	mux.HandleFunc(discoveryPath, func(w http.ResponseWriter, req *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 server.URL,
+			"authorization_endpoint": server.URL + "/authorize",
+			"token_endpoint":         server.URL + "/token",
+		})
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		assert.Equal(t, "code", query.Get("response_type"))
+		assert.Equal(t, "S256", query.Get("code_challenge_method"))
+		assert.True(t, strings.HasSuffix(query.Get("redirect_uri"), callbackPath))
+		challenge = query.Get("code_challenge")
+
+		callback := url.Values{"state": {query.Get("state")}}
+		if query.Get("client_id") == "denied" {
+			callback.Set("error", "access_denied")
+			callback.Set("error_description", "the user denied")
+		} else {
+			callback.Set("code", "fake-code")
+		}
+		http.Redirect(w, req, query.Get("redirect_uri")+"?"+callback.Encode(), http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, req *http.Request) {
+		assert.Nil(t, req.ParseForm())
+		switch req.PostForm.Get("grant_type") {
+		case "authorization_code":
+			hash := sha256.Sum256([]byte(req.PostForm.Get("code_verifier")))
+			if req.PostForm.Get("code") != "fake-code" || base64.RawURLEncoding.EncodeToString(hash[:]) != challenge {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"access_token":"access-1","token_type":"Bearer","refresh_token":"refresh-1","expires_in":3600}`))
+		case "refresh_token":
+			if req.PostForm.Get("refresh_token") != "refresh-1" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+				return
+			}
+			refreshed++
+			_, _ = fmt.Fprintf(w, `{"access_token":"access-%d","token_type":"Bearer","expires_in":3600}`, refreshed+1)
+		}
+	})
+	return server
+}
@@ -0,0 +1,137 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Session is the profile and the last token of a login
+type Session struct {
+	Profile Profile `json:"profile"`
+	Token   Token   `json:"token"`
+}
+
+// Store caches the sessions in a JSON file which is readable by the owner only
+type Store struct {
+	file string
+	lock sync.Mutex
+}
+
+// NewStore creates the store of the file
+func NewStore(file string) *Store {
+	return &Store{file: file}
+}
+
+// DefaultStoreFile returns the auth.json in the user config directory
+func DefaultStoreFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "atest", "auth.json")
+}
+
+// Names returns the names of all the sessions
+func (s *Store) Names() (names []string, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var sessions map[string]*Session
+	if sessions, err = s.load(); err == nil {
+		for name := range sessions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	return
+}
+
+// Save puts the session with the name
+func (s *Store) Save(name string, session *Session) (err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var sessions map[string]*Session
+	if sessions, err = s.load(); err == nil {
+		sessions[name] = session
+		err = s.save(sessions)
+	}
+	return
+}
+
+// Delete removes the session of the name
+func (s *Store) Delete(name string) (err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var sessions map[string]*Session
+	if sessions, err = s.load(); err == nil {
+		if _, ok := sessions[name]; !ok {
+			err = fmt.Errorf("no session named %q", name)
+			return
+		}
+		delete(sessions, name)
+		err = s.save(sessions)
+	}
+	return
+}
+
+// AccessToken returns the token of the session, it's refreshed and saved if the access token is expired
+func (s *Store) AccessToken(ctx context.Context, name string) (token *Token, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var sessions map[string]*Session
+	if sessions, err = s.load(); err != nil {
+		return
+	}
+	session, ok := sessions[name]
+	if !ok {
+		err = fmt.Errorf("no session named %q, please login first: atest auth login %s", name, name)
+		return
+	}
+	if session.Token.Valid() {
+		token = &session.Token
+		return
+	}
+
+	if token, err = Refresh(ctx, &session.Profile, session.Token.RefreshToken); err != nil {
+		err = fmt.Errorf("the access token of %q is expired and failed to refresh, please login again, %v", name, err)
+		return
+	}
+	session.Token = *token
+	err = s.save(sessions)
+	return
+}
+
+func (s *Store) load() (sessions map[string]*Session, err error) {
+	sessions = map[string]*Session{}
+
+	var data []byte
+	if data, err = os.ReadFile(s.file); err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+	if err = json.Unmarshal(data, &sessions); err != nil {
+		err = fmt.Errorf("invalid auth store %s, %v", s.file, err)
+	}
+	return
+}
+
+func (s *Store) save(sessions map[string]*Session) (err error) {
+	var data []byte
+	if data, err = json.MarshalIndent(sessions, "", "  "); err != nil {
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(s.file), 0700); err == nil {
+		err = os.WriteFile(s.file, data, 0600)
+	}
+	return
+}
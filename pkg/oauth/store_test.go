@@ -0,0 +1,79 @@
+package oauth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore(t *testing.T) {
+	server := newFakeIssuer(t)
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "atest", "auth.json")
+	store := NewStore(file)
+	ctx := context.Background()
+
+	names, err := store.Names()
+	assert.Nil(t, err)
+	assert.Empty(t, names)
+
+	_, err = store.AccessToken(ctx, "dev")
+	assert.EqualError(t, err, `no session named "dev", please login first: atest auth login dev`)
+
+	profile := Profile{ClientID: "atest", TokenURL: server.URL + "/token"}
+	assert.Nil(t, store.Save("dev", &Session{Profile: profile, Token: Token{
+		AccessToken: "access-1", RefreshToken: "refresh-1", Expiry: time.Now().Add(time.Hour)}}))
+	assert.Nil(t, store.Save("expired", &Session{Profile: profile, Token: Token{
+		AccessToken: "access-1", RefreshToken: "refresh-1", Expiry: time.Now().Add(-time.Hour)}}))
+	assert.Nil(t, store.Save("no-refresh", &Session{Profile: profile, Token: Token{
+		AccessToken: "access-1", Expiry: time.Now().Add(-time.Hour)}}))
+
+	info, err := os.Stat(file)
+	if assert.Nil(t, err) {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	names, err = store.Names()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"dev", "expired", "no-refresh"}, names)
+
+	token, err := store.AccessToken(ctx, "dev")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "access-1", token.AccessToken)
+	}
+
+	// the refreshed token is saved
+	token, err = store.AccessToken(ctx, "expired")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "access-2", token.AccessToken)
+	}
+	token, err = NewStore(file).AccessToken(ctx, "expired")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "access-2", token.AccessToken)
+		assert.Equal(t, "refresh-1", token.RefreshToken)
+	}
+
+	_, err = store.AccessToken(ctx, "no-refresh")
+	assert.EqualError(t, err, `the access token of "no-refresh" is expired and failed to refresh, please login again, no refresh token`)
+
+	assert.Nil(t, store.Delete("dev"))
+	assert.EqualError(t, store.Delete("dev"), `no session named "dev"`)
+
+	assert.Nil(t, os.WriteFile(file, []byte("fake"), 0600))
+	_, err = store.Names()
+	assert.Contains(t, err.Error(), "invalid auth store")
+}
+
+func TestTokenValid(t *testing.T) {
+	assert.False(t, (*Token)(nil).Valid())
+	assert.False(t, (&Token{}).Valid())
+	assert.True(t, (&Token{AccessToken: "fake"}).Valid())
+	assert.False(t, (&Token{AccessToken: "fake", Expiry: time.Now().Add(time.Second)}).Valid())
+	assert.True(t, (&Token{AccessToken: "fake", Expiry: time.Now().Add(time.Minute)}).Valid())
+	assert.NotEmpty(t, DefaultStoreFile())
+}
@@ -0,0 +1,2 @@
+// Package oci provides the package format of the test suites, and pushes or pulls the packages against the OCI registries
+package oci
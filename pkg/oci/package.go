@@ -0,0 +1,145 @@
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+// MetadataFile is the file of the package metadata which is in the root directory of a package
+const MetadataFile = "atest-pkg.yaml"
+
+// Metadata describes a package of the test suites
+type Metadata struct {
+	Name        string `yaml:"name" json:"name"`
+	Version     string `yaml:"version,omitempty" json:"version,omitempty"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// Suites are the relative paths of the test suites, the other files are the templates or data of them
+	Suites []string `yaml:"suites" json:"suites"`
+}
+
+// Pack validates the metadata and the suites of the directory, then archives all its files as a tarball,
+// the hidden files and directories are ignored
+func Pack(dir string) (data []byte, metadata *Metadata, err error) {
+	if metadata, err = readMetadata(filepath.Join(dir, MetadataFile)); err != nil {
+		return
+	}
+	for _, suite := range metadata.Suites {
+		if _, err = testing.Parse(filepath.Join(dir, suite)); err != nil {
+			err = fmt.Errorf("invalid suite %s, %v", suite, err)
+			return
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	if err = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, walkErr error) (err error) {
+		if err = walkErr; err != nil || path == dir {
+			return
+		}
+		if strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				err = filepath.SkipDir
+			}
+			return
+		}
+		if !entry.Type().IsRegular() {
+			return
+		}
+
+		var name string
+		if name, err = filepath.Rel(dir, path); err != nil {
+			return
+		}
+		var content []byte
+		if content, err = os.ReadFile(path); err != nil {
+			return
+		}
+		if err = tarWriter.WriteHeader(&tar.Header{
+			Name: filepath.ToSlash(name),
+			Mode: 0644,
+			Size: int64(len(content)),
+		}); err == nil {
+			_, err = tarWriter.Write(content)
+		}
+		return
+	}); err != nil {
+		return
+	}
+
+	if err = tarWriter.Close(); err == nil {
+		err = gzipWriter.Close()
+	}
+	data = buf.Bytes()
+	return
+}
+
+// Unpack extracts the tarball into the directory
+func Unpack(data []byte, dir string) (err error) {
+	var gzipReader *gzip.Reader
+	if gzipReader, err = gzip.NewReader(bytes.NewReader(data)); err != nil {
+		return
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		var header *tar.Header
+		if header, err = tarReader.Next(); err == io.EOF {
+			err = nil
+			return
+		} else if err != nil {
+			return
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		// the files out of the directory are not allowed
+		name := filepath.FromSlash(header.Name)
+		if filepath.IsAbs(name) || strings.HasPrefix(filepath.Clean(name), "..") {
+			err = fmt.Errorf("invalid file %s in the package", header.Name)
+			return
+		}
+
+		target := filepath.Join(dir, name)
+		if err = os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return
+		}
+		var content []byte
+		if content, err = io.ReadAll(tarReader); err != nil {
+			return
+		}
+		if err = os.WriteFile(target, content, 0644); err != nil {
+			return
+		}
+	}
+}
+
+func readMetadata(file string) (metadata *Metadata, err error) {
+	var data []byte
+	if data, err = os.ReadFile(file); err != nil {
+		return
+	}
+
+	metadata = &Metadata{}
+	if err = yaml.Unmarshal(data, metadata); err != nil {
+		return
+	}
+	if metadata.Name == "" {
+		err = fmt.Errorf("the name of the package is required in %s", file)
+	} else if len(metadata.Suites) == 0 {
+		err = fmt.Errorf("at least one suite is required in %s", file)
+	}
+	return
+}
@@ -0,0 +1,112 @@
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	sampleSuite = `name: login
+api: http://localhost:8080
+items:
+- name: login
+  request:
+    api: /login
+`
+	sampleMetadata = `name: keycloak-smoke
+version: v1.0.0
+suites:
+  - login.yaml
+`
+)
+
+func TestPackAndUnpack(t *testing.T) {
+	dir := newPackageDir(t, map[string]string{
+		MetadataFile:      sampleMetadata,
+		"login.yaml":      sampleSuite,
+		"data/users.json": `[]`,
+		".git/config":     "ignored",
+		".env":            "ignored",
+	})
+
+	data, metadata, err := Pack(dir)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, &Metadata{Name: "keycloak-smoke", Version: "v1.0.0", Suites: []string{"login.yaml"}}, metadata)
+
+	target := t.TempDir()
+	if !assert.Nil(t, Unpack(data, target)) {
+		return
+	}
+	for file, content := range map[string]string{
+		MetadataFile:      sampleMetadata,
+		"login.yaml":      sampleSuite,
+		"data/users.json": `[]`,
+	} {
+		actual, err := os.ReadFile(filepath.Join(target, file))
+		assert.Nil(t, err)
+		assert.Equal(t, content, string(actual))
+	}
+	for _, file := range []string{".git", ".env"} {
+		_, err = os.Stat(filepath.Join(target, file))
+		assert.True(t, os.IsNotExist(err), file)
+	}
+}
+
+func TestPackWithInvalidPackage(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{{
+		name:  "no metadata",
+		files: map[string]string{"login.yaml": sampleSuite},
+	}, {
+		name:  "no name",
+		files: map[string]string{MetadataFile: "suites: [login.yaml]", "login.yaml": sampleSuite},
+	}, {
+		name:  "no suites",
+		files: map[string]string{MetadataFile: "name: empty"},
+	}, {
+		name:  "not found suite",
+		files: map[string]string{MetadataFile: sampleMetadata},
+	}, {
+		name:  "invalid suite",
+		files: map[string]string{MetadataFile: sampleMetadata, "login.yaml": "items: fake"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Pack(newPackageDir(t, tt.files))
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestUnpackWithInvalidPackage(t *testing.T) {
+	buf := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	assert.Nil(t, tarWriter.WriteHeader(&tar.Header{Name: "../escape.yaml", Mode: 0644, Size: 1}))
+	_, _ = tarWriter.Write([]byte("a"))
+	assert.Nil(t, tarWriter.Close())
+	assert.Nil(t, gzipWriter.Close())
+
+	assert.NotNil(t, Unpack(buf.Bytes(), t.TempDir()))
+	assert.NotNil(t, Unpack([]byte("fake"), t.TempDir()))
+}
+
+func newPackageDir(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(file), 0755))
+		assert.Nil(t, os.WriteFile(file, []byte(content), 0644))
+	}
+	return dir
+}
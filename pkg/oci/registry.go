@@ -0,0 +1,366 @@
+package oci
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// the media types of the package artifact
+const (
+	MediaTypeManifest = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeConfig   = "application/vnd.atest.package.config.v1+json"
+	MediaTypeLayer    = "application/vnd.atest.package.layer.v1.tar+gzip"
+)
+
+// DefaultTag is the tag of the reference if it's not set
+const DefaultTag = "latest"
+
+// Reference is the location of a package in the registry, such as: ghcr.io/linuxsuren/keycloak-smoke:v1.0
+type Reference struct {
+	Registry   string
+	Repository string
+	// Tag is a tag or a digest like sha256:<hex>
+	Tag string
+}
+
+// ParseReference parses the reference, the tag is latest by default
+func ParseReference(ref string) (reference Reference, err error) {
+	slash := strings.Index(ref, "/")
+	if slash <= 0 {
+		err = fmt.Errorf("invalid reference %q, the registry is required, e.g. ghcr.io/org/name:tag", ref)
+		return
+	}
+	reference.Registry, reference.Repository = ref[:slash], ref[slash+1:]
+
+	if at := strings.Index(reference.Repository, "@"); at >= 0 {
+		reference.Repository, reference.Tag = reference.Repository[:at], reference.Repository[at+1:]
+	} else if colon := strings.LastIndex(reference.Repository, ":"); colon >= 0 {
+		reference.Repository, reference.Tag = reference.Repository[:colon], reference.Repository[colon+1:]
+	}
+	if reference.Tag == "" {
+		reference.Tag = DefaultTag
+	}
+	if reference.Repository == "" {
+		err = fmt.Errorf("invalid reference %q, the repository is required", ref)
+	}
+	return
+}
+
+// String returns the reference in the form of registry/repository:tag
+func (r Reference) String() string {
+	if strings.Contains(r.Tag, ":") {
+		return fmt.Sprintf("%s/%s@%s", r.Registry, r.Repository, r.Tag)
+	}
+	return fmt.Sprintf("%s/%s:%s", r.Registry, r.Repository, r.Tag)
+}
+
+// descriptor describes a blob of the manifest
+type descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// manifest is the OCI image manifest of the package
+type manifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	Config        descriptor        `json:"config"`
+	Layers        []descriptor      `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// Client pushes and pulls the packages by the OCI distribution API,
+// both the basic authentication and the bearer token are supported
+type Client struct {
+	Username string
+	Password string
+	// PlainHTTP talks to the registry over HTTP instead of HTTPS
+	PlainHTTP bool
+
+	client *http.Client
+	// token is the authorization header of the last challenge
+	token string
+}
+
+// NewClient creates the registry client
+func NewClient(username, password string, plainHTTP bool) *Client {
+	return &Client{
+		Username:  username,
+		Password:  password,
+		PlainHTTP: plainHTTP,
+		client:    &http.Client{Timeout: time.Minute},
+	}
+}
+
+// Push uploads the package as an artifact, then returns the digest of the manifest
+func (c *Client) Push(ref Reference, data []byte, metadata *Metadata) (digest string, err error) {
+	var config []byte
+	if config, err = json.Marshal(metadata); err != nil {
+		return
+	}
+
+	artifact := manifest{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeManifest,
+		Config:        newDescriptor(MediaTypeConfig, config),
+		Layers:        []descriptor{newDescriptor(MediaTypeLayer, data)},
+		Annotations: map[string]string{
+			"org.opencontainers.image.title":       metadata.Name,
+			"org.opencontainers.image.version":     metadata.Version,
+			"org.opencontainers.image.description": metadata.Description,
+		},
+	}
+	artifact.Layers[0].Annotations = map[string]string{"org.opencontainers.image.title": metadata.Name + ".tar.gz"}
+
+	for _, blob := range []struct {
+		desc    descriptor
+		content []byte
+	}{{artifact.Config, config}, {artifact.Layers[0], data}} {
+		if err = c.pushBlob(ref, blob.desc.Digest, blob.content); err != nil {
+			err = fmt.Errorf("failed to push the blob %s, %v", blob.desc.Digest, err)
+			return
+		}
+	}
+
+	var manifestData []byte
+	if manifestData, err = json.Marshal(artifact); err != nil {
+		return
+	}
+	var resp *http.Response
+	if resp, err = c.do(http.MethodPut, c.url(ref, "manifests", ref.Tag), MediaTypeManifest, manifestData, ref); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if err = expectStatus(resp, http.StatusCreated); err == nil {
+		digest = newDescriptor(MediaTypeManifest, manifestData).Digest
+	}
+	return
+}
+
+// Pull downloads the package, the digests of the blobs are verified
+func (c *Client) Pull(ref Reference) (data []byte, metadata *Metadata, err error) {
+	var manifestData []byte
+	if manifestData, err = c.get(ref, c.url(ref, "manifests", ref.Tag), MediaTypeManifest); err != nil {
+		return
+	}
+
+	artifact := &manifest{}
+	if err = json.Unmarshal(manifestData, artifact); err != nil {
+		return
+	}
+	if artifact.Config.MediaType != MediaTypeConfig || len(artifact.Layers) != 1 || artifact.Layers[0].MediaType != MediaTypeLayer {
+		err = fmt.Errorf("%s is not a package of the test suites", ref)
+		return
+	}
+
+	var config []byte
+	if config, err = c.pullBlob(ref, artifact.Config); err != nil {
+		return
+	}
+	metadata = &Metadata{}
+	if err = json.Unmarshal(config, metadata); err != nil {
+		return
+	}
+	data, err = c.pullBlob(ref, artifact.Layers[0])
+	return
+}
+
+func (c *Client) pushBlob(ref Reference, digest string, content []byte) (err error) {
+	var resp *http.Response
+	if resp, err = c.do(http.MethodPost, c.url(ref, "blobs", "uploads/"), "", nil, ref); err != nil {
+		return
+	}
+	resp.Body.Close()
+	if err = expectStatus(resp, http.StatusAccepted); err != nil {
+		return
+	}
+
+	// the location could be relative to the registry
+	var location *url.URL
+	if location, err = resp.Request.URL.Parse(resp.Header.Get("Location")); err != nil {
+		return
+	}
+	query := location.Query()
+	query.Set("digest", digest)
+	location.RawQuery = query.Encode()
+
+	if resp, err = c.do(http.MethodPut, location.String(), "application/octet-stream", content, ref); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	err = expectStatus(resp, http.StatusCreated)
+	return
+}
+
+func (c *Client) pullBlob(ref Reference, desc descriptor) (content []byte, err error) {
+	if content, err = c.get(ref, c.url(ref, "blobs", desc.Digest), ""); err == nil {
+		if actual := newDescriptor(desc.MediaType, content).Digest; actual != desc.Digest {
+			err = fmt.Errorf("the digest of the blob is %s, expect %s", actual, desc.Digest)
+		}
+	}
+	return
+}
+
+func (c *Client) get(ref Reference, api, accept string) (data []byte, err error) {
+	var resp *http.Response
+	if resp, err = c.do(http.MethodGet, api, "", nil, ref, accept); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if err = expectStatus(resp, http.StatusOK); err == nil {
+		data, err = io.ReadAll(resp.Body)
+	}
+	return
+}
+
+// do sends the request, it authorizes then retries once if the registry responds the challenge
+func (c *Client) do(method, api, contentType string, body []byte, ref Reference, accept ...string) (resp *http.Response, err error) {
+	for retried := false; ; retried = true {
+		var req *http.Request
+		if req, err = http.NewRequest(method, api, bytes.NewReader(body)); err != nil {
+			return
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		for _, item := range accept {
+			if item != "" {
+				req.Header.Add("Accept", item)
+			}
+		}
+		if c.token != "" {
+			req.Header.Set("Authorization", c.token)
+		}
+
+		if resp, err = c.client.Do(req); err != nil || resp.StatusCode != http.StatusUnauthorized || retried {
+			return
+		}
+		resp.Body.Close()
+		if err = c.authorize(resp.Header.Get("WWW-Authenticate"), ref); err != nil {
+			return
+		}
+	}
+}
+
+// authorize answers the challenge, the bearer token is requested from the realm with the credential
+func (c *Client) authorize(challenge string, ref Reference) (err error) {
+	scheme, params := parseChallenge(challenge)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if c.Username == "" {
+			err = fmt.Errorf("the username and password are required by %s", ref.Registry)
+			return
+		}
+		req := &http.Request{Header: http.Header{}}
+		req.SetBasicAuth(c.Username, c.Password)
+		c.token = req.Header.Get("Authorization")
+	case "bearer":
+		var realm *url.URL
+		if realm, err = url.Parse(params["realm"]); err != nil {
+			return
+		}
+		query := realm.Query()
+		if service := params["service"]; service != "" {
+			query.Set("service", service)
+		}
+		scope := params["scope"]
+		if scope == "" {
+			scope = fmt.Sprintf("repository:%s:pull,push", ref.Repository)
+		}
+		query.Set("scope", scope)
+		realm.RawQuery = query.Encode()
+
+		var req *http.Request
+		if req, err = http.NewRequest(http.MethodGet, realm.String(), nil); err != nil {
+			return
+		}
+		if c.Username != "" {
+			req.SetBasicAuth(c.Username, c.Password)
+		}
+		var resp *http.Response
+		if resp, err = c.client.Do(req); err != nil {
+			return
+		}
+		defer resp.Body.Close()
+		if err = expectStatus(resp, http.StatusOK); err != nil {
+			err = fmt.Errorf("failed to get the token, %v", err)
+			return
+		}
+
+		token := struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+		}{}
+		if err = json.NewDecoder(resp.Body).Decode(&token); err != nil {
+			return
+		}
+		if token.Token == "" {
+			token.Token = token.AccessToken
+		}
+		c.token = "Bearer " + token.Token
+	default:
+		err = fmt.Errorf("unsupported authentication challenge %q", challenge)
+	}
+	return
+}
+
+func (c *Client) url(ref Reference, kind, name string) string {
+	scheme := "https"
+	if c.PlainHTTP {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s/v2/%s/%s/%s", scheme, ref.Registry, ref.Repository, kind, name)
+}
+
+// parseChallenge parses the header like: Bearer realm="https://auth.docker.io/token",service="registry.docker.io"
+func parseChallenge(challenge string) (scheme string, params map[string]string) {
+	params = map[string]string{}
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	for rest != "" {
+		var pair string
+		pair, rest = nextChallengeParam(rest)
+		if key, val, ok := strings.Cut(pair, "="); ok {
+			params[strings.ToLower(strings.TrimSpace(key))] = strings.Trim(strings.TrimSpace(val), `"`)
+		}
+	}
+	return
+}
+
+// nextChallengeParam splits the first parameter by the comma which is not quoted
+func nextChallengeParam(params string) (param, rest string) {
+	quoted := false
+	for i, c := range params {
+		if c == '"' {
+			quoted = !quoted
+		} else if c == ',' && !quoted {
+			return params[:i], params[i+1:]
+		}
+	}
+	return params, ""
+}
+
+func newDescriptor(mediaType string, content []byte) descriptor {
+	return descriptor{
+		MediaType: mediaType,
+		Digest:    fmt.Sprintf("sha256:%x", sha256.Sum256(content)),
+		Size:      int64(len(content)),
+	}
+}
+
+func expectStatus(resp *http.Response, code int) (err error) {
+	if resp.StatusCode != code {
+		data, _ := io.ReadAll(resp.Body)
+		err = fmt.Errorf("%s %s, expect status code %d, actual %d, %s", resp.Request.Method, resp.Request.URL,
+			code, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return
+}
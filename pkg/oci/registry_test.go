@@ -0,0 +1,178 @@
+package oci
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		ref    string
+		expect Reference
+		str    string
+		hasErr bool
+	}{{
+		ref:    "ghcr.io/linuxsuren/keycloak-smoke:v1.0.0",
+		expect: Reference{Registry: "ghcr.io", Repository: "linuxsuren/keycloak-smoke", Tag: "v1.0.0"},
+		str:    "ghcr.io/linuxsuren/keycloak-smoke:v1.0.0",
+	}, {
+		ref:    "localhost:5000/keycloak-smoke",
+		expect: Reference{Registry: "localhost:5000", Repository: "keycloak-smoke", Tag: DefaultTag},
+		str:    "localhost:5000/keycloak-smoke:latest",
+	}, {
+		ref:    "localhost:5000/keycloak-smoke@sha256:abc",
+		expect: Reference{Registry: "localhost:5000", Repository: "keycloak-smoke", Tag: "sha256:abc"},
+		str:    "localhost:5000/keycloak-smoke@sha256:abc",
+	}, {
+		ref:    "keycloak-smoke:v1.0.0",
+		hasErr: true,
+	}, {
+		ref:    "ghcr.io/:v1",
+		hasErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			ref, err := ParseReference(tt.ref)
+			assert.Equal(t, tt.hasErr, err != nil, err)
+			if !tt.hasErr {
+				assert.Equal(t, tt.expect, ref)
+				assert.Equal(t, tt.str, ref.String())
+			}
+		})
+	}
+}
+
+func TestPushAndPull(t *testing.T) {
+	server := httptest.NewServer(newFakeRegistry("admin", "secret"))
+	defer server.Close()
+
+	data, metadata, err := Pack(newPackageDir(t, map[string]string{
+		MetadataFile: sampleMetadata,
+		"login.yaml": sampleSuite,
+	}))
+	if !assert.Nil(t, err) {
+		return
+	}
+	ref, err := ParseReference(strings.TrimPrefix(server.URL, "http://") + "/suites/keycloak-smoke:v1.0.0")
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	t.Run("push and pull", func(t *testing.T) {
+		digest, err := NewClient("admin", "secret", true).Push(ref, data, metadata)
+		assert.Nil(t, err)
+		assert.True(t, strings.HasPrefix(digest, "sha256:"), digest)
+
+		pulled, pulledMetadata, err := NewClient("admin", "secret", true).Pull(ref)
+		assert.Nil(t, err)
+		assert.Equal(t, data, pulled)
+		assert.Equal(t, metadata, pulledMetadata)
+
+		// pull by the digest
+		ref.Tag = digest
+		_, _, err = NewClient("admin", "secret", true).Pull(ref)
+		assert.Nil(t, err)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		_, err := NewClient("admin", "fake", true).Push(ref, data, metadata)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		ref.Tag = "fake"
+		_, _, err := NewClient("admin", "secret", true).Pull(ref)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("HTTPS is not served", func(t *testing.T) {
+		_, _, err := NewClient("admin", "secret", false).Pull(ref)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestParseChallenge(t *testing.T) {
+	scheme, params := parseChallenge(`Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:a/b:pull,push"`)
+	assert.Equal(t, "Bearer", scheme)
+	assert.Equal(t, map[string]string{
+		"realm":   "https://auth.docker.io/token",
+		"service": "registry.docker.io",
+		"scope":   "repository:a/b:pull,push",
+	}, params)
+
+	scheme, params = parseChallenge(`Basic realm="registry"`)
+	assert.Equal(t, "Basic", scheme)
+	assert.Equal(t, map[string]string{"realm": "registry"}, params)
+}
+
+// newFakeRegistry creates an in-memory registry which issues the bearer token to the user
+func newFakeRegistry(username, password string) http.Handler {
+	var lock sync.Mutex
+	blobs := map[string][]byte{}
+	manifests := map[string][]byte{}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if r.URL.Path == "/token" {
+			if user, pass, ok := r.BasicAuth(); !ok || user != username || pass != password {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = io.WriteString(w, `{"token": "fake"}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer fake" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="http://%s/token",service="fake"`, r.Host))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		path := strings.TrimPrefix(r.URL.Path, "/v2/suites/keycloak-smoke/")
+		switch {
+		case r.Method == http.MethodPost && path == "blobs/uploads/":
+			w.Header().Set("Location", "/v2/suites/keycloak-smoke/blobs/uploads/1?state=fake")
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodPut && path == "blobs/uploads/1":
+			digest := r.URL.Query().Get("digest")
+			if r.URL.Query().Get("state") != "fake" || digest != fmt.Sprintf("sha256:%x", sha256.Sum256(body)) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			blobs[digest] = body
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPut && strings.HasPrefix(path, "manifests/"):
+			if r.Header.Get("Content-Type") != MediaTypeManifest {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			manifests[strings.TrimPrefix(path, "manifests/")] = body
+			manifests[fmt.Sprintf("sha256:%x", sha256.Sum256(body))] = body
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet && strings.HasPrefix(path, "manifests/"):
+			if data, ok := manifests[strings.TrimPrefix(path, "manifests/")]; ok {
+				_, _ = w.Write(data)
+			} else {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		case r.Method == http.MethodGet && strings.HasPrefix(path, "blobs/"):
+			if data, ok := blobs[strings.TrimPrefix(path, "blobs/")]; ok {
+				_, _ = w.Write(data)
+			} else {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+}
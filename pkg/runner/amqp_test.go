@@ -0,0 +1,334 @@
+package runner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyAMQP(t *testing.T) {
+	tests := []struct {
+		name         string
+		verification *atest.AMQPVerification
+		expectErr    string
+	}{{
+		name:         "queue is required",
+		verification: &atest.AMQPVerification{},
+		expectErr:    "the queue is required",
+	}, {
+		name:         "invalid timeout",
+		verification: &atest.AMQPVerification{Queue: "orders", Timeout: "fake"},
+		expectErr:    "invalid duration",
+	}, {
+		name:         "invalid body pattern",
+		verification: &atest.AMQPVerification{Queue: "orders", BodyMatch: "("},
+		expectErr:    "missing closing )",
+	}, {
+		name:         "cannot connect",
+		verification: &atest.AMQPVerification{URL: "amqp://127.0.0.1:1", Queue: "orders", Timeout: "1s"},
+		expectErr:    "connection refused",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewSimpleTestCaseRunner().(*simpleTestCaseRunner)
+			err := r.verifyAMQP(tt.verification)
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestRunVerifyPlugins(t *testing.T) {
+	r := NewSimpleTestCaseRunner().(*simpleTestCaseRunner)
+	testcase := &atest.TestCase{Name: "foo"}
+	assert.Nil(t, r.runVerifyPlugins(testcase))
+
+	testcase.Expect.Plugins = &atest.VerifyPlugins{AMQP: &atest.AMQPVerification{}}
+	_, err := r.runTestCaseWith(testcase, func(*ReportRecord) (interface{}, error) {
+		return nil, nil
+	})
+	assert.EqualError(t, err, "case: foo, failed to verify the AMQP queue, the queue is required")
+}
+
+func TestAMQPTestCaseRunner(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer listener.Close()
+	go serveFakeAMQP(listener)
+	api := "amqp://guest:guest@" + listener.Addr().String() + "/"
+
+	tests := []struct {
+		name     string
+		testCase *atest.TestCase
+		verify   func(t *testing.T, output interface{}, err error)
+	}{{
+		name: "publish and consume",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				Body: `{"id": {{.id}}}`,
+				AMQP: &atest.AMQPRequest{RoutingKey: "orders", Queue: "orders", ContentType: "application/json"},
+			},
+			Expect: atest.Response{
+				Messages: []string{`{"id": 1}`},
+				Verify:   []string{"data[0].id == 1"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+			assert.Equal(t, []interface{}{map[string]interface{}{"id": float64(1)}}, output)
+		},
+	}, {
+		name: "unexpected message",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				Body: "created",
+				AMQP: &atest.AMQPRequest{Exchange: "events", RoutingKey: "orders", Queue: "orders"},
+			},
+			Expect: atest.Response{
+				Messages: []string{"deleted"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "publish only",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				Body: "created",
+				AMQP: &atest.AMQPRequest{RoutingKey: "orders"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name: "no message in the queue",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				AMQP: &atest.AMQPRequest{Queue: "empty", Timeout: "100ms"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), "expect 1 messages from empty, received 0")
+			}
+		},
+	}, {
+		name: "routing key or queue is required",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				AMQP: &atest.AMQPRequest{},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "queue is required to receive",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				AMQP: &atest.AMQPRequest{RoutingKey: "orders"},
+			},
+			Expect: atest.Response{
+				Messages: []string{"created"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), "the queue is required to receive the messages")
+			}
+		},
+	}, {
+		name: "invalid timeout",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				AMQP: &atest.AMQPRequest{Queue: "orders", Timeout: "fake"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "cannot connect",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  "amqp://127.0.0.1:1",
+				AMQP: &atest.AMQPRequest{Queue: "orders", Timeout: "1s"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), "connection refused")
+			}
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amqpRunner := GetTestCaseRunner(tt.testCase)
+			output, err := amqpRunner.RunTestCase(tt.testCase, map[string]interface{}{"id": 1}, context.TODO())
+			tt.verify(t, output, err)
+		})
+	}
+}
+
+// serveFakeAMQP is a broker which routes the published messages to the queue named as the routing key
+func serveFakeAMQP(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go handleFakeAMQP(conn)
+	}
+}
+
+func handleFakeAMQP(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	if _, err := io.ReadFull(reader, make([]byte, 8)); err != nil {
+		return
+	}
+
+	// the queues have the pending messages, and the consumers are the channel and tag of the queues
+	queues := map[string][][]byte{}
+	consumers := map[string]fakeAMQPConsumer{}
+	var deliveryTag uint64
+	deliver := func(queue string) {
+		consumer, ok := consumers[queue]
+		for ; ok && len(queues[queue]) > 0; queues[queue] = queues[queue][1:] {
+			deliveryTag++
+			body := queues[queue][0]
+			writeAMQPMethod(conn, consumer.channel, 60, 60, amqpShortString(consumer.tag),
+				amqpUint64(deliveryTag), []byte{0}, amqpShortString(""), amqpShortString(queue))
+			writeAMQPFrame(conn, 2, consumer.channel, append([]byte{0, 60, 0, 0}, append(amqpUint64(uint64(len(body))), 0, 0)...))
+			writeAMQPFrame(conn, 3, consumer.channel, body)
+		}
+	}
+
+	writeAMQPMethod(conn, 0, 10, 10, []byte{0, 9}, amqpUint32(0), amqpLongString("PLAIN"), amqpLongString("en_US"))
+	for {
+		frameType, channel, payload, err := readAMQPFrame(reader)
+		if err != nil || frameType != 1 {
+			return
+		}
+
+		// the class and method ids
+		method := binary.BigEndian.Uint32(payload)
+		args := payload[4:]
+		switch method {
+		case 10<<16 | 11: // connection start-ok
+			writeAMQPMethod(conn, 0, 10, 30, []byte{0, 0}, amqpUint32(131072), []byte{0, 0})
+		case 10<<16 | 40: // connection open
+			writeAMQPMethod(conn, 0, 10, 41, amqpShortString(""))
+		case 10<<16 | 50: // connection close
+			writeAMQPMethod(conn, 0, 10, 51)
+			return
+		case 20<<16 | 10: // channel open
+			writeAMQPMethod(conn, channel, 20, 11, amqpLongString(""))
+		case 20<<16 | 40: // channel close
+			writeAMQPMethod(conn, channel, 20, 41)
+		case 60<<16 | 10: // basic qos
+			writeAMQPMethod(conn, channel, 60, 11)
+		case 60<<16 | 20: // basic consume
+			queue, rest := readAMQPShortString(args[2:])
+			tag, _ := readAMQPShortString(rest)
+			consumers[queue] = fakeAMQPConsumer{channel: channel, tag: tag}
+			writeAMQPMethod(conn, channel, 60, 21, amqpShortString(tag))
+			deliver(queue)
+		case 60<<16 | 40: // basic publish, the content header and body frames are followed
+			_, rest := readAMQPShortString(args[2:])
+			routingKey, _ := readAMQPShortString(rest)
+			_, _, header, _ := readAMQPFrame(reader)
+			size := binary.BigEndian.Uint64(header[4:])
+			var body []byte
+			for uint64(len(body)) < size {
+				_, _, data, err := readAMQPFrame(reader)
+				if err != nil {
+					return
+				}
+				body = append(body, data...)
+			}
+			queues[routingKey] = append(queues[routingKey], body)
+			deliver(routingKey)
+		}
+	}
+}
+
+type fakeAMQPConsumer struct {
+	channel uint16
+	tag     string
+}
+
+func readAMQPFrame(reader io.Reader) (frameType byte, channel uint16, payload []byte, err error) {
+	header := make([]byte, 7)
+	if _, err = io.ReadFull(reader, header); err != nil {
+		return
+	}
+	frameType, channel = header[0], binary.BigEndian.Uint16(header[1:])
+	payload = make([]byte, binary.BigEndian.Uint32(header[3:])+1)
+	if _, err = io.ReadFull(reader, payload); err == nil {
+		payload = payload[:len(payload)-1]
+	}
+	return
+}
+
+func writeAMQPFrame(writer io.Writer, frameType byte, channel uint16, payload []byte) {
+	frame := append([]byte{frameType}, append(amqpUint16(channel), amqpUint32(uint32(len(payload)))...)...)
+	_, _ = writer.Write(append(append(frame, payload...), 0xce))
+}
+
+func writeAMQPMethod(writer io.Writer, channel, class, method uint16, args ...[]byte) {
+	payload := append(amqpUint16(class), amqpUint16(method)...)
+	for _, arg := range args {
+		payload = append(payload, arg...)
+	}
+	writeAMQPFrame(writer, 1, channel, payload)
+}
+
+func readAMQPShortString(data []byte) (value string, rest []byte) {
+	size := int(data[0])
+	return string(data[1 : 1+size]), data[1+size:]
+}
+
+func amqpShortString(value string) []byte {
+	return append([]byte{byte(len(value))}, value...)
+}
+
+func amqpLongString(value string) []byte {
+	return append(amqpUint32(uint32(len(value))), value...)
+}
+
+func amqpUint16(value uint16) []byte {
+	data := make([]byte, 2)
+	binary.BigEndian.PutUint16(data, value)
+	return data
+}
+
+func amqpUint32(value uint32) []byte {
+	data := make([]byte, 4)
+	binary.BigEndian.PutUint32(data, value)
+	return data
+}
+
+func amqpUint64(value uint64) []byte {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, value)
+	return data
+}
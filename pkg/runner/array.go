@@ -0,0 +1,155 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/PaesslerAG/jsonpath"
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+// verifyArrays verifies the length, the elements and the order of the arrays which are selected by the JSONPath expressions
+func verifyArrays(caseName string, expect map[string]*testing.ArrayExpect, body interface{}) (err error) {
+	for path, arrayExpect := range expect {
+		if arrayExpect == nil {
+			continue
+		}
+
+		var val interface{}
+		if val, err = jsonpath.Get(path, body); err != nil {
+			err = fmt.Errorf("case: %s, failed to get the JSONPath %s, %v", caseName, path, err)
+			return
+		}
+		items, ok := val.([]interface{})
+		if !ok {
+			err = fmt.Errorf("case: %s, JSONPath[%s] is not an array, actual: %v", caseName, path, val)
+			return
+		}
+		if err = verifyArray(arrayExpect, items); err != nil {
+			err = fmt.Errorf("case: %s, the array %s %v", caseName, path, err)
+			return
+		}
+	}
+	return
+}
+
+func verifyArray(expect *testing.ArrayExpect, items []interface{}) (err error) {
+	if expect.Length != nil {
+		if err = verifyArrayLength(expect.Length, len(items)); err != nil {
+			return
+		}
+	}
+
+	for _, element := range normalizeJSONValues(expect.Contains) {
+		if !jsonContains(items, []interface{}{element}) {
+			err = fmt.Errorf("does not contain %s, actual: %v", jsonText(element), items)
+			return
+		}
+	}
+	for _, element := range normalizeJSONValues(expect.NotContains) {
+		if jsonContains(items, []interface{}{element}) {
+			err = fmt.Errorf("contains the unexpected %s", jsonText(element))
+			return
+		}
+	}
+
+	if expect.Sorted != "" || expect.SortBy != "" {
+		err = verifyArrayOrder(expect.Sorted, expect.SortBy, items)
+	}
+	return
+}
+
+// verifyArrayLength compares the length with the number, or the numeric conditions, such as: gte 1, lte 100
+func verifyArrayLength(expect interface{}, length int) error {
+	if conditions, ok := parseNumericConditions(expect); ok {
+		if !matchNumericConditions(conditions, float64(length)) {
+			return fmt.Errorf("expect length: %v, actual: %d", expect, length)
+		}
+		return nil
+	}
+
+	if !fieldValueEqual(expect, float64(length)) {
+		return fmt.Errorf("expect length: %v, actual: %d", expect, length)
+	}
+	return nil
+}
+
+// verifyArrayOrder verifies the order of the numbers or the strings, the timestamps in RFC3339 are sorted as strings
+func verifyArrayOrder(sorted, sortBy string, items []interface{}) (err error) {
+	if sorted == "" {
+		sorted = "asc"
+	}
+	if sorted != "asc" && sorted != "desc" {
+		err = fmt.Errorf("has an invalid order %q, it should be asc or desc", sorted)
+		return
+	}
+
+	keys := make([]interface{}, len(items))
+	for i, item := range items {
+		keys[i] = item
+		if sortBy != "" {
+			if keys[i], err = jsonpath.Get(sortBy, item); err != nil {
+				err = fmt.Errorf("failed to get the sort key %s of the element %d, %v", sortBy, i, err)
+				return
+			}
+		}
+	}
+
+	for i := 1; i < len(keys); i++ {
+		var compared int
+		if compared, err = compareSortKeys(keys[i-1], keys[i]); err != nil {
+			return
+		}
+		if (sorted == "asc" && compared > 0) || (sorted == "desc" && compared < 0) {
+			err = fmt.Errorf("is not sorted in %s order, %v is before %v", sorted, keys[i-1], keys[i])
+			return
+		}
+	}
+	return
+}
+
+func compareSortKeys(a, b interface{}) (int, error) {
+	switch left := a.(type) {
+	case float64:
+		if right, ok := b.(float64); ok {
+			switch {
+			case left < right:
+				return -1, nil
+			case left > right:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	case string:
+		if right, ok := b.(string); ok {
+			switch {
+			case left < right:
+				return -1, nil
+			case left > right:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	return 0, fmt.Errorf("could not compare %v with %v, only the numbers or the strings are sortable", a, b)
+}
+
+// normalizeJSONValues converts the values which are parsed from YAML to the ones which are decoded from JSON,
+// e.g. the integers are float64 numbers
+func normalizeJSONValues(values []interface{}) (normalized []interface{}) {
+	if len(values) == 0 {
+		return
+	}
+	if data, err := json.Marshal(values); err == nil && json.Unmarshal(data, &normalized) == nil {
+		return
+	}
+	return values
+}
+
+func jsonText(val interface{}) string {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprintf("%v", val)
+	}
+	return string(data)
+}
@@ -0,0 +1,99 @@
+package runner
+
+import (
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyArrays(t *testing.T) {
+	body := []byte(`{"items": [{"id": 1, "name": "rick", "createdAt": "2024-01-01T00:00:00Z"},
+{"id": 2, "name": "morty", "createdAt": "2024-01-02T00:00:00Z"}, {"id": 2, "name": "summer", "createdAt": "2024-01-03T00:00:00Z"}],
+"tags": ["c", "b", "a"], "total": 3}`)
+
+	tests := []struct {
+		name      string
+		body      []byte
+		arrays    map[string]*atest.ArrayExpect
+		expectErr string
+	}{{
+		name: "expected arrays",
+		body: body,
+		arrays: map[string]*atest.ArrayExpect{
+			"$.items": {
+				Length:      3,
+				Contains:    []interface{}{map[string]interface{}{"name": "morty"}, map[string]interface{}{"id": 1, "name": "rick"}},
+				NotContains: []interface{}{map[string]interface{}{"name": "beth"}},
+				Sorted:      "asc",
+				SortBy:      "$.id",
+			},
+			"$.items[*].createdAt": {Length: "gte 1, lte 10", Sorted: "asc"},
+			"$.tags":               {Contains: []interface{}{"a"}, NotContains: []interface{}{"d"}, Sorted: "desc"},
+		},
+	}, {
+		name:   "the array body",
+		body:   []byte(`[1, 2, 3]`),
+		arrays: map[string]*atest.ArrayExpect{"$": {Length: float64(3), Contains: []interface{}{3}, SortBy: "$"}},
+	}, {
+		name:      "unexpected length",
+		body:      body,
+		arrays:    map[string]*atest.ArrayExpect{"$.items": {Length: 2}},
+		expectErr: "case: case, the array $.items expect length: 2, actual: 3",
+	}, {
+		name:      "not match the length conditions",
+		body:      body,
+		arrays:    map[string]*atest.ArrayExpect{"$.tags": {Length: "lt 3"}},
+		expectErr: "the array $.tags expect length: lt 3, actual: 3",
+	}, {
+		name:      "not contain",
+		body:      body,
+		arrays:    map[string]*atest.ArrayExpect{"$.items": {Contains: []interface{}{map[string]interface{}{"id": 1, "name": "morty"}}}},
+		expectErr: `the array $.items does not contain {"id":1,"name":"morty"}`,
+	}, {
+		name:      "contain the unexpected one",
+		body:      body,
+		arrays:    map[string]*atest.ArrayExpect{"$.tags": {NotContains: []interface{}{"b"}}},
+		expectErr: `the array $.tags contains the unexpected "b"`,
+	}, {
+		name:      "not sorted",
+		body:      body,
+		arrays:    map[string]*atest.ArrayExpect{"$.items": {Sorted: "desc", SortBy: "$.createdAt"}},
+		expectErr: "the array $.items is not sorted in desc order, 2024-01-01T00:00:00Z is before 2024-01-02T00:00:00Z",
+	}, {
+		name:      "invalid order",
+		body:      body,
+		arrays:    map[string]*atest.ArrayExpect{"$.tags": {Sorted: "random"}},
+		expectErr: `the array $.tags has an invalid order "random"`,
+	}, {
+		name:      "not sortable",
+		body:      body,
+		arrays:    map[string]*atest.ArrayExpect{"$.items": {Sorted: "asc"}},
+		expectErr: "only the numbers or the strings are sortable",
+	}, {
+		name:      "sort key not found",
+		body:      body,
+		arrays:    map[string]*atest.ArrayExpect{"$.items": {SortBy: "$.fake"}},
+		expectErr: "failed to get the sort key $.fake of the element 0",
+	}, {
+		name:      "not an array",
+		body:      body,
+		arrays:    map[string]*atest.ArrayExpect{"$.total": {Length: 3}},
+		expectErr: "JSONPath[$.total] is not an array, actual: 3",
+	}, {
+		name:      "not found",
+		body:      body,
+		arrays:    map[string]*atest.ArrayExpect{"$.fake": {Length: 3}},
+		expectErr: "failed to get the JSONPath $.fake",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifyResponseBodyData("case", atest.Response{Arrays: tt.arrays}, tt.body)
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expectErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package runner
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+// verifyBodyText verifies the body by the regular expressions and the partial contents, it works with
+// the dynamic values like the timestamps and UUIDs. The unexpected partial contents are verified as well
+func verifyBodyText(caseName string, expect testing.Response, body []byte) (err error) {
+	for _, pattern := range expect.BodyRegexp {
+		var reg *regexp.Regexp
+		if reg, err = regexp.Compile(pattern); err != nil {
+			err = fmt.Errorf("case: %s, invalid bodyRegexp %q, %v", caseName, pattern, err)
+			return
+		}
+		if !reg.Match(body) {
+			err = fmt.Errorf("case: %s, the body does not match the regexp %q, actual: %s", caseName, pattern, string(body))
+			return
+		}
+	}
+
+	var bodyValue interface{}
+	bodyIsJSON := json.Unmarshal(body, &bodyValue) == nil
+	for _, item := range expect.BodyContains {
+		if fragment, ok := parseJSONFragment(item); ok && bodyIsJSON {
+			if !jsonContainsAnywhere(bodyValue, fragment) {
+				err = fmt.Errorf("case: %s, the body does not contain the JSON fragment %s, actual: %s", caseName, item, string(body))
+				return
+			}
+		} else if !strings.Contains(string(body), item) {
+			err = fmt.Errorf("case: %s, the body does not contain %q, actual: %s", caseName, item, string(body))
+			return
+		}
+	}
+
+	// the leaked values are not printed with the body again
+	for _, item := range expect.BodyNotContains {
+		if fragment, ok := parseJSONFragment(item); ok && bodyIsJSON {
+			if jsonContainsAnywhere(bodyValue, fragment) {
+				err = fmt.Errorf("case: %s, the body contains the unexpected JSON fragment %s", caseName, item)
+				return
+			}
+		} else if strings.Contains(string(body), item) {
+			err = fmt.Errorf("case: %s, the body contains the unexpected %q", caseName, item)
+			return
+		}
+	}
+	return
+}
+
+// expectBodySize verifies the size of the received body, it's decompressed if the Accept-Encoding header is not set
+// or the compression is verified
+func expectBodySize(caseName string, expect testing.Response, size int) (err error) {
+	if expect.MaxBodySize > 0 && size > expect.MaxBodySize {
+		err = fmt.Errorf("case: %s, expect the body size not greater than %d bytes, actual %d", caseName, expect.MaxBodySize, size)
+	} else if expect.MinBodySize > 0 && size < expect.MinBodySize {
+		err = fmt.Errorf("case: %s, expect the body size not less than %d bytes, actual %d", caseName, expect.MinBodySize, size)
+	}
+	return
+}
+
+// hasBodyChecksum returns true if the body is verified by the checksums
+func hasBodyChecksum(expect testing.Response) bool {
+	return expect.BodySHA256 != "" || expect.BodyMD5 != ""
+}
+
+// expectBodyChecksum compares the hex checksums of the body, they're case-insensitive
+func expectBodyChecksum(caseName string, expect testing.Response, body []byte) (err error) {
+	if expect.BodySHA256 != "" {
+		sum := sha256.Sum256(body)
+		if actual := hex.EncodeToString(sum[:]); !strings.EqualFold(expect.BodySHA256, actual) {
+			err = fmt.Errorf("case: %s, expect the body SHA256 %s, actual %s", caseName, expect.BodySHA256, actual)
+			return
+		}
+	}
+	if expect.BodyMD5 != "" {
+		sum := md5.Sum(body)
+		if actual := hex.EncodeToString(sum[:]); !strings.EqualFold(expect.BodyMD5, actual) {
+			err = fmt.Errorf("case: %s, expect the body MD5 %s, actual %s", caseName, expect.BodyMD5, actual)
+		}
+	}
+	return
+}
+
+// parseJSONFragment returns the object or array, the other JSON values are treated as the sub-strings
+func parseJSONFragment(text string) (fragment interface{}, ok bool) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "{") && !strings.HasPrefix(text, "[") {
+		return
+	}
+	decoder := json.NewDecoder(bytes.NewBufferString(text))
+	ok = decoder.Decode(&fragment) == nil && !decoder.More()
+	return
+}
+
+// jsonContainsAnywhere returns true if the fragment is contained by the value or any of its nested values
+func jsonContainsAnywhere(value, fragment interface{}) bool {
+	if jsonContains(value, fragment) {
+		return true
+	}
+
+	switch val := value.(type) {
+	case map[string]interface{}:
+		for _, item := range val {
+			if jsonContainsAnywhere(item, fragment) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range val {
+			if jsonContainsAnywhere(item, fragment) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsonContains returns true if the value has all the fields of the fragment object, or all the items of
+// the fragment array are contained by the items of the value, the other values must be equal
+func jsonContains(value, fragment interface{}) bool {
+	switch expect := fragment.(type) {
+	case map[string]interface{}:
+		actual, ok := value.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for key, expectVal := range expect {
+			if actualVal, ok := actual[key]; !ok || !jsonContains(actualVal, expectVal) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		actual, ok := value.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, expectItem := range expect {
+			found := false
+			for _, actualItem := range actual {
+				if found = jsonContains(actualItem, expectItem); found {
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(value, fragment)
+	}
+}
@@ -0,0 +1,298 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/h2non/gock"
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyBodyText(t *testing.T) {
+	body := []byte(`{"id":"0f8fad5b-d9cb-469f-a165-70867728950e","createdAt":"2023-06-01T08:00:00Z","name":"rick"}`)
+
+	tests := []struct {
+		name      string
+		expect    atest.Response
+		expectErr string
+	}{{
+		name: "no patterns",
+	}, {
+		name: "all the patterns match",
+		expect: atest.Response{BodyRegexp: []string{
+			`"id":"[0-9a-f-]{36}"`,
+			`"createdAt":"\d{4}-\d{2}-\d{2}T[\d:]+Z"`,
+			`(?s)^\{.*"name":"rick"\}$`,
+		}},
+	}, {
+		name:      "not match",
+		expect:    atest.Response{BodyRegexp: []string{`"id":"[0-9a-f-]{36}"`, `"name":"morty"`}},
+		expectErr: `case: fake, the body does not match the regexp "\"name\":\"morty\""`,
+	}, {
+		name:      "invalid pattern",
+		expect:    atest.Response{BodyRegexp: []string{`(`}},
+		expectErr: `case: fake, invalid bodyRegexp "("`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyBodyText("fake", tt.expect, body)
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expectErr)
+			}
+		})
+	}
+
+	_, err := verifyResponseBodyData("fake", atest.Response{BodyRegexp: []string{`"name":"morty"`}}, body)
+	assert.NotNil(t, err)
+	_, err = verifyXMLResponse("fake", atest.Response{BodyRegexp: []string{`<name>\w+</name>`}}, []byte(`<user><name>rick</name></user>`))
+	assert.Nil(t, err)
+}
+
+func TestVerifyBodyContains(t *testing.T) {
+	body := []byte(`{"data":{"total":2,"users":[{"name":"rick","roles":["admin","dev"]},{"name":"morty","age":14}]}}`)
+
+	tests := []struct {
+		name      string
+		body      []byte
+		contains  []string
+		expectErr string
+	}{{
+		name:     "sub-strings",
+		body:     body,
+		contains: []string{`"total":2`, "morty"},
+	}, {
+		name:     "JSON fragments",
+		body:     body,
+		contains: []string{`{"name": "rick"}`, `{"name":"morty","age":14}`, `{"roles":["dev"]}`, `[{"age":14}]`, `{"total":2}`},
+	}, {
+		name:      "not found the sub-string",
+		body:      body,
+		contains:  []string{"summer"},
+		expectErr: `case: fake, the body does not contain "summer"`,
+	}, {
+		name:      "the field value is different",
+		body:      body,
+		contains:  []string{`{"name":"morty","age":15}`},
+		expectErr: `case: fake, the body does not contain the JSON fragment {"name":"morty","age":15}`,
+	}, {
+		name:      "the fields are in the different objects",
+		body:      body,
+		contains:  []string{`{"name":"rick","age":14}`},
+		expectErr: "does not contain the JSON fragment",
+	}, {
+		name:      "the type is different",
+		body:      body,
+		contains:  []string{`{"users":{"name":"rick"}}`},
+		expectErr: "does not contain the JSON fragment",
+	}, {
+		name:     "not a JSON body",
+		body:     []byte(`hello {"name":"rick"}`),
+		contains: []string{`{"name":"rick"}`},
+	}, {
+		name:     "not a JSON fragment",
+		body:     []byte(`[1, 2] {`),
+		contains: []string{`[1, 2] {`},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyBodyText("fake", atest.Response{BodyContains: tt.contains}, tt.body)
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestVerifyBodyNotContains(t *testing.T) {
+	body := []byte(`{"user":{"name":"rick","token":"s3cr3t"},"debug":false}`)
+
+	tests := []struct {
+		name        string
+		body        []byte
+		notContains []string
+		expectErr   string
+	}{{
+		name:        "not leaked",
+		body:        body,
+		notContains: []string{"password", `{"debug":true}`, `{"name":"morty"}`},
+	}, {
+		name:        "the sub-string is leaked",
+		body:        body,
+		notContains: []string{"s3cr3t"},
+		expectErr:   `case: fake, the body contains the unexpected "s3cr3t"`,
+	}, {
+		name:        "the JSON fragment is leaked",
+		body:        body,
+		notContains: []string{`{"token":"s3cr3t"}`},
+		expectErr:   `case: fake, the body contains the unexpected JSON fragment {"token":"s3cr3t"}`,
+	}, {
+		name:        "not a JSON body",
+		body:        []byte(`token={"token":"s3cr3t"}`),
+		notContains: []string{`{"token":"s3cr3t"}`},
+		expectErr:   "the body contains the unexpected",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyBodyText("fake", atest.Response{BodyNotContains: tt.notContains}, tt.body)
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestExpectBodySize(t *testing.T) {
+	defer gock.Off()
+
+	tests := []struct {
+		name      string
+		expect    atest.Response
+		expectErr string
+	}{{
+		name:   "in the limits",
+		expect: atest.Response{MaxBodySize: 20, MinBodySize: 10},
+	}, {
+		name:      "too large",
+		expect:    atest.Response{MaxBodySize: 10},
+		expectErr: "case: size, expect the body size not greater than 10 bytes, actual 15",
+	}, {
+		name:      "truncated",
+		expect:    atest.Response{MinBodySize: 1024},
+		expectErr: "case: size, expect the body size not less than 1024 bytes, actual 15",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gock.New("http://localhost").Get("/users").Reply(http.StatusOK).BodyString(`{"name":"rick"}`)
+
+			_, err := NewSimpleTestCaseRunner().RunTestCase(&atest.TestCase{
+				Name:    "size",
+				Request: atest.Request{API: "http://localhost/users"},
+				Expect:  tt.expect,
+			}, nil, context.TODO())
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestExpectBodyChecksum(t *testing.T) {
+	defer gock.Off()
+
+	tests := []struct {
+		name      string
+		body      string
+		expect    atest.Response
+		expectErr string
+	}{{
+		name: "binary body",
+		body: "hello\x00\x01",
+		expect: atest.Response{
+			BodySHA256: "2E426E051F77FA9B8A440D2BF885E0F03A2CC9323D7135EE3949686612A1FED2",
+			BodyMD5:    "73579358f4364fd9f6508eecd290474c",
+		},
+	}, {
+		name: "empty body",
+		body: "",
+		expect: atest.Response{
+			BodySHA256: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		},
+	}, {
+		name: "text assertions of the binary body are verified as well",
+		body: "hello\x00\x01",
+		expect: atest.Response{
+			BodySHA256:   "2E426E051F77FA9B8A440D2BF885E0F03A2CC9323D7135EE3949686612A1FED2",
+			BodyContains: []string{"world"},
+		},
+		expectErr: `case: checksum, the body does not contain "world"`,
+	}, {
+		name: "JSON body is verified as well",
+		body: `{"name":"rick"}`,
+		expect: atest.Response{
+			BodySHA256:       "9b0311f60790c3faa7a2d8281b1424a2e5679558261a43b521e50a187ac22bdb",
+			BodyFieldsExpect: map[string]interface{}{"name": "morty"},
+		},
+		expectErr: "field[name] expect value: morty, actual: rick",
+	}, {
+		name:      "unexpected SHA256",
+		body:      "hello",
+		expect:    atest.Response{BodySHA256: "fake"},
+		expectErr: "case: checksum, expect the body SHA256 fake, actual 2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+	}, {
+		name:      "unexpected MD5",
+		body:      "hello",
+		expect:    atest.Response{BodyMD5: "fake"},
+		expectErr: "case: checksum, expect the body MD5 fake, actual 5d41402abc4b2a76b9719d911017c592",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gock.New("http://localhost").Get("/download").Reply(http.StatusOK).BodyString(tt.body)
+
+			_, err := NewSimpleTestCaseRunner().RunTestCase(&atest.TestCase{
+				Name:    "checksum",
+				Request: atest.Request{API: "http://localhost/download"},
+				Expect:  tt.expect,
+			}, nil, context.TODO())
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestTextResponse(t *testing.T) {
+	defer gock.Off()
+
+	tests := []struct {
+		name      string
+		expect    atest.Response
+		expectErr string
+	}{{
+		name: "text assertions",
+		expect: atest.Response{
+			BodyContains:    []string{"Welcome"},
+			BodyNotContains: []string{"Error"},
+			BodyRegexp:      []string{`^Welcome, \w+!$`},
+			MaxBodySize:     100,
+			ContentType:     "text/plain",
+		},
+	}, {
+		name:      "text assertion fails",
+		expect:    atest.Response{BodyContains: []string{"Goodbye"}},
+		expectErr: `case: text, the body does not contain "Goodbye"`,
+	}, {
+		name:      "JSON expectation",
+		expect:    atest.Response{BodyFieldsExpect: map[string]interface{}{"name": "rick"}},
+		expectErr: "invalid character 'W' looking for beginning of value",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gock.New("http://localhost").Get("/welcome").Reply(http.StatusOK).
+				SetHeader("Content-Type", "text/plain; charset=utf-8").BodyString("Welcome, rick!")
+
+			output, err := NewSimpleTestCaseRunner().RunTestCase(&atest.TestCase{
+				Name:    "text",
+				Request: atest.Request{API: "http://localhost/welcome"},
+				Expect:  tt.expect,
+			}, nil, context.TODO())
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+				assert.Equal(t, "Welcome, rick!", output)
+			} else {
+				assert.ErrorContains(t, err, tt.expectErr)
+			}
+		})
+	}
+}
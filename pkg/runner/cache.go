@@ -0,0 +1,158 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+// the values of the second request in the cache expectation
+const (
+	cacheRevalidated = "revalidated"
+	cacheCached      = "cached"
+)
+
+// cacheStatusHeaders are the headers of the CDNs and proxies which tell if the response is from the cache
+var cacheStatusHeaders = []string{"X-Cache", "X-Cache-Status", "CF-Cache-Status", "X-Proxy-Cache"}
+
+// expectCache verifies the Cache-Control and ETag headers, then sends the second request if it's required
+func expectCache(name string, expect *testing.CacheExpect, client *http.Client, request *http.Request,
+	resp *http.Response) (err error) {
+	if expect == nil {
+		return
+	}
+
+	directives := parseCacheControl(resp.Header.Values("Cache-Control"))
+	for _, directive := range expect.Directives {
+		key, val, hasVal := strings.Cut(directive, "=")
+		actual, ok := directives[strings.ToLower(strings.TrimSpace(key))]
+		if !ok || (hasVal && actual != strings.Trim(strings.TrimSpace(val), `"`)) {
+			err = fmt.Errorf("case: %s, expect the Cache-Control has %s, actual %q", name, directive,
+				strings.Join(resp.Header.Values("Cache-Control"), ", "))
+			return
+		}
+	}
+
+	if expect.MaxAge != nil {
+		if err = expectMaxAge(expect.MaxAge, directives); err != nil {
+			err = fmt.Errorf("case: %s, %v", name, err)
+			return
+		}
+	}
+
+	if expect.ETag && resp.Header.Get("ETag") == "" {
+		err = fmt.Errorf("case: %s, expect the ETag header, but it's missing", name)
+		return
+	}
+
+	switch expect.Second {
+	case "":
+	case cacheRevalidated, cacheCached:
+		err = expectSecondResponse(name, expect.Second, client, request, resp)
+	default:
+		err = fmt.Errorf("case: %s, unsupported second %q of the cache, only revalidated and cached are supported", name, expect.Second)
+	}
+	return
+}
+
+// parseCacheControl returns the directives of the Cache-Control headers, the names are in lower case
+func parseCacheControl(values []string) (directives map[string]string) {
+	directives = map[string]string{}
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			key, val, _ := strings.Cut(item, "=")
+			directives[strings.ToLower(strings.TrimSpace(key))] = strings.Trim(strings.TrimSpace(val), `"`)
+		}
+	}
+	return
+}
+
+// expectMaxAge compares the s-maxage of the shared caches, or the max-age with the number or the conditions
+func expectMaxAge(expect interface{}, directives map[string]string) (err error) {
+	val, ok := directives["s-maxage"]
+	if !ok {
+		val, ok = directives["max-age"]
+	}
+	if !ok {
+		err = fmt.Errorf("expect the max-age %v, but it's missing", expect)
+		return
+	}
+
+	var maxAge int
+	if maxAge, err = strconv.Atoi(val); err != nil {
+		err = fmt.Errorf("invalid max-age %q, %v", val, err)
+		return
+	}
+
+	matched := fieldValueEqual(expect, float64(maxAge))
+	if conditions, isConditions := parseNumericConditions(expect); isConditions {
+		matched = matchNumericConditions(conditions, float64(maxAge))
+	}
+	if !matched {
+		err = fmt.Errorf("expect the max-age %v, actual %d", expect, maxAge)
+	}
+	return
+}
+
+// expectSecondResponse sends the request again, it's conditional by the validators of the first response if
+// it should be revalidated
+func expectSecondResponse(name, second string, client *http.Client, request *http.Request, first *http.Response) (err error) {
+	req := request.Clone(request.Context())
+	if request.GetBody != nil {
+		if req.Body, err = request.GetBody(); err != nil {
+			return
+		}
+	}
+
+	if second == cacheRevalidated {
+		etag, lastModified := first.Header.Get("ETag"), first.Header.Get("Last-Modified")
+		if etag == "" && lastModified == "" {
+			err = fmt.Errorf("case: %s, could not revalidate the response without the ETag or Last-Modified header", name)
+			return
+		}
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	var resp *http.Response
+	if resp, err = client.Do(req); err != nil {
+		err = fmt.Errorf("case: %s, failed to send the second request, %v", name, err)
+		return
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	if second == cacheRevalidated {
+		if resp.StatusCode != http.StatusNotModified {
+			err = fmt.Errorf("case: %s, expect 304 for the conditional second request, actual %d", name, resp.StatusCode)
+		}
+		return
+	}
+
+	if resp.Header.Get("Age") != "" {
+		return
+	}
+	var statuses []string
+	for _, key := range cacheStatusHeaders {
+		if status := resp.Header.Get(key); status != "" {
+			if strings.Contains(strings.ToUpper(status), "HIT") {
+				return
+			}
+			statuses = append(statuses, key+": "+status)
+		}
+	}
+	err = fmt.Errorf("case: %s, expect the second response is served by the cache, but there is no Age header or cache HIT status, actual %q",
+		name, statuses)
+	return
+}
@@ -0,0 +1,135 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpectCache(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/etag", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", `public, max-age=60, s-maxage="3600"`)
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("/cdn", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Last-Modified", "Wed, 21 Oct 2015 07:28:00 GMT")
+		if r.Header.Get("If-Modified-Since") == "" {
+			w.Header().Set("CF-Cache-Status", "HIT")
+		}
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("X-Cache", "MISS")
+		_, _ = w.Write([]byte(`{}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		path      string
+		expect    *atest.CacheExpect
+		expectErr string
+	}{{
+		name: "revalidated by the ETag",
+		path: "/etag",
+		expect: &atest.CacheExpect{
+			Directives: []string{"public", "max-age=60", "S-MAXAGE=3600"},
+			MaxAge:     "gte 600",
+			ETag:       true,
+			Second:     "revalidated",
+		},
+	}, {
+		name:   "the exact max-age",
+		path:   "/cdn",
+		expect: &atest.CacheExpect{MaxAge: 60},
+	}, {
+		name:   "cached by the CDN",
+		path:   "/cdn",
+		expect: &atest.CacheExpect{Second: "cached"},
+	}, {
+		name:      "missing directive",
+		path:      "/",
+		expect:    &atest.CacheExpect{Directives: []string{"public"}},
+		expectErr: `case: cache, expect the Cache-Control has public, actual "no-store"`,
+	}, {
+		name:      "unexpected directive value",
+		path:      "/etag",
+		expect:    &atest.CacheExpect{Directives: []string{"max-age=600"}},
+		expectErr: `case: cache, expect the Cache-Control has max-age=600, actual "public, max-age=60, s-maxage=\"3600\""`,
+	}, {
+		name:      "unexpected max-age",
+		path:      "/etag",
+		expect:    &atest.CacheExpect{MaxAge: "lt 60"},
+		expectErr: "case: cache, expect the max-age lt 60, actual 3600",
+	}, {
+		name:      "missing max-age",
+		path:      "/",
+		expect:    &atest.CacheExpect{MaxAge: 60},
+		expectErr: "case: cache, expect the max-age 60, but it's missing",
+	}, {
+		name:      "missing ETag",
+		path:      "/cdn",
+		expect:    &atest.CacheExpect{ETag: true},
+		expectErr: "case: cache, expect the ETag header, but it's missing",
+	}, {
+		name:      "not revalidated",
+		path:      "/cdn",
+		expect:    &atest.CacheExpect{Second: "revalidated"},
+		expectErr: "case: cache, expect 304 for the conditional second request, actual 200",
+	}, {
+		name:      "no validators",
+		path:      "/",
+		expect:    &atest.CacheExpect{Second: "revalidated"},
+		expectErr: "case: cache, could not revalidate the response without the ETag or Last-Modified header",
+	}, {
+		name:      "not cached",
+		path:      "/",
+		expect:    &atest.CacheExpect{Second: "cached"},
+		expectErr: `case: cache, expect the second response is served by the cache, but there is no Age header or cache HIT status, actual ["X-Cache: MISS"]`,
+	}, {
+		name:      "unsupported second",
+		path:      "/",
+		expect:    &atest.CacheExpect{Second: "fake"},
+		expectErr: `case: cache, unsupported second "fake" of the cache, only revalidated and cached are supported`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSimpleTestCaseRunner().RunTestCase(&atest.TestCase{
+				Name:    "cache",
+				Request: atest.Request{API: server.URL + tt.path},
+				Expect:  atest.Response{Cache: tt.expect},
+			}, nil, context.TODO())
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expectErr)
+			}
+		})
+	}
+
+	// served by a proxy which has the Age header
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Age", "12")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer proxy.Close()
+	_, err := NewSimpleTestCaseRunner().RunTestCase(&atest.TestCase{
+		Name:    "cache",
+		Request: atest.Request{API: proxy.URL},
+		Expect:  atest.Response{Cache: &atest.CacheExpect{Second: "cached"}},
+	}, nil, context.TODO())
+	assert.Nil(t, err)
+}
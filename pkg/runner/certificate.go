@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+// expectCertificate verifies the leaf certificate of the server, the certificate is not verified by the
+// HTTP client, so the expired one is caught here as well
+func expectCertificate(name string, expect *testing.CertificateExpect, state *tls.ConnectionState) (err error) {
+	if expect == nil {
+		return
+	}
+	if state == nil || len(state.PeerCertificates) == 0 {
+		err = fmt.Errorf("case: %s, no server certificate found, the API should be HTTPS", name)
+		return
+	}
+
+	cert := state.PeerCertificates[0]
+	if expect.Issuer != "" && !strings.Contains(cert.Issuer.String(), expect.Issuer) {
+		err = fmt.Errorf("case: %s, expect the certificate issuer %q, actual %q", name, expect.Issuer, cert.Issuer.String())
+		return
+	}
+	if expect.Subject != "" && !strings.Contains(cert.Subject.String(), expect.Subject) {
+		err = fmt.Errorf("case: %s, expect the certificate subject %q, actual %q", name, expect.Subject, cert.Subject.String())
+		return
+	}
+
+	sans := append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	for _, san := range expect.SANs {
+		found := false
+		for _, item := range sans {
+			if found = strings.EqualFold(item, san); found {
+				break
+			}
+		}
+		if !found {
+			err = fmt.Errorf("case: %s, expect %s in the certificate SANs, actual %v", name, san, sans)
+			return
+		}
+	}
+
+	if expect.MinDaysUntilExpiry > 0 {
+		if days := int(time.Until(cert.NotAfter).Hours() / 24); days < expect.MinDaysUntilExpiry {
+			err = fmt.Errorf("case: %s, expect the certificate expires in %d days at least, actual %d days, not after %s",
+				name, expect.MinDaysUntilExpiry, days, cert.NotAfter.Format(time.RFC3339))
+		}
+	}
+	return
+}
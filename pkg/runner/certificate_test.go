@@ -0,0 +1,77 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpectCertificate(t *testing.T) {
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer tlsServer.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		api       string
+		expect    *atest.CertificateExpect
+		expectErr string
+	}{{
+		name: "expected certificate",
+		api:  tlsServer.URL,
+		expect: &atest.CertificateExpect{
+			Issuer:             "O=Acme Co",
+			Subject:            "Acme Co",
+			SANs:               []string{"example.com", "127.0.0.1"},
+			MinDaysUntilExpiry: 30,
+		},
+	}, {
+		name:      "unexpected issuer",
+		api:       tlsServer.URL,
+		expect:    &atest.CertificateExpect{Issuer: "Let's Encrypt"},
+		expectErr: `case: cert, expect the certificate issuer "Let's Encrypt", actual "O=Acme Co"`,
+	}, {
+		name:      "unexpected subject",
+		api:       tlsServer.URL,
+		expect:    &atest.CertificateExpect{Subject: "CN=foo"},
+		expectErr: `case: cert, expect the certificate subject "CN=foo"`,
+	}, {
+		name:      "not in the SANs",
+		api:       tlsServer.URL,
+		expect:    &atest.CertificateExpect{SANs: []string{"foo.com"}},
+		expectErr: "case: cert, expect foo.com in the certificate SANs, actual [example.com",
+	}, {
+		name:      "expires soon",
+		api:       tlsServer.URL,
+		expect:    &atest.CertificateExpect{MinDaysUntilExpiry: 365 * 100},
+		expectErr: "case: cert, expect the certificate expires in 36500 days at least",
+	}, {
+		name:      "not HTTPS",
+		api:       server.URL,
+		expect:    &atest.CertificateExpect{MinDaysUntilExpiry: 1},
+		expectErr: "case: cert, no server certificate found, the API should be HTTPS",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSimpleTestCaseRunner().RunTestCase(&atest.TestCase{
+				Name:    "cert",
+				Request: atest.Request{API: tt.api},
+				Expect:  atest.Response{Certificate: tt.expect},
+			}, nil, context.TODO())
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expectErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/andreyvit/diff"
+)
+
+// TargetRecords holds the records of running the test suites against a target
+type TargetRecords struct {
+	Name    string
+	Records []*ReportRecord
+}
+
+// WriteComparison writes a Markdown report which compares the status, latency and body
+// of the same test cases side by side, the first target is the baseline of the body diffs
+func WriteComparison(writer io.Writer, targets []TargetRecords) (err error) {
+	if len(targets) == 0 {
+		return
+	}
+
+	var names []string
+	records := make([]map[string]*ReportRecord, len(targets))
+	for i, target := range targets {
+		records[i] = map[string]*ReportRecord{}
+		for _, record := range target.Records {
+			if _, ok := records[i][record.Name]; ok {
+				continue
+			}
+			records[i][record.Name] = record
+			if !contains(names, record.Name) {
+				names = append(names, record.Name)
+			}
+		}
+	}
+
+	buf := new(strings.Builder)
+	buf.WriteString("| Case |")
+	for _, target := range targets {
+		buf.WriteString(" " + target.Name + " |")
+	}
+	buf.WriteString("\n|---|" + strings.Repeat("---|", len(targets)) + "\n")
+
+	var diffs []string
+	for _, name := range names {
+		baseline := records[0][name]
+		buf.WriteString("| " + name + " |")
+		for i, target := range targets {
+			record, ok := records[i][name]
+			if !ok {
+				buf.WriteString(" - |")
+				continue
+			}
+
+			cell := fmt.Sprintf("%d %s", record.StatusCode, record.Duration().Round(time.Millisecond))
+			if record.Error != nil {
+				cell += " error"
+			}
+			if i > 0 && baseline != nil && !sameBody(baseline.Body, record.Body) {
+				cell += " body diff"
+				diffs = append(diffs, fmt.Sprintf("#### %s: %s vs %s\n\n```diff\n%s\n```\n", name,
+					targets[0].Name, target.Name, diff.LineDiff(formatBody(baseline.Body), formatBody(record.Body))))
+			}
+			buf.WriteString(" " + cell + " |")
+		}
+		buf.WriteString("\n")
+	}
+
+	if len(diffs) > 0 {
+		buf.WriteString("\n### Body diffs\n\n" + strings.Join(diffs, "\n"))
+	}
+	_, err = io.WriteString(writer, buf.String())
+	return
+}
+
+// sameBody compares the JSON bodies semantically, others are compared as text
+func sameBody(a, b string) bool {
+	var objA, objB interface{}
+	if json.Unmarshal([]byte(a), &objA) == nil && json.Unmarshal([]byte(b), &objB) == nil {
+		return reflect.DeepEqual(objA, objB)
+	}
+	return a == b
+}
+
+// formatBody indents the JSON body to make the diff readable
+func formatBody(body string) string {
+	var object interface{}
+	if err := json.Unmarshal([]byte(body), &object); err == nil {
+		if data, err := json.MarshalIndent(object, "", "  "); err == nil {
+			return string(data)
+		}
+	}
+	return body
+}
+
+func contains(items []string, item string) bool {
+	for _, val := range items {
+		if val == item {
+			return true
+		}
+	}
+	return false
+}
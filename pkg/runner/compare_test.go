@@ -0,0 +1,46 @@
+package runner
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteComparison(t *testing.T) {
+	now := time.Now()
+	newRecord := func(name string, status int, latency time.Duration, body string) *ReportRecord {
+		return &ReportRecord{Name: name, StatusCode: status, Body: body, BeginTime: now, EndTime: now.Add(latency)}
+	}
+
+	failed := newRecord("get", 500, 3*time.Millisecond, "oops")
+	failed.Error = errors.New("fake")
+
+	buf := new(bytes.Buffer)
+	err := WriteComparison(buf, []TargetRecords{{
+		Name: "prod",
+		Records: []*ReportRecord{
+			newRecord("list", 200, 10*time.Millisecond, `{"a": 1, "b": 2}`),
+			newRecord("get", 200, 20*time.Millisecond, `{"a": 1}`),
+		},
+	}, {
+		Name: "canary",
+		Records: []*ReportRecord{
+			newRecord("list", 200, 12*time.Millisecond, `{"b":2,"a":1}`),
+			failed,
+			newRecord("new", 201, time.Millisecond, ""),
+		},
+	}})
+	assert.Nil(t, err)
+	assert.Equal(t, "| Case | prod | canary |\n|---|---|---|\n"+
+		"| list | 200 10ms | 200 12ms |\n"+
+		"| get | 200 20ms | 500 3ms error body diff |\n"+
+		"| new | - | 201 1ms |\n"+
+		"\n### Body diffs\n\n#### get: prod vs canary\n\n```diff\n-{\n-  \"a\": 1\n-}\n+oops\n```\n", buf.String())
+
+	buf.Reset()
+	assert.Nil(t, WriteComparison(buf, nil))
+	assert.Empty(t, buf.String())
+}
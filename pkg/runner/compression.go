@@ -0,0 +1,83 @@
+package runner
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+// decodableEncodings are the Content-Encodings which are decompressed by the standard library
+var decodableEncodings = map[string]bool{"gzip": true, "x-gzip": true, "deflate": true}
+
+// acceptEncoding returns the Accept-Encoding header of the compression expectation. The transport doesn't
+// decompress the body transparently once it's set, so the received size is known
+func acceptEncoding(expect *testing.CompressionExpect) string {
+	if expect.Encoding != "" {
+		return expect.Encoding
+	}
+	return "gzip, deflate"
+}
+
+// decodeBody decompresses the gzip or deflate body, the other encodings are kept as they're received
+func decodeBody(header http.Header, data []byte) (body []byte, err error) {
+	var reader io.ReadCloser
+	switch strings.ToLower(strings.TrimSpace(header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		reader, err = gzip.NewReader(bytes.NewReader(data))
+	case "deflate":
+		// it should be in the zlib format, but the raw deflate is sent by some servers
+		if reader, err = zlib.NewReader(bytes.NewReader(data)); err != nil {
+			reader, err = flate.NewReader(bytes.NewReader(data)), nil
+		}
+	default:
+		body = data
+		return
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to decode the body, %v", err)
+		return
+	}
+	defer reader.Close()
+
+	if body, err = io.ReadAll(reader); err != nil {
+		err = fmt.Errorf("failed to decode the body, %v", err)
+	}
+	return
+}
+
+// expectCompression verifies the Content-Encoding and the ratio of the decoded size to the received size,
+// the responses which are smaller than the threshold are not verified
+func expectCompression(name string, expect *testing.CompressionExpect, header http.Header, received, decoded []byte) (err error) {
+	if expect == nil || len(decoded) < expect.MinSize {
+		return
+	}
+
+	encoding := strings.TrimSpace(header.Get("Content-Encoding"))
+	if encoding == "" || strings.EqualFold(encoding, "identity") {
+		err = fmt.Errorf("case: %s, expect the body of %d bytes is compressed, but there is no Content-Encoding", name, len(decoded))
+		return
+	}
+	if expect.Encoding != "" && !strings.EqualFold(expect.Encoding, encoding) {
+		err = fmt.Errorf("case: %s, expect the Content-Encoding %s, actual %s", name, expect.Encoding, encoding)
+		return
+	}
+
+	if expect.MinRatio > 0 {
+		if !decodableEncodings[strings.ToLower(encoding)] {
+			err = fmt.Errorf("case: %s, could not get the compression ratio of the Content-Encoding %s", name, encoding)
+			return
+		}
+		if ratio := float64(len(decoded)) / float64(len(received)); ratio < expect.MinRatio {
+			err = fmt.Errorf("case: %s, expect the compression ratio not less than %.2f, actual %.2f (%d/%d bytes)",
+				name, expect.MinRatio, ratio, len(decoded), len(received))
+		}
+	}
+	return
+}
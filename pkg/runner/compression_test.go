@@ -0,0 +1,117 @@
+package runner
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpectCompression(t *testing.T) {
+	body := `{"items": [` + strings.TrimSuffix(strings.Repeat(`{"name": "rick"},`, 100), ",") + `]}`
+	compressed := func(w http.ResponseWriter, encoding string, level int) {
+		var writer io.WriteCloser
+		if encoding == "deflate" {
+			writer, _ = zlib.NewWriterLevel(w, level)
+		} else {
+			writer, _ = gzip.NewWriterLevel(w, level)
+		}
+		w.Header().Set("Content-Encoding", encoding)
+		_, _ = writer.Write([]byte(body))
+		_ = writer.Close()
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/negotiate", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("Accept-Encoding") {
+		case "gzip, deflate":
+			compressed(w, "gzip", gzip.BestCompression)
+		case "deflate":
+			compressed(w, "deflate", zlib.BestCompression)
+		default:
+			_, _ = w.Write([]byte(body))
+		}
+	})
+	mux.HandleFunc("/stored", func(w http.ResponseWriter, r *http.Request) {
+		compressed(w, "gzip", gzip.NoCompression)
+	})
+	mux.HandleFunc("/br", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "br")
+		_, _ = w.Write([]byte(body))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		path      string
+		header    map[string]string
+		expect    *atest.CompressionExpect
+		expectErr string
+	}{{
+		name:   "gzip",
+		path:   "/negotiate",
+		expect: &atest.CompressionExpect{Encoding: "gzip", MinSize: 1024, MinRatio: 5},
+		header: map[string]string{"Accept-Encoding": "gzip, deflate"},
+	}, {
+		name:   "any encoding",
+		path:   "/negotiate",
+		expect: &atest.CompressionExpect{MinRatio: 5},
+	}, {
+		name:   "deflate",
+		path:   "/negotiate",
+		expect: &atest.CompressionExpect{Encoding: "deflate", MinRatio: 5},
+	}, {
+		name:   "smaller than the threshold",
+		path:   "/plain",
+		expect: &atest.CompressionExpect{Encoding: "gzip", MinSize: 4096},
+	}, {
+		name:      "not compressed",
+		path:      "/plain",
+		expect:    &atest.CompressionExpect{Encoding: "gzip", MinSize: 1024},
+		expectErr: "case: compression, expect the body of 1712 bytes is compressed, but there is no Content-Encoding",
+	}, {
+		name:      "unexpected encoding",
+		path:      "/negotiate",
+		header:    map[string]string{"Accept-Encoding": "gzip, deflate"},
+		expect:    &atest.CompressionExpect{Encoding: "deflate"},
+		expectErr: "case: compression, expect the Content-Encoding deflate, actual gzip",
+	}, {
+		name:      "low ratio",
+		path:      "/stored",
+		expect:    &atest.CompressionExpect{MinRatio: 2},
+		expectErr: "case: compression, expect the compression ratio not less than 2.00, actual 0.99",
+	}, {
+		name:      "the ratio of brotli",
+		path:      "/br",
+		expect:    &atest.CompressionExpect{Encoding: "br", MinRatio: 2},
+		expectErr: "case: compression, could not get the compression ratio of the Content-Encoding br",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSimpleTestCaseRunner().RunTestCase(&atest.TestCase{
+				Name:    "compression",
+				Request: atest.Request{API: server.URL + tt.path, Header: tt.header},
+				Expect: atest.Response{
+					Compression: tt.expect,
+					Verify:      []string{`data.items[0].name == "rick"`},
+				},
+			}, nil, context.TODO())
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package runner
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+// expectCookies verifies the cookies of the Set-Cookie headers, the last one wins if a cookie is set more than once
+func expectCookies(name string, expect map[string]*testing.CookieExpect, cookies []*http.Cookie) (err error) {
+	actual := map[string]*http.Cookie{}
+	for _, cookie := range cookies {
+		actual[cookie.Name] = cookie
+	}
+
+	for cookieName, cookieExpect := range expect {
+		cookie, ok := actual[cookieName]
+		if !ok {
+			err = fmt.Errorf("case: %s, not found the cookie %s in the Set-Cookie headers", name, cookieName)
+			return
+		}
+		if cookieExpect == nil {
+			continue
+		}
+		if err = expectCookie(cookieExpect, cookie); err != nil {
+			err = fmt.Errorf("case: %s, cookie %s, %v", name, cookieName, err)
+			return
+		}
+	}
+	return
+}
+
+// expectCookie compares the attributes which are set
+func expectCookie(expect *testing.CookieExpect, cookie *http.Cookie) (err error) {
+	switch {
+	case expect.Value != "" && expect.Value != cookie.Value:
+		err = fmt.Errorf("expect value %s, actual %s", expect.Value, cookie.Value)
+	case expect.Path != "" && expect.Path != cookie.Path:
+		err = fmt.Errorf("expect Path %s, actual %s", expect.Path, cookie.Path)
+	case expect.Domain != "" && !strings.EqualFold(strings.TrimPrefix(expect.Domain, "."), strings.TrimPrefix(cookie.Domain, ".")):
+		err = fmt.Errorf("expect Domain %s, actual %s", expect.Domain, cookie.Domain)
+	case expect.Secure != nil && *expect.Secure != cookie.Secure:
+		err = fmt.Errorf("expect Secure %v, actual %v", *expect.Secure, cookie.Secure)
+	case expect.HTTPOnly != nil && *expect.HTTPOnly != cookie.HttpOnly:
+		err = fmt.Errorf("expect HttpOnly %v, actual %v", *expect.HTTPOnly, cookie.HttpOnly)
+	case expect.SameSite != "" && !strings.EqualFold(expect.SameSite, sameSiteName(cookie.SameSite)):
+		err = fmt.Errorf("expect SameSite %s, actual %s", expect.SameSite, sameSiteName(cookie.SameSite))
+	case expect.MaxAge != nil:
+		// the negative MaxAge of the cookie means Max-Age=0, and zero means it's not set
+		switch {
+		case cookie.MaxAge == 0:
+			err = fmt.Errorf("expect Max-Age %d, but it's not set", *expect.MaxAge)
+		case cookie.MaxAge < 0 && *expect.MaxAge != 0, cookie.MaxAge > 0 && *expect.MaxAge != cookie.MaxAge:
+			err = fmt.Errorf("expect Max-Age %d, actual %s", *expect.MaxAge, maxAgeValue(cookie.MaxAge))
+		}
+	}
+	return
+}
+
+func sameSiteName(mode http.SameSite) string {
+	switch mode {
+	case http.SameSiteStrictMode:
+		return "Strict"
+	case http.SameSiteLaxMode:
+		return "Lax"
+	case http.SameSiteNoneMode:
+		return "None"
+	}
+	return ""
+}
+
+func maxAgeValue(maxAge int) string {
+	if maxAge < 0 {
+		return "0"
+	}
+	return fmt.Sprint(maxAge)
+}
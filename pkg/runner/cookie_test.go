@@ -0,0 +1,94 @@
+package runner
+
+import (
+	"net/http"
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpectCookies(t *testing.T) {
+	header := http.Header{}
+	header.Add("Set-Cookie", "session=abc; Path=/; Domain=.example.com; Max-Age=3600; Secure; HttpOnly; SameSite=Strict")
+	header.Add("Set-Cookie", "theme=dark; Path=/settings")
+	header.Add("Set-Cookie", "token=; Max-Age=0")
+	cookies := (&http.Response{Header: header}).Cookies()
+
+	yes, no := true, false
+	hour, zero := 3600, 0
+
+	tests := []struct {
+		name      string
+		expect    map[string]*atest.CookieExpect
+		expectErr string
+	}{{
+		name: "all the attributes",
+		expect: map[string]*atest.CookieExpect{
+			"session": {
+				Value:    "abc",
+				Path:     "/",
+				Domain:   "example.com",
+				Secure:   &yes,
+				HTTPOnly: &yes,
+				MaxAge:   &hour,
+				SameSite: "strict",
+			},
+			"theme": {Value: "dark", Secure: &no, HTTPOnly: &no},
+			"token": {MaxAge: &zero},
+		},
+	}, {
+		name:   "only the existence",
+		expect: map[string]*atest.CookieExpect{"theme": nil},
+	}, {
+		name:      "not found",
+		expect:    map[string]*atest.CookieExpect{"fake": nil},
+		expectErr: "case: fake, not found the cookie fake in the Set-Cookie headers",
+	}, {
+		name:      "unexpected value",
+		expect:    map[string]*atest.CookieExpect{"theme": {Value: "light"}},
+		expectErr: "case: fake, cookie theme, expect value light, actual dark",
+	}, {
+		name:      "not secure",
+		expect:    map[string]*atest.CookieExpect{"theme": {Secure: &yes}},
+		expectErr: "expect Secure true, actual false",
+	}, {
+		name:      "not HttpOnly",
+		expect:    map[string]*atest.CookieExpect{"theme": {HTTPOnly: &yes}},
+		expectErr: "expect HttpOnly true, actual false",
+	}, {
+		name:      "unexpected path",
+		expect:    map[string]*atest.CookieExpect{"theme": {Path: "/"}},
+		expectErr: "expect Path /, actual /settings",
+	}, {
+		name:      "unexpected domain",
+		expect:    map[string]*atest.CookieExpect{"session": {Domain: "example.org"}},
+		expectErr: "expect Domain example.org, actual",
+	}, {
+		name:      "unexpected SameSite",
+		expect:    map[string]*atest.CookieExpect{"theme": {SameSite: "Lax"}},
+		expectErr: "expect SameSite Lax, actual ",
+	}, {
+		name:      "no Max-Age",
+		expect:    map[string]*atest.CookieExpect{"theme": {MaxAge: &hour}},
+		expectErr: "expect Max-Age 3600, but it's not set",
+	}, {
+		name:      "unexpected Max-Age",
+		expect:    map[string]*atest.CookieExpect{"session": {MaxAge: &zero}},
+		expectErr: "expect Max-Age 0, actual 3600",
+	}, {
+		name:      "deleted cookie",
+		expect:    map[string]*atest.CookieExpect{"token": {MaxAge: &hour}},
+		expectErr: "expect Max-Age 3600, actual 0",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := expectCookies("fake", tt.expect, cookies)
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expectErr)
+			}
+		})
+	}
+}
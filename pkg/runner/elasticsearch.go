@@ -0,0 +1,151 @@
+package runner
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/linuxsuren/api-testing/pkg/util"
+)
+
+const (
+	// defaultElasticsearchURL is the address of the cluster if it's not set
+	defaultElasticsearchURL = "http://localhost:9200"
+	// defaultElasticsearchTimeout is the timeout of waiting for the expected hits if it's not set
+	defaultElasticsearchTimeout = 10 * time.Second
+	// elasticsearchInterval is the interval of the searches, the documents are searchable after refreshing
+	elasticsearchInterval = 500 * time.Millisecond
+)
+
+// elasticsearchResult is the part of the search response which is verified
+type elasticsearchResult struct {
+	Hits struct {
+		// Total is a number before Elasticsearch 7, or an object like {"value": 1, "relation": "eq"}
+		Total json.RawMessage `json:"total"`
+		Hits  []struct {
+			Source json.RawMessage `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// verifyElasticsearch searches the index until the hits match the expectations or timeout
+func (r *simpleTestCaseRunner) verifyElasticsearch(verification *testing.ElasticsearchVerification) (err error) {
+	if verification.Index == "" {
+		err = fmt.Errorf("the index is required")
+		return
+	}
+
+	timeout := defaultElasticsearchTimeout
+	if verification.Timeout != "" {
+		if timeout, err = time.ParseDuration(verification.Timeout); err != nil {
+			return
+		}
+	}
+
+	query := []byte("{}")
+	if strings.TrimSpace(verification.Query) != "" {
+		// the query could be written in YAML or JSON
+		if query, err = yaml.YAMLToJSON([]byte(verification.Query)); err != nil {
+			err = fmt.Errorf("invalid query, %v", err)
+			return
+		}
+	}
+
+	url := strings.TrimSuffix(verification.URL, "/")
+	if url == "" {
+		url = defaultElasticsearchURL
+	}
+	api := fmt.Sprintf("%s/%s/_search", url, verification.Index)
+
+	client := http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	r.log.Info("start to search %s\n", api)
+	deadline := time.Now().Add(timeout)
+	for {
+		var result *elasticsearchResult
+		if result, err = searchElasticsearch(client, api, query, verification); err != nil {
+			return
+		}
+		if err = matchElasticsearchResult(result, verification); err == nil || time.Now().Add(elasticsearchInterval).After(deadline) {
+			return
+		}
+		r.log.Debug("the hits are not expected yet, %v\n", err)
+		time.Sleep(elasticsearchInterval)
+	}
+}
+
+func searchElasticsearch(client http.Client, api string, query []byte,
+	verification *testing.ElasticsearchVerification) (result *elasticsearchResult, err error) {
+	var req *http.Request
+	if req, err = http.NewRequest(http.MethodPost, api, bytes.NewReader(query)); err != nil {
+		return
+	}
+	req.Header.Set(util.ContentType, "application/json")
+	if verification.Username != "" {
+		req.SetBasicAuth(verification.Username, verification.Password)
+	}
+
+	var resp *http.Response
+	if resp, err = client.Do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	var data []byte
+	if data, err = io.ReadAll(resp.Body); err != nil {
+		return
+	}
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("unexpected status code %d, %s", resp.StatusCode, string(data))
+		return
+	}
+
+	result = &elasticsearchResult{}
+	err = json.Unmarshal(data, result)
+	return
+}
+
+func matchElasticsearchResult(result *elasticsearchResult, verification *testing.ElasticsearchVerification) (err error) {
+	var total int
+	if err = json.Unmarshal(result.Hits.Total, &total); err != nil {
+		var totalObject struct {
+			Value int `json:"value"`
+		}
+		if err = json.Unmarshal(result.Hits.Total, &totalObject); err != nil {
+			return
+		}
+		total = totalObject.Value
+	}
+
+	if verification.Hits != nil {
+		if total != *verification.Hits {
+			err = fmt.Errorf("expect %d hits, actual %d", *verification.Hits, total)
+			return
+		}
+	} else if total == 0 {
+		err = fmt.Errorf("expect at least one hit, actual 0")
+		return
+	}
+
+	if len(verification.Fields) > 0 {
+		if len(result.Hits.Hits) == 0 {
+			err = fmt.Errorf("there is no hit to verify the fields")
+			return
+		}
+		_, err = verifyResponseBodyData(verification.Index, testing.Response{BodyFieldsExpect: verification.Fields},
+			result.Hits.Hits[0].Source)
+	}
+	return
+}
@@ -0,0 +1,97 @@
+package runner
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyElasticsearch(t *testing.T) {
+	var searches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		query, _ := io.ReadAll(req.Body)
+		switch {
+		case req.URL.Path == "/error/_search":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": "index_not_found_exception"}`))
+		case req.URL.Path == "/legacy/_search":
+			_, _ = w.Write([]byte(`{"hits": {"total": 1, "hits": [{"_source": {"name": "linuxsuren"}}]}}`))
+		case string(query) != `{"query":{"term":{"name":"linuxsuren"}}}`:
+			_, _ = w.Write([]byte(`{"hits": {"total": {"value": 0}, "hits": []}}`))
+		case atomic.AddInt32(&searches, 1) < 2:
+			// the document is not searchable before refreshing
+			_, _ = w.Write([]byte(`{"hits": {"total": {"value": 0}, "hits": []}}`))
+		default:
+			user, _, _ := req.BasicAuth()
+			_, _ = w.Write([]byte(`{"hits": {"total": {"value": 1, "relation": "eq"}, "hits": [{"_source": {"name": "linuxsuren", "user": "` + user + `", "address": {"city": "Beijing"}}}]}}`))
+		}
+	}))
+	defer server.Close()
+
+	zero := 0
+	tests := []struct {
+		name         string
+		verification *atest.ElasticsearchVerification
+		expectErr    string
+	}{{
+		name: "wait until the document is searchable",
+		verification: &atest.ElasticsearchVerification{
+			URL:      server.URL,
+			Index:    "users",
+			Query:    "query:\n  term:\n    name: linuxsuren",
+			Username: "admin",
+			Fields:   map[string]interface{}{"user": "admin", "address/city": "Beijing"},
+		},
+	}, {
+		name:         "the total hits of the legacy version",
+		verification: &atest.ElasticsearchVerification{URL: server.URL + "/", Index: "legacy"},
+	}, {
+		name:         "no hits",
+		verification: &atest.ElasticsearchVerification{URL: server.URL, Index: "users", Hits: &zero},
+	}, {
+		name:         "unexpected hits",
+		verification: &atest.ElasticsearchVerification{URL: server.URL, Index: "users", Timeout: "100ms"},
+		expectErr:    "expect at least one hit, actual 0",
+	}, {
+		name: "unexpected fields",
+		verification: &atest.ElasticsearchVerification{
+			URL:     server.URL,
+			Index:   "legacy",
+			Fields:  map[string]interface{}{"name": "fake"},
+			Timeout: "100ms",
+		},
+		expectErr: "field[name] expect value: fake, actual: linuxsuren",
+	}, {
+		name:         "unexpected status code",
+		verification: &atest.ElasticsearchVerification{URL: server.URL, Index: "error"},
+		expectErr:    "unexpected status code 404",
+	}, {
+		name:         "index is required",
+		verification: &atest.ElasticsearchVerification{URL: server.URL},
+		expectErr:    "the index is required",
+	}, {
+		name:         "invalid query",
+		verification: &atest.ElasticsearchVerification{URL: server.URL, Index: "users", Query: "{"},
+		expectErr:    "invalid query",
+	}, {
+		name:         "invalid timeout",
+		verification: &atest.ElasticsearchVerification{URL: server.URL, Index: "users", Timeout: "fake"},
+		expectErr:    "invalid duration",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewSimpleTestCaseRunner().(*simpleTestCaseRunner)
+			err := r.verifyElasticsearch(tt.verification)
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expectErr)
+			}
+		})
+	}
+}
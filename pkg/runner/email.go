@@ -0,0 +1,242 @@
+package runner
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/client"
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+const (
+	// defaultEmailTimeout is the timeout of waiting for the expected email if it's not set
+	defaultEmailTimeout = 10 * time.Second
+	// emailInterval is the interval of polling the mailbox
+	emailInterval = 500 * time.Millisecond
+	// defaultMailbox is the IMAP mailbox if it's not set
+	defaultMailbox = "INBOX"
+)
+
+// mailbox searches the emails which match the verification
+type mailbox interface {
+	search(verification *testing.EmailVerification) (found bool, err error)
+	close() error
+}
+
+// verifyEmail polls the mailbox until the expected email arrives or timeout
+func (r *simpleTestCaseRunner) verifyEmail(verification *testing.EmailVerification) (err error) {
+	timeout := defaultEmailTimeout
+	if verification.Timeout != "" {
+		if timeout, err = time.ParseDuration(verification.Timeout); err != nil {
+			return
+		}
+	}
+
+	var box mailbox
+	if box, err = openMailbox(verification, timeout); err != nil {
+		return
+	}
+	defer func() {
+		_ = box.close()
+	}()
+
+	r.log.Info("start to search the emails of %s\n", verification.Server)
+	deadline := time.Now().Add(timeout)
+	for {
+		var found bool
+		if found, err = box.search(verification); err != nil || found {
+			return
+		}
+		if time.Now().Add(emailInterval).After(deadline) {
+			err = fmt.Errorf("not found the email to %q with the subject %q in %v", verification.To, verification.Subject, timeout)
+			return
+		}
+		time.Sleep(emailInterval)
+	}
+}
+
+// openMailbox connects to the server according to the scheme
+func openMailbox(verification *testing.EmailVerification, timeout time.Duration) (box mailbox, err error) {
+	var server *url.URL
+	if server, err = url.Parse(verification.Server); err != nil {
+		return
+	}
+
+	switch server.Scheme {
+	case "http", "https":
+		box = &mailHogMailbox{
+			api: strings.TrimSuffix(verification.Server, "/") + "/api/v2/messages",
+			client: http.Client{
+				Timeout: timeout,
+				Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+				},
+			},
+		}
+	case "imap", "imaps":
+		box, err = openIMAPMailbox(server, verification, timeout)
+	default:
+		err = fmt.Errorf("unsupported scheme %q, only http, https, imap and imaps are supported", server.Scheme)
+	}
+	return
+}
+
+// mailHogMailbox searches the emails by the API of MailHog
+type mailHogMailbox struct {
+	api    string
+	client http.Client
+}
+
+// mailHogMessages is the part of the messages which is searched
+type mailHogMessages struct {
+	Items []struct {
+		Content mailHogContent `json:"Content"`
+		MIME    *struct {
+			Parts []mailHogContent `json:"Parts"`
+		} `json:"MIME"`
+	} `json:"items"`
+}
+
+type mailHogContent struct {
+	Headers map[string][]string `json:"Headers"`
+	Body    string              `json:"Body"`
+}
+
+func (m *mailHogMailbox) search(verification *testing.EmailVerification) (found bool, err error) {
+	var resp *http.Response
+	if resp, err = m.client.Get(m.api); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	var data []byte
+	if data, err = io.ReadAll(resp.Body); err != nil {
+		return
+	}
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("unexpected status code %d, %s", resp.StatusCode, string(data))
+		return
+	}
+
+	messages := &mailHogMessages{}
+	if err = json.Unmarshal(data, messages); err != nil {
+		return
+	}
+	for _, item := range messages.Items {
+		body := item.Content.Body
+		if item.MIME != nil {
+			for _, part := range item.MIME.Parts {
+				body += part.Body
+			}
+		}
+		if matchEmail(item.Content.Headers, body, verification) {
+			found = true
+			return
+		}
+	}
+	return
+}
+
+func (m *mailHogMailbox) close() error {
+	return nil
+}
+
+// matchEmail checks the sub-strings of the headers and the body, the encoded headers are decoded
+func matchEmail(headers map[string][]string, body string, verification *testing.EmailVerification) bool {
+	decoder := new(mime.WordDecoder)
+	header := func(key string) string {
+		var values []string
+		for name, items := range headers {
+			if strings.EqualFold(name, key) {
+				values = append(values, items...)
+			}
+		}
+		value := strings.Join(values, ", ")
+		if decoded, err := decoder.DecodeHeader(value); err == nil {
+			value = decoded
+		}
+		return value
+	}
+
+	return strings.Contains(strings.ToLower(header("To")), strings.ToLower(verification.To)) &&
+		strings.Contains(header("Subject"), verification.Subject) &&
+		strings.Contains(body, verification.BodyContains)
+}
+
+// imapMailbox searches the emails by the IMAP SEARCH command
+type imapMailbox struct {
+	client *client.Client
+}
+
+func openIMAPMailbox(server *url.URL, verification *testing.EmailVerification, timeout time.Duration) (box mailbox, err error) {
+	address := server.Host
+	if server.Port() == "" {
+		port := "143"
+		if server.Scheme == "imaps" {
+			port = "993"
+		}
+		address = net.JoinHostPort(server.Hostname(), port)
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	var c *client.Client
+	if server.Scheme == "imaps" {
+		c, err = client.DialWithDialerTLS(dialer, address, &tls.Config{ServerName: server.Hostname()})
+	} else {
+		c, err = client.DialWithDialer(dialer, address)
+	}
+	if err != nil {
+		return
+	}
+	c.Timeout = timeout
+
+	if verification.Username != "" {
+		if err = c.Login(verification.Username, verification.Password); err != nil {
+			_ = c.Logout()
+			return
+		}
+	}
+
+	name := verification.Mailbox
+	if name == "" {
+		name = defaultMailbox
+	}
+	if _, err = c.Select(name, true); err != nil {
+		_ = c.Logout()
+		return
+	}
+	box = &imapMailbox{client: c}
+	return
+}
+
+func (m *imapMailbox) search(verification *testing.EmailVerification) (found bool, err error) {
+	criteria := imap.NewSearchCriteria()
+	if verification.To != "" {
+		criteria.Header.Add("To", verification.To)
+	}
+	if verification.Subject != "" {
+		criteria.Header.Add("Subject", verification.Subject)
+	}
+	if verification.BodyContains != "" {
+		criteria.Body = []string{verification.BodyContains}
+	}
+
+	var ids []uint32
+	if ids, err = m.client.Search(criteria); err == nil {
+		found = len(ids) > 0
+	}
+	return
+}
+
+func (m *imapMailbox) close() error {
+	return m.client.Logout()
+}
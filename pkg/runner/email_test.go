@@ -0,0 +1,210 @@
+package runner
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyEmail(t *testing.T) {
+	mailHog := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/messages" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = io.WriteString(w, `{"total": 2, "items": [{
+  "Content": {"Headers": {"To": ["rick@example.com"], "Subject": ["Welcome"]}, "Body": "Hi rick"}
+}, {
+  "Content": {"Headers": {"To": ["Morty <morty@example.com>"], "Subject": ["=?UTF-8?B?5qyi6L+O?="]}, "Body": ""},
+  "MIME": {"Parts": [{"Body": "Your code is 123456"}]}
+}]}`)
+	}))
+	defer mailHog.Close()
+
+	imapAddress := startFakeIMAP(t)
+
+	tests := []struct {
+		name         string
+		verification *atest.EmailVerification
+		expectErr    string
+	}{{
+		name:         "found by MailHog",
+		verification: &atest.EmailVerification{Server: mailHog.URL, To: "RICK@example.com", Subject: "Welcome", BodyContains: "rick"},
+	}, {
+		name:         "found the encoded subject and the MIME part by MailHog",
+		verification: &atest.EmailVerification{Server: mailHog.URL + "/", To: "morty@example.com", Subject: "欢迎", BodyContains: "123456"},
+	}, {
+		name:         "not found by MailHog",
+		verification: &atest.EmailVerification{Server: mailHog.URL, To: "rick@example.com", Subject: "Goodbye", Timeout: "600ms"},
+		expectErr:    `not found the email to "rick@example.com" with the subject "Goodbye"`,
+	}, {
+		name:         "unexpected status code",
+		verification: &atest.EmailVerification{Server: mailHog.URL + "/fake"},
+		expectErr:    "unexpected status code 404",
+	}, {
+		name: "found by IMAP",
+		verification: &atest.EmailVerification{
+			Server:       "imap://" + imapAddress,
+			Username:     "username",
+			Password:     "password",
+			To:           "contact@example.org",
+			Subject:      "little message",
+			BodyContains: "Hi there",
+		},
+	}, {
+		name: "not found by IMAP",
+		verification: &atest.EmailVerification{
+			Server:   "imap://" + imapAddress,
+			Username: "username",
+			Password: "password",
+			Subject:  "Goodbye",
+			Timeout:  "600ms",
+		},
+		expectErr: "not found the email",
+	}, {
+		name:         "wrong password",
+		verification: &atest.EmailVerification{Server: "imap://" + imapAddress, Username: "username", Password: "fake"},
+		expectErr:    "Bad username or password",
+	}, {
+		name:         "not found the mailbox",
+		verification: &atest.EmailVerification{Server: "imap://" + imapAddress, Username: "username", Password: "password", Mailbox: "Fake"},
+		expectErr:    "No such mailbox",
+	}, {
+		name:         "unsupported scheme",
+		verification: &atest.EmailVerification{Server: "pop3://localhost"},
+		expectErr:    `unsupported scheme "pop3"`,
+	}, {
+		name:         "invalid timeout",
+		verification: &atest.EmailVerification{Server: mailHog.URL, Timeout: "fake"},
+		expectErr:    "invalid duration",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewSimpleTestCaseRunner().(*simpleTestCaseRunner)
+			err := r.verifyEmail(tt.verification)
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expectErr)
+			}
+		})
+	}
+}
+
+// startFakeIMAP starts a server which has one email in the INBOX of the user "username"
+func startFakeIMAP(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveFakeIMAP(conn)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// serveFakeIMAP implements the commands which are used by the client, the SEARCH command matches the
+// arguments of the TO, SUBJECT, HEADER and BODY keys with the only email
+func serveFakeIMAP(conn net.Conn) {
+	defer conn.Close()
+	headers := map[string]string{"TO": "contact@example.org", "SUBJECT": "A little message, just for you"}
+	body := "Hi there :)"
+
+	_, _ = io.WriteString(conn, "* OK [CAPABILITY IMAP4rev1] ready\r\n")
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := splitIMAPArgs(strings.TrimSpace(line))
+		if len(fields) < 2 {
+			return
+		}
+		tag, command, args := fields[0], strings.ToUpper(fields[1]), fields[2:]
+
+		reply := "OK completed"
+		switch command {
+		case "CAPABILITY":
+			_, _ = io.WriteString(conn, "* CAPABILITY IMAP4rev1\r\n")
+		case "LOGIN":
+			if len(args) != 2 || args[0] != "username" || args[1] != "password" {
+				reply = "NO Bad username or password"
+			}
+		case "SELECT", "EXAMINE":
+			if len(args) == 1 && args[0] == "INBOX" {
+				_, _ = io.WriteString(conn, "* FLAGS (\\Seen)\r\n* 1 EXISTS\r\n* 0 RECENT\r\n")
+				reply = "OK [READ-ONLY] completed"
+			} else {
+				reply = "NO No such mailbox"
+			}
+		case "SEARCH":
+			matched := true
+			for i := 0; i < len(args); i++ {
+				switch key := strings.ToUpper(args[i]); key {
+				case "CHARSET":
+					i++
+				case "TO", "SUBJECT":
+					matched = matched && i+1 < len(args) && strings.Contains(headers[key], args[i+1])
+					i++
+				case "HEADER":
+					matched = matched && i+2 < len(args) && strings.Contains(headers[strings.ToUpper(args[i+1])], args[i+2])
+					i += 2
+				case "BODY":
+					matched = matched && i+1 < len(args) && strings.Contains(body, args[i+1])
+					i++
+				}
+			}
+			if matched {
+				_, _ = io.WriteString(conn, "* SEARCH 1\r\n")
+			} else {
+				_, _ = io.WriteString(conn, "* SEARCH\r\n")
+			}
+		case "LOGOUT":
+			_, _ = io.WriteString(conn, "* BYE\r\n")
+		default:
+			reply = "BAD unknown command"
+		}
+		_, _ = fmt.Fprintf(conn, "%s %s\r\n", tag, reply)
+		if command == "LOGOUT" {
+			return
+		}
+	}
+}
+
+// splitIMAPArgs splits the command line by the spaces, the quotes of the strings are removed
+func splitIMAPArgs(line string) (args []string) {
+	var current strings.Builder
+	quoted := false
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '\\' && quoted && i+1 < len(line):
+			i++
+			current.WriteByte(line[i])
+		case c == '"':
+			quoted = !quoted
+		case c == ' ' && !quoted:
+			args = append(args, current.String())
+			current.Reset()
+		default:
+			current.WriteByte(c)
+		}
+	}
+	return append(args, current.String())
+}
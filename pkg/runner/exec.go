@@ -0,0 +1,118 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+type execTestCaseRunner struct {
+	*simpleTestCaseRunner
+}
+
+// NewExecTestCaseRunner creates the instance of the exec test case runner
+func NewExecTestCaseRunner() TestCaseRunner {
+	runner := &execTestCaseRunner{simpleTestCaseRunner: &simpleTestCaseRunner{}}
+	return runner.embeddedBy(runner)
+}
+
+// RunTestCase runs the command, then verifies the exit code and the output
+func (r *execTestCaseRunner) RunTestCase(testcase *testing.TestCase, dataContext interface{}, ctx context.Context) (output interface{}, err error) {
+	return r.runTestCaseWith(testcase, func(record *ReportRecord) (interface{}, error) {
+		return r.doExec(testcase, dataContext, record)
+	})
+}
+
+func (r *execTestCaseRunner) doExec(testcase *testing.TestCase, dataContext interface{},
+	record *ReportRecord) (output interface{}, err error) {
+	if err = testcase.Request.Render(dataContext); err != nil {
+		return
+	}
+
+	execRequest := testcase.Request.Exec
+	record.Method = "EXEC"
+	record.API = execRequest.Command
+	if execRequest.Command == "" {
+		err = fmt.Errorf("case: %s, the command is required", testcase.Name)
+		return
+	}
+
+	r.log.Info("start to run %s %s\n", execRequest.Command, strings.Join(execRequest.Args, " "))
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	exitCode := 0
+	if runErr := r.execer.RunCommandWithBuffer(execRequest.Command, execRequest.Dir, stdout, stderr, execRequest.Args...); runErr != nil {
+		// the error without the exit code means the command cannot be started
+		var exitErr interface{ ExitCode() int }
+		if !errors.As(runErr, &exitErr) {
+			err = fmt.Errorf("case: %s, failed to run the command, %v", testcase.Name, runErr)
+			return
+		}
+		exitCode = exitErr.ExitCode()
+	}
+	r.log.Debug("stdout: %s\nstderr: %s\n", stdout.String(), stderr.String())
+
+	result := map[string]interface{}{
+		"stdout":   strings.TrimRight(stdout.String(), "\r\n"),
+		"stderr":   strings.TrimRight(stderr.String(), "\r\n"),
+		"exitCode": exitCode,
+	}
+	record.Body = result["stdout"].(string)
+
+	if exitCode != testcase.Expect.ExitCode {
+		err = fmt.Errorf("case: %s, expect exit code %d, actual %d, stderr: %s", testcase.Name,
+			testcase.Expect.ExitCode, exitCode, result["stderr"])
+		return
+	}
+
+	// the expected body is compared with the stdout rather than the whole output
+	expect := testcase.Expect
+	if expect.Body != "" {
+		if err = expectString(testcase.Name, strings.TrimSpace(expect.Body), strings.TrimSpace(record.Body)); err != nil {
+			return
+		}
+		expect.Body = ""
+	}
+
+	var data []byte
+	if data, err = json.Marshal(result); err != nil {
+		return
+	}
+	if output, err = verifyResponseBodyData(testcase.Name, expect, data); err != nil {
+		return
+	}
+	err = jsonSchemaValidation(expect.Schema, data)
+	return
+}
+
+// ExecFeatures returns the features of the test case which run the local commands, such as the exec request.
+// They should be allowed explicitly if the test case comes from the remote
+func ExecFeatures(testcase *testing.TestCase) (features []string) {
+	if testcase.Request.Exec != nil {
+		features = append(features, "exec request")
+	}
+	if signing := testcase.Request.Signing; signing != nil && signing.Type == signerExec {
+		features = append(features, "exec signer")
+	}
+	for _, verification := range testcase.Expect.Verifiers {
+		if verification.Type == verifierExec {
+			features = append(features, "exec verifier")
+			break
+		}
+	}
+	if plugins := testcase.Expect.Plugins; plugins != nil && plugins.SSH != nil {
+		features = append(features, "ssh verification")
+	}
+	// the sftp command runs for the API which is not a plain FTP one, the templates might be rendered as sftp
+	if testcase.Request.FTP != nil && !strings.HasPrefix(testcase.Request.API, "ftp://") {
+		features = append(features, "sftp request")
+	}
+	if plugins := testcase.Expect.Plugins; len(testcase.Prepare.Files) > 0 || (plugins != nil && len(plugins.Files) > 0) {
+		features = append(features, "file step")
+	}
+	return
+}
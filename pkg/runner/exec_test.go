@@ -0,0 +1,96 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	fakeruntime "github.com/linuxsuren/go-fake-runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecTestCaseRunner(t *testing.T) {
+	tests := []struct {
+		name   string
+		exec   *atest.ExecRequest
+		expect atest.Response
+		execer fakeruntime.Execer
+		verify func(t *testing.T, output interface{}, err error)
+	}{{
+		name: "capture the stdout",
+		exec: &atest.ExecRequest{Command: "sh", Args: []string{"-c", "echo {{.name}}; echo warn >&2"}},
+		expect: atest.Response{
+			Body:   "hello",
+			Verify: []string{`data.stderr == "warn"`},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+			assert.Equal(t, map[string]interface{}{
+				"stdout":   "hello",
+				"stderr":   "warn",
+				"exitCode": float64(0),
+			}, output)
+		},
+	}, {
+		name: "expected exit code",
+		exec: &atest.ExecRequest{Command: "sh", Args: []string{"-c", "exit 3"}},
+		expect: atest.Response{
+			ExitCode:         3,
+			BodyFieldsExpect: map[string]interface{}{"exitCode": 3},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name: "unexpected exit code",
+		exec: &atest.ExecRequest{Command: "sh", Args: []string{"-c", "exit 1"}},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name:   "unexpected stdout",
+		exec:   &atest.ExecRequest{Command: "echo", Args: []string{"fake"}},
+		expect: atest.Response{Body: "hello"},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "run in the dir",
+		exec: &atest.ExecRequest{Command: "pwd", Dir: "/"},
+		expect: atest.Response{
+			BodyFieldsExpect: map[string]interface{}{"stdout": "/"},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name:   "cannot start the command",
+		exec:   &atest.ExecRequest{Command: "fake"},
+		execer: fakeruntime.FakeExecer{ExpectError: errors.New("not found")},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "no command",
+		exec: &atest.ExecRequest{},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testCase := &atest.TestCase{
+				Name:    tt.name,
+				Request: atest.Request{Exec: tt.exec},
+				Expect:  tt.expect,
+			}
+			execRunner := GetTestCaseRunner(testCase)
+			if tt.execer != nil {
+				execRunner.WithExecer(tt.execer)
+			}
+			output, err := execRunner.RunTestCase(testCase, map[string]string{"name": "hello"}, context.TODO())
+			tt.verify(t, output, err)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package runner
+
+import "github.com/linuxsuren/api-testing/pkg/testing"
+
+// GetTestCaseRunner returns the runner according to the kind of the test case request
+func GetTestCaseRunner(testcase *testing.TestCase) TestCaseRunner {
+	if testcase.Request.GRPC != nil {
+		return NewGRPCTestCaseRunner()
+	} else if testcase.Request.WebSocket != nil {
+		return NewWebSocketTestCaseRunner()
+	} else if testcase.Request.SSE != nil {
+		return NewSSETestCaseRunner()
+	} else if testcase.Request.MQTT != nil {
+		return NewMQTTTestCaseRunner()
+	} else if testcase.Request.AMQP != nil {
+		return NewAMQPTestCaseRunner()
+	} else if testcase.Request.NATS != nil {
+		return NewNATSTestCaseRunner()
+	} else if testcase.Request.TCP != nil {
+		return NewTCPTestCaseRunner()
+	} else if testcase.Request.UDP != nil {
+		return NewUDPTestCaseRunner()
+	} else if testcase.Request.OIDC != nil {
+		return NewOIDCTestCaseRunner()
+	} else if testcase.Request.FTP != nil {
+		return NewFTPTestCaseRunner()
+	} else if testcase.Request.Thrift != nil {
+		return NewThriftTestCaseRunner()
+	} else if testcase.Request.Exec != nil {
+		return NewExecTestCaseRunner()
+	} else if testcase.Request.Webhook != nil {
+		return NewWebhookTestCaseRunner()
+	}
+	return NewSimpleTestCaseRunner()
+}
@@ -0,0 +1,360 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jlaffaye/ftp"
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	fakeruntime "github.com/linuxsuren/go-fake-runtime"
+)
+
+const (
+	// defaultFTPTimeout is the timeout of connecting to the server if it's not set
+	defaultFTPTimeout = 10 * time.Second
+	// the actions of the file transfer
+	ftpActionUpload   = "upload"
+	ftpActionDownload = "download"
+	ftpActionList     = "list"
+)
+
+// fileEntry is a file or directory on the server
+type fileEntry struct {
+	Name string `json:"name"`
+	Size uint64 `json:"size"`
+	Dir  bool   `json:"dir"`
+}
+
+// fileTransferClient is the common operations of FTP and SFTP
+type fileTransferClient interface {
+	upload(path string, data []byte) error
+	download(path string) ([]byte, error)
+	list(path string) ([]fileEntry, error)
+	stat(path string) (fileEntry, error)
+	close() error
+}
+
+type ftpTestCaseRunner struct {
+	*simpleTestCaseRunner
+}
+
+// NewFTPTestCaseRunner creates the instance of the FTP and SFTP test case runner
+func NewFTPTestCaseRunner() TestCaseRunner {
+	runner := &ftpTestCaseRunner{simpleTestCaseRunner: &simpleTestCaseRunner{}}
+	return runner.embeddedBy(runner)
+}
+
+// RunTestCase uploads, downloads the file, or lists the directory, then verifies the result
+func (r *ftpTestCaseRunner) RunTestCase(testcase *testing.TestCase, dataContext interface{}, ctx context.Context) (output interface{}, err error) {
+	return r.runTestCaseWith(testcase, func(record *ReportRecord) (interface{}, error) {
+		return r.doFTPRequest(testcase, dataContext, ctx, record)
+	})
+}
+
+func (r *ftpTestCaseRunner) doFTPRequest(testcase *testing.TestCase, dataContext interface{}, ctx context.Context,
+	record *ReportRecord) (output interface{}, err error) {
+	if err = testcase.Request.Render(dataContext); err != nil {
+		return
+	}
+
+	ftpRequest := testcase.Request.FTP
+	var target *url.URL
+	if target, err = url.Parse(testcase.Request.API); err != nil {
+		return
+	}
+	record.Method = strings.ToUpper(target.Scheme)
+
+	timeout := defaultFTPTimeout
+	if ftpRequest.Timeout != "" {
+		if timeout, err = time.ParseDuration(ftpRequest.Timeout); err != nil {
+			return
+		}
+	}
+
+	var client fileTransferClient
+	if client, err = r.newFileTransferClient(ctx, target, ftpRequest, timeout); err != nil {
+		err = fmt.Errorf("case: %s, failed to connect to %s, %v", testcase.Name, target.Host, err)
+		return
+	}
+	defer func() {
+		_ = client.close()
+	}()
+
+	filePath := target.Path
+	r.log.Info("start to %s %s\n", ftpRequest.Action, filePath)
+	var result interface{}
+	switch ftpRequest.Action {
+	case ftpActionUpload:
+		var body []byte
+		if body, err = decodeTCPData(ftpRequest.Encoding, testcase.Request.Body); err != nil {
+			return
+		}
+		if err = client.upload(filePath, body); err == nil {
+			result, err = client.stat(filePath)
+		}
+	case ftpActionDownload:
+		var data []byte
+		if data, err = client.download(filePath); err != nil {
+			err = fmt.Errorf("case: %s, failed to download %s, %v", testcase.Name, filePath, err)
+			return
+		}
+		output, err = verifyRawData(testcase, ftpRequest.Encoding, data, record)
+		return
+	case ftpActionList:
+		result, err = client.list(filePath)
+	default:
+		err = fmt.Errorf("unsupported FTP action %q", ftpRequest.Action)
+	}
+	if err != nil {
+		err = fmt.Errorf("case: %s, failed to %s %s, %v", testcase.Name, ftpRequest.Action, filePath, err)
+		return
+	}
+
+	var data []byte
+	if data, err = json.Marshal(result); err != nil {
+		return
+	}
+	record.Body = string(data)
+	if output, err = verifyResponseBodyData(testcase.Name, testcase.Expect, data); err != nil {
+		return
+	}
+	err = jsonSchemaValidation(testcase.Expect.Schema, data)
+	return
+}
+
+// newFileTransferClient connects to the server according to the scheme of the API
+func (r *simpleTestCaseRunner) newFileTransferClient(ctx context.Context, target *url.URL, ftpRequest *testing.FTPRequest,
+	timeout time.Duration) (client fileTransferClient, err error) {
+	username := ftpRequest.Username
+	password := ftpRequest.Password
+	if username == "" && target.User != nil {
+		username = target.User.Username()
+		password, _ = target.User.Password()
+	}
+
+	switch target.Scheme {
+	case "ftp":
+		if username == "" {
+			username, password = "anonymous", "anonymous"
+		}
+
+		host := target.Host
+		if target.Port() == "" {
+			host = net.JoinHostPort(target.Hostname(), "21")
+		}
+
+		var conn *ftp.ServerConn
+		if conn, err = ftp.Dial(host, ftp.DialWithContext(ctx), ftp.DialWithTimeout(timeout)); err != nil {
+			return
+		}
+		if err = conn.Login(username, password); err != nil {
+			_ = conn.Quit()
+			return
+		}
+		client = &ftpClient{conn: conn}
+	case "sftp":
+		client = &sftpClient{
+			execer:       r.execer,
+			target:       target,
+			username:     username,
+			identityFile: ftpRequest.IdentityFile,
+			timeout:      timeout,
+		}
+	default:
+		err = fmt.Errorf("unsupported scheme %q, only ftp and sftp are supported", target.Scheme)
+	}
+	return
+}
+
+type ftpClient struct {
+	conn *ftp.ServerConn
+}
+
+func (c *ftpClient) upload(filePath string, data []byte) error {
+	return c.conn.Stor(filePath, bytes.NewReader(data))
+}
+
+func (c *ftpClient) download(filePath string) (data []byte, err error) {
+	var resp *ftp.Response
+	if resp, err = c.conn.Retr(filePath); err != nil {
+		return
+	}
+	defer resp.Close()
+	data, err = io.ReadAll(resp)
+	return
+}
+
+func (c *ftpClient) list(filePath string) (entries []fileEntry, err error) {
+	var items []*ftp.Entry
+	if items, err = c.conn.List(filePath); err != nil {
+		return
+	}
+
+	entries = make([]fileEntry, 0, len(items))
+	for _, item := range items {
+		if item.Name == "." || item.Name == ".." {
+			continue
+		}
+		entries = append(entries, fileEntry{
+			Name: path.Base(item.Name),
+			Size: item.Size,
+			Dir:  item.Type == ftp.EntryTypeFolder,
+		})
+	}
+	return
+}
+
+func (c *ftpClient) stat(filePath string) (entry fileEntry, err error) {
+	var size int64
+	if size, err = c.conn.FileSize(filePath); err == nil {
+		entry = fileEntry{Name: path.Base(filePath), Size: uint64(size)}
+	}
+	return
+}
+
+func (c *ftpClient) close() error {
+	return c.conn.Quit()
+}
+
+// sftpClient runs the sftp command in the batch mode, the key-based authentication is required
+type sftpClient struct {
+	execer       fakeruntime.Execer
+	target       *url.URL
+	username     string
+	identityFile string
+	timeout      time.Duration
+}
+
+func (c *sftpClient) upload(filePath string, data []byte) (err error) {
+	var localFile string
+	if localFile, err = writeTempFile(data); err != nil {
+		return
+	}
+	defer os.Remove(localFile)
+
+	_, err = c.run(fmt.Sprintf("put %q %q", localFile, filePath))
+	return
+}
+
+func (c *sftpClient) download(filePath string) (data []byte, err error) {
+	var localFile string
+	if localFile, err = writeTempFile(nil); err != nil {
+		return
+	}
+	defer os.Remove(localFile)
+
+	if _, err = c.run(fmt.Sprintf("get %q %q", filePath, localFile)); err == nil {
+		data, err = os.ReadFile(localFile)
+	}
+	return
+}
+
+func (c *sftpClient) list(filePath string) (entries []fileEntry, err error) {
+	var output string
+	if output, err = c.run(fmt.Sprintf("ls -la %q", filePath)); err == nil {
+		entries = parseSFTPList(output)
+	}
+	return
+}
+
+func (c *sftpClient) stat(filePath string) (entry fileEntry, err error) {
+	var entries []fileEntry
+	if entries, err = c.list(filePath); err != nil {
+		return
+	}
+	for _, item := range entries {
+		if item.Name == path.Base(filePath) {
+			entry = item
+			return
+		}
+	}
+	err = fmt.Errorf("not found %s", filePath)
+	return
+}
+
+func (c *sftpClient) close() error {
+	return nil
+}
+
+// run executes the command in a batch file, the sftp command fails if the command fails
+func (c *sftpClient) run(command string) (output string, err error) {
+	var batchFile string
+	if batchFile, err = writeTempFile([]byte(command + "\n")); err != nil {
+		return
+	}
+	defer os.Remove(batchFile)
+
+	args := []string{"-b", batchFile,
+		"-o", "BatchMode=yes",
+		"-o", fmt.Sprintf("ConnectTimeout=%d", int(c.timeout.Seconds()))}
+	if port := c.target.Port(); port != "" {
+		args = append(args, "-P", port)
+	}
+	if c.identityFile != "" {
+		args = append(args, "-i", c.identityFile)
+	}
+
+	// the host should not be an option of the sftp command, such as: -oProxyCommand=
+	host := c.target.Hostname()
+	if strings.HasPrefix(host, "-") || strings.HasPrefix(c.username, "-") {
+		err = fmt.Errorf("invalid host %q of the sftp", c.target.Host)
+		return
+	}
+	if c.username != "" {
+		host = c.username + "@" + host
+	}
+	if output, err = c.execer.RunCommandAndReturn("sftp", "", append(args, "--", host)...); err != nil {
+		err = fmt.Errorf("%v, %s", err, strings.TrimSpace(output))
+	}
+	return
+}
+
+// parseSFTPList parses the long listing format, the echoed commands and the invalid lines are ignored
+func parseSFTPList(output string) (entries []fileEntry) {
+	entries = []fileEntry{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 9 || strings.HasPrefix(line, "sftp>") {
+			continue
+		}
+
+		size, err := strconv.ParseUint(fields[4], 10, 64)
+		if err != nil {
+			continue
+		}
+		name := path.Base(strings.Join(fields[8:], " "))
+		if name == "." || name == ".." {
+			continue
+		}
+		entries = append(entries, fileEntry{
+			Name: name,
+			Size: size,
+			Dir:  strings.HasPrefix(fields[0], "d"),
+		})
+	}
+	return
+}
+
+func writeTempFile(data []byte) (name string, err error) {
+	var file *os.File
+	if file, err = os.CreateTemp("", "atest-ftp"); err != nil {
+		return
+	}
+	name = file.Name()
+	if _, err = file.Write(data); err != nil {
+		_ = file.Close()
+		return
+	}
+	err = file.Close()
+	return
+}
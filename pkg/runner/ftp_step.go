@@ -0,0 +1,110 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+const (
+	// the actions of the file steps
+	fileStepActionUpload = "upload"
+	fileStepActionCheck  = "check"
+)
+
+// runFileSteps runs the file steps in order, the action of a step is the default one if it's not set
+func (r *simpleTestCaseRunner) runFileSteps(steps []testing.FileStep, defaultAction string) (err error) {
+	for _, step := range steps {
+		action := step.Action
+		if action == "" {
+			action = defaultAction
+		}
+
+		r.log.Info("start to %s the file %s\n", action, step.URL)
+		if err = r.runFileStep(step, action); err != nil {
+			err = fmt.Errorf("failed to %s %s, %v", action, step.URL, err)
+			return
+		}
+	}
+	return
+}
+
+// runFileStep uploads the body, or downloads the file then compares it with the expectations
+func (r *simpleTestCaseRunner) runFileStep(step testing.FileStep, action string) (err error) {
+	if action != fileStepActionUpload && action != fileStepActionCheck {
+		err = fmt.Errorf("unsupported action %q, only upload and check are supported", action)
+		return
+	}
+
+	var target *url.URL
+	if target, err = url.Parse(step.URL); err != nil {
+		return
+	}
+
+	timeout := defaultFTPTimeout
+	if step.Timeout != "" {
+		if timeout, err = time.ParseDuration(step.Timeout); err != nil {
+			return
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var client fileTransferClient
+	if client, err = r.newFileTransferClient(ctx, target, &testing.FTPRequest{
+		Username:     step.Username,
+		Password:     step.Password,
+		IdentityFile: step.IdentityFile,
+	}, timeout); err != nil {
+		return
+	}
+	defer func() {
+		_ = client.close()
+	}()
+
+	switch action {
+	case fileStepActionUpload:
+		data := []byte(step.Body)
+		if step.BodyFromFile != "" {
+			if data, err = os.ReadFile(step.BodyFromFile); err != nil {
+				return
+			}
+		}
+		err = client.upload(target.Path, data)
+	default:
+		err = checkFile(client, target.Path, step)
+	}
+	return
+}
+
+// checkFile compares the existence, the size and the content of the file
+func checkFile(client fileTransferClient, filePath string, step testing.FileStep) (err error) {
+	var data []byte
+	data, err = client.download(filePath)
+	if step.Absent {
+		if err == nil {
+			err = fmt.Errorf("expect %s does not exist, but it does", filePath)
+		} else {
+			err = nil
+		}
+		return
+	} else if err != nil {
+		return
+	}
+
+	switch {
+	case step.Size != nil && *step.Size != len(data):
+		err = fmt.Errorf("expect the size %d, but got %d", *step.Size, len(data))
+	case step.Body != "" && step.Body != string(data):
+		err = fmt.Errorf("expect the content %q, but got %q", step.Body, string(data))
+	case step.Contains != "" && !bytes.Contains(data, []byte(step.Contains)):
+		err = fmt.Errorf("expect the content contains %q, but got %q", step.Contains, strings.TrimSpace(string(data)))
+	}
+	return
+}
@@ -0,0 +1,106 @@
+package runner_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/h2non/gock"
+	"github.com/linuxsuren/api-testing/pkg/runner"
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSteps(t *testing.T) {
+	server := &fakeFTPServer{files: map[string]string{"/upload/report.csv": "id,name\n1,linuxsuren\n"}}
+	address, err := server.start()
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer server.listener.Close()
+	base := "ftp://linuxsuren:secret@" + address
+
+	localFile := filepath.Join(t.TempDir(), "order.json")
+	assert.Nil(t, os.WriteFile(localFile, []byte(`{"id": 1}`), 0644))
+
+	size := 21
+	wrongSize := 1
+	tests := []struct {
+		name      string
+		prepare   []atest.FileStep
+		verify    []atest.FileStep
+		expectErr string
+	}{{
+		name: "check the files",
+		verify: []atest.FileStep{
+			{URL: base + "/upload/report.csv", Size: &size, Contains: "linuxsuren"},
+			{URL: base + "/upload/report.csv", Body: "id,name\n1,linuxsuren\n"},
+			{URL: base + "/upload/fake.csv", Absent: true},
+		},
+	}, {
+		name: "upload then check the files",
+		prepare: []atest.FileStep{
+			{URL: base + "/upload/users.csv", Body: "id\n"},
+			{URL: base + "/upload/order.json", BodyFromFile: localFile},
+			{URL: base + "/upload/order.json", Action: "check", Contains: `"id"`},
+		},
+		verify: []atest.FileStep{
+			{URL: base + "/upload/users.csv", Body: "id\n"},
+			{URL: base + "/upload/order.json", Body: `{"id": 1}`},
+		},
+	}, {
+		name:      "unexpected size",
+		verify:    []atest.FileStep{{URL: base + "/upload/report.csv", Size: &wrongSize}},
+		expectErr: "expect the size 1, but got 21",
+	}, {
+		name:      "unexpected content",
+		verify:    []atest.FileStep{{URL: base + "/upload/report.csv", Contains: "rick"}},
+		expectErr: `expect the content contains "rick"`,
+	}, {
+		name:      "the file is not absent",
+		verify:    []atest.FileStep{{URL: base + "/upload/report.csv", Absent: true}},
+		expectErr: "does not exist, but it does",
+	}, {
+		name:      "the file does not exist",
+		verify:    []atest.FileStep{{URL: base + "/upload/fake.csv"}},
+		expectErr: "failed to verify the files, failed to check",
+	}, {
+		name:      "not found the local file",
+		prepare:   []atest.FileStep{{URL: base + "/upload/fake.csv", BodyFromFile: "fake"}},
+		expectErr: "failed to prepare the files, error: failed to upload",
+	}, {
+		name:      "unsupported action",
+		verify:    []atest.FileStep{{URL: base + "/upload/report.csv", Action: "delete"}},
+		expectErr: `unsupported action "delete"`,
+	}, {
+		name:      "unsupported scheme",
+		verify:    []atest.FileStep{{URL: "http://" + address + "/upload/report.csv"}},
+		expectErr: `unsupported scheme "http"`,
+	}, {
+		name:      "invalid timeout",
+		verify:    []atest.FileStep{{URL: base + "/upload/report.csv", Timeout: "fake"}},
+		expectErr: "invalid duration",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer gock.Clean()
+			gock.New("http://localhost").Get("/orders").Reply(http.StatusOK).JSON([]string{})
+
+			_, err := runner.NewSimpleTestCaseRunner().RunTestCase(&atest.TestCase{
+				Prepare: atest.Prepare{Files: tt.prepare},
+				Request: atest.Request{API: "http://localhost/orders"},
+				Expect: atest.Response{
+					StatusCode: http.StatusOK,
+					Plugins:    &atest.VerifyPlugins{Files: tt.verify},
+				},
+			}, nil, context.TODO())
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expectErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,365 @@
+package runner_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/linuxsuren/api-testing/pkg/runner"
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	fakeruntime "github.com/linuxsuren/go-fake-runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFTPTestCaseRunner(t *testing.T) {
+	server := &fakeFTPServer{files: map[string]string{"/upload/report.csv": "id,name\n1,linuxsuren\n"}}
+	api, err := server.start()
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer server.listener.Close()
+
+	tests := []struct {
+		name     string
+		testCase *atest.TestCase
+		verify   func(t *testing.T, output interface{}, err error)
+	}{{
+		name: "list",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: "ftp://" + api + "/upload",
+				FTP: &atest.FTPRequest{Action: "list", Username: "{{.username}}", Password: "secret"},
+			},
+			Expect: atest.Response{
+				Verify: []string{
+					`any(data, {.name == "report.csv" && .size == 21})`,
+					`len(data) == 1`,
+				},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+			assert.Equal(t, []interface{}{map[string]interface{}{
+				"name": "report.csv", "size": float64(21), "dir": false,
+			}}, output)
+		},
+	}, {
+		name: "upload",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  "ftp://linuxsuren:secret@" + api + "/upload/order.json",
+				Body: `{"id": 1}` + "\n",
+				FTP:  &atest.FTPRequest{Action: "upload"},
+			},
+			Expect: atest.Response{
+				BodyFieldsExpect: map[string]interface{}{"size": 10},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+			assert.Equal(t, `{"id": 1}`+"\n", server.file("/upload/order.json"))
+		},
+	}, {
+		name: "download",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: "ftp://" + api + "/upload/report.csv",
+				FTP: &atest.FTPRequest{Action: "download", Username: "linuxsuren", Password: "secret"},
+			},
+			Expect: atest.Response{
+				Body:   "id,name\n1,linuxsuren",
+				Verify: []string{`data.length == 21`},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name: "download the missing file",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: "ftp://" + api + "/upload/missing.csv",
+				FTP: &atest.FTPRequest{Action: "download", Username: "linuxsuren", Password: "secret"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), "failed to download /upload/missing.csv")
+			}
+		},
+	}, {
+		name: "wrong password",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: "ftp://" + api + "/upload",
+				FTP: &atest.FTPRequest{Action: "list", Username: "linuxsuren", Password: "wrong"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), "failed to connect to")
+			}
+		},
+	}, {
+		name: "unsupported action",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: "ftp://" + api + "/upload",
+				FTP: &atest.FTPRequest{Action: "delete", Username: "linuxsuren", Password: "secret"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "unsupported scheme",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: "http://" + api + "/upload",
+				FTP: &atest.FTPRequest{Action: "list"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "invalid timeout",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: "ftp://" + api + "/upload",
+				FTP: &atest.FTPRequest{Action: "list", Timeout: "fake"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ftpRunner := runner.GetTestCaseRunner(tt.testCase)
+			output, err := ftpRunner.RunTestCase(tt.testCase, map[string]string{"username": "linuxsuren"}, context.TODO())
+			tt.verify(t, output, err)
+		})
+	}
+}
+
+func TestSFTPTestCaseRunner(t *testing.T) {
+	listing := `sftp> ls -la "/upload"
+drwxr-xr-x    2 linuxsuren linuxsuren     4096 Jan  1 00:00 .
+drwxr-xr-x    5 root       root           4096 Jan  1 00:00 ..
+-rw-r--r--    1 linuxsuren linuxsuren       21 Jan  1 00:00 /upload/report.csv
+drwxr-xr-x    2 linuxsuren linuxsuren     4096 Jan  1 00:00 /upload/archive
+`
+	tests := []struct {
+		name     string
+		testCase *atest.TestCase
+		execer   fakeruntime.Execer
+		verify   func(t *testing.T, output interface{}, err error)
+	}{{
+		name: "list",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: "sftp://linuxsuren@localhost:2222/upload",
+				FTP: &atest.FTPRequest{Action: "list", IdentityFile: "/root/.ssh/id_rsa"},
+			},
+			Expect: atest.Response{
+				Verify: []string{`any(data, {.name == "report.csv" && .size == 21})`},
+			},
+		},
+		execer: fakeruntime.FakeExecer{ExpectOutput: listing},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+			assert.Equal(t, []interface{}{map[string]interface{}{
+				"name": "report.csv", "size": float64(21), "dir": false,
+			}, map[string]interface{}{
+				"name": "archive", "size": float64(4096), "dir": true,
+			}}, output)
+		},
+	}, {
+		name: "upload",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  "sftp://localhost/upload/report.csv",
+				Body: "id,name\n1,linuxsuren\n",
+				FTP:  &atest.FTPRequest{Action: "upload"},
+			},
+			Expect: atest.Response{
+				BodyFieldsExpect: map[string]interface{}{"name": "report.csv", "size": 21},
+			},
+		},
+		execer: fakeruntime.FakeExecer{ExpectOutput: listing},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name: "the uploaded file is not found",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: "sftp://localhost/upload/order.json",
+				FTP: &atest.FTPRequest{Action: "upload"},
+			},
+		},
+		execer: fakeruntime.FakeExecer{ExpectOutput: listing},
+		verify: func(t *testing.T, output interface{}, err error) {
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), "not found /upload/order.json")
+			}
+		},
+	}, {
+		name: "failed to run sftp",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: "sftp://localhost/upload",
+				FTP: &atest.FTPRequest{Action: "list"},
+			},
+		},
+		execer: fakeruntime.FakeExecer{ExpectError: errors.New("exit status 255"), ExpectErrOutput: "Permission denied"},
+		verify: func(t *testing.T, output interface{}, err error) {
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), "Permission denied")
+			}
+		},
+	}, {
+		name: "the host is an option of sftp",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: "sftp://-oProxyCommand=touch/upload",
+				FTP: &atest.FTPRequest{Action: "list"},
+			},
+		},
+		execer: fakeruntime.FakeExecer{ExpectOutput: listing},
+		verify: func(t *testing.T, output interface{}, err error) {
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), "invalid host")
+			}
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sftpRunner := runner.GetTestCaseRunner(tt.testCase).WithExecer(tt.execer)
+			output, err := sftpRunner.RunTestCase(tt.testCase, nil, context.TODO())
+			tt.verify(t, output, err)
+		})
+	}
+}
+
+// fakeFTPServer implements the commands which are used by the FTP client in the passive mode
+type fakeFTPServer struct {
+	listener net.Listener
+	lock     sync.Mutex
+	files    map[string]string
+}
+
+func (s *fakeFTPServer) start() (addr string, err error) {
+	if s.listener, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+		return
+	}
+	go func() {
+		for {
+			conn, err := s.listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	addr = s.listener.Addr().String()
+	return
+}
+
+func (s *fakeFTPServer) file(name string) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.files[name]
+}
+
+func (s *fakeFTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	reply := func(format string, args ...interface{}) {
+		_, _ = fmt.Fprintf(conn, format+"\r\n", args...)
+	}
+	reply("220 ready")
+
+	var dataListener net.Listener
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+
+		switch command {
+		case "USER":
+			reply("331 password required")
+		case "PASS":
+			if arg == "secret" {
+				reply("230 logged in")
+			} else {
+				reply("530 login incorrect")
+			}
+		case "TYPE":
+			reply("200 type set")
+		case "EPSV":
+			if dataListener, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+				reply("425 cannot open the data connection")
+				continue
+			}
+			reply("229 entering extended passive mode (|||%d|)", dataListener.Addr().(*net.TCPAddr).Port)
+		case "SIZE":
+			if content := s.file(arg); content != "" {
+				reply("213 %d", len(content))
+			} else {
+				reply("550 not found")
+			}
+		case "LIST", "RETR", "STOR":
+			s.transfer(dataListener, command, arg, reply)
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func (s *fakeFTPServer) transfer(dataListener net.Listener, command, arg string, reply func(string, ...interface{})) {
+	defer dataListener.Close()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	content, ok := s.files[arg]
+	if command == "RETR" && !ok {
+		reply("550 not found")
+		return
+	}
+
+	reply("150 opening the data connection")
+	dataConn, err := dataListener.Accept()
+	if err != nil {
+		reply("425 cannot open the data connection")
+		return
+	}
+	switch command {
+	case "LIST":
+		for name, content := range s.files {
+			if strings.HasPrefix(name, arg+"/") {
+				_, _ = fmt.Fprintf(dataConn, "-rw-r--r-- 1 linuxsuren linuxsuren %d Jan 01 00:00 %s\r\n",
+					len(content), strings.TrimPrefix(name, arg+"/"))
+			}
+		}
+	case "RETR":
+		_, _ = io.WriteString(dataConn, content)
+	case "STOR":
+		data, _ := io.ReadAll(dataConn)
+		s.files[arg] = string(data)
+	}
+	_ = dataConn.Close()
+	reply("226 transfer complete")
+}
@@ -0,0 +1,44 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// VerifyGolden compares the output of the test case with the golden file, the fields which match the ignore
+// rules are skipped, such as: items/*/updatedAt. The golden file is written if it does not exist or the update is true
+func VerifyGolden(caseName, file string, ignores []string, output interface{}, update bool) (err error) {
+	var actual interface{}
+	var data []byte
+	if data, err = json.MarshalIndent(output, "", "  "); err != nil {
+		return
+	}
+	// the output is compared as it's decoded from the file
+	if err = json.Unmarshal(data, &actual); err != nil {
+		return
+	}
+
+	var golden []byte
+	if golden, err = os.ReadFile(file); os.IsNotExist(err) || update {
+		if err = os.MkdirAll(filepath.Dir(file), 0755); err == nil {
+			err = os.WriteFile(file, append(data, '\n'), 0644)
+		}
+		return
+	} else if err != nil {
+		return
+	}
+
+	var expect interface{}
+	if err = json.Unmarshal(golden, &expect); err != nil {
+		err = fmt.Errorf("case: %s, invalid golden file %s, %v", caseName, file, err)
+		return
+	}
+	if differences := (valueDiffer{left: "golden", right: "output", ignores: ignores}).diff("", expect, actual); len(differences) > 0 {
+		err = fmt.Errorf("case: %s, the output is different from the golden file %s, update it by --update-snapshots if it's expected:\n  %s",
+			caseName, file, strings.Join(differences, "\n  "))
+	}
+	return
+}
@@ -0,0 +1,48 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyGolden(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "golden", "users.json")
+	output := map[string]interface{}{
+		"items": []interface{}{map[string]interface{}{"id": 1, "name": "rick", "updatedAt": "2024-01-01"}},
+	}
+
+	// recorded by the first run
+	assert.Nil(t, VerifyGolden("users", file, nil, output, false))
+	data, err := os.ReadFile(file)
+	assert.Nil(t, err)
+	assert.Equal(t, `{
+  "items": [
+    {
+      "id": 1,
+      "name": "rick",
+      "updatedAt": "2024-01-01"
+    }
+  ]
+}
+`, string(data))
+	assert.Nil(t, VerifyGolden("users", file, nil, output, false))
+
+	changed := map[string]interface{}{
+		"items": []interface{}{map[string]interface{}{"id": 1, "name": "morty", "updatedAt": "2024-01-02", "role": "admin"}},
+	}
+	err = VerifyGolden("users", file, []string{"items/*/updatedAt"}, changed, false)
+	assert.EqualError(t, err, `case: users, the output is different from the golden file `+file+`, update it by --update-snapshots if it's expected:
+  items/0/name: "rick" != "morty"
+  items/0/role: missing in the golden`)
+
+	// update the golden file
+	assert.Nil(t, VerifyGolden("users", file, nil, changed, true))
+	assert.Nil(t, VerifyGolden("users", file, nil, changed, false))
+
+	assert.Nil(t, os.WriteFile(file, []byte("fake"), 0644))
+	err = VerifyGolden("users", file, nil, changed, false)
+	assert.Contains(t, err.Error(), "case: users, invalid golden file")
+}
@@ -0,0 +1,58 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// verifyGraphQLResponse verifies the errors of the GraphQL response, then returns the data part.
+// It fails if there are any errors which are not expected, or any expected ones are missing
+func verifyGraphQLResponse(caseName string, expect testing.Response, body []byte) (data []byte, err error) {
+	response := &graphQLResponse{}
+	if err = json.Unmarshal(body, response); err != nil {
+		err = fmt.Errorf("case: %s, not a valid GraphQL response, %v", caseName, err)
+		return
+	}
+
+	var messages []string
+	for _, item := range response.Errors {
+		messages = append(messages, item.Message)
+	}
+
+	if len(expect.GraphQLErrors) == 0 && len(messages) > 0 {
+		err = fmt.Errorf("case: %s, got GraphQL errors: %s", caseName, strings.Join(messages, "; "))
+		return
+	}
+
+	for _, expectErr := range expect.GraphQLErrors {
+		found := false
+		for _, message := range messages {
+			if strings.Contains(message, expectErr) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			err = fmt.Errorf("case: %s, expect GraphQL error: %s, actual: [%s]", caseName,
+				expectErr, strings.Join(messages, "; "))
+			return
+		}
+	}
+
+	data = response.Data
+	if len(data) == 0 {
+		data = []byte("null")
+	}
+	return
+}
@@ -0,0 +1,48 @@
+package runner
+
+import (
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyGraphQLResponse(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect atest.Response
+		body   string
+		data   string
+		hasErr bool
+	}{{
+		name: "only data",
+		body: `{"data":{"users":[]}}`,
+		data: `{"users":[]}`,
+	}, {
+		name:   "unexpected errors",
+		body:   `{"data":null,"errors":[{"message":"unauthorized"}]}`,
+		hasErr: true,
+	}, {
+		name:   "expected errors",
+		expect: atest.Response{GraphQLErrors: []string{"unauthorized"}},
+		body:   `{"errors":[{"message":"request is unauthorized"}]}`,
+		data:   "null",
+	}, {
+		name:   "missing the expected errors",
+		expect: atest.Response{GraphQLErrors: []string{"unauthorized"}},
+		body:   `{"data":{"users":[]}}`,
+		hasErr: true,
+	}, {
+		name:   "not a JSON",
+		body:   "fake",
+		hasErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := verifyGraphQLResponse("test", tt.expect, []byte(tt.body))
+			if assert.Equal(t, tt.hasErr, err != nil, err) && !tt.hasErr {
+				assert.JSONEq(t, tt.data, string(data))
+			}
+		})
+	}
+}
@@ -0,0 +1,519 @@
+package runner
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	fakeruntime "github.com/linuxsuren/go-fake-runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+type grpcTestCaseRunner struct {
+	*simpleTestCaseRunner
+}
+
+// NewGRPCTestCaseRunner creates the instance of the gRPC test case runner
+func NewGRPCTestCaseRunner() TestCaseRunner {
+	runner := &grpcTestCaseRunner{simpleTestCaseRunner: &simpleTestCaseRunner{}}
+	return runner.embeddedBy(runner)
+}
+
+// RunTestCase calls the gRPC method, then verifies the response message
+func (r *grpcTestCaseRunner) RunTestCase(testcase *testing.TestCase, dataContext interface{}, ctx context.Context) (output interface{}, err error) {
+	return r.runTestCaseWith(testcase, func(record *ReportRecord) (interface{}, error) {
+		return r.doGRPCRequest(testcase, dataContext, ctx, record)
+	})
+}
+
+// grpcOptions returns the dial options and the per-call deadline of the gRPC options in the transport
+func grpcOptions(transport *testing.Transport) (dialOptions []grpc.DialOption, callTimeout time.Duration, err error) {
+	if transport == nil || transport.GRPC == nil {
+		return
+	}
+	options := transport.GRPC
+
+	if options.KeepaliveTime != "" || options.KeepaliveTimeout != "" {
+		params := keepalive.ClientParameters{PermitWithoutStream: true}
+		if options.KeepaliveTime != "" {
+			if params.Time, err = time.ParseDuration(options.KeepaliveTime); err != nil {
+				return
+			}
+		}
+		if options.KeepaliveTimeout != "" {
+			if params.Timeout, err = time.ParseDuration(options.KeepaliveTimeout); err != nil {
+				return
+			}
+		}
+		dialOptions = append(dialOptions, grpc.WithKeepaliveParams(params))
+	}
+
+	var callOptions []grpc.CallOption
+	if options.MaxRecvMsgSize > 0 {
+		callOptions = append(callOptions, grpc.MaxCallRecvMsgSize(options.MaxRecvMsgSize))
+	}
+	if options.MaxSendMsgSize > 0 {
+		callOptions = append(callOptions, grpc.MaxCallSendMsgSize(options.MaxSendMsgSize))
+	}
+	if len(callOptions) > 0 {
+		dialOptions = append(dialOptions, grpc.WithDefaultCallOptions(callOptions...))
+	}
+
+	if options.CallTimeout != "" {
+		callTimeout, err = time.ParseDuration(options.CallTimeout)
+	}
+	return
+}
+
+func (r *grpcTestCaseRunner) doGRPCRequest(testcase *testing.TestCase, dataContext interface{}, ctx context.Context,
+	record *ReportRecord) (output interface{}, err error) {
+	if err = testcase.Request.Render(dataContext); err != nil {
+		return
+	}
+
+	grpcRequest := testcase.Request.GRPC
+	record.Method = "GRPC"
+	record.API = fmt.Sprintf("%s/%s/%s", testcase.Request.API, grpcRequest.Service, grpcRequest.Method)
+
+	var dialOptions []grpc.DialOption
+	var callTimeout time.Duration
+	if dialOptions, callTimeout, err = grpcOptions(testcase.Request.Transport); err != nil {
+		err = fmt.Errorf("case: %s, invalid gRPC options, %v", testcase.Name, err)
+		return
+	}
+
+	// there's no connection of gRPC-Web, the calls are HTTP requests
+	var conn *grpc.ClientConn
+	if !grpcRequest.Web {
+		creds := insecure.NewCredentials()
+		if grpcRequest.TLS {
+			creds = credentials.NewTLS(&tls.Config{InsecureSkipVerify: true})
+		}
+		if transport := testcase.Request.Transport; transport != nil && transport.SPIFFE != nil {
+			var tlsConfig *tls.Config
+			if tlsConfig, err = newSPIFFETLSConfig(ctx, transport.SPIFFE); err != nil {
+				return
+			}
+			creds = credentials.NewTLS(tlsConfig)
+		}
+
+		dialOptions = append(dialOptions, grpc.WithTransportCredentials(creds))
+		if conn, err = grpc.DialContext(ctx, testcase.Request.API, dialOptions...); err != nil {
+			return
+		}
+		defer conn.Close()
+	}
+
+	var method protoreflect.MethodDescriptor
+	if method, err = r.getMethodDescriptor(ctx, conn, grpcRequest); err != nil {
+		return
+	}
+	if grpcRequest.Web && method.IsStreamingClient() {
+		err = fmt.Errorf("case: %s, the client streaming method %s is not supported by gRPC-Web", testcase.Name, method.Name())
+		return
+	}
+
+	var requests []proto.Message
+	if len(grpcRequest.Messages) == 0 || !method.IsStreamingClient() {
+		var request proto.Message
+		if request, err = newGRPCMessage(method.Input(), testcase.Request.Body); err != nil {
+			return
+		}
+		requests = append(requests, request)
+	}
+	if method.IsStreamingClient() {
+		for _, message := range grpcRequest.Messages {
+			var request proto.Message
+			if request, err = newGRPCMessage(method.Input(), message); err != nil {
+				return
+			}
+			requests = append(requests, request)
+		}
+	}
+
+	if len(testcase.Request.Header) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(testcase.Request.Header))
+	}
+
+	// the deadline is not for resolving the method descriptor
+	if callTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, callTimeout)
+		defer cancel()
+	}
+
+	r.log.Info("start to call %s\n", record.API)
+	fullMethod := fmt.Sprintf("/%s/%s", grpcRequest.Service, grpcRequest.Method)
+	var responses []protoreflect.Message
+	var callErr error
+	switch {
+	case grpcRequest.Web:
+		responses, callErr = callGRPCWeb(ctx, &testcase.Request, method, fullMethod, requests[0])
+	case method.IsStreamingClient() || method.IsStreamingServer():
+		responses, callErr = callGRPCStream(ctx, conn, method, fullMethod, requests)
+	default:
+		response := dynamicpb.NewMessage(method.Output())
+		callErr = conn.Invoke(ctx, fullMethod, requests[0], response)
+		responses = append(responses, response)
+	}
+
+	expectStatus := testcase.Expect.GRPCStatus
+	if expectStatus == "" {
+		expectStatus = "OK"
+	}
+	callStatus := status.Convert(callErr)
+	if callStatus.Code().String() != expectStatus {
+		err = fmt.Errorf("case: %s, expect gRPC status %s, actual %s, message: %s", testcase.Name,
+			expectStatus, callStatus.Code().String(), callStatus.Message())
+		return
+	} else if callErr != nil {
+		// the error is expected, there is no response message to verify
+		return
+	}
+
+	// the descriptor from the server reflection is the schema of the response
+	if grpcRequest.ProtoFile == "" {
+		for _, response := range responses {
+			if err = r.verifyResponseSchema(testcase, response); err != nil {
+				return
+			}
+		}
+	}
+
+	messages := make([]json.RawMessage, len(responses))
+	for i, response := range responses {
+		if messages[i], err = (protojson.MarshalOptions{EmitUnpopulated: true}).Marshal(response.Interface()); err != nil {
+			return
+		}
+	}
+
+	var responseBodyData []byte
+	if method.IsStreamingServer() {
+		// the received messages are verified as an array
+		if err = verifyGRPCMessages(testcase, messages); err != nil {
+			return
+		}
+		if responseBodyData, err = json.Marshal(messages); err != nil {
+			return
+		}
+	} else {
+		responseBodyData = messages[0]
+	}
+	record.Body = string(responseBodyData)
+	r.log.Debug("response message: %s\n", record.Body)
+
+	if output, err = verifyResponseBodyData(testcase.Name, testcase.Expect, responseBodyData); err != nil {
+		return
+	}
+	err = jsonSchemaValidation(testcase.Expect.Schema, responseBodyData)
+	return
+}
+
+// newGRPCMessage parses the message which could be written in YAML or JSON
+func newGRPCMessage(desc protoreflect.MessageDescriptor, body string) (message proto.Message, err error) {
+	message = dynamicpb.NewMessage(desc)
+	if body = strings.TrimSpace(body); body != "" {
+		var data []byte
+		if data, err = yaml.YAMLToJSON([]byte(body)); err != nil {
+			return
+		}
+		if err = protojson.Unmarshal(data, message); err != nil {
+			err = fmt.Errorf("failed to parse the request message, %v", err)
+		}
+	}
+	return
+}
+
+// callGRPCStream sends the messages in order and closes the sending side, then receives the messages
+// until the server finishes the stream
+func callGRPCStream(ctx context.Context, conn *grpc.ClientConn, method protoreflect.MethodDescriptor,
+	fullMethod string, requests []proto.Message) (responses []protoreflect.Message, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var stream grpc.ClientStream
+	if stream, err = conn.NewStream(ctx, &grpc.StreamDesc{
+		StreamName:    string(method.Name()),
+		ServerStreams: method.IsStreamingServer(),
+		ClientStreams: method.IsStreamingClient(),
+	}, fullMethod); err != nil {
+		return
+	}
+
+	for _, request := range requests {
+		// io.EOF means the stream is finished by the server, the status comes from receiving
+		if err = stream.SendMsg(request); err == io.EOF {
+			break
+		} else if err != nil {
+			return
+		}
+	}
+	if err = stream.CloseSend(); err != nil {
+		return
+	}
+
+	for {
+		response := dynamicpb.NewMessage(method.Output())
+		if err = stream.RecvMsg(response); err == io.EOF {
+			err = nil
+			return
+		} else if err != nil {
+			return
+		}
+		responses = append(responses, response)
+	}
+}
+
+// verifyGRPCMessages compares the count of the received messages, then matches the expected fields of each message in order
+func verifyGRPCMessages(testcase *testing.TestCase, messages []json.RawMessage) (err error) {
+	if len(testcase.Expect.Messages) == 0 {
+		return
+	}
+	if len(messages) != len(testcase.Expect.Messages) {
+		err = fmt.Errorf("case: %s, expect %d messages, received %d", testcase.Name,
+			len(testcase.Expect.Messages), len(messages))
+		return
+	}
+
+	for i, expect := range testcase.Expect.Messages {
+		var data []byte
+		if data, err = yaml.YAMLToJSON([]byte(expect)); err != nil {
+			return
+		}
+		var expectFields, actualFields interface{}
+		if err = json.Unmarshal(data, &expectFields); err != nil {
+			return
+		}
+		if err = json.Unmarshal(messages[i], &actualFields); err != nil {
+			return
+		}
+		if err = matchFields(fmt.Sprintf("[%d]", i), expectFields, actualFields); err != nil {
+			err = fmt.Errorf("case: %s, unexpected message %s", testcase.Name, err)
+			return
+		}
+	}
+	return
+}
+
+// matchFields checks the expected fields recursively, the fields which are not expected are ignored.
+// The scalar values are compared as strings, because the 64-bit integers are strings in the JSON of protobuf
+func matchFields(path string, expect, actual interface{}) (err error) {
+	switch expectVal := expect.(type) {
+	case map[string]interface{}:
+		actualVal, ok := actual.(map[string]interface{})
+		if !ok {
+			err = fmt.Errorf("%s: expect an object, actual %v", path, actual)
+			return
+		}
+		for key, val := range expectVal {
+			if err = matchFields(path+"."+key, val, actualVal[key]); err != nil {
+				return
+			}
+		}
+	case []interface{}:
+		actualVal, ok := actual.([]interface{})
+		if !ok || len(actualVal) != len(expectVal) {
+			err = fmt.Errorf("%s: expect %v, actual %v", path, expect, actual)
+			return
+		}
+		for i, val := range expectVal {
+			if err = matchFields(fmt.Sprintf("%s[%d]", path, i), val, actualVal[i]); err != nil {
+				return
+			}
+		}
+	default:
+		if fmt.Sprint(expect) != fmt.Sprint(actual) {
+			err = fmt.Errorf("%s: expect %v, actual %v", path, expect, actual)
+		}
+	}
+	return
+}
+
+// verifyResponseSchema reports the anomalies of the response message against its descriptor,
+// the fields in the default value are only allowed in the non-strict mode
+func (r *grpcTestCaseRunner) verifyResponseSchema(testcase *testing.TestCase, response protoreflect.Message) (err error) {
+	violations, defaults := schemaAnomalies(response)
+	if len(defaults) > 0 {
+		r.log.Info("the fields are in the default value: %s\n", strings.Join(defaults, ", "))
+	}
+
+	if len(violations) > 0 {
+		err = fmt.Errorf("case: %s, the response doesn't match the descriptor: %s", testcase.Name,
+			strings.Join(violations, ", "))
+	} else if testcase.Expect.GRPCStrict && len(defaults) > 0 {
+		err = fmt.Errorf("case: %s, the fields are in the default value: %s", testcase.Name,
+			strings.Join(defaults, ", "))
+	}
+	return
+}
+
+// getMethodDescriptor finds the method from the proto file, or the server reflection
+func (r *grpcTestCaseRunner) getMethodDescriptor(ctx context.Context, conn *grpc.ClientConn,
+	grpcRequest *testing.GRPCRequest) (method protoreflect.MethodDescriptor, err error) {
+	var files *protoregistry.Files
+	if grpcRequest.ProtoFile != "" {
+		files, err = r.getFilesFromProto(grpcRequest)
+	} else if conn == nil {
+		err = fmt.Errorf("the proto file is required by gRPC-Web, the server reflection is not supported")
+	} else {
+		files, err = getFilesFromReflection(ctx, conn, grpcRequest.Service)
+	}
+	if err != nil {
+		return
+	}
+
+	var desc protoreflect.Descriptor
+	if desc, err = files.FindDescriptorByName(protoreflect.FullName(grpcRequest.Service)); err != nil {
+		err = fmt.Errorf("cannot find service %s, %v", grpcRequest.Service, err)
+		return
+	}
+
+	service, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		err = fmt.Errorf("%s is not a service", grpcRequest.Service)
+		return
+	}
+
+	if method = service.Methods().ByName(protoreflect.Name(grpcRequest.Method)); method == nil {
+		err = fmt.Errorf("cannot find method %s in service %s", grpcRequest.Method, grpcRequest.Service)
+	}
+	return
+}
+
+// getFilesFromProto compiles the proto file to be a descriptor set with protoc
+func (r *grpcTestCaseRunner) getFilesFromProto(grpcRequest *testing.GRPCRequest) (files *protoregistry.Files, err error) {
+	return compileProtoFile(r.execer, grpcRequest.ProtoFile, grpcRequest.ImportPaths)
+}
+
+// compileProtoFile compiles the proto file and its imports to be a descriptor set with protoc
+func compileProtoFile(execer fakeruntime.Execer, protoFile string, importPaths []string) (files *protoregistry.Files, err error) {
+	var descFile *os.File
+	if descFile, err = os.CreateTemp("", "atest-*.pb"); err != nil {
+		return
+	}
+	_ = descFile.Close()
+	defer os.Remove(descFile.Name())
+
+	args := []string{"--include_imports", "--descriptor_set_out=" + descFile.Name(),
+		"-I" + filepath.Dir(protoFile)}
+	for _, importPath := range importPaths {
+		args = append(args, "-I"+importPath)
+	}
+	args = append(args, protoFile)
+
+	var output string
+	if output, err = execer.RunCommandAndReturn("protoc", "", args...); err != nil {
+		err = fmt.Errorf("failed to compile %s, %v, %s", protoFile, err, output)
+		return
+	}
+	files, err = loadDescriptorSet(descFile.Name())
+	return
+}
+
+// loadDescriptorSet reads the file descriptors from the serialized FileDescriptorSet
+func loadDescriptorSet(file string) (files *protoregistry.Files, err error) {
+	var data []byte
+	if data, err = os.ReadFile(file); err != nil {
+		return
+	}
+
+	descSet := &descriptorpb.FileDescriptorSet{}
+	if err = proto.Unmarshal(data, descSet); err == nil {
+		files, err = protodesc.NewFiles(descSet)
+	}
+	return
+}
+
+// getFilesFromReflection gets the file descriptors of the service and its dependencies from the server reflection
+func getFilesFromReflection(ctx context.Context, conn *grpc.ClientConn, service string) (files *protoregistry.Files, err error) {
+	var stream rpb.ServerReflection_ServerReflectionInfoClient
+	if stream, err = rpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx); err != nil {
+		return
+	}
+	defer func() {
+		_ = stream.CloseSend()
+	}()
+
+	descs := map[string]*descriptorpb.FileDescriptorProto{}
+	requests := []*rpb.ServerReflectionRequest{{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: service},
+	}}
+	for len(requests) > 0 {
+		request := requests[0]
+		requests = requests[1:]
+
+		var resp *rpb.ServerReflectionResponse
+		if err = stream.Send(request); err != nil {
+			return
+		}
+		if resp, err = stream.Recv(); err != nil {
+			return
+		}
+		if errResp := resp.GetErrorResponse(); errResp != nil {
+			err = fmt.Errorf("failed to get the descriptor of %s by reflection, %s", service, errResp.GetErrorMessage())
+			return
+		}
+
+		for _, data := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			desc := &descriptorpb.FileDescriptorProto{}
+			if err = proto.Unmarshal(data, desc); err != nil {
+				return
+			}
+			descs[desc.GetName()] = desc
+		}
+
+		// request the dependencies which are not returned, the well-known ones could be found locally
+		for found := true; found; {
+			found = false
+			for _, desc := range descs {
+				for _, dep := range desc.GetDependency() {
+					if _, ok := descs[dep]; ok {
+						continue
+					}
+
+					found = true
+					if fd, findErr := protoregistry.GlobalFiles.FindFileByPath(dep); findErr == nil {
+						descs[dep] = protodesc.ToFileDescriptorProto(fd)
+					} else {
+						descs[dep] = nil
+						requests = append(requests, &rpb.ServerReflectionRequest{
+							MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: dep},
+						})
+					}
+				}
+			}
+		}
+	}
+
+	descSet := &descriptorpb.FileDescriptorSet{}
+	for name, desc := range descs {
+		if desc == nil {
+			err = fmt.Errorf("cannot find the descriptor of %s by reflection", name)
+			return
+		}
+		descSet.File = append(descSet.File, desc)
+	}
+	files, err = protodesc.NewFiles(descSet)
+	return
+}
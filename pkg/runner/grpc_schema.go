@@ -0,0 +1,78 @@
+package runner
+
+import (
+	"fmt"
+	"sort"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// schemaAnomalies walks the message to find the parts which don't match its descriptor.
+// The unknown fields and undefined enum values are the violations, and the fields
+// in the default value (not populated) are the defaults
+func schemaAnomalies(message protoreflect.Message) (violations, defaults []string) {
+	walkMessageSchema("", message, &violations, &defaults)
+	sort.Strings(violations)
+	sort.Strings(defaults)
+	return
+}
+
+func walkMessageSchema(prefix string, message protoreflect.Message, violations, defaults *[]string) {
+	if unknown := message.GetUnknown(); len(unknown) > 0 {
+		*violations = append(*violations, fmt.Sprintf("%s: %d bytes of unknown fields", emptyThenRoot(prefix), len(unknown)))
+	}
+
+	fields := message.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		name := joinFieldName(prefix, string(field.Name()))
+		if !message.Has(field) {
+			// only one field of the oneof could be populated
+			if oneof := field.ContainingOneof(); oneof == nil || oneof.IsSynthetic() {
+				*defaults = append(*defaults, name)
+			}
+			continue
+		}
+
+		value := message.Get(field)
+		switch {
+		case field.IsList():
+			list := value.List()
+			for j := 0; j < list.Len(); j++ {
+				walkValueSchema(fmt.Sprintf("%s[%d]", name, j), field, list.Get(j), violations, defaults)
+			}
+		case field.IsMap():
+			value.Map().Range(func(key protoreflect.MapKey, val protoreflect.Value) bool {
+				walkValueSchema(fmt.Sprintf("%s[%s]", name, key.String()), field.MapValue(), val, violations, defaults)
+				return true
+			})
+		default:
+			walkValueSchema(name, field, value, violations, defaults)
+		}
+	}
+}
+
+func walkValueSchema(name string, field protoreflect.FieldDescriptor, value protoreflect.Value, violations, defaults *[]string) {
+	switch field.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		walkMessageSchema(name, value.Message(), violations, defaults)
+	case protoreflect.EnumKind:
+		if field.Enum().Values().ByNumber(value.Enum()) == nil {
+			*violations = append(*violations, fmt.Sprintf("%s: undefined value %d of enum %s", name, value.Enum(), field.Enum().FullName()))
+		}
+	}
+}
+
+func joinFieldName(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func emptyThenRoot(name string) string {
+	if name == "" {
+		return "(root)"
+	}
+	return name
+}
@@ -0,0 +1,80 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestSchemaAnomalies(t *testing.T) {
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("order.proto"),
+		Package: proto.String("sample"),
+		Syntax:  proto.String("proto3"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{{
+			Name: proto.String("Status"),
+			Value: []*descriptorpb.EnumValueDescriptorProto{
+				{Name: proto.String("UNKNOWN"), Number: proto.Int32(0)},
+				{Name: proto.String("PAID"), Number: proto.Int32(1)},
+			},
+		}},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Item"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				newFieldDescriptor("name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				newFieldDescriptor("status", 2, descriptorpb.FieldDescriptorProto_TYPE_ENUM, ".sample.Status"),
+			},
+		}, {
+			Name: proto.String("Order"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				newFieldDescriptor("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				newFieldDescriptor("note", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				newFieldDescriptor("item", 3, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".sample.Item"),
+			},
+		}},
+	}, nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	order := dynamicpb.NewMessage(file.Messages().ByName("Order"))
+	violations, defaults := schemaAnomalies(order)
+	assert.Empty(t, violations)
+	assert.Equal(t, []string{"id", "item", "note"}, defaults)
+
+	itemDesc := file.Messages().ByName("Item")
+	item := dynamicpb.NewMessage(itemDesc)
+	item.Set(itemDesc.Fields().ByName("name"), protoreflect.ValueOfString("book"))
+	item.Set(itemDesc.Fields().ByName("status"), protoreflect.ValueOfEnum(9))
+	order.Set(order.Descriptor().Fields().ByName("id"), protoreflect.ValueOfString("1"))
+	order.Set(order.Descriptor().Fields().ByName("item"), protoreflect.ValueOfMessage(item))
+	order.SetUnknown(protowire.AppendVarint(protowire.AppendTag(nil, 10, protowire.VarintType), 1))
+
+	violations, defaults = schemaAnomalies(order)
+	assert.Equal(t, []string{
+		"(root): 2 bytes of unknown fields",
+		"item.status: undefined value 9 of enum sample.Status",
+	}, violations)
+	assert.Equal(t, []string{"note"}, defaults)
+}
+
+func newFieldDescriptor(name string, number int32, kind descriptorpb.FieldDescriptorProto_Type,
+	typeName string) (field *descriptorpb.FieldDescriptorProto) {
+	field = &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String(name),
+		JsonName: proto.String(name),
+		Number:   proto.Int32(number),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:     kind.Enum(),
+	}
+	if typeName != "" {
+		field.TypeName = proto.String(typeName)
+	}
+	return
+}
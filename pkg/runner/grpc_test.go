@@ -0,0 +1,548 @@
+package runner_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/linuxsuren/api-testing/pkg/runner"
+	"github.com/linuxsuren/api-testing/pkg/server"
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	fakeruntime "github.com/linuxsuren/go-fake-runtime"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestGRPCTestCaseRunner(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	gRPCServer := grpc.NewServer()
+	server.RegisterRunnerServer(gRPCServer, server.NewServer("v0.0.1", nil))
+	reflection.Register(gRPCServer)
+	go func() {
+		_ = gRPCServer.Serve(lis)
+	}()
+	defer gRPCServer.Stop()
+
+	address := lis.Addr().String()
+	tests := []struct {
+		name     string
+		testCase *atest.TestCase
+		execer   fakeruntime.Execer
+		verify   func(t *testing.T, output interface{}, err error)
+	}{{
+		name: "normal",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: address,
+				Header: map[string]string{
+					"user": "linuxsuren",
+				},
+				GRPC: &atest.GRPCRequest{
+					Service: "server.Runner",
+					Method:  "GetVersion",
+				},
+			},
+			Expect: atest.Response{
+				BodyFieldsExpect: map[string]interface{}{
+					"message": "v0.0.1",
+				},
+				Verify: []string{`data.error == ""`},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+			assert.Equal(t, map[string]interface{}{"message": "v0.0.1", "error": ""}, output)
+		},
+	}, {
+		name: "request message in YAML",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  address,
+				Body: "kind: fake",
+				GRPC: &atest.GRPCRequest{
+					Service: "server.Runner",
+					Method:  "Run",
+				},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name: "unexpected field value",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: address,
+				GRPC: &atest.GRPCRequest{
+					Service: "server.Runner",
+					Method:  "GetVersion",
+				},
+			},
+			Expect: atest.Response{
+				BodyFieldsExpect: map[string]interface{}{
+					"message": "v0.0.2",
+				},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "the fields in the default value are not allowed in the strict mode",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: address,
+				GRPC: &atest.GRPCRequest{
+					Service: "server.Runner",
+					Method:  "GetVersion",
+				},
+			},
+			Expect: atest.Response{
+				GRPCStrict: true,
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), "the fields are in the default value: error")
+		},
+	}, {
+		name: "invalid request message",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  address,
+				Body: `{"fake": "fake"}`,
+				GRPC: &atest.GRPCRequest{
+					Service: "server.Runner",
+					Method:  "Run",
+				},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "unexpected gRPC status",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: address,
+				GRPC: &atest.GRPCRequest{
+					Service: "server.Runner",
+					Method:  "GetVersion",
+				},
+			},
+			Expect: atest.Response{
+				GRPCStatus: "NotFound",
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "the options of the gRPC calls",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  address,
+				Body: fmt.Sprintf(`{"data": "%s"}`, strings.Repeat("a", 2048)),
+				GRPC: &atest.GRPCRequest{
+					Service: "server.Runner",
+					Method:  "Run",
+				},
+				// the messages of the reflection are smaller than the limit
+				Transport: &atest.Transport{GRPC: &atest.GRPCOptions{
+					KeepaliveTime:    "30s",
+					KeepaliveTimeout: "10s",
+					MaxRecvMsgSize:   1024 * 1024,
+					MaxSendMsgSize:   1024,
+				}},
+			},
+			Expect: atest.Response{
+				GRPCStatus: "ResourceExhausted",
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name: "the deadline of the call",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: address,
+				GRPC: &atest.GRPCRequest{
+					Service: "server.Runner",
+					Method:  "GetVersion",
+				},
+				Transport: &atest.Transport{GRPC: &atest.GRPCOptions{CallTimeout: "1ns"}},
+			},
+			Expect: atest.Response{
+				GRPCStatus: "DeadlineExceeded",
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name: "invalid gRPC options",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: address,
+				GRPC: &atest.GRPCRequest{
+					Service: "server.Runner",
+					Method:  "GetVersion",
+				},
+				Transport: &atest.Transport{GRPC: &atest.GRPCOptions{KeepaliveTime: "fake"}},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.ErrorContains(t, err, "invalid gRPC options")
+		},
+	}, {
+		name: "method not found",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: address,
+				GRPC: &atest.GRPCRequest{
+					Service: "server.Runner",
+					Method:  "Fake",
+				},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "service not found",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: address,
+				GRPC: &atest.GRPCRequest{
+					Service: "server.Fake",
+					Method:  "Fake",
+				},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "failed to compile the proto file",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: address,
+				GRPC: &atest.GRPCRequest{
+					Service:   "server.Runner",
+					Method:    "GetVersion",
+					ProtoFile: "server.proto",
+				},
+			},
+		},
+		execer: fakeruntime.FakeExecer{ExpectError: errors.New("fake")},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "service not found in the proto file",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: address,
+				GRPC: &atest.GRPCRequest{
+					Service:   "server.Runner",
+					Method:    "GetVersion",
+					ProtoFile: "server.proto",
+				},
+			},
+		},
+		execer: fakeruntime.FakeExecer{},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grpcRunner := runner.GetTestCaseRunner(tt.testCase)
+			if tt.execer != nil {
+				grpcRunner.WithExecer(tt.execer)
+			}
+			output, err := grpcRunner.RunTestCase(tt.testCase, nil, context.TODO())
+			tt.verify(t, output, err)
+		})
+	}
+}
+
+func TestGRPCStreaming(t *testing.T) {
+	item, err := registerStreamService()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	gRPCServer := grpc.NewServer()
+	gRPCServer.RegisterService(newStreamServiceDesc(item), struct{}{})
+	reflection.Register(gRPCServer)
+	go func() {
+		_ = gRPCServer.Serve(lis)
+	}()
+	defer gRPCServer.Stop()
+
+	address := lis.Addr().String()
+	tests := []struct {
+		name     string
+		method   string
+		body     string
+		messages []string
+		expect   atest.Response
+		verify   func(t *testing.T, output interface{}, err error)
+	}{{
+		name:   "server streaming",
+		method: "Count",
+		body:   `{"name": "a", "index": 3}`,
+		expect: atest.Response{
+			Messages: []string{`{"index": 1}`, "name: a\nindex: 2", "index: 3"},
+			Verify:   []string{"len(data) == 3"},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+			assert.Equal(t, []interface{}{
+				map[string]interface{}{"name": "a", "index": "1"},
+				map[string]interface{}{"name": "a", "index": "2"},
+				map[string]interface{}{"name": "a", "index": "3"},
+			}, output)
+		},
+	}, {
+		name:   "unexpected count of the messages",
+		method: "Count",
+		body:   `{"index": 3}`,
+		expect: atest.Response{
+			Messages: []string{"index: 1", "index: 2"},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), "expect 2 messages, received 3")
+			}
+		},
+	}, {
+		name:   "unexpected order of the messages",
+		method: "Count",
+		body:   `{"index": 2}`,
+		expect: atest.Response{
+			Messages: []string{"index: 2", "index: 1"},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), "[0].index: expect 2, actual 1")
+			}
+		},
+	}, {
+		name:   "expected error status of the stream",
+		method: "Count",
+		body:   `{"index": -1}`,
+		expect: atest.Response{
+			GRPCStatus: "InvalidArgument",
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name:     "client streaming",
+		method:   "Join",
+		messages: []string{"name: a", `{"name": "b"}`},
+		expect: atest.Response{
+			BodyFieldsExpect: map[string]interface{}{"name": "a,b"},
+			Verify:           []string{`data.index == "2"`},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name:     "bidirectional streaming",
+		method:   "Echo",
+		messages: []string{"name: b", "name: c"},
+		expect: atest.Response{
+			Messages: []string{"name: b", "name: c"},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name:   "the body is sent if there are no messages",
+		method: "Echo",
+		body:   "name: z",
+		expect: atest.Response{
+			Messages: []string{"name: z"},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name:     "invalid message",
+		method:   "Echo",
+		messages: []string{"fake: fake"},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testCase := &atest.TestCase{
+				Request: atest.Request{
+					API:  address,
+					Body: tt.body,
+					GRPC: &atest.GRPCRequest{
+						Service:  "runner.test.Stream",
+						Method:   tt.method,
+						Messages: tt.messages,
+					},
+				},
+				Expect: tt.expect,
+			}
+			output, err := runner.GetTestCaseRunner(testCase).RunTestCase(testCase, nil, context.TODO())
+			tt.verify(t, output, err)
+		})
+	}
+}
+
+// registerStreamService registers the descriptor of the service which has the streaming methods,
+// it's found by the server reflection. The unary method is only served by gRPC-Web
+func registerStreamService() (item protoreflect.MessageDescriptor, err error) {
+	const fileName = "runner/test/stream.proto"
+	var file protoreflect.FileDescriptor
+	if file, err = protoregistry.GlobalFiles.FindFileByPath(fileName); err != nil {
+		if file, err = protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+			Name:    proto.String(fileName),
+			Package: proto.String("runner.test"),
+			Syntax:  proto.String("proto3"),
+			MessageType: []*descriptorpb.DescriptorProto{{
+				Name: proto.String("Item"),
+				Field: []*descriptorpb.FieldDescriptorProto{{
+					Name:     proto.String("name"),
+					JsonName: proto.String("name"),
+					Number:   proto.Int32(1),
+					Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+					Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				}, {
+					Name:     proto.String("index"),
+					JsonName: proto.String("index"),
+					Number:   proto.Int32(2),
+					Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+					Type:     descriptorpb.FieldDescriptorProto_TYPE_INT64.Enum(),
+				}},
+			}},
+			Service: []*descriptorpb.ServiceDescriptorProto{{
+				Name: proto.String("Stream"),
+				Method: []*descriptorpb.MethodDescriptorProto{{
+					Name:       proto.String("Get"),
+					InputType:  proto.String(".runner.test.Item"),
+					OutputType: proto.String(".runner.test.Item"),
+				}, {
+					Name:            proto.String("Count"),
+					InputType:       proto.String(".runner.test.Item"),
+					OutputType:      proto.String(".runner.test.Item"),
+					ServerStreaming: proto.Bool(true),
+				}, {
+					Name:            proto.String("Join"),
+					InputType:       proto.String(".runner.test.Item"),
+					OutputType:      proto.String(".runner.test.Item"),
+					ClientStreaming: proto.Bool(true),
+				}, {
+					Name:            proto.String("Echo"),
+					InputType:       proto.String(".runner.test.Item"),
+					OutputType:      proto.String(".runner.test.Item"),
+					ClientStreaming: proto.Bool(true),
+					ServerStreaming: proto.Bool(true),
+				}},
+			}},
+		}, protoregistry.GlobalFiles); err != nil {
+			return
+		}
+		if err = protoregistry.GlobalFiles.RegisterFile(file); err != nil {
+			return
+		}
+	}
+	item = file.Messages().ByName("Item")
+	return
+}
+
+// newStreamServiceDesc implements the streaming methods with the dynamic messages
+func newStreamServiceDesc(item protoreflect.MessageDescriptor) *grpc.ServiceDesc {
+	name, index := item.Fields().ByName("name"), item.Fields().ByName("index")
+	newItem := func(nameVal string, indexVal int64) *dynamicpb.Message {
+		message := dynamicpb.NewMessage(item)
+		message.Set(name, protoreflect.ValueOfString(nameVal))
+		message.Set(index, protoreflect.ValueOfInt64(indexVal))
+		return message
+	}
+
+	return &grpc.ServiceDesc{
+		ServiceName: "runner.test.Stream",
+		HandlerType: (*interface{})(nil),
+		Streams: []grpc.StreamDesc{{
+			StreamName:    "Count",
+			ServerStreams: true,
+			Handler: func(_ interface{}, stream grpc.ServerStream) (err error) {
+				request := dynamicpb.NewMessage(item)
+				if err = stream.RecvMsg(request); err != nil {
+					return
+				}
+				count := request.Get(index).Int()
+				if count < 0 {
+					return status.Error(codes.InvalidArgument, "negative count")
+				}
+				for i := int64(1); i <= count && err == nil; i++ {
+					err = stream.SendMsg(newItem(request.Get(name).String(), i))
+				}
+				return
+			},
+		}, {
+			StreamName:    "Join",
+			ClientStreams: true,
+			Handler: func(_ interface{}, stream grpc.ServerStream) (err error) {
+				var names []string
+				for {
+					request := dynamicpb.NewMessage(item)
+					if err = stream.RecvMsg(request); err == io.EOF {
+						break
+					} else if err != nil {
+						return
+					}
+					names = append(names, request.Get(name).String())
+				}
+				return stream.SendMsg(newItem(strings.Join(names, ","), int64(len(names))))
+			},
+		}, {
+			StreamName:    "Echo",
+			ClientStreams: true,
+			ServerStreams: true,
+			Handler: func(_ interface{}, stream grpc.ServerStream) (err error) {
+				for {
+					request := dynamicpb.NewMessage(item)
+					if err = stream.RecvMsg(request); err == io.EOF {
+						return nil
+					} else if err != nil {
+						return
+					}
+					if err = stream.SendMsg(request); err != nil {
+						return
+					}
+				}
+			},
+		}},
+	}
+}
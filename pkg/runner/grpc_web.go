@@ -0,0 +1,151 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const (
+	// grpcWebContentType is the content type of gRPC-Web in the binary format
+	grpcWebContentType = "application/grpc-web+proto"
+	// grpcWebTrailerFlag is the flag of the frame which has the trailers instead of a message
+	grpcWebTrailerFlag byte = 0x80
+	// maxGRPCWebFrameSize avoids allocating too much memory for the bad frame
+	maxGRPCWebFrameSize = 64 << 20
+)
+
+// grpcWebHTTPCodes are the gRPC status of the HTTP status codes which are returned by the gateway
+var grpcWebHTTPCodes = map[int]codes.Code{
+	http.StatusBadRequest:         codes.Internal,
+	http.StatusUnauthorized:       codes.Unauthenticated,
+	http.StatusForbidden:          codes.PermissionDenied,
+	http.StatusNotFound:           codes.Unimplemented,
+	http.StatusTooManyRequests:    codes.Unavailable,
+	http.StatusBadGateway:         codes.Unavailable,
+	http.StatusServiceUnavailable: codes.Unavailable,
+	http.StatusGatewayTimeout:     codes.Unavailable,
+}
+
+// callGRPCWeb posts the message in a gRPC-Web frame, then reads the message frames until the trailers.
+// The error is a gRPC status which comes from the trailers, or the headers if there's no message
+func callGRPCWeb(ctx context.Context, request *testing.Request, method protoreflect.MethodDescriptor,
+	fullMethod string, message proto.Message) (responses []protoreflect.Message, err error) {
+	var data []byte
+	if data, err = proto.Marshal(message); err != nil {
+		return
+	}
+	frame := append(make([]byte, 5), data...)
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(data)))
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(request.API, "/")+fullMethod,
+		bytes.NewReader(frame)); err != nil {
+		return
+	}
+	for key, val := range request.Header {
+		req.Header.Set(key, val)
+	}
+	req.Header.Set("Content-Type", grpcWebContentType)
+	req.Header.Set("Accept", grpcWebContentType)
+	req.Header.Set("X-Grpc-Web", "1")
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+	var resp *http.Response
+	if resp, err = client.Do(req); err != nil {
+		err = status.Error(codes.Unavailable, err.Error())
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		code, ok := grpcWebHTTPCodes[resp.StatusCode]
+		if !ok {
+			code = codes.Unknown
+		}
+		err = status.Errorf(code, "unexpected HTTP status code %d", resp.StatusCode)
+		return
+	}
+
+	trailers := resp.Header
+	for {
+		header := make([]byte, 5)
+		if _, err = io.ReadFull(resp.Body, header); err == io.EOF {
+			err = nil
+			break
+		} else if err != nil {
+			return
+		}
+
+		size := binary.BigEndian.Uint32(header[1:])
+		if size > maxGRPCWebFrameSize {
+			err = fmt.Errorf("invalid gRPC-Web frame size %d", size)
+			return
+		}
+		payload := make([]byte, size)
+		if _, err = io.ReadFull(resp.Body, payload); err != nil {
+			return
+		}
+
+		if header[0]&grpcWebTrailerFlag != 0 {
+			if trailers, err = parseGRPCWebTrailers(payload); err != nil {
+				return
+			}
+			break
+		}
+
+		response := dynamicpb.NewMessage(method.Output())
+		if err = proto.Unmarshal(payload, response); err != nil {
+			return
+		}
+		responses = append(responses, response)
+	}
+
+	var code int
+	if code, err = strconv.Atoi(trailers.Get("grpc-status")); err != nil {
+		err = fmt.Errorf("invalid gRPC status %q in the trailers", trailers.Get("grpc-status"))
+		return
+	}
+	if code != int(codes.OK) {
+		// the message is percent-encoded
+		statusMessage, unescapeErr := url.PathUnescape(trailers.Get("grpc-message"))
+		if unescapeErr != nil {
+			statusMessage = trailers.Get("grpc-message")
+		}
+		err = status.Error(codes.Code(code), statusMessage)
+	}
+	return
+}
+
+// parseGRPCWebTrailers parses the trailers which are in the HTTP/1 header format
+func parseGRPCWebTrailers(data []byte) (trailers http.Header, err error) {
+	data = append(bytes.TrimRight(data, "\r\n"), "\r\n\r\n"...)
+	reader := textproto.NewReader(bufio.NewReader(bytes.NewReader(data)))
+	var header textproto.MIMEHeader
+	if header, err = reader.ReadMIMEHeader(); err != nil {
+		err = fmt.Errorf("invalid gRPC-Web trailers, %v", err)
+		return
+	}
+	trailers = http.Header(header)
+	return
+}
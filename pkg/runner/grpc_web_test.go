@@ -0,0 +1,212 @@
+package runner_test
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/linuxsuren/api-testing/pkg/runner"
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	fakeruntime "github.com/linuxsuren/go-fake-runtime"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestGRPCWeb(t *testing.T) {
+	item, err := registerStreamService()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	server := httptest.NewServer(newFakeGRPCWebHandler(item))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		api    string
+		method string
+		body   string
+		expect atest.Response
+		verify func(t *testing.T, output interface{}, err error)
+	}{{
+		name:   "unary",
+		method: "Get",
+		body:   "name: a",
+		expect: atest.Response{
+			BodyFieldsExpect: map[string]interface{}{"name": "a"},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+			assert.Equal(t, map[string]interface{}{"name": "a", "index": "0"}, output)
+		},
+	}, {
+		name:   "server streaming",
+		method: "Count",
+		body:   `{"name": "a", "index": 2}`,
+		expect: atest.Response{
+			Messages: []string{"index: 1", "index: 2"},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name:   "status in the trailers",
+		method: "Get",
+		body:   "name: missing",
+		expect: atest.Response{
+			GRPCStatus: "NotFound",
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name:   "status in the headers",
+		method: "Count",
+		body:   "index: -1",
+		verify: func(t *testing.T, output interface{}, err error) {
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), "expect gRPC status OK, actual InvalidArgument, message: negative count")
+			}
+		},
+	}, {
+		name:   "HTTP status",
+		api:    server.URL + "/fake",
+		method: "Get",
+		expect: atest.Response{
+			GRPCStatus: "Unimplemented",
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name:   "client streaming is not supported",
+		method: "Join",
+		verify: func(t *testing.T, output interface{}, err error) {
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), "not supported by gRPC-Web")
+			}
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := tt.api
+			if api == "" {
+				api = server.URL
+			}
+			testCase := &atest.TestCase{
+				Request: atest.Request{
+					API:  api,
+					Body: tt.body,
+					GRPC: &atest.GRPCRequest{
+						Service:   "runner.test.Stream",
+						Method:    tt.method,
+						ProtoFile: "stream.proto",
+						Web:       true,
+					},
+				},
+				Expect: tt.expect,
+			}
+			grpcRunner := runner.GetTestCaseRunner(testCase).WithExecer(descriptorExecer{file: item.ParentFile()})
+			output, err := grpcRunner.RunTestCase(testCase, nil, context.TODO())
+			tt.verify(t, output, err)
+		})
+	}
+
+	t.Run("the proto file is required", func(t *testing.T) {
+		testCase := &atest.TestCase{
+			Request: atest.Request{
+				API: server.URL,
+				GRPC: &atest.GRPCRequest{
+					Service: "runner.test.Stream",
+					Method:  "Get",
+					Web:     true,
+				},
+			},
+		}
+		_, err := runner.GetTestCaseRunner(testCase).RunTestCase(testCase, nil, context.TODO())
+		assert.NotNil(t, err)
+	})
+}
+
+// descriptorExecer writes the descriptor set of the file instead of running protoc
+type descriptorExecer struct {
+	fakeruntime.FakeExecer
+	file protoreflect.FileDescriptor
+}
+
+func (e descriptorExecer) RunCommandAndReturn(name, dir string, args ...string) (result string, err error) {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--descriptor_set_out=") {
+			var data []byte
+			if data, err = proto.Marshal(&descriptorpb.FileDescriptorSet{
+				File: []*descriptorpb.FileDescriptorProto{protodesc.ToFileDescriptorProto(e.file)},
+			}); err == nil {
+				err = os.WriteFile(strings.TrimPrefix(arg, "--descriptor_set_out="), data, 0644)
+			}
+		}
+	}
+	return
+}
+
+// newFakeGRPCWebHandler implements the unary and server streaming methods by gRPC-Web
+func newFakeGRPCWebHandler(item protoreflect.MessageDescriptor) http.HandlerFunc {
+	name, index := item.Fields().ByName("name"), item.Fields().ByName("index")
+	writeFrame := func(w io.Writer, flag byte, data []byte) {
+		frame := append([]byte{flag, 0, 0, 0, 0}, data...)
+		binary.BigEndian.PutUint32(frame[1:5], uint32(len(data)))
+		_, _ = w.Write(frame)
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/grpc-web+proto" || r.Header.Get("X-Grpc-Web") != "1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		data, _ := io.ReadAll(r.Body)
+		request := dynamicpb.NewMessage(item)
+		if len(data) < 5 || proto.Unmarshal(data[5:], request) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/grpc-web+proto")
+		switch r.URL.Path {
+		case "/runner.test.Stream/Get":
+			if request.Get(name).String() == "missing" {
+				writeFrame(w, 0x80, []byte("grpc-status: 5\r\ngrpc-message: not%20found\r\n"))
+				return
+			}
+			data, _ = proto.Marshal(request)
+			writeFrame(w, 0, data)
+			writeFrame(w, 0x80, []byte("grpc-status: 0\r\n"))
+		case "/runner.test.Stream/Count":
+			count := request.Get(index).Int()
+			if count < 0 {
+				// the trailers-only response has the status in the headers
+				w.Header().Set("grpc-status", "3")
+				w.Header().Set("grpc-message", "negative count")
+				return
+			}
+			for i := int64(1); i <= count; i++ {
+				message := dynamicpb.NewMessage(item)
+				message.Set(name, request.Get(name))
+				message.Set(index, protoreflect.ValueOfInt64(i))
+				data, _ = proto.Marshal(message)
+				writeFrame(w, 0, data)
+			}
+			writeFrame(w, 0x80, []byte("grpc-status: 0\r\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package runner
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/linuxsuren/api-testing/pkg/util"
+)
+
+const (
+	headerMatchExact  = "exact"
+	headerMatchPrefix = "prefix"
+	headerMatchRegex  = "regex"
+)
+
+// expectHeader compares the response headers by their matching modes, they're matched exactly by default.
+// The absent headers should not be in the response
+func expectHeader(name string, expect testing.Response, header http.Header) (err error) {
+	if err = expectContentType(name, expect.ContentType, header.Get(util.ContentType)); err != nil {
+		return
+	}
+
+	for key, val := range expect.Header {
+		actual := header.Get(key)
+		switch mode := headerMatchMode(expect.HeaderMatch, key); mode {
+		case "", headerMatchExact:
+			err = expectString(name, val, actual)
+		case headerMatchPrefix:
+			if !strings.HasPrefix(actual, val) {
+				err = fmt.Errorf("case: %s, expect the header %s starts with %s, actual %s", name, key, val, actual)
+			}
+		case headerMatchRegex:
+			var reg *regexp.Regexp
+			if reg, err = regexp.Compile(val); err == nil && !reg.MatchString(actual) {
+				err = fmt.Errorf("case: %s, expect the header %s matches %s, actual %s", name, key, val, actual)
+			}
+		default:
+			err = fmt.Errorf("case: %s, unsupported match mode %q of the header %s, only exact, prefix and regex are supported",
+				name, mode, key)
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	for key, values := range expect.HeaderValues {
+		mode := headerMatchMode(expect.HeaderMatch, key)
+		items := headerItems(header, key)
+		for _, val := range values {
+			found := false
+			for _, item := range items {
+				if found, err = headerValueMatch(mode, val, item); err != nil {
+					err = fmt.Errorf("case: %s, invalid value %s of the header %s, %v", name, val, key, err)
+					return
+				} else if found {
+					break
+				}
+			}
+			if !found {
+				err = fmt.Errorf("case: %s, expect the header %s has the value %s, actual %q", name, key, val, items)
+				return
+			}
+		}
+	}
+
+	for _, key := range expect.HeaderAbsent {
+		if values := header.Values(key); len(values) > 0 {
+			err = fmt.Errorf("case: %s, expect the header %s is absent, actual %s", name, key, strings.Join(values, ", "))
+			return
+		}
+	}
+	return
+}
+
+// expectContentType compares the media type and the expected parameters, they're case-insensitive
+func expectContentType(name, expect, actual string) (err error) {
+	if expect == "" {
+		return
+	}
+
+	var expectType, actualType string
+	var expectParams, actualParams map[string]string
+	if expectType, expectParams, err = mime.ParseMediaType(expect); err != nil {
+		err = fmt.Errorf("case: %s, invalid contentType %q, %v", name, expect, err)
+		return
+	}
+	if actual == "" {
+		err = fmt.Errorf("case: %s, expect the Content-Type %s, but it's missing", name, expect)
+		return
+	}
+	if actualType, actualParams, err = mime.ParseMediaType(actual); err != nil {
+		err = fmt.Errorf("case: %s, expect the Content-Type %s, actual the invalid one %q", name, expect, actual)
+		return
+	}
+
+	matched := expectType == actualType
+	for key, val := range expectParams {
+		if actualVal, ok := actualParams[key]; !ok || !strings.EqualFold(val, actualVal) {
+			matched = false
+		}
+	}
+	if !matched {
+		err = fmt.Errorf("case: %s, expect the Content-Type %s, actual %s", name, expect, actual)
+	}
+	return
+}
+
+// headerItems returns the values of the repeated header, and the items of the comma separated lists in them,
+// e.g. Vary: Accept-Encoding, Origin. The Set-Cookie values are not split since the expires have commas
+func headerItems(header http.Header, key string) (items []string) {
+	for _, val := range header.Values(key) {
+		items = append(items, val)
+		if strings.Contains(val, ",") && !strings.EqualFold(key, "Set-Cookie") {
+			for _, item := range strings.Split(val, ",") {
+				items = append(items, strings.TrimSpace(item))
+			}
+		}
+	}
+	return
+}
+
+func headerValueMatch(mode, expect, actual string) (bool, error) {
+	switch mode {
+	case "", headerMatchExact:
+		return expect == actual, nil
+	case headerMatchPrefix:
+		return strings.HasPrefix(actual, expect), nil
+	case headerMatchRegex:
+		reg, err := regexp.Compile(expect)
+		if err != nil {
+			return false, err
+		}
+		return reg.MatchString(actual), nil
+	default:
+		return false, fmt.Errorf("unsupported match mode %q, only exact, prefix and regex are supported", mode)
+	}
+}
+
+// headerMatchMode finds the mode of the header, the name is case-insensitive
+func headerMatchMode(modes map[string]string, key string) string {
+	if mode, ok := modes[key]; ok {
+		return mode
+	}
+	for name, mode := range modes {
+		if strings.EqualFold(name, key) {
+			return mode
+		}
+	}
+	return ""
+}
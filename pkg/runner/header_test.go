@@ -0,0 +1,188 @@
+package runner
+
+import (
+	"net/http"
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpectHeader(t *testing.T) {
+	header := http.Header{}
+	header.Set("Content-Type", "application/json; charset=utf-8")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Request-Id", "3f2b9c1e-8d4a-4b7e-9f6a-2c1d0e5b7a91")
+	header.Add("Set-Cookie", "session=abc; Path=/; Expires=Wed, 21 Oct 2026 07:28:00 GMT; HttpOnly")
+	header.Add("Set-Cookie", "theme=dark; Path=/")
+	header.Add("Vary", "Accept-Encoding, Origin")
+	header.Add("Vary", "Cookie")
+
+	tests := []struct {
+		name      string
+		expect    atest.Response
+		expectErr string
+	}{{
+		name: "exact by default",
+		expect: atest.Response{Header: map[string]string{
+			"Cache-Control": "no-cache",
+		}},
+	}, {
+		name: "all the modes",
+		expect: atest.Response{Header: map[string]string{
+			"Content-Type":  "application/json",
+			"cache-control": "no-cache",
+			"X-Request-Id":  "^[0-9a-f-]{36}$",
+		}, HeaderMatch: map[string]string{
+			"content-type":  "prefix",
+			"Cache-Control": "exact",
+			"X-Request-Id":  "regex",
+		}},
+	}, {
+		name: "not exact",
+		expect: atest.Response{Header: map[string]string{
+			"Content-Type": "application/json",
+		}},
+		expectErr: "case: fake, expect application/json, actual application/json; charset=utf-8",
+	}, {
+		name: "not the prefix",
+		expect: atest.Response{Header: map[string]string{
+			"Content-Type": "text/",
+		}, HeaderMatch: map[string]string{"Content-Type": "prefix"}},
+		expectErr: "expect the header Content-Type starts with text/",
+	}, {
+		name: "not match the regex",
+		expect: atest.Response{Header: map[string]string{
+			"X-Request-Id": "^[0-9]+$",
+		}, HeaderMatch: map[string]string{"X-Request-Id": "regex"}},
+		expectErr: "expect the header X-Request-Id matches ^[0-9]+$",
+	}, {
+		name: "invalid regex",
+		expect: atest.Response{Header: map[string]string{
+			"X-Request-Id": "[",
+		}, HeaderMatch: map[string]string{"X-Request-Id": "regex"}},
+		expectErr: "missing closing ]",
+	}, {
+		name: "unsupported mode",
+		expect: atest.Response{Header: map[string]string{
+			"X-Request-Id": "3f2b",
+		}, HeaderMatch: map[string]string{"X-Request-Id": "contains"}},
+		expectErr: `unsupported match mode "contains"`,
+	}, {
+		name:   "absent headers",
+		expect: atest.Response{HeaderAbsent: []string{"X-Debug-Token", "Server"}},
+	}, {
+		name:      "the header is not absent",
+		expect:    atest.Response{HeaderAbsent: []string{"x-request-id"}},
+		expectErr: "case: fake, expect the header x-request-id is absent, actual 3f2b9c1e-8d4a-4b7e-9f6a-2c1d0e5b7a91",
+	}, {
+		name: "repeated headers",
+		expect: atest.Response{HeaderValues: map[string][]string{
+			"Set-Cookie": {"theme=dark; Path=/", "session=abc;"},
+			"vary":       {"Origin", "Cookie", "Accept-Encoding"},
+		}, HeaderMatch: map[string]string{"Set-Cookie": "prefix"}},
+	}, {
+		name: "the value of the repeated header is not found",
+		expect: atest.Response{HeaderValues: map[string][]string{
+			"Vary": {"Accept"},
+		}},
+		expectErr: `case: fake, expect the header Vary has the value Accept, actual ["Accept-Encoding, Origin" "Accept-Encoding" "Origin" "Cookie"]`,
+	}, {
+		name: "the Set-Cookie is not split",
+		expect: atest.Response{HeaderValues: map[string][]string{
+			"Set-Cookie": {"21 Oct 2026 07:28:00 GMT; HttpOnly"},
+		}},
+		expectErr: "expect the header Set-Cookie has the value 21 Oct 2026 07:28:00 GMT; HttpOnly",
+	}, {
+		name: "the repeated header is missing",
+		expect: atest.Response{HeaderValues: map[string][]string{
+			"Link": {"rel=next"},
+		}, HeaderMatch: map[string]string{"Link": "regex"}},
+		expectErr: "case: fake, expect the header Link has the value rel=next, actual []",
+	}, {
+		name: "invalid regex of the repeated header",
+		expect: atest.Response{HeaderValues: map[string][]string{
+			"Vary": {"["},
+		}, HeaderMatch: map[string]string{"Vary": "regex"}},
+		expectErr: "case: fake, invalid value [ of the header Vary, error parsing regexp: missing closing ]",
+	}, {
+		name: "unsupported mode of the repeated header",
+		expect: atest.Response{HeaderValues: map[string][]string{
+			"Vary": {"Origin"},
+		}, HeaderMatch: map[string]string{"Vary": "contains"}},
+		expectErr: `case: fake, invalid value Origin of the header Vary, unsupported match mode "contains"`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := expectHeader("fake", tt.expect, header)
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestExpectContentType(t *testing.T) {
+	tests := []struct {
+		name      string
+		expect    string
+		actual    string
+		expectErr string
+	}{{
+		name:   "not verified",
+		actual: "text/plain",
+	}, {
+		name:   "the same media type and charset",
+		expect: "application/json; charset=utf-8",
+		actual: "Application/JSON;charset=UTF-8",
+	}, {
+		name:   "the charset is not verified",
+		expect: "application/json",
+		actual: "application/json; charset=utf-8",
+	}, {
+		name:      "mismatched media type",
+		expect:    "application/json",
+		actual:    "text/html; charset=utf-8",
+		expectErr: "case: fake, expect the Content-Type application/json, actual text/html; charset=utf-8",
+	}, {
+		name:      "mismatched charset",
+		expect:    "application/json; charset=utf-8",
+		actual:    "application/json; charset=iso-8859-1",
+		expectErr: "case: fake, expect the Content-Type application/json; charset=utf-8, actual application/json; charset=iso-8859-1",
+	}, {
+		name:      "missing charset",
+		expect:    "text/plain; charset=utf-8",
+		actual:    "text/plain",
+		expectErr: "case: fake, expect the Content-Type text/plain; charset=utf-8, actual text/plain",
+	}, {
+		name:      "missing Content-Type",
+		expect:    "application/json",
+		expectErr: "case: fake, expect the Content-Type application/json, but it's missing",
+	}, {
+		name:      "invalid Content-Type",
+		expect:    "application/json",
+		actual:    "application/json; charset",
+		expectErr: `case: fake, expect the Content-Type application/json, actual the invalid one "application/json; charset"`,
+	}, {
+		name:      "invalid contentType",
+		expect:    "/json",
+		actual:    "application/json",
+		expectErr: `case: fake, invalid contentType "/json"`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.actual != "" {
+				header.Set("Content-Type", tt.actual)
+			}
+			err := expectHeader("fake", atest.Response{ContentType: tt.expect}, header)
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expectErr)
+			}
+		})
+	}
+}
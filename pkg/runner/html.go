@@ -0,0 +1,396 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/antchfx/xpath"
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	"golang.org/x/net/html"
+)
+
+// verifyHTMLResponse verifies the HTML response body with the CSS selector expectations,
+// the output is the text of the body
+func verifyHTMLResponse(caseName string, expect testing.Response, body []byte) (output interface{}, err error) {
+	if expect.Body != "" && strings.TrimSpace(string(body)) != strings.TrimSpace(expect.Body) {
+		err = fmt.Errorf("case: %s, got different response body, expect: %s, actual: %s", caseName, expect.Body, string(body))
+		return
+	}
+	if err = verifyBodyText(caseName, expect, body); err != nil {
+		return
+	}
+
+	var root *xmlNode
+	if root, err = parseHTML(body); err != nil {
+		err = fmt.Errorf("case: %s, failed to parse the HTML response, %v", caseName, err)
+		return
+	}
+
+	for selector, htmlExpect := range expect.HTML {
+		if htmlExpect == nil {
+			htmlExpect = &testing.HTMLExpect{}
+		}
+
+		var nodes []*xmlNode
+		if nodes, err = selectHTML(root, selector); err != nil {
+			err = fmt.Errorf("case: %s, %v", caseName, err)
+			return
+		}
+		if err = verifyHTMLNodes(htmlExpect, nodes); err != nil {
+			err = fmt.Errorf("case: %s, CSS[%s] %v", caseName, selector, err)
+			return
+		}
+	}
+	output = string(body)
+	return
+}
+
+func verifyHTMLNodes(expect *testing.HTMLExpect, nodes []*xmlNode) (err error) {
+	if expect.Exists != nil && !*expect.Exists {
+		if len(nodes) > 0 {
+			err = fmt.Errorf("expect no node, actual %d", len(nodes))
+		}
+		return
+	}
+	if expect.Count != nil {
+		if err = verifyArrayLength(expect.Count, len(nodes)); err != nil {
+			return
+		}
+	}
+	if len(nodes) == 0 {
+		if expect.Count == nil {
+			err = fmt.Errorf("not found the node")
+		}
+		return
+	}
+
+	text := strings.Join(strings.Fields(nodes[0].innerText()), " ")
+	if expect.Text != "" && text != expect.Text {
+		err = fmt.Errorf("expect text: %s, actual: %s", expect.Text, text)
+		return
+	}
+	if expect.TextContains != "" && !strings.Contains(text, expect.TextContains) {
+		err = fmt.Errorf("expect text contains: %s, actual: %s", expect.TextContains, text)
+		return
+	}
+	for key, expectVal := range expect.Attrs {
+		val, ok := nodeAttr(nodes[0], key)
+		if !ok {
+			err = fmt.Errorf("not found the attribute %s", key)
+			return
+		}
+		if val != expectVal {
+			err = fmt.Errorf("expect attribute %s: %s, actual: %s", key, expectVal, val)
+			return
+		}
+	}
+	return
+}
+
+func nodeAttr(node *xmlNode, key string) (string, bool) {
+	for _, attr := range node.attrs {
+		if attr.Name.Local == strings.ToLower(key) {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+// parseHTML parses the data to be a document tree like the XML one, so the nodes could be selected by XPath.
+// The element and attribute names are in lower case, the whitespace-only texts are ignored
+func parseHTML(data []byte) (root *xmlNode, err error) {
+	var doc *html.Node
+	if doc, err = html.Parse(bytes.NewReader(data)); err != nil {
+		return
+	}
+
+	root = &xmlNode{nodeType: xpath.RootNode}
+	var convert func(parent *xmlNode, node *html.Node)
+	convert = func(parent *xmlNode, node *html.Node) {
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			switch child.Type {
+			case html.ElementNode:
+				element := &xmlNode{nodeType: xpath.ElementNode, name: xml.Name{Local: child.Data}}
+				for _, attr := range child.Attr {
+					element.attrs = append(element.attrs, xml.Attr{Name: xml.Name{Local: attr.Key}, Value: attr.Val})
+				}
+				parent.appendChild(element)
+				convert(element, child)
+			case html.TextNode:
+				if strings.TrimSpace(child.Data) != "" {
+					parent.appendChild(&xmlNode{nodeType: xpath.TextNode, data: child.Data})
+				}
+			case html.CommentNode:
+				parent.appendChild(&xmlNode{nodeType: xpath.CommentNode, data: child.Data})
+			}
+		}
+	}
+	convert(root, doc)
+	return
+}
+
+// selectHTML selects the nodes by the CSS selector in the document order
+func selectHTML(root *xmlNode, selector string) (nodes []*xmlNode, err error) {
+	var expression string
+	if expression, err = cssToXPath(selector); err != nil {
+		return
+	}
+
+	var expr *xpath.Expr
+	if expr, err = xpath.Compile(expression); err != nil {
+		err = fmt.Errorf("invalid CSS selector '%s', %v", selector, err)
+		return
+	}
+	iterator := expr.Select(newXMLNavigator(root))
+	for iterator.MoveNext() {
+		if navigator, ok := iterator.Current().(*xmlNavigator); ok {
+			nodes = append(nodes, navigator.current)
+		}
+	}
+	return
+}
+
+// cssToXPath translates the CSS selector to be an XPath expression. The type, universal, id, class and attribute
+// selectors, the combinators, the groups and the :first-child, :last-child and :nth-child(n) are supported
+func cssToXPath(selector string) (expression string, err error) {
+	scanner := &cssScanner{input: strings.TrimSpace(selector)}
+	var paths []string
+	for {
+		var path string
+		if path, err = scanner.complex(); err != nil {
+			break
+		}
+		paths = append(paths, path)
+		if scanner.eof() {
+			break
+		}
+		scanner.pos++ // the comma
+	}
+
+	if err != nil {
+		err = fmt.Errorf("invalid CSS selector '%s', %v", selector, err)
+		return
+	}
+	expression = strings.Join(paths, " | ")
+	return
+}
+
+type cssScanner struct {
+	input string
+	pos   int
+}
+
+func (s *cssScanner) eof() bool {
+	return s.pos >= len(s.input)
+}
+
+func (s *cssScanner) peek() byte {
+	if s.eof() {
+		return 0
+	}
+	return s.input[s.pos]
+}
+
+func (s *cssScanner) skipSpaces() {
+	for !s.eof() && strings.IndexByte(" \t\r\n", s.peek()) >= 0 {
+		s.pos++
+	}
+}
+
+func (s *cssScanner) ident() string {
+	start := s.pos
+	for !s.eof() {
+		c := s.peek()
+		if c != '-' && c != '_' && c < 0x80 && !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+			break
+		}
+		s.pos++
+	}
+	return s.input[start:s.pos]
+}
+
+// complex parses the compound selectors and the combinators until the comma or the end
+func (s *cssScanner) complex() (path string, err error) {
+	s.skipSpaces()
+	var step string
+	if step, err = s.compound(); err != nil {
+		return
+	}
+	path = "//" + step
+
+	for {
+		s.skipSpaces()
+		if s.eof() || s.peek() == ',' {
+			return
+		}
+
+		combinator := byte(' ')
+		if c := s.peek(); c == '>' || c == '+' || c == '~' {
+			combinator = c
+			s.pos++
+			s.skipSpaces()
+		}
+		if step, err = s.compound(); err != nil {
+			return
+		}
+
+		switch combinator {
+		case '>':
+			path += "/" + step
+		case '+':
+			path += "/following-sibling::*[1]/self::" + step
+		case '~':
+			path += "/following-sibling::" + step
+		default:
+			path += "//" + step
+		}
+	}
+}
+
+// compound parses a type selector and the id, class, attribute and pseudo-class selectors which follow it
+func (s *cssScanner) compound() (step string, err error) {
+	start := s.pos
+	step = "*"
+	if s.peek() == '*' {
+		s.pos++
+	} else if name := s.ident(); name != "" {
+		step = strings.ToLower(name)
+	}
+
+	for {
+		var predicate string
+		switch s.peek() {
+		case '#':
+			s.pos++
+			predicate, err = s.nonEmpty(s.ident(), "id")
+			predicate = "@id=" + xpathLiteral(predicate)
+		case '.':
+			s.pos++
+			predicate, err = s.nonEmpty(s.ident(), "class")
+			predicate = "contains(concat(' ', normalize-space(@class), ' '), " + xpathLiteral(" "+predicate+" ") + ")"
+		case '[':
+			s.pos++
+			predicate, err = s.attribute()
+		case ':':
+			s.pos++
+			predicate, err = s.pseudoClass()
+		default:
+			if s.pos == start {
+				err = fmt.Errorf("expect a selector at %d", s.pos)
+			}
+			return
+		}
+		if err != nil {
+			return
+		}
+		step += "[" + predicate + "]"
+	}
+}
+
+func (s *cssScanner) nonEmpty(name, kind string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("expect the %s at %d", kind, s.pos)
+	}
+	return name, nil
+}
+
+// attribute parses the attribute selector after the bracket, such as: [href^="https://"]
+func (s *cssScanner) attribute() (predicate string, err error) {
+	s.skipSpaces()
+	var name string
+	if name, err = s.nonEmpty(s.ident(), "attribute name"); err != nil {
+		return
+	}
+	attr := "@" + strings.ToLower(name)
+	s.skipSpaces()
+	if s.peek() == ']' {
+		s.pos++
+		predicate = attr
+		return
+	}
+
+	var operator string
+	for _, item := range []string{"=", "~=", "^=", "$=", "*="} {
+		if strings.HasPrefix(s.input[s.pos:], item) {
+			operator = item
+			s.pos += len(item)
+			break
+		}
+	}
+	if operator == "" {
+		err = fmt.Errorf("unexpected %q in the attribute selector at %d", s.peek(), s.pos)
+		return
+	}
+
+	s.skipSpaces()
+	var value string
+	if c := s.peek(); c == '"' || c == '\'' {
+		end := strings.IndexByte(s.input[s.pos+1:], c)
+		if end < 0 {
+			err = fmt.Errorf("the quoted value is not closed at %d", s.pos)
+			return
+		}
+		value = s.input[s.pos+1 : s.pos+1+end]
+		s.pos += end + 2
+	} else if value, err = s.nonEmpty(s.ident(), "attribute value"); err != nil {
+		return
+	}
+	s.skipSpaces()
+	if s.peek() != ']' {
+		err = fmt.Errorf("expect ] at %d", s.pos)
+		return
+	}
+	s.pos++
+
+	literal := xpathLiteral(value)
+	switch operator {
+	case "=":
+		predicate = attr + "=" + literal
+	case "~=":
+		predicate = "contains(concat(' ', normalize-space(" + attr + "), ' '), " + xpathLiteral(" "+value+" ") + ")"
+	case "^=":
+		predicate = "starts-with(" + attr + ", " + literal + ")"
+	case "$=":
+		predicate = "ends-with(" + attr + ", " + literal + ")"
+	case "*=":
+		predicate = "contains(" + attr + ", " + literal + ")"
+	}
+	return
+}
+
+func (s *cssScanner) pseudoClass() (predicate string, err error) {
+	name := strings.ToLower(s.ident())
+	switch name {
+	case "first-child":
+		predicate = "not(preceding-sibling::*)"
+	case "last-child":
+		predicate = "not(following-sibling::*)"
+	case "nth-child":
+		end := strings.IndexByte(s.input[s.pos:], ')')
+		var index int
+		if s.peek() != '(' || end < 0 {
+			err = fmt.Errorf("expect the index of :nth-child at %d", s.pos)
+		} else if index, err = strconv.Atoi(strings.TrimSpace(s.input[s.pos+1 : s.pos+end])); err != nil || index < 1 {
+			err = fmt.Errorf("the index of :nth-child should be a positive integer at %d", s.pos)
+		} else {
+			s.pos += end + 1
+			predicate = "count(preceding-sibling::*)=" + strconv.Itoa(index-1)
+		}
+	default:
+		err = fmt.Errorf("unsupported pseudo-class :%s", name)
+	}
+	return
+}
+
+// xpathLiteral quotes the value to be an XPath string literal
+func xpathLiteral(value string) string {
+	if !strings.Contains(value, "'") {
+		return "'" + value + "'"
+	} else if !strings.Contains(value, `"`) {
+		return `"` + value + `"`
+	}
+	return "concat('" + strings.ReplaceAll(value, "'", `', "'", '`) + "')"
+}
@@ -0,0 +1,141 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/h2non/gock"
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+const htmlPage = `<!DOCTYPE html>
+<html>
+<head><title>Users</title></head>
+<body>
+  <h1 class="title main">All   users</h1>
+  <ul id="users">
+    <li class="user"><a href="/users/rick" data-role="admin owner">Rick</a></li>
+    <li class="user disabled"><a href="/users/morty">Morty</a></li>
+    <li class="user"><a href='https://example.com/it&apos;s'>Summer</a></li>
+  </ul>
+  <p>Total: <b>3</b></p>
+  <input type="checkbox" checked>
+</body>
+</html>`
+
+func TestSelectHTML(t *testing.T) {
+	root, err := parseHTML([]byte(htmlPage))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	tests := []struct {
+		selector  string
+		expect    []string
+		expectErr string
+	}{
+		{selector: "title", expect: []string{"Users"}},
+		{selector: "H1.title.main", expect: []string{"All   users"}},
+		{selector: "#users > li", expect: []string{"Rick", "Morty", "Summer"}},
+		{selector: "body li.disabled a", expect: []string{"Morty"}},
+		{selector: "li:first-child, li:last-child", expect: []string{"Rick", "Summer"}},
+		{selector: "li:nth-child(2)", expect: []string{"Morty"}},
+		{selector: "li.disabled + li", expect: []string{"Summer"}},
+		{selector: "li:first-child ~ *", expect: []string{"Morty", "Summer"}},
+		{selector: "a[href^='/users/']", expect: []string{"Rick", "Morty"}},
+		{selector: `a[href$="it's"]`, expect: []string{"Summer"}},
+		{selector: "a[href*=morty]", expect: []string{"Morty"}},
+		{selector: "a[data-role~=owner]", expect: []string{"Rick"}},
+		{selector: "input[checked]", expect: []string{""}},
+		{selector: "ul > a"},
+		{selector: "", expectErr: "invalid CSS selector '', expect a selector at 0"},
+		{selector: "li,", expectErr: "invalid CSS selector 'li,', expect a selector at 3"},
+		{selector: "a[href", expectErr: "invalid CSS selector 'a[href', unexpected '\\x00' in the attribute selector at 6"},
+		{selector: "a[href='/users]", expectErr: "invalid CSS selector 'a[href='/users]', the quoted value is not closed at 7"},
+		{selector: "li:hover", expectErr: "invalid CSS selector 'li:hover', unsupported pseudo-class :hover"},
+		{selector: "li:nth-child(odd)", expectErr: "invalid CSS selector 'li:nth-child(odd)', the index of :nth-child should be a positive integer at 12"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			nodes, err := selectHTML(root, tt.selector)
+			if tt.expectErr != "" {
+				assert.EqualError(t, err, tt.expectErr)
+				return
+			}
+			assert.Nil(t, err)
+
+			var texts []string
+			for _, node := range nodes {
+				texts = append(texts, node.innerText())
+			}
+			assert.Equal(t, tt.expect, texts)
+		})
+	}
+}
+
+func TestVerifyHTMLResponse(t *testing.T) {
+	defer gock.Off()
+
+	notExists := false
+	tests := []struct {
+		name      string
+		expect    map[string]*atest.HTMLExpect
+		expectErr string
+	}{{
+		name: "normal",
+		expect: map[string]*atest.HTMLExpect{
+			"h1":               {Text: "All users"},
+			"p":                {TextContains: "Total: 3"},
+			"#users > li":      {Count: 3},
+			"li.disabled a":    {Attrs: map[string]string{"HREF": "/users/morty"}},
+			".error":           {Exists: &notExists},
+			"a[href^='https']": nil,
+			"li.admin":         {Count: "lt 1"},
+		},
+	}, {
+		name:      "not found",
+		expect:    map[string]*atest.HTMLExpect{".error": {}},
+		expectErr: "case: html, CSS[.error] not found the node",
+	}, {
+		name:      "unexpected node",
+		expect:    map[string]*atest.HTMLExpect{"li": {Exists: &notExists}},
+		expectErr: "case: html, CSS[li] expect no node, actual 3",
+	}, {
+		name:      "unexpected count",
+		expect:    map[string]*atest.HTMLExpect{"li": {Count: "gt 3"}},
+		expectErr: "case: html, CSS[li] expect length: gt 3, actual: 3",
+	}, {
+		name:      "unexpected text",
+		expect:    map[string]*atest.HTMLExpect{"title": {Text: "Orders"}},
+		expectErr: "case: html, CSS[title] expect text: Orders, actual: Users",
+	}, {
+		name:      "missing attribute",
+		expect:    map[string]*atest.HTMLExpect{"a": {Attrs: map[string]string{"target": "_blank"}}},
+		expectErr: "case: html, CSS[a] not found the attribute target",
+	}, {
+		name:      "invalid selector",
+		expect:    map[string]*atest.HTMLExpect{"li:hover": {}},
+		expectErr: "case: html, invalid CSS selector 'li:hover', unsupported pseudo-class :hover",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gock.New("http://localhost").Get("/users").Reply(http.StatusOK).
+				SetHeader("Content-Type", "text/html; charset=utf-8").BodyString(htmlPage)
+
+			output, err := NewSimpleTestCaseRunner().RunTestCase(&atest.TestCase{
+				Name:    "html",
+				Request: atest.Request{API: "http://localhost/users"},
+				Expect:  atest.Response{HTML: tt.expect},
+			}, nil, context.TODO())
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+				assert.True(t, strings.HasPrefix(output.(string), "<!DOCTYPE html>"))
+			} else {
+				assert.EqualError(t, err, tt.expectErr)
+			}
+		})
+	}
+}
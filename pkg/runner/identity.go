@@ -0,0 +1,302 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/render"
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/linuxsuren/api-testing/pkg/util"
+)
+
+// IdentityProvider creates and deletes the temporary users, the credentials have the username and password at least
+type IdentityProvider interface {
+	Create(ctx context.Context, identity *testing.Identity) (credentials map[string]string, err error)
+	Delete(ctx context.Context, identity *testing.Identity, credentials map[string]string) error
+}
+
+const (
+	identityProviderKeycloak = "keycloak"
+	identityProviderHTTP     = "http"
+)
+
+var identityProviders = map[string]IdentityProvider{}
+
+// RegisterIdentityProvider registers a provider with the name, it overrides the built-in one of the same name
+func RegisterIdentityProvider(name string, provider IdentityProvider) {
+	identityProviders[name] = provider
+}
+
+// GetIdentityProviderNames returns all the registered provider names
+func GetIdentityProviderNames() (names []string) {
+	for name := range identityProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// ProvisionedIdentity is a created identity and its credentials
+type ProvisionedIdentity struct {
+	Identity    *testing.Identity
+	Credentials map[string]string
+}
+
+// ProvisionIdentities creates the identities in order, the created ones are returned
+// even if it fails, so that they could be deleted
+func ProvisionIdentities(ctx context.Context, identities []testing.Identity) (created []*ProvisionedIdentity, err error) {
+	for i := range identities {
+		identity := &identities[i]
+		provider, ok := identityProviders[identity.Provider]
+		if !ok {
+			err = fmt.Errorf("not supported identity provider: '%s', supported: %v", identity.Provider, GetIdentityProviderNames())
+			return
+		}
+
+		// the secrets could be read from the environment variables, e.g. {{env "ADMIN_PASSWORD"}}
+		if err = renderIdentity(identity); err != nil {
+			return
+		}
+		if identity.Username == "" {
+			identity.Username = "atest-" + strings.ToLower(util.String(8))
+		}
+		if identity.Password == "" {
+			// satisfy the common password policies
+			identity.Password = util.String(16) + "-Aa1"
+		}
+
+		var credentials map[string]string
+		if credentials, err = provider.Create(ctx, identity); err != nil {
+			err = fmt.Errorf("failed to create the identity %s, %v", identity.Name, err)
+			return
+		}
+		created = append(created, &ProvisionedIdentity{Identity: identity, Credentials: credentials})
+	}
+	return
+}
+
+func renderIdentity(identity *testing.Identity) (err error) {
+	if identity.API, err = render.Render("api", identity.API, nil); err != nil {
+		return
+	}
+	for key, val := range identity.Options {
+		if identity.Options[key], err = render.Render("option", val, nil); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// DeleteIdentities deletes the identities in the reverse order, all of them are tried even if some fail
+func DeleteIdentities(ctx context.Context, identities []*ProvisionedIdentity) (err error) {
+	var failed []string
+	for i := len(identities) - 1; i >= 0; i-- {
+		identity := identities[i]
+		if deleteErr := identityProviders[identity.Identity.Provider].Delete(ctx, identity.Identity,
+			identity.Credentials); deleteErr != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", identity.Identity.Name, deleteErr))
+		}
+	}
+	if len(failed) > 0 {
+		err = fmt.Errorf("failed to delete the identities, %s", strings.Join(failed, "; "))
+	}
+	return
+}
+
+// IdentityContext returns the credentials of the identities by their names
+func IdentityContext(identities []*ProvisionedIdentity) map[string]interface{} {
+	result := map[string]interface{}{}
+	for _, identity := range identities {
+		result[identity.Identity.Name] = identity.Credentials
+	}
+	return result
+}
+
+// keycloakIdentityProvider creates the users by the admin REST API of Keycloak. The admin token is acquired by
+// the adminUsername and adminPassword of the admin-cli, or the clientID and clientSecret of the adminRealm
+type keycloakIdentityProvider struct{}
+
+func (p *keycloakIdentityProvider) Create(ctx context.Context, identity *testing.Identity) (credentials map[string]string, err error) {
+	var token string
+	if token, err = keycloakAdminToken(ctx, identity); err != nil {
+		return
+	}
+
+	var data []byte
+	if data, err = json.Marshal(map[string]interface{}{
+		"username":      identity.Username,
+		"enabled":       true,
+		"email":         identity.Username + "@example.com",
+		"emailVerified": true,
+		"firstName":     "atest",
+		"lastName":      identity.Username,
+		"credentials": []map[string]interface{}{{
+			"type": "password", "value": identity.Password, "temporary": false,
+		}},
+	}); err != nil {
+		return
+	}
+
+	var resp *http.Response
+	if resp, _, err = sendIdentityRequest(ctx, http.MethodPost, keycloakUsersAPI(identity),
+		map[string]string{"Authorization": "Bearer " + token, util.ContentType: "application/json"}, string(data)); err != nil {
+		return
+	}
+
+	// the Location header is the URL of the created user
+	credentials = map[string]string{
+		"id":       path.Base(resp.Header.Get("Location")),
+		"username": identity.Username,
+		"password": identity.Password,
+		"email":    identity.Username + "@example.com",
+	}
+	return
+}
+
+func (p *keycloakIdentityProvider) Delete(ctx context.Context, identity *testing.Identity, credentials map[string]string) (err error) {
+	// the token might be expired after running the test cases
+	var token string
+	if token, err = keycloakAdminToken(ctx, identity); err == nil {
+		_, _, err = sendIdentityRequest(ctx, http.MethodDelete, keycloakUsersAPI(identity)+"/"+url.PathEscape(credentials["id"]),
+			map[string]string{"Authorization": "Bearer " + token}, "")
+	}
+	return
+}
+
+func keycloakUsersAPI(identity *testing.Identity) string {
+	return fmt.Sprintf("%s/admin/realms/%s/users", strings.TrimSuffix(identity.API, "/"),
+		url.PathEscape(identity.Options["realm"]))
+}
+
+func keycloakAdminToken(ctx context.Context, identity *testing.Identity) (token string, err error) {
+	options := identity.Options
+	if options["realm"] == "" {
+		err = fmt.Errorf("the realm option is required by %s", identityProviderKeycloak)
+		return
+	}
+
+	form := url.Values{}
+	if options["clientSecret"] != "" {
+		form.Set("grant_type", "client_credentials")
+		form.Set("client_id", options["clientID"])
+		form.Set("client_secret", options["clientSecret"])
+	} else {
+		form.Set("grant_type", "password")
+		form.Set("client_id", emptyThenDefault(options["clientID"], "admin-cli"))
+		form.Set("username", options["adminUsername"])
+		form.Set("password", options["adminPassword"])
+	}
+
+	var data []byte
+	if _, data, err = sendIdentityRequest(ctx, http.MethodPost, fmt.Sprintf("%s/realms/%s/protocol/openid-connect/token",
+		strings.TrimSuffix(identity.API, "/"), url.PathEscape(emptyThenDefault(options["adminRealm"], "master"))),
+		map[string]string{util.ContentType: util.Form}, form.Encode()); err != nil {
+		err = fmt.Errorf("failed to get the admin token, %v", err)
+		return
+	}
+
+	result := struct {
+		AccessToken string `json:"access_token"`
+	}{}
+	if err = json.Unmarshal(data, &result); err == nil && result.AccessToken == "" {
+		err = fmt.Errorf("no access token in the token response")
+	}
+	token = result.AccessToken
+	return
+}
+
+// httpIdentityProvider sends the create and delete requests, they're rendered with the credentials
+type httpIdentityProvider struct{}
+
+func (p *httpIdentityProvider) Create(ctx context.Context, identity *testing.Identity) (credentials map[string]string, err error) {
+	if identity.Create == nil {
+		err = fmt.Errorf("the create request is required by the %s identity provider", identityProviderHTTP)
+		return
+	}
+
+	credentials = map[string]string{
+		"username": identity.Username,
+		"password": identity.Password,
+	}
+	var data []byte
+	if data, err = sendIdentityTemplateRequest(ctx, identity.Create, http.MethodPost, credentials); err != nil {
+		return
+	}
+
+	// the response might have the ID or token of the user
+	fields := map[string]interface{}{}
+	if json.Unmarshal(data, &fields) == nil {
+		for key, val := range fields {
+			switch val.(type) {
+			case map[string]interface{}, []interface{}, nil:
+			default:
+				credentials[key] = fmt.Sprint(val)
+			}
+		}
+	}
+	return
+}
+
+func (p *httpIdentityProvider) Delete(ctx context.Context, identity *testing.Identity, credentials map[string]string) (err error) {
+	if identity.Delete != nil {
+		_, err = sendIdentityTemplateRequest(ctx, identity.Delete, http.MethodDelete, credentials)
+	}
+	return
+}
+
+func sendIdentityTemplateRequest(ctx context.Context, request *testing.IdentityRequest, defaultMethod string,
+	credentials map[string]string) (data []byte, err error) {
+	var api, body string
+	if api, err = render.Render("api", request.API, credentials); err != nil {
+		return
+	}
+	if body, err = render.Render("body", request.Body, credentials); err != nil {
+		return
+	}
+
+	header := map[string]string{}
+	for key, val := range request.Header {
+		if header[key], err = render.Render("header", val, credentials); err != nil {
+			return
+		}
+	}
+	if _, ok := header[util.ContentType]; !ok && body != "" {
+		header[util.ContentType] = "application/json"
+	}
+
+	_, data, err = sendIdentityRequest(ctx, emptyThenDefault(request.Method, defaultMethod), api, header, body)
+	return
+}
+
+func sendIdentityRequest(ctx context.Context, method, api string, header map[string]string,
+	body string) (resp *http.Response, data []byte, err error) {
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, method, api, strings.NewReader(body)); err != nil {
+		return
+	}
+	for key, val := range header {
+		req.Header.Set(key, val)
+	}
+
+	if resp, err = http.DefaultClient.Do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if data, err = io.ReadAll(resp.Body); err == nil && (resp.StatusCode < 200 || resp.StatusCode >= 300) {
+		err = fmt.Errorf("%s %s, unexpected status code %d, %s", method, api, resp.StatusCode, string(data))
+	}
+	return
+}
+
+func init() {
+	RegisterIdentityProvider(identityProviderKeycloak, &keycloakIdentityProvider{})
+	RegisterIdentityProvider(identityProviderHTTP, &httpIdentityProvider{})
+}
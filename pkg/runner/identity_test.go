@@ -0,0 +1,145 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeycloakIdentityProvider(t *testing.T) {
+	users := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch {
+		case req.URL.Path == "/realms/master/protocol/openid-connect/token":
+			assert.Nil(t, req.ParseForm())
+			if req.PostForm.Get("client_id") != "admin-cli" || req.PostForm.Get("password") != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"access_token":"admin-token"}`))
+		case req.Header.Get("Authorization") != "Bearer admin-token":
+			w.WriteHeader(http.StatusUnauthorized)
+		case req.Method == http.MethodPost && req.URL.Path == "/admin/realms/test/users":
+			user := map[string]interface{}{}
+			assert.Nil(t, json.NewDecoder(req.Body).Decode(&user))
+			assert.Equal(t, true, user["enabled"])
+			users["1234"] = user["username"].(string)
+			w.Header().Set("Location", "http://"+req.Host+"/admin/realms/test/users/1234")
+			w.WriteHeader(http.StatusCreated)
+		case req.Method == http.MethodDelete && req.URL.Path == "/admin/realms/test/users/1234" && users["1234"] != "":
+			delete(users, "1234")
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("ADMIN_PASSWORD", "secret")
+	identities := []atest.Identity{{
+		Name:     "alice",
+		Provider: "keycloak",
+		API:      server.URL,
+		Options:  map[string]string{"realm": "test", "adminUsername": "admin", "adminPassword": `{{env "ADMIN_PASSWORD"}}`},
+	}}
+	created, err := ProvisionIdentities(context.TODO(), identities)
+	if assert.Nil(t, err) && assert.Equal(t, 1, len(created)) {
+		credentials := created[0].Credentials
+		assert.Equal(t, "1234", credentials["id"])
+		assert.True(t, strings.HasPrefix(credentials["username"], "atest-"))
+		assert.NotEmpty(t, credentials["password"])
+		assert.Equal(t, map[string]string{"1234": credentials["username"]}, users)
+		assert.Equal(t, map[string]interface{}{"alice": credentials}, IdentityContext(created))
+	}
+
+	assert.Nil(t, DeleteIdentities(context.TODO(), created))
+	assert.Empty(t, users)
+
+	// the user does not exist anymore
+	err = DeleteIdentities(context.TODO(), created)
+	assert.Contains(t, err.Error(), "failed to delete the identities, alice: DELETE")
+
+	_, err = ProvisionIdentities(context.TODO(), []atest.Identity{{
+		Name: "bob", Provider: "keycloak", API: server.URL,
+		Options: map[string]string{"realm": "test", "adminUsername": "admin", "adminPassword": "fake"},
+	}})
+	assert.Contains(t, err.Error(), "failed to create the identity bob, failed to get the admin token")
+
+	_, err = ProvisionIdentities(context.TODO(), []atest.Identity{{Name: "bob", Provider: "keycloak"}})
+	assert.EqualError(t, err, "failed to create the identity bob, the realm option is required by keycloak")
+}
+
+func TestHTTPIdentityProvider(t *testing.T) {
+	deleted := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodPost:
+			body := map[string]string{}
+			assert.Nil(t, json.NewDecoder(req.Body).Decode(&body))
+			assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+			assert.Equal(t, "Bearer admin", req.Header.Get("Authorization"))
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"id": 42, "name": body["name"], "roles": []string{"admin"},
+			})
+		case http.MethodDelete:
+			deleted = req.URL.Path
+		}
+	}))
+	defer server.Close()
+
+	created, err := ProvisionIdentities(context.TODO(), []atest.Identity{{
+		Name:     "alice",
+		Provider: "http",
+		Username: "alice",
+		Password: "secret",
+		Create: &atest.IdentityRequest{
+			API:    server.URL + "/users",
+			Header: map[string]string{"Authorization": "Bearer admin"},
+			Body:   `{"name": "{{.username}}", "password": "{{.password}}"}`,
+		},
+		Delete: &atest.IdentityRequest{API: server.URL + "/users/{{.id}}"},
+	}, {
+		Name: "bob", Provider: "http",
+	}})
+	assert.EqualError(t, err, "failed to create the identity bob, the create request is required by the http identity provider")
+	if assert.Equal(t, 1, len(created)) {
+		assert.Equal(t, map[string]string{"id": "42", "name": "alice", "username": "alice", "password": "secret"},
+			created[0].Credentials)
+	}
+
+	assert.Nil(t, DeleteIdentities(context.TODO(), created))
+	assert.Equal(t, "/users/42", deleted)
+
+	_, err = ProvisionIdentities(context.TODO(), []atest.Identity{{Name: "alice", Provider: "fake"}})
+	assert.EqualError(t, err, "not supported identity provider: 'fake', supported: [http keycloak]")
+}
+
+type fakeIdentityProvider struct {
+	deleteErr error
+}
+
+func (p *fakeIdentityProvider) Create(ctx context.Context, identity *atest.Identity) (map[string]string, error) {
+	return map[string]string{"username": identity.Username}, nil
+}
+
+func (p *fakeIdentityProvider) Delete(ctx context.Context, identity *atest.Identity, credentials map[string]string) error {
+	return p.deleteErr
+}
+
+func TestRegisterIdentityProvider(t *testing.T) {
+	RegisterIdentityProvider("fake", &fakeIdentityProvider{deleteErr: errors.New("not found")})
+	defer delete(identityProviders, "fake")
+
+	created, err := ProvisionIdentities(context.TODO(), []atest.Identity{{Name: "a", Provider: "fake", Username: "rick"},
+		{Name: "b", Provider: "fake", Username: "morty"}})
+	assert.Nil(t, err)
+	assert.Equal(t, "rick", created[0].Credentials["username"])
+	assert.EqualError(t, DeleteIdentities(context.TODO(), created), "failed to delete the identities, b: not found; a: not found")
+}
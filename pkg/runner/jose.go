@@ -0,0 +1,181 @@
+package runner
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	jose "github.com/go-jose/go-jose/v3"
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+const (
+	joseTypeJWE = "JWE"
+	joseTypeJWS = "JWS"
+)
+
+var compactJOSEPattern = regexp.MustCompile(`^[A-Za-z0-9_\-.]+$`)
+
+// decodeJOSE returns the payload of the JWE or JWS body, the body is returned as it is if it's neither of them
+func decodeJOSE(body []byte, keys *testing.JOSE) (payload []byte, err error) {
+	payload = body
+	// the signed payload might be encrypted, it's the nested JWT
+	for i := 0; i < 2; i++ {
+		text := strings.TrimSpace(string(payload))
+		switch joseType(text) {
+		case joseTypeJWE:
+			payload, err = decryptJWE(text, keys)
+		case joseTypeJWS:
+			payload, err = verifyJWS(text, keys)
+		default:
+			return
+		}
+
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// joseType tells the type by the shape of the compact or JSON serialization
+func joseType(text string) string {
+	if strings.HasPrefix(text, "{") {
+		fields := map[string]json.RawMessage{}
+		if err := json.Unmarshal([]byte(text), &fields); err == nil {
+			if _, ok := fields["ciphertext"]; ok {
+				return joseTypeJWE
+			} else if _, ok := fields["payload"]; ok {
+				return joseTypeJWS
+			}
+		}
+		return ""
+	}
+
+	if !compactJOSEPattern.MatchString(text) {
+		return ""
+	}
+	switch strings.Count(text, ".") {
+	case 4:
+		return joseTypeJWE
+	case 2:
+		return joseTypeJWS
+	}
+	return ""
+}
+
+func decryptJWE(text string, keys *testing.JOSE) (payload []byte, err error) {
+	var jwe *jose.JSONWebEncryption
+	if jwe, err = jose.ParseEncrypted(text); err != nil {
+		err = fmt.Errorf("invalid JWE, %v", err)
+		return
+	}
+
+	var key interface{}
+	switch {
+	case keys.Key != "":
+		if key, err = loadJOSEKey(keys.Key); err != nil {
+			return
+		}
+	case keys.Secret != "":
+		key = []byte(keys.Secret)
+	default:
+		err = fmt.Errorf("the key or secret is required to decrypt the JWE")
+		return
+	}
+
+	if payload, err = jwe.Decrypt(key); err != nil {
+		err = fmt.Errorf("failed to decrypt the JWE, %v", err)
+	}
+	return
+}
+
+func verifyJWS(text string, keys *testing.JOSE) (payload []byte, err error) {
+	var jws *jose.JSONWebSignature
+	if jws, err = jose.ParseSigned(text); err != nil {
+		err = fmt.Errorf("invalid JWS, %v", err)
+		return
+	}
+
+	var key interface{}
+	switch {
+	case keys.VerifyKey != "":
+		if key, err = loadJOSEKey(keys.VerifyKey); err != nil {
+			return
+		}
+		// the public key is used if it's a private key file
+		if privateKey, ok := key.(interface{ Public() crypto.PublicKey }); ok {
+			key = privateKey.Public()
+		}
+	case keys.Secret != "":
+		key = []byte(keys.Secret)
+	default:
+		err = fmt.Errorf("the verifyKey or secret is required to verify the JWS")
+		return
+	}
+
+	if payload, err = jws.Verify(key); err != nil {
+		err = fmt.Errorf("failed to verify the JWS, %v", err)
+	}
+	return
+}
+
+// loadJOSEKey loads the key from a JWK, JWK Set, or PEM file. The key of a JWK Set is chosen by the key ID of the header
+func loadJOSEKey(file string) (key interface{}, err error) {
+	var data []byte
+	if data, err = os.ReadFile(file); err != nil {
+		return
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		keySet := &jose.JSONWebKeySet{}
+		if err = json.Unmarshal(data, keySet); err == nil && len(keySet.Keys) > 0 {
+			if len(keySet.Keys) == 1 {
+				key = keySet.Keys[0]
+			} else {
+				key = keySet
+			}
+			return
+		}
+
+		jwk := jose.JSONWebKey{}
+		if err = jwk.UnmarshalJSON(data); err != nil {
+			err = fmt.Errorf("invalid JWK %s, %v", file, err)
+		}
+		key = jwk
+		return
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		err = fmt.Errorf("%s is neither a PEM nor a JWK file", file)
+		return
+	}
+
+	switch block.Type {
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+			key = cert.PublicKey
+		}
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		err = fmt.Errorf("unsupported PEM type %q of %s", block.Type, file)
+	}
+	return
+}
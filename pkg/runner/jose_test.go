@@ -0,0 +1,178 @@
+package runner
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	jose "github.com/go-jose/go-jose/v3"
+	"github.com/h2non/gock"
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeJOSE(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	rsaKeyFile := filepath.Join(dir, "rsa.pem")
+	assert.Nil(t, os.WriteFile(rsaKeyFile, pem.EncodeToMemory(&pem.Block{
+		Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}), 0600))
+	ecPublicKey, err := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	assert.Nil(t, err)
+	ecPublicKeyFile := filepath.Join(dir, "ec.pem")
+	assert.Nil(t, os.WriteFile(ecPublicKeyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: ecPublicKey}), 0600))
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: &rsaKey.PublicKey, KeyID: "rsa"},
+		{Key: &ecKey.PublicKey, KeyID: "ec"},
+	}})
+	assert.Nil(t, err)
+	jwksFile := filepath.Join(dir, "jwks.json")
+	assert.Nil(t, os.WriteFile(jwksFile, jwks, 0600))
+	invalidFile := filepath.Join(dir, "invalid.txt")
+	assert.Nil(t, os.WriteFile(invalidFile, []byte("fake"), 0600))
+
+	secret := "0123456789abcdef0123456789abcdef"
+	payload := `{"name":"rick"}`
+	encrypt := func(key interface{}, alg jose.KeyAlgorithm, text string, full bool) string {
+		encrypter, err := jose.NewEncrypter(jose.A256GCM, jose.Recipient{Algorithm: alg, Key: key}, nil)
+		assert.Nil(t, err)
+		jwe, err := encrypter.Encrypt([]byte(text))
+		assert.Nil(t, err)
+		if full {
+			return jwe.FullSerialize()
+		}
+		result, err := jwe.CompactSerialize()
+		assert.Nil(t, err)
+		return result
+	}
+	sign := func(key interface{}, alg jose.SignatureAlgorithm, kid string) string {
+		signer, err := jose.NewSigner(jose.SigningKey{Algorithm: alg, Key: key},
+			(&jose.SignerOptions{}).WithHeader(jose.HeaderKey("kid"), kid))
+		assert.Nil(t, err)
+		jws, err := signer.Sign([]byte(payload))
+		assert.Nil(t, err)
+		result, err := jws.CompactSerialize()
+		assert.Nil(t, err)
+		return result
+	}
+
+	tests := []struct {
+		name      string
+		body      string
+		keys      *atest.JOSE
+		expectErr string
+	}{{
+		name: "not a JOSE body",
+		body: payload,
+		keys: &atest.JOSE{},
+	}, {
+		name: "RSA-OAEP JWE",
+		body: encrypt(&rsaKey.PublicKey, jose.RSA_OAEP_256, payload, false),
+		keys: &atest.JOSE{Key: rsaKeyFile},
+	}, {
+		name: "dir JWE in the JSON serialization",
+		body: encrypt([]byte(secret), jose.DIRECT, payload, true),
+		keys: &atest.JOSE{Secret: secret},
+	}, {
+		name: "JWS verified by the public key",
+		body: sign(ecKey, jose.ES256, ""),
+		keys: &atest.JOSE{VerifyKey: ecPublicKeyFile},
+	}, {
+		name: "JWS verified by the JWK Set",
+		body: sign(ecKey, jose.ES256, "ec") + "\n",
+		keys: &atest.JOSE{VerifyKey: jwksFile},
+	}, {
+		name: "HMAC JWS",
+		body: sign([]byte(secret), jose.HS256, ""),
+		keys: &atest.JOSE{Secret: secret},
+	}, {
+		name: "JWS nested in the JWE",
+		body: encrypt(&rsaKey.PublicKey, jose.RSA_OAEP, sign(rsaKey, jose.RS256, "rsa"), false),
+		keys: &atest.JOSE{Key: rsaKeyFile, VerifyKey: rsaKeyFile},
+	}, {
+		name:      "no key of the JWE",
+		body:      encrypt([]byte(secret), jose.DIRECT, payload, false),
+		keys:      &atest.JOSE{},
+		expectErr: "the key or secret is required to decrypt the JWE",
+	}, {
+		name:      "wrong secret of the JWE",
+		body:      encrypt([]byte(secret), jose.A256KW, payload, false),
+		keys:      &atest.JOSE{Secret: "fedcba9876543210fedcba9876543210"},
+		expectErr: "failed to decrypt the JWE",
+	}, {
+		name:      "no key of the JWS",
+		body:      sign([]byte(secret), jose.HS256, ""),
+		keys:      &atest.JOSE{},
+		expectErr: "the verifyKey or secret is required to verify the JWS",
+	}, {
+		name:      "wrong key of the JWS",
+		body:      sign(rsaKey, jose.RS256, "rsa"),
+		keys:      &atest.JOSE{VerifyKey: ecPublicKeyFile},
+		expectErr: "failed to verify the JWS",
+	}, {
+		name:      "invalid JWS",
+		body:      "a.b.c",
+		keys:      &atest.JOSE{Secret: secret},
+		expectErr: "invalid JWS",
+	}, {
+		name:      "not found the key file",
+		body:      sign([]byte(secret), jose.HS256, ""),
+		keys:      &atest.JOSE{VerifyKey: filepath.Join(dir, "fake.pem")},
+		expectErr: "fake.pem",
+	}, {
+		name:      "invalid key file",
+		body:      encrypt([]byte(secret), jose.DIRECT, payload, false),
+		keys:      &atest.JOSE{Key: invalidFile},
+		expectErr: "is neither a PEM nor a JWK file",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := decodeJOSE([]byte(tt.body), tt.keys)
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+				assert.Equal(t, payload, string(result))
+			} else if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestRunTestCaseWithJOSE(t *testing.T) {
+	defer gock.Off()
+
+	secret := "0123456789abcdef0123456789abcdef"
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.HS256, Key: []byte(secret)}, nil)
+	assert.Nil(t, err)
+	jws, err := signer.Sign([]byte(`{"name":"rick"}`))
+	assert.Nil(t, err)
+	body, err := jws.CompactSerialize()
+	assert.Nil(t, err)
+
+	gock.New("http://localhost").Get("/users/rick").Reply(http.StatusOK).
+		SetHeader("Content-Type", "application/jose").BodyString(body)
+
+	output, err := NewSimpleTestCaseRunner().RunTestCase(&atest.TestCase{
+		Name:    "signed",
+		Request: atest.Request{API: "http://localhost/users/rick"},
+		Expect: atest.Response{
+			BodyFieldsExpect: map[string]interface{}{"name": "rick"},
+			JOSE:             &atest.JOSE{Secret: secret},
+		},
+	}, nil, context.TODO())
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "rick"}, output)
+}
@@ -0,0 +1,121 @@
+package runner
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/andreyvit/diff"
+)
+
+// jsonDiff is the structured difference of the expected and the actual JSON values, the paths are separated
+// by slashes like the bodyFieldsExpect, such as: items/0/name
+type jsonDiff struct {
+	Added   []jsonDiffItem   `json:"added,omitempty"`
+	Removed []jsonDiffItem   `json:"removed,omitempty"`
+	Changed []jsonDiffChange `json:"changed,omitempty"`
+}
+
+// jsonDiffItem is a field which is only in one side
+type jsonDiffItem struct {
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// jsonDiffChange is a field which has the different values
+type jsonDiffChange struct {
+	Path   string      `json:"path"`
+	Expect interface{} `json:"expect"`
+	Actual interface{} `json:"actual"`
+}
+
+// bodyDiff returns the structured difference if both bodies are JSON, or the difference of the lines
+func bodyDiff(expect string, actual []byte) string {
+	var expectVal, actualVal interface{}
+	if json.Unmarshal([]byte(expect), &expectVal) == nil && json.Unmarshal(actual, &actualVal) == nil {
+		if result := diffJSON("", expectVal, actualVal); !result.empty() {
+			return result.String()
+		}
+	}
+	return diff.LineDiff(expect, string(actual))
+}
+
+// fieldValueDiff returns the structured difference of the object or array field, it's nil for the scalar fields
+func fieldValueDiff(key string, expect, actual interface{}) *jsonDiff {
+	expect = normalizeJSONValues([]interface{}{expect})[0]
+	switch expect.(type) {
+	case map[string]interface{}, []interface{}:
+		if result := diffJSON(key, expect, actual); !result.empty() {
+			return result
+		}
+	}
+	return nil
+}
+
+// diffJSON compares the decoded JSON values, the fields which are not expected are added,
+// the expected ones which are missing are removed
+func diffJSON(fieldPath string, expect, actual interface{}) (result *jsonDiff) {
+	result = &jsonDiff{}
+	result.compare(fieldPath, expect, actual)
+	return
+}
+
+func (d *jsonDiff) empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+func (d *jsonDiff) String() string {
+	data, _ := json.MarshalIndent(d, "", "  ")
+	return string(data)
+}
+
+func (d *jsonDiff) compare(fieldPath string, expect, actual interface{}) {
+	switch expectVal := expect.(type) {
+	case map[string]interface{}:
+		if actualVal, ok := actual.(map[string]interface{}); ok {
+			for _, key := range sortedKeys(expectVal) {
+				if item, found := actualVal[key]; found {
+					d.compare(joinFieldPath(fieldPath, key), expectVal[key], item)
+				} else {
+					d.Removed = append(d.Removed, jsonDiffItem{Path: joinFieldPath(fieldPath, key), Value: expectVal[key]})
+				}
+			}
+			for _, key := range sortedKeys(actualVal) {
+				if _, found := expectVal[key]; !found {
+					d.Added = append(d.Added, jsonDiffItem{Path: joinFieldPath(fieldPath, key), Value: actualVal[key]})
+				}
+			}
+			return
+		}
+	case []interface{}:
+		if actualVal, ok := actual.([]interface{}); ok {
+			for i := range expectVal {
+				if i < len(actualVal) {
+					d.compare(joinFieldPath(fieldPath, strconv.Itoa(i)), expectVal[i], actualVal[i])
+				} else {
+					d.Removed = append(d.Removed, jsonDiffItem{Path: joinFieldPath(fieldPath, strconv.Itoa(i)), Value: expectVal[i]})
+				}
+			}
+			for i := len(expectVal); i < len(actualVal); i++ {
+				d.Added = append(d.Added, jsonDiffItem{Path: joinFieldPath(fieldPath, strconv.Itoa(i)), Value: actualVal[i]})
+			}
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(expect, actual) {
+		if fieldPath == "" {
+			fieldPath = "(root)"
+		}
+		d.Changed = append(d.Changed, jsonDiffChange{Path: fieldPath, Expect: expect, Actual: actual})
+	}
+}
+
+func sortedKeys(data map[string]interface{}) (keys []string) {
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return
+}
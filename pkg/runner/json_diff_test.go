@@ -0,0 +1,133 @@
+package runner
+
+import (
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect interface{}
+		actual interface{}
+		diff   *jsonDiff
+	}{{
+		name:   "same",
+		expect: map[string]interface{}{"name": "rick", "tags": []interface{}{"a"}},
+		actual: map[string]interface{}{"name": "rick", "tags": []interface{}{"a"}},
+		diff:   &jsonDiff{},
+	}, {
+		name: "objects",
+		expect: map[string]interface{}{
+			"name": "rick", "age": float64(70),
+			"address": map[string]interface{}{"city": "Seattle"},
+		},
+		actual: map[string]interface{}{
+			"name": "morty", "email": "morty@example.com",
+			"address": map[string]interface{}{"city": "Seattle", "zip": "98101"},
+		},
+		diff: &jsonDiff{
+			Added: []jsonDiffItem{
+				{Path: "address/zip", Value: "98101"},
+				{Path: "email", Value: "morty@example.com"},
+			},
+			Removed: []jsonDiffItem{{Path: "age", Value: float64(70)}},
+			Changed: []jsonDiffChange{{Path: "name", Expect: "rick", Actual: "morty"}},
+		},
+	}, {
+		name:   "arrays",
+		expect: []interface{}{"a", "b", "c"},
+		actual: []interface{}{"a", "x"},
+		diff: &jsonDiff{
+			Removed: []jsonDiffItem{{Path: "2", Value: "c"}},
+			Changed: []jsonDiffChange{{Path: "1", Expect: "b", Actual: "x"}},
+		},
+	}, {
+		name:   "longer array",
+		expect: []interface{}{},
+		actual: []interface{}{map[string]interface{}{"id": float64(1)}},
+		diff:   &jsonDiff{Added: []jsonDiffItem{{Path: "0", Value: map[string]interface{}{"id": float64(1)}}}},
+	}, {
+		name:   "different types",
+		expect: map[string]interface{}{"items": []interface{}{}},
+		actual: map[string]interface{}{"items": nil},
+		diff:   &jsonDiff{Changed: []jsonDiffChange{{Path: "items", Expect: []interface{}{}, Actual: nil}}},
+	}, {
+		name:   "root",
+		expect: "a",
+		actual: float64(1),
+		diff:   &jsonDiff{Changed: []jsonDiffChange{{Path: "(root)", Expect: "a", Actual: float64(1)}}},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.diff, diffJSON("", tt.expect, tt.actual))
+		})
+	}
+}
+
+func TestVerifyResponseBodyDiff(t *testing.T) {
+	tests := []struct {
+		name      string
+		expect    atest.Response
+		body      string
+		expectErr string
+	}{{
+		name:   "JSON body",
+		expect: atest.Response{Body: `{"name": "rick", "items": [1, 2]}`},
+		body:   `{"name":"morty","items":[1],"age":14}`,
+		expectErr: `case: diff, got different response body, diff: 
+{
+  "added": [
+    {
+      "path": "age",
+      "value": 14
+    }
+  ],
+  "removed": [
+    {
+      "path": "items/1",
+      "value": 2
+    }
+  ],
+  "changed": [
+    {
+      "path": "name",
+      "expect": "rick",
+      "actual": "morty"
+    }
+  ]
+}`,
+	}, {
+		name:      "the same JSON in different format",
+		expect:    atest.Response{Body: `{"name": "rick"}`},
+		body:      `{"name":"rick"}`,
+		expectErr: "case: diff, got different response body, diff: \n-{\"name\": \"rick\"}\n+{\"name\":\"rick\"}",
+	}, {
+		name:   "object field",
+		expect: atest.Response{BodyFieldsExpect: map[string]interface{}{"user": map[string]interface{}{"name": "rick", "age": 70}}},
+		body:   `{"user":{"name":"rick","age":14}}`,
+		expectErr: `field[user] got different value, diff: 
+{
+  "changed": [
+    {
+      "path": "user/age",
+      "expect": 70,
+      "actual": 14
+    }
+  ]
+}`,
+	}, {
+		name:      "scalar field",
+		expect:    atest.Response{BodyFieldsExpect: map[string]interface{}{"user/name": "morty"}},
+		body:      `{"user":{"name":"rick"}}`,
+		expectErr: "field[user/name] expect value: morty, actual: rick",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifyResponseBodyData("diff", tt.expect, []byte(tt.body))
+			assert.EqualError(t, err, tt.expectErr)
+		})
+	}
+}
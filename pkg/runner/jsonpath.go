@@ -0,0 +1,140 @@
+package runner
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/PaesslerAG/jsonpath"
+)
+
+// verifyJSONPath evaluates the JSONPath expressions against the decoded body, such as: $.items[0].id,
+// the expressions which select multiple values like $.items[*].id are compared with the arrays.
+// The numbers could be compared by the conditions, such as: gt 0, lt 100 or approx 250 ±1%
+func verifyJSONPath(caseName string, expect map[string]interface{}, body interface{}) (err error) {
+	for path, expectVal := range expect {
+		var val interface{}
+		if val, err = jsonpath.Get(path, body); err != nil {
+			err = fmt.Errorf("case: %s, failed to get the JSONPath %s, %v", caseName, path, err)
+			return
+		}
+		if conditions, ok := parseNumericConditions(expectVal); ok && isNumbers(val) {
+			if !matchNumericConditions(conditions, val) {
+				err = fmt.Errorf("case: %s, JSONPath[%s] expect value: %v, actual: %v", caseName, path, expectVal, val)
+				return
+			}
+		} else if !fieldValueEqual(expectVal, val) {
+			err = fmt.Errorf("case: %s, JSONPath[%s] expect value: %v, actual: %v", caseName, path, expectVal, val)
+			return
+		}
+	}
+	return
+}
+
+// fieldValueEqual compares the expected value with the decoded one, the integers are equal to the same float numbers
+func fieldValueEqual(expect, actual interface{}) bool {
+	if reflect.DeepEqual(expect, actual) {
+		return true
+	}
+	return expect != nil && reflect.TypeOf(expect).Kind() == reflect.Int && fmt.Sprintf("%v", expect) == fmt.Sprintf("%v", actual)
+}
+
+// numericCondition compares a number with the value, the tolerance of approx is absolute or a percentage of the value
+type numericCondition struct {
+	operator  string
+	value     float64
+	tolerance float64
+	percent   bool
+}
+
+var numericOperators = map[string]string{
+	">": "gt", ">=": "gte", "<": "lt", "<=": "lte", "~": "approx",
+	"gt": "gt", "gte": "gte", "lt": "lt", "lte": "lte", "approx": "approx",
+}
+
+// parseNumericConditions parses the comma separated conditions, such as: gt 0, lt 100 or approx 250 ±1%.
+// It's not a condition if any part of it is invalid
+func parseNumericConditions(expect interface{}) (conditions []numericCondition, ok bool) {
+	text, isString := expect.(string)
+	if !isString {
+		return
+	}
+
+	for _, item := range strings.Split(text, ",") {
+		fields := strings.Fields(strings.NewReplacer("±", " ± ", "+-", " ± ").Replace(item))
+		if len(fields) != 2 && len(fields) != 4 {
+			return nil, false
+		}
+
+		condition := numericCondition{operator: numericOperators[fields[0]]}
+		var err error
+		if condition.value, err = strconv.ParseFloat(fields[1], 64); err != nil || condition.operator == "" {
+			return nil, false
+		}
+
+		if len(fields) == 4 {
+			if condition.operator != "approx" || fields[2] != "±" {
+				return nil, false
+			}
+			tolerance := fields[3]
+			condition.percent = strings.HasSuffix(tolerance, "%")
+			if condition.tolerance, err = strconv.ParseFloat(strings.TrimSuffix(tolerance, "%"), 64); err != nil {
+				return nil, false
+			}
+		}
+		conditions = append(conditions, condition)
+	}
+	ok = len(conditions) > 0
+	return
+}
+
+// isNumbers returns true if the value is a number, or a non-empty array of numbers
+func isNumbers(val interface{}) bool {
+	if items, ok := val.([]interface{}); ok {
+		for _, item := range items {
+			if _, ok := item.(float64); !ok {
+				return false
+			}
+		}
+		return len(items) > 0
+	}
+	_, ok := val.(float64)
+	return ok
+}
+
+// matchNumericConditions returns true if the number, or all the numbers of an array match the conditions
+func matchNumericConditions(conditions []numericCondition, val interface{}) bool {
+	numbers, ok := val.([]interface{})
+	if !ok {
+		numbers = []interface{}{val}
+	}
+	for _, number := range numbers {
+		for _, condition := range conditions {
+			if !condition.match(number.(float64)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (c numericCondition) match(actual float64) bool {
+	switch c.operator {
+	case "gt":
+		return actual > c.value
+	case "gte":
+		return actual >= c.value
+	case "lt":
+		return actual < c.value
+	case "lte":
+		return actual <= c.value
+	default:
+		tolerance := c.tolerance
+		if c.percent {
+			tolerance = math.Abs(c.value) * c.tolerance / 100
+		}
+		return math.Abs(actual-c.value) <= tolerance
+	}
+}
@@ -0,0 +1,112 @@
+package runner
+
+import (
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyJSONPath(t *testing.T) {
+	body := []byte(`{"items": [{"id": 42, "name": "rick", "tags": ["admin"]}, {"id": 43, "name": "morty"}], "total": 2}`)
+
+	tests := []struct {
+		name      string
+		body      []byte
+		jsonPath  map[string]interface{}
+		expectErr string
+	}{{
+		name: "expected values",
+		body: body,
+		jsonPath: map[string]interface{}{
+			"$.items[0].id":                   float64(42),
+			"$.items[1].name":                 "morty",
+			"$.items[0].tags":                 []interface{}{"admin"},
+			"$.total":                         2,
+			"$.items[*].id":                   []interface{}{float64(42), float64(43)},
+			`$.items[?(@.name == "rick")].id`: []interface{}{float64(42)},
+		},
+	}, {
+		name:     "the array body",
+		body:     []byte(`[{"id": 1}]`),
+		jsonPath: map[string]interface{}{"$[0].id": 1},
+	}, {
+		name:      "unexpected value",
+		body:      body,
+		jsonPath:  map[string]interface{}{"$.items[0].name": "morty"},
+		expectErr: "JSONPath[$.items[0].name] expect value: morty, actual: rick",
+	}, {
+		name:      "not found",
+		body:      body,
+		jsonPath:  map[string]interface{}{"$.items[0].fake": "rick"},
+		expectErr: "failed to get the JSONPath $.items[0].fake",
+	}, {
+		name: "numeric conditions",
+		body: []byte(`{"cpu": 42.5, "latency": 251, "counts": [1, 5, 9], "version": "> 1"}`),
+		jsonPath: map[string]interface{}{
+			"$.cpu":       "gt 0, lt 100",
+			"$.latency":   "approx 250 ±1%",
+			"$.counts":    ">= 1, <= 9",
+			"$.counts[0]": "~ 2 +- 1",
+			"$.version":   "> 1",
+		},
+	}, {
+		name:      "not match the conditions",
+		body:      []byte(`{"latency": 260}`),
+		jsonPath:  map[string]interface{}{"$.latency": "approx 250 ±1%"},
+		expectErr: "JSONPath[$.latency] expect value: approx 250 ±1%, actual: 260",
+	}, {
+		name:      "not all the numbers match",
+		body:      []byte(`{"counts": [1, 10]}`),
+		jsonPath:  map[string]interface{}{"$.counts": "lt 10"},
+		expectErr: "JSONPath[$.counts] expect value: lt 10, actual: [1 10]",
+	}, {
+		name:      "invalid JSONPath",
+		body:      body,
+		jsonPath:  map[string]interface{}{"$.items[": "rick"},
+		expectErr: "failed to get the JSONPath",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifyResponseBodyData("case", atest.Response{JSONPath: tt.jsonPath}, tt.body)
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestParseNumericConditions(t *testing.T) {
+	tests := []struct {
+		expect     interface{}
+		conditions []numericCondition
+	}{{
+		expect:     "gt 0, lte 1.5",
+		conditions: []numericCondition{{operator: "gt"}, {operator: "lte", value: 1.5}},
+	}, {
+		expect:     "approx -10 ±0.5",
+		conditions: []numericCondition{{operator: "approx", value: -10, tolerance: 0.5}},
+	}, {
+		expect:     "approx 100±2%",
+		conditions: []numericCondition{{operator: "approx", value: 100, tolerance: 2, percent: true}},
+	}, {
+		expect: 1,
+	}, {
+		expect: "rick",
+	}, {
+		expect: "gt rick",
+	}, {
+		expect: "gt 1 ± 1",
+	}, {
+		expect: "approx 1 ± rick",
+	}, {
+		expect: "gt 1, rick",
+	}}
+	for _, tt := range tests {
+		conditions, ok := parseNumericConditions(tt.expect)
+		assert.Equal(t, tt.conditions != nil, ok, tt.expect)
+		assert.Equal(t, tt.conditions, conditions, tt.expect)
+	}
+}
@@ -0,0 +1,84 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+type jsonRPCResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int             `json:"code"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data,omitempty"`
+	} `json:"error"`
+}
+
+// verifyJSONRPCResponse verifies the id and error of the JSON-RPC response, then returns the result part,
+// or the error part if the error is expected
+func verifyJSONRPCResponse(caseName string, request *testing.JSONRPCRequest, expect testing.Response,
+	body []byte) (data []byte, err error) {
+	response := &jsonRPCResponse{}
+	if err = json.Unmarshal(body, response); err != nil {
+		err = fmt.Errorf("case: %s, not a valid JSON-RPC response, %v", caseName, err)
+		return
+	}
+
+	if err = matchJSONRPCID(request.ID, response.ID); err != nil {
+		err = fmt.Errorf("case: %s, %v", caseName, err)
+		return
+	}
+
+	expectErr := expect.JSONRPCError
+	switch {
+	case response.Error == nil && expectErr != nil:
+		err = fmt.Errorf("case: %s, expect JSON-RPC error: %d %s, actual: none", caseName, expectErr.Code, expectErr.Message)
+		return
+	case response.Error != nil && expectErr == nil:
+		err = fmt.Errorf("case: %s, got JSON-RPC error: %d %s", caseName, response.Error.Code, response.Error.Message)
+		return
+	case response.Error != nil:
+		if (expectErr.Code != 0 && expectErr.Code != response.Error.Code) ||
+			!strings.Contains(response.Error.Message, expectErr.Message) {
+			err = fmt.Errorf("case: %s, expect JSON-RPC error: %d %s, actual: %d %s", caseName,
+				expectErr.Code, expectErr.Message, response.Error.Code, response.Error.Message)
+			return
+		}
+		data, err = json.Marshal(response.Error)
+		return
+	}
+
+	data = response.Result
+	if len(data) == 0 {
+		data = []byte("null")
+	}
+	return
+}
+
+// matchJSONRPCID compares the ids as JSON values, so the number 1 equals to 1.0
+func matchJSONRPCID(expect interface{}, actual json.RawMessage) (err error) {
+	var expectData []byte
+	if expectData, err = json.Marshal(expect); err != nil {
+		return
+	}
+
+	var expectID, actualID interface{}
+	if err = json.Unmarshal(expectData, &expectID); err != nil {
+		return
+	}
+	if len(actual) > 0 {
+		if err = json.Unmarshal(actual, &actualID); err != nil {
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(expectID, actualID) {
+		err = fmt.Errorf("expect JSON-RPC id: %s, actual: %s", string(expectData), string(actual))
+	}
+	return
+}
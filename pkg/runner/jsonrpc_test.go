@@ -0,0 +1,76 @@
+package runner
+
+import (
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyJSONRPCResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		request *atest.JSONRPCRequest
+		expect  atest.Response
+		body    string
+		data    string
+		hasErr  bool
+	}{{
+		name:    "only result",
+		request: &atest.JSONRPCRequest{ID: 1},
+		body:    `{"jsonrpc":"2.0","result":{"name":"linuxsuren"},"id":1}`,
+		data:    `{"name":"linuxsuren"}`,
+	}, {
+		name:    "string id",
+		request: &atest.JSONRPCRequest{ID: "abc"},
+		body:    `{"jsonrpc":"2.0","result":19,"id":"abc"}`,
+		data:    `19`,
+	}, {
+		name:    "mismatched id",
+		request: &atest.JSONRPCRequest{ID: 1},
+		body:    `{"jsonrpc":"2.0","result":19,"id":2}`,
+		hasErr:  true,
+	}, {
+		name:    "unexpected error",
+		request: &atest.JSONRPCRequest{ID: 1},
+		body:    `{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found"},"id":1}`,
+		hasErr:  true,
+	}, {
+		name:    "expected error",
+		request: &atest.JSONRPCRequest{ID: 1},
+		expect:  atest.Response{JSONRPCError: &atest.JSONRPCError{Code: -32601, Message: "not found"}},
+		body:    `{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found"},"id":1}`,
+		data:    `{"code":-32601,"message":"Method not found"}`,
+	}, {
+		name:    "expected error without the id",
+		request: &atest.JSONRPCRequest{ID: 1},
+		expect:  atest.Response{JSONRPCError: &atest.JSONRPCError{Code: -32700}},
+		body:    `{"jsonrpc":"2.0","error":{"code":-32700,"message":"Parse error"},"id":null}`,
+		hasErr:  true,
+	}, {
+		name:    "different error code",
+		request: &atest.JSONRPCRequest{ID: 1},
+		expect:  atest.Response{JSONRPCError: &atest.JSONRPCError{Code: -32602}},
+		body:    `{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found"},"id":1}`,
+		hasErr:  true,
+	}, {
+		name:    "missing the expected error",
+		request: &atest.JSONRPCRequest{ID: 1},
+		expect:  atest.Response{JSONRPCError: &atest.JSONRPCError{Message: "not found"}},
+		body:    `{"jsonrpc":"2.0","result":null,"id":1}`,
+		hasErr:  true,
+	}, {
+		name:    "not a JSON",
+		request: &atest.JSONRPCRequest{ID: 1},
+		body:    "fake",
+		hasErr:  true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := verifyJSONRPCResponse("test", tt.request, tt.expect, []byte(tt.body))
+			if assert.Equal(t, tt.hasErr, err != nil, err) && !tt.hasErr {
+				assert.JSONEq(t, tt.data, string(data))
+			}
+		})
+	}
+}
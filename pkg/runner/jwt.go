@@ -0,0 +1,198 @@
+package runner
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// jsonWebToken is a parsed JWS in the compact serialization
+type jsonWebToken struct {
+	Header       map[string]interface{}
+	Claims       map[string]interface{}
+	signingInput string
+	signature    []byte
+}
+
+// jsonWebKey is a public key of the JWK Set, see also RFC 7517
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	// the RSA key
+	N string `json:"n"`
+	E string `json:"e"`
+	// the EC key
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// jsonWebKeySet is the response of the jwks_uri
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+var jwtHashes = map[string]crypto.Hash{
+	"256": crypto.SHA256,
+	"384": crypto.SHA384,
+	"512": crypto.SHA512,
+}
+
+// isJWT returns true if the token looks like a JWS in the compact serialization
+func isJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// parseJWT decodes the header and claims of the token without verifying the signature
+func parseJWT(token string) (jwt *jsonWebToken, err error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		err = fmt.Errorf("not a valid JWT, expect 3 parts, actual %d", len(parts))
+		return
+	}
+
+	jwt = &jsonWebToken{signingInput: parts[0] + "." + parts[1]}
+	if err = decodeJWTPart(parts[0], &jwt.Header); err != nil {
+		err = fmt.Errorf("invalid JWT header, %v", err)
+		return
+	}
+	if err = decodeJWTPart(parts[1], &jwt.Claims); err != nil {
+		err = fmt.Errorf("invalid JWT claims, %v", err)
+		return
+	}
+	if jwt.signature, err = base64.RawURLEncoding.DecodeString(parts[2]); err != nil {
+		err = fmt.Errorf("invalid JWT signature, %v", err)
+	}
+	return
+}
+
+func decodeJWTPart(part string, object interface{}) (err error) {
+	var data []byte
+	if data, err = base64.RawURLEncoding.DecodeString(part); err == nil {
+		err = json.Unmarshal(data, object)
+	}
+	return
+}
+
+// verify checks the signature with the key set, the secret is used by the HMAC algorithms
+func (t *jsonWebToken) verify(keySet *jsonWebKeySet, secret string) (err error) {
+	alg, _ := t.Header["alg"].(string)
+	if len(alg) != 5 {
+		err = fmt.Errorf("unsupported JWT algorithm %q", alg)
+		return
+	}
+	hash, ok := jwtHashes[alg[2:]]
+	if !ok {
+		err = fmt.Errorf("unsupported JWT algorithm %q", alg)
+		return
+	}
+
+	digest := hash.New()
+	_, _ = digest.Write([]byte(t.signingInput))
+	hashed := digest.Sum(nil)
+
+	switch alg[:2] {
+	case "HS":
+		mac := hmac.New(hash.New, []byte(secret))
+		_, _ = mac.Write([]byte(t.signingInput))
+		if !hmac.Equal(mac.Sum(nil), t.signature) {
+			err = fmt.Errorf("invalid JWT signature")
+		}
+	case "RS", "PS":
+		var key *rsa.PublicKey
+		if key, err = t.findKey(keySet).rsaPublicKey(); err != nil {
+			return
+		}
+		if alg[:2] == "RS" {
+			err = rsa.VerifyPKCS1v15(key, hash, hashed, t.signature)
+		} else {
+			err = rsa.VerifyPSS(key, hash, hashed, t.signature, nil)
+		}
+	case "ES":
+		var key *ecdsa.PublicKey
+		if key, err = t.findKey(keySet).ecdsaPublicKey(); err != nil {
+			return
+		}
+		size := len(t.signature) / 2
+		r := new(big.Int).SetBytes(t.signature[:size])
+		s := new(big.Int).SetBytes(t.signature[size:])
+		if !ecdsa.Verify(key, hashed, r, s) {
+			err = fmt.Errorf("invalid JWT signature")
+		}
+	default:
+		err = fmt.Errorf("unsupported JWT algorithm %q", alg)
+	}
+	return
+}
+
+// findKey returns the key which has the same key ID, or the first key if the token has no key ID
+func (t *jsonWebToken) findKey(keySet *jsonWebKeySet) *jsonWebKey {
+	if keySet == nil {
+		return nil
+	}
+
+	kid, _ := t.Header["kid"].(string)
+	for i, key := range keySet.Keys {
+		if kid == "" || key.Kid == kid {
+			return &keySet.Keys[i]
+		}
+	}
+	return nil
+}
+
+func (k *jsonWebKey) rsaPublicKey() (key *rsa.PublicKey, err error) {
+	if k == nil || k.Kty != "RSA" {
+		err = fmt.Errorf("no RSA key is found to verify the JWT")
+		return
+	}
+
+	var n, e []byte
+	if n, err = base64.RawURLEncoding.DecodeString(k.N); err != nil {
+		return
+	}
+	if e, err = base64.RawURLEncoding.DecodeString(k.E); err != nil {
+		return
+	}
+	key = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	return
+}
+
+func (k *jsonWebKey) ecdsaPublicKey() (key *ecdsa.PublicKey, err error) {
+	if k == nil || k.Kty != "EC" {
+		err = fmt.Errorf("no EC key is found to verify the JWT")
+		return
+	}
+
+	var curve elliptic.Curve
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		err = fmt.Errorf("unsupported EC curve %q", k.Crv)
+		return
+	}
+
+	var x, y []byte
+	if x, err = base64.RawURLEncoding.DecodeString(k.X); err != nil {
+		return
+	}
+	if y, err = base64.RawURLEncoding.DecodeString(k.Y); err != nil {
+		return
+	}
+	key = &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	return
+}
@@ -0,0 +1,119 @@
+package runner
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONWebToken(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	keySet := &jsonWebKeySet{Keys: []jsonWebKey{{
+		Kty: "EC",
+		Kid: "ec",
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(ecKey.X.Bytes()),
+		Y:   base64.RawURLEncoding.EncodeToString(ecKey.Y.Bytes()),
+	}, {
+		Kty: "RSA",
+		Kid: "rsa",
+		N:   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+	}}}
+
+	sign := func(header map[string]interface{}, signer func(input []byte) []byte) string {
+		headerData, _ := json.Marshal(header)
+		input := base64.RawURLEncoding.EncodeToString(headerData) + "." +
+			base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"linuxsuren"}`))
+		return input + "." + base64.RawURLEncoding.EncodeToString(signer([]byte(input)))
+	}
+	rsaSigner := func(input []byte) []byte {
+		hashed := sha256.Sum256(input)
+		signature, _ := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, hashed[:])
+		return signature
+	}
+	pssSigner := func(input []byte) []byte {
+		hashed := sha256.Sum256(input)
+		signature, _ := rsa.SignPSS(rand.Reader, rsaKey, crypto.SHA256, hashed[:], nil)
+		return signature
+	}
+	ecSigner := func(input []byte) []byte {
+		hashed := sha256.Sum256(input)
+		r, s, _ := ecdsa.Sign(rand.Reader, ecKey, hashed[:])
+		signature := make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+		return signature
+	}
+	hmacSigner := func(input []byte) []byte {
+		mac := hmac.New(sha256.New, []byte("secret"))
+		_, _ = mac.Write(input)
+		return mac.Sum(nil)
+	}
+
+	tests := []struct {
+		name      string
+		token     string
+		expectErr string
+	}{{
+		name:  "RS256",
+		token: sign(map[string]interface{}{"alg": "RS256", "kid": "rsa"}, rsaSigner),
+	}, {
+		name:  "PS256",
+		token: sign(map[string]interface{}{"alg": "PS256", "kid": "rsa"}, pssSigner),
+	}, {
+		name:  "ES256 without the key ID",
+		token: sign(map[string]interface{}{"alg": "ES256"}, ecSigner),
+	}, {
+		name:  "HS256",
+		token: sign(map[string]interface{}{"alg": "HS256"}, hmacSigner),
+	}, {
+		name:      "invalid signature",
+		token:     sign(map[string]interface{}{"alg": "ES256", "kid": "ec"}, hmacSigner),
+		expectErr: "invalid JWT signature",
+	}, {
+		name:      "key not found",
+		token:     sign(map[string]interface{}{"alg": "RS256", "kid": "fake"}, rsaSigner),
+		expectErr: "no RSA key is found to verify the JWT",
+	}, {
+		name:      "unsupported algorithm",
+		token:     sign(map[string]interface{}{"alg": "none"}, hmacSigner),
+		expectErr: `unsupported JWT algorithm "none"`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, isJWT(tt.token))
+			jwt, err := parseJWT(tt.token)
+			if !assert.Nil(t, err) {
+				return
+			}
+			assert.Equal(t, map[string]interface{}{"sub": "linuxsuren"}, jwt.Claims)
+
+			err = jwt.verify(keySet, "secret")
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expectErr)
+			}
+		})
+	}
+
+	_, err = parseJWT("a.b")
+	assert.NotNil(t, err)
+	_, err = parseJWT("a.b.c")
+	assert.NotNil(t, err)
+}
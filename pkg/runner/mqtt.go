@@ -0,0 +1,150 @@
+package runner
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+// defaultMQTTTimeout is the timeout of the MQTT steps if it's not set
+const defaultMQTTTimeout = 10 * time.Second
+
+type mqttTestCaseRunner struct {
+	*simpleTestCaseRunner
+}
+
+// NewMQTTTestCaseRunner creates the instance of the MQTT test case runner
+func NewMQTTTestCaseRunner() TestCaseRunner {
+	runner := &mqttTestCaseRunner{simpleTestCaseRunner: &simpleTestCaseRunner{}}
+	return runner.embeddedBy(runner)
+}
+
+// RunTestCase publishes the body to the broker, then verifies the messages of the subscribed topic
+func (r *mqttTestCaseRunner) RunTestCase(testcase *testing.TestCase, dataContext interface{}, ctx context.Context) (output interface{}, err error) {
+	return r.runTestCaseWith(testcase, func(record *ReportRecord) (interface{}, error) {
+		return r.doMQTTRequest(testcase, dataContext, ctx, record)
+	})
+}
+
+func (r *mqttTestCaseRunner) doMQTTRequest(testcase *testing.TestCase, dataContext interface{}, ctx context.Context,
+	record *ReportRecord) (output interface{}, err error) {
+	if err = testcase.Request.Render(dataContext); err != nil {
+		return
+	}
+
+	mqttRequest := testcase.Request.MQTT
+	record.Method = "MQTT"
+	if mqttRequest.Publish == "" && mqttRequest.Subscribe == "" {
+		err = fmt.Errorf("case: %s, either the publish or subscribe topic is required", testcase.Name)
+		return
+	}
+
+	timeout := defaultMQTTTimeout
+	if mqttRequest.Timeout != "" {
+		if timeout, err = time.ParseDuration(mqttRequest.Timeout); err != nil {
+			return
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	clientID := mqttRequest.ClientID
+	if clientID == "" {
+		clientID = fmt.Sprintf("atest-%d", time.Now().UnixNano())
+	}
+	options := mqtt.NewClientOptions().
+		AddBroker(testcase.Request.API).
+		SetClientID(clientID).
+		SetUsername(mqttRequest.Username).
+		SetPassword(mqttRequest.Password).
+		SetTLSConfig(&tls.Config{InsecureSkipVerify: true}).
+		SetConnectTimeout(timeout).
+		SetAutoReconnect(false)
+
+	r.log.Info("start to connect %s\n", testcase.Request.API)
+	client := mqtt.NewClient(options)
+	if err = waitMQTTToken(ctx, client.Connect()); err != nil {
+		err = fmt.Errorf("failed to connect the broker %s, %v", testcase.Request.API, err)
+		return
+	}
+	defer client.Disconnect(100)
+
+	receive := mqttRequest.Receive
+	if receive < len(testcase.Expect.Messages) {
+		receive = len(testcase.Expect.Messages)
+	}
+	if receive == 0 && mqttRequest.Subscribe != "" {
+		receive = 1
+	}
+
+	received := make(chan string, receive)
+	if mqttRequest.Subscribe != "" {
+		token := client.Subscribe(mqttRequest.Subscribe, mqttRequest.QoS, func(_ mqtt.Client, message mqtt.Message) {
+			select {
+			case received <- string(message.Payload()):
+			default:
+				// the redundant messages are dropped
+			}
+		})
+		if err = waitMQTTToken(ctx, token); err != nil {
+			err = fmt.Errorf("failed to subscribe the topic %s, %v", mqttRequest.Subscribe, err)
+			return
+		}
+	}
+
+	if mqttRequest.Publish != "" {
+		token := client.Publish(mqttRequest.Publish, mqttRequest.QoS, mqttRequest.Retained, testcase.Request.Body)
+		if err = waitMQTTToken(ctx, token); err != nil {
+			err = fmt.Errorf("failed to publish to the topic %s, %v", mqttRequest.Publish, err)
+			return
+		}
+	}
+
+	messages := []string{}
+	for len(messages) < receive {
+		select {
+		case message := <-received:
+			r.log.Debug("received message: %s\n", message)
+			messages = append(messages, message)
+		case <-ctx.Done():
+			err = fmt.Errorf("case: %s, expect %d messages from %s, received %d, %v", testcase.Name, receive,
+				mqttRequest.Subscribe, len(messages), ctx.Err())
+			return
+		}
+	}
+	record.Body = strings.Join(messages, "\n")
+
+	for i, expect := range testcase.Expect.Messages {
+		if err = expectString(testcase.Name, strings.TrimSpace(expect), strings.TrimSpace(messages[i])); err != nil {
+			return
+		}
+	}
+
+	// the received messages are verified as an array, the JSON ones are parsed
+	var data []byte
+	if data, err = json.Marshal(parseMessages(messages)); err != nil {
+		return
+	}
+	if output, err = verifyResponseBodyData(testcase.Name, testcase.Expect, data); err != nil {
+		return
+	}
+	err = jsonSchemaValidation(testcase.Expect.Schema, data)
+	return
+}
+
+// waitMQTTToken waits for the token until the context is done
+func waitMQTTToken(ctx context.Context, token mqtt.Token) (err error) {
+	select {
+	case <-token.Done():
+		err = token.Error()
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	return
+}
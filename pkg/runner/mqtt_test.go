@@ -0,0 +1,186 @@
+package runner_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/linuxsuren/api-testing/pkg/runner"
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMQTTTestCaseRunner(t *testing.T) {
+	api := startFakeMQTTBroker(t)
+
+	tests := []struct {
+		name     string
+		testCase *atest.TestCase
+		verify   func(t *testing.T, output interface{}, err error)
+	}{{
+		name: "publish and subscribe",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				Body: `{"name": "{{.name}}"}`,
+				MQTT: &atest.MQTTRequest{
+					Publish:   "devices/{{.name}}",
+					Subscribe: "devices/hello",
+				},
+			},
+			Expect: atest.Response{
+				Verify: []string{`data[0].name == "hello"`},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+			assert.Equal(t, []interface{}{map[string]interface{}{"name": "hello"}}, output)
+		},
+	}, {
+		name: "unexpected message",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				Body: "hello",
+				MQTT: &atest.MQTTRequest{Publish: "devices", Subscribe: "devices"},
+			},
+			Expect: atest.Response{
+				Messages: []string{"fake"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "publish only",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				Body: "hello",
+				MQTT: &atest.MQTTRequest{Publish: "devices"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name: "timeout",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: api,
+				MQTT: &atest.MQTTRequest{
+					Subscribe: "devices",
+					Timeout:   "100ms",
+				},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "no topic",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				MQTT: &atest.MQTTRequest{},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "invalid timeout",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				MQTT: &atest.MQTTRequest{Publish: "devices", Timeout: "fake"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "cannot connect",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  "tcp://127.0.0.1:1",
+				MQTT: &atest.MQTTRequest{Publish: "devices", Timeout: "1s"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mqttRunner := runner.GetTestCaseRunner(tt.testCase)
+			output, err := mqttRunner.RunTestCase(tt.testCase, map[string]string{"name": "hello"}, context.TODO())
+			tt.verify(t, output, err)
+		})
+	}
+}
+
+// startFakeMQTTBroker starts a broker which echoes the published messages back to the same client
+func startFakeMQTTBroker(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveFakeMQTT(conn)
+		}
+	}()
+	return "tcp://" + listener.Addr().String()
+}
+
+func serveFakeMQTT(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		// the fixed header is the packet type and the variable length
+		packet := []byte{0}
+		var err error
+		if packet[0], err = reader.ReadByte(); err != nil {
+			return
+		}
+		length, multiplier := 0, 1
+		for {
+			var b byte
+			if b, err = reader.ReadByte(); err != nil {
+				return
+			}
+			packet = append(packet, b)
+			length += int(b&127) * multiplier
+			multiplier *= 128
+			if b&128 == 0 {
+				break
+			}
+		}
+		payload := make([]byte, length)
+		if _, err = io.ReadFull(reader, payload); err != nil {
+			return
+		}
+
+		switch packet[0] >> 4 {
+		case 1: // CONNECT
+			_, _ = conn.Write([]byte{0x20, 2, 0, 0})
+		case 3: // PUBLISH, only QoS 0 is supported
+			_, _ = conn.Write(append(packet, payload...))
+		case 8: // SUBSCRIBE
+			_, _ = conn.Write([]byte{0x90, 3, payload[0], payload[1], 0})
+		case 12: // PINGREQ
+			_, _ = conn.Write([]byte{0xd0, 0})
+		case 14: // DISCONNECT
+			return
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/nats-io/nats.go"
+)
+
+// defaultNATSTimeout is the timeout of the NATS steps if it's not set
+const defaultNATSTimeout = 10 * time.Second
+
+type natsTestCaseRunner struct {
+	*simpleTestCaseRunner
+}
+
+// NewNATSTestCaseRunner creates the instance of the NATS test case runner
+func NewNATSTestCaseRunner() TestCaseRunner {
+	runner := &natsTestCaseRunner{simpleTestCaseRunner: &simpleTestCaseRunner{}}
+	return runner.embeddedBy(runner)
+}
+
+// RunTestCase publishes the body or sends it as a request, then verifies the received messages
+func (r *natsTestCaseRunner) RunTestCase(testcase *testing.TestCase, dataContext interface{}, ctx context.Context) (output interface{}, err error) {
+	return r.runTestCaseWith(testcase, func(record *ReportRecord) (interface{}, error) {
+		return r.doNATSRequest(testcase, dataContext, ctx, record)
+	})
+}
+
+func (r *natsTestCaseRunner) doNATSRequest(testcase *testing.TestCase, dataContext interface{}, ctx context.Context,
+	record *ReportRecord) (output interface{}, err error) {
+	if err = testcase.Request.Render(dataContext); err != nil {
+		return
+	}
+
+	natsRequest := testcase.Request.NATS
+	record.Method = "NATS"
+	if natsRequest.Request != "" && (natsRequest.Publish != "" || natsRequest.Subscribe != "") {
+		err = fmt.Errorf("case: %s, the request subject cannot be used with the publish or subscribe subject", testcase.Name)
+		return
+	} else if natsRequest.Request == "" && natsRequest.Publish == "" && natsRequest.Subscribe == "" {
+		err = fmt.Errorf("case: %s, one of the publish, subscribe, or request subject is required", testcase.Name)
+		return
+	}
+
+	receive := natsRequest.Receive
+	if receive < len(testcase.Expect.Messages) {
+		receive = len(testcase.Expect.Messages)
+	}
+	if natsRequest.Request != "" {
+		if receive > 1 {
+			err = fmt.Errorf("case: %s, only one reply is received from the request", testcase.Name)
+			return
+		}
+		receive = 1
+	} else if receive == 0 && natsRequest.Subscribe != "" {
+		receive = 1
+	} else if receive > 0 && natsRequest.Subscribe == "" {
+		err = fmt.Errorf("case: %s, the subscribe subject is required to receive the messages", testcase.Name)
+		return
+	}
+
+	timeout := defaultNATSTimeout
+	if natsRequest.Timeout != "" {
+		if timeout, err = time.ParseDuration(natsRequest.Timeout); err != nil {
+			return
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	url := testcase.Request.API
+	if url == "" {
+		url = nats.DefaultURL
+	}
+
+	r.log.Info("start to connect %s\n", url)
+	var conn *nats.Conn
+	if conn, err = nats.Connect(url, nats.Timeout(timeout), nats.NoReconnect()); err != nil {
+		err = fmt.Errorf("failed to connect the server %s, %v", url, err)
+		return
+	}
+	defer conn.Close()
+
+	message := nats.NewMsg(natsRequest.Publish)
+	message.Data = []byte(testcase.Request.Body)
+	for key, val := range testcase.Request.Header {
+		message.Header.Set(key, val)
+	}
+
+	messages := []string{}
+	if natsRequest.Request != "" {
+		message.Subject = natsRequest.Request
+
+		var reply *nats.Msg
+		if reply, err = conn.RequestMsgWithContext(ctx, message); err != nil {
+			err = fmt.Errorf("failed to request the subject %s, %v", natsRequest.Request, err)
+			return
+		}
+		r.log.Debug("received reply: %s\n", string(reply.Data))
+		messages = append(messages, string(reply.Data))
+	} else {
+		var subscription *nats.Subscription
+		if natsRequest.Subscribe != "" {
+			if subscription, err = conn.SubscribeSync(natsRequest.Subscribe); err != nil {
+				err = fmt.Errorf("failed to subscribe the subject %s, %v", natsRequest.Subscribe, err)
+				return
+			}
+			defer func() {
+				_ = subscription.Unsubscribe()
+			}()
+		}
+
+		if natsRequest.Publish != "" {
+			if err = conn.PublishMsg(message); err == nil {
+				err = conn.FlushWithContext(ctx)
+			}
+			if err != nil {
+				err = fmt.Errorf("failed to publish to the subject %s, %v", natsRequest.Publish, err)
+				return
+			}
+		}
+
+		for len(messages) < receive {
+			var received *nats.Msg
+			if received, err = subscription.NextMsgWithContext(ctx); err != nil {
+				err = fmt.Errorf("case: %s, expect %d messages from %s, received %d, %v", testcase.Name, receive,
+					natsRequest.Subscribe, len(messages), err)
+				return
+			}
+			r.log.Debug("received message: %s\n", string(received.Data))
+			messages = append(messages, string(received.Data))
+		}
+	}
+	record.Body = strings.Join(messages, "\n")
+
+	for i, expect := range testcase.Expect.Messages {
+		if err = expectString(testcase.Name, strings.TrimSpace(expect), strings.TrimSpace(messages[i])); err != nil {
+			return
+		}
+	}
+
+	// the received messages are verified as an array, the JSON ones are parsed
+	var data []byte
+	if data, err = json.Marshal(parseMessages(messages)); err != nil {
+		return
+	}
+	if output, err = verifyResponseBodyData(testcase.Name, testcase.Expect, data); err != nil {
+		return
+	}
+	err = jsonSchemaValidation(testcase.Expect.Schema, data)
+	return
+}
@@ -0,0 +1,279 @@
+package runner_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/linuxsuren/api-testing/pkg/runner"
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNATSTestCaseRunner(t *testing.T) {
+	api := startFakeNATSServer(t)
+
+	tests := []struct {
+		name     string
+		testCase *atest.TestCase
+		verify   func(t *testing.T, output interface{}, err error)
+	}{{
+		name: "publish and subscribe",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				Body: `{"name": "{{.name}}"}`,
+				NATS: &atest.NATSRequest{
+					Publish:   "orders.{{.name}}",
+					Subscribe: "orders.*",
+				},
+			},
+			Expect: atest.Response{
+				Verify: []string{`data[0].name == "hello"`},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+			assert.Equal(t, []interface{}{map[string]interface{}{"name": "hello"}}, output)
+		},
+	}, {
+		name: "request and reply",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				Body: `{"name": "{{.name}}"}`,
+				NATS: &atest.NATSRequest{Request: "echo.{{.name}}"},
+			},
+			Expect: atest.Response{
+				Messages: []string{`{"name": "hello"}`},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name: "request with headers",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:    api,
+				Header: map[string]string{"Trace-Id": "abc"},
+				Body:   "hello",
+				NATS:   &atest.NATSRequest{Request: "echo.header"},
+			},
+			Expect: atest.Response{
+				Messages: []string{"hello"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name: "no reply",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				NATS: &atest.NATSRequest{Request: "silent", Timeout: "100ms"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "unexpected message",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				Body: "hello",
+				NATS: &atest.NATSRequest{Publish: "orders", Subscribe: "orders"},
+			},
+			Expect: atest.Response{
+				Messages: []string{"fake"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "publish only",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				Body: "hello",
+				NATS: &atest.NATSRequest{Publish: "orders"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name: "receive without subscribing",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				NATS: &atest.NATSRequest{Publish: "orders", Receive: 1},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "request with the subscribe subject",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				NATS: &atest.NATSRequest{Request: "echo.a", Subscribe: "orders"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "timeout",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				NATS: &atest.NATSRequest{Subscribe: "orders", Timeout: "100ms"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "no subject",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				NATS: &atest.NATSRequest{},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "invalid timeout",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				NATS: &atest.NATSRequest{Publish: "orders", Timeout: "fake"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "cannot connect",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  "nats://127.0.0.1:1",
+				NATS: &atest.NATSRequest{Publish: "orders", Timeout: "1s"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			natsRunner := runner.GetTestCaseRunner(tt.testCase)
+			output, err := natsRunner.RunTestCase(tt.testCase, map[string]string{"name": "hello"}, context.TODO())
+			tt.verify(t, output, err)
+		})
+	}
+}
+
+// startFakeNATSServer starts a server which delivers the messages to the subscriptions of the same client,
+// and replies the requests of the echo.* subjects with the same payload
+func startFakeNATSServer(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveFakeNATS(conn)
+		}
+	}()
+	return "nats://" + listener.Addr().String()
+}
+
+func serveFakeNATS(conn net.Conn) {
+	defer conn.Close()
+	var lock sync.Mutex
+	write := func(format string, args ...interface{}) {
+		lock.Lock()
+		defer lock.Unlock()
+		_, _ = fmt.Fprintf(conn, format, args...)
+	}
+	write("INFO {\"server_id\":\"fake\",\"version\":\"2.2.0\",\"proto\":1,\"headers\":true,\"max_payload\":1048576}\r\n")
+
+	subscriptions := map[string]string{}
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		args := strings.Fields(line)
+		if len(args) == 0 {
+			continue
+		}
+
+		switch args[0] {
+		case "PING":
+			write("PONG\r\n")
+		case "SUB":
+			subscriptions[args[len(args)-1]] = args[1]
+		case "UNSUB":
+			delete(subscriptions, args[1])
+		case "PUB", "HPUB":
+			// the size of the headers is the argument before the total size
+			msg, subject, reply, headerSize := "MSG", args[1], "", ""
+			if args[0] == "HPUB" {
+				msg, headerSize = "HMSG", args[len(args)-2]+" "
+				args = append(args[:len(args)-2], args[len(args)-1])
+			}
+			if len(args) == 4 {
+				reply = args[2] + " "
+			}
+			size, _ := strconv.Atoi(args[len(args)-1])
+			payload := make([]byte, size+2)
+			if _, err = io.ReadFull(reader, payload); err != nil {
+				return
+			}
+
+			for sid, pattern := range subscriptions {
+				if matchNATSSubject(pattern, subject) {
+					write("%s %s %s %s%s%d\r\n%s", msg, subject, sid, reply, headerSize, size, payload)
+				}
+				if reply != "" && strings.HasPrefix(subject, "echo.") && matchNATSSubject(pattern, strings.TrimSpace(reply)) {
+					write("%s %s %s %s%d\r\n%s", msg, strings.TrimSpace(reply), sid, headerSize, size, payload)
+				}
+			}
+		}
+	}
+}
+
+// matchNATSSubject matches the subject with the wildcards * and >
+func matchNATSSubject(pattern, subject string) bool {
+	patterns, tokens := strings.Split(pattern, "."), strings.Split(subject, ".")
+	for i, token := range patterns {
+		if token == ">" {
+			return len(tokens) > i
+		} else if i >= len(tokens) || (token != "*" && token != tokens[i]) {
+			return false
+		}
+	}
+	return len(patterns) == len(tokens)
+}
@@ -0,0 +1,141 @@
+package runner
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/Azure/go-ntlmssp"
+	"github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/credentials"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+const (
+	negotiateKerberos = "kerberos"
+	negotiateNTLM     = "ntlm"
+	// defaultKRB5Config is the krb5.conf if it's neither set nor in $KRB5_CONFIG
+	defaultKRB5Config = "/etc/krb5.conf"
+)
+
+// newNegotiateTransport wraps the transport to authenticate the requests by NTLM or Kerberos
+func newNegotiateTransport(base http.RoundTripper, auth *testing.NegotiateAuth) (transport http.RoundTripper, err error) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	switch auth.Mechanism {
+	case "", negotiateKerberos:
+		var krbClient *client.Client
+		if krbClient, err = newKerberosClient(auth); err != nil {
+			err = fmt.Errorf("failed to login the Kerberos, %v", err)
+			return
+		}
+		transport = &kerberosTransport{base: base, client: krbClient, spn: auth.SPN}
+	case negotiateNTLM:
+		username := auth.Username
+		if auth.Domain != "" && !strings.Contains(username, `\`) {
+			username = auth.Domain + `\` + username
+		}
+		transport = &ntlmTransport{
+			negotiator: ntlmssp.Negotiator{RoundTripper: base},
+			username:   username,
+			password:   auth.Password,
+		}
+	default:
+		err = fmt.Errorf("unsupported mechanism %q, only kerberos and ntlm are supported", auth.Mechanism)
+	}
+	return
+}
+
+// ntlmTransport sends the credential as the basic auth, the negotiator converts it to the NTLM
+// handshake if the server asks for it
+type ntlmTransport struct {
+	negotiator ntlmssp.Negotiator
+	username   string
+	password   string
+}
+
+func (t *ntlmTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.SetBasicAuth(t.username, t.password)
+	return t.negotiator.RoundTrip(req)
+}
+
+// kerberosTransport sets the SPNEGO token to every request
+type kerberosTransport struct {
+	base   http.RoundTripper
+	client *client.Client
+	spn    string
+}
+
+func (t *kerberosTransport) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+	spn := t.spn
+	if spn == "" {
+		spn = "HTTP/" + req.URL.Hostname()
+	}
+
+	req = req.Clone(req.Context())
+	if err = spnego.SetSPNEGOHeader(t.client, req, spn); err == nil {
+		resp, err = t.base.RoundTrip(req)
+	}
+	return
+}
+
+// newKerberosClient logs in by the keytab, the password, or the credential cache in order
+func newKerberosClient(auth *testing.NegotiateAuth) (krbClient *client.Client, err error) {
+	configFile := auth.Config
+	if configFile == "" {
+		configFile = os.Getenv("KRB5_CONFIG")
+	}
+	if configFile == "" {
+		configFile = defaultKRB5Config
+	}
+
+	var cfg *config.Config
+	if cfg, err = config.Load(configFile); err != nil {
+		err = fmt.Errorf("invalid krb5.conf %s, %v", configFile, err)
+		return
+	}
+
+	realm := auth.Domain
+	if realm == "" {
+		realm = cfg.LibDefaults.DefaultRealm
+	}
+
+	// the FAST is not supported by the Active Directory
+	disableFAST := client.DisablePAFXFAST(true)
+	switch {
+	case auth.Keytab != "":
+		var kt *keytab.Keytab
+		if kt, err = keytab.Load(auth.Keytab); err != nil {
+			return
+		}
+		krbClient = client.NewWithKeytab(auth.Username, realm, kt, cfg, disableFAST)
+	case auth.Password != "":
+		krbClient = client.NewWithPassword(auth.Username, realm, auth.Password, cfg, disableFAST)
+	default:
+		ccacheFile := auth.CCache
+		if ccacheFile == "" {
+			ccacheFile = strings.TrimPrefix(os.Getenv("KRB5CCNAME"), "FILE:")
+		}
+		if ccacheFile == "" {
+			err = fmt.Errorf("one of the keytab, password or ccache is required")
+			return
+		}
+
+		var ccache *credentials.CCache
+		if ccache, err = credentials.LoadCCache(ccacheFile); err != nil {
+			return
+		}
+		if krbClient, err = client.NewFromCCache(ccache, cfg, disableFAST); err != nil {
+			return
+		}
+	}
+	err = krbClient.Login()
+	return
+}
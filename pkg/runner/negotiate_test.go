@@ -0,0 +1,162 @@
+package runner
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNTLM(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(fakeNTLMHandler))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		auth   *atest.NegotiateAuth
+		expect int
+	}{{
+		name:   "normal",
+		auth:   &atest.NegotiateAuth{Mechanism: "ntlm", Username: "rick", Password: "secret", Domain: "EXAMPLE"},
+		expect: http.StatusOK,
+	}, {
+		name:   "domain in the username",
+		auth:   &atest.NegotiateAuth{Mechanism: "ntlm", Username: `EXAMPLE\rick`, Password: "secret"},
+		expect: http.StatusOK,
+	}, {
+		name:   "unknown user",
+		auth:   &atest.NegotiateAuth{Mechanism: "ntlm", Username: "morty", Password: "secret"},
+		expect: http.StatusForbidden,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, err := newNegotiateTransport(&http.Transport{}, tt.auth)
+			assert.Nil(t, err)
+
+			client := &http.Client{Transport: transport}
+			resp, err := client.Post(server.URL, "text/plain", strings.NewReader("hello"))
+			if assert.Nil(t, err) {
+				defer resp.Body.Close()
+				assert.Equal(t, tt.expect, resp.StatusCode)
+				if tt.expect == http.StatusOK {
+					data, _ := io.ReadAll(resp.Body)
+					assert.Equal(t, "hello", string(data))
+				}
+			}
+		})
+	}
+}
+
+func TestNegotiateTransportError(t *testing.T) {
+	dir := t.TempDir()
+	krb5Conf := filepath.Join(dir, "krb5.conf")
+	assert.Nil(t, os.WriteFile(krb5Conf, []byte(`[libdefaults]
+  default_realm = EXAMPLE.COM
+  dns_lookup_kdc = false
+[realms]
+  EXAMPLE.COM = {
+    kdc = 127.0.0.1:1
+  }
+`), 0644))
+	t.Setenv("KRB5CCNAME", "")
+
+	tests := []struct {
+		name      string
+		auth      *atest.NegotiateAuth
+		expectErr string
+	}{{
+		name:      "unsupported mechanism",
+		auth:      &atest.NegotiateAuth{Mechanism: "digest"},
+		expectErr: `unsupported mechanism "digest"`,
+	}, {
+		name:      "not found krb5.conf",
+		auth:      &atest.NegotiateAuth{Config: filepath.Join(dir, "fake.conf")},
+		expectErr: "invalid krb5.conf",
+	}, {
+		name:      "no credential",
+		auth:      &atest.NegotiateAuth{Username: "rick", Config: krb5Conf},
+		expectErr: "one of the keytab, password or ccache is required",
+	}, {
+		name:      "not found keytab",
+		auth:      &atest.NegotiateAuth{Username: "rick", Keytab: filepath.Join(dir, "fake.keytab"), Config: krb5Conf},
+		expectErr: "fake.keytab",
+	}, {
+		name:      "cannot reach the KDC",
+		auth:      &atest.NegotiateAuth{Username: "rick", Password: "secret", Config: krb5Conf},
+		expectErr: "failed to login the Kerberos",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newNegotiateTransport(nil, tt.auth)
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expectErr)
+			}
+		})
+	}
+}
+
+// fakeNTLMHandler accepts the user rick of any password, and echoes the request body
+func fakeNTLMHandler(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "NTLM ") {
+		w.Header().Set("WWW-Authenticate", "NTLM")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(auth, "NTLM "))
+	if err != nil || len(data) < 12 || string(data[:8]) != "NTLMSSP\x00" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	switch binary.LittleEndian.Uint32(data[8:12]) {
+	case 1:
+		// the challenge has the unicode flag, and the target info which has the end of the list only
+		challenge := make([]byte, 52)
+		copy(challenge, "NTLMSSP\x00")
+		binary.LittleEndian.PutUint32(challenge[8:], 2)
+		binary.LittleEndian.PutUint32(challenge[20:], 1)
+		copy(challenge[24:32], "12345678")
+		binary.LittleEndian.PutUint16(challenge[40:], 4)
+		binary.LittleEndian.PutUint16(challenge[42:], 4)
+		binary.LittleEndian.PutUint32(challenge[44:], 48)
+
+		w.Header().Set("WWW-Authenticate", "NTLM "+base64.StdEncoding.EncodeToString(challenge))
+		w.WriteHeader(http.StatusUnauthorized)
+	case 3:
+		if len(data) < 44 || ntlmField(data, 36) != "rick" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write(body)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+// ntlmField reads the UTF-16 string of the field at the offset of the message
+func ntlmField(data []byte, offset int) string {
+	length := int(binary.LittleEndian.Uint16(data[offset:]))
+	start := int(binary.LittleEndian.Uint32(data[offset+4:]))
+	if start+length > len(data) {
+		return ""
+	}
+
+	chars := make([]uint16, length/2)
+	for i := range chars {
+		chars[i] = binary.LittleEndian.Uint16(data[start+i*2:])
+	}
+	return string(utf16.Decode(chars))
+}
@@ -0,0 +1,234 @@
+package runner
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/linuxsuren/api-testing/pkg/util"
+)
+
+const (
+	// oidcDiscoveryPath is the path of the discovery document of an issuer
+	oidcDiscoveryPath = "/.well-known/openid-configuration"
+	// oidcGrantPassword is the grant type which requires the username and password
+	oidcGrantPassword = "password"
+)
+
+// oidcDiscovery is the part of the discovery document which is required
+type oidcDiscovery struct {
+	Issuer        string `json:"issuer"`
+	TokenEndpoint string `json:"token_endpoint"`
+	JWKSURI       string `json:"jwks_uri"`
+}
+
+type oidcTestCaseRunner struct {
+	*simpleTestCaseRunner
+}
+
+// NewOIDCTestCaseRunner creates the instance of the OpenID Connect test case runner
+func NewOIDCTestCaseRunner() TestCaseRunner {
+	runner := &oidcTestCaseRunner{simpleTestCaseRunner: &simpleTestCaseRunner{}}
+	return runner.embeddedBy(runner)
+}
+
+// RunTestCase discovers the issuer, acquires the tokens, then verifies the claims of them
+func (r *oidcTestCaseRunner) RunTestCase(testcase *testing.TestCase, dataContext interface{}, ctx context.Context) (output interface{}, err error) {
+	return r.runTestCaseWith(testcase, func(record *ReportRecord) (interface{}, error) {
+		return r.doOIDCRequest(testcase, dataContext, ctx, record)
+	})
+}
+
+func (r *oidcTestCaseRunner) doOIDCRequest(testcase *testing.TestCase, dataContext interface{}, ctx context.Context,
+	record *ReportRecord) (output interface{}, err error) {
+	if err = testcase.Request.Render(dataContext); err != nil {
+		return
+	}
+
+	oidcRequest := testcase.Request.OIDC
+	record.Method = "OIDC"
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	issuer := strings.TrimSuffix(strings.TrimSuffix(testcase.Request.API, oidcDiscoveryPath), "/")
+	r.log.Info("start to discover the issuer %s\n", issuer)
+	discovery := &oidcDiscovery{}
+	var discoveryData []byte
+	if discoveryData, err = getOIDCJSON(ctx, client, issuer+oidcDiscoveryPath, discovery); err != nil {
+		err = fmt.Errorf("case: %s, failed to discover the issuer, %v", testcase.Name, err)
+		return
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != issuer {
+		err = fmt.Errorf("case: %s, expect issuer: %s, actual: %s", testcase.Name, issuer, discovery.Issuer)
+		return
+	}
+	if discovery.TokenEndpoint == "" {
+		err = fmt.Errorf("case: %s, the token endpoint is not found in the discovery document", testcase.Name)
+		return
+	}
+
+	var tokenData []byte
+	if tokenData, err = r.requestOIDCToken(ctx, client, discovery.TokenEndpoint, oidcRequest); err != nil {
+		err = fmt.Errorf("case: %s, failed to acquire the token, %v", testcase.Name, err)
+		return
+	}
+	record.Body = string(tokenData)
+
+	token := map[string]interface{}{}
+	if err = json.Unmarshal(tokenData, &token); err != nil {
+		err = fmt.Errorf("case: %s, not a valid token response, %v", testcase.Name, err)
+		return
+	}
+
+	var keySet *jsonWebKeySet
+	result := map[string]interface{}{
+		"discovery": json.RawMessage(discoveryData),
+		"token":     token,
+	}
+	for _, name := range []string{"access_token", "id_token"} {
+		// the opaque access token has no claims
+		raw, _ := token[name].(string)
+		if !isJWT(raw) {
+			continue
+		}
+
+		var jwt *jsonWebToken
+		if jwt, err = parseJWT(raw); err != nil {
+			err = fmt.Errorf("case: %s, invalid %s, %v", testcase.Name, name, err)
+			return
+		}
+		if !oidcRequest.SkipVerify {
+			if alg, _ := jwt.Header["alg"].(string); keySet == nil && !strings.HasPrefix(alg, "HS") {
+				keySet = &jsonWebKeySet{}
+				if _, err = getOIDCJSON(ctx, client, discovery.JWKSURI, keySet); err != nil {
+					err = fmt.Errorf("case: %s, failed to get the JWKS, %v", testcase.Name, err)
+					return
+				}
+			}
+			if err = verifyOIDCToken(jwt, keySet, discovery.Issuer, oidcRequest, name == "id_token"); err != nil {
+				err = fmt.Errorf("case: %s, invalid %s, %v", testcase.Name, name, err)
+				return
+			}
+		}
+		result[strings.TrimSuffix(name, "_token")+"Token"] = jwt.Claims
+	}
+
+	var data []byte
+	if data, err = json.Marshal(result); err != nil {
+		return
+	}
+	if output, err = verifyResponseBodyData(testcase.Name, testcase.Expect, data); err != nil {
+		return
+	}
+	err = jsonSchemaValidation(testcase.Expect.Schema, data)
+	return
+}
+
+func (r *oidcTestCaseRunner) requestOIDCToken(ctx context.Context, client *http.Client, endpoint string,
+	oidcRequest *testing.OIDCRequest) (data []byte, err error) {
+	form := url.Values{}
+	form.Set("grant_type", oidcRequest.GrantType)
+	if oidcRequest.GrantType == oidcGrantPassword {
+		form.Set("username", oidcRequest.Username)
+		form.Set("password", oidcRequest.Password)
+	}
+	if oidcRequest.Scope != "" {
+		form.Set("scope", oidcRequest.Scope)
+	}
+	if oidcRequest.Audience != "" {
+		form.Set("audience", oidcRequest.Audience)
+	}
+	if oidcRequest.ClientSecret == "" {
+		form.Set("client_id", oidcRequest.ClientID)
+	}
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode())); err != nil {
+		return
+	}
+	req.Header.Set(util.ContentType, util.Form)
+	if oidcRequest.ClientSecret != "" {
+		// the client_secret_basic is the default authentication method of the token endpoint
+		req.SetBasicAuth(url.QueryEscape(oidcRequest.ClientID), url.QueryEscape(oidcRequest.ClientSecret))
+	}
+
+	r.log.Info("start to request the token from %s\n", endpoint)
+	var resp *http.Response
+	if resp, err = client.Do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if data, err = io.ReadAll(resp.Body); err == nil && resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("unexpected status code %d, %s", resp.StatusCode, string(data))
+	}
+	return
+}
+
+// verifyOIDCToken verifies the signature, issuer, expiration, and the audience of the ID token
+func verifyOIDCToken(jwt *jsonWebToken, keySet *jsonWebKeySet, issuer string, oidcRequest *testing.OIDCRequest,
+	idToken bool) (err error) {
+	if err = jwt.verify(keySet, oidcRequest.ClientSecret); err != nil {
+		return
+	}
+
+	if iss, ok := jwt.Claims["iss"]; ok && iss != issuer {
+		err = fmt.Errorf("expect issuer: %s, actual: %v", issuer, iss)
+		return
+	}
+	if exp, ok := jwt.Claims["exp"].(float64); ok && time.Now().After(time.Unix(int64(exp), 0)) {
+		err = fmt.Errorf("expired at %s", time.Unix(int64(exp), 0).Format(time.RFC3339))
+		return
+	}
+
+	// only the ID token must have the client as the audience
+	if idToken {
+		switch aud := jwt.Claims["aud"].(type) {
+		case string:
+			if aud == oidcRequest.ClientID {
+				return
+			}
+		case []interface{}:
+			for _, item := range aud {
+				if item == oidcRequest.ClientID {
+					return
+				}
+			}
+		}
+		err = fmt.Errorf("expect audience: %s, actual: %v", oidcRequest.ClientID, jwt.Claims["aud"])
+	}
+	return
+}
+
+func getOIDCJSON(ctx context.Context, client *http.Client, api string, object interface{}) (data []byte, err error) {
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, api, nil); err != nil {
+		return
+	}
+
+	var resp *http.Response
+	if resp, err = client.Do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if data, err = io.ReadAll(resp.Body); err != nil {
+		return
+	}
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("unexpected status code %d, %s", resp.StatusCode, string(data))
+		return
+	}
+	err = json.Unmarshal(data, object)
+	return
+}
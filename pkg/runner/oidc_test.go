@@ -0,0 +1,233 @@
+package runner_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/linuxsuren/api-testing/pkg/runner"
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOIDCTestCaseRunner(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if !assert.Nil(t, err) {
+		return
+	}
+	signJWT := func(claims map[string]interface{}) string {
+		claimsData, _ := json.Marshal(claims)
+		input := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","kid":"test"}`)) + "." +
+			base64.RawURLEncoding.EncodeToString(claimsData)
+		hashed := sha256.Sum256([]byte(input))
+		signature, _ := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+		return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+	}
+
+	var issuer string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":         issuer,
+			"token_endpoint": issuer + "/token",
+			"jwks_uri":       issuer + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, req *http.Request) {
+		clientID, clientSecret, _ := req.BasicAuth()
+		if clientID != "api-testing" || clientSecret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+			return
+		}
+
+		exp := time.Now().Add(time.Hour).Unix()
+		if req.FormValue("scope") == "expired" {
+			exp = time.Now().Add(-time.Hour).Unix()
+		}
+		token := map[string]interface{}{
+			"access_token": signJWT(map[string]interface{}{
+				"iss": issuer, "sub": req.FormValue("username"), "scope": req.FormValue("scope"), "exp": exp,
+			}),
+			"token_type": "Bearer",
+			"expires_in": 3600,
+		}
+		if req.FormValue("grant_type") == "password" {
+			token["id_token"] = signJWT(map[string]interface{}{
+				"iss": issuer, "sub": req.FormValue("username"), "aud": req.FormValue("audience"), "exp": exp,
+			})
+		}
+		_ = json.NewEncoder(w).Encode(token)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	issuer = server.URL
+
+	tests := []struct {
+		name     string
+		testCase *atest.TestCase
+		verify   func(t *testing.T, output interface{}, err error)
+	}{{
+		name: "client credentials",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: server.URL,
+				OIDC: &atest.OIDCRequest{
+					ClientID:     "api-testing",
+					ClientSecret: "{{.secret}}",
+					Scope:        "read",
+				},
+			},
+			Expect: atest.Response{
+				BodyFieldsExpect: map[string]interface{}{
+					"token/token_type":  "Bearer",
+					"accessToken/scope": "read",
+				},
+				Verify: []string{`data.accessToken.iss == data.discovery.issuer`},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name: "password grant with the ID token",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: server.URL + "/.well-known/openid-configuration",
+				OIDC: &atest.OIDCRequest{
+					GrantType:    "password",
+					ClientID:     "api-testing",
+					ClientSecret: "secret",
+					Username:     "linuxsuren",
+					Password:     "password",
+					Audience:     "api-testing",
+				},
+			},
+			Expect: atest.Response{
+				BodyFieldsExpect: map[string]interface{}{
+					"idToken/sub": "linuxsuren",
+				},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name: "unexpected audience of the ID token",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: server.URL,
+				OIDC: &atest.OIDCRequest{
+					GrantType:    "password",
+					ClientID:     "api-testing",
+					ClientSecret: "secret",
+					Audience:     "fake",
+				},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), "expect audience: api-testing, actual: fake")
+			}
+		},
+	}, {
+		name: "expired token",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: server.URL,
+				OIDC: &atest.OIDCRequest{
+					ClientID:     "api-testing",
+					ClientSecret: "secret",
+					Scope:        "expired",
+				},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), "expired at")
+			}
+		},
+	}, {
+		name: "skip verifying the expired token",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: server.URL,
+				OIDC: &atest.OIDCRequest{
+					ClientID:     "api-testing",
+					ClientSecret: "secret",
+					Scope:        "expired",
+					SkipVerify:   true,
+				},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name: "invalid client",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  server.URL,
+				OIDC: &atest.OIDCRequest{ClientID: "api-testing", ClientSecret: "fake"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), "unexpected status code 401")
+			}
+		},
+	}, {
+		name: "unexpected claims",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  server.URL,
+				OIDC: &atest.OIDCRequest{ClientID: "api-testing", ClientSecret: "secret", Scope: "read"},
+			},
+			Expect: atest.Response{
+				BodyFieldsExpect: map[string]interface{}{"accessToken/scope": "write"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "not an issuer",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  server.URL + "/fake",
+				OIDC: &atest.OIDCRequest{ClientID: "api-testing"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), "failed to discover the issuer")
+			}
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oidcRunner := runner.GetTestCaseRunner(tt.testCase)
+			output, err := oidcRunner.RunTestCase(tt.testCase, map[string]string{"secret": "secret"}, context.TODO())
+			tt.verify(t, output, err)
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// OpenAPISpec finds the request and response schemas of the operations in the OpenAPI 3 (or Swagger 2) spec
+type OpenAPISpec struct {
+	document   map[string]interface{}
+	basePaths  []string
+	operations []openAPIOperation
+}
+
+type openAPIOperation struct {
+	method      string
+	path        *regexp.Regexp
+	responses   map[string]interface{}
+	requestBody interface{}
+}
+
+var openAPIMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true,
+	"options": true, "head": true, "patch": true, "trace": true,
+}
+
+var openAPIPathParamReg = regexp.MustCompile(`\{[^/{}]+\}`)
+
+// LoadOpenAPISpec loads the OpenAPI spec from a YAML or JSON file
+func LoadOpenAPISpec(file string) (spec *OpenAPISpec, err error) {
+	var data []byte
+	if data, err = os.ReadFile(file); err == nil {
+		if spec, err = NewOpenAPISpec(data); err != nil {
+			err = fmt.Errorf("failed to parse the OpenAPI spec %s, %v", file, err)
+		}
+	}
+	return
+}
+
+// NewOpenAPISpec parses the OpenAPI spec, the nullable fields of OpenAPI 3.0 are converted to be the JSON schema ones
+func NewOpenAPISpec(data []byte) (spec *OpenAPISpec, err error) {
+	if data, err = yaml.YAMLToJSON(data); err != nil {
+		return
+	}
+
+	spec = &OpenAPISpec{}
+	if err = json.Unmarshal(data, &spec.document); err != nil {
+		return
+	}
+	paths, _ := spec.document["paths"].(map[string]interface{})
+	if len(paths) == 0 {
+		err = fmt.Errorf("no paths found in the OpenAPI spec")
+		return
+	}
+	convertNullable(spec.document)
+
+	for path, item := range paths {
+		operations, _ := item.(map[string]interface{})
+		for method, operation := range operations {
+			// skip the fields which are not operations, such as: parameters, summary
+			if !openAPIMethods[method] {
+				continue
+			}
+			responses, _ := mapValue(operation, "responses").(map[string]interface{})
+			// the request body of Swagger 2 is the body parameter
+			requestBody := mapValue(operation, "requestBody")
+			parameters, _ := mapValue(operation, "parameters").([]interface{})
+			for _, parameter := range parameters {
+				if parameter = spec.resolveRef(parameter); mapValue(parameter, "in") == "body" {
+					requestBody = parameter
+				}
+			}
+			spec.operations = append(spec.operations, openAPIOperation{
+				method:      strings.ToUpper(method),
+				path:        regexp.MustCompile("^" + openAPIPathRegexp(path) + "$"),
+				responses:   responses,
+				requestBody: requestBody,
+			})
+		}
+	}
+	// the static paths have higher priority than the parameterized ones, such as: /users/me and /users/{id}
+	sort.SliceStable(spec.operations, func(i, j int) bool {
+		return strings.Count(spec.operations[i].path.String(), "[^/]+") < strings.Count(spec.operations[j].path.String(), "[^/]+")
+	})
+
+	if basePath, ok := spec.document["basePath"].(string); ok {
+		spec.basePaths = append(spec.basePaths, basePath)
+	}
+	servers, _ := spec.document["servers"].([]interface{})
+	for _, server := range servers {
+		if serverURL, ok := mapValue(server, "url").(string); ok {
+			if u, parseErr := url.Parse(serverURL); parseErr == nil {
+				spec.basePaths = append(spec.basePaths, u.Path)
+			}
+		}
+	}
+	return
+}
+
+func openAPIPathRegexp(path string) string {
+	parts := openAPIPathParamReg.Split(path, -1)
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	return strings.Join(parts, "[^/]+")
+}
+
+// ResponseSchema returns the JSON schema of the response of the operation, the path might have the base path of
+// the servers. It's empty if the operation is not found, or there's no JSON schema of the status code
+func (s *OpenAPISpec) ResponseSchema(method, path string, statusCode int) (schema string) {
+	operation := s.findOperation(method, path)
+	if operation == nil {
+		return
+	}
+
+	code := strconv.Itoa(statusCode)
+	for _, key := range []string{code, code[:1] + "XX", code[:1] + "xx", "default"} {
+		if response, ok := operation.responses[key]; ok {
+			return s.schemaOfResponse(s.resolveRef(response))
+		}
+	}
+	return
+}
+
+// RequestSchema returns the JSON schema of the request body of the operation, it's empty if the operation
+// is not found, or there's no JSON schema of the request body
+func (s *OpenAPISpec) RequestSchema(method, path string) (schema string) {
+	if operation := s.findOperation(method, path); operation != nil && operation.requestBody != nil {
+		schema = s.schemaOfResponse(s.resolveRef(operation.requestBody))
+	}
+	return
+}
+
+// findOperation returns the operation of the method and path, the path might have the base path of the servers
+func (s *OpenAPISpec) findOperation(method, path string) *openAPIOperation {
+	path, _, _ = strings.Cut(path, "?")
+	paths := []string{path}
+	for _, basePath := range s.basePaths {
+		if basePath = strings.TrimSuffix(basePath, "/"); basePath != "" && strings.HasPrefix(path, basePath+"/") {
+			paths = append(paths, strings.TrimPrefix(path, basePath))
+		}
+	}
+
+	for i, operation := range s.operations {
+		if operation.method == strings.ToUpper(method) && matchAnyPath(operation.path, paths) {
+			return &s.operations[i]
+		}
+	}
+	return nil
+}
+
+func matchAnyPath(reg *regexp.Regexp, paths []string) bool {
+	for _, path := range paths {
+		if reg.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaOfResponse returns the schema of the JSON media type of the response or request body, the schemas of the spec are put together
+// so that the references could be resolved
+func (s *OpenAPISpec) schemaOfResponse(response interface{}) string {
+	// the schema of Swagger 2
+	schema, _ := mapValue(response, "schema").(map[string]interface{})
+	if content, ok := mapValue(response, "content").(map[string]interface{}); ok {
+		var mediaTypes []string
+		for mediaType := range content {
+			if strings.Contains(mediaType, "json") {
+				mediaTypes = append(mediaTypes, mediaType)
+			}
+		}
+		sort.Strings(mediaTypes)
+		if len(mediaTypes) > 0 {
+			schema, _ = mapValue(content[mediaTypes[0]], "schema").(map[string]interface{})
+		}
+	}
+	if len(schema) == 0 {
+		return ""
+	}
+
+	document := map[string]interface{}{}
+	for key, val := range schema {
+		document[key] = val
+	}
+	for _, key := range []string{"components", "definitions"} {
+		if val, ok := s.document[key]; ok {
+			if _, exist := document[key]; !exist {
+				document[key] = val
+			}
+		}
+	}
+	data, _ := json.Marshal(document)
+	return string(data)
+}
+
+// resolveRef returns the referenced object of the spec, such as: #/components/responses/NotFound
+func (s *OpenAPISpec) resolveRef(object interface{}) interface{} {
+	ref, ok := mapValue(object, "$ref").(string)
+	if !ok || !strings.HasPrefix(ref, "#/") {
+		return object
+	}
+
+	var result interface{} = s.document
+	for _, key := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		key = strings.ReplaceAll(strings.ReplaceAll(key, "~1", "/"), "~0", "~")
+		result = mapValue(result, key)
+	}
+	return result
+}
+
+func mapValue(object interface{}, key string) interface{} {
+	if data, ok := object.(map[string]interface{}); ok {
+		return data[key]
+	}
+	return nil
+}
+
+// convertNullable converts the schemas which have nullable: true, the null is allowed by the type and enum
+func convertNullable(object interface{}) {
+	switch data := object.(type) {
+	case map[string]interface{}:
+		if data["nullable"] == true {
+			delete(data, "nullable")
+			if schemaType, ok := data["type"].(string); ok {
+				data["type"] = []interface{}{schemaType, "null"}
+			}
+			if enum, ok := data["enum"].([]interface{}); ok {
+				data["enum"] = append(enum, nil)
+			}
+		}
+		for _, val := range data {
+			convertNullable(val)
+		}
+	case []interface{}:
+		for _, val := range data {
+			convertNullable(val)
+		}
+	}
+}
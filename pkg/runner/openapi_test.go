@@ -0,0 +1,244 @@
+package runner
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const openAPI3Spec = `openapi: 3.0.0
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /users:
+    parameters: []
+    get:
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/User'
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/User'
+      responses:
+        "201":
+          description: created
+  /users/me:
+    get:
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+  /users/{id}:
+    get:
+      responses:
+        "2XX":
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+        "404":
+          $ref: '#/components/responses/NotFound'
+    delete:
+      responses:
+        "204":
+          description: deleted
+  /avatar:
+    get:
+      responses:
+        "200":
+          content:
+            image/png: {}
+components:
+  responses:
+    NotFound:
+      content:
+        application/problem+json:
+          schema:
+            type: object
+            required: [title]
+  schemas:
+    User:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+        email:
+          type: string
+          nullable: true
+`
+
+const swagger2Spec = `swagger: "2.0"
+basePath: /api
+paths:
+  /pets:
+    post:
+      parameters:
+        - in: body
+          name: pet
+          schema:
+            $ref: '#/definitions/Pet'
+      responses:
+        default:
+          schema:
+            $ref: '#/definitions/Pet'
+definitions:
+  Pet:
+    type: object
+    required: [id]
+`
+
+func TestOpenAPISpecResponseSchema(t *testing.T) {
+	openAPI3, err := NewOpenAPISpec([]byte(openAPI3Spec))
+	assert.Nil(t, err)
+	swagger2, err := NewOpenAPISpec([]byte(swagger2Spec))
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name       string
+		spec       *OpenAPISpec
+		method     string
+		path       string
+		statusCode int
+		body       string
+		expectErr  string
+		noSchema   bool
+	}{{
+		name:       "array of references",
+		spec:       openAPI3,
+		method:     http.MethodGet,
+		path:       "/users?page=1",
+		statusCode: http.StatusOK,
+		body:       `[{"name": "rick", "email": null}]`,
+	}, {
+		name:       "invalid item",
+		spec:       openAPI3,
+		method:     http.MethodGet,
+		path:       "/users",
+		statusCode: http.StatusOK,
+		body:       `[{"email": "rick@example.com"}]`,
+		expectErr:  "name is required",
+	}, {
+		name:       "static path first",
+		spec:       openAPI3,
+		method:     "get",
+		path:       "/users/me",
+		statusCode: http.StatusOK,
+		body:       `{"name": 1}`,
+		expectErr:  "Invalid type. Expected: string, given: integer",
+	}, {
+		name:       "status code range with the base path",
+		spec:       openAPI3,
+		method:     http.MethodGet,
+		path:       "/v1/users/{{.create.id}}",
+		statusCode: http.StatusAccepted,
+		body:       `{"name": "rick"}`,
+	}, {
+		name:       "referenced response",
+		spec:       openAPI3,
+		method:     http.MethodGet,
+		path:       "/users/1",
+		statusCode: http.StatusNotFound,
+		body:       `{}`,
+		expectErr:  "title is required",
+	}, {
+		name:       "no content",
+		spec:       openAPI3,
+		method:     http.MethodDelete,
+		path:       "/users/1",
+		statusCode: http.StatusNoContent,
+		noSchema:   true,
+	}, {
+		name:       "not JSON",
+		spec:       openAPI3,
+		method:     http.MethodGet,
+		path:       "/avatar",
+		statusCode: http.StatusOK,
+		noSchema:   true,
+	}, {
+		name:       "undocumented status code",
+		spec:       openAPI3,
+		method:     http.MethodGet,
+		path:       "/users/me",
+		statusCode: http.StatusInternalServerError,
+		noSchema:   true,
+	}, {
+		name:       "undocumented operation",
+		spec:       openAPI3,
+		method:     http.MethodPost,
+		path:       "/users/1",
+		statusCode: http.StatusOK,
+		noSchema:   true,
+	}, {
+		name:       "default response of Swagger 2",
+		spec:       swagger2,
+		method:     http.MethodPost,
+		path:       "/api/pets",
+		statusCode: http.StatusCreated,
+		body:       `{"name": "tom"}`,
+		expectErr:  "id is required",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := tt.spec.ResponseSchema(tt.method, tt.path, tt.statusCode)
+			if tt.noSchema {
+				assert.Empty(t, schema)
+				return
+			}
+			if !assert.NotEmpty(t, schema) {
+				return
+			}
+
+			err := jsonSchemaValidation(schema, []byte(tt.body))
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestOpenAPISpecRequestSchema(t *testing.T) {
+	openAPI3, err := NewOpenAPISpec([]byte(openAPI3Spec))
+	assert.Nil(t, err)
+	swagger2, err := NewOpenAPISpec([]byte(swagger2Spec))
+	assert.Nil(t, err)
+
+	schema := openAPI3.RequestSchema(http.MethodPost, "/v1/users")
+	assert.ErrorContains(t, jsonSchemaValidation(schema, []byte(`{"email": "rick@example.com"}`)), "name is required")
+
+	schema = swagger2.RequestSchema(http.MethodPost, "/api/pets")
+	assert.ErrorContains(t, jsonSchemaValidation(schema, []byte(`{}`)), "id is required")
+
+	assert.Empty(t, openAPI3.RequestSchema(http.MethodGet, "/users"))
+	assert.Empty(t, openAPI3.RequestSchema(http.MethodPost, "/users/1"))
+}
+
+func TestLoadOpenAPISpec(t *testing.T) {
+	_, err := LoadOpenAPISpec(filepath.Join(t.TempDir(), "fake.yaml"))
+	assert.Error(t, err)
+
+	file := filepath.Join(t.TempDir(), "spec.yaml")
+	assert.Nil(t, os.WriteFile(file, []byte("openapi: 3.0.0\ninfo: {}"), 0644))
+	_, err = LoadOpenAPISpec(file)
+	assert.ErrorContains(t, err, "no paths found in the OpenAPI spec")
+
+	assert.Nil(t, os.WriteFile(file, []byte(swagger2Spec), 0644))
+	spec, err := LoadOpenAPISpec(file)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/api"}, spec.basePaths)
+}
@@ -0,0 +1,71 @@
+package runner
+
+import (
+	"fmt"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+// runVerifyPlugins verifies the side effects of the test case after the response is verified
+func (r *simpleTestCaseRunner) runVerifyPlugins(testcase *testing.TestCase) (err error) {
+	plugins := testcase.Expect.Plugins
+	if plugins == nil {
+		return
+	}
+
+	if plugins.AMQP != nil {
+		if err = r.verifyAMQP(plugins.AMQP); err != nil {
+			err = fmt.Errorf("case: %s, failed to verify the AMQP queue, %v", testcase.Name, err)
+			return
+		}
+	}
+
+	if plugins.Elasticsearch != nil {
+		if err = r.verifyElasticsearch(plugins.Elasticsearch); err != nil {
+			err = fmt.Errorf("case: %s, failed to verify the Elasticsearch index, %v", testcase.Name, err)
+			return
+		}
+	}
+
+	if plugins.S3 != nil {
+		if err = r.verifyS3(plugins.S3); err != nil {
+			err = fmt.Errorf("case: %s, failed to verify the S3 object, %v", testcase.Name, err)
+			return
+		}
+	}
+
+	if plugins.SSH != nil {
+		if err = r.verifySSH(plugins.SSH); err != nil {
+			err = fmt.Errorf("case: %s, failed to verify by SSH, %v", testcase.Name, err)
+			return
+		}
+	}
+
+	if plugins.Redis != nil {
+		if err = r.verifyRedis(plugins.Redis); err != nil {
+			err = fmt.Errorf("case: %s, failed to verify the Redis entries, %v", testcase.Name, err)
+			return
+		}
+	}
+
+	if plugins.SQL != nil {
+		if err = r.verifySQL(plugins.SQL); err != nil {
+			err = fmt.Errorf("case: %s, failed to verify the SQL rows, %v", testcase.Name, err)
+			return
+		}
+	}
+
+	if len(plugins.Files) > 0 {
+		if err = r.runFileSteps(plugins.Files, fileStepActionCheck); err != nil {
+			err = fmt.Errorf("case: %s, failed to verify the files, %v", testcase.Name, err)
+			return
+		}
+	}
+
+	if plugins.Email != nil {
+		if err = r.verifyEmail(plugins.Email); err != nil {
+			err = fmt.Errorf("case: %s, failed to verify the email, %v", testcase.Name, err)
+		}
+	}
+	return
+}
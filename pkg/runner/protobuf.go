@@ -0,0 +1,49 @@
+package runner
+
+import (
+	"fmt"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	fakeruntime "github.com/linuxsuren/go-fake-runtime"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// decodeProtobuf decodes the binary protobuf body to be JSON, the repeated and map fields are arrays and
+// objects, so they could be verified like the other JSON bodies
+func decodeProtobuf(execer fakeruntime.Execer, expect *testing.ProtobufExpect, data []byte) (result []byte, err error) {
+	var files *protoregistry.Files
+	switch {
+	case expect.DescriptorSet != "":
+		files, err = loadDescriptorSet(expect.DescriptorSet)
+	case expect.ProtoFile != "":
+		files, err = compileProtoFile(execer, expect.ProtoFile, expect.ImportPaths)
+	default:
+		err = fmt.Errorf("the proto file or the descriptor set of the protobuf message is required")
+	}
+	if err != nil {
+		return
+	}
+
+	var desc protoreflect.Descriptor
+	if desc, err = files.FindDescriptorByName(protoreflect.FullName(expect.Message)); err != nil {
+		err = fmt.Errorf("cannot find message %s, %v", expect.Message, err)
+		return
+	}
+	msgDesc, ok := desc.(protoreflect.MessageDescriptor)
+	if !ok {
+		err = fmt.Errorf("%s is not a message", expect.Message)
+		return
+	}
+
+	msg := dynamicpb.NewMessage(msgDesc)
+	if err = proto.Unmarshal(data, msg); err != nil {
+		err = fmt.Errorf("failed to decode the body as %s, %v", expect.Message, err)
+		return
+	}
+	result, err = protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(msg)
+	return
+}
@@ -0,0 +1,137 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/h2non/gock"
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	fakeruntime "github.com/linuxsuren/go-fake-runtime"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestDecodeProtobuf(t *testing.T) {
+	defer gock.Off()
+
+	countsField := newFieldDescriptor("counts", 3, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".sample.Order.CountsEntry")
+	countsField.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	tagsField := newFieldDescriptor("tags", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, "")
+	tagsField.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("order.proto"),
+		Package: proto.String("sample"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Order"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				newFieldDescriptor("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				tagsField,
+				countsField,
+			},
+			NestedType: []*descriptorpb.DescriptorProto{{
+				Name: proto.String("CountsEntry"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					newFieldDescriptor("key", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+					newFieldDescriptor("value", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32, ""),
+				},
+				Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+			}},
+		}},
+	}, nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	descSet, err := proto.Marshal(&descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{protodesc.ToFileDescriptorProto(file)},
+	})
+	assert.Nil(t, err)
+	descFile := filepath.Join(t.TempDir(), "order.pb")
+	assert.Nil(t, os.WriteFile(descFile, descSet, 0644))
+
+	orderDesc := file.Messages().ByName("Order")
+	order := dynamicpb.NewMessage(orderDesc)
+	order.Set(orderDesc.Fields().ByName("id"), protoreflect.ValueOfString("1"))
+	tags := order.Mutable(orderDesc.Fields().ByName("tags")).List()
+	tags.Append(protoreflect.ValueOfString("book"))
+	tags.Append(protoreflect.ValueOfString("pen"))
+	counts := order.Mutable(orderDesc.Fields().ByName("counts")).Map()
+	counts.Set(protoreflect.ValueOfString("book").MapKey(), protoreflect.ValueOfInt32(2))
+	body, err := proto.Marshal(order)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name      string
+		execer    fakeruntime.Execer
+		expect    atest.Response
+		expectErr string
+	}{{
+		name: "repeated and map fields",
+		expect: atest.Response{
+			Protobuf:         &atest.ProtobufExpect{Message: "sample.Order", DescriptorSet: descFile},
+			BodyFieldsExpect: map[string]interface{}{"id": "1", "tags": []interface{}{"book", "pen"}},
+			Verify:           []string{"len(data.tags) == 2", "data.counts.book == 2"},
+			Arrays:           map[string]*atest.ArrayExpect{"$.tags": {Contains: []interface{}{"pen"}}},
+		},
+	}, {
+		name: "wrong field value",
+		expect: atest.Response{
+			Protobuf: &atest.ProtobufExpect{Message: "sample.Order", DescriptorSet: descFile},
+			Verify:   []string{"data.counts.book == 3"},
+		},
+		expectErr: "failed to verify: data.counts.book == 3",
+	}, {
+		name: "unknown message",
+		expect: atest.Response{
+			Protobuf: &atest.ProtobufExpect{Message: "sample.Item", DescriptorSet: descFile},
+		},
+		expectErr: "case: protobuf, cannot find message sample.Item",
+	}, {
+		name: "failed to compile the proto file",
+		execer: fakeruntime.FakeExecer{
+			ExpectError:  errors.New("fake"),
+			ExpectOutput: "order.proto: File not found.",
+		},
+		expect: atest.Response{
+			Protobuf: &atest.ProtobufExpect{Message: "sample.Order", ProtoFile: "order.proto"},
+		},
+		expectErr: "case: protobuf, failed to compile order.proto, fake, order.proto: File not found.",
+	}, {
+		name: "no descriptor",
+		expect: atest.Response{
+			Protobuf: &atest.ProtobufExpect{Message: "sample.Order"},
+		},
+		expectErr: "case: protobuf, the proto file or the descriptor set of the protobuf message is required",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gock.New("http://localhost").Get("/orders/1").Reply(http.StatusOK).
+				SetHeader("Content-Type", "application/x-protobuf").Body(bytes.NewReader(body))
+
+			runner := NewSimpleTestCaseRunner()
+			if tt.execer != nil {
+				runner.WithExecer(tt.execer)
+			}
+			_, err := runner.RunTestCase(&atest.TestCase{
+				Name:    "protobuf",
+				Request: atest.Request{API: "http://localhost/orders/1"},
+				Expect:  tt.expect,
+			}, nil, context.TODO())
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectErr)
+			}
+		})
+	}
+}
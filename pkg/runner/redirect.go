@@ -0,0 +1,73 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+// maxRedirects is the same as the default policy of the HTTP client
+const maxRedirects = 10
+
+// redirectChain records the redirections which are followed by the HTTP client
+type redirectChain struct {
+	statusCodes []int
+	locations   []*url.URL
+}
+
+func (c *redirectChain) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return errors.New("stopped after 10 redirects")
+	}
+	if req.Response != nil {
+		c.statusCodes = append(c.statusCodes, req.Response.StatusCode)
+	}
+	c.locations = append(c.locations, req.URL)
+	return nil
+}
+
+// expect verifies the status codes and the locations of the redirections, and the URL of the last request
+func (c *redirectChain) expect(name string, expect *testing.RedirectExpect, finalURL *url.URL) (err error) {
+	if expect == nil {
+		return
+	}
+
+	statusCodes := c.statusCodes
+	if statusCodes == nil {
+		statusCodes = []int{}
+	}
+	if expect.StatusCodes != nil && !reflect.DeepEqual(expect.StatusCodes, statusCodes) {
+		err = fmt.Errorf("case: %s, expect the redirect status codes %v, actual %v", name, expect.StatusCodes, statusCodes)
+		return
+	}
+
+	if expect.Locations != nil {
+		matched := len(expect.Locations) == len(c.locations)
+		for i := 0; matched && i < len(c.locations); i++ {
+			matched = urlMatch(expect.Locations[i], c.locations[i])
+		}
+		if !matched {
+			err = fmt.Errorf("case: %s, expect the redirect locations %v, actual %v", name, expect.Locations, c.locations)
+			return
+		}
+	}
+
+	if expect.FinalURL != "" && !urlMatch(expect.FinalURL, finalURL) {
+		err = fmt.Errorf("case: %s, expect the final URL %s, actual %s", name, expect.FinalURL, finalURL)
+	}
+	return
+}
+
+// urlMatch compares the path and query only if the expected URL starts with a slash, the host of the
+// test environment is not fixed
+func urlMatch(expect string, actual *url.URL) bool {
+	if strings.HasPrefix(expect, "/") {
+		return expect == actual.RequestURI()
+	}
+	return expect == actual.String()
+}
@@ -0,0 +1,81 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpectRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/s/abc", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/login?next=%2Fdashboard", http.StatusFound)
+	})
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/dashboard", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		api       string
+		expect    *atest.RedirectExpect
+		expectErr string
+	}{{
+		name: "expected chain",
+		api:  server.URL + "/s/abc",
+		expect: &atest.RedirectExpect{
+			StatusCodes: []int{http.StatusFound, http.StatusMovedPermanently},
+			Locations:   []string{"/login?next=%2Fdashboard", server.URL + "/dashboard"},
+			FinalURL:    server.URL + "/dashboard",
+		},
+	}, {
+		name:   "no redirection",
+		api:    server.URL + "/dashboard",
+		expect: &atest.RedirectExpect{StatusCodes: []int{}, FinalURL: "/dashboard"},
+	}, {
+		name:      "unexpected status codes",
+		api:       server.URL + "/s/abc",
+		expect:    &atest.RedirectExpect{StatusCodes: []int{http.StatusFound}},
+		expectErr: "case: redirect, expect the redirect status codes [302], actual [302 301]",
+	}, {
+		name:      "unexpected locations",
+		api:       server.URL + "/s/abc",
+		expect:    &atest.RedirectExpect{Locations: []string{"/login", "/dashboard"}},
+		expectErr: "case: redirect, expect the redirect locations [/login /dashboard], actual [" + server.URL + "/login?next=%2Fdashboard",
+	}, {
+		name:      "unexpected final URL",
+		api:       server.URL + "/s/abc",
+		expect:    &atest.RedirectExpect{FinalURL: "/login"},
+		expectErr: "case: redirect, expect the final URL /login, actual " + server.URL + "/dashboard",
+	}, {
+		name:      "too many redirections",
+		api:       server.URL + "/loop",
+		expectErr: "stopped after 10 redirects",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSimpleTestCaseRunner().RunTestCase(&atest.TestCase{
+				Name:    "redirect",
+				Request: atest.Request{API: tt.api},
+				Expect:  atest.Response{Redirects: tt.expect},
+			}, nil, context.TODO())
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expectErr)
+			}
+		})
+	}
+}
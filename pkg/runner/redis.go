@@ -0,0 +1,90 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// defaultRedisAddress is the address of the server if it's not set
+	defaultRedisAddress = "localhost:6379"
+	// defaultRedisTimeout is the timeout of the checks if it's not set
+	defaultRedisTimeout = 10 * time.Second
+)
+
+// verifyRedis runs the checks in order, it stops at the first unexpected entry
+func (r *simpleTestCaseRunner) verifyRedis(verification *testing.RedisVerification) (err error) {
+	timeout := defaultRedisTimeout
+	if verification.Timeout != "" {
+		if timeout, err = time.ParseDuration(verification.Timeout); err != nil {
+			return
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	address := verification.Address
+	if address == "" {
+		address = defaultRedisAddress
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr:        address,
+		Password:    verification.Password,
+		DB:          verification.DB,
+		DialTimeout: timeout,
+	})
+	defer client.Close()
+
+	for _, check := range verification.Checks {
+		r.log.Info("start to check the key %s of Redis\n", check.Key)
+		if err = checkRedis(ctx, client, check); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// checkRedis runs the command, then compares the existence and the value of the entry
+func checkRedis(ctx context.Context, client *redis.Client, check testing.RedisCheck) (err error) {
+	var value string
+	exists := true
+	command := strings.ToUpper(check.Command)
+	switch command {
+	case "", "GET":
+		command = "GET"
+		value, err = client.Get(ctx, check.Key).Result()
+	case "HGET":
+		if check.Field == "" {
+			err = fmt.Errorf("the field of HGET %s is required", check.Key)
+			return
+		}
+		value, err = client.HGet(ctx, check.Key, check.Field).Result()
+	case "EXISTS":
+		var count int64
+		count, err = client.Exists(ctx, check.Key).Result()
+		exists = count > 0
+	default:
+		err = fmt.Errorf("unsupported command %q, only GET, EXISTS and HGET are supported", check.Command)
+		return
+	}
+
+	if errors.Is(err, redis.Nil) {
+		exists, err = false, nil
+	} else if err != nil {
+		return
+	}
+
+	target := strings.TrimSpace(strings.Join([]string{command, check.Key, check.Field}, " "))
+	if exists == check.Absent {
+		err = fmt.Errorf("%s, expect exists: %v, actual: %v", target, !check.Absent, exists)
+	} else if exists && check.Value != "" && check.Value != value {
+		err = fmt.Errorf("%s, expect value: %s, actual: %s", target, check.Value, value)
+	}
+	return
+}
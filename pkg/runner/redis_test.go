@@ -0,0 +1,181 @@
+package runner
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyRedis(t *testing.T) {
+	address := startFakeRedis(t)
+
+	tests := []struct {
+		name      string
+		checks    []atest.RedisCheck
+		timeout   string
+		expectErr string
+	}{{
+		name: "expected entries",
+		checks: []atest.RedisCheck{
+			{Key: "order:1", Value: "created"},
+			{Command: "exists", Key: "order:1"},
+			{Command: "HGET", Key: "user:1", Field: "name", Value: "rick"},
+			{Command: "HGET", Key: "user:1", Field: "age", Absent: true},
+			{Command: "EXISTS", Key: "order:2", Absent: true},
+		},
+	}, {
+		name:      "unexpected value",
+		checks:    []atest.RedisCheck{{Key: "order:1", Value: "deleted"}},
+		expectErr: "GET order:1, expect value: deleted, actual: created",
+	}, {
+		name:      "the key does not exist",
+		checks:    []atest.RedisCheck{{Key: "order:2"}},
+		expectErr: "GET order:2, expect exists: true, actual: false",
+	}, {
+		name:      "the key is not absent",
+		checks:    []atest.RedisCheck{{Command: "EXISTS", Key: "order:1", Absent: true}},
+		expectErr: "EXISTS order:1, expect exists: false, actual: true",
+	}, {
+		name:      "the field is required",
+		checks:    []atest.RedisCheck{{Command: "HGET", Key: "user:1"}},
+		expectErr: "the field of HGET user:1 is required",
+	}, {
+		name:      "unsupported command",
+		checks:    []atest.RedisCheck{{Command: "DEL", Key: "order:1"}},
+		expectErr: `unsupported command "DEL"`,
+	}, {
+		name:      "invalid timeout",
+		timeout:   "fake",
+		expectErr: "invalid duration",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewSimpleTestCaseRunner().(*simpleTestCaseRunner)
+			err := r.verifyRedis(&atest.RedisVerification{
+				Address: address,
+				DB:      1,
+				Timeout: tt.timeout,
+				Checks:  tt.checks,
+			})
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expectErr)
+			}
+		})
+	}
+
+	t.Run("check before the request", func(t *testing.T) {
+		_, err := NewSimpleTestCaseRunner().RunTestCase(&atest.TestCase{
+			Prepare: atest.Prepare{
+				Redis: &atest.RedisVerification{
+					Address: address,
+					Checks:  []atest.RedisCheck{{Key: "order:1", Absent: true}},
+				},
+			},
+			Request: atest.Request{API: "http://localhost/orders"},
+		}, nil, context.TODO())
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), "before the request")
+		}
+	})
+}
+
+// startFakeRedis starts a server which only has the key order:1 and the hash user:1
+func startFakeRedis(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveFakeRedis(conn)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func serveFakeRedis(conn net.Conn) {
+	defer conn.Close()
+	values := map[string]string{"order:1": "created"}
+	hashes := map[string]map[string]string{"user:1": {"name": "rick"}}
+
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readRESPArray(reader)
+		if err != nil {
+			return
+		}
+
+		var reply string
+		switch strings.ToUpper(args[0]) {
+		case "HELLO":
+			reply = "-ERR unknown command 'HELLO'\r\n"
+		case "SELECT", "AUTH":
+			reply = "+OK\r\n"
+		case "GET":
+			value, ok := values[args[1]]
+			reply = bulkString(value, ok)
+		case "HGET":
+			value, ok := hashes[args[1]][args[2]]
+			reply = bulkString(value, ok)
+		case "EXISTS":
+			_, isValue := values[args[1]]
+			_, isHash := hashes[args[1]]
+			reply = ":0\r\n"
+			if isValue || isHash {
+				reply = ":1\r\n"
+			}
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		if _, err = io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+// readRESPArray reads a command which is an array of the bulk strings
+func readRESPArray(reader *bufio.Reader) (args []string, err error) {
+	var line string
+	if line, err = reader.ReadString('\n'); err != nil {
+		return
+	}
+	count, _ := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	for i := 0; i < count; i++ {
+		if line, err = reader.ReadString('\n'); err != nil {
+			return
+		}
+		size, _ := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		data := make([]byte, size+2)
+		if _, err = io.ReadFull(reader, data); err != nil {
+			return
+		}
+		args = append(args, string(data[:size]))
+	}
+	if len(args) == 0 {
+		err = fmt.Errorf("empty command")
+	}
+	return
+}
+
+func bulkString(value string, ok bool) string {
+	if !ok {
+		return "$-1\r\n"
+	}
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+}
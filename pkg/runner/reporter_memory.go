@@ -2,11 +2,13 @@ package runner
 
 import (
 	"sort"
+	"sync"
 	"time"
 )
 
 type memoryTestReporter struct {
 	records []*ReportRecord
+	mu      sync.RWMutex
 }
 
 // NewMemoryTestReporter creates a memory based test reporter
@@ -26,12 +28,16 @@ type ReportResultWithTotal struct {
 
 // PutRecord puts the record to memory
 func (r *memoryTestReporter) PutRecord(record *ReportRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.records = append(r.records, record)
 }
 
-// GetAllRecords returns all the records
+// GetAllRecords returns all the records, it's safe to be called during the run
 func (r *memoryTestReporter) GetAllRecords() []*ReportRecord {
-	return r.records
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]*ReportRecord{}, r.records...)
 }
 
 func getMaxAndMin(max, min, duration time.Duration) (time.Duration, time.Duration) {
@@ -48,7 +54,7 @@ func getMaxAndMin(max, min, duration time.Duration) (time.Duration, time.Duratio
 // ExportAllReportResults exports all the report results
 func (r *memoryTestReporter) ExportAllReportResults() (result ReportResultSlice, err error) {
 	resultWithTotal := map[string]*ReportResultWithTotal{}
-	for _, record := range r.records {
+	for _, record := range r.GetAllRecords() {
 		api := record.Method + " " + record.API
 		duration := record.Duration()
 
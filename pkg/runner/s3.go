@@ -0,0 +1,160 @@
+package runner
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+const (
+	// defaultS3Endpoint is the address of the storage if it's not set
+	defaultS3Endpoint = "https://s3.amazonaws.com"
+	// defaultS3Region is the region to sign the requests if it's not set
+	defaultS3Region = "us-east-1"
+	// s3EmptyPayloadHash is the SHA256 of the empty payload
+	s3EmptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// verifyS3 checks the existence of the object, and its checksum if it's set
+func (r *simpleTestCaseRunner) verifyS3(verification *testing.S3Verification) (err error) {
+	if verification.Bucket == "" || verification.Key == "" {
+		err = fmt.Errorf("both the bucket and key are required")
+		return
+	}
+
+	var newHash func() hash.Hash
+	var expectChecksum string
+	if verification.Checksum != "" {
+		if newHash, expectChecksum, err = parseChecksum(verification.Checksum); err != nil {
+			return
+		}
+	}
+
+	endpoint := strings.TrimSuffix(verification.Endpoint, "/")
+	if endpoint == "" {
+		endpoint = defaultS3Endpoint
+	}
+	region := verification.Region
+	if region == "" {
+		region = defaultS3Region
+	}
+	accessKey, secretKey := verification.AccessKey, verification.SecretKey
+	if accessKey == "" {
+		accessKey, secretKey = os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
+
+	// only the content is required to compute the checksum
+	method := http.MethodHead
+	if newHash != nil {
+		method = http.MethodGet
+	}
+
+	// the path-style address works with both AWS S3 and MinIO
+	var req *http.Request
+	if req, err = http.NewRequest(method, endpoint+"/"+verification.Bucket, nil); err != nil {
+		return
+	}
+	req.URL.Path = req.URL.Path + "/" + verification.Key
+	req.URL.RawPath = s3EscapePath(req.URL.Path)
+	if accessKey != "" {
+		signS3Request(req, accessKey, secretKey, region, time.Now())
+	}
+
+	r.log.Info("start to check the object %s\n", req.URL.String())
+	client := http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+	var resp *http.Response
+	if resp, err = client.Do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	exists := verification.Exists == nil || *verification.Exists
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		if exists {
+			err = fmt.Errorf("the object %s/%s does not exist", verification.Bucket, verification.Key)
+		}
+		return
+	case resp.StatusCode != http.StatusOK:
+		data, _ := io.ReadAll(resp.Body)
+		err = fmt.Errorf("unexpected status code %d, %s", resp.StatusCode, string(data))
+		return
+	case !exists:
+		err = fmt.Errorf("the object %s/%s exists", verification.Bucket, verification.Key)
+		return
+	}
+
+	if newHash != nil {
+		digest := newHash()
+		if _, err = io.Copy(digest, resp.Body); err != nil {
+			return
+		}
+		if actual := hex.EncodeToString(digest.Sum(nil)); actual != expectChecksum {
+			err = fmt.Errorf("expect checksum %s, actual %s", expectChecksum, actual)
+		}
+	}
+	return
+}
+
+// parseChecksum parses the checksum like sha256:<hex>, it's md5 without the prefix
+func parseChecksum(checksum string) (newHash func() hash.Hash, digest string, err error) {
+	algorithm, digest, ok := strings.Cut(checksum, ":")
+	if !ok {
+		algorithm, digest = "md5", checksum
+	}
+	digest = strings.ToLower(strings.TrimSpace(digest))
+
+	switch strings.ToLower(algorithm) {
+	case "md5":
+		newHash = md5.New
+	case "sha1":
+		newHash = sha1.New
+	case "sha256":
+		newHash = sha256.New
+	default:
+		err = fmt.Errorf("unsupported checksum algorithm %q", algorithm)
+	}
+	return
+}
+
+// signS3Request signs the request without payload by AWS Signature Version 4,
+// see also https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html
+func signS3Request(req *http.Request, accessKey, secretKey, region string, now time.Time) {
+	signAWSV4(req, accessKey, secretKey, region, "s3", s3EmptyPayloadHash, now)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3EscapePath escapes the path as the URI encoding of AWS, the slashes are kept
+func s3EscapePath(path string) string {
+	escaped := &strings.Builder{}
+	for _, b := range []byte(path) {
+		if ('A' <= b && b <= 'Z') || ('a' <= b && b <= 'z') || ('0' <= b && b <= '9') ||
+			b == '-' || b == '_' || b == '.' || b == '~' || b == '/' {
+			escaped.WriteByte(b)
+		} else {
+			fmt.Fprintf(escaped, "%%%02X", b)
+		}
+	}
+	return escaped.String()
+}
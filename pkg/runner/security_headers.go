@@ -0,0 +1,158 @@
+package runner
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// securityHeaderRule returns the violation of the response headers, it's empty if there is no violation
+type securityHeaderRule func(header http.Header, https bool) string
+
+// securityHeaderProfiles are the named policies of the security headers, the strict one is based on
+// the recommendations of the OWASP Secure Headers Project
+var securityHeaderProfiles = map[string][]securityHeaderRule{
+	"basic": {
+		requireHSTS(1, false),
+		requireNoSniff,
+		requireFrameProtection,
+	},
+	"strict": {
+		requireHSTS(31536000, true),
+		requireNoSniff,
+		requireFrameProtection,
+		requireCSP,
+		requireReferrerPolicy,
+		forbidHeader("X-Powered-By"),
+	},
+}
+
+// expectSecurityHeaders verifies the response headers against the profile, all the violations are reported
+func expectSecurityHeaders(name, profile string, resp *http.Response) (err error) {
+	if profile == "" || profile == "none" {
+		return
+	}
+
+	rules, ok := securityHeaderProfiles[profile]
+	if !ok {
+		err = fmt.Errorf("case: %s, unsupported security headers profile %q, only basic, strict and none are supported", name, profile)
+		return
+	}
+
+	https := resp.TLS != nil || (resp.Request != nil && resp.Request.URL.Scheme == "https")
+	var violations []string
+	for _, rule := range rules {
+		if violation := rule(resp.Header, https); violation != "" {
+			violations = append(violations, violation)
+		}
+	}
+	if len(violations) > 0 {
+		err = fmt.Errorf("case: %s, the security headers don't match the %s profile: %s", name, profile,
+			strings.Join(violations, "; "))
+	}
+	return
+}
+
+// requireHSTS requires the Strict-Transport-Security of the HTTPS responses, the browsers ignore it over HTTP
+func requireHSTS(minAge int, includeSubDomains bool) securityHeaderRule {
+	return func(header http.Header, https bool) string {
+		if !https {
+			return ""
+		}
+		val := header.Get("Strict-Transport-Security")
+		if val == "" {
+			return "Strict-Transport-Security is missing"
+		}
+
+		directives := map[string]string{}
+		for _, item := range strings.Split(val, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(item), "=")
+			directives[strings.ToLower(strings.TrimSpace(key))] = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+		if maxAge, err := strconv.Atoi(directives["max-age"]); err != nil || maxAge < minAge {
+			return fmt.Sprintf("expect the max-age of Strict-Transport-Security is not less than %d, actual %q", minAge, val)
+		}
+		if _, ok := directives["includesubdomains"]; includeSubDomains && !ok {
+			return fmt.Sprintf("expect Strict-Transport-Security has includeSubDomains, actual %q", val)
+		}
+		return ""
+	}
+}
+
+func requireNoSniff(header http.Header, _ bool) string {
+	if val := header.Get("X-Content-Type-Options"); !strings.EqualFold(strings.TrimSpace(val), "nosniff") {
+		return fmt.Sprintf("expect X-Content-Type-Options is nosniff, actual %q", val)
+	}
+	return ""
+}
+
+// requireFrameProtection requires the X-Frame-Options, or the frame-ancestors of the Content-Security-Policy
+func requireFrameProtection(header http.Header, _ bool) string {
+	if _, ok := cspDirectives(header)["frame-ancestors"]; ok {
+		return ""
+	}
+	switch val := strings.ToUpper(strings.TrimSpace(header.Get("X-Frame-Options"))); val {
+	case "DENY", "SAMEORIGIN":
+		return ""
+	case "":
+		return "X-Frame-Options or the frame-ancestors of Content-Security-Policy is missing"
+	default:
+		return fmt.Sprintf("expect X-Frame-Options is DENY or SAMEORIGIN, actual %q", val)
+	}
+}
+
+// requireCSP requires the Content-Security-Policy which restricts the scripts, the inline and eval scripts are not allowed
+func requireCSP(header http.Header, _ bool) string {
+	directives := cspDirectives(header)
+	if len(directives) == 0 {
+		return "Content-Security-Policy is missing"
+	}
+
+	scriptSrc, ok := directives["script-src"]
+	if !ok {
+		if scriptSrc, ok = directives["default-src"]; !ok {
+			return "expect Content-Security-Policy has default-src or script-src"
+		}
+	}
+	for _, source := range strings.Fields(scriptSrc) {
+		if source == "'unsafe-inline'" || source == "'unsafe-eval'" {
+			return fmt.Sprintf("expect Content-Security-Policy does not allow %s scripts", source)
+		}
+	}
+	return ""
+}
+
+// cspDirectives returns the directives of the Content-Security-Policy, the names are in lower case
+func cspDirectives(header http.Header) (directives map[string]string) {
+	directives = map[string]string{}
+	for _, val := range header.Values("Content-Security-Policy") {
+		for _, item := range strings.Split(val, ";") {
+			if fields := strings.Fields(item); len(fields) > 0 {
+				directives[strings.ToLower(fields[0])] = strings.Join(fields[1:], " ")
+			}
+		}
+	}
+	return
+}
+
+func requireReferrerPolicy(header http.Header, _ bool) string {
+	val := header.Get("Referrer-Policy")
+	// the last one is used by the browsers if there are many
+	policies := strings.Split(val, ",")
+	switch strings.ToLower(strings.TrimSpace(policies[len(policies)-1])) {
+	case "no-referrer", "same-origin", "strict-origin", "strict-origin-when-cross-origin":
+		return ""
+	}
+	return fmt.Sprintf("expect Referrer-Policy is no-referrer, same-origin, strict-origin or strict-origin-when-cross-origin, actual %q", val)
+}
+
+// forbidHeader reports the header which discloses the details of the server
+func forbidHeader(key string) securityHeaderRule {
+	return func(header http.Header, _ bool) string {
+		if val := header.Get(key); val != "" {
+			return fmt.Sprintf("expect %s is absent, actual %q", key, val)
+		}
+		return ""
+	}
+}
@@ -0,0 +1,104 @@
+package runner
+
+import (
+	"crypto/tls"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpectSecurityHeaders(t *testing.T) {
+	strictHeader := http.Header{
+		"Strict-Transport-Security": []string{"max-age=63072000; includeSubDomains; preload"},
+		"X-Content-Type-Options":    []string{"nosniff"},
+		"Content-Security-Policy":   []string{"default-src 'self'; frame-ancestors 'none'"},
+		"Referrer-Policy":           []string{"no-referrer, strict-origin-when-cross-origin"},
+	}
+
+	tests := []struct {
+		name      string
+		profile   string
+		header    http.Header
+		https     bool
+		expectErr string
+	}{{
+		name:    "no profile",
+		profile: "",
+	}, {
+		name:    "none",
+		profile: "none",
+	}, {
+		name:    "strict",
+		profile: "strict",
+		header:  strictHeader,
+		https:   true,
+	}, {
+		name:    "basic over HTTP",
+		profile: "basic",
+		header: http.Header{
+			"X-Content-Type-Options": []string{"NoSniff"},
+			"X-Frame-Options":        []string{"sameorigin"},
+		},
+	}, {
+		name:      "basic without HSTS",
+		profile:   "basic",
+		header:    http.Header{"X-Content-Type-Options": []string{"nosniff"}, "X-Frame-Options": []string{"DENY"}},
+		https:     true,
+		expectErr: "case: security, the security headers don't match the basic profile: Strict-Transport-Security is missing",
+	}, {
+		name:    "strict with all violations",
+		profile: "strict",
+		header: http.Header{
+			"Strict-Transport-Security": []string{"max-age=3600"},
+			"X-Frame-Options":           []string{"ALLOW-FROM https://example.com"},
+			"Content-Security-Policy":   []string{"default-src 'self'; script-src 'self' 'unsafe-inline'"},
+			"Referrer-Policy":           []string{"unsafe-url"},
+			"X-Powered-By":              []string{"Express"},
+		},
+		https: true,
+		expectErr: `case: security, the security headers don't match the strict profile: ` +
+			`expect the max-age of Strict-Transport-Security is not less than 31536000, actual "max-age=3600"; ` +
+			`expect X-Content-Type-Options is nosniff, actual ""; ` +
+			`expect X-Frame-Options is DENY or SAMEORIGIN, actual "ALLOW-FROM HTTPS://EXAMPLE.COM"; ` +
+			`expect Content-Security-Policy does not allow 'unsafe-inline' scripts; ` +
+			`expect Referrer-Policy is no-referrer, same-origin, strict-origin or strict-origin-when-cross-origin, actual "unsafe-url"; ` +
+			`expect X-Powered-By is absent, actual "Express"`,
+	}, {
+		name:    "strict without includeSubDomains and CSP",
+		profile: "strict",
+		header: http.Header{
+			"Strict-Transport-Security": []string{"max-age=31536000"},
+			"X-Content-Type-Options":    []string{"nosniff"},
+			"X-Frame-Options":           []string{"DENY"},
+			"Referrer-Policy":           []string{"same-origin"},
+		},
+		https: true,
+		expectErr: `case: security, the security headers don't match the strict profile: ` +
+			`expect Strict-Transport-Security has includeSubDomains, actual "max-age=31536000"; ` +
+			`Content-Security-Policy is missing`,
+	}, {
+		name:    "basic without any header",
+		profile: "basic",
+		expectErr: `case: security, the security headers don't match the basic profile: expect X-Content-Type-Options is nosniff, actual ""; ` +
+			`X-Frame-Options or the frame-ancestors of Content-Security-Policy is missing`,
+	}, {
+		name:      "unknown profile",
+		profile:   "paranoid",
+		expectErr: `case: security, unsupported security headers profile "paranoid", only basic, strict and none are supported`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Header: tt.header}
+			if tt.https {
+				resp.TLS = &tls.ConnectionState{}
+			}
+			err := expectSecurityHeaders("security", tt.profile, resp)
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expectErr)
+			}
+		})
+	}
+}
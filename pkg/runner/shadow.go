@@ -0,0 +1,151 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ShadowDiff represents the differences between the responses of the primary and shadow hosts
+type ShadowDiff struct {
+	Name        string
+	Differences []string
+}
+
+// DiffRecords compares the responses of the records semantically. The JSON bodies are compared field by field,
+// the fields match the ignore rules are skipped. A rule is a slash separated path, and `*` matches any segment,
+// such as: data/*/updatedAt
+func DiffRecords(primary, shadow *ReportRecord, ignores []string) (differences []string) {
+	if primary.StatusCode != shadow.StatusCode {
+		differences = append(differences, fmt.Sprintf("status code: %d != %d", primary.StatusCode, shadow.StatusCode))
+	}
+
+	var primaryBody, shadowBody interface{}
+	if json.Unmarshal([]byte(primary.Body), &primaryBody) != nil || json.Unmarshal([]byte(shadow.Body), &shadowBody) != nil {
+		if primary.Body != shadow.Body && !isIgnored("", ignores) {
+			differences = append(differences, "body: the non-JSON bodies are different")
+		}
+		return
+	}
+	differences = append(differences, valueDiffer{left: "primary", right: "shadow", ignores: ignores}.diff("", primaryBody, shadowBody)...)
+	return
+}
+
+// valueDiffer compares the decoded JSON values, the sides are named in the differences of the missing fields
+type valueDiffer struct {
+	left    string
+	right   string
+	ignores []string
+}
+
+func (d valueDiffer) diff(fieldPath string, primary, shadow interface{}) (differences []string) {
+	ignores := d.ignores
+	if isIgnored(fieldPath, ignores) {
+		return
+	}
+
+	name := fieldPath
+	if name == "" {
+		name = "body"
+	}
+
+	switch primaryVal := primary.(type) {
+	case map[string]interface{}:
+		shadowVal, ok := shadow.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		keys := map[string]bool{}
+		for key := range primaryVal {
+			keys[key] = true
+		}
+		for key := range shadowVal {
+			keys[key] = true
+		}
+		var sortedKeys []string
+		for key := range keys {
+			sortedKeys = append(sortedKeys, key)
+		}
+		sort.Strings(sortedKeys)
+
+		for _, key := range sortedKeys {
+			childPath := joinFieldPath(fieldPath, key)
+			primaryItem, primaryOK := primaryVal[key]
+			shadowItem, shadowOK := shadowVal[key]
+			switch {
+			case isIgnored(childPath, ignores):
+			case !shadowOK:
+				differences = append(differences, childPath+": missing in the "+d.right)
+			case !primaryOK:
+				differences = append(differences, childPath+": missing in the "+d.left)
+			default:
+				differences = append(differences, d.diff(childPath, primaryItem, shadowItem)...)
+			}
+		}
+		return
+	case []interface{}:
+		shadowVal, ok := shadow.([]interface{})
+		if !ok {
+			break
+		}
+
+		if len(primaryVal) != len(shadowVal) {
+			differences = append(differences, fmt.Sprintf("%s: length %d != %d", name, len(primaryVal), len(shadowVal)))
+			return
+		}
+		for i := range primaryVal {
+			differences = append(differences, d.diff(joinFieldPath(fieldPath, strconv.Itoa(i)), primaryVal[i], shadowVal[i])...)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(primary, shadow) {
+		differences = append(differences, fmt.Sprintf("%s: %s != %s", name, toJSON(primary), toJSON(shadow)))
+	}
+	return
+}
+
+func joinFieldPath(parent, child string) string {
+	if parent == "" {
+		return child
+	}
+	return parent + "/" + child
+}
+
+func isIgnored(fieldPath string, ignores []string) bool {
+	for _, rule := range ignores {
+		if ok, _ := path.Match(rule, fieldPath); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func toJSON(val interface{}) string {
+	data, _ := json.Marshal(val)
+	return string(data)
+}
+
+// WriteShadowReport writes the differences of the test cases
+func WriteShadowReport(writer io.Writer, diffs []ShadowDiff) (err error) {
+	buf := new(strings.Builder)
+	if len(diffs) == 0 {
+		buf.WriteString("no differences found between the primary and shadow\n")
+	} else {
+		buf.WriteString(fmt.Sprintf("found differences of %d test cases between the primary and shadow:\n", len(diffs)))
+	}
+	for _, diff := range diffs {
+		buf.WriteString(diff.Name + ":\n")
+		for _, difference := range diff.Differences {
+			buf.WriteString("  " + difference + "\n")
+		}
+	}
+	_, err = io.WriteString(writer, buf.String())
+	return
+}
@@ -0,0 +1,77 @@
+package runner
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffRecords(t *testing.T) {
+	tests := []struct {
+		name    string
+		primary *ReportRecord
+		shadow  *ReportRecord
+		ignores []string
+		expect  []string
+	}{{
+		name:    "same",
+		primary: &ReportRecord{StatusCode: 200, Body: `{"name": "a", "tags": [1, 2]}`},
+		shadow:  &ReportRecord{StatusCode: 200, Body: `{"tags": [1, 2], "name": "a"}`},
+	}, {
+		name:    "different status code",
+		primary: &ReportRecord{StatusCode: 200, Body: `{}`},
+		shadow:  &ReportRecord{StatusCode: 500, Body: `{}`},
+		expect:  []string{"status code: 200 != 500"},
+	}, {
+		name:    "different fields",
+		primary: &ReportRecord{StatusCode: 200, Body: `{"name": "a", "age": 1, "data": [{"id": 1}]}`},
+		shadow:  &ReportRecord{StatusCode: 200, Body: `{"name": "b", "role": "admin", "data": [{"id": "1"}]}`},
+		expect: []string{
+			"age: missing in the shadow",
+			`data/0/id: 1 != "1"`,
+			`name: "a" != "b"`,
+			"role: missing in the primary",
+		},
+	}, {
+		name:    "different length",
+		primary: &ReportRecord{Body: `{"data": [1, 2]}`},
+		shadow:  &ReportRecord{Body: `{"data": [1]}`},
+		expect:  []string{"data: length 2 != 1"},
+	}, {
+		name:    "ignored fields",
+		primary: &ReportRecord{Body: `{"data": [{"id": 1, "updatedAt": "a"}], "requestId": "a"}`},
+		shadow:  &ReportRecord{Body: `{"data": [{"id": 1, "updatedAt": "b"}], "requestId": "b"}`},
+		ignores: []string{"data/*/updatedAt", "requestId"},
+	}, {
+		name:    "different root",
+		primary: &ReportRecord{Body: `[1]`},
+		shadow:  &ReportRecord{Body: `{}`},
+		expect:  []string{"body: [1] != {}"},
+	}, {
+		name:    "non-JSON",
+		primary: &ReportRecord{Body: `hello`},
+		shadow:  &ReportRecord{Body: `world`},
+		expect:  []string{"body: the non-JSON bodies are different"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, DiffRecords(tt.primary, tt.shadow, tt.ignores))
+		})
+	}
+}
+
+func TestWriteShadowReport(t *testing.T) {
+	buf := new(bytes.Buffer)
+	err := WriteShadowReport(buf, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "no differences found between the primary and shadow\n", buf.String())
+
+	buf.Reset()
+	err = WriteShadowReport(buf, []ShadowDiff{{Name: "foo", Differences: []string{"status code: 200 != 500"}}})
+	assert.Nil(t, err)
+	assert.Equal(t, `found differences of 1 test cases between the primary and shadow:
+foo:
+  status code: 200 != 500
+`, buf.String())
+}
@@ -0,0 +1,278 @@
+package runner
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	fakeruntime "github.com/linuxsuren/go-fake-runtime"
+)
+
+// RequestSigner signs the HTTP request, the body is passed as bytes since the request body can be read only once
+type RequestSigner interface {
+	Sign(request *http.Request, body []byte, signing *testing.Signing) (*http.Request, error)
+}
+
+const (
+	signerSigV4 = "sigv4"
+	signerHMAC  = "hmac"
+	signerExec  = "exec"
+)
+
+var signers = map[string]RequestSigner{}
+
+// RegisterRequestSigner registers a signer with the type name, it overrides the built-in one of the same name
+func RegisterRequestSigner(name string, signer RequestSigner) {
+	signers[name] = signer
+}
+
+// GetRequestSignerNames returns all the registered signer names
+func GetRequestSignerNames() (names []string) {
+	for name := range signers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// signRequest signs the request by the signer of the type, the exec signer runs the command by the execer of the runner
+func (r *simpleTestCaseRunner) signRequest(request *http.Request, body []byte, signing *testing.Signing) (*http.Request, error) {
+	signer, ok := signers[signing.Type]
+	if signing.Type == signerExec {
+		signer, ok = &execSigner{execer: r.execer}, true
+	}
+	if !ok {
+		return nil, fmt.Errorf("not supported signer: '%s', supported: %v", signing.Type,
+			append(GetRequestSignerNames(), signerExec))
+	}
+	return signer.Sign(request, body, signing)
+}
+
+// sigV4Signer signs the request by AWS Signature Version 4, the options are region, service and sessionToken
+type sigV4Signer struct {
+	now func() time.Time
+}
+
+func (s *sigV4Signer) Sign(request *http.Request, body []byte, signing *testing.Signing) (*http.Request, error) {
+	region, service := signing.Options["region"], signing.Options["service"]
+	if region == "" || service == "" {
+		return nil, fmt.Errorf("both the region and service options are required by %s", signerSigV4)
+	}
+
+	request = request.Clone(request.Context())
+	if token := signing.Options["sessionToken"]; token != "" {
+		request.Header.Set("X-Amz-Security-Token", token)
+	}
+	payloadHash := sha256.Sum256(body)
+	signAWSV4(request, signing.Key, signing.Secret, region, service, hex.EncodeToString(payloadHash[:]), s.now())
+	return request, nil
+}
+
+// hmacSigner puts the timestamp and the HMAC signature of the request in the headers
+type hmacSigner struct {
+	now func() time.Time
+}
+
+// defaultHMACStringToSign is the template of the signed content if it's not set by the option stringToSign
+const defaultHMACStringToSign = "{{.method}}\n{{.uri}}\n{{.timestamp}}\n{{.body}}"
+
+func (s *hmacSigner) Sign(request *http.Request, body []byte, signing *testing.Signing) (signed *http.Request, err error) {
+	options := signing.Options
+	var newHash func() hash.Hash
+	switch algorithm := emptyThenDefault(options["algorithm"], "sha256"); algorithm {
+	case "sha1":
+		newHash = sha1.New
+	case "sha256":
+		newHash = sha256.New
+	case "sha512":
+		newHash = sha512.New
+	default:
+		err = fmt.Errorf("unsupported HMAC algorithm %q", algorithm)
+		return
+	}
+
+	// the content is not escaped, and the whitespaces are kept
+	var tpl *template.Template
+	if tpl, err = template.New("stringToSign").Parse(emptyThenDefault(options["stringToSign"], defaultHMACStringToSign)); err != nil {
+		return
+	}
+
+	timestamp := strconv.FormatInt(s.now().Unix(), 10)
+	mac := hmac.New(newHash, []byte(signing.Secret))
+	if err = tpl.Execute(mac, map[string]string{
+		"method":    request.Method,
+		"uri":       request.URL.RequestURI(),
+		"host":      request.URL.Host,
+		"timestamp": timestamp,
+		"body":      string(body),
+	}); err != nil {
+		return
+	}
+	var signature string
+	switch encoding := emptyThenDefault(options["encoding"], "hex"); encoding {
+	case "hex":
+		signature = hex.EncodeToString(mac.Sum(nil))
+	case "base64":
+		signature = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	default:
+		err = fmt.Errorf("unsupported signature encoding %q", encoding)
+		return
+	}
+
+	signed = request.Clone(request.Context())
+	signed.Header.Set(emptyThenDefault(options["timestampHeader"], "X-Timestamp"), timestamp)
+	signed.Header.Set(emptyThenDefault(options["header"], "X-Signature"), signature)
+	if signing.Key != "" {
+		signed.Header.Set(emptyThenDefault(options["keyHeader"], "X-Key-Id"), signing.Key)
+	}
+	return
+}
+
+// signingRequest is the request which is exchanged with the command of the exec signer
+type signingRequest struct {
+	Method string            `json:"method,omitempty"`
+	URL    string            `json:"url,omitempty"`
+	Header map[string]string `json:"header,omitempty"`
+	Body   string            `json:"body,omitempty"`
+}
+
+// execSigner passes the request to the command by a JSON file, the command writes the signed request to the stdout.
+// The fields of the signed request are optional, the headers are added to the original ones
+type execSigner struct {
+	execer fakeruntime.Execer
+}
+
+func (s *execSigner) Sign(request *http.Request, body []byte, signing *testing.Signing) (signed *http.Request, err error) {
+	if signing.Command == "" {
+		err = fmt.Errorf("the command is required by the %s signer", signerExec)
+		return
+	}
+
+	origin := signingRequest{
+		Method: request.Method,
+		URL:    request.URL.String(),
+		Header: map[string]string{},
+		Body:   string(body),
+	}
+	for key := range request.Header {
+		origin.Header[key] = request.Header.Get(key)
+	}
+
+	var file *os.File
+	if file, err = os.CreateTemp("", "atest-signing-*.json"); err != nil {
+		return
+	}
+	defer os.Remove(file.Name())
+	err = json.NewEncoder(file).Encode(origin)
+	_ = file.Close()
+	if err != nil {
+		return
+	}
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	if err = s.execer.RunCommandWithBuffer(signing.Command, "", stdout, stderr,
+		append(signing.Args, file.Name())...); err != nil {
+		err = fmt.Errorf("failed to sign the request by %s, %v, %s", signing.Command, err, stderr.String())
+		return
+	}
+
+	result := signingRequest{}
+	if err = json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		err = fmt.Errorf("the output of %s is not a valid request, %v", signing.Command, err)
+		return
+	}
+
+	signed = request.Clone(request.Context())
+	if result.Method != "" {
+		signed.Method = result.Method
+	}
+	if result.URL != "" {
+		if signed.URL, err = signed.URL.Parse(result.URL); err != nil {
+			return
+		}
+		signed.Host = signed.URL.Host
+	}
+	for key, val := range result.Header {
+		signed.Header.Set(key, val)
+	}
+	if result.Body != "" && result.Body != origin.Body {
+		signed.Body = io.NopCloser(strings.NewReader(result.Body))
+		signed.ContentLength = int64(len(result.Body))
+	}
+	return
+}
+
+// signAWSV4 signs the request by AWS Signature Version 4 with the hash of the payload,
+// see also https://docs.aws.amazon.com/IAM/latest/UserGuide/create-signed-request.html
+func signAWSV4(req *http.Request, accessKey, secretKey, region, service, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for key, values := range req.Header {
+		headers[strings.ToLower(key)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	var names []string
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	canonicalHeaders := &strings.Builder{}
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := []byte("AWS4" + secretKey)
+	for _, item := range []string{date, region, service, "aws4_request"} {
+		key = hmacSHA256(key, item)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+func emptyThenDefault(val, defVal string) string {
+	if val == "" {
+		val = defVal
+	}
+	return val
+}
+
+func init() {
+	RegisterRequestSigner(signerSigV4, &sigV4Signer{now: time.Now})
+	RegisterRequestSigner(signerHMAC, &hmacSigner{now: time.Now})
+}
@@ -0,0 +1,162 @@
+package runner
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/h2non/gock"
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	fakeruntime "github.com/linuxsuren/go-fake-runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignRequest(t *testing.T) {
+	now := func() time.Time {
+		return time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	}
+	expectHMAC := func(content string) string {
+		mac := hmac.New(sha256.New, []byte("secret"))
+		_, _ = mac.Write([]byte(content))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	tests := []struct {
+		name      string
+		signer    RequestSigner
+		signing   *atest.Signing
+		verify    func(t *testing.T, request *http.Request)
+		expectErr string
+	}{{
+		name:   "sigv4",
+		signer: &sigV4Signer{now: now},
+		signing: &atest.Signing{Type: "sigv4", Key: "AKID", Secret: "secret", Options: map[string]string{
+			"region": "us-east-1", "service": "execute-api", "sessionToken": "token",
+		}},
+		verify: func(t *testing.T, request *http.Request) {
+			assert.Equal(t, "20230601T000000Z", request.Header.Get("X-Amz-Date"))
+			assert.Equal(t, "token", request.Header.Get("X-Amz-Security-Token"))
+			assert.Equal(t, "9b0311f60790c3faa7a2d8281b1424a2e5679558261a43b521e50a187ac22bdb",
+				request.Header.Get("X-Amz-Content-Sha256"))
+			assert.Contains(t, request.Header.Get("Authorization"),
+				"AWS4-HMAC-SHA256 Credential=AKID/20230601/us-east-1/execute-api/aws4_request, "+
+					"SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date;x-amz-security-token, Signature=")
+		},
+	}, {
+		name:      "sigv4 without the region",
+		signer:    &sigV4Signer{now: now},
+		signing:   &atest.Signing{Type: "sigv4", Options: map[string]string{"service": "execute-api"}},
+		expectErr: "both the region and service options are required",
+	}, {
+		name:    "hmac",
+		signer:  &hmacSigner{now: now},
+		signing: &atest.Signing{Type: "hmac", Key: "rick", Secret: "secret"},
+		verify: func(t *testing.T, request *http.Request) {
+			assert.Equal(t, "1685577600", request.Header.Get("X-Timestamp"))
+			assert.Equal(t, "rick", request.Header.Get("X-Key-Id"))
+			assert.Equal(t, expectHMAC("POST\n/orders?id=1\n1685577600\n{\"name\":\"rick\"}"), request.Header.Get("X-Signature"))
+		},
+	}, {
+		name:   "hmac with the options",
+		signer: &hmacSigner{now: now},
+		signing: &atest.Signing{Type: "hmac", Secret: "secret", Options: map[string]string{
+			"header": "X-Sign", "encoding": "base64", "stringToSign": "{{.timestamp}}{{.body}}",
+		}},
+		verify: func(t *testing.T, request *http.Request) {
+			assert.Equal(t, "bpyJzgByyZrYCgqJyq9Tez4zjW5uvVqw/DKcptcLjJ4=", request.Header.Get("X-Sign"))
+			assert.Empty(t, request.Header.Get("X-Key-Id"))
+		},
+	}, {
+		name:      "unsupported HMAC algorithm",
+		signer:    &hmacSigner{now: now},
+		signing:   &atest.Signing{Type: "hmac", Options: map[string]string{"algorithm": "md5"}},
+		expectErr: `unsupported HMAC algorithm "md5"`,
+	}, {
+		name:      "unsupported encoding",
+		signer:    &hmacSigner{now: now},
+		signing:   &atest.Signing{Type: "hmac", Options: map[string]string{"encoding": "base32"}},
+		expectErr: `unsupported signature encoding "base32"`,
+	}, {
+		name:   "exec",
+		signer: &execSigner{execer: fakeruntime.DefaultExecer{}},
+		signing: &atest.Signing{Type: "exec", Command: "sh", Args: []string{"-c",
+			`grep -q '"body":"{\\"name\\":\\"rick\\"}"' "$0" && echo '{"url": "/orders?id=2", "header": {"X-Signature": "abc"}, "body": "signed"}'`}},
+		verify: func(t *testing.T, request *http.Request) {
+			assert.Equal(t, "http://localhost/orders?id=2", request.URL.String())
+			assert.Equal(t, "abc", request.Header.Get("X-Signature"))
+			assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+
+			data, err := io.ReadAll(request.Body)
+			assert.Nil(t, err)
+			assert.Equal(t, "signed", string(data))
+		},
+	}, {
+		name:      "exec without the command",
+		signer:    &execSigner{execer: fakeruntime.DefaultExecer{}},
+		signing:   &atest.Signing{Type: "exec"},
+		expectErr: "the command is required",
+	}, {
+		name:      "failed to run the command",
+		signer:    &execSigner{execer: fakeruntime.FakeExecer{ExpectError: errors.New("not found")}},
+		signing:   &atest.Signing{Type: "exec", Command: "fake"},
+		expectErr: "failed to sign the request by fake, not found",
+	}, {
+		name:      "invalid output of the command",
+		signer:    &execSigner{execer: fakeruntime.DefaultExecer{}},
+		signing:   &atest.Signing{Type: "exec", Command: "echo", Args: []string{"fake"}},
+		expectErr: "the output of echo is not a valid request",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := []byte(`{"name":"rick"}`)
+			request, err := http.NewRequest(http.MethodPost, "http://localhost/orders?id=1", strings.NewReader(string(body)))
+			assert.Nil(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			signed, err := tt.signer.Sign(request, body, tt.signing)
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+				tt.verify(t, signed)
+				assert.Empty(t, request.Header.Get("Authorization"), "the original request should not be changed")
+			} else if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestRunTestCaseWithSigning(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("http://localhost").Post("/orders").
+		MatchHeader("X-Signature", "^[0-9a-f]{64}$").
+		Reply(http.StatusOK).JSON(`{}`)
+
+	runner := NewSimpleTestCaseRunner()
+	_, err := runner.RunTestCase(&atest.TestCase{
+		Name: "signed",
+		Request: atest.Request{
+			API:     "http://localhost/orders",
+			Method:  http.MethodPost,
+			Body:    `{"name":"rick"}`,
+			Signing: &atest.Signing{Type: "hmac", Secret: "secret"},
+		},
+	}, nil, context.TODO())
+	assert.Nil(t, err)
+
+	_, err = runner.RunTestCase(&atest.TestCase{
+		Name: "unsupported",
+		Request: atest.Request{
+			API:     "http://localhost/orders",
+			Signing: &atest.Signing{Type: "fake"},
+		},
+	}, nil, context.TODO())
+	assert.EqualError(t, err, "failed to sign the request, not supported signer: 'fake', supported: [hmac sigv4 exec]")
+}
@@ -1,17 +1,16 @@
 package runner
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
-	"reflect"
 	"strings"
 	"time"
 
-	"github.com/andreyvit/diff"
 	"github.com/antonmedv/expr"
 	"github.com/antonmedv/expr/vm"
 	"github.com/linuxsuren/api-testing/pkg/runner/kubernetes"
@@ -81,12 +80,18 @@ type TestCaseRunner interface {
 
 // ReportRecord represents the raw data of a HTTP request
 type ReportRecord struct {
-	Method    string
-	API       string
+	Name       string
+	Method     string
+	API        string
+	StatusCode int
+	// Protocol is the protocol of the HTTP response, e.g. HTTP/2.0
+	Protocol  string
 	Body      string
 	BeginTime time.Time
 	EndTime   time.Time
 	Error     error
+	// ResponseTime is the duration of sending the request and reading the response, the assertions are not included
+	ResponseTime time.Duration
 }
 
 // Duration returns the duration between begin and end time
@@ -157,6 +162,8 @@ type simpleTestCaseRunner struct {
 	writer       io.Writer
 	log          LevelWriter
 	execer       fakeruntime.Execer
+	// embedding is the runner of another kind which embeds this one, it's returned by the With* methods
+	embedding TestCaseRunner
 }
 
 // NewSimpleTestCaseRunner creates the instance of the simple test case runner
@@ -168,15 +175,47 @@ func NewSimpleTestCaseRunner() TestCaseRunner {
 		WithExecer(fakeruntime.DefaultExecer{})
 }
 
+// embeddedBy sets the default options for the runner which embeds the simple one,
+// the With* methods return the embedding runner so that they could be chained
+func (r *simpleTestCaseRunner) embeddedBy(runner TestCaseRunner) TestCaseRunner {
+	r.embedding = runner
+	return runner.WithOutputWriter(io.Discard).
+		WithWriteLevel("info").
+		WithTestReporter(NewDiscardTestReporter()).
+		WithExecer(fakeruntime.DefaultExecer{})
+}
+
+// self returns the embedding runner, or the simple runner itself
+func (r *simpleTestCaseRunner) self() TestCaseRunner {
+	if r.embedding != nil {
+		return r.embedding
+	}
+	return r
+}
+
 // RunTestCase is the main entry point of a test case
 func (r *simpleTestCaseRunner) RunTestCase(testcase *testing.TestCase, dataContext interface{}, ctx context.Context) (output interface{}, err error) {
+	return r.runTestCaseWith(testcase, func(record *ReportRecord) (interface{}, error) {
+		return r.doHTTPRequest(testcase, dataContext, ctx, record)
+	})
+}
+
+// runTestCaseWith does the prepare and clean work around sending the request,
+// the record will be put into the reporter after that
+func (r *simpleTestCaseRunner) runTestCaseWith(testcase *testing.TestCase,
+	send func(*ReportRecord) (interface{}, error)) (output interface{}, err error) {
 	r.log.Info("start to run: '%s'\n", testcase.Name)
 	record := NewReportRecord()
 	defer func(rr *ReportRecord) {
 		rr.EndTime = time.Now()
 		rr.Error = err
-		rr.API = testcase.Request.API
-		rr.Method = testcase.Request.Method
+		rr.Name = testcase.Name
+		if rr.API == "" {
+			rr.API = testcase.Request.API
+		}
+		if rr.Method == "" {
+			rr.Method = testcase.Request.Method
+		}
 		r.testReporter.PutRecord(rr)
 	}(record)
 
@@ -185,18 +224,59 @@ func (r *simpleTestCaseRunner) RunTestCase(testcase *testing.TestCase, dataConte
 		return
 	}
 
+	if err = testcase.Expect.LoadSchema(); err != nil {
+		err = fmt.Errorf("failed to load the schema, error: %v", err)
+		return
+	}
+
 	defer func() {
 		if testcase.Clean.CleanPrepare {
 			err = r.doCleanPrepare(testcase)
 		}
 	}()
 
-	client := http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		},
+	if testcase.Prepare.Redis != nil {
+		if err = r.verifyRedis(testcase.Prepare.Redis); err != nil {
+			err = fmt.Errorf("failed to check the Redis entries before the request, error: %v", err)
+			return
+		}
+	}
+
+	if len(testcase.Prepare.Files) > 0 {
+		if err = r.runFileSteps(testcase.Prepare.Files, fileStepActionUpload); err != nil {
+			err = fmt.Errorf("failed to prepare the files, error: %v", err)
+			return
+		}
+	}
+
+	var maxResponseTime time.Duration
+	if testcase.Expect.MaxResponseTime != "" {
+		if maxResponseTime, err = time.ParseDuration(testcase.Expect.MaxResponseTime); err != nil {
+			err = fmt.Errorf("invalid maxResponseTime, error: %v", err)
+			return
+		}
 	}
 
+	begin := time.Now()
+	if output, err = send(record); err == nil {
+		// the runners which don't record the response time are measured by the whole sending
+		duration := record.ResponseTime
+		if duration == 0 {
+			duration = time.Since(begin)
+		}
+		if maxResponseTime > 0 && duration > maxResponseTime {
+			err = fmt.Errorf("case: %s, expect the response time less than %v, actual %v", testcase.Name, maxResponseTime, duration)
+			return
+		}
+		if err = r.runVerifiers(testcase, output, record); err == nil {
+			err = r.runVerifyPlugins(testcase)
+		}
+	}
+	return
+}
+
+func (r *simpleTestCaseRunner) doHTTPRequest(testcase *testing.TestCase, dataContext interface{}, ctx context.Context,
+	record *ReportRecord) (output interface{}, err error) {
 	if err = testcase.Request.Render(dataContext); err != nil {
 		return
 	}
@@ -206,8 +286,26 @@ func (r *simpleTestCaseRunner) RunTestCase(testcase *testing.TestCase, dataConte
 		return
 	}
 
+	// the body is read ahead for the signer
+	var signingBody []byte
+	if testcase.Request.Signing != nil && requestBody != nil {
+		if signingBody, err = io.ReadAll(requestBody); err != nil {
+			return
+		}
+		requestBody = bytes.NewReader(signingBody)
+	}
+
+	// all the transports share the dialer, the unix socket one replaces the default dialer
+	var dial dialContextFunc
+	api := testcase.Request.API
+	if socket, path, ok := parseUnixSocketAPI(api); ok {
+		dial = newUnixSocketDialer(socket)
+		api = "http://unix" + path
+	}
+	client := http.Client{Transport: newTransport(dial)}
+
 	var request *http.Request
-	if request, err = http.NewRequestWithContext(ctx, testcase.Request.Method, testcase.Request.API, requestBody); err != nil {
+	if request, err = http.NewRequestWithContext(ctx, testcase.Request.Method, api, requestBody); err != nil {
 		return
 	}
 
@@ -215,6 +313,20 @@ func (r *simpleTestCaseRunner) RunTestCase(testcase *testing.TestCase, dataConte
 	for key, val := range testcase.Request.Header {
 		request.Header.Add(key, val)
 	}
+	if transport := testcase.Request.Transport; transport != nil && transport.UserAgent != "" &&
+		request.Header.Get("User-Agent") == "" {
+		request.Header.Set("User-Agent", transport.UserAgent)
+	}
+	if compression := testcase.Expect.Compression; compression != nil && request.Header.Get("Accept-Encoding") == "" {
+		request.Header.Set("Accept-Encoding", acceptEncoding(compression))
+	}
+
+	if signing := testcase.Request.Signing; signing != nil {
+		if request, err = r.signRequest(request, signingBody, signing); err != nil {
+			err = fmt.Errorf("failed to sign the request, %v", err)
+			return
+		}
+	}
 
 	r.log.Info("start to send request to %s\n", testcase.Request.API)
 
@@ -223,8 +335,39 @@ func (r *simpleTestCaseRunner) RunTestCase(testcase *testing.TestCase, dataConte
 		client = *http.DefaultClient
 	}
 
+	if transport := testcase.Request.Transport; transport != nil && transport.Protocol != "" {
+		if client.Transport, err = newProtocolTransport(transport.Protocol, dial); err != nil {
+			return
+		}
+	}
+
+	if transport := testcase.Request.Transport; transport != nil && transport.SPIFFE != nil {
+		var tlsConfig *tls.Config
+		if tlsConfig, err = newSPIFFETLSConfig(ctx, transport.SPIFFE); err != nil {
+			return
+		}
+		setTLSConfig(client.Transport, tlsConfig)
+	}
+
+	// the cleartext requests are sent by the default client
+	if transport := testcase.Request.Transport; transport != nil && transport.TLS != nil && request.URL.Scheme == "https" {
+		if err = applyTLSOptions(client.Transport, transport.TLS); err != nil {
+			return
+		}
+	}
+
+	if transport := testcase.Request.Transport; transport != nil && transport.Negotiate != nil {
+		if client.Transport, err = newNegotiateTransport(client.Transport, transport.Negotiate); err != nil {
+			return
+		}
+	}
+
+	chain := &redirectChain{}
+	client.CheckRedirect = chain.checkRedirect
+
 	// send the HTTP request
 	var resp *http.Response
+	sent := time.Now()
 	if resp, err = client.Do(request); err != nil {
 		return
 	}
@@ -233,6 +376,16 @@ func (r *simpleTestCaseRunner) RunTestCase(testcase *testing.TestCase, dataConte
 	if responseBodyData, err = io.ReadAll(resp.Body); err != nil {
 		return
 	}
+	record.ResponseTime = time.Since(sent)
+	receivedBodyData := responseBodyData
+	if testcase.Expect.Compression != nil {
+		if responseBodyData, err = decodeBody(resp.Header, responseBodyData); err != nil {
+			err = fmt.Errorf("case: %s, %v", testcase.Name, err)
+			return
+		}
+	}
+	record.StatusCode = resp.StatusCode
+	record.Protocol = resp.Proto
 	record.Body = string(responseBodyData)
 	r.log.Debug("response body: %s\n", record.Body)
 
@@ -243,15 +396,82 @@ func (r *simpleTestCaseRunner) RunTestCase(testcase *testing.TestCase, dataConte
 		err = fmt.Errorf("error is: %v", err)
 		return
 	}
+	if err = expectProtocol(testcase.Name, testcase.Expect.Protocol, resp); err != nil {
+		return
+	}
+	if err = expectCertificate(testcase.Name, testcase.Expect.Certificate, resp.TLS); err != nil {
+		return
+	}
+	if err = chain.expect(testcase.Name, testcase.Expect.Redirects, resp.Request.URL); err != nil {
+		return
+	}
+
+	if err = expectHeader(testcase.Name, testcase.Expect, resp.Header); err != nil {
+		return
+	}
+	if err = expectCookies(testcase.Name, testcase.Expect.Cookies, resp.Cookies()); err != nil {
+		return
+	}
+	if err = expectCache(testcase.Name, testcase.Expect.Cache, &client, request, resp); err != nil {
+		return
+	}
+	if err = expectCompression(testcase.Name, testcase.Expect.Compression, resp.Header, receivedBodyData, responseBodyData); err != nil {
+		return
+	}
+	if err = expectSecurityHeaders(testcase.Name, testcase.Expect.SecurityHeaders, resp); err != nil {
+		return
+	}
+	if err = expectBodySize(testcase.Name, testcase.Expect, len(responseBodyData)); err != nil {
+		return
+	}
+	if err = expectBodyChecksum(testcase.Name, testcase.Expect, responseBodyData); err != nil {
+		return
+	}
+	if keys := testcase.Expect.JOSE; keys != nil {
+		if responseBodyData, err = decodeJOSE(responseBodyData, keys); err != nil {
+			err = fmt.Errorf("case: %s, %v", testcase.Name, err)
+			return
+		}
+		r.log.Debug("JOSE payload: %s\n", string(responseBodyData))
+	}
+
+	if protobuf := testcase.Expect.Protobuf; protobuf != nil {
+		if responseBodyData, err = decodeProtobuf(r.execer, protobuf, responseBodyData); err != nil {
+			err = fmt.Errorf("case: %s, %v", testcase.Name, err)
+			return
+		}
+		r.log.Debug("protobuf message: %s\n", string(responseBodyData))
+	}
+
+	if len(testcase.Expect.HTML) > 0 {
+		output, err = verifyHTMLResponse(testcase.Name, testcase.Expect, responseBodyData)
+		return
+	}
+
+	if testcase.Request.IsXML() || len(testcase.Expect.XPath) > 0 || hasXSD(testcase.Expect) {
+		if output, err = verifyXMLResponse(testcase.Name, testcase.Expect, responseBodyData); err == nil && hasXSD(testcase.Expect) {
+			err = r.verifyXSD(testcase.Name, testcase.Expect, responseBodyData)
+		}
+		return
+	}
 
-	for key, val := range testcase.Expect.Header {
-		actualVal := resp.Header.Get(key)
-		if err = expectString(testcase.Name, val, actualVal); err != nil {
+	// the body expectations of GraphQL are verified against the data
+	if testcase.Request.GraphQL != nil {
+		if responseBodyData, err = verifyGraphQLResponse(testcase.Name, testcase.Expect, responseBodyData); err != nil {
 			return
 		}
 	}
 
-	if output, err = verifyResponseBodyData(testcase.Name, testcase.Expect, responseBodyData); err != nil {
+	// the body expectations of JSON-RPC are verified against the result
+	if testcase.Request.JSONRPC != nil {
+		if responseBodyData, err = verifyJSONRPCResponse(testcase.Name, testcase.Request.JSONRPC, testcase.Expect,
+			responseBodyData); err != nil {
+			return
+		}
+	}
+
+	if output, err = verifyResponseBodyValue(testcase.Name, testcase.Expect, responseBodyData,
+		testcase.Request.JSONRPC != nil); err != nil {
 		return
 	}
 
@@ -262,7 +482,7 @@ func (r *simpleTestCaseRunner) RunTestCase(testcase *testing.TestCase, dataConte
 // WithOutputWriter sets the io.Writer
 func (r *simpleTestCaseRunner) WithOutputWriter(writer io.Writer) TestCaseRunner {
 	r.writer = writer
-	return r
+	return r.self()
 }
 
 // WithWriteLevel sets the level writer
@@ -270,19 +490,19 @@ func (r *simpleTestCaseRunner) WithWriteLevel(level string) TestCaseRunner {
 	if level != "" {
 		r.log = NewDefaultLevelWriter(level, r.writer)
 	}
-	return r
+	return r.self()
 }
 
 // WithTestReporter sets the TestReporter
 func (r *simpleTestCaseRunner) WithTestReporter(reporter TestReporter) TestCaseRunner {
 	r.testReporter = reporter
-	return r
+	return r.self()
 }
 
 // WithExecer sets the execer
 func (r *simpleTestCaseRunner) WithExecer(execer fakeruntime.Execer) TestCaseRunner {
 	r.execer = execer
-	return r
+	return r.self()
 }
 
 func (r *simpleTestCaseRunner) doPrepare(testcase *testing.TestCase) (err error) {
@@ -337,30 +557,53 @@ func jsonSchemaValidation(schema string, body []byte) (err error) {
 	return
 }
 
+// hasJSONExpectation indicates if the body should be decoded as JSON to verify the expectations
+func hasJSONExpectation(expect testing.Response) bool {
+	return len(expect.BodyFieldsExpect) > 0 || len(expect.JSONPath) > 0 || len(expect.Arrays) > 0 ||
+		len(expect.Verify) > 0 || expect.Schema != ""
+}
+
 func verifyResponseBodyData(caseName string, expect testing.Response, responseBodyData []byte) (output interface{}, err error) {
+	return verifyResponseBodyValue(caseName, expect, responseBodyData, false)
+}
+
+// verifyResponseBodyValue verifies the body which is an object or an array, the scalar value is allowed as well
+// if it's the result of a JSON-RPC or Thrift call
+func verifyResponseBodyValue(caseName string, expect testing.Response, responseBodyData []byte,
+	allowScalar bool) (output interface{}, err error) {
 	if expect.Body != "" {
 		if string(responseBodyData) != strings.TrimSpace(expect.Body) {
 			err = fmt.Errorf("case: %s, got different response body, diff: \n%s", caseName,
-				diff.LineDiff(expect.Body, string(responseBodyData)))
+				bodyDiff(expect.Body, responseBodyData))
 			return
 		}
 	}
+	if err = verifyBodyText(caseName, expect, responseBodyData); err != nil {
+		return
+	}
+	// the text responses and the downloads are verified by the text assertions and the checksums only, the output
+	// is the text. The empty body is still decoded as JSON unless it's verified by the checksums
+	if (len(bytes.TrimSpace(responseBodyData)) > 0 || hasBodyChecksum(expect)) && !json.Valid(responseBodyData) &&
+		!hasJSONExpectation(expect) {
+		output = string(responseBodyData)
+		return
+	}
 
 	var bodyMap map[string]interface{}
 	mapOutput := map[string]interface{}{}
 	if err = json.Unmarshal(responseBodyData, &mapOutput); err != nil {
 		switch b := err.(type) {
 		case *json.UnmarshalTypeError:
-			if b.Value != "array" {
+			if b.Value != "array" && !allowScalar {
 				return
 			}
 
-			var arrayOutput []interface{}
-			if err = json.Unmarshal(responseBodyData, &arrayOutput); err != nil {
+			var value interface{}
+			if err = json.Unmarshal(responseBodyData, &value); err != nil {
 				return
 			}
-			output = arrayOutput
-			mapOutput["data"] = arrayOutput
+			output = value
+			mapOutput["data"] = value
 		default:
 			return
 		}
@@ -381,13 +624,23 @@ func verifyResponseBodyData(caseName string, expect testing.Response, responseBo
 		} else if !ok {
 			err = fmt.Errorf("not found field: %s", key)
 			return
-		} else if !reflect.DeepEqual(expectVal, val) {
-			if reflect.TypeOf(expectVal).Kind() == reflect.Int {
-				if strings.Compare(fmt.Sprintf("%v", expectVal), fmt.Sprintf("%v", val)) == 0 {
-					continue
-				}
+		} else if !fieldValueEqual(expectVal, val) {
+			if fieldDiff := fieldValueDiff(key, expectVal, val); fieldDiff != nil {
+				err = fmt.Errorf("field[%s] got different value, diff: \n%s", key, fieldDiff)
+			} else {
+				err = fmt.Errorf("field[%s] expect value: %v, actual: %v", key, expectVal, val)
 			}
-			err = fmt.Errorf("field[%s] expect value: %v, actual: %v", key, expectVal, val)
+			return
+		}
+	}
+
+	if len(expect.JSONPath) > 0 {
+		if err = verifyJSONPath(caseName, expect.JSONPath, output); err != nil {
+			return
+		}
+	}
+	if len(expect.Arrays) > 0 {
+		if err = verifyArrays(caseName, expect.Arrays, output); err != nil {
 			return
 		}
 	}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"testing"
+	"time"
 
 	_ "embed"
 
@@ -108,6 +109,45 @@ func TestTestCase(t *testing.T) {
 			assert.Nil(t, err)
 			assert.Equal(t, []interface{}{"foo", "bar"}, output)
 		},
+	}, {
+		name: "verify the expressions against the arrays",
+		testCase: &atest.TestCase{
+			Request: fooRequst,
+			Expect: atest.Response{
+				Verify: []string{
+					`len(data.items) > 3 && data.items[0].name == "foo"`,
+					`all(data.items, {.id > 0})`,
+				},
+			},
+		},
+		prepare: func() {
+			gock.New(urlLocalhost).
+				Get("/foo").
+				Reply(http.StatusOK).
+				BodyString(`{"items": [{"id": 1, "name": "foo"}, {"id": 2}, {"id": 3}, {"id": 4}]}`)
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name: "the expression is false",
+		testCase: &atest.TestCase{
+			Request: fooRequst,
+			Expect: atest.Response{
+				Verify: []string{
+					`len(data.items) > 3`,
+				},
+			},
+		},
+		prepare: func() {
+			gock.New(urlLocalhost).
+				Get("/foo").
+				Reply(http.StatusOK).
+				BodyString(`{"items": []}`)
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.EqualError(t, err, "failed to verify: len(data.items) > 3")
+		},
 	}, {
 		name: "normal, response from file",
 		testCase: &atest.TestCase{
@@ -334,6 +374,157 @@ func TestTestCase(t *testing.T) {
 					Reply(http.StatusOK).BodyString(`{}`)
 			},
 			verify: noError,
+		}, {
+			name: "graphql, verify the data",
+			testCase: &atest.TestCase{
+				Request: atest.Request{
+					API: urlFoo,
+					GraphQL: &atest.GraphQLRequest{
+						Query:     "query user($name: String!) { user(name: $name) { name } }",
+						Variables: map[string]interface{}{"name": "linuxsuren"},
+					},
+				},
+				Expect: atest.Response{
+					BodyFieldsExpect: map[string]interface{}{
+						"user/name": "linuxsuren",
+					},
+				},
+			},
+			prepare: func() {
+				gock.New(urlLocalhost).
+					Post("/foo").MatchType("json").
+					JSON(map[string]interface{}{
+						"query":     "query user($name: String!) { user(name: $name) { name } }",
+						"variables": map[string]interface{}{"name": "linuxsuren"},
+					}).
+					Reply(http.StatusOK).BodyString(`{"data":{"user":{"name":"linuxsuren"}}}`)
+			},
+			verify: func(t *testing.T, output interface{}, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, map[string]interface{}{"user": map[string]interface{}{"name": "linuxsuren"}}, output)
+			},
+		}, {
+			name: "soap, verify with xpath",
+			testCase: &atest.TestCase{
+				Request: atest.Request{
+					API:      urlFoo,
+					BodyType: atest.BodyTypeSOAP,
+					Body:     "<m:GetPrice><m:Item>apple</m:Item></m:GetPrice>",
+				},
+				Expect: atest.Response{
+					XPath: map[string]string{
+						"//m:Price": "1.90",
+					},
+				},
+			},
+			prepare: func() {
+				gock.New(urlLocalhost).
+					Post("/foo").MatchHeader("Content-Type", "text/xml; charset=utf-8").
+					BodyString("<soap:Envelope").
+					Reply(http.StatusOK).
+					BodyString(`<soap:Envelope><soap:Body><m:Price>1.90</m:Price></soap:Body></soap:Envelope>`)
+			},
+			verify: func(t *testing.T, output interface{}, err error) {
+				assert.Nil(t, err)
+				assert.Contains(t, output, "<m:Price>1.90</m:Price>")
+			},
+		}, {
+			name: "graphql, unexpected errors",
+			testCase: &atest.TestCase{
+				Request: atest.Request{
+					API:     urlFoo,
+					GraphQL: &atest.GraphQLRequest{Query: "{ users { name } }"},
+				},
+			},
+			prepare: func() {
+				gock.New(urlLocalhost).
+					Post("/foo").
+					Reply(http.StatusOK).BodyString(`{"errors":[{"message":"unauthorized"}]}`)
+			},
+		}, {
+			name: "jsonrpc, verify the scalar result",
+			testCase: &atest.TestCase{
+				Request: atest.Request{
+					API: urlFoo,
+					JSONRPC: &atest.JSONRPCRequest{
+						Method: "subtract",
+						Params: []interface{}{42, 23},
+					},
+				},
+				Expect: atest.Response{
+					Verify: []string{"data == 19"},
+				},
+			},
+			prepare: func() {
+				gock.New(urlLocalhost).
+					Post("/foo").MatchType("json").
+					JSON(map[string]interface{}{
+						"jsonrpc": "2.0",
+						"method":  "subtract",
+						"params":  []interface{}{42, 23},
+						"id":      1,
+					}).
+					Reply(http.StatusOK).BodyString(`{"jsonrpc":"2.0","result":19,"id":1}`)
+			},
+			verify: func(t *testing.T, output interface{}, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, float64(19), output)
+			},
+		}, {
+			name: "in the max response time",
+			testCase: &atest.TestCase{
+				Request: fooRequst,
+				Expect:  atest.Response{MaxResponseTime: "1m"},
+			},
+			prepare: defaultPrepare,
+			verify: func(t *testing.T, output interface{}, err error) {
+				assert.Nil(t, err)
+			},
+		}, {
+			name: "exceed the max response time",
+			testCase: &atest.TestCase{
+				Name:    "slow",
+				Request: fooRequst,
+				Expect:  atest.Response{MaxResponseTime: "10ms"},
+			},
+			prepare: func() {
+				gock.New(urlLocalhost).
+					Get("/foo").Reply(http.StatusOK).Delay(50 * time.Millisecond).BodyString(`{"items":[]}`)
+			},
+			verify: func(t *testing.T, output interface{}, err error) {
+				if assert.NotNil(t, err) {
+					assert.Contains(t, err.Error(), "case: slow, expect the response time less than 10ms, actual")
+				}
+			},
+		}, {
+			name: "the second request is not in the response time",
+			testCase: &atest.TestCase{
+				Request: fooRequst,
+				Expect: atest.Response{
+					MaxResponseTime: "30ms",
+					Cache:           &atest.CacheExpect{Second: "revalidated"},
+				},
+			},
+			prepare: func() {
+				gock.New(urlLocalhost).
+					Get("/foo").Reply(http.StatusOK).SetHeader("ETag", `"v1"`).BodyString(`{"items":[]}`)
+				gock.New(urlLocalhost).
+					Get("/foo").MatchHeader("If-None-Match", `"v1"`).Reply(http.StatusNotModified).Delay(50 * time.Millisecond)
+			},
+			verify: func(t *testing.T, output interface{}, err error) {
+				assert.Nil(t, err)
+			},
+		}, {
+			name: "invalid max response time",
+			testCase: &atest.TestCase{
+				Request: fooRequst,
+				Expect:  atest.Response{MaxResponseTime: "fake"},
+			},
+			verify: func(t *testing.T, output interface{}, err error) {
+				if assert.NotNil(t, err) {
+					assert.Contains(t, err.Error(), "invalid maxResponseTime")
+				}
+			},
 		}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -436,3 +627,21 @@ var genericBody string
 
 const urlFoo = "http://localhost/foo"
 const urlLocalhost = "http://localhost"
+
+func TestEmbeddedRunnerOptions(t *testing.T) {
+	runners := []TestCaseRunner{
+		NewAMQPTestCaseRunner(), NewExecTestCaseRunner(), NewFTPTestCaseRunner(), NewGRPCTestCaseRunner(),
+		NewMQTTTestCaseRunner(), NewNATSTestCaseRunner(), NewOIDCTestCaseRunner(), NewSSETestCaseRunner(),
+		NewTCPTestCaseRunner(), NewThriftTestCaseRunner(), NewUDPTestCaseRunner(), NewWebhookTestCaseRunner(),
+		NewWebSocketTestCaseRunner(),
+	}
+	for _, runner := range runners {
+		// the chained options return the embedding runner instead of the simple one
+		chained := runner.WithOutputWriter(new(bytes.Buffer)).
+			WithWriteLevel("debug").
+			WithTestReporter(NewMemoryTestReporter()).
+			WithExecer(fakeruntime.FakeExecer{})
+		assert.Same(t, runner, chained)
+	}
+	assert.IsType(t, &simpleTestCaseRunner{}, NewSimpleTestCaseRunner().WithWriteLevel("debug"))
+}
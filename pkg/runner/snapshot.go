@@ -0,0 +1,144 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/PaesslerAG/jsonpath"
+	"github.com/antonmedv/expr"
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+// SnapshotDiff is the differences of the items between two snapshots, the changed ones are the new items
+type SnapshotDiff struct {
+	Added   []interface{}
+	Removed []interface{}
+	Changed []interface{}
+}
+
+// DiffSnapshots compares the items of the responses before and after the test case. The items are identified
+// by the key field, or the whole items if the key is empty, then there are no changed items
+func DiffSnapshots(snapshot *testing.Snapshot, before, after interface{}) (diff *SnapshotDiff, err error) {
+	var beforeItems, afterItems []interface{}
+	if beforeItems, err = snapshotItems(snapshot.Items, before); err != nil {
+		return
+	}
+	if afterItems, err = snapshotItems(snapshot.Items, after); err != nil {
+		return
+	}
+
+	var beforeKeys, afterKeys []string
+	if beforeKeys, err = snapshotKeys(snapshot.Key, beforeItems); err != nil {
+		return
+	}
+	if afterKeys, err = snapshotKeys(snapshot.Key, afterItems); err != nil {
+		return
+	}
+
+	// the count makes the duplicated items work
+	remaining := map[string][]interface{}{}
+	for i, key := range beforeKeys {
+		remaining[key] = append(remaining[key], beforeItems[i])
+	}
+
+	diff = &SnapshotDiff{}
+	for i, key := range afterKeys {
+		items := remaining[key]
+		if len(items) == 0 {
+			diff.Added = append(diff.Added, afterItems[i])
+			continue
+		}
+		if !reflect.DeepEqual(items[0], afterItems[i]) {
+			diff.Changed = append(diff.Changed, afterItems[i])
+		}
+		remaining[key] = items[1:]
+	}
+	for _, key := range beforeKeys {
+		if items := remaining[key]; len(items) > 0 {
+			diff.Removed = append(diff.Removed, items[0])
+			remaining[key] = items[1:]
+		}
+	}
+	return
+}
+
+func snapshotItems(path string, body interface{}) (items []interface{}, err error) {
+	if path != "" {
+		if body, err = jsonpath.Get(path, body); err != nil {
+			err = fmt.Errorf("failed to get the JSONPath %s, %v", path, err)
+			return
+		}
+	}
+
+	var ok bool
+	if items, ok = body.([]interface{}); !ok {
+		err = fmt.Errorf("the items of the snapshot should be an array, but it's %v", body)
+	}
+	return
+}
+
+func snapshotKeys(key string, items []interface{}) (keys []string, err error) {
+	keys = make([]string, len(items))
+	for i, item := range items {
+		if key == "" {
+			var data []byte
+			if data, err = json.Marshal(item); err != nil {
+				return
+			}
+			keys[i] = string(data)
+			continue
+		}
+
+		fields, ok := item.(map[string]interface{})
+		if !ok || fields[key] == nil {
+			err = fmt.Errorf("the item does not have the key %s, %v", key, item)
+			return
+		}
+		keys[i] = fmt.Sprint(fields[key])
+	}
+	return
+}
+
+// VerifySnapshot verifies the number of the added, removed and changed items, then evaluates the expressions
+func VerifySnapshot(caseName string, expect *testing.SnapshotExpect, before, after interface{}, diff *SnapshotDiff) (err error) {
+	for _, item := range []struct {
+		name   string
+		expect *int
+		actual []interface{}
+	}{{"added", expect.Added, diff.Added}, {"removed", expect.Removed, diff.Removed}, {"changed", expect.Changed, diff.Changed}} {
+		if item.expect != nil && *item.expect != len(item.actual) {
+			err = fmt.Errorf("case: %s, expect %d %s items in the snapshot, actual %d, %v",
+				caseName, *item.expect, item.name, len(item.actual), item.actual)
+			return
+		}
+	}
+
+	env := map[string]interface{}{
+		"before":  before,
+		"after":   after,
+		"added":   emptyThenArray(diff.Added),
+		"removed": emptyThenArray(diff.Removed),
+		"changed": emptyThenArray(diff.Changed),
+	}
+	for _, verify := range expect.Verify {
+		var result interface{}
+		if result, err = expr.Eval(verify, env); err != nil {
+			err = fmt.Errorf("case: %s, failed to evaluate %s, %v", caseName, verify, err)
+			return
+		}
+		if ok, _ := result.(bool); !ok {
+			err = fmt.Errorf("case: %s, failed to verify the snapshot: %s", caseName, verify)
+			return
+		}
+	}
+	return
+}
+
+// emptyThenArray makes len() work in the expressions
+func emptyThenArray(items []interface{}) []interface{} {
+	if items == nil {
+		items = []interface{}{}
+	}
+	return items
+}
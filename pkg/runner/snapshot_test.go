@@ -0,0 +1,115 @@
+package runner
+
+import (
+	"encoding/json"
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	tests := []struct {
+		name      string
+		snapshot  atest.Snapshot
+		before    string
+		after     string
+		expect    *SnapshotDiff
+		expectErr string
+	}{{
+		name:     "by the key",
+		snapshot: atest.Snapshot{Items: "$.items", Key: "id"},
+		before:   `{"items": [{"id": 1, "name": "a"}, {"id": 2, "name": "b"}]}`,
+		after:    `{"items": [{"id": 2, "name": "c"}, {"id": 3, "name": "d"}]}`,
+		expect: &SnapshotDiff{
+			Added:   []interface{}{map[string]interface{}{"id": float64(3), "name": "d"}},
+			Removed: []interface{}{map[string]interface{}{"id": float64(1), "name": "a"}},
+			Changed: []interface{}{map[string]interface{}{"id": float64(2), "name": "c"}},
+		},
+	}, {
+		name:   "by the whole items",
+		before: `["a", "b", "b"]`,
+		after:  `["b", "c"]`,
+		expect: &SnapshotDiff{
+			Added:   []interface{}{"c"},
+			Removed: []interface{}{"a", "b"},
+		},
+	}, {
+		name:   "no differences",
+		before: `[1, 2]`,
+		after:  `[2, 1]`,
+		expect: &SnapshotDiff{},
+	}, {
+		name:      "not an array",
+		before:    `{"items": []}`,
+		after:     `[]`,
+		expectErr: "the items of the snapshot should be an array, but it's map[items:[]]",
+	}, {
+		name:      "invalid JSONPath",
+		snapshot:  atest.Snapshot{Items: "$.items"},
+		before:    `[]`,
+		after:     `[]`,
+		expectErr: "failed to get the JSONPath $.items",
+	}, {
+		name:      "no key",
+		snapshot:  atest.Snapshot{Key: "id"},
+		before:    `[{"name": "a"}]`,
+		after:     `[]`,
+		expectErr: "the item does not have the key id, map[name:a]",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var before, after interface{}
+			assert.Nil(t, json.Unmarshal([]byte(tt.before), &before))
+			assert.Nil(t, json.Unmarshal([]byte(tt.after), &after))
+
+			diff, err := DiffSnapshots(&tt.snapshot, before, after)
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expect, diff)
+			} else if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestVerifySnapshot(t *testing.T) {
+	one, zero := 1, 0
+	before := []interface{}{"a"}
+	after := []interface{}{"a", "b"}
+	diff := &SnapshotDiff{Added: []interface{}{"b"}}
+
+	tests := []struct {
+		name      string
+		expect    atest.SnapshotExpect
+		expectErr string
+	}{{
+		name: "normal",
+		expect: atest.SnapshotExpect{Added: &one, Removed: &zero, Changed: &zero, Verify: []string{
+			`added[0] == "b"`, `len(after) - len(before) == 1`, `len(removed) == 0`,
+		}},
+	}, {
+		name:      "unexpected number",
+		expect:    atest.SnapshotExpect{Removed: &one},
+		expectErr: "case: fake, expect 1 removed items in the snapshot, actual 0, []",
+	}, {
+		name:      "false expression",
+		expect:    atest.SnapshotExpect{Verify: []string{`len(changed) > 0`}},
+		expectErr: "case: fake, failed to verify the snapshot: len(changed) > 0",
+	}, {
+		name:      "invalid expression",
+		expect:    atest.SnapshotExpect{Verify: []string{`fake(`}},
+		expectErr: "case: fake, failed to evaluate fake(",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifySnapshot("fake", &tt.expect, before, after, diff)
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expectErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	// spiffeEndpointSocketEnv is the environment variable of the Workload API address
+	spiffeEndpointSocketEnv = "SPIFFE_ENDPOINT_SOCKET"
+	// spiffeFetchX509SVID is the method of the Workload API which streams the X.509 SVIDs
+	spiffeFetchX509SVID = "/SpiffeWorkloadAPI/FetchX509SVID"
+	// defaultSPIFFETimeout is the timeout of fetching the SVID if it's not set
+	defaultSPIFFETimeout = 10 * time.Second
+)
+
+// x509SVID is the first X.509 SVID of the workload, and the trust bundle of its trust domain
+type x509SVID struct {
+	id           string
+	certificates [][]byte
+	key          crypto.PrivateKey
+	bundle       []*x509.Certificate
+}
+
+// newSPIFFETLSConfig fetches the SVID from the Workload API, then uses it as the client certificate.
+// The server certificate is verified by the trust bundle only if the server ID is set
+func newSPIFFETLSConfig(ctx context.Context, spiffe *testing.SPIFFE) (config *tls.Config, err error) {
+	timeout := defaultSPIFFETimeout
+	if spiffe.Timeout != "" {
+		if timeout, err = time.ParseDuration(spiffe.Timeout); err != nil {
+			return
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	socket := spiffe.Socket
+	if socket == "" {
+		socket = os.Getenv(spiffeEndpointSocketEnv)
+	}
+	if socket == "" {
+		err = fmt.Errorf("the socket of the Workload API is required, or set it by $%s", spiffeEndpointSocketEnv)
+		return
+	}
+
+	var svid *x509SVID
+	if svid, err = fetchX509SVID(ctx, socket); err != nil {
+		err = fmt.Errorf("failed to fetch the X.509 SVID from %s, %v", socket, err)
+		return
+	}
+
+	config = &tls.Config{
+		Certificates: []tls.Certificate{{
+			Certificate: svid.certificates,
+			PrivateKey:  svid.key,
+		}},
+		// the SPIFFE IDs are in the URI SANs instead of the DNS names
+		InsecureSkipVerify: true,
+	}
+	if spiffe.ServerID != "" {
+		config.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifySPIFFEPeer(rawCerts, svid.bundle, spiffe.ServerID)
+		}
+	}
+	return
+}
+
+// verifySPIFFEPeer verifies the certificate chain by the trust bundle, then compares the SPIFFE ID
+func verifySPIFFEPeer(rawCerts [][]byte, bundle []*x509.Certificate, expectID string) (err error) {
+	if len(rawCerts) == 0 {
+		return fmt.Errorf("no certificate of the server")
+	}
+
+	var certs []*x509.Certificate
+	if certs, err = x509.ParseCertificates(bytes.Join(rawCerts, nil)); err != nil {
+		return
+	}
+
+	roots, intermediates := x509.NewCertPool(), x509.NewCertPool()
+	for _, cert := range bundle {
+		roots.AddCert(cert)
+	}
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err = certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return
+	}
+
+	var ids []string
+	for _, uri := range certs[0].URIs {
+		if ids = append(ids, uri.String()); uri.String() == expectID {
+			return
+		}
+	}
+	return fmt.Errorf("expect the server ID %s, actual %v", expectID, ids)
+}
+
+// fetchX509SVID receives the first response of the Workload API, the messages are encoded by hand
+// since they're simple, and it avoids generating the code of the proto file
+func fetchX509SVID(ctx context.Context, socket string) (svid *x509SVID, err error) {
+	target := socket
+	if strings.HasPrefix(target, "/") {
+		target = "unix://" + target
+	}
+	target = strings.TrimPrefix(target, "tcp://")
+
+	var conn *grpc.ClientConn
+	if conn, err = grpc.DialContext(ctx, target, grpc.WithTransportCredentials(insecure.NewCredentials())); err != nil {
+		return
+	}
+	defer conn.Close()
+
+	// the header is required by the Workload API to avoid the SSRF attacks
+	ctx = metadata.AppendToOutgoingContext(ctx, "workload.spiffe.io", "true")
+	var stream grpc.ClientStream
+	if stream, err = conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, spiffeFetchX509SVID,
+		grpc.ForceCodec(rawCodec{})); err != nil {
+		return
+	}
+
+	request := []byte{}
+	if err = stream.SendMsg(&request); err != nil {
+		return
+	}
+	if err = stream.CloseSend(); err != nil {
+		return
+	}
+
+	var response []byte
+	if err = stream.RecvMsg(&response); err == nil {
+		svid, err = parseX509SVIDResponse(response)
+	}
+	return
+}
+
+// parseX509SVIDResponse parses the first SVID of the X509SVIDResponse
+func parseX509SVIDResponse(data []byte) (svid *x509SVID, err error) {
+	var svidData []byte
+	if err = consumeBytesFields(data, func(num protowire.Number, value []byte) {
+		if num == 1 && svidData == nil {
+			svidData = value
+		}
+	}); err != nil {
+		return
+	}
+	if svidData == nil {
+		err = fmt.Errorf("no SVID in the response")
+		return
+	}
+
+	svid = &x509SVID{}
+	var certData, keyData, bundleData []byte
+	if err = consumeBytesFields(svidData, func(num protowire.Number, value []byte) {
+		switch num {
+		case 1:
+			svid.id = string(value)
+		case 2:
+			certData = value
+		case 3:
+			keyData = value
+		case 4:
+			bundleData = value
+		}
+	}); err != nil {
+		return
+	}
+
+	var certs []*x509.Certificate
+	if certs, err = x509.ParseCertificates(certData); err != nil {
+		err = fmt.Errorf("invalid certificates of %s, %v", svid.id, err)
+		return
+	}
+	for _, cert := range certs {
+		svid.certificates = append(svid.certificates, cert.Raw)
+	}
+	if svid.key, err = x509.ParsePKCS8PrivateKey(keyData); err != nil {
+		err = fmt.Errorf("invalid private key of %s, %v", svid.id, err)
+		return
+	}
+	svid.bundle, err = x509.ParseCertificates(bundleData)
+	return
+}
+
+// consumeBytesFields calls the handler with the length-delimited fields, the other fields are skipped
+func consumeBytesFields(data []byte, handle func(protowire.Number, []byte)) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		if typ == protowire.BytesType {
+			var value []byte
+			if value, n = protowire.ConsumeBytes(data); n >= 0 {
+				handle(num, value)
+			}
+		} else {
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// rawCodec passes the encoded messages through
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	return *v.(*[]byte), nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	*v.(*[]byte) = append([]byte(nil), data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
@@ -0,0 +1,176 @@
+package runner
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestSPIFFE(t *testing.T) {
+	ca, caKey := newSPIFFECert(t, "", nil, nil)
+	client, clientKey := newSPIFFECert(t, "spiffe://example.org/client", ca, caKey)
+	server, serverKey := newSPIFFECert(t, "spiffe://example.org/server", ca, caKey)
+
+	socket := startFakeWorkloadAPI(t, client, clientKey, ca)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	api := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].URIs[0].String()))
+	}))
+	api.TLS = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{server.Raw}, PrivateKey: serverKey}},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    roots,
+	}
+	api.StartTLS()
+	defer api.Close()
+
+	tests := []struct {
+		name      string
+		spiffe    *atest.SPIFFE
+		env       string
+		expectErr string
+	}{{
+		name:   "normal",
+		spiffe: &atest.SPIFFE{Socket: "unix://" + socket, ServerID: "spiffe://example.org/server"},
+	}, {
+		name:   "socket from the environment",
+		spiffe: &atest.SPIFFE{},
+		env:    socket,
+	}, {
+		name:      "unexpected server ID",
+		spiffe:    &atest.SPIFFE{Socket: socket, ServerID: "spiffe://example.org/fake"},
+		expectErr: "expect the server ID spiffe://example.org/fake",
+	}, {
+		name:      "no socket",
+		spiffe:    &atest.SPIFFE{},
+		expectErr: "the socket of the Workload API is required",
+	}, {
+		name:      "invalid timeout",
+		spiffe:    &atest.SPIFFE{Socket: socket, Timeout: "fake"},
+		expectErr: "invalid duration",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(spiffeEndpointSocketEnv, tt.env)
+
+			config, err := newSPIFFETLSConfig(context.Background(), tt.spiffe)
+			if err == nil {
+				httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+				var resp *http.Response
+				if resp, err = httpClient.Get(api.URL); err == nil {
+					assert.Equal(t, http.StatusOK, resp.StatusCode)
+					_ = resp.Body.Close()
+				}
+			}
+
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestParseX509SVIDResponse(t *testing.T) {
+	_, err := parseX509SVIDResponse(nil)
+	assert.EqualError(t, err, "no SVID in the response")
+
+	_, err = parseX509SVIDResponse(protowire.AppendBytes(protowire.AppendTag(nil, 1, protowire.BytesType), []byte("fake")))
+	assert.NotNil(t, err)
+}
+
+// startFakeWorkloadAPI serves the FetchX509SVID method on a unix socket
+func startFakeWorkloadAPI(t *testing.T, cert *x509.Certificate, key *ecdsa.PrivateKey, bundle *x509.Certificate) string {
+	keyData, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.Nil(t, err)
+
+	var svid []byte
+	svid = appendBytesField(svid, 1, []byte(cert.URIs[0].String()))
+	svid = appendBytesField(svid, 2, cert.Raw)
+	svid = appendBytesField(svid, 3, keyData)
+	svid = appendBytesField(svid, 4, bundle.Raw)
+	response := appendBytesField(nil, 1, svid)
+
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	listener, err := net.Listen("unix", socket)
+	assert.Nil(t, err)
+
+	server := grpc.NewServer(grpc.ForceServerCodec(rawCodec{}),
+		grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+			if method, _ := grpc.MethodFromServerStream(stream); method != spiffeFetchX509SVID {
+				return status.Errorf(codes.Unimplemented, "unknown method %s", method)
+			}
+			if md, _ := metadata.FromIncomingContext(stream.Context()); len(md.Get("workload.spiffe.io")) == 0 {
+				return status.Error(codes.InvalidArgument, "security header missing from request")
+			}
+
+			var request []byte
+			if err := stream.RecvMsg(&request); err != nil {
+				return err
+			}
+			return stream.SendMsg(&response)
+		}))
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+	return socket
+}
+
+func appendBytesField(data []byte, num protowire.Number, value []byte) []byte {
+	return protowire.AppendBytes(protowire.AppendTag(data, num, protowire.BytesType), value)
+}
+
+// newSPIFFECert creates a CA if the parent is nil, or a leaf certificate with the SPIFFE ID
+func newSPIFFECert(t *testing.T, id string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{Organization: []string{"SPIFFE"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	} else {
+		uri, err := url.Parse(id)
+		assert.Nil(t, err)
+		template.URIs = []*url.URL{uri}
+		template.KeyUsage = x509.KeyUsageDigitalSignature
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	}
+
+	data, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	assert.Nil(t, err)
+	cert, err := x509.ParseCertificate(data)
+	assert.Nil(t, err)
+	return cert, key
+}
@@ -0,0 +1,114 @@
+package runner
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	// the supported drivers of the SQL verification
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+// defaultSQLTimeout is the timeout of the query if it's not set
+const defaultSQLTimeout = 10 * time.Second
+
+// verifySQL runs the query, then compares the count and the columns of the rows
+func (r *simpleTestCaseRunner) verifySQL(verification *testing.SQLVerification) (err error) {
+	if verification.Driver == "" || verification.DSN == "" || verification.Query == "" {
+		err = fmt.Errorf("driver, dsn and query are required")
+		return
+	}
+
+	timeout := defaultSQLTimeout
+	if verification.Timeout != "" {
+		if timeout, err = time.ParseDuration(verification.Timeout); err != nil {
+			return
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var db *sql.DB
+	if db, err = sql.Open(verification.Driver, verification.DSN); err != nil {
+		return
+	}
+	defer db.Close()
+
+	r.log.Info("start to query %s\n", verification.Query)
+	var rows []map[string]interface{}
+	if rows, err = querySQLRows(ctx, db, verification.Query, verification.Args...); err != nil {
+		return
+	}
+	r.log.Debug("the rows of the query: %v\n", rows)
+
+	if verification.Rows != nil {
+		if len(rows) != *verification.Rows {
+			err = fmt.Errorf("expect %d rows, actual %d", *verification.Rows, len(rows))
+			return
+		}
+	} else if len(rows) == 0 {
+		err = fmt.Errorf("expect at least one row, actual 0")
+		return
+	}
+
+	if len(verification.Values) > len(rows) {
+		err = fmt.Errorf("expect the values of %d rows, actual %d", len(verification.Values), len(rows))
+		return
+	}
+	for i, values := range verification.Values {
+		for column, expect := range values {
+			actual, ok := rows[i][column]
+			if !ok {
+				err = fmt.Errorf("not found column %s in row %d", column, i)
+				return
+			}
+			// the values are compared as strings, because the types of the columns vary with the drivers
+			if fmt.Sprint(expect) != fmt.Sprint(actual) {
+				err = fmt.Errorf("row %d, column %s expect value: %v, actual: %v", i, column, expect, actual)
+				return
+			}
+		}
+	}
+	return
+}
+
+// querySQLRows returns the rows as maps of the column names, the bytes are converted to strings
+func querySQLRows(ctx context.Context, db *sql.DB, query string, args ...interface{}) (result []map[string]interface{}, err error) {
+	var rows *sql.Rows
+	if rows, err = db.QueryContext(ctx, query, args...); err != nil {
+		return
+	}
+	defer rows.Close()
+
+	var columns []string
+	if columns, err = rows.Columns(); err != nil {
+		return
+	}
+
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err = rows.Scan(pointers...); err != nil {
+			return
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			if data, ok := values[i].([]byte); ok {
+				row[column] = string(data)
+			} else {
+				row[column] = values[i]
+			}
+		}
+		result = append(result, row)
+	}
+	err = rows.Err()
+	return
+}
@@ -0,0 +1,173 @@
+package runner
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	sql.Register("fakesql", fakeSQLDriver{})
+}
+
+func TestVerifySQL(t *testing.T) {
+	zero, two := 0, 2
+
+	tests := []struct {
+		name         string
+		verification *atest.SQLVerification
+		expectErr    string
+	}{{
+		name: "expected rows",
+		verification: &atest.SQLVerification{
+			Query: "select * from users",
+			Rows:  &two,
+			Values: []map[string]interface{}{
+				{"id": 1, "name": "rick"},
+				{"id": 2, "email": nil},
+			},
+		},
+	}, {
+		name: "at least one row",
+		verification: &atest.SQLVerification{
+			Query: "select * from users where id = ?",
+			Args:  []interface{}{1},
+		},
+	}, {
+		name:         "no rows",
+		verification: &atest.SQLVerification{Query: "select * from users where id = 3", Rows: &zero},
+	}, {
+		name:         "at least one row is expected",
+		verification: &atest.SQLVerification{Query: "select * from users where id = 3"},
+		expectErr:    "expect at least one row, actual 0",
+	}, {
+		name:         "unexpected count of the rows",
+		verification: &atest.SQLVerification{Query: "select * from users", Rows: &zero},
+		expectErr:    "expect 0 rows, actual 2",
+	}, {
+		name: "unexpected value",
+		verification: &atest.SQLVerification{
+			Query:  "select * from users",
+			Values: []map[string]interface{}{{"name": "morty"}},
+		},
+		expectErr: "row 0, column name expect value: morty, actual: rick",
+	}, {
+		name: "not found column",
+		verification: &atest.SQLVerification{
+			Query:  "select * from users",
+			Values: []map[string]interface{}{{"age": 1}},
+		},
+		expectErr: "not found column age in row 0",
+	}, {
+		name: "more values than the rows",
+		verification: &atest.SQLVerification{
+			Query:  "select * from users where id = ?",
+			Args:   []interface{}{1},
+			Values: []map[string]interface{}{{"id": 1}, {"id": 2}},
+		},
+		expectErr: "expect the values of 2 rows, actual 1",
+	}, {
+		name:         "invalid query",
+		verification: &atest.SQLVerification{Query: "delete from users"},
+		expectErr:    "unsupported query",
+	}, {
+		name:         "unknown driver",
+		verification: &atest.SQLVerification{Driver: "fake", DSN: "fake", Query: "select 1"},
+		expectErr:    "unknown driver",
+	}, {
+		name:         "query is required",
+		verification: &atest.SQLVerification{},
+		expectErr:    "driver, dsn and query are required",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.verification.Query != "" && tt.verification.Driver == "" {
+				tt.verification.Driver, tt.verification.DSN = "fakesql", "users"
+			}
+			r := NewSimpleTestCaseRunner().(*simpleTestCaseRunner)
+			err := r.verifySQL(tt.verification)
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expectErr)
+			}
+		})
+	}
+}
+
+// fakeSQLDriver only supports the queries of the users table which has two rows
+type fakeSQLDriver struct{}
+
+func (d fakeSQLDriver) Open(name string) (driver.Conn, error) {
+	return fakeSQLConn{}, nil
+}
+
+type fakeSQLConn struct{}
+
+func (c fakeSQLConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeSQLStmt(query), nil
+}
+
+func (c fakeSQLConn) Close() error {
+	return nil
+}
+
+func (c fakeSQLConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+type fakeSQLStmt string
+
+func (s fakeSQLStmt) Close() error {
+	return nil
+}
+
+func (s fakeSQLStmt) NumInput() int {
+	return -1
+}
+
+func (s fakeSQLStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+func (s fakeSQLStmt) Query(args []driver.Value) (driver.Rows, error) {
+	users := [][]driver.Value{
+		{int64(1), []byte("rick"), []byte("rick@example.com")},
+		{int64(2), []byte("morty"), nil},
+	}
+	switch string(s) {
+	case "select * from users":
+		return &fakeSQLRows{rows: users}, nil
+	case "select * from users where id = ?":
+		return &fakeSQLRows{rows: users[args[0].(int64)-1 : args[0].(int64)]}, nil
+	case "select * from users where id = 3":
+		return &fakeSQLRows{}, nil
+	}
+	return nil, fmt.Errorf("unsupported query: %s", s)
+}
+
+type fakeSQLRows struct {
+	rows [][]driver.Value
+}
+
+func (r *fakeSQLRows) Columns() []string {
+	return []string{"id", "name", "email"}
+}
+
+func (r *fakeSQLRows) Close() error {
+	return nil
+}
+
+func (r *fakeSQLRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
@@ -0,0 +1,167 @@
+package runner
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+// defaultSSEDuration is the duration of keeping the SSE connection if it's not set
+const defaultSSEDuration = 10 * time.Second
+
+type sseTestCaseRunner struct {
+	*simpleTestCaseRunner
+}
+
+// NewSSETestCaseRunner creates the instance of the Server-Sent Events test case runner
+func NewSSETestCaseRunner() TestCaseRunner {
+	runner := &sseTestCaseRunner{simpleTestCaseRunner: &simpleTestCaseRunner{}}
+	return runner.embeddedBy(runner)
+}
+
+// RunTestCase keeps the SSE connection for a while, then verifies the received events
+func (r *sseTestCaseRunner) RunTestCase(testcase *testing.TestCase, dataContext interface{}, ctx context.Context) (output interface{}, err error) {
+	return r.runTestCaseWith(testcase, func(record *ReportRecord) (interface{}, error) {
+		return r.doSSERequest(testcase, dataContext, ctx, record)
+	})
+}
+
+func (r *sseTestCaseRunner) doSSERequest(testcase *testing.TestCase, dataContext interface{}, ctx context.Context,
+	record *ReportRecord) (output interface{}, err error) {
+	if err = testcase.Request.Render(dataContext); err != nil {
+		return
+	}
+
+	sseRequest := testcase.Request.SSE
+	duration := defaultSSEDuration
+	if sseRequest.Duration != "" {
+		if duration, err = time.ParseDuration(sseRequest.Duration); err != nil {
+			return
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	var requestBody io.Reader
+	if requestBody, err = testcase.Request.GetBody(); err != nil {
+		return
+	}
+
+	var request *http.Request
+	if request, err = http.NewRequestWithContext(ctx, testcase.Request.Method, testcase.Request.API, requestBody); err != nil {
+		return
+	}
+	for key, val := range testcase.Request.Header {
+		request.Header.Add(key, val)
+	}
+	if request.Header.Get("Accept") == "" {
+		request.Header.Set("Accept", "text/event-stream")
+	}
+	request.Header.Set("Cache-Control", "no-cache")
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	r.log.Info("start to listen the events of %s\n", testcase.Request.API)
+	var resp *http.Response
+	if resp, err = client.Do(request); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	record.StatusCode = resp.StatusCode
+
+	if err = testcase.Expect.Render(nil); err != nil {
+		return
+	}
+	if err = expectInt(testcase.Name, testcase.Expect.StatusCode, resp.StatusCode); err != nil {
+		return
+	}
+	if err = expectHeader(testcase.Name, testcase.Expect, resp.Header); err != nil {
+		return
+	}
+
+	events, readErr := readServerSentEvents(resp.Body, sseRequest.Count)
+	if readErr != nil && ctx.Err() == nil {
+		err = readErr
+		return
+	}
+	if len(events) < sseRequest.Count {
+		err = fmt.Errorf("case: %s, expect %d events in %s, received %d", testcase.Name, sseRequest.Count, duration, len(events))
+		return
+	}
+
+	var data []byte
+	if data, err = json.Marshal(events); err != nil {
+		return
+	}
+	record.Body = string(data)
+	r.log.Debug("received events: %s\n", record.Body)
+
+	if output, err = verifyResponseBodyData(testcase.Name, testcase.Expect, data); err != nil {
+		return
+	}
+	err = jsonSchemaValidation(testcase.Expect.Schema, data)
+	return
+}
+
+// readServerSentEvents reads the events until the end of the stream, or the count of events is reached
+// if it's positive. The data of an event is parsed if it's JSON
+func readServerSentEvents(reader io.Reader, count int) (events []interface{}, err error) {
+	events = []interface{}{}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	event := map[string]interface{}{}
+	var data []string
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			// dispatch the event
+			if len(data) > 0 {
+				if _, ok := event["event"]; !ok {
+					event["event"] = "message"
+				}
+				event["data"] = parseEventData(strings.Join(data, "\n"))
+				events = append(events, event)
+				if count > 0 && len(events) >= count {
+					return
+				}
+			}
+			event, data = map[string]interface{}{}, nil
+			continue
+		} else if strings.HasPrefix(line, ":") {
+			// it's a comment
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "data":
+			data = append(data, value)
+		case "event", "id", "retry":
+			event[field] = value
+		}
+	}
+	err = scanner.Err()
+	return
+}
+
+func parseEventData(data string) interface{} {
+	var object interface{}
+	if err := json.Unmarshal([]byte(data), &object); err == nil {
+		return object
+	}
+	return data
+}
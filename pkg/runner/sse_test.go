@@ -0,0 +1,125 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadServerSentEvents(t *testing.T) {
+	stream := ": this is a comment\n\n" +
+		"event: tick\nid: 1\ndata: {\"count\": 1}\n\n" +
+		"data: first line\r\ndata: second line\r\n\r\n" +
+		"retry: 1000\n\n" +
+		"data:no-space\n\n"
+
+	events, err := readServerSentEvents(strings.NewReader(stream), 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"event": "tick", "id": "1", "data": map[string]interface{}{"count": float64(1)}},
+		map[string]interface{}{"event": "message", "data": "first line\nsecond line"},
+		map[string]interface{}{"event": "message", "data": "no-space"},
+	}, events)
+
+	events, err = readServerSentEvents(strings.NewReader(stream), 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(events))
+}
+
+func TestSSETestCaseRunner(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Accept") != "text/event-stream" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 1; i <= 3; i++ {
+			_, _ = fmt.Fprintf(w, "event: tick\ndata: {\"count\": %d}\n\n", i)
+			w.(http.Flusher).Flush()
+		}
+		// keep the connection until the client closes it
+		<-req.Context().Done()
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		testCase *atest.TestCase
+		verify   func(t *testing.T, output interface{}, err error)
+	}{{
+		name: "keep the connection for a duration",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: server.URL,
+				SSE: &atest.SSERequest{Duration: "200ms"},
+			},
+			Expect: atest.Response{
+				Header: map[string]string{"Content-Type": "text/event-stream"},
+				Verify: []string{`len(data) == 3`, `all(data, {.event == "tick"})`, `data[2].data.count == 3`},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+			assert.Equal(t, 3, len(output.([]interface{})))
+		},
+	}, {
+		name: "stop once the count is reached",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: server.URL,
+				SSE: &atest.SSERequest{Count: 2},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+			assert.Equal(t, 2, len(output.([]interface{})))
+		},
+	}, {
+		name: "less events than the count",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: server.URL,
+				SSE: &atest.SSERequest{Count: 5, Duration: "200ms"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "unexpected status code",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:    server.URL,
+				Header: map[string]string{"Accept": "application/json"},
+				SSE:    &atest.SSERequest{Duration: "200ms"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "invalid duration",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: server.URL,
+				SSE: &atest.SSERequest{Duration: "fake"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := GetTestCaseRunner(tt.testCase).RunTestCase(tt.testCase, nil, context.TODO())
+			tt.verify(t, output, err)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+// verifySSH runs the command by ssh in the batch mode, then compares the exit code and the output
+func (r *simpleTestCaseRunner) verifySSH(verification *testing.SSHVerification) (err error) {
+	if verification.Host == "" || verification.Command == "" {
+		err = fmt.Errorf("host and command are required")
+		return
+	}
+
+	args := []string{"-o", "BatchMode=yes"}
+	if verification.IdentityFile != "" {
+		args = append(args, "-i", verification.IdentityFile)
+	}
+	var target []string
+	if target, err = sshTarget(verification.Host, verification.User); err != nil {
+		return
+	}
+	args = append(args, target...)
+	args = append(args, verification.Command)
+
+	r.log.Info("start to run %q on %s\n", verification.Command, verification.Host)
+	output, runErr := r.execer.RunCommandAndReturn("ssh", "", args...)
+	r.log.Debug("output of the command: %s\n", output)
+
+	exitCode := 0
+	if runErr != nil {
+		// the error without the exit code means ssh cannot be started
+		var exitErr interface{ ExitCode() int }
+		if !errors.As(runErr, &exitErr) {
+			err = runErr
+			return
+		}
+		exitCode = exitErr.ExitCode()
+	}
+
+	if exitCode != verification.ExpectExitCode {
+		err = fmt.Errorf("expect exit code %d, actual %d, output: %s", verification.ExpectExitCode, exitCode, output)
+	} else if !strings.Contains(output, verification.OutputContains) {
+		err = fmt.Errorf("the output does not contain %q, output: %s", verification.OutputContains, output)
+	}
+	return
+}
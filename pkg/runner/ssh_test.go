@@ -0,0 +1,69 @@
+package runner
+
+import (
+	"errors"
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	fakeruntime "github.com/linuxsuren/go-fake-runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifySSH(t *testing.T) {
+	tests := []struct {
+		name         string
+		verification *atest.SSHVerification
+		execer       fakeruntime.Execer
+		expectErr    string
+	}{{
+		name:         "the output contains the text",
+		verification: &atest.SSHVerification{Host: "server:2222", User: "root", Command: "cat /tmp/order", OutputContains: "created"},
+		execer:       fakeruntime.FakeExecer{ExpectOutput: "order 1 created"},
+	}, {
+		name:         "expected exit code",
+		verification: &atest.SSHVerification{Host: "server", Command: "test -f /tmp/order", ExpectExitCode: 1},
+		execer:       fakeruntime.FakeExecer{ExpectError: fakeExitError(1)},
+	}, {
+		name:         "unexpected exit code",
+		verification: &atest.SSHVerification{Host: "server", Command: "systemctl is-active app"},
+		execer:       fakeruntime.FakeExecer{ExpectOutput: "inactive", ExpectError: fakeExitError(3)},
+		expectErr:    "expect exit code 0, actual 3, output: inactive",
+	}, {
+		name:         "the output does not contain the text",
+		verification: &atest.SSHVerification{Host: "server", Command: "cat /tmp/order", OutputContains: "deleted"},
+		execer:       fakeruntime.FakeExecer{ExpectOutput: "order 1 created"},
+		expectErr:    `the output does not contain "deleted"`,
+	}, {
+		name:         "cannot run ssh",
+		verification: &atest.SSHVerification{Host: "server", Command: "ls"},
+		execer:       fakeruntime.FakeExecer{ExpectError: errors.New("executable file not found")},
+		expectErr:    "executable file not found",
+	}, {
+		name:         "host and command are required",
+		verification: &atest.SSHVerification{Host: "server"},
+		execer:       fakeruntime.FakeExecer{},
+		expectErr:    "host and command are required",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewSimpleTestCaseRunner().WithExecer(tt.execer).(*simpleTestCaseRunner)
+			err := r.verifySSH(tt.verification)
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expectErr)
+			}
+		})
+	}
+}
+
+// fakeExitError is the error of a command which exits with the code
+type fakeExitError int
+
+func (e fakeExitError) Error() string {
+	return "exit status"
+}
+
+func (e fakeExitError) ExitCode() int {
+	return int(e)
+}
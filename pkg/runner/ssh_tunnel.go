@@ -0,0 +1,121 @@
+package runner
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	fakeruntime "github.com/linuxsuren/go-fake-runtime"
+)
+
+// sshTunnels are the opened tunnels by the local port, they're shared by the concurrent runs of the test suites
+var sshTunnels = &sshTunnelRegistry{tunnels: map[int]*sharedSSHTunnel{}}
+
+type sshTunnelRegistry struct {
+	lock    sync.Mutex
+	tunnels map[int]*sharedSSHTunnel
+}
+
+type sharedSSHTunnel struct {
+	tunnel testing.SSHTunnel
+	refs   int
+}
+
+// OpenSSHTunnel opens the SSH tunnel of the test suite, or reuses the one which forwards the same local port.
+// The tunnel is closed when all the returned close functions are called
+func OpenSSHTunnel(tunnel *testing.SSHTunnel) (closeTunnel func() error, err error) {
+	return sshTunnels.open(fakeruntime.DefaultExecer{}, tunnel)
+}
+
+func (r *sshTunnelRegistry) open(execer fakeruntime.Execer, tunnel *testing.SSHTunnel) (closeTunnel func() error, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	shared, ok := r.tunnels[tunnel.LocalPort]
+	if ok && shared.tunnel != *tunnel {
+		err = fmt.Errorf("the local port %d is forwarded to %s via %s already",
+			tunnel.LocalPort, shared.tunnel.RemoteAddr, shared.tunnel.Host)
+		return
+	} else if !ok {
+		if err = openSSHTunnel(execer, tunnel); err != nil {
+			return
+		}
+		shared = &sharedSSHTunnel{tunnel: *tunnel}
+		r.tunnels[tunnel.LocalPort] = shared
+	}
+	shared.refs++
+
+	var once sync.Once
+	closeTunnel = func() (closeErr error) {
+		once.Do(func() {
+			closeErr = r.close(execer, shared)
+		})
+		return
+	}
+	return
+}
+
+func (r *sshTunnelRegistry) close(execer fakeruntime.Execer, shared *sharedSSHTunnel) (err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if shared.refs--; shared.refs == 0 {
+		delete(r.tunnels, shared.tunnel.LocalPort)
+		err = closeSSHTunnel(execer, &shared.tunnel)
+	}
+	return
+}
+
+// openSSHTunnel starts a background SSH process which forwards the local port to the remote address
+func openSSHTunnel(execer fakeruntime.Execer, tunnel *testing.SSHTunnel) (err error) {
+	if tunnel.Host == "" || tunnel.LocalPort <= 0 || tunnel.RemoteAddr == "" {
+		err = fmt.Errorf("host, localPort and remoteAddr are required for the SSH tunnel")
+		return
+	}
+
+	args := []string{"-f", "-N", "-M", "-S", sshControlSocket(tunnel),
+		"-o", "ExitOnForwardFailure=yes",
+		"-L", fmt.Sprintf("%d:%s", tunnel.LocalPort, tunnel.RemoteAddr)}
+	var target []string
+	if target, err = sshTarget(tunnel.Host, tunnel.User); err == nil {
+		err = execer.RunCommand("ssh", append(args, target...)...)
+	}
+	return
+}
+
+// closeSSHTunnel asks the background SSH process to exit
+func closeSSHTunnel(execer fakeruntime.Execer, tunnel *testing.SSHTunnel) (err error) {
+	args := []string{"-S", sshControlSocket(tunnel), "-O", "exit"}
+	var target []string
+	if target, err = sshTarget(tunnel.Host, tunnel.User); err == nil {
+		err = execer.RunCommand("ssh", append(args, target...)...)
+	}
+	return
+}
+
+// sshTarget returns the arguments of the destination, the port is optional in the host.
+// The host and user which look like the options of the ssh command, such as: -oProxyCommand=, are invalid
+func sshTarget(host, user string) (args []string, err error) {
+	if h, port, splitErr := net.SplitHostPort(host); splitErr == nil {
+		host = h
+		args = append(args, "-p", port)
+	}
+
+	if strings.HasPrefix(host, "-") || strings.HasPrefix(user, "-") {
+		err = fmt.Errorf("invalid SSH host %q or user %q", host, user)
+		return
+	}
+	if user != "" {
+		host = fmt.Sprintf("%s@%s", user, host)
+	}
+	args = append(args, host)
+	return
+}
+
+func sshControlSocket(tunnel *testing.SSHTunnel) string {
+	return filepath.Join(os.TempDir(), fmt.Sprintf("atest-ssh-tunnel-%d.sock", tunnel.LocalPort))
+}
@@ -0,0 +1,77 @@
+package runner
+
+import (
+	"errors"
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	fakeruntime "github.com/linuxsuren/go-fake-runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSHTunnel(t *testing.T) {
+	tunnel := &atest.SSHTunnel{
+		Host:       "bastion:2222",
+		User:       "root",
+		LocalPort:  8080,
+		RemoteAddr: "10.0.0.1:80",
+	}
+
+	target, err := sshTarget(tunnel.Host, tunnel.User)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"-p", "2222", "root@bastion"}, target)
+	target, err = sshTarget("bastion", "")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"bastion"}, target)
+	_, err = sshTarget("-oProxyCommand=touch /tmp/pwned", "")
+	assert.NotNil(t, err)
+	_, err = sshTarget("bastion", "-oProxyCommand=sh")
+	assert.NotNil(t, err)
+	assert.Contains(t, sshControlSocket(tunnel), "atest-ssh-tunnel-8080.sock")
+
+	assert.Nil(t, openSSHTunnel(fakeruntime.FakeExecer{}, tunnel))
+	assert.Nil(t, closeSSHTunnel(fakeruntime.FakeExecer{}, tunnel))
+	assert.NotNil(t, openSSHTunnel(fakeruntime.FakeExecer{ExpectError: errors.New("fake")}, tunnel))
+	assert.NotNil(t, openSSHTunnel(fakeruntime.FakeExecer{}, &atest.SSHTunnel{Host: "bastion"}))
+	assert.NotNil(t, openSSHTunnel(fakeruntime.FakeExecer{}, &atest.SSHTunnel{
+		Host: "-oProxyCommand=sh", LocalPort: 8080, RemoteAddr: "10.0.0.1:80"}))
+}
+
+func TestSharedSSHTunnel(t *testing.T) {
+	execer := &sshCommandRecorder{}
+	registry := &sshTunnelRegistry{tunnels: map[int]*sharedSSHTunnel{}}
+	tunnel := &atest.SSHTunnel{Host: "bastion", LocalPort: 8080, RemoteAddr: "10.0.0.1:80"}
+
+	closeFirst, err := registry.open(execer, tunnel)
+	assert.Nil(t, err)
+	closeSecond, err := registry.open(execer, &atest.SSHTunnel{Host: "bastion", LocalPort: 8080, RemoteAddr: "10.0.0.1:80"})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(execer.calls), "the tunnel should be opened once")
+
+	// the same local port could not be forwarded to another address
+	_, err = registry.open(execer, &atest.SSHTunnel{Host: "bastion", LocalPort: 8080, RemoteAddr: "10.0.0.2:80"})
+	assert.NotNil(t, err)
+
+	assert.Nil(t, closeFirst())
+	assert.Nil(t, closeFirst())
+	assert.Equal(t, 1, len(execer.calls), "the tunnel is still used by the others")
+	assert.Nil(t, closeSecond())
+	if assert.Equal(t, 2, len(execer.calls)) {
+		assert.Contains(t, execer.calls[1], "exit")
+	}
+	assert.Empty(t, registry.tunnels)
+
+	_, err = registry.open(fakeruntime.FakeExecer{ExpectError: errors.New("fake")}, tunnel)
+	assert.NotNil(t, err)
+	assert.Empty(t, registry.tunnels)
+}
+
+type sshCommandRecorder struct {
+	fakeruntime.FakeExecer
+	calls [][]string
+}
+
+func (r *sshCommandRecorder) RunCommand(name string, args ...string) error {
+	r.calls = append(r.calls, append([]string{name}, args...))
+	return nil
+}
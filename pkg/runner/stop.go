@@ -0,0 +1,141 @@
+package runner
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/vm"
+)
+
+// Metrics represents the observed metrics of the records, the durations are in milliseconds
+// and the error rate is in percent
+type Metrics struct {
+	Count     int
+	Errors    int
+	ErrorRate float64
+	QPS       float64
+	Average   float64
+	Max       float64
+	Min       float64
+	P50       float64
+	P90       float64
+	P95       float64
+	P99       float64
+}
+
+// ComputeMetrics computes the metrics of all the records
+func ComputeMetrics(records []*ReportRecord) (metrics Metrics) {
+	metrics.Count = len(records)
+	if metrics.Count == 0 {
+		return
+	}
+
+	durations := make([]float64, 0, len(records))
+	var total float64
+	first, last := records[0].BeginTime, records[0].EndTime
+	for _, record := range records {
+		duration := float64(record.Duration()) / float64(time.Millisecond)
+		durations = append(durations, duration)
+		total += duration
+		metrics.Errors += record.ErrorCount()
+
+		if record.BeginTime.Before(first) {
+			first = record.BeginTime
+		}
+		if record.EndTime.After(last) {
+			last = record.EndTime
+		}
+	}
+	sort.Float64s(durations)
+
+	metrics.ErrorRate = float64(metrics.Errors) * 100 / float64(metrics.Count)
+	metrics.Average = total / float64(metrics.Count)
+	metrics.Min = durations[0]
+	metrics.Max = durations[len(durations)-1]
+	metrics.P50 = percentile(durations, 50)
+	metrics.P90 = percentile(durations, 90)
+	metrics.P95 = percentile(durations, 95)
+	metrics.P99 = percentile(durations, 99)
+	if seconds := last.Sub(first).Seconds(); seconds > 0 {
+		metrics.QPS = float64(metrics.Count) / seconds
+	}
+	return
+}
+
+// percentile returns the nearest-rank percentile of the sorted values
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func (m Metrics) env() map[string]interface{} {
+	return map[string]interface{}{
+		"count":     m.Count,
+		"errors":    m.Errors,
+		"errorRate": m.ErrorRate,
+		"qps":       m.QPS,
+		"avg":       m.Average,
+		"max":       m.Max,
+		"min":       m.Min,
+		"p50":       m.P50,
+		"p90":       m.P90,
+		"p95":       m.P95,
+		"p99":       m.P99,
+	}
+}
+
+// String returns the summary of the metrics
+func (m Metrics) String() string {
+	return fmt.Sprintf("count=%d errorRate=%.2f%% qps=%.2f avg=%.2fms p50=%.2fms p90=%.2fms p95=%.2fms p99=%.2fms max=%.2fms",
+		m.Count, m.ErrorRate, m.QPS, m.Average, m.P50, m.P90, m.P95, m.P99, m.Max)
+}
+
+// StopCondition is an expression of the metrics to abort a run, such as: p95>800ms || errorRate>5%
+type StopCondition struct {
+	text    string
+	program *vm.Program
+}
+
+var durationLiteralReg = regexp.MustCompile(`\b(\d+(?:\.\d+)?)(us|ms|s|m)\b`)
+var percentLiteralReg = regexp.MustCompile(`\b(\d+(?:\.\d+)?)%`)
+
+// NewStopCondition compiles the stop condition. The duration literals (e.g. 800ms, 1.5s) are converted
+// to milliseconds, and the percent literals (e.g. 5%) are converted to numbers
+func NewStopCondition(text string) (condition *StopCondition, err error) {
+	code := durationLiteralReg.ReplaceAllStringFunc(text, func(literal string) string {
+		groups := durationLiteralReg.FindStringSubmatch(literal)
+		duration, _ := time.ParseDuration(groups[1] + groups[2])
+		return strconv.FormatFloat(float64(duration)/float64(time.Millisecond), 'f', -1, 64)
+	})
+	code = percentLiteralReg.ReplaceAllString(code, "$1")
+
+	var program *vm.Program
+	if program, err = expr.Compile(code, expr.Env(Metrics{}.env()), expr.AsBool()); err != nil {
+		err = fmt.Errorf("invalid stop condition '%s', %v", text, err)
+		return
+	}
+	condition = &StopCondition{text: text, program: program}
+	return
+}
+
+// Match returns true if the metrics meet the condition
+func (c *StopCondition) Match(metrics Metrics) (ok bool, err error) {
+	var result interface{}
+	if result, err = expr.Run(c.program, metrics.env()); err == nil {
+		ok, _ = result.(bool)
+	}
+	return
+}
+
+// String returns the original text of the condition
+func (c *StopCondition) String() string {
+	return c.text
+}
@@ -0,0 +1,71 @@
+package runner
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeMetrics(t *testing.T) {
+	assert.Equal(t, Metrics{}, ComputeMetrics(nil))
+
+	now := time.Now()
+	var records []*ReportRecord
+	for i := 1; i <= 100; i++ {
+		record := &ReportRecord{
+			BeginTime: now,
+			EndTime:   now.Add(time.Duration(i) * time.Millisecond),
+		}
+		if i%10 == 0 {
+			record.Error = errors.New("fake")
+		}
+		records = append(records, record)
+	}
+
+	metrics := ComputeMetrics(records)
+	assert.Equal(t, 100, metrics.Count)
+	assert.Equal(t, 10, metrics.Errors)
+	assert.Equal(t, 10.0, metrics.ErrorRate)
+	assert.Equal(t, 50.5, metrics.Average)
+	assert.Equal(t, 1.0, metrics.Min)
+	assert.Equal(t, 100.0, metrics.Max)
+	assert.Equal(t, 50.0, metrics.P50)
+	assert.Equal(t, 90.0, metrics.P90)
+	assert.Equal(t, 95.0, metrics.P95)
+	assert.Equal(t, 99.0, metrics.P99)
+	assert.Equal(t, 1000.0, metrics.QPS)
+	assert.Contains(t, metrics.String(), "p95=95.00ms")
+}
+
+func TestStopCondition(t *testing.T) {
+	metrics := Metrics{Count: 100, Errors: 6, ErrorRate: 6, P95: 500, Max: 1200}
+
+	tests := []struct {
+		condition string
+		match     bool
+		hasErr    bool
+	}{
+		{condition: "p95>800ms || errorRate>5%", match: true},
+		{condition: "p95>800ms && errorRate>5%", match: false},
+		{condition: "p95 > 0.4s", match: true},
+		{condition: "max>1m", match: false},
+		{condition: "count >= 100 and errors > 10", match: false},
+		{condition: "fake > 1", hasErr: true},
+		{condition: "p95", hasErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			condition, err := NewStopCondition(tt.condition)
+			if !assert.Equal(t, tt.hasErr, err != nil, err) || tt.hasErr {
+				return
+			}
+
+			assert.Equal(t, tt.condition, condition.String())
+			match, err := condition.Match(metrics)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.match, match)
+		})
+	}
+}
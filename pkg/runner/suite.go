@@ -0,0 +1,102 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+// SetupSuite prepares the environment of the test suite before running its test cases in scope, such as the
+// webhook listeners, the SSH tunnel, the identities and the public tunnel. It's shared by the run command and
+// the server. The returned function tears the environment down, it's called already if there is an error
+func SetupSuite(ctx context.Context, suite *testing.TestSuite, caseItems []string,
+	dataContext map[string]interface{}) (teardown func() error, err error) {
+	var teardowns []func() error
+	teardown = func() (err error) {
+		for i := len(teardowns) - 1; i >= 0; i-- {
+			if tearErr := teardowns[i](); tearErr != nil && err == nil {
+				err = tearErr
+			}
+		}
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = teardown()
+		}
+	}()
+
+	// the webhook listeners are shared by the threads which run the same test suite
+	var webhookListeners []*testing.WebhookListener
+	for i := range suite.Items {
+		if listener := suite.Items[i].Prepare.WebhookListener; listener != nil && suite.Items[i].InScope(caseItems) {
+			webhookListeners = append(webhookListeners, listener)
+		}
+	}
+	var closeListeners func()
+	if closeListeners, err = OpenWebhookListeners(webhookListeners); err != nil {
+		err = fmt.Errorf("failed to start the webhook listeners, error: %v", err)
+		return
+	}
+	teardowns = append(teardowns, func() error {
+		closeListeners()
+		return nil
+	})
+
+	// the SSH tunnel is shared by the threads which run the same test suite
+	if sshTunnel := suite.SSHTunnel; sshTunnel != nil {
+		var closeTunnel func() error
+		if closeTunnel, err = OpenSSHTunnel(sshTunnel); err != nil {
+			err = fmt.Errorf("failed to open the SSH tunnel, error: %v", err)
+			return
+		}
+		teardowns = append(teardowns, func() (closeErr error) {
+			if closeErr = closeTunnel(); closeErr != nil {
+				closeErr = fmt.Errorf("failed to close the SSH tunnel, error: %v", closeErr)
+			}
+			return
+		})
+	}
+
+	if len(suite.Identities) > 0 {
+		var identities []*ProvisionedIdentity
+		identities, err = ProvisionIdentities(ctx, suite.Identities)
+		// the identities are deleted even if the test cases time out
+		teardowns = append(teardowns, func() error {
+			deleteCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			return DeleteIdentities(deleteCtx, identities)
+		})
+		if err != nil {
+			return
+		}
+		dataContext["identity"] = IdentityContext(identities)
+	}
+	if tunnel := suite.Tunnel; tunnel != nil {
+		var publicURL string
+		var stop func()
+		if publicURL, stop, err = OpenTunnel(ctx, tunnel); err != nil {
+			return
+		}
+		teardowns = append(teardowns, func() error {
+			stop()
+			return nil
+		})
+		dataContext["tunnel"] = map[string]string{"url": publicURL}
+	}
+	return
+}
+
+// SuiteExecFeatures returns the features of the test suite which run the local commands, such as the SSH tunnel.
+// They should be allowed explicitly if the test suite comes from the remote
+func SuiteExecFeatures(suite *testing.TestSuite) (features []string) {
+	if suite.SSHTunnel != nil {
+		features = append(features, "SSH tunnel")
+	}
+	if suite.Tunnel != nil {
+		features = append(features, "tunnel agent")
+	}
+	return
+}
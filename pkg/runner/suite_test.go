@@ -0,0 +1,46 @@
+package runner
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetupSuiteWebhookListener(t *testing.T) {
+	port, skipped := freePort(t), freePort(t)
+	suite := &atest.TestSuite{Items: []atest.TestCase{{
+		Name:    "webhook",
+		Prepare: atest.Prepare{WebhookListener: &atest.WebhookListener{Port: port}},
+		Request: atest.Request{Webhook: &atest.WebhookRequest{Port: port, Timeout: "1ms"}},
+	}, {
+		Name:    "skipped",
+		Prepare: atest.Prepare{WebhookListener: &atest.WebhookListener{Port: skipped}},
+	}}}
+
+	teardown, err := SetupSuite(context.TODO(), suite, []string{"webhook"}, map[string]interface{}{})
+	if !assert.Nil(t, err) {
+		return
+	}
+	_, err = NewWebhookTestCaseRunner().RunTestCase(&suite.Items[0], nil, context.TODO())
+	assert.EqualError(t, err, "case: webhook, expect 1 requests in 1ms, received 0")
+	assert.NotNil(t, getWebhookReceiver(port))
+	assert.Nil(t, getWebhookReceiver(skipped), "the listeners of the test cases out of scope are not started")
+
+	assert.Nil(t, teardown())
+	assert.Nil(t, getWebhookReceiver(port))
+
+	// the port is in use
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if assert.Nil(t, err) {
+		defer ln.Close()
+		suite.Items[0].Prepare.WebhookListener.Port = ln.Addr().(*net.TCPAddr).Port
+		_, err = SetupSuite(context.TODO(), suite, nil, map[string]interface{}{})
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), "failed to start the webhook listeners")
+		}
+		assert.Nil(t, getWebhookReceiver(skipped))
+	}
+}
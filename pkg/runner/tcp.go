@@ -0,0 +1,190 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+const (
+	// defaultTCPTimeout is the timeout of a TCP session if it's not set
+	defaultTCPTimeout = 10 * time.Second
+	// the encodings of the TCP data
+	tcpEncodingText = "text"
+	tcpEncodingHex  = "hex"
+)
+
+type tcpTestCaseRunner struct {
+	*simpleTestCaseRunner
+}
+
+// NewTCPTestCaseRunner creates the instance of the raw TCP test case runner
+func NewTCPTestCaseRunner() TestCaseRunner {
+	runner := &tcpTestCaseRunner{simpleTestCaseRunner: &simpleTestCaseRunner{}}
+	return runner.embeddedBy(runner)
+}
+
+// RunTestCase writes the body to the TCP connection, then verifies the bytes read back
+func (r *tcpTestCaseRunner) RunTestCase(testcase *testing.TestCase, dataContext interface{}, ctx context.Context) (output interface{}, err error) {
+	return r.runTestCaseWith(testcase, func(record *ReportRecord) (interface{}, error) {
+		return r.doTCPRequest(testcase, dataContext, ctx, record)
+	})
+}
+
+func (r *tcpTestCaseRunner) doTCPRequest(testcase *testing.TestCase, dataContext interface{}, ctx context.Context,
+	record *ReportRecord) (output interface{}, err error) {
+	if err = testcase.Request.Render(dataContext); err != nil {
+		return
+	}
+
+	tcpRequest := testcase.Request.TCP
+	record.Method = "TCP"
+
+	var body, delimiter []byte
+	if body, err = decodeTCPData(tcpRequest.Encoding, testcase.Request.Body); err != nil {
+		return
+	}
+	if delimiter, err = decodeTCPData(tcpRequest.Encoding, tcpRequest.Delimiter); err != nil {
+		return
+	}
+
+	deadline := time.Now().Add(defaultTCPTimeout)
+	if tcpRequest.Timeout != "" {
+		var timeout time.Duration
+		if timeout, err = time.ParseDuration(tcpRequest.Timeout); err != nil {
+			return
+		}
+		deadline = time.Now().Add(timeout)
+	}
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	r.log.Info("start to connect %s\n", testcase.Request.API)
+	dialer := &net.Dialer{Deadline: deadline}
+	var conn net.Conn
+	if tcpRequest.TLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", testcase.Request.API, &tls.Config{InsecureSkipVerify: true})
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", testcase.Request.API)
+	}
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(deadline); err != nil {
+		return
+	}
+
+	if len(body) > 0 {
+		if _, err = conn.Write(body); err != nil {
+			return
+		}
+	}
+
+	var data []byte
+	if data, err = readTCPData(conn, tcpRequest.Size, delimiter); err != nil {
+		err = fmt.Errorf("case: %s, failed to read, received %d bytes, %v", testcase.Name, len(data), err)
+		return
+	}
+	r.log.Debug("received: %s\n", encodeTCPData(tcpRequest.Encoding, data))
+	output, err = verifyRawData(testcase, tcpRequest.Encoding, data, record)
+	return
+}
+
+// verifyRawData verifies the bytes with the expected body in the encoding, then verifies them as an object
+// which has the fields text, hex and length
+func verifyRawData(testcase *testing.TestCase, encoding string, data []byte, record *ReportRecord) (output interface{}, err error) {
+	actual := encodeTCPData(encoding, data)
+	record.Body = actual
+
+	if testcase.Expect.Body != "" {
+		var expect []byte
+		if expect, err = decodeTCPData(encoding, strings.TrimSpace(testcase.Expect.Body)); err != nil {
+			return
+		}
+		if err = expectString(testcase.Name, encodeTCPData(encoding, expect), strings.TrimSpace(actual)); err != nil {
+			return
+		}
+	}
+
+	// the received bytes are verified as an object, the body is verified already
+	var result []byte
+	if result, err = json.Marshal(map[string]interface{}{
+		"text":   string(data),
+		"hex":    hex.EncodeToString(data),
+		"length": len(data),
+	}); err != nil {
+		return
+	}
+	expect := testcase.Expect
+	expect.Body = ""
+	if output, err = verifyResponseBodyData(testcase.Name, expect, result); err != nil {
+		return
+	}
+	err = jsonSchemaValidation(testcase.Expect.Schema, result)
+	return
+}
+
+// readTCPData reads the bytes until the size, the delimiter or the connection is closed,
+// the timeout is not an error if neither the size nor delimiter is set
+func readTCPData(conn net.Conn, size int, delimiter []byte) (data []byte, err error) {
+	if size > 0 {
+		data = make([]byte, size)
+		var n int
+		n, err = io.ReadFull(conn, data)
+		data = data[:n]
+		return
+	}
+
+	buf := make([]byte, 4096)
+	for {
+		var n int
+		n, err = conn.Read(buf)
+		data = append(data, buf[:n]...)
+		if index := bytes.Index(data, delimiter); len(delimiter) > 0 && index >= 0 {
+			data, err = data[:index+len(delimiter)], nil
+			return
+		}
+
+		if err != nil {
+			if len(delimiter) == 0 && (err == io.EOF || errors.Is(err, os.ErrDeadlineExceeded)) {
+				err = nil
+			}
+			return
+		}
+	}
+}
+
+func decodeTCPData(encoding, text string) (data []byte, err error) {
+	switch encoding {
+	case "", tcpEncodingText:
+		data = []byte(text)
+	case tcpEncodingHex:
+		// the spaces are allowed to make the hex string readable, such as: 0a 0b
+		if data, err = hex.DecodeString(strings.Join(strings.Fields(text), "")); err != nil {
+			err = fmt.Errorf("invalid hex data %q, %v", text, err)
+		}
+	default:
+		err = fmt.Errorf("unsupported encoding %q", encoding)
+	}
+	return
+}
+
+func encodeTCPData(encoding string, data []byte) string {
+	if encoding == tcpEncodingHex {
+		return hex.EncodeToString(data)
+	}
+	return string(data)
+}
@@ -0,0 +1,190 @@
+package runner_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/linuxsuren/api-testing/pkg/runner"
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTCPTestCaseRunner(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			// a line based protocol, it closes the connection after the QUIT command
+			go func(conn net.Conn) {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					switch strings.TrimSpace(line) {
+					case "PING":
+						_, _ = conn.Write([]byte("+PONG\r\n+EXTRA\r\n"))
+					case "QUIT":
+						_, _ = conn.Write([]byte("+BYE\r\n"))
+						return
+					case "SILENT":
+					default:
+						_, _ = conn.Write([]byte("-ERR " + line))
+					}
+				}
+			}(conn)
+		}
+	}()
+	api := listener.Addr().String()
+
+	tests := []struct {
+		name     string
+		testCase *atest.TestCase
+		verify   func(t *testing.T, output interface{}, err error)
+	}{{
+		name: "read until the delimiter",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				Body: "{{.command}}\n",
+				TCP:  &atest.TCPRequest{Delimiter: "\r\n"},
+			},
+			Expect: atest.Response{
+				Body:   "+PONG",
+				Verify: []string{`data.length == 7`, `data.text startsWith "+PONG"`},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+			assert.Equal(t, map[string]interface{}{
+				"text": "+PONG\r\n", "hex": "2b504f4e470d0a", "length": float64(7),
+			}, output)
+		},
+	}, {
+		name: "read the size in hex",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				Body: "50 49 4e 47 0a",
+				TCP:  &atest.TCPRequest{Encoding: "hex", Size: 5},
+			},
+			Expect: atest.Response{
+				Body: "2B 50 4F 4E 47",
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name: "read until the connection is closed",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				Body: "QUIT\n",
+				TCP:  &atest.TCPRequest{},
+			},
+			Expect: atest.Response{
+				Body: "+BYE",
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name: "unexpected body",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				Body: "FAKE\n",
+				TCP:  &atest.TCPRequest{Delimiter: "\n"},
+			},
+			Expect: atest.Response{
+				Body: "+OK",
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "the delimiter is not received before timeout",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				Body: "SILENT\n",
+				TCP:  &atest.TCPRequest{Delimiter: "\n", Timeout: "100ms"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "nothing is received before timeout",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				Body: "SILENT\n",
+				TCP:  &atest.TCPRequest{Timeout: "100ms"},
+			},
+			Expect: atest.Response{
+				Verify: []string{`data.length == 0`},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name: "invalid hex body",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				Body: "fake",
+				TCP:  &atest.TCPRequest{Encoding: "hex"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "unsupported encoding",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: api,
+				TCP: &atest.TCPRequest{Encoding: "fake"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "cannot connect",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: "127.0.0.1:1",
+				TCP: &atest.TCPRequest{},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tcpRunner := runner.GetTestCaseRunner(tt.testCase)
+			output, err := tcpRunner.RunTestCase(tt.testCase, map[string]string{"command": "PING"}, context.TODO())
+			tt.verify(t, output, err)
+		})
+	}
+}
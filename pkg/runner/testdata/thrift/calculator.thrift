@@ -0,0 +1,44 @@
+/*
+ * The calculator of the Thrift tutorial
+ */
+include "shared.thrift"
+
+namespace go tutorial
+namespace java tutorial
+
+typedef i32 MyInteger
+
+const i32 INT32CONSTANT = 9853
+const map<string,string> MAPCONSTANT = {'hello':'world', 'goodnight':'moon'}
+
+enum Operation {
+  ADD = 1,
+  SUBTRACT = 2,
+  MULTIPLY = 3,
+  DIVIDE = 4
+}
+
+struct Work {
+  1: i32 num1 = 0,
+  2: i32 num2,
+  3: Operation op,
+  4: optional string comment,
+}
+
+exception InvalidOperation {
+  1: i32 whatOp,
+  2: string why
+}
+
+service Calculator extends shared.SharedService {
+   void ping(),
+
+   i32 add(1:i32 num1, 2:i32 num2),
+
+   # the work is calculated
+   i32 calculate(1:i32 logid, 2:Work w) throws (1:InvalidOperation ouch),
+
+   list<shared.SharedStruct> search(1: map<MyInteger, string> filters, 2: set<Operation> ops) (deprecated = "true")
+
+   oneway void zip()
+}
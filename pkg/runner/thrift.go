@@ -0,0 +1,253 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+const (
+	// defaultThriftTimeout is the timeout of a Thrift call if it's not set
+	defaultThriftTimeout = 10 * time.Second
+	// thriftTransportFramed is the transport which has the size before each message
+	thriftTransportFramed = "framed"
+	// thriftProtocolCompact is the protocol which encodes the integers in varint
+	thriftProtocolCompact = "compact"
+	// thriftContentType is the content type of the message over HTTP
+	thriftContentType = "application/x-thrift"
+	// thriftSeqID is the sequence id of the call, there's only one call in a connection
+	thriftSeqID int32 = 1
+)
+
+type thriftTestCaseRunner struct {
+	*simpleTestCaseRunner
+}
+
+// NewThriftTestCaseRunner creates the instance of the Thrift test case runner
+func NewThriftTestCaseRunner() TestCaseRunner {
+	runner := &thriftTestCaseRunner{simpleTestCaseRunner: &simpleTestCaseRunner{}}
+	return runner.embeddedBy(runner)
+}
+
+// RunTestCase calls the method with the arguments in the body, then verifies the returned value
+func (r *thriftTestCaseRunner) RunTestCase(testcase *testing.TestCase, dataContext interface{}, ctx context.Context) (output interface{}, err error) {
+	return r.runTestCaseWith(testcase, func(record *ReportRecord) (interface{}, error) {
+		return r.doThriftRequest(testcase, dataContext, ctx, record)
+	})
+}
+
+func (r *thriftTestCaseRunner) doThriftRequest(testcase *testing.TestCase, dataContext interface{}, ctx context.Context,
+	record *ReportRecord) (output interface{}, err error) {
+	if err = testcase.Request.Render(dataContext); err != nil {
+		return
+	}
+
+	thriftRequest := testcase.Request.Thrift
+	record.Method = "THRIFT"
+
+	var doc *thriftDocument
+	if doc, err = parseThriftFile(thriftRequest.IDL); err != nil {
+		return
+	}
+	var method *thriftMethod
+	if method, err = doc.findMethod(thriftRequest.Service, thriftRequest.Method); err != nil {
+		err = fmt.Errorf("case: %s, %v", testcase.Name, err)
+		return
+	}
+
+	var args interface{}
+	if testcase.Request.Body != "" {
+		decoder := json.NewDecoder(bytes.NewBufferString(testcase.Request.Body))
+		decoder.UseNumber()
+		if err = decoder.Decode(&args); err != nil {
+			err = fmt.Errorf("case: %s, the body is not a valid JSON, %v", testcase.Name, err)
+			return
+		}
+	}
+
+	codec := &thriftCodec{doc: doc}
+	messageType := thriftMessageCall
+	if method.Oneway {
+		messageType = thriftMessageOneway
+	}
+	compact := thriftRequest.Protocol == thriftProtocolCompact
+	writer := &thriftWriter{compact: compact}
+	writer.messageBegin(method.Name, messageType, thriftSeqID)
+	if err = codec.encodeStruct(writer, method.Args, args); err != nil {
+		err = fmt.Errorf("case: %s, invalid arguments of %s, %v", testcase.Name, method.Name, err)
+		return
+	}
+
+	deadline := time.Now().Add(defaultThriftTimeout)
+	if thriftRequest.Timeout != "" {
+		var timeout time.Duration
+		if timeout, err = time.ParseDuration(thriftRequest.Timeout); err != nil {
+			return
+		}
+		deadline = time.Now().Add(timeout)
+	}
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	r.log.Info("start to call %s of %s\n", method.Name, testcase.Request.API)
+	var reader io.Reader
+	if strings.HasPrefix(testcase.Request.API, "http://") || strings.HasPrefix(testcase.Request.API, "https://") {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+
+		var resp *http.Response
+		if resp, err = postThriftMessage(ctx, &testcase.Request, writer.buf); err != nil {
+			err = fmt.Errorf("case: %s, %v", testcase.Name, err)
+			return
+		}
+		defer resp.Body.Close()
+		if method.Oneway {
+			return
+		}
+		reader = resp.Body
+	} else {
+		var conn net.Conn
+		if conn, err = (&net.Dialer{Deadline: deadline}).DialContext(ctx, "tcp", testcase.Request.API); err != nil {
+			return
+		}
+		defer conn.Close()
+		if err = conn.SetDeadline(deadline); err != nil {
+			return
+		}
+
+		message := writer.buf
+		if thriftRequest.Transport == thriftTransportFramed {
+			message = append(make([]byte, 4), message...)
+			binary.BigEndian.PutUint32(message, uint32(len(message)-4))
+		}
+		if _, err = conn.Write(message); err != nil || method.Oneway {
+			return
+		}
+
+		reader = conn
+		if thriftRequest.Transport == thriftTransportFramed {
+			var data []byte
+			if data, err = readFrame(conn); err != nil {
+				err = fmt.Errorf("case: %s, failed to read the frame, %v", testcase.Name, err)
+				return
+			}
+			reader = bytes.NewReader(data)
+		}
+	}
+
+	var result interface{}
+	if result, err = readThriftReply(newThriftReader(reader, compact), codec, method); err != nil {
+		err = fmt.Errorf("case: %s, %v", testcase.Name, err)
+		return
+	}
+
+	var data []byte
+	if data, err = json.Marshal(result); err != nil {
+		return
+	}
+	record.Body = string(data)
+	if output, err = verifyResponseBodyValue(testcase.Name, testcase.Expect, data, true); err != nil {
+		return
+	}
+	err = jsonSchemaValidation(testcase.Expect.Schema, data)
+	return
+}
+
+// postThriftMessage sends the message as the body of a POST request, the reply is the response body
+func postThriftMessage(ctx context.Context, request *testing.Request, message []byte) (resp *http.Response, err error) {
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodPost, request.API, bytes.NewReader(message)); err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", thriftContentType)
+	req.Header.Set("Accept", thriftContentType)
+	for key, val := range request.Header {
+		req.Header.Set(key, val)
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+	if resp, err = client.Do(req); err == nil && resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		err = fmt.Errorf("unexpected status code %d, %s", resp.StatusCode, string(data))
+	}
+	return
+}
+
+// readThriftReply returns the success value of the reply, the declared exception is an error
+func readThriftReply(reader *thriftReader, codec *thriftCodec, method *thriftMethod) (result interface{}, err error) {
+	name, messageType, seqID := reader.messageBegin()
+	if err = reader.err; err != nil {
+		return
+	}
+	if name != method.Name || seqID != thriftSeqID {
+		err = fmt.Errorf("unexpected reply of %s with the sequence id %d", name, seqID)
+		return
+	}
+
+	switch messageType {
+	case thriftMessageException:
+		var exception map[string]interface{}
+		if exception, err = codec.decodeStruct(reader, thriftApplicationException); err == nil {
+			err = fmt.Errorf("got Thrift application exception: %v", exception["message"])
+		}
+		return
+	case thriftMessageReply:
+	default:
+		err = fmt.Errorf("unexpected Thrift message type %d", messageType)
+		return
+	}
+
+	// the result struct has the return value as the field 0, and the declared exceptions
+	fields := method.Throws
+	if method.Return != nil {
+		fields = append([]thriftField{{ID: 0, Name: "success", Type: method.Return}}, fields...)
+	}
+	var object map[string]interface{}
+	if object, err = codec.decodeStruct(reader, fields); err != nil {
+		return
+	}
+	for _, field := range method.Throws {
+		if exception, ok := object[field.Name]; ok {
+			var data []byte
+			data, _ = json.Marshal(exception)
+			err = fmt.Errorf("got Thrift exception %s: %s", field.Type.Name, string(data))
+			return
+		}
+	}
+	result = object["success"]
+	return
+}
+
+// thriftApplicationException is the fields of the exception which is raised by the Thrift framework
+var thriftApplicationException = []thriftField{
+	{ID: 1, Name: "message", Type: &thriftType{Name: "string"}},
+	{ID: 2, Name: "type", Type: &thriftType{Name: "i32"}},
+}
+
+// readFrame reads a message which has the size before it
+func readFrame(reader io.Reader) (data []byte, err error) {
+	size := make([]byte, 4)
+	if _, err = io.ReadFull(reader, size); err != nil {
+		return
+	}
+	data = make([]byte, binary.BigEndian.Uint32(size))
+	_, err = io.ReadFull(reader, data)
+	return
+}
@@ -0,0 +1,531 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// thriftType is a type in the Thrift IDL, the name is a base type, a container (list, set and map),
+// or the name of a typedef, enum, or struct
+type thriftType struct {
+	Name string
+	Key  *thriftType
+	Elem *thriftType
+	// scope is the name of the included file which defines the type
+	scope string
+}
+
+type thriftField struct {
+	ID       int16
+	Name     string
+	Type     *thriftType
+	Required bool
+}
+
+// thriftStruct is a struct, union or exception
+type thriftStruct struct {
+	Name   string
+	Fields []thriftField
+}
+
+type thriftMethod struct {
+	Name   string
+	Return *thriftType
+	Args   []thriftField
+	Throws []thriftField
+	Oneway bool
+}
+
+type thriftService struct {
+	Name    string
+	Extends string
+	Methods map[string]*thriftMethod
+	scope   string
+}
+
+// thriftDocument contains the definitions of an IDL file and its included files,
+// the definitions of the included files are prefixed by the file name, e.g. shared.SharedStruct
+type thriftDocument struct {
+	typedefs map[string]*thriftType
+	enums    map[string]map[string]int32
+	structs  map[string]*thriftStruct
+	services map[string]*thriftService
+	// kinds is the kind of each qualified name
+	kinds map[string]string
+}
+
+// the kinds of the definitions
+const (
+	thriftKindTypedef = "typedef"
+	thriftKindEnum    = "enum"
+	thriftKindStruct  = "struct"
+	thriftKindService = "service"
+)
+
+var thriftBaseTypes = map[string]bool{
+	"bool": true, "byte": true, "i8": true, "i16": true, "i32": true, "i64": true,
+	"double": true, "string": true, "binary": true,
+	"list": true, "set": true, "map": true,
+}
+
+// parseThriftFile parses the IDL file and the included files
+func parseThriftFile(file string) (doc *thriftDocument, err error) {
+	doc = &thriftDocument{
+		typedefs: map[string]*thriftType{},
+		enums:    map[string]map[string]int32{},
+		structs:  map[string]*thriftStruct{},
+		services: map[string]*thriftService{},
+		kinds:    map[string]string{},
+	}
+	err = doc.parseFile(file, "", map[string]bool{})
+	return
+}
+
+func (d *thriftDocument) parseFile(file, scope string, parsed map[string]bool) (err error) {
+	if parsed[file] {
+		return
+	}
+	parsed[file] = true
+
+	var data []byte
+	if data, err = os.ReadFile(file); err != nil {
+		return
+	}
+	parser := &thriftParser{tokens: tokenizeThrift(string(data)), doc: d, scope: scope}
+	if err = parser.parse(); err != nil {
+		err = fmt.Errorf("failed to parse %s, %v", file, err)
+		return
+	}
+
+	for _, include := range parser.includes {
+		includeScope := strings.TrimSuffix(filepath.Base(include), filepath.Ext(include))
+		if err = d.parseFile(filepath.Join(filepath.Dir(file), include), includeScope, parsed); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// lookup returns the qualified name and the kind of the definition, the one in the same scope comes first
+func (d *thriftDocument) lookup(name, scope string) (qualified, kind string) {
+	if scope != "" {
+		if kind = d.kinds[scope+"."+name]; kind != "" {
+			qualified = scope + "." + name
+			return
+		}
+	}
+	qualified, kind = name, d.kinds[name]
+	return
+}
+
+// underlying follows the typedefs, then returns the type whose name is a base type, a container,
+// or the qualified name of an enum or struct
+func (d *thriftDocument) underlying(t *thriftType) (resolved *thriftType, err error) {
+	for i := 0; i < 100; i++ {
+		if thriftBaseTypes[t.Name] {
+			resolved = t
+			return
+		}
+
+		switch name, kind := d.lookup(t.Name, t.scope); kind {
+		case thriftKindTypedef:
+			t = d.typedefs[name]
+		case thriftKindEnum, thriftKindStruct:
+			resolved = &thriftType{Name: name}
+			return
+		default:
+			err = fmt.Errorf("unknown Thrift type %q", t.Name)
+			return
+		}
+	}
+	err = fmt.Errorf("circular Thrift typedef %q", t.Name)
+	return
+}
+
+// findMethod finds the method in the service and the services it extends,
+// the service name is optional if there's only one service in the IDL file except the included files
+func (d *thriftDocument) findMethod(serviceName, methodName string) (method *thriftMethod, err error) {
+	var service *thriftService
+	if serviceName == "" {
+		for _, item := range d.services {
+			if item.scope == "" {
+				if service != nil {
+					err = fmt.Errorf("the Thrift service is required since there are multiple services")
+					return
+				}
+				service = item
+			}
+		}
+	} else {
+		service = d.services[serviceName]
+	}
+	if service == nil {
+		err = fmt.Errorf("not found Thrift service %q", serviceName)
+		return
+	}
+
+	for i := 0; service != nil && i < 100; i++ {
+		if method = service.Methods[methodName]; method != nil {
+			return
+		}
+		if service.Extends == "" {
+			break
+		}
+		name, _ := d.lookup(service.Extends, service.scope)
+		service = d.services[name]
+	}
+	err = fmt.Errorf("not found method %q in Thrift service %q", methodName, serviceName)
+	return
+}
+
+type thriftParser struct {
+	tokens   []string
+	pos      int
+	doc      *thriftDocument
+	scope    string
+	includes []string
+}
+
+func (p *thriftParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *thriftParser) next() (token string) {
+	token = p.peek()
+	p.pos++
+	return
+}
+
+func (p *thriftParser) expect(token string) (err error) {
+	if actual := p.next(); actual != token {
+		err = fmt.Errorf("expect %q, actual %q", token, actual)
+	}
+	return
+}
+
+// define records the kind of the definition, then returns the name prefixed by the scope of the included file
+func (p *thriftParser) define(kind, name string) (qualified string) {
+	qualified = name
+	if p.scope != "" {
+		qualified = p.scope + "." + name
+	}
+	p.doc.kinds[qualified] = kind
+	return
+}
+
+func (p *thriftParser) parse() (err error) {
+	for p.pos < len(p.tokens) && err == nil {
+		switch keyword := p.next(); keyword {
+		case "include":
+			p.includes = append(p.includes, unquoteThrift(p.next()))
+		case "cpp_include":
+			p.next()
+		case "namespace":
+			p.pos += 2
+		case "const":
+			if _, err = p.parseType(); err == nil {
+				p.next()
+				if err = p.expect("="); err == nil {
+					p.skipValue()
+					p.skipSeparator()
+				}
+			}
+		case "typedef":
+			var t *thriftType
+			if t, err = p.parseType(); err == nil {
+				p.doc.typedefs[p.define(thriftKindTypedef, p.next())] = t
+				p.skipAnnotations()
+				p.skipSeparator()
+			}
+		case "enum":
+			err = p.parseEnum()
+		case "senum":
+			p.next()
+			p.skipBlock()
+		case "struct", "union", "exception":
+			name := p.next()
+			p.skipAnnotations()
+			if p.peek() == "xsd_all" {
+				p.next()
+			}
+			var fields []thriftField
+			if fields, err = p.parseFields("{", "}"); err == nil {
+				p.doc.structs[p.define(thriftKindStruct, name)] = &thriftStruct{Name: name, Fields: fields}
+				p.skipAnnotations()
+			}
+		case "service":
+			err = p.parseService()
+		case ";", ",":
+		default:
+			err = fmt.Errorf("unexpected token %q", keyword)
+		}
+	}
+	return
+}
+
+func (p *thriftParser) parseEnum() (err error) {
+	name := p.next()
+	if err = p.expect("{"); err != nil {
+		return
+	}
+
+	values := map[string]int32{}
+	var value int64
+	for p.peek() != "}" {
+		if p.peek() == "" {
+			err = fmt.Errorf("unexpected end of the enum %s", name)
+			return
+		}
+		item := p.next()
+		if p.peek() == "=" {
+			p.next()
+			if value, err = strconv.ParseInt(p.next(), 0, 32); err != nil {
+				return
+			}
+		}
+		values[item] = int32(value)
+		value++
+		p.skipAnnotations()
+		p.skipSeparator()
+	}
+	p.next()
+	p.skipAnnotations()
+	p.doc.enums[p.define(thriftKindEnum, name)] = values
+	return
+}
+
+func (p *thriftParser) parseService() (err error) {
+	service := &thriftService{Name: p.next(), Methods: map[string]*thriftMethod{}, scope: p.scope}
+	if p.peek() == "extends" {
+		p.next()
+		service.Extends = p.next()
+	}
+	if err = p.expect("{"); err != nil {
+		return
+	}
+
+	for p.peek() != "}" {
+		if p.peek() == "" {
+			err = fmt.Errorf("unexpected end of the service %s", service.Name)
+			return
+		}
+
+		method := &thriftMethod{}
+		if p.peek() == "oneway" {
+			p.next()
+			method.Oneway = true
+		}
+		if p.peek() == "void" {
+			p.next()
+		} else if method.Return, err = p.parseType(); err != nil {
+			return
+		}
+		method.Name = p.next()
+		if method.Args, err = p.parseFields("(", ")"); err != nil {
+			return
+		}
+		if p.peek() == "throws" {
+			p.next()
+			if method.Throws, err = p.parseFields("(", ")"); err != nil {
+				return
+			}
+		}
+		p.skipAnnotations()
+		p.skipSeparator()
+		service.Methods[method.Name] = method
+	}
+	p.next()
+	p.skipAnnotations()
+	p.doc.services[p.define(thriftKindService, service.Name)] = service
+	return
+}
+
+// parseFields parses the fields between the open and close tokens, the implicit field ids are negative
+func (p *thriftParser) parseFields(open, close string) (fields []thriftField, err error) {
+	if err = p.expect(open); err != nil {
+		return
+	}
+
+	implicitID := int16(0)
+	for p.peek() != close {
+		if p.peek() == "" {
+			err = fmt.Errorf("expect %q, actual the end of file", close)
+			return
+		}
+
+		field := thriftField{}
+		if p.pos+1 < len(p.tokens) && p.tokens[p.pos+1] == ":" {
+			var id int64
+			if id, err = strconv.ParseInt(p.next(), 0, 16); err != nil {
+				return
+			}
+			field.ID = int16(id)
+			p.next()
+		} else {
+			implicitID--
+			field.ID = implicitID
+		}
+
+		switch p.peek() {
+		case "required":
+			field.Required = true
+			p.next()
+		case "optional":
+			p.next()
+		}
+		if field.Type, err = p.parseType(); err != nil {
+			return
+		}
+		field.Name = p.next()
+		if p.peek() == "=" {
+			p.next()
+			p.skipValue()
+		}
+		p.skipAnnotations()
+		p.skipSeparator()
+		fields = append(fields, field)
+	}
+	p.next()
+	return
+}
+
+func (p *thriftParser) parseType() (t *thriftType, err error) {
+	t = &thriftType{Name: p.next(), scope: p.scope}
+	switch t.Name {
+	case "list", "set", "map":
+		if p.peek() == "cpp_type" {
+			p.pos += 2
+		}
+		if err = p.expect("<"); err != nil {
+			return
+		}
+		if t.Name == "map" {
+			if t.Key, err = p.parseType(); err != nil {
+				return
+			}
+			if err = p.expect(","); err != nil {
+				return
+			}
+		}
+		if t.Elem, err = p.parseType(); err != nil {
+			return
+		}
+		err = p.expect(">")
+	case "", "void":
+		err = fmt.Errorf("expect a type, actual %q", t.Name)
+	}
+	p.skipAnnotations()
+	return
+}
+
+func (p *thriftParser) skipSeparator() {
+	if token := p.peek(); token == "," || token == ";" {
+		p.next()
+	}
+}
+
+func (p *thriftParser) skipAnnotations() {
+	if p.peek() == "(" {
+		p.skipBlock()
+	}
+}
+
+// skipValue skips the constant value, which is a literal, a list or a map
+func (p *thriftParser) skipValue() {
+	if token := p.peek(); token == "[" || token == "{" {
+		p.skipBlock()
+	} else {
+		p.next()
+	}
+}
+
+// skipBlock skips the tokens until the brackets are balanced
+func (p *thriftParser) skipBlock() {
+	depth := 0
+	for p.pos < len(p.tokens) {
+		switch p.next() {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			depth--
+		}
+		if depth <= 0 {
+			return
+		}
+	}
+}
+
+// tokenizeThrift splits the IDL into the identifiers, literals and symbols, the comments are ignored
+func tokenizeThrift(text string) (tokens []string) {
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case c == '#' || strings.HasPrefix(text[i:], "//"):
+			if end := strings.IndexByte(text[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(text)
+			}
+		case strings.HasPrefix(text[i:], "/*"):
+			if end := strings.Index(text[i+2:], "*/"); end >= 0 {
+				i += end + 4
+			} else {
+				i = len(text)
+			}
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(text) && text[end] != c {
+				if text[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end < len(text) {
+				end++
+			}
+			tokens = append(tokens, text[i:end])
+			i = end
+		case isThriftWordChar(c) || ((c == '-' || c == '+') && i+1 < len(text) && isThriftDigit(text[i+1])):
+			// the sign is part of the number, and the exponent of the number
+			number := !isThriftLetter(c)
+			end := i + 1
+			for end < len(text) && (isThriftWordChar(text[end]) ||
+				(number && (text[end] == '-' || text[end] == '+') && (text[end-1] == 'e' || text[end-1] == 'E'))) {
+				end++
+			}
+			tokens = append(tokens, text[i:end])
+			i = end
+		default:
+			tokens = append(tokens, string(c))
+			i++
+		}
+	}
+	return
+}
+
+func isThriftDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isThriftLetter(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isThriftWordChar(c byte) bool {
+	return c == '.' || isThriftDigit(c) || isThriftLetter(c)
+}
+
+func unquoteThrift(literal string) string {
+	if len(literal) >= 2 {
+		return literal[1 : len(literal)-1]
+	}
+	return literal
+}
@@ -0,0 +1,109 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseThriftFile(t *testing.T) {
+	doc, err := parseThriftFile("testdata/thrift/calculator.thrift")
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	assert.Equal(t, map[string]int32{"ADD": 1, "SUBTRACT": 2, "MULTIPLY": 3, "DIVIDE": 4}, doc.enums["Operation"])
+	assert.Equal(t, []thriftField{
+		{ID: 1, Name: "num1", Type: &thriftType{Name: "i32"}},
+		{ID: 2, Name: "num2", Type: &thriftType{Name: "i32"}},
+		{ID: 3, Name: "op", Type: &thriftType{Name: "Operation"}},
+		{ID: 4, Name: "comment", Type: &thriftType{Name: "string"}},
+	}, doc.structs["Work"].Fields)
+	assert.Contains(t, doc.structs, "shared.SharedStruct")
+
+	resolved, err := doc.underlying(&thriftType{Name: "MyInteger"})
+	assert.Nil(t, err)
+	assert.Equal(t, "i32", resolved.Name)
+
+	// the type of the included file is resolved in its scope
+	method, err := doc.findMethod("Calculator", "getStruct")
+	if assert.Nil(t, err) {
+		resolved, err = doc.underlying(method.Return)
+		assert.Nil(t, err)
+		assert.Equal(t, "shared.SharedStruct", resolved.Name)
+	}
+
+	method, err = doc.findMethod("", "calculate")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "i32", method.Return.Name)
+		assert.Equal(t, []string{"logid", "w"}, []string{method.Args[0].Name, method.Args[1].Name})
+		assert.Equal(t, "ouch", method.Throws[0].Name)
+	}
+
+	method, err = doc.findMethod("Calculator", "search")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "list", method.Return.Name)
+		assert.Equal(t, "map", method.Args[0].Type.Name)
+		assert.Equal(t, "MyInteger", method.Args[0].Type.Key.Name)
+		assert.Equal(t, "Operation", method.Args[1].Type.Elem.Name)
+	}
+
+	method, err = doc.findMethod("Calculator", "zip")
+	if assert.Nil(t, err) {
+		assert.True(t, method.Oneway)
+		assert.Nil(t, method.Return)
+	}
+
+	_, err = doc.findMethod("Calculator", "fake")
+	assert.NotNil(t, err)
+	_, err = doc.findMethod("fake", "add")
+	assert.NotNil(t, err)
+	_, err = doc.underlying(&thriftType{Name: "fake"})
+	assert.NotNil(t, err)
+}
+
+func TestParseInvalidThriftFile(t *testing.T) {
+	tests := []struct {
+		name string
+		idl  string
+	}{{
+		name: "unexpected token",
+		idl:  `fake Work {}`,
+	}, {
+		name: "unclosed struct",
+		idl:  `struct Work { 1: i32 num1`,
+	}, {
+		name: "invalid map type",
+		idl:  `typedef map<string> Filters`,
+	}, {
+		name: "invalid field id",
+		idl:  `struct Work { a: i32 num1 }`,
+	}, {
+		name: "missing included file",
+		idl:  `include "fake.thrift"`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "test.thrift")
+			if assert.Nil(t, os.WriteFile(file, []byte(tt.idl), 0644)) {
+				_, err := parseThriftFile(file)
+				assert.NotNil(t, err)
+			}
+		})
+	}
+
+	_, err := parseThriftFile("fake.thrift")
+	assert.NotNil(t, err)
+}
+
+func TestTokenizeThrift(t *testing.T) {
+	assert.Equal(t, []string{
+		"const", "double", "PI", "=", "-3.14e-2", "const", "string", "NAME", "=", `"a // b"`,
+		"struct", "shared.Work", "{", "}",
+	}, tokenizeThrift(`const double PI = -3.14e-2 // the comment
+# the comment
+const string NAME = "a // b" /* the
+comment */ struct shared.Work {}`))
+}
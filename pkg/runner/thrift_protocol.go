@@ -0,0 +1,827 @@
+package runner
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// the types on the wire of the Thrift protocol
+const (
+	thriftTypeStop   byte = 0
+	thriftTypeBool   byte = 2
+	thriftTypeByte   byte = 3
+	thriftTypeDouble byte = 4
+	thriftTypeI16    byte = 6
+	thriftTypeI32    byte = 8
+	thriftTypeI64    byte = 10
+	thriftTypeString byte = 11
+	thriftTypeStruct byte = 12
+	thriftTypeMap    byte = 13
+	thriftTypeSet    byte = 14
+	thriftTypeList   byte = 15
+)
+
+// the types of the Thrift message
+const (
+	thriftMessageCall      byte = 1
+	thriftMessageReply     byte = 2
+	thriftMessageException byte = 3
+	thriftMessageOneway    byte = 4
+)
+
+// thriftVersion1 is the version of the strict binary protocol
+const thriftVersion1 uint32 = 0x80010000
+
+// maxThriftSize is the max size of the string and containers, it avoids allocating too much memory for the bad data
+const maxThriftSize = 64 << 20
+
+var thriftWireTypes = map[string]byte{
+	"bool": thriftTypeBool, "byte": thriftTypeByte, "i8": thriftTypeByte, "i16": thriftTypeI16, "i32": thriftTypeI32, "i64": thriftTypeI64,
+	"double": thriftTypeDouble, "string": thriftTypeString, "binary": thriftTypeString,
+	"list": thriftTypeList, "set": thriftTypeSet, "map": thriftTypeMap,
+}
+
+// the types on the wire of the Thrift compact protocol, the bool field has the value in its type
+const (
+	thriftCompactTrue   byte = 1
+	thriftCompactFalse  byte = 2
+	thriftCompactByte   byte = 3
+	thriftCompactI16    byte = 4
+	thriftCompactI32    byte = 5
+	thriftCompactI64    byte = 6
+	thriftCompactDouble byte = 7
+	thriftCompactBinary byte = 8
+	thriftCompactList   byte = 9
+	thriftCompactSet    byte = 10
+	thriftCompactMap    byte = 11
+	thriftCompactStruct byte = 12
+)
+
+// the header of the message in the compact protocol
+const (
+	thriftCompactProtocolID byte = 0x82
+	thriftCompactVersion    byte = 1
+)
+
+var thriftCompactTypes = map[byte]byte{
+	thriftTypeBool: thriftCompactTrue, thriftTypeByte: thriftCompactByte, thriftTypeI16: thriftCompactI16,
+	thriftTypeI32: thriftCompactI32, thriftTypeI64: thriftCompactI64, thriftTypeDouble: thriftCompactDouble,
+	thriftTypeString: thriftCompactBinary, thriftTypeList: thriftCompactList, thriftTypeSet: thriftCompactSet,
+	thriftTypeMap: thriftCompactMap, thriftTypeStruct: thriftCompactStruct,
+}
+
+// thriftWriter encodes the message in the binary protocol, or the compact protocol
+type thriftWriter struct {
+	buf     []byte
+	compact bool
+
+	// the compact protocol writes the delta of the field ids, and the bool field with its value
+	lastField  int16
+	lastFields []int16
+	boolField  *int16
+}
+
+func (w *thriftWriter) messageBegin(name string, messageType byte, seqID int32) {
+	if w.compact {
+		w.byte(thriftCompactProtocolID)
+		w.byte(thriftCompactVersion | messageType<<5)
+		w.varint(uint64(uint32(seqID)))
+		w.binary([]byte(name))
+		return
+	}
+	w.i32(int32(thriftVersion1 | uint32(messageType)))
+	w.binary([]byte(name))
+	w.i32(seqID)
+}
+
+func (w *thriftWriter) structBegin() {
+	w.lastFields = append(w.lastFields, w.lastField)
+	w.lastField = 0
+}
+
+func (w *thriftWriter) fieldBegin(fieldType byte, id int16) {
+	if !w.compact {
+		w.byte(fieldType)
+		w.i16(id)
+		return
+	}
+
+	if fieldType == thriftTypeBool {
+		w.boolField = &id
+		return
+	}
+	w.compactFieldBegin(thriftCompactTypes[fieldType], id)
+}
+
+func (w *thriftWriter) compactFieldBegin(compactType byte, id int16) {
+	if delta := id - w.lastField; delta > 0 && delta <= 15 {
+		w.byte(byte(delta)<<4 | compactType)
+	} else {
+		w.byte(compactType)
+		w.i16(id)
+	}
+	w.lastField = id
+}
+
+func (w *thriftWriter) fieldStop() {
+	w.byte(thriftTypeStop)
+	if count := len(w.lastFields); count > 0 {
+		w.lastField = w.lastFields[count-1]
+		w.lastFields = w.lastFields[:count-1]
+	}
+}
+
+func (w *thriftWriter) listBegin(elemType byte, size int) {
+	if !w.compact {
+		w.byte(elemType)
+		w.i32(int32(size))
+		return
+	}
+
+	if size < 15 {
+		w.byte(byte(size)<<4 | thriftCompactTypes[elemType])
+	} else {
+		w.byte(0xf0 | thriftCompactTypes[elemType])
+		w.varint(uint64(size))
+	}
+}
+
+func (w *thriftWriter) mapBegin(keyType, valueType byte, size int) {
+	if !w.compact {
+		w.byte(keyType)
+		w.byte(valueType)
+		w.i32(int32(size))
+		return
+	}
+
+	w.varint(uint64(size))
+	if size > 0 {
+		w.byte(thriftCompactTypes[keyType]<<4 | thriftCompactTypes[valueType])
+	}
+}
+
+func (w *thriftWriter) bool(val bool) {
+	switch {
+	case w.compact && w.boolField != nil:
+		compactType := thriftCompactFalse
+		if val {
+			compactType = thriftCompactTrue
+		}
+		w.compactFieldBegin(compactType, *w.boolField)
+		w.boolField = nil
+	case w.compact && val:
+		w.byte(thriftCompactTrue)
+	case w.compact:
+		w.byte(thriftCompactFalse)
+	case val:
+		w.byte(1)
+	default:
+		w.byte(0)
+	}
+}
+
+func (w *thriftWriter) byte(val byte) {
+	w.buf = append(w.buf, val)
+}
+
+func (w *thriftWriter) i16(val int16) {
+	if w.compact {
+		w.zigzag(int64(val))
+		return
+	}
+	w.buf = append(w.buf, 0, 0)
+	binary.BigEndian.PutUint16(w.buf[len(w.buf)-2:], uint16(val))
+}
+
+func (w *thriftWriter) i32(val int32) {
+	if w.compact {
+		w.zigzag(int64(val))
+		return
+	}
+	w.buf = append(w.buf, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(w.buf[len(w.buf)-4:], uint32(val))
+}
+
+func (w *thriftWriter) i64(val int64) {
+	if w.compact {
+		w.zigzag(val)
+		return
+	}
+	w.buf = append(w.buf, make([]byte, 8)...)
+	binary.BigEndian.PutUint64(w.buf[len(w.buf)-8:], uint64(val))
+}
+
+func (w *thriftWriter) double(val float64) {
+	w.buf = append(w.buf, make([]byte, 8)...)
+	if w.compact {
+		binary.LittleEndian.PutUint64(w.buf[len(w.buf)-8:], math.Float64bits(val))
+	} else {
+		binary.BigEndian.PutUint64(w.buf[len(w.buf)-8:], math.Float64bits(val))
+	}
+}
+
+func (w *thriftWriter) binary(val []byte) {
+	if w.compact {
+		w.varint(uint64(len(val)))
+	} else {
+		w.i32(int32(len(val)))
+	}
+	w.buf = append(w.buf, val...)
+}
+
+func (w *thriftWriter) zigzag(val int64) {
+	w.varint(uint64(val<<1) ^ uint64(val>>63))
+}
+
+func (w *thriftWriter) varint(val uint64) {
+	data := make([]byte, binary.MaxVarintLen64)
+	w.buf = append(w.buf, data[:binary.PutUvarint(data, val)]...)
+}
+
+// thriftReader decodes the message in the binary protocol, or the compact protocol. The first error is kept
+type thriftReader struct {
+	reader  *bufio.Reader
+	compact bool
+	err     error
+
+	lastField  int16
+	lastFields []int16
+	boolValue  *bool
+}
+
+func newThriftReader(reader io.Reader, compact bool) *thriftReader {
+	return &thriftReader{reader: bufio.NewReader(reader), compact: compact}
+}
+
+func (r *thriftReader) next(size int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if size < 0 || size > maxThriftSize {
+		r.err = fmt.Errorf("invalid Thrift size %d", size)
+		return nil
+	}
+	data := make([]byte, size)
+	if _, r.err = io.ReadFull(r.reader, data); r.err != nil {
+		return nil
+	}
+	return data
+}
+
+// messageBegin reads the header of the message, the binary protocol must be the strict one
+func (r *thriftReader) messageBegin() (name string, messageType byte, seqID int32) {
+	if r.compact {
+		protocolID, version := r.byte(), r.byte()
+		if r.err == nil && (protocolID != thriftCompactProtocolID || version&0x1f != thriftCompactVersion) {
+			r.err = fmt.Errorf("unsupported Thrift compact protocol %x%x", protocolID, version)
+			return
+		}
+		messageType = version >> 5
+		seqID = int32(r.varint())
+		name = string(r.binary())
+		return
+	}
+
+	version := uint32(r.i32())
+	if r.err == nil && version&0xffff0000 != thriftVersion1 {
+		r.err = fmt.Errorf("unsupported Thrift protocol version %x", version)
+		return
+	}
+	messageType = byte(version)
+	name = string(r.binary())
+	seqID = r.i32()
+	return
+}
+
+func (r *thriftReader) structBegin() {
+	r.lastFields = append(r.lastFields, r.lastField)
+	r.lastField = 0
+}
+
+func (r *thriftReader) fieldBegin() (fieldType byte, id int16) {
+	if !r.compact {
+		if fieldType = r.byte(); fieldType != thriftTypeStop {
+			id = r.i16()
+		}
+		return
+	}
+
+	header := r.byte()
+	if header == thriftTypeStop {
+		fieldType = thriftTypeStop
+		if count := len(r.lastFields); count > 0 {
+			r.lastField = r.lastFields[count-1]
+			r.lastFields = r.lastFields[:count-1]
+		}
+		return
+	}
+	if delta := int16(header >> 4); delta > 0 {
+		id = r.lastField + delta
+	} else {
+		id = r.i16()
+	}
+	r.lastField = id
+
+	if compactType := header & 0x0f; compactType == thriftCompactTrue || compactType == thriftCompactFalse {
+		val := compactType == thriftCompactTrue
+		r.boolValue = &val
+	}
+	fieldType = r.wireType(header & 0x0f)
+	return
+}
+
+// wireType converts the type of the compact protocol to be the binary one
+func (r *thriftReader) wireType(compactType byte) byte {
+	if compactType == thriftCompactFalse {
+		return thriftTypeBool
+	}
+	for wireType, val := range thriftCompactTypes {
+		if val == compactType {
+			return wireType
+		}
+	}
+	if r.err == nil {
+		r.err = fmt.Errorf("unknown Thrift compact type %d", compactType)
+	}
+	return thriftTypeStop
+}
+
+func (r *thriftReader) listBegin() (elemType byte, size int) {
+	if !r.compact {
+		elemType = r.byte()
+		size = r.size()
+		return
+	}
+
+	header := r.byte()
+	elemType = r.wireType(header & 0x0f)
+	if size = int(header >> 4); size == 15 {
+		size = r.compactSize()
+	}
+	return
+}
+
+func (r *thriftReader) mapBegin() (keyType, valueType byte, size int) {
+	if !r.compact {
+		keyType = r.byte()
+		valueType = r.byte()
+		size = r.size()
+		return
+	}
+
+	if size = r.compactSize(); size > 0 {
+		header := r.byte()
+		keyType, valueType = r.wireType(header>>4), r.wireType(header&0x0f)
+	}
+	return
+}
+
+func (r *thriftReader) size() int {
+	size := int(r.i32())
+	if r.err == nil && (size < 0 || size > maxThriftSize) {
+		r.err = fmt.Errorf("invalid Thrift size %d", size)
+		return 0
+	}
+	return size
+}
+
+func (r *thriftReader) compactSize() int {
+	size := r.varint()
+	if r.err == nil && size > maxThriftSize {
+		r.err = fmt.Errorf("invalid Thrift size %d", size)
+		return 0
+	}
+	return int(size)
+}
+
+func (r *thriftReader) bool() bool {
+	if r.boolValue != nil {
+		val := *r.boolValue
+		r.boolValue = nil
+		return val
+	}
+	if r.compact {
+		return r.byte() == thriftCompactTrue
+	}
+	return r.byte() != 0
+}
+
+func (r *thriftReader) byte() byte {
+	if data := r.next(1); data != nil {
+		return data[0]
+	}
+	return thriftTypeStop
+}
+
+func (r *thriftReader) i16() int16 {
+	if r.compact {
+		return int16(r.zigzag())
+	}
+	if data := r.next(2); data != nil {
+		return int16(binary.BigEndian.Uint16(data))
+	}
+	return 0
+}
+
+func (r *thriftReader) i32() int32 {
+	if r.compact {
+		return int32(r.zigzag())
+	}
+	if data := r.next(4); data != nil {
+		return int32(binary.BigEndian.Uint32(data))
+	}
+	return 0
+}
+
+func (r *thriftReader) i64() int64 {
+	if r.compact {
+		return r.zigzag()
+	}
+	if data := r.next(8); data != nil {
+		return int64(binary.BigEndian.Uint64(data))
+	}
+	return 0
+}
+
+func (r *thriftReader) double() float64 {
+	data := r.next(8)
+	switch {
+	case data == nil:
+		return 0
+	case r.compact:
+		return math.Float64frombits(binary.LittleEndian.Uint64(data))
+	default:
+		return math.Float64frombits(binary.BigEndian.Uint64(data))
+	}
+}
+
+func (r *thriftReader) binary() []byte {
+	if r.compact {
+		return r.next(r.compactSize())
+	}
+	return r.next(int(r.i32()))
+}
+
+func (r *thriftReader) zigzag() int64 {
+	val := r.varint()
+	return int64(val>>1) ^ -int64(val&1)
+}
+
+func (r *thriftReader) varint() (val uint64) {
+	if r.err != nil {
+		return
+	}
+	val, r.err = binary.ReadUvarint(r.reader)
+	return
+}
+
+// skip skips the value of the type which is not defined in the IDL
+func (r *thriftReader) skip(fieldType byte) {
+	switch fieldType {
+	case thriftTypeBool:
+		r.bool()
+	case thriftTypeByte:
+		r.byte()
+	case thriftTypeI16:
+		r.i16()
+	case thriftTypeI32:
+		r.i32()
+	case thriftTypeI64:
+		r.i64()
+	case thriftTypeDouble:
+		r.double()
+	case thriftTypeString:
+		r.binary()
+	case thriftTypeStruct:
+		r.structBegin()
+		for r.err == nil {
+			fieldType, _ := r.fieldBegin()
+			if fieldType == thriftTypeStop {
+				break
+			}
+			r.skip(fieldType)
+		}
+	case thriftTypeMap:
+		keyType, valueType, size := r.mapBegin()
+		for i := 0; i < size && r.err == nil; i++ {
+			r.skip(keyType)
+			r.skip(valueType)
+		}
+	case thriftTypeList, thriftTypeSet:
+		elemType, size := r.listBegin()
+		for i := 0; i < size && r.err == nil; i++ {
+			r.skip(elemType)
+		}
+	default:
+		if r.err == nil {
+			r.err = fmt.Errorf("unknown Thrift type %d", fieldType)
+		}
+	}
+}
+
+// thriftCodec converts the JSON values to the Thrift values according to the IDL, and vice versa
+type thriftCodec struct {
+	doc *thriftDocument
+}
+
+func (c *thriftCodec) wireType(t *thriftType) (wireType byte, err error) {
+	if t, err = c.doc.underlying(t); err != nil {
+		return
+	}
+
+	var ok bool
+	if wireType, ok = thriftWireTypes[t.Name]; !ok {
+		if _, isEnum := c.doc.enums[t.Name]; isEnum {
+			wireType = thriftTypeI32
+		} else {
+			wireType = thriftTypeStruct
+		}
+	}
+	return
+}
+
+// encodeStruct writes the fields of the object, the unknown fields are not allowed
+func (c *thriftCodec) encodeStruct(w *thriftWriter, fields []thriftField, value interface{}) (err error) {
+	object, ok := value.(map[string]interface{})
+	if !ok && value != nil {
+		err = fmt.Errorf("expect an object, actual %v", value)
+		return
+	}
+
+	w.structBegin()
+	known := map[string]bool{}
+	for _, field := range fields {
+		known[field.Name] = true
+		fieldValue, ok := object[field.Name]
+		if !ok || fieldValue == nil {
+			if field.Required {
+				err = fmt.Errorf("missing the required field %q", field.Name)
+				return
+			}
+			continue
+		}
+
+		var wireType byte
+		if wireType, err = c.wireType(field.Type); err != nil {
+			return
+		}
+		w.fieldBegin(wireType, field.ID)
+		if err = c.encode(w, field.Type, fieldValue); err != nil {
+			err = fmt.Errorf("invalid field %q, %v", field.Name, err)
+			return
+		}
+	}
+	w.fieldStop()
+
+	for name := range object {
+		if !known[name] {
+			err = fmt.Errorf("unknown field %q", name)
+			return
+		}
+	}
+	return
+}
+
+func (c *thriftCodec) encode(w *thriftWriter, t *thriftType, value interface{}) (err error) {
+	if t, err = c.doc.underlying(t); err != nil {
+		return
+	}
+
+	switch t.Name {
+	case "bool":
+		val, ok := value.(bool)
+		if !ok {
+			err = fmt.Errorf("expect a bool, actual %v", value)
+		}
+		w.bool(val)
+	case "byte", "i8", "i16", "i32", "i64":
+		var val int64
+		if val, err = thriftInt(value); err != nil {
+			return
+		}
+		switch t.Name {
+		case "i16":
+			w.i16(int16(val))
+		case "i32":
+			w.i32(int32(val))
+		case "i64":
+			w.i64(val)
+		default:
+			w.byte(byte(val))
+		}
+	case "double":
+		var val float64
+		if val, err = strconv.ParseFloat(fmt.Sprintf("%v", value), 64); err == nil {
+			w.double(val)
+		}
+	case "string":
+		w.binary([]byte(fmt.Sprintf("%v", value)))
+	case "binary":
+		// the binary is in base64 like the JSON protocol of Thrift
+		var val []byte
+		if val, err = base64.StdEncoding.DecodeString(fmt.Sprintf("%v", value)); err == nil {
+			w.binary(val)
+		}
+	case "list", "set":
+		items, ok := value.([]interface{})
+		if !ok {
+			err = fmt.Errorf("expect an array, actual %v", value)
+			return
+		}
+		var elemType byte
+		if elemType, err = c.wireType(t.Elem); err != nil {
+			return
+		}
+		w.listBegin(elemType, len(items))
+		for _, item := range items {
+			if err = c.encode(w, t.Elem, item); err != nil {
+				return
+			}
+		}
+	case "map":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			err = fmt.Errorf("expect an object, actual %v", value)
+			return
+		}
+		var keyType, valueType byte
+		if keyType, err = c.wireType(t.Key); err != nil {
+			return
+		}
+		if valueType, err = c.wireType(t.Elem); err != nil {
+			return
+		}
+
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		w.mapBegin(keyType, valueType, len(keys))
+		for _, key := range keys {
+			// the keys of a JSON object are strings, they are converted to the key type
+			var keyValue interface{} = key
+			if keyType == thriftTypeBool {
+				if keyValue, err = strconv.ParseBool(key); err != nil {
+					return
+				}
+			}
+			if err = c.encode(w, t.Key, keyValue); err != nil {
+				return
+			}
+			if err = c.encode(w, t.Elem, object[key]); err != nil {
+				return
+			}
+		}
+	default:
+		if values, isEnum := c.doc.enums[t.Name]; isEnum {
+			// the enum is the name or the number
+			val, isName := values[fmt.Sprintf("%v", value)]
+			if !isName {
+				var number int64
+				if number, err = thriftInt(value); err != nil {
+					err = fmt.Errorf("unknown value %v of the enum %s", value, t.Name)
+					return
+				}
+				val = int32(number)
+			}
+			w.i32(val)
+			return
+		}
+		err = c.encodeStruct(w, c.doc.structs[t.Name].Fields, value)
+	}
+	return
+}
+
+// decodeStruct reads the fields as an object, the unknown fields are skipped
+func (c *thriftCodec) decodeStruct(r *thriftReader, fields []thriftField) (object map[string]interface{}, err error) {
+	object = map[string]interface{}{}
+	r.structBegin()
+	for {
+		fieldType, id := r.fieldBegin()
+		if r.err != nil {
+			err = r.err
+			return
+		}
+		if fieldType == thriftTypeStop {
+			return
+		}
+
+		var field *thriftField
+		for i := range fields {
+			if fields[i].ID == id {
+				field = &fields[i]
+				break
+			}
+		}
+
+		var expectType byte
+		if field != nil {
+			if expectType, err = c.wireType(field.Type); err != nil {
+				return
+			}
+		}
+		if field == nil || expectType != fieldType {
+			r.skip(fieldType)
+			continue
+		}
+		if object[field.Name], err = c.decode(r, field.Type); err != nil {
+			return
+		}
+	}
+}
+
+func (c *thriftCodec) decode(r *thriftReader, t *thriftType) (value interface{}, err error) {
+	if t, err = c.doc.underlying(t); err != nil {
+		return
+	}
+
+	switch t.Name {
+	case "bool":
+		value = r.bool()
+	case "byte", "i8":
+		value = int8(r.byte())
+	case "i16":
+		value = r.i16()
+	case "i32":
+		value = r.i32()
+	case "i64":
+		value = r.i64()
+	case "double":
+		value = r.double()
+	case "string":
+		value = string(r.binary())
+	case "binary":
+		value = base64.StdEncoding.EncodeToString(r.binary())
+	case "list", "set":
+		_, size := r.listBegin()
+		items := make([]interface{}, 0, size)
+		for i := 0; i < size && r.err == nil; i++ {
+			var item interface{}
+			if item, err = c.decode(r, t.Elem); err != nil {
+				return
+			}
+			items = append(items, item)
+		}
+		value = items
+	case "map":
+		_, _, size := r.mapBegin()
+		object := make(map[string]interface{}, size)
+		for i := 0; i < size && r.err == nil; i++ {
+			var key, val interface{}
+			if key, err = c.decode(r, t.Key); err != nil {
+				return
+			}
+			if val, err = c.decode(r, t.Elem); err != nil {
+				return
+			}
+			object[fmt.Sprintf("%v", key)] = val
+		}
+		value = object
+	default:
+		if values, isEnum := c.doc.enums[t.Name]; isEnum {
+			number := r.i32()
+			value = number
+			for name, val := range values {
+				if val == number {
+					value = name
+					break
+				}
+			}
+		} else {
+			value, err = c.decodeStruct(r, c.doc.structs[t.Name].Fields)
+		}
+	}
+	if err == nil {
+		err = r.err
+	}
+	return
+}
+
+// thriftInt converts the JSON number, or the number in string to be an integer
+func thriftInt(value interface{}) (val int64, err error) {
+	switch number := value.(type) {
+	case json.Number:
+		val, err = number.Int64()
+	case float64:
+		val = int64(number)
+		if float64(val) != number {
+			err = fmt.Errorf("expect an integer, actual %v", number)
+		}
+	case int:
+		val = int64(number)
+	case string:
+		val, err = strconv.ParseInt(number, 10, 64)
+	default:
+		err = fmt.Errorf("expect an integer, actual %v", value)
+	}
+	return
+}
@@ -0,0 +1,117 @@
+package runner
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThriftCodec(t *testing.T) {
+	idl := filepath.Join(t.TempDir(), "sample.thrift")
+	err := os.WriteFile(idl, []byte(`
+struct Inner {
+  1: bool flag
+}
+
+struct Sample {
+  1: bool yes,
+  2: bool no,
+  3: double ratio,
+  4: i64 big,
+  20: i16 far,
+  21: list<bool> flags,
+  22: list<i32> numbers,
+  23: map<string, i32> empty,
+  24: Inner inner,
+  25: binary data,
+  26: i8 tiny,
+}`), 0644)
+	if !assert.Nil(t, err) {
+		return
+	}
+	doc, err := parseThriftFile(idl)
+	if !assert.Nil(t, err) {
+		return
+	}
+	codec := &thriftCodec{doc: doc}
+	fields := doc.structs["Sample"].Fields
+
+	numbers := []interface{}{}
+	decodedNumbers := []interface{}{}
+	for i := -8; i < 8; i++ {
+		numbers = append(numbers, i*1000)
+		decodedNumbers = append(decodedNumbers, int32(i*1000))
+	}
+
+	for _, compact := range []bool{false, true} {
+		writer := &thriftWriter{compact: compact}
+		err = codec.encodeStruct(writer, fields, map[string]interface{}{
+			"yes":     true,
+			"no":      false,
+			"ratio":   1.5,
+			"big":     -1 << 40,
+			"far":     300,
+			"flags":   []interface{}{true, false},
+			"numbers": numbers,
+			"empty":   map[string]interface{}{},
+			"inner":   map[string]interface{}{"flag": true},
+			"data":    "AQI=",
+			"tiny":    -1,
+		})
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		object, err := codec.decodeStruct(newThriftReader(bytes.NewReader(writer.buf), compact), fields)
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"yes":     true,
+			"no":      false,
+			"ratio":   1.5,
+			"big":     int64(-1 << 40),
+			"far":     int16(300),
+			"flags":   []interface{}{true, false},
+			"numbers": decodedNumbers,
+			"empty":   map[string]interface{}{},
+			"inner":   map[string]interface{}{"flag": true},
+			"data":    "AQI=",
+			"tiny":    int8(-1),
+		}, object, "compact: %v", compact)
+
+		// the unknown fields are skipped
+		object, err = codec.decodeStruct(newThriftReader(bytes.NewReader(writer.buf), compact), fields[4:5])
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]interface{}{"far": int16(300)}, object, "compact: %v", compact)
+	}
+}
+
+func TestThriftCompactMessage(t *testing.T) {
+	doc, err := parseThriftFile("testdata/thrift/calculator.thrift")
+	if !assert.Nil(t, err) {
+		return
+	}
+	method, err := doc.findMethod("", "add")
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	writer := &thriftWriter{compact: true}
+	writer.messageBegin("add", thriftMessageCall, thriftSeqID)
+	err = (&thriftCodec{doc: doc}).encodeStruct(writer, method.Args, map[string]interface{}{"num1": 1, "num2": -2})
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x82, 0x21, 0x01, 0x03, 'a', 'd', 'd', 0x15, 0x02, 0x15, 0x03, 0x00}, writer.buf)
+
+	reader := newThriftReader(bytes.NewReader(writer.buf), true)
+	name, messageType, seqID := reader.messageBegin()
+	assert.Nil(t, reader.err)
+	assert.Equal(t, "add", name)
+	assert.Equal(t, thriftMessageCall, messageType)
+	assert.Equal(t, thriftSeqID, seqID)
+
+	reader = newThriftReader(bytes.NewReader([]byte{0x80, 0x01}), true)
+	reader.messageBegin()
+	assert.NotNil(t, reader.err)
+}
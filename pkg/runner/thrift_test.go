@@ -0,0 +1,408 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThriftTestCaseRunner(t *testing.T) {
+	const idl = "testdata/thrift/calculator.thrift"
+	doc, err := parseThriftFile(idl)
+	if !assert.Nil(t, err) {
+		return
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer listener.Close()
+
+	oneway := make(chan string, 1)
+	go serveFakeThrift(listener, &thriftCodec{doc: doc}, oneway)
+	api := listener.Addr().String()
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/error" || r.Header.Get("Content-Type") != thriftContentType {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", thriftContentType)
+		_, _ = w.Write(handleFakeThrift(r.Body, &thriftCodec{doc: doc}, oneway))
+	}))
+	defer httpServer.Close()
+
+	tests := []struct {
+		name     string
+		testCase *atest.TestCase
+		verify   func(t *testing.T, output interface{}, err error)
+	}{{
+		name: "scalar result",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:    api,
+				Body:   `{"num1": {{.num}}, "num2": 2}`,
+				Thrift: &atest.ThriftRequest{IDL: idl, Method: "add"},
+			},
+			Expect: atest.Response{
+				Body: "3",
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+			assert.Equal(t, float64(3), output)
+		},
+	}, {
+		name: "struct argument in the framed transport",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:    api,
+				Body:   `{"logid": 1, "w": {"num1": 6, "num2": 3, "op": "DIVIDE"}}`,
+				Thrift: &atest.ThriftRequest{IDL: idl, Service: "Calculator", Method: "calculate", Transport: "framed"},
+			},
+			Expect: atest.Response{
+				Verify: []string{"data == 2"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name: "compact protocol in the framed transport",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:    api,
+				Body:   `{"logid": 1, "w": {"num1": 6, "num2": 3, "op": "DIVIDE", "comment": "compact"}}`,
+				Thrift: &atest.ThriftRequest{IDL: idl, Method: "calculate", Transport: "framed", Protocol: "compact"},
+			},
+			Expect: atest.Response{
+				Body: "2",
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name: "compact protocol with the containers",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:    api,
+				Body:   `{"filters": {"1": "a", "2": "b"}, "ops": ["ADD", "DIVIDE"]}`,
+				Thrift: &atest.ThriftRequest{IDL: idl, Method: "search", Protocol: "compact"},
+			},
+			Expect: atest.Response{
+				Verify: []string{"len(data) == 2", `data[1].value == "b"`},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name: "over HTTP",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:    httpServer.URL,
+				Body:   `{"num1": 1, "num2": 2}`,
+				Thrift: &atest.ThriftRequest{IDL: idl, Method: "add"},
+			},
+			Expect: atest.Response{
+				Body: "3",
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name: "compact protocol over HTTP",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:    httpServer.URL,
+				Body:   `{"key": 1}`,
+				Thrift: &atest.ThriftRequest{IDL: idl, Method: "getStruct", Protocol: "compact"},
+			},
+			Expect: atest.Response{
+				BodyFieldsExpect: map[string]interface{}{"key": 1, "value": "shared"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name: "unexpected HTTP status",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:    httpServer.URL + "/error",
+				Thrift: &atest.ThriftRequest{IDL: idl, Method: "ping"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), "unexpected status code 500")
+			}
+		},
+	}, {
+		name: "declared exception",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:    api,
+				Body:   `{"logid": 1, "w": {"num1": 6, "num2": 0, "op": 4}}`,
+				Thrift: &atest.ThriftRequest{IDL: idl, Method: "calculate"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), `got Thrift exception InvalidOperation: {"whatOp":4,"why":"Cannot divide by 0"}`)
+			}
+		},
+	}, {
+		name: "struct result of the extended service",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:    api,
+				Body:   `{"key": 1}`,
+				Thrift: &atest.ThriftRequest{IDL: idl, Method: "getStruct"},
+			},
+			Expect: atest.Response{
+				BodyFieldsExpect: map[string]interface{}{"key": 1, "value": "shared"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name: "application exception",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:    api,
+				Body:   `{"key": -1}`,
+				Thrift: &atest.ThriftRequest{IDL: idl, Method: "getStruct"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), "got Thrift application exception: internal error")
+			}
+		},
+	}, {
+		name: "containers",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:    api,
+				Body:   `{"filters": {"1": "a", "2": "b"}, "ops": ["ADD"]}`,
+				Thrift: &atest.ThriftRequest{IDL: idl, Method: "search"},
+			},
+			Expect: atest.Response{
+				Verify: []string{"len(data) == 2", `data[1].value == "b"`},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name: "void method",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:    api,
+				Thrift: &atest.ThriftRequest{IDL: idl, Method: "ping"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name: "oneway method",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:    api,
+				Thrift: &atest.ThriftRequest{IDL: idl, Method: "zip"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+			assert.Nil(t, output)
+			select {
+			case name := <-oneway:
+				assert.Equal(t, "zip", name)
+			case <-time.After(time.Second):
+				t.Error("the oneway call is not received")
+			}
+		},
+	}, {
+		name: "unknown argument",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:    api,
+				Body:   `{"num3": 1}`,
+				Thrift: &atest.ThriftRequest{IDL: idl, Method: "add"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), `unknown field "num3"`)
+			}
+		},
+	}, {
+		name: "invalid argument",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:    api,
+				Body:   `{"logid": 1, "w": {"op": "POWER"}}`,
+				Thrift: &atest.ThriftRequest{IDL: idl, Method: "calculate"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "invalid body",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:    api,
+				Body:   `fake`,
+				Thrift: &atest.ThriftRequest{IDL: idl, Method: "add"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "unknown method",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:    api,
+				Thrift: &atest.ThriftRequest{IDL: idl, Method: "fake"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "invalid timeout",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:    api,
+				Thrift: &atest.ThriftRequest{IDL: idl, Method: "ping", Timeout: "fake"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thriftRunner := GetTestCaseRunner(tt.testCase)
+			output, err := thriftRunner.RunTestCase(tt.testCase, map[string]interface{}{"num": 1}, context.TODO())
+			tt.verify(t, output, err)
+		})
+	}
+}
+
+// serveFakeThrift serves the calculator, the framed transport is detected by the first byte
+func serveFakeThrift(listener net.Listener, codec *thriftCodec, oneway chan<- string) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go func(conn net.Conn) {
+			defer conn.Close()
+
+			var input io.Reader = bufio.NewReader(conn)
+			header, _ := input.(*bufio.Reader).Peek(1)
+			framed := len(header) > 0 && header[0] == 0
+			if framed {
+				data, _ := readFrame(input)
+				input = bytes.NewReader(data)
+			}
+
+			message := handleFakeThrift(input, codec, oneway)
+			if framed && message != nil {
+				message = append(make([]byte, 4), message...)
+				binary.BigEndian.PutUint32(message, uint32(len(message)-4))
+			}
+			_, _ = conn.Write(message)
+		}(conn)
+	}
+}
+
+// handleFakeThrift implements the calculator, the compact protocol is detected by the first byte
+func handleFakeThrift(input io.Reader, codec *thriftCodec, oneway chan<- string) []byte {
+	bufReader := bufio.NewReader(input)
+	header, _ := bufReader.Peek(1)
+	compact := len(header) > 0 && header[0] == thriftCompactProtocolID
+
+	reader := newThriftReader(bufReader, compact)
+	name, messageType, seqID := reader.messageBegin()
+	method, err := codec.doc.findMethod("Calculator", name)
+	if err != nil {
+		return nil
+	}
+	args, _ := codec.decodeStruct(reader, method.Args)
+	if messageType == thriftMessageOneway {
+		oneway <- name
+		return nil
+	}
+
+	fields := method.Throws
+	if method.Return != nil {
+		fields = append([]thriftField{{ID: 0, Name: "success", Type: method.Return}}, fields...)
+	}
+	writer := &thriftWriter{compact: compact}
+	writer.messageBegin(name, thriftMessageReply, seqID)
+	switch name {
+	case "add":
+		_ = codec.encodeStruct(writer, fields, map[string]interface{}{
+			"success": int(args["num1"].(int32) + args["num2"].(int32)),
+		})
+	case "calculate":
+		work := args["w"].(map[string]interface{})
+		if work["num2"].(int32) == 0 {
+			_ = codec.encodeStruct(writer, fields, map[string]interface{}{
+				"ouch": map[string]interface{}{"whatOp": 4, "why": "Cannot divide by 0"},
+			})
+		} else {
+			_ = codec.encodeStruct(writer, fields, map[string]interface{}{
+				"success": int(work["num1"].(int32) / work["num2"].(int32)),
+			})
+		}
+	case "getStruct":
+		if key := args["key"].(int32); key < 0 {
+			writer = &thriftWriter{compact: compact}
+			writer.messageBegin(name, thriftMessageException, seqID)
+			_ = codec.encodeStruct(writer, thriftApplicationException, map[string]interface{}{
+				"message": "internal error", "type": 6,
+			})
+		} else {
+			_ = codec.encodeStruct(writer, fields, map[string]interface{}{
+				"success": map[string]interface{}{"key": int(key), "value": "shared"},
+			})
+		}
+	case "search":
+		filters := args["filters"].(map[string]interface{})
+		keys := make([]string, 0, len(filters))
+		for key := range filters {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		items := []interface{}{}
+		for _, key := range keys {
+			items = append(items, map[string]interface{}{"key": key, "value": filters[key]})
+		}
+		_ = codec.encodeStruct(writer, fields, map[string]interface{}{"success": items})
+	default:
+		writer.fieldStop()
+	}
+	return writer.buf
+}
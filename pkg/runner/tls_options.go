@@ -0,0 +1,95 @@
+package runner
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	"golang.org/x/net/http2"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+var tlsCurves = map[string]tls.CurveID{
+	"X25519": tls.X25519,
+	"P256":   tls.CurveP256,
+	"P384":   tls.CurveP384,
+	"P521":   tls.CurveP521,
+}
+
+// applyTLSOptions sets the versions, cipher suites and curves to the TLS config of the transport, the other
+// settings of it are kept, e.g. the client certificate of SPIFFE
+func applyTLSOptions(transport http.RoundTripper, options *testing.TLSOptions) (err error) {
+	var config *tls.Config
+	switch t := transport.(type) {
+	case *http.Transport:
+		config = t.TLSClientConfig
+	case *http2.Transport:
+		config = t.TLSClientConfig
+	default:
+		err = fmt.Errorf("the TLS options are not supported by the transport %T", transport)
+		return
+	}
+	if config == nil {
+		config = &tls.Config{InsecureSkipVerify: true}
+	} else {
+		config = config.Clone()
+	}
+
+	if config.MinVersion, err = parseTLSVersion(options.MinVersion); err != nil {
+		return
+	}
+	if config.MaxVersion, err = parseTLSVersion(options.MaxVersion); err != nil {
+		return
+	}
+
+	config.CipherSuites = nil
+	for _, name := range options.CipherSuites {
+		id, ok := cipherSuiteID(name)
+		if !ok {
+			err = fmt.Errorf("unknown TLS cipher suite %q", name)
+			return
+		}
+		config.CipherSuites = append(config.CipherSuites, id)
+	}
+
+	config.CurvePreferences = nil
+	for _, name := range options.Curves {
+		curve, ok := tlsCurves[name]
+		if !ok {
+			err = fmt.Errorf("unknown TLS curve %q, supported: X25519, P256, P384, P521", name)
+			return
+		}
+		config.CurvePreferences = append(config.CurvePreferences, curve)
+	}
+
+	setTLSConfig(transport, config)
+	return
+}
+
+func parseTLSVersion(version string) (id uint16, err error) {
+	if version == "" {
+		return
+	}
+	var ok bool
+	if id, ok = tlsVersions[version]; !ok {
+		err = fmt.Errorf("unknown TLS version %q, supported: 1.0, 1.1, 1.2, 1.3", version)
+	}
+	return
+}
+
+// cipherSuiteID finds the cipher suite by the name, the insecure ones are included for testing the servers
+func cipherSuiteID(name string) (uint16, bool) {
+	for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		if suite.Name == name {
+			return suite.ID, true
+		}
+	}
+	return 0, false
+}
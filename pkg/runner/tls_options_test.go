@@ -0,0 +1,84 @@
+package runner
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientFingerprint(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"userAgent": r.UserAgent(),
+			"version":   r.TLS.Version,
+			"cipher":    tls.CipherSuiteName(r.TLS.CipherSuite),
+		})
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		header    map[string]string
+		transport *atest.Transport
+		expect    map[string]interface{}
+		expectErr string
+	}{{
+		name:      "default User-Agent",
+		transport: &atest.Transport{UserAgent: "Mozilla/5.0 (compatible; Googlebot/2.1)"},
+		expect:    map[string]interface{}{"userAgent": "Mozilla/5.0 (compatible; Googlebot/2.1)"},
+	}, {
+		name:      "the header takes precedence",
+		header:    map[string]string{"User-Agent": "curl/8.0.1"},
+		transport: &atest.Transport{UserAgent: "Mozilla/5.0 (compatible; Googlebot/2.1)"},
+		expect:    map[string]interface{}{"userAgent": "curl/8.0.1"},
+	}, {
+		name: "TLS options",
+		transport: &atest.Transport{TLS: &atest.TLSOptions{
+			MinVersion:   "1.2",
+			MaxVersion:   "1.2",
+			CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+			Curves:       []string{"P256", "X25519"},
+		}},
+		expect: map[string]interface{}{"version": tls.VersionTLS12, "cipher": "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+	}, {
+		name:      "TLS 1.3",
+		transport: &atest.Transport{TLS: &atest.TLSOptions{MinVersion: "1.3"}},
+		expect:    map[string]interface{}{"version": tls.VersionTLS13},
+	}, {
+		name:      "unknown version",
+		transport: &atest.Transport{TLS: &atest.TLSOptions{MaxVersion: "2.0"}},
+		expectErr: `unknown TLS version "2.0", supported: 1.0, 1.1, 1.2, 1.3`,
+	}, {
+		name:      "unknown cipher suite",
+		transport: &atest.Transport{TLS: &atest.TLSOptions{CipherSuites: []string{"fake"}}},
+		expectErr: `unknown TLS cipher suite "fake"`,
+	}, {
+		name:      "unknown curve",
+		transport: &atest.Transport{TLS: &atest.TLSOptions{Curves: []string{"fake"}}},
+		expectErr: `unknown TLS curve "fake", supported: X25519, P256, P384, P521`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSimpleTestCaseRunner().RunTestCase(&atest.TestCase{
+				Name: "fingerprint",
+				Request: atest.Request{
+					API:       server.URL,
+					Header:    tt.header,
+					Transport: tt.transport,
+				},
+				Expect: atest.Response{BodyFieldsExpect: tt.expect},
+			}, nil, context.TODO())
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expectErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// chromeTraceEvent is an event of the Chrome trace format, it could be viewed in Perfetto or chrome://tracing
+// see also https://docs.google.com/document/d/1CvAClvFfyA5R-PhYUmn5OOQtYMH4h6I0nSsKchNAySU
+type chromeTraceEvent struct {
+	Name      string                 `json:"name"`
+	Category  string                 `json:"cat,omitempty"`
+	Phase     string                 `json:"ph"`
+	Timestamp int64                  `json:"ts"`
+	Duration  int64                  `json:"dur,omitempty"`
+	PID       int                    `json:"pid"`
+	TID       int                    `json:"tid"`
+	Args      map[string]interface{} `json:"args,omitempty"`
+}
+
+type chromeTrace struct {
+	TraceEvents     []chromeTraceEvent `json:"traceEvents"`
+	DisplayTimeUnit string             `json:"displayTimeUnit"`
+}
+
+// WriteChromeTrace writes the records as a timeline of the Chrome trace format,
+// the overlapped records are put into different lanes to show the concurrency
+func WriteChromeTrace(writer io.Writer, records []*ReportRecord) (err error) {
+	sorted := make([]*ReportRecord, len(records))
+	copy(sorted, records)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].BeginTime.Before(sorted[j].BeginTime)
+	})
+
+	trace := chromeTrace{
+		TraceEvents:     []chromeTraceEvent{},
+		DisplayTimeUnit: "ms",
+	}
+
+	var start time.Time
+	if len(sorted) > 0 {
+		start = sorted[0].BeginTime
+	}
+
+	// the end time of the last record in each lane
+	var lanes []time.Time
+	for _, record := range sorted {
+		lane := -1
+		for i, end := range lanes {
+			if !end.After(record.BeginTime) {
+				lane = i
+				break
+			}
+		}
+		if lane < 0 {
+			lane = len(lanes)
+			lanes = append(lanes, record.EndTime)
+			trace.TraceEvents = append(trace.TraceEvents, chromeTraceEvent{
+				Name:  "thread_name",
+				Phase: "M",
+				PID:   1,
+				TID:   lane,
+				Args:  map[string]interface{}{"name": fmt.Sprintf("lane-%d", lane)},
+			})
+		} else {
+			lanes[lane] = record.EndTime
+		}
+
+		args := map[string]interface{}{
+			"method": record.Method,
+			"api":    record.API,
+		}
+		if record.Protocol != "" {
+			args["protocol"] = record.Protocol
+		}
+		if record.Error != nil {
+			args["error"] = record.Error.Error()
+		}
+
+		name := record.Name
+		if name == "" {
+			name = record.Method + " " + record.API
+		}
+		trace.TraceEvents = append(trace.TraceEvents, chromeTraceEvent{
+			Name:      name,
+			Category:  "testcase",
+			Phase:     "X",
+			Timestamp: record.BeginTime.Sub(start).Microseconds(),
+			Duration:  record.Duration().Microseconds(),
+			PID:       1,
+			TID:       lane,
+			Args:      args,
+		})
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(trace)
+	return
+}
@@ -0,0 +1,56 @@
+package runner_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/linuxsuren/api-testing/pkg/runner"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteChromeTrace(t *testing.T) {
+	now := time.Now()
+	buf := new(bytes.Buffer)
+	err := runner.WriteChromeTrace(buf, []*runner.ReportRecord{{
+		Name:      "second",
+		Method:    http.MethodGet,
+		API:       urlBar,
+		BeginTime: now.Add(time.Millisecond),
+		EndTime:   now.Add(3 * time.Millisecond),
+		Error:     errors.New("fake"),
+	}, {
+		Method:    http.MethodGet,
+		API:       urlFoo,
+		BeginTime: now,
+		EndTime:   now.Add(2 * time.Millisecond),
+	}, {
+		Name:      "third",
+		Method:    http.MethodPost,
+		API:       urlFoo,
+		Protocol:  "HTTP/2.0",
+		BeginTime: now.Add(2 * time.Millisecond),
+		EndTime:   now.Add(4 * time.Millisecond),
+	}})
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{
+  "displayTimeUnit": "ms",
+  "traceEvents": [
+    {"name": "thread_name", "ph": "M", "ts": 0, "pid": 1, "tid": 0, "args": {"name": "lane-0"}},
+    {"name": "GET http://foo", "cat": "testcase", "ph": "X", "ts": 0, "dur": 2000, "pid": 1, "tid": 0,
+      "args": {"method": "GET", "api": "http://foo"}},
+    {"name": "thread_name", "ph": "M", "ts": 0, "pid": 1, "tid": 1, "args": {"name": "lane-1"}},
+    {"name": "second", "cat": "testcase", "ph": "X", "ts": 1000, "dur": 2000, "pid": 1, "tid": 1,
+      "args": {"method": "GET", "api": "http://bar", "error": "fake"}},
+    {"name": "third", "cat": "testcase", "ph": "X", "ts": 2000, "dur": 2000, "pid": 1, "tid": 0,
+      "args": {"method": "POST", "api": "http://foo", "protocol": "HTTP/2.0"}}
+  ]
+}`, buf.String())
+
+	buf.Reset()
+	err = runner.WriteChromeTrace(buf, nil)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"displayTimeUnit": "ms", "traceEvents": []}`, buf.String())
+}
@@ -0,0 +1,83 @@
+package runner
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/http2"
+)
+
+// the protocols of the HTTP transport
+const (
+	protocolH2  = "h2"
+	protocolH2C = "h2c"
+)
+
+// dialContextFunc dials the connection of the HTTP transport
+type dialContextFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// newTransport creates a HTTP/1.1 transport, the default dialer is used if dial is nil
+func newTransport(dial dialContextFunc) *http.Transport {
+	return &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		DialContext:     dial,
+	}
+}
+
+// newProtocolTransport creates a HTTP transport which only speaks the protocol. The cleartext
+// connections are dialed by dial, the default dialer is used if it is nil
+func newProtocolTransport(protocol string, dial dialContextFunc) (transport http.RoundTripper, err error) {
+	if dial == nil {
+		var dialer net.Dialer
+		dial = dialer.DialContext
+	}
+
+	switch protocol {
+	case protocolH2:
+		// it fails if the server does not negotiate h2 by ALPN
+		transport = &http2.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}
+	case protocolH2C:
+		transport = &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				return dial(ctx, network, addr)
+			},
+		}
+	default:
+		err = fmt.Errorf("unsupported protocol %q, only h2 and h2c are supported", protocol)
+	}
+	return
+}
+
+// setTLSConfig replaces the TLS config of the transport, the unknown transports are ignored
+func setTLSConfig(transport http.RoundTripper, config *tls.Config) {
+	switch t := transport.(type) {
+	case *http.Transport:
+		t.TLSClientConfig = config
+	case *http2.Transport:
+		t.TLSClientConfig = config
+	}
+}
+
+// expectProtocol compares the protocol of the response, the minor version is optional, e.g. HTTP/2
+func expectProtocol(name, expect string, resp *http.Response) (err error) {
+	if expect == "" {
+		return
+	}
+
+	version := strings.ToUpper(strings.TrimSpace(expect))
+	if !strings.Contains(version, ".") {
+		version += ".0"
+	}
+	major, minor, ok := http.ParseHTTPVersion(version)
+	if !ok || major != resp.ProtoMajor || minor != resp.ProtoMinor {
+		err = fmt.Errorf("case: %s, expect protocol %s, actual %s", name, expect, resp.Proto)
+	}
+	return
+}
@@ -0,0 +1,103 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+func TestProtocolTransport(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"proto":"%s"}`, r.Proto)
+	})
+
+	tlsServer := httptest.NewUnstartedServer(handler)
+	tlsServer.EnableHTTP2 = true
+	tlsServer.StartTLS()
+	defer tlsServer.Close()
+
+	cleartextServer := httptest.NewServer(h2c.NewHandler(handler, &http2.Server{}))
+	defer cleartextServer.Close()
+
+	http1Server := httptest.NewTLSServer(handler)
+	defer http1Server.Close()
+
+	tests := []struct {
+		name     string
+		api      string
+		protocol string
+		expect   atest.Response
+		hasErr   bool
+	}{{
+		name:     "h2 over TLS",
+		api:      tlsServer.URL,
+		protocol: "h2",
+		expect: atest.Response{
+			StatusCode:       http.StatusOK,
+			Protocol:         "HTTP/2",
+			BodyFieldsExpect: map[string]interface{}{"proto": "HTTP/2.0"},
+		},
+	}, {
+		name:     "h2c with the prior knowledge",
+		api:      cleartextServer.URL,
+		protocol: "h2c",
+		expect: atest.Response{
+			StatusCode: http.StatusOK,
+			Protocol:   "HTTP/2.0",
+		},
+	}, {
+		name: "HTTP/1.1 by default",
+		api:  http1Server.URL,
+		expect: atest.Response{
+			StatusCode: http.StatusOK,
+			Protocol:   "HTTP/1.1",
+		},
+	}, {
+		name: "unexpected protocol",
+		api:  http1Server.URL,
+		expect: atest.Response{
+			StatusCode: http.StatusOK,
+			Protocol:   "HTTP/2",
+		},
+		hasErr: true,
+	}, {
+		name:     "h2 is not negotiated",
+		api:      http1Server.URL,
+		protocol: "h2",
+		hasErr:   true,
+	}, {
+		name:     "unsupported protocol",
+		api:      cleartextServer.URL,
+		protocol: "spdy",
+		hasErr:   true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testCase := &atest.TestCase{
+				Request: atest.Request{
+					API:    tt.api,
+					Method: http.MethodGet,
+				},
+				Expect: tt.expect,
+			}
+			if tt.protocol != "" {
+				testCase.Request.Transport = &atest.Transport{Protocol: tt.protocol}
+			}
+
+			reporter := NewMemoryTestReporter()
+			_, err := NewSimpleTestCaseRunner().WithTestReporter(reporter).RunTestCase(testCase, nil, context.TODO())
+			assert.Equal(t, tt.hasErr, err != nil, err)
+			if records := reporter.GetAllRecords(); !tt.hasErr && assert.Equal(t, 1, len(records)) {
+				assert.True(t, strings.HasPrefix(records[0].Protocol, tt.expect.Protocol), records[0].Protocol)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package runner
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/linuxsuren/api-testing/pkg/render"
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+// defaultTunnelTimeout is the timeout of waiting for the public URL if it's not set
+const defaultTunnelTimeout = 30 * time.Second
+
+// tunnelProvider has the arguments of the agent, and the pattern of the public URL in its logs
+type tunnelProvider struct {
+	args    func(tunnel *testing.Tunnel) []string
+	pattern *regexp.Regexp
+}
+
+var tunnelProviders = map[string]tunnelProvider{
+	"ngrok": {
+		args: func(tunnel *testing.Tunnel) (args []string) {
+			args = []string{"http", strconv.Itoa(tunnel.Port), "--log", "stdout", "--log-format", "json"}
+			if tunnel.AuthToken != "" {
+				args = append(args, "--authtoken", tunnel.AuthToken)
+			}
+			return
+		},
+		pattern: regexp.MustCompile(`"url":"(https://[^"]+)"`),
+	},
+	"cloudflared": {
+		args: func(tunnel *testing.Tunnel) []string {
+			return []string{"tunnel", "--no-autoupdate", "--url", fmt.Sprintf("http://localhost:%d", tunnel.Port)}
+		},
+		pattern: regexp.MustCompile(`(https://[-a-z0-9]+\.trycloudflare\.com)`),
+	},
+}
+
+// OpenTunnel starts the agent of the tunneling provider in the background, then waits for the public URL
+// in its logs. The agent keeps running until the returned stop function is called
+func OpenTunnel(ctx context.Context, tunnel *testing.Tunnel) (publicURL string, stop func(), err error) {
+	provider, ok := tunnelProviders[tunnel.Provider]
+	if !ok {
+		err = fmt.Errorf("not supported tunnel provider: '%s', supported: cloudflared, ngrok", tunnel.Provider)
+		return
+	}
+	if tunnel.Port <= 0 {
+		err = fmt.Errorf("the port of the tunnel is required")
+		return
+	}
+
+	timeout := defaultTunnelTimeout
+	if tunnel.Timeout != "" {
+		if timeout, err = time.ParseDuration(tunnel.Timeout); err != nil {
+			return
+		}
+	}
+	// the token could be read from the environment variables, e.g. {{env "NGROK_AUTHTOKEN"}}
+	if tunnel.AuthToken, err = render.Render("auth token", tunnel.AuthToken, nil); err != nil {
+		return
+	}
+
+	command := tunnel.Command
+	if command == "" {
+		command = tunnel.Provider
+	}
+
+	// the agent should not be stopped with the context of waiting
+	agentCtx, cancel := context.WithCancel(context.Background())
+	cmd := exec.CommandContext(agentCtx, command, provider.args(tunnel)...)
+	// the logs are read from a pipe which is not copied by a goroutine, so that waiting for the agent is not
+	// blocked by its child processes
+	var reader, writer *os.File
+	if reader, writer, err = os.Pipe(); err != nil {
+		cancel()
+		return
+	}
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+	err = cmd.Start()
+	_ = writer.Close()
+	if err != nil {
+		cancel()
+		_ = reader.Close()
+		err = fmt.Errorf("failed to start the tunnel agent %s, %v", command, err)
+		return
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(exited)
+	}()
+	stop = func() {
+		cancel()
+		<-exited
+	}
+
+	found := make(chan string, 1)
+	lastLine := make(chan string, 1)
+	go func() {
+		// keep reading the logs, or the agent is blocked
+		scanner := bufio.NewScanner(reader)
+		var line string
+		for scanner.Scan() {
+			line = scanner.Text()
+			if matches := provider.pattern.FindStringSubmatch(line); len(matches) > 1 {
+				select {
+				case found <- matches[1]:
+				default:
+				}
+			}
+		}
+		_ = reader.Close()
+		lastLine <- strings.TrimSpace(line)
+	}()
+
+	waitCtx, waitCancel := context.WithTimeout(ctx, timeout)
+	defer waitCancel()
+	select {
+	case publicURL = <-found:
+		return
+	case line := <-lastLine:
+		err = fmt.Errorf("the tunnel agent %s exited before the public URL was found, %s", command, line)
+	case <-waitCtx.Done():
+		err = fmt.Errorf("failed to get the public URL of the tunnel in %s", timeout)
+	}
+	stop()
+	stop = nil
+	return
+}
@@ -0,0 +1,72 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenTunnel(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake agents are shell scripts")
+	}
+
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	fakeAgent := func(name, script string) string {
+		file := filepath.Join(dir, name)
+		assert.Nil(t, os.WriteFile(file, []byte("#!/bin/sh\necho \"$@\" > "+argsFile+"\n"+script), 0755))
+		return file
+	}
+
+	t.Setenv("NGROK_AUTHTOKEN", "token")
+	publicURL, stop, err := OpenTunnel(context.TODO(), &atest.Tunnel{
+		Provider:  "ngrok",
+		Port:      9090,
+		AuthToken: `{{env "NGROK_AUTHTOKEN"}}`,
+		Command:   fakeAgent("ngrok", `echo '{"lvl":"info","msg":"started tunnel","url":"https://abc.ngrok.app"}'; sleep 30`),
+	})
+	if assert.Nil(t, err) {
+		assert.Equal(t, "https://abc.ngrok.app", publicURL)
+		stop()
+	}
+	args, _ := os.ReadFile(argsFile)
+	assert.Equal(t, "http 9090 --log stdout --log-format json --authtoken token\n", string(args))
+
+	publicURL, stop, err = OpenTunnel(context.TODO(), &atest.Tunnel{
+		Provider: "cloudflared",
+		Port:     9090,
+		Command:  fakeAgent("cloudflared", `echo 'INF |  https://fake-tunnel.trycloudflare.com  |' >&2; sleep 30`),
+	})
+	if assert.Nil(t, err) {
+		assert.Equal(t, "https://fake-tunnel.trycloudflare.com", publicURL)
+		stop()
+	}
+	args, _ = os.ReadFile(argsFile)
+	assert.Equal(t, "tunnel --no-autoupdate --url http://localhost:9090\n", string(args))
+
+	_, _, err = OpenTunnel(context.TODO(), &atest.Tunnel{
+		Provider: "ngrok", Port: 9090, Command: fakeAgent("exited", "echo 'ERR_NGROK_4018: authentication failed'; exit 1"),
+	})
+	assert.EqualError(t, err, "the tunnel agent "+filepath.Join(dir, "exited")+
+		" exited before the public URL was found, ERR_NGROK_4018: authentication failed")
+
+	_, _, err = OpenTunnel(context.TODO(), &atest.Tunnel{
+		Provider: "ngrok", Port: 9090, Timeout: "100ms", Command: fakeAgent("slow", "sleep 30"),
+	})
+	assert.EqualError(t, err, "failed to get the public URL of the tunnel in 100ms")
+
+	_, _, err = OpenTunnel(context.TODO(), &atest.Tunnel{Provider: "ngrok", Port: 9090, Command: filepath.Join(dir, "fake")})
+	assert.Contains(t, err.Error(), "failed to start the tunnel agent")
+
+	_, _, err = OpenTunnel(context.TODO(), &atest.Tunnel{Provider: "fake"})
+	assert.EqualError(t, err, "not supported tunnel provider: 'fake', supported: cloudflared, ngrok")
+
+	_, _, err = OpenTunnel(context.TODO(), &atest.Tunnel{Provider: "ngrok"})
+	assert.EqualError(t, err, "the port of the tunnel is required")
+}
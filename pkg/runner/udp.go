@@ -0,0 +1,89 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+const (
+	// defaultUDPTimeout is the timeout of waiting for the reply if it's not set
+	defaultUDPTimeout = 5 * time.Second
+	// maxUDPDatagramSize is the max size of a UDP datagram
+	maxUDPDatagramSize = 65535
+)
+
+type udpTestCaseRunner struct {
+	*simpleTestCaseRunner
+}
+
+// NewUDPTestCaseRunner creates the instance of the UDP test case runner
+func NewUDPTestCaseRunner() TestCaseRunner {
+	runner := &udpTestCaseRunner{simpleTestCaseRunner: &simpleTestCaseRunner{}}
+	return runner.embeddedBy(runner)
+}
+
+// RunTestCase sends the body as a datagram, then verifies the reply if it's expected
+func (r *udpTestCaseRunner) RunTestCase(testcase *testing.TestCase, dataContext interface{}, ctx context.Context) (output interface{}, err error) {
+	return r.runTestCaseWith(testcase, func(record *ReportRecord) (interface{}, error) {
+		return r.doUDPRequest(testcase, dataContext, ctx, record)
+	})
+}
+
+func (r *udpTestCaseRunner) doUDPRequest(testcase *testing.TestCase, dataContext interface{}, ctx context.Context,
+	record *ReportRecord) (output interface{}, err error) {
+	if err = testcase.Request.Render(dataContext); err != nil {
+		return
+	}
+
+	udpRequest := testcase.Request.UDP
+	record.Method = "UDP"
+
+	var body []byte
+	if body, err = decodeTCPData(udpRequest.Encoding, testcase.Request.Body); err != nil {
+		return
+	}
+
+	deadline := time.Now().Add(defaultUDPTimeout)
+	if udpRequest.Timeout != "" {
+		var timeout time.Duration
+		if timeout, err = time.ParseDuration(udpRequest.Timeout); err != nil {
+			return
+		}
+		deadline = time.Now().Add(timeout)
+	}
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	var conn net.Conn
+	if conn, err = (&net.Dialer{Deadline: deadline}).DialContext(ctx, "udp", testcase.Request.API); err != nil {
+		return
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(deadline); err != nil {
+		return
+	}
+
+	r.log.Info("start to send %d bytes to %s\n", len(body), testcase.Request.API)
+	if _, err = conn.Write(body); err != nil {
+		return
+	}
+	if !udpRequest.Reply {
+		return
+	}
+
+	data := make([]byte, maxUDPDatagramSize)
+	var n int
+	if n, err = conn.Read(data); err != nil {
+		err = fmt.Errorf("case: %s, failed to receive the reply, %v", testcase.Name, err)
+		return
+	}
+	data = data[:n]
+	r.log.Debug("received: %s\n", encodeTCPData(udpRequest.Encoding, data))
+	output, err = verifyRawData(testcase, udpRequest.Encoding, data, record)
+	return
+}
@@ -0,0 +1,162 @@
+package runner_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/linuxsuren/api-testing/pkg/runner"
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUDPTestCaseRunner(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer conn.Close()
+
+	received := make(chan string, 10)
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			datagram := string(buf[:n])
+			received <- datagram
+			switch datagram {
+			case "PING":
+				_, _ = conn.WriteTo([]byte("PONG\n"), addr)
+			case "\x00\x01":
+				_, _ = conn.WriteTo([]byte{0x00, 0x02, 0xff}, addr)
+			}
+		}
+	}()
+	api := conn.LocalAddr().String()
+
+	tests := []struct {
+		name     string
+		testCase *atest.TestCase
+		verify   func(t *testing.T, output interface{}, err error)
+	}{{
+		name: "request and reply",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				Body: "{{.command}}",
+				UDP:  &atest.UDPRequest{Reply: true},
+			},
+			Expect: atest.Response{
+				Body:   "PONG",
+				Verify: []string{`data.length == 5`},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+			assert.Equal(t, map[string]interface{}{
+				"text": "PONG\n", "hex": "504f4e470a", "length": float64(5),
+			}, output)
+			assert.Equal(t, "PING", <-received)
+		},
+	}, {
+		name: "reply in hex",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				Body: "00 01",
+				UDP:  &atest.UDPRequest{Encoding: "hex", Reply: true},
+			},
+			Expect: atest.Response{
+				Body: "00 02 FF",
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+			<-received
+		},
+	}, {
+		name: "fire and forget",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				Body: "metric:1|c",
+				UDP:  &atest.UDPRequest{},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+			assert.Nil(t, output)
+			select {
+			case datagram := <-received:
+				assert.Equal(t, "metric:1|c", datagram)
+			case <-time.After(time.Second):
+				t.Error("the datagram is not received")
+			}
+		},
+	}, {
+		name: "unexpected reply",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				Body: "PING",
+				UDP:  &atest.UDPRequest{Reply: true},
+			},
+			Expect: atest.Response{
+				Body: "PANG",
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+			<-received
+		},
+	}, {
+		name: "no reply before timeout",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				Body: "SILENT",
+				UDP:  &atest.UDPRequest{Reply: true, Timeout: "100ms"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), "failed to receive the reply")
+			}
+			<-received
+		},
+	}, {
+		name: "invalid hex body",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:  api,
+				Body: "fake",
+				UDP:  &atest.UDPRequest{Encoding: "hex"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "invalid timeout",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: api,
+				UDP: &atest.UDPRequest{Timeout: "fake"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			udpRunner := runner.GetTestCaseRunner(tt.testCase)
+			output, err := udpRunner.RunTestCase(tt.testCase, map[string]string{"command": "PING"}, context.TODO())
+			tt.verify(t, output, err)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+)
+
+const unixSocketPrefix = "unix://"
+
+// parseUnixSocketAPI splits an API like unix:///var/run/app.sock:/v1/health
+// into the socket file path and the HTTP request path
+func parseUnixSocketAPI(api string) (socket, path string, ok bool) {
+	if !strings.HasPrefix(api, unixSocketPrefix) {
+		return
+	}
+
+	socket = strings.TrimPrefix(api, unixSocketPrefix)
+	if index := strings.Index(socket, ":/"); index >= 0 {
+		path = socket[index+1:]
+		socket = socket[:index]
+	} else {
+		path = "/"
+	}
+	ok = socket != ""
+	return
+}
+
+// JoinAPI reuses the API prefix of the test suite if the API is a path. The path is separated from
+// the unix socket by a colon, e.g. unix:///var/run/app.sock and /v1 are joined as unix:///var/run/app.sock:/v1
+func JoinAPI(prefix, api string) string {
+	if !strings.HasPrefix(api, "/") {
+		return api
+	}
+
+	if socket := strings.TrimPrefix(prefix, unixSocketPrefix); socket != prefix && !strings.Contains(socket, ":/") {
+		return fmt.Sprintf("%s:%s", prefix, api)
+	}
+	return fmt.Sprintf("%s%s", prefix, api)
+}
+
+// newUnixSocketDialer creates a dial function which always dial to the unix socket
+func newUnixSocketDialer(socket string) dialContextFunc {
+	return func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, "unix", socket)
+	}
+}
@@ -0,0 +1,147 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+func TestParseUnixSocketAPI(t *testing.T) {
+	tests := []struct {
+		name   string
+		api    string
+		socket string
+		path   string
+		ok     bool
+	}{{
+		name:   "with path",
+		api:    "unix:///var/run/app.sock:/v1/health",
+		socket: "/var/run/app.sock",
+		path:   "/v1/health",
+		ok:     true,
+	}, {
+		name:   "without path",
+		api:    "unix:///var/run/app.sock",
+		socket: "/var/run/app.sock",
+		path:   "/",
+		ok:     true,
+	}, {
+		name: "not unix socket",
+		api:  "http://localhost/foo",
+	}, {
+		name: "empty socket",
+		api:  "unix://",
+		path: "/",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			socket, path, ok := parseUnixSocketAPI(tt.api)
+			assert.Equal(t, tt.socket, socket)
+			assert.Equal(t, tt.path, path)
+			assert.Equal(t, tt.ok, ok)
+		})
+	}
+}
+
+func TestJoinAPI(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		api    string
+		expect string
+	}{{
+		name:   "http",
+		prefix: "http://localhost",
+		api:    "/v1/health",
+		expect: "http://localhost/v1/health",
+	}, {
+		name:   "unix socket without path",
+		prefix: "unix:///var/run/app.sock",
+		api:    "/v1/health",
+		expect: "unix:///var/run/app.sock:/v1/health",
+	}, {
+		name:   "unix socket with path",
+		prefix: "unix:///var/run/app.sock:/v1",
+		api:    "/health",
+		expect: "unix:///var/run/app.sock:/v1/health",
+	}, {
+		name:   "not a path",
+		prefix: "unix:///var/run/app.sock",
+		api:    "http://localhost/health",
+		expect: "http://localhost/health",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := JoinAPI(tt.prefix, tt.api)
+			assert.Equal(t, tt.expect, api)
+
+			if socket, path, ok := parseUnixSocketAPI(api); ok {
+				assert.Equal(t, "/var/run/app.sock", socket)
+				assert.Equal(t, "/v1/health", path)
+			}
+		})
+	}
+}
+
+func TestUnixSocketRequest(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "app.sock")
+	lis, err := net.Listen("unix", socket)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer lis.Close()
+
+	go http.Serve(lis, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/health" {
+			w.Write([]byte(`{"status":"ok"}`))
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	runner := NewSimpleTestCaseRunner()
+	output, err := runner.RunTestCase(&atest.TestCase{
+		Request: atest.Request{
+			API: "unix://" + socket + ":/v1/health",
+		},
+		Expect: atest.Response{
+			StatusCode: http.StatusOK,
+		},
+	}, nil, context.TODO())
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"status": "ok"}, output)
+}
+
+func TestUnixSocketRequestWithProtocol(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "app.sock")
+	lis, err := net.Listen("unix", socket)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer lis.Close()
+
+	go http.Serve(lis, h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"proto":"%s"}`, r.Proto)
+	}), &http2.Server{}))
+
+	output, err := NewSimpleTestCaseRunner().RunTestCase(&atest.TestCase{
+		Request: atest.Request{
+			API:       "unix://" + socket + ":/",
+			Transport: &atest.Transport{Protocol: "h2c"},
+		},
+		Expect: atest.Response{
+			StatusCode: http.StatusOK,
+			Protocol:   "HTTP/2",
+		},
+	}, nil, context.TODO())
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"proto": "HTTP/2.0"}, output)
+}
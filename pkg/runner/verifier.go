@@ -0,0 +1,109 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	fakeruntime "github.com/linuxsuren/go-fake-runtime"
+)
+
+// Verifier verifies the response with the custom logic, such as the invariants of the business.
+// It's invoked after the built-in expectations are verified
+type Verifier interface {
+	Verify(response *VerifyResponse, verification *testing.Verification) error
+}
+
+// VerifyResponse is the response of the test case, the output is the decoded body, such as the JSON object
+type VerifyResponse struct {
+	Case       string      `json:"case"`
+	API        string      `json:"api"`
+	StatusCode int         `json:"statusCode"`
+	Body       string      `json:"body"`
+	Output     interface{} `json:"output"`
+}
+
+const verifierExec = "exec"
+
+var verifiers = map[string]Verifier{}
+
+// RegisterVerifier registers a verifier with the type name, it overrides the one of the same name
+func RegisterVerifier(name string, verifier Verifier) {
+	verifiers[name] = verifier
+}
+
+// GetVerifierNames returns all the registered verifier names
+func GetVerifierNames() (names []string) {
+	for name := range verifiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// runVerifiers runs the custom verifiers in order, the exec verifier runs the command by the execer of the runner
+func (r *simpleTestCaseRunner) runVerifiers(testcase *testing.TestCase, output interface{}, record *ReportRecord) (err error) {
+	if len(testcase.Expect.Verifiers) == 0 {
+		return
+	}
+
+	response := &VerifyResponse{
+		Case:       testcase.Name,
+		API:        emptyThenDefault(record.API, testcase.Request.API),
+		StatusCode: record.StatusCode,
+		Body:       record.Body,
+		Output:     output,
+	}
+	for i := range testcase.Expect.Verifiers {
+		verification := &testcase.Expect.Verifiers[i]
+		verifier, ok := verifiers[verification.Type]
+		if verification.Type == verifierExec {
+			verifier, ok = &execVerifier{execer: r.execer}, true
+		}
+		if !ok {
+			err = fmt.Errorf("case: %s, not supported verifier: '%s', supported: %v", testcase.Name, verification.Type,
+				append(GetVerifierNames(), verifierExec))
+			return
+		}
+
+		if err = verifier.Verify(response, verification); err != nil {
+			err = fmt.Errorf("case: %s, failed to verify by %s, %v", testcase.Name, verification.Type, err)
+			return
+		}
+	}
+	return
+}
+
+// execVerifier passes the response to the command by a JSON file, the output of the command is the failure message
+type execVerifier struct {
+	execer fakeruntime.Execer
+}
+
+func (v *execVerifier) Verify(response *VerifyResponse, verification *testing.Verification) (err error) {
+	if verification.Command == "" {
+		err = fmt.Errorf("the command is required by the %s verifier", verifierExec)
+		return
+	}
+
+	var file *os.File
+	if file, err = os.CreateTemp("", "atest-verify-*.json"); err != nil {
+		return
+	}
+	defer os.Remove(file.Name())
+	err = json.NewEncoder(file).Encode(response)
+	_ = file.Close()
+	if err != nil {
+		return
+	}
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	if err = v.execer.RunCommandWithBuffer(verification.Command, "", stdout, stderr,
+		append(verification.Args, file.Name())...); err != nil {
+		err = fmt.Errorf("%s, %s", err, strings.TrimSpace(stdout.String()+stderr.String()))
+	}
+	return
+}
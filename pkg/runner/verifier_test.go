@@ -0,0 +1,89 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/h2non/gock"
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+// balanceVerifier fails if the balance of the account is negative
+type balanceVerifier struct{}
+
+func (v *balanceVerifier) Verify(response *VerifyResponse, verification *atest.Verification) error {
+	body, ok := response.Output.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("the output is not an object")
+	}
+	if balance, _ := body[verification.Options["field"]].(float64); balance < 0 {
+		return fmt.Errorf("the balance is negative: %v", balance)
+	}
+	return nil
+}
+
+func TestRunVerifiers(t *testing.T) {
+	defer gock.Off()
+	RegisterVerifier("balance", &balanceVerifier{})
+	defer delete(verifiers, "balance")
+
+	tests := []struct {
+		name      string
+		body      string
+		verifiers []atest.Verification
+		expectErr string
+	}{{
+		name: "registered",
+		body: `{"balance": 10}`,
+		verifiers: []atest.Verification{
+			{Type: "balance", Options: map[string]string{"field": "balance"}},
+		},
+	}, {
+		name: "registered failed",
+		body: `{"balance": -1}`,
+		verifiers: []atest.Verification{
+			{Type: "balance", Options: map[string]string{"field": "balance"}},
+		},
+		expectErr: "case: verifier, failed to verify by balance, the balance is negative: -1",
+	}, {
+		name: "exec",
+		body: `{"balance": 10}`,
+		verifiers: []atest.Verification{{Type: "exec", Command: "sh", Args: []string{"-c",
+			`grep -q '"statusCode":200' "$0" && grep -q '"output":{"balance":10}' "$0"`}}},
+	}, {
+		name: "exec failed",
+		body: `{"balance": 10}`,
+		verifiers: []atest.Verification{{Type: "exec", Command: "sh", Args: []string{"-c",
+			`echo "the order is missing"; exit 1`}}},
+		expectErr: "case: verifier, failed to verify by exec, exit status 1, the order is missing",
+	}, {
+		name:      "exec without the command",
+		body:      `{}`,
+		verifiers: []atest.Verification{{Type: "exec"}},
+		expectErr: "case: verifier, failed to verify by exec, the command is required by the exec verifier",
+	}, {
+		name:      "not supported",
+		body:      `{}`,
+		verifiers: []atest.Verification{{Type: "fake"}},
+		expectErr: "case: verifier, not supported verifier: 'fake', supported: [balance exec]",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gock.New("http://localhost").Get("/accounts/1").Reply(http.StatusOK).JSON(tt.body)
+
+			_, err := NewSimpleTestCaseRunner().RunTestCase(&atest.TestCase{
+				Name:    "verifier",
+				Request: atest.Request{API: "http://localhost/accounts/1"},
+				Expect:  atest.Response{Verifiers: tt.verifiers},
+			}, nil, context.TODO())
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expectErr)
+			}
+		})
+	}
+}
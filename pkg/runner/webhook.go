@@ -0,0 +1,259 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+// defaultWebhookTimeout is the timeout of waiting for the callbacks if it's not set
+const defaultWebhookTimeout = 10 * time.Second
+
+// webhookReceiver records the requests which are received by a webhook listener
+type webhookReceiver struct {
+	listener *testing.WebhookListener
+	server   *http.Server
+	lock     sync.Mutex
+	requests []map[string]interface{}
+	notify   chan struct{}
+	// refs is the count of the test suite runs which share the listener
+	refs int
+}
+
+var (
+	webhookLock      sync.Mutex
+	webhookReceivers = map[int]*webhookReceiver{}
+)
+
+// OpenWebhookListeners starts the webhook listeners before running the test cases of a suite, the ones on the
+// same port are shared by the concurrent runs. A listener is stopped once all the runs which share it call the
+// returned function
+func OpenWebhookListeners(listeners []*testing.WebhookListener) (closeListeners func(), err error) {
+	var receivers []*webhookReceiver
+	closeListeners = func() {
+		webhookLock.Lock()
+		defer webhookLock.Unlock()
+		for _, receiver := range receivers {
+			if receiver.refs--; receiver.refs <= 0 {
+				_ = receiver.server.Close()
+				delete(webhookReceivers, receiver.listener.Port)
+			}
+		}
+	}
+
+	webhookLock.Lock()
+	for _, listener := range listeners {
+		var receiver *webhookReceiver
+		if receiver, err = listenWebhook(listener); err != nil {
+			break
+		}
+		receiver.refs++
+		receivers = append(receivers, receiver)
+	}
+	webhookLock.Unlock()
+
+	if err != nil {
+		closeListeners()
+	}
+	return
+}
+
+// listenWebhook returns the receiver on the port, or starts a new one. The webhookLock should be held
+func listenWebhook(listener *testing.WebhookListener) (receiver *webhookReceiver, err error) {
+	if receiver = webhookReceivers[listener.Port]; receiver != nil {
+		return
+	}
+
+	host := listener.Host
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	var ln net.Listener
+	if ln, err = net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(listener.Port))); err != nil {
+		return
+	}
+
+	receiver = &webhookReceiver{listener: listener, notify: make(chan struct{}, 1)}
+	receiver.server = &http.Server{Handler: receiver, ReadHeaderTimeout: defaultWebhookTimeout}
+	go func() {
+		_ = receiver.server.Serve(ln)
+	}()
+	webhookReceivers[listener.Port] = receiver
+	return
+}
+
+func getWebhookReceiver(port int) *webhookReceiver {
+	webhookLock.Lock()
+	defer webhookLock.Unlock()
+	return webhookReceivers[port]
+}
+
+// ServeHTTP records the request, the JSON body is parsed
+func (w *webhookReceiver) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if w.listener.Path != "" && req.URL.Path != w.listener.Path {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var body interface{} = string(data)
+	var jsonBody interface{}
+	if json.Unmarshal(data, &jsonBody) == nil {
+		body = jsonBody
+	}
+
+	query := map[string]string{}
+	for key := range req.URL.Query() {
+		query[key] = req.URL.Query().Get(key)
+	}
+	header := map[string]string{}
+	for key := range req.Header {
+		header[key] = req.Header.Get(key)
+	}
+
+	w.lock.Lock()
+	w.requests = append(w.requests, map[string]interface{}{
+		"method": req.Method,
+		"path":   req.URL.Path,
+		"query":  query,
+		"header": header,
+		"body":   body,
+	})
+	w.lock.Unlock()
+
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+	if w.listener.StatusCode > 0 {
+		rw.WriteHeader(w.listener.StatusCode)
+	}
+}
+
+// wait takes the first count requests of the path in the order of arrival, the received ones
+// are returned if the context is done before that
+func (w *webhookReceiver) wait(ctx context.Context, path string, count int) (requests []interface{}, ok bool) {
+	for {
+		if requests, ok = w.take(path, count, false); ok {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			requests, ok = w.take(path, count, true)
+			return
+		case <-w.notify:
+		}
+	}
+}
+
+func (w *webhookReceiver) take(path string, count int, partial bool) (requests []interface{}, ok bool) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	var indexes []int
+	for i, request := range w.requests {
+		if path == "" || request["path"] == path {
+			indexes = append(indexes, i)
+		}
+		if len(indexes) == count {
+			break
+		}
+	}
+	if ok = len(indexes) == count; !ok && !partial {
+		return
+	}
+
+	var remaining []map[string]interface{}
+	next := 0
+	for i, request := range w.requests {
+		if next < len(indexes) && indexes[next] == i {
+			requests = append(requests, request)
+			next++
+			continue
+		}
+		remaining = append(remaining, request)
+	}
+	w.requests = remaining
+	return
+}
+
+type webhookTestCaseRunner struct {
+	*simpleTestCaseRunner
+}
+
+// NewWebhookTestCaseRunner creates the instance of the webhook test case runner
+func NewWebhookTestCaseRunner() TestCaseRunner {
+	runner := &webhookTestCaseRunner{simpleTestCaseRunner: &simpleTestCaseRunner{}}
+	return runner.embeddedBy(runner)
+}
+
+// RunTestCase waits for the callbacks, then verifies them as an array
+func (r *webhookTestCaseRunner) RunTestCase(testcase *testing.TestCase, dataContext interface{}, ctx context.Context) (output interface{}, err error) {
+	return r.runTestCaseWith(testcase, func(record *ReportRecord) (interface{}, error) {
+		return r.doWebhookRequest(testcase, dataContext, ctx, record)
+	})
+}
+
+func (r *webhookTestCaseRunner) doWebhookRequest(testcase *testing.TestCase, dataContext interface{}, ctx context.Context,
+	record *ReportRecord) (output interface{}, err error) {
+	if err = testcase.Request.Render(dataContext); err != nil {
+		return
+	}
+
+	webhook := testcase.Request.Webhook
+	record.Method = "WEBHOOK"
+	record.API = fmt.Sprintf(":%d%s", webhook.Port, webhook.Path)
+
+	receiver := getWebhookReceiver(webhook.Port)
+	if receiver == nil {
+		err = fmt.Errorf("case: %s, no webhook listener on the port %d, start it by the prepare.webhookListener of a previous test case",
+			testcase.Name, webhook.Port)
+		return
+	}
+
+	timeout := defaultWebhookTimeout
+	if webhook.Timeout != "" {
+		if timeout, err = time.ParseDuration(webhook.Timeout); err != nil {
+			return
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	count := webhook.Count
+	if count <= 0 {
+		count = 1
+	}
+	r.log.Info("start to wait for %d requests of %s\n", count, record.API)
+	requests, ok := receiver.wait(ctx, webhook.Path, count)
+
+	var data []byte
+	if data, err = json.Marshal(requests); err != nil {
+		return
+	}
+	record.Body = string(data)
+	r.log.Debug("received requests: %s\n", record.Body)
+	if !ok {
+		err = fmt.Errorf("case: %s, expect %d requests in %s, received %d", testcase.Name, count, timeout, len(requests))
+		return
+	}
+
+	if output, err = verifyResponseBodyData(testcase.Name, testcase.Expect, data); err != nil {
+		return
+	}
+	err = jsonSchemaValidation(testcase.Expect.Schema, data)
+	return
+}
@@ -0,0 +1,137 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookTestCaseRunner(t *testing.T) {
+	port := freePort(t)
+	listener := &atest.WebhookListener{Port: port, Path: "/callback", StatusCode: http.StatusAccepted}
+	closeListeners, err := OpenWebhookListeners([]*atest.WebhookListener{listener})
+	assert.Nil(t, err)
+	defer closeListeners()
+	// it's fine to start it again
+	closeAgain, err := OpenWebhookListeners([]*atest.WebhookListener{listener})
+	assert.Nil(t, err)
+	defer closeAgain()
+
+	// not intercepted by the mocks of the other tests
+	client := &http.Client{Transport: &http.Transport{}}
+	callback := func(path, body string) int {
+		resp, err := client.Post(fmt.Sprintf("http://localhost:%d%s?order=1", port, path), "application/json",
+			strings.NewReader(body))
+		if !assert.Nil(t, err) {
+			return 0
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusAccepted, callback("/callback", `{"status": "pending"}`))
+	assert.Equal(t, http.StatusAccepted, callback("/callback", `{"status": "paid"}`))
+	assert.Equal(t, http.StatusAccepted, callback("/callback", `done`))
+	assert.Equal(t, http.StatusNotFound, callback("/fake", `{}`))
+
+	tests := []struct {
+		name      string
+		webhook   *atest.WebhookRequest
+		expect    atest.Response
+		output    interface{}
+		expectErr string
+	}{{
+		name:    "in order",
+		webhook: &atest.WebhookRequest{Port: port, Path: "/callback", Count: 2},
+		expect: atest.Response{Verify: []string{
+			`data[0].body.status == "pending" && data[1].body.status == "paid"`,
+			`data[0].query.order == "1" && data[0].header["Content-Type"] == "application/json"`,
+		}},
+	}, {
+		name:    "the received ones are not returned again",
+		webhook: &atest.WebhookRequest{Port: port},
+		output: []interface{}{map[string]interface{}{
+			"method": http.MethodPost,
+			"path":   "/callback",
+			"query":  map[string]interface{}{"order": "1"},
+			"header": map[string]interface{}{"Accept-Encoding": "gzip", "Content-Length": "4",
+				"Content-Type": "application/json", "User-Agent": "Go-http-client/1.1"},
+			"body": "done",
+		}},
+	}, {
+		name:      "timeout",
+		webhook:   &atest.WebhookRequest{Port: port, Timeout: "10ms"},
+		expectErr: "case: webhook, expect 1 requests in 10ms, received 0",
+	}, {
+		name:      "no listener",
+		webhook:   &atest.WebhookRequest{Port: freePort(t)},
+		expectErr: "no webhook listener on the port",
+	}, {
+		name:      "invalid timeout",
+		webhook:   &atest.WebhookRequest{Port: port, Timeout: "fake"},
+		expectErr: `invalid duration "fake"`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testCase := &atest.TestCase{
+				Name:    "webhook",
+				Request: atest.Request{Webhook: tt.webhook},
+				Expect:  tt.expect,
+			}
+			output, err := GetTestCaseRunner(testCase).RunTestCase(testCase, nil, context.TODO())
+			if tt.expectErr != "" {
+				if assert.NotNil(t, err) {
+					assert.Contains(t, err.Error(), tt.expectErr)
+				}
+				return
+			}
+			assert.Nil(t, err)
+			if tt.output != nil {
+				assert.Equal(t, tt.output, output)
+			}
+		})
+	}
+}
+
+func freePort(t *testing.T) int {
+	ln, err := net.Listen("tcp", ":0")
+	assert.Nil(t, err)
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestOpenWebhookListeners(t *testing.T) {
+	port := freePort(t)
+	listener := &atest.WebhookListener{Port: port}
+
+	// two runs of the test suite share the listener
+	closeFirst, err := OpenWebhookListeners([]*atest.WebhookListener{listener})
+	assert.Nil(t, err)
+	closeSecond, err := OpenWebhookListeners([]*atest.WebhookListener{listener})
+	assert.Nil(t, err)
+
+	closeFirst()
+	if assert.NotNil(t, getWebhookReceiver(port)) {
+		conn, dialErr := net.Dial("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+		if assert.Nil(t, dialErr) {
+			conn.Close()
+		}
+	}
+	closeSecond()
+	assert.Nil(t, getWebhookReceiver(port))
+
+	// the started listeners are stopped if one of them fails
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if assert.Nil(t, err) {
+		defer ln.Close()
+		_, err = OpenWebhookListeners([]*atest.WebhookListener{listener, {Port: ln.Addr().(*net.TCPAddr).Port}})
+		assert.NotNil(t, err)
+		assert.Nil(t, getWebhookReceiver(port))
+	}
+}
@@ -0,0 +1,141 @@
+package runner
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+	"golang.org/x/net/websocket"
+)
+
+// defaultWebSocketTimeout is the timeout of a WebSocket session if it's not set
+const defaultWebSocketTimeout = 10 * time.Second
+
+type webSocketTestCaseRunner struct {
+	*simpleTestCaseRunner
+}
+
+// NewWebSocketTestCaseRunner creates the instance of the WebSocket test case runner
+func NewWebSocketTestCaseRunner() TestCaseRunner {
+	runner := &webSocketTestCaseRunner{simpleTestCaseRunner: &simpleTestCaseRunner{}}
+	return runner.embeddedBy(runner)
+}
+
+// RunTestCase sends the messages through a WebSocket connection, then verifies the received messages
+func (r *webSocketTestCaseRunner) RunTestCase(testcase *testing.TestCase, dataContext interface{}, ctx context.Context) (output interface{}, err error) {
+	return r.runTestCaseWith(testcase, func(record *ReportRecord) (interface{}, error) {
+		return r.doWebSocketRequest(testcase, dataContext, ctx, record)
+	})
+}
+
+func (r *webSocketTestCaseRunner) doWebSocketRequest(testcase *testing.TestCase, dataContext interface{}, ctx context.Context,
+	record *ReportRecord) (output interface{}, err error) {
+	if err = testcase.Request.Render(dataContext); err != nil {
+		return
+	}
+
+	wsRequest := testcase.Request.WebSocket
+	record.Method = "WS"
+
+	deadline := time.Now().Add(defaultWebSocketTimeout)
+	if wsRequest.Timeout != "" {
+		var timeout time.Duration
+		if timeout, err = time.ParseDuration(wsRequest.Timeout); err != nil {
+			return
+		}
+		deadline = time.Now().Add(timeout)
+	}
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	var config *websocket.Config
+	if config, err = websocket.NewConfig(testcase.Request.API, webSocketOrigin(testcase.Request.API)); err != nil {
+		return
+	}
+	config.TlsConfig = &tls.Config{InsecureSkipVerify: true}
+	config.Dialer = &net.Dialer{Deadline: deadline}
+	for key, val := range testcase.Request.Header {
+		config.Header.Set(key, val)
+	}
+
+	r.log.Info("start to connect %s\n", testcase.Request.API)
+	var conn *websocket.Conn
+	if conn, err = websocket.DialConfig(config); err != nil {
+		return
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(deadline); err != nil {
+		return
+	}
+
+	for _, message := range wsRequest.Messages {
+		if err = websocket.Message.Send(conn, message); err != nil {
+			err = fmt.Errorf("failed to send message: %s, %v", message, err)
+			return
+		}
+	}
+
+	receive := wsRequest.Receive
+	if receive < len(testcase.Expect.Messages) {
+		receive = len(testcase.Expect.Messages)
+	}
+
+	messages := []string{}
+	for len(messages) < receive {
+		var message string
+		if err = websocket.Message.Receive(conn, &message); err != nil {
+			err = fmt.Errorf("case: %s, expect %d messages, received %d, %v", testcase.Name, receive, len(messages), err)
+			return
+		}
+		r.log.Debug("received message: %s\n", message)
+		messages = append(messages, message)
+	}
+	record.Body = strings.Join(messages, "\n")
+
+	for i, expect := range testcase.Expect.Messages {
+		if err = expectString(testcase.Name, strings.TrimSpace(expect), strings.TrimSpace(messages[i])); err != nil {
+			return
+		}
+	}
+
+	// the received messages are verified as an array, the JSON ones are parsed
+	var data []byte
+	if data, err = json.Marshal(parseMessages(messages)); err != nil {
+		return
+	}
+	if output, err = verifyResponseBodyData(testcase.Name, testcase.Expect, data); err != nil {
+		return
+	}
+	err = jsonSchemaValidation(testcase.Expect.Schema, data)
+	return
+}
+
+func parseMessages(messages []string) (result []interface{}) {
+	result = make([]interface{}, len(messages))
+	for i, message := range messages {
+		var object interface{}
+		if err := json.Unmarshal([]byte(message), &object); err == nil {
+			result[i] = object
+		} else {
+			result[i] = message
+		}
+	}
+	return
+}
+
+// webSocketOrigin returns the HTTP origin of the WebSocket address
+func webSocketOrigin(api string) string {
+	origin := strings.Replace(api, "ws", "http", 1)
+	if index := strings.Index(origin, "://"); index > 0 {
+		if end := strings.Index(origin[index+3:], "/"); end >= 0 {
+			origin = origin[:index+3+end]
+		}
+	}
+	return origin
+}
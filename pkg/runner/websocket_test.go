@@ -0,0 +1,131 @@
+package runner_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/linuxsuren/api-testing/pkg/runner"
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+func TestWebSocketTestCaseRunner(t *testing.T) {
+	server := httptest.NewServer(websocket.Handler(func(conn *websocket.Conn) {
+		for {
+			var message string
+			if err := websocket.Message.Receive(conn, &message); err != nil {
+				return
+			}
+
+			_ = websocket.Message.Send(conn, `{"type":"ack","user":"`+conn.Request().Header.Get("user")+`"}`)
+			_ = websocket.Message.Send(conn, "echo: "+message)
+		}
+	}))
+	defer server.Close()
+	api := strings.Replace(server.URL, "http", "ws", 1) + "/ws"
+
+	tests := []struct {
+		name     string
+		testCase *atest.TestCase
+		verify   func(t *testing.T, output interface{}, err error)
+	}{{
+		name: "normal",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:    api,
+				Header: map[string]string{"user": "linuxsuren"},
+				WebSocket: &atest.WebSocketRequest{
+					Messages: []string{"{{.name}}"},
+				},
+			},
+			Expect: atest.Response{
+				Messages: []string{`{"type":"ack","user":"linuxsuren"}`, "echo: hello"},
+				Verify:   []string{`len(data) == 2`, `data[0].type == "ack"`},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+			assert.Equal(t, []interface{}{
+				map[string]interface{}{"type": "ack", "user": "linuxsuren"},
+				"echo: hello",
+			}, output)
+		},
+	}, {
+		name: "receive more messages",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: api,
+				WebSocket: &atest.WebSocketRequest{
+					Messages: []string{"a", "b"},
+					Receive:  4,
+				},
+			},
+			Expect: atest.Response{
+				Verify: []string{`data[3] == "echo: b"`},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.Nil(t, err)
+		},
+	}, {
+		name: "unexpected message",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:       api,
+				WebSocket: &atest.WebSocketRequest{Messages: []string{"hello"}},
+			},
+			Expect: atest.Response{
+				Messages: []string{"fake"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "timeout",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API: api,
+				WebSocket: &atest.WebSocketRequest{
+					Receive: 1,
+					Timeout: "100ms",
+				},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "invalid timeout",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:       api,
+				WebSocket: &atest.WebSocketRequest{Timeout: "fake"},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}, {
+		name: "cannot connect",
+		testCase: &atest.TestCase{
+			Request: atest.Request{
+				API:       "ws://127.0.0.1:1/ws",
+				WebSocket: &atest.WebSocketRequest{},
+			},
+		},
+		verify: func(t *testing.T, output interface{}, err error) {
+			assert.NotNil(t, err)
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wsRunner := runner.GetTestCaseRunner(tt.testCase)
+			output, err := wsRunner.RunTestCase(tt.testCase, map[string]string{"name": "hello"}, context.TODO())
+			tt.verify(t, output, err)
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package runner
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// WriteTerraformResult writes the result in the protocol of the Terraform external data source,
+// it's a flat JSON object whose values are strings, such as: {"passed": "true", "count": "3", "errors": "0", "error": ""}
+func WriteTerraformResult(writer io.Writer, results []ReportResult, runErr error) error {
+	var count, errors int
+	for _, result := range results {
+		count += result.Count
+		errors += result.Error
+	}
+
+	output := map[string]string{
+		"passed": strconv.FormatBool(runErr == nil && errors == 0),
+		"count":  strconv.Itoa(count),
+		"errors": strconv.Itoa(errors),
+		"error":  "",
+	}
+	if runErr != nil {
+		output["error"] = runErr.Error()
+	}
+	return json.NewEncoder(writer).Encode(output)
+}
@@ -0,0 +1,39 @@
+package runner_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/linuxsuren/api-testing/pkg/runner"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteTerraformResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []runner.ReportResult
+		runErr  error
+		expect  string
+	}{{
+		name:    "passed",
+		results: []runner.ReportResult{{API: "/users", Count: 2}, {API: "/orders", Count: 1}},
+		expect:  `{"count":"3","error":"","errors":"0","passed":"true"}` + "\n",
+	}, {
+		name:    "failed",
+		results: []runner.ReportResult{{API: "/users", Count: 2, Error: 1}},
+		runErr:  errors.New("failed to run 'users'"),
+		expect:  `{"count":"2","error":"failed to run 'users'","errors":"1","passed":"false"}` + "\n",
+	}, {
+		name:   "no results",
+		expect: `{"count":"0","error":"","errors":"0","passed":"true"}` + "\n",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			err := runner.WriteTerraformResult(buf, tt.results, tt.runErr)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expect, buf.String())
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/antchfx/xpath"
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+// xmlNode is a node of the XML document tree, the name space is the raw prefix,
+// so the XPath should have the same prefixes as the document
+type xmlNode struct {
+	nodeType    xpath.NodeType
+	name        xml.Name
+	data        string
+	attrs       []xml.Attr
+	parent      *xmlNode
+	firstChild  *xmlNode
+	lastChild   *xmlNode
+	prevSibling *xmlNode
+	nextSibling *xmlNode
+}
+
+func (n *xmlNode) appendChild(child *xmlNode) {
+	child.parent = n
+	if n.lastChild == nil {
+		n.firstChild = child
+	} else {
+		n.lastChild.nextSibling = child
+		child.prevSibling = n.lastChild
+	}
+	n.lastChild = child
+}
+
+func (n *xmlNode) innerText() string {
+	if n.nodeType == xpath.TextNode || n.nodeType == xpath.CommentNode {
+		return n.data
+	}
+
+	buf := new(strings.Builder)
+	for child := n.firstChild; child != nil; child = child.nextSibling {
+		if child.nodeType != xpath.CommentNode {
+			buf.WriteString(child.innerText())
+		}
+	}
+	return buf.String()
+}
+
+// parseXML parses the data to be a document tree, the whitespace-only texts are ignored
+func parseXML(data []byte) (root *xmlNode, err error) {
+	root = &xmlNode{nodeType: xpath.RootNode}
+	current := root
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		var token xml.Token
+		if token, err = decoder.RawToken(); err == io.EOF {
+			err = nil
+			break
+		} else if err != nil {
+			return
+		}
+
+		switch item := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{nodeType: xpath.ElementNode, name: item.Name, attrs: item.Copy().Attr}
+			current.appendChild(node)
+			current = node
+		case xml.EndElement:
+			if current.parent == nil || current.name != item.Name {
+				err = fmt.Errorf("unexpected end element %s", item.Name.Local)
+				return
+			}
+			current = current.parent
+		case xml.CharData:
+			if text := string(item); strings.TrimSpace(text) != "" {
+				current.appendChild(&xmlNode{nodeType: xpath.TextNode, data: text})
+			}
+		case xml.Comment:
+			current.appendChild(&xmlNode{nodeType: xpath.CommentNode, data: string(item)})
+		}
+	}
+
+	if current != root {
+		err = fmt.Errorf("element %s is not closed", current.name.Local)
+		return
+	}
+	for child := root.firstChild; child != nil; child = child.nextSibling {
+		if child.nodeType == xpath.ElementNode {
+			return
+		}
+	}
+	err = fmt.Errorf("no XML element found")
+	return
+}
+
+// xmlNavigator implements the xpath.NodeNavigator
+type xmlNavigator struct {
+	root, current *xmlNode
+	attr          int
+}
+
+func newXMLNavigator(root *xmlNode) *xmlNavigator {
+	return &xmlNavigator{root: root, current: root, attr: -1}
+}
+
+func (n *xmlNavigator) NodeType() xpath.NodeType {
+	if n.attr != -1 {
+		return xpath.AttributeNode
+	}
+	return n.current.nodeType
+}
+
+func (n *xmlNavigator) LocalName() string {
+	if n.attr != -1 {
+		return n.current.attrs[n.attr].Name.Local
+	}
+	return n.current.name.Local
+}
+
+func (n *xmlNavigator) Prefix() string {
+	if n.attr != -1 {
+		return n.current.attrs[n.attr].Name.Space
+	}
+	return n.current.name.Space
+}
+
+func (n *xmlNavigator) Value() string {
+	if n.attr != -1 {
+		return n.current.attrs[n.attr].Value
+	}
+	return n.current.innerText()
+}
+
+func (n *xmlNavigator) Copy() xpath.NodeNavigator {
+	navigator := *n
+	return &navigator
+}
+
+func (n *xmlNavigator) MoveToRoot() {
+	n.current, n.attr = n.root, -1
+}
+
+func (n *xmlNavigator) MoveToParent() bool {
+	if n.attr != -1 {
+		n.attr = -1
+		return true
+	} else if n.current.parent != nil {
+		n.current = n.current.parent
+		return true
+	}
+	return false
+}
+
+func (n *xmlNavigator) MoveToNextAttribute() bool {
+	if n.attr >= len(n.current.attrs)-1 {
+		return false
+	}
+	n.attr++
+	return true
+}
+
+func (n *xmlNavigator) MoveToChild() bool {
+	if n.attr != -1 || n.current.firstChild == nil {
+		return false
+	}
+	n.current = n.current.firstChild
+	return true
+}
+
+func (n *xmlNavigator) MoveToFirst() bool {
+	if n.attr != -1 || n.current.prevSibling == nil {
+		return false
+	}
+	for n.current.prevSibling != nil {
+		n.current = n.current.prevSibling
+	}
+	return true
+}
+
+func (n *xmlNavigator) MoveToNext() bool {
+	if n.attr != -1 || n.current.nextSibling == nil {
+		return false
+	}
+	n.current = n.current.nextSibling
+	return true
+}
+
+func (n *xmlNavigator) MoveToPrevious() bool {
+	if n.attr != -1 || n.current.prevSibling == nil {
+		return false
+	}
+	n.current = n.current.prevSibling
+	return true
+}
+
+func (n *xmlNavigator) MoveTo(other xpath.NodeNavigator) bool {
+	node, ok := other.(*xmlNavigator)
+	if !ok || node.root != n.root {
+		return false
+	}
+	n.current, n.attr = node.current, node.attr
+	return true
+}
+
+// evaluateXPath evaluates the XPath expression, the value of the first node is returned if it selects nodes
+func evaluateXPath(root *xmlNode, expression string) (result string, err error) {
+	var expr *xpath.Expr
+	if expr, err = xpath.Compile(expression); err != nil {
+		err = fmt.Errorf("invalid XPath '%s', %v", expression, err)
+		return
+	}
+
+	switch val := expr.Evaluate(newXMLNavigator(root)).(type) {
+	case *xpath.NodeIterator:
+		if !val.MoveNext() {
+			err = fmt.Errorf("not found the node by XPath '%s'", expression)
+			return
+		}
+		result = val.Current().Value()
+	case float64:
+		result = strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		result = strconv.FormatBool(val)
+	default:
+		result = fmt.Sprintf("%v", val)
+	}
+	return
+}
+
+// verifyXMLResponse verifies the XML response body with the XPath expectations,
+// the output is the text of the body
+func verifyXMLResponse(caseName string, expect testing.Response, body []byte) (output interface{}, err error) {
+	if expect.Body != "" && strings.TrimSpace(string(body)) != strings.TrimSpace(expect.Body) {
+		err = fmt.Errorf("case: %s, got different response body, expect: %s, actual: %s", caseName, expect.Body, string(body))
+		return
+	}
+	if err = verifyBodyText(caseName, expect, body); err != nil {
+		return
+	}
+
+	var root *xmlNode
+	if root, err = parseXML(body); err != nil {
+		err = fmt.Errorf("case: %s, failed to parse the XML response, %v", caseName, err)
+		return
+	}
+
+	for expression, expectVal := range expect.XPath {
+		var val string
+		if val, err = evaluateXPath(root, expression); err != nil {
+			return
+		}
+		if strings.TrimSpace(val) != expectVal {
+			err = fmt.Errorf("case: %s, XPath[%s] expect value: %s, actual: %s", caseName, expression, expectVal, val)
+			return
+		}
+	}
+	output = string(body)
+	return
+}
@@ -0,0 +1,98 @@
+package runner
+
+import (
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+const soapResponse = `<?xml version="1.0" encoding="utf-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <!-- the price of an item -->
+    <m:GetPriceResponse xmlns:m="https://www.w3schools.com/prices">
+      <m:Price currency="USD">1.90</m:Price>
+      <m:Item>apple</m:Item>
+      <m:Item>banana</m:Item>
+    </m:GetPriceResponse>
+  </soap:Body>
+</soap:Envelope>`
+
+func TestEvaluateXPath(t *testing.T) {
+	root, err := parseXML([]byte(soapResponse))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	tests := []struct {
+		expression string
+		expect     string
+		hasErr     bool
+	}{
+		{expression: "//Price", hasErr: true},
+		{expression: "//m:Price", expect: "1.90"},
+		{expression: "/soap:Envelope/soap:Body/m:GetPriceResponse/m:Price/@currency", expect: "USD"},
+		{expression: "//m:Item[2]", expect: "banana"},
+		{expression: "//m:Item[last()]/preceding-sibling::*[1]", expect: "apple"},
+		{expression: "count(//m:Item)", expect: "2"},
+		{expression: "//m:Price > 1", expect: "true"},
+		{expression: "string(//m:Item)", expect: "apple"},
+		{expression: "//*[local-name()='Price']/../*[local-name()='Item']", expect: "apple"},
+		{expression: "//comment()", expect: " the price of an item "},
+		{expression: "//fake", hasErr: true},
+		{expression: "//[", hasErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			result, err := evaluateXPath(root, tt.expression)
+			if assert.Equal(t, tt.hasErr, err != nil, err) && !tt.hasErr {
+				assert.Equal(t, tt.expect, result)
+			}
+		})
+	}
+}
+
+func TestParseXML(t *testing.T) {
+	for _, invalid := range []string{"", "fake", "<a><b></a>", "</a>", "<a>"} {
+		_, err := parseXML([]byte(invalid))
+		assert.NotNil(t, err, invalid)
+	}
+}
+
+func TestVerifyXMLResponse(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect atest.Response
+		hasErr bool
+	}{{
+		name: "normal",
+		expect: atest.Response{
+			XPath: map[string]string{
+				"//m:Price":           "1.90",
+				"//m:Price/@currency": "USD",
+			},
+		},
+	}, {
+		name: "unexpected value",
+		expect: atest.Response{
+			XPath: map[string]string{"//m:Price": "2"},
+		},
+		hasErr: true,
+	}, {
+		name:   "unexpected body",
+		expect: atest.Response{Body: "<a/>"},
+		hasErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := verifyXMLResponse("test", tt.expect, []byte(soapResponse))
+			if assert.Equal(t, tt.hasErr, err != nil, err) && !tt.hasErr {
+				assert.Equal(t, soapResponse, output)
+			}
+		})
+	}
+
+	_, err := verifyXMLResponse("test", atest.Response{}, []byte("{}"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,38 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/linuxsuren/api-testing/pkg/testing"
+)
+
+func hasXSD(expect testing.Response) bool {
+	return expect.XSD != "" || expect.XSDFromFile != ""
+}
+
+// verifyXSD validates the XML body by xmllint. The inline schema takes precedence over the schema file,
+// it's written into a temporary file because xmllint only reads the schema from a file
+func (r *simpleTestCaseRunner) verifyXSD(caseName string, expect testing.Response, body []byte) (err error) {
+	schemaFile := expect.XSDFromFile
+	if expect.XSD != "" {
+		if schemaFile, err = writeTempFile([]byte(expect.XSD)); err != nil {
+			return
+		}
+		defer os.Remove(schemaFile)
+	}
+
+	var bodyFile string
+	if bodyFile, err = writeTempFile(body); err != nil {
+		return
+	}
+	defer os.Remove(bodyFile)
+
+	var output string
+	if output, err = r.execer.RunCommandAndReturn("xmllint", "", "--noout", "--schema", schemaFile, bodyFile); err != nil {
+		err = fmt.Errorf("case: %s, the XML body does not match the XSD, %v, %s", caseName, err,
+			strings.TrimSpace(strings.ReplaceAll(output, bodyFile, "body")))
+	}
+	return
+}
@@ -0,0 +1,81 @@
+package runner
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	atest "github.com/linuxsuren/api-testing/pkg/testing"
+	fakeruntime "github.com/linuxsuren/go-fake-runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+const priceXSD = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="price">
+    <xs:complexType>
+      <xs:simpleContent>
+        <xs:extension base="xs:decimal">
+          <xs:attribute name="currency" type="xs:string" use="required"/>
+        </xs:extension>
+      </xs:simpleContent>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`
+
+func TestVerifyXSD(t *testing.T) {
+	if _, err := exec.LookPath("xmllint"); err != nil {
+		t.Skip("xmllint is not installed")
+	}
+
+	schemaFile := filepath.Join(t.TempDir(), "price.xsd")
+	assert.Nil(t, os.WriteFile(schemaFile, []byte(priceXSD), 0644))
+
+	runner := NewSimpleTestCaseRunner().(*simpleTestCaseRunner)
+	tests := []struct {
+		name      string
+		expect    atest.Response
+		body      string
+		expectErr string
+	}{{
+		name:   "inline schema",
+		expect: atest.Response{XSD: priceXSD},
+		body:   `<price currency="USD">1.90</price>`,
+	}, {
+		name:   "schema file",
+		expect: atest.Response{XSDFromFile: schemaFile},
+		body:   `<price currency="USD">1.90</price>`,
+	}, {
+		name:      "missing attribute",
+		expect:    atest.Response{XSDFromFile: schemaFile},
+		body:      `<price>1.90</price>`,
+		expectErr: "case: fake, the XML body does not match the XSD",
+	}, {
+		name:      "invalid value",
+		expect:    atest.Response{XSD: priceXSD},
+		body:      `<price currency="USD">free</price>`,
+		expectErr: "'free' is not a valid value of the atomic type 'xs:decimal'",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runner.verifyXSD("fake", tt.expect, []byte(tt.body))
+			if tt.expectErr == "" {
+				assert.Nil(t, err)
+			} else if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestVerifyXSDWithoutXMLLint(t *testing.T) {
+	runner := NewSimpleTestCaseRunner().WithExecer(fakeruntime.FakeExecer{
+		ExpectError: errors.New("exit status 3"), ExpectErrOutput: "fails to validate",
+	}).(*simpleTestCaseRunner)
+	err := runner.verifyXSD("fake", atest.Response{XSD: priceXSD}, []byte(`<price/>`))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "case: fake, the XML body does not match the XSD, exit status 3")
+	assert.False(t, hasXSD(atest.Response{}))
+}
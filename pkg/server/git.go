@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ghodss/yaml"
+)
+
+// gitSource is the data of the git task, the suite file is at the path of the repository
+type gitSource struct {
+	Repo string `json:"repo"`
+	Ref  string `json:"ref"`
+	Path string `json:"path"`
+}
+
+var (
+	// gitCacheDir keeps a clone of each repository, the refs are fetched into it shallowly
+	gitCacheDir = defaultGitCacheDir()
+	gitLock     sync.Mutex
+	// gitProtocols are the allowed transports of the repositories, the local ones are not allowed by default
+	gitProtocols = []string{"https", "ssh"}
+)
+
+func defaultGitCacheDir() string {
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "atest", "git")
+	}
+	return filepath.Join(os.TempDir(), "atest-git")
+}
+
+// loadSuiteFromGit checks out the ref of the repository, then reads the suite file. The ref is the
+// default branch if it's empty, it could be a branch, tag or commit
+func loadSuiteFromGit(ctx context.Context, data string) (suiteData []byte, err error) {
+	source := gitSource{}
+	if err = yaml.Unmarshal([]byte(data), &source); err != nil {
+		return
+	}
+	if source.Repo == "" || source.Path == "" {
+		err = fmt.Errorf("the repo and path of the git task are required")
+		return
+	}
+	// the options of git should not be injected by the repo or ref
+	if strings.HasPrefix(source.Repo, "-") || strings.HasPrefix(source.Ref, "-") {
+		err = fmt.Errorf("the repo and ref of the git task should not start with '-'")
+		return
+	}
+	ref := source.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	// the checkouts of the same repository should not be mixed
+	gitLock.Lock()
+	defer gitLock.Unlock()
+
+	sum := sha256.Sum256([]byte(source.Repo))
+	dir := filepath.Join(gitCacheDir, hex.EncodeToString(sum[:8]))
+	if _, statErr := os.Stat(filepath.Join(dir, ".git")); statErr != nil {
+		if err = runGit(ctx, "", "init", "-q", dir); err != nil {
+			return
+		}
+	}
+	args := []string{"-c", "protocol.allow=never"}
+	for _, protocol := range gitProtocols {
+		args = append(args, "-c", fmt.Sprintf("protocol.%s.allow=always", protocol))
+	}
+	if err = runGit(ctx, dir, append(args, "fetch", "-q", "--depth", "1", "--", source.Repo, ref)...); err != nil {
+		return
+	}
+	if err = runGit(ctx, dir, "checkout", "-q", "--force", "FETCH_HEAD"); err != nil {
+		return
+	}
+
+	var suiteFile string
+	if suiteFile, err = repoFilePath(dir, source.Path); err == nil {
+		suiteData, err = os.ReadFile(suiteFile)
+	}
+	return
+}
+
+// repoFilePath returns the real path of the file in the repository, the file (or the symbolic links
+// of it) should not be out of the repository
+func repoFilePath(dir, name string) (file string, err error) {
+	if dir, err = filepath.EvalSymlinks(dir); err != nil {
+		return
+	}
+	if file, err = filepath.EvalSymlinks(filepath.Join(dir, filepath.Clean("/"+name))); err != nil {
+		return
+	}
+
+	if rel, relErr := filepath.Rel(dir, file); relErr != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		err = fmt.Errorf("the path %s is out of the repository", name)
+	}
+	return
+}
+
+func runGit(ctx context.Context, dir string, args ...string) (err error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var output []byte
+	if output, err = cmd.CombinedOutput(); err != nil {
+		err = fmt.Errorf("failed to run git %s, %v, %s", gitSubCommand(args), err, strings.TrimSpace(string(output)))
+	}
+	return
+}
+
+// gitSubCommand returns the sub command of the arguments, the config options are skipped
+func gitSubCommand(args []string) string {
+	for i := 0; i < len(args); i++ {
+		if args[i] == "-c" {
+			i++
+			continue
+		}
+		return args[i]
+	}
+	return ""
+}
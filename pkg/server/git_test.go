@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunFromGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	defer gock.Off()
+
+	repo := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=atest", "-c", "user.email=atest@example.com"}, args...)...)
+		cmd.Dir = repo
+		output, err := cmd.CombinedOutput()
+		assert.Nil(t, err, string(output))
+	}
+	git("init", "-q")
+	assert.Nil(t, os.MkdirAll(filepath.Join(repo, "suites"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(repo, "suites", "simple.yaml"), []byte(simpleSuite), 0644))
+	git("add", ".")
+	git("commit", "-q", "-m", "add the suite")
+	git("tag", "v0.1.0")
+	git("checkout", "-q", "-b", "feature")
+	assert.Nil(t, os.WriteFile(filepath.Join(repo, "suites", "simple.yaml"), []byte(`name: feature
+api: http://foo
+items:
+  - name: feature
+    request:
+      api: /feature
+`), 0644))
+	git("commit", "-q", "-a", "-m", "change the suite")
+	secret := filepath.Join(t.TempDir(), "secret.yaml")
+	assert.Nil(t, os.WriteFile(secret, []byte(simpleSuite), 0644))
+	assert.Nil(t, os.Symlink(secret, filepath.Join(repo, "suites", "link.yaml")))
+	git("add", ".")
+	git("commit", "-q", "-m", "add the link")
+
+	oldCacheDir, oldProtocols := gitCacheDir, gitProtocols
+	gitCacheDir = t.TempDir()
+	defer func() {
+		gitCacheDir, gitProtocols = oldCacheDir, oldProtocols
+	}()
+
+	// the local repository is not allowed by default
+	server := NewRemoteServer(false)
+	_, err := server.Run(context.TODO(), &TestTask{
+		Kind: "git",
+		Data: `{"repo": "` + repo + `", "ref": "feature", "path": "suites/simple.yaml"}`,
+	})
+	assert.ErrorContains(t, err, "failed to run git fetch")
+
+	gitProtocols = append(gitProtocols, "file")
+	gock.New(urlFoo).Get("/feature").Reply(http.StatusOK).JSON(`{}`)
+	reply, err := server.Run(context.TODO(), &TestTask{
+		Kind: "git",
+		Data: `{"repo": "` + repo + `", "ref": "feature", "path": "suites/simple.yaml"}`,
+	})
+	if assert.Nil(t, err) {
+		assert.Empty(t, reply.Error)
+	}
+	assert.True(t, gock.IsDone())
+
+	// the cached repository is reused
+	gock.New(urlFoo).Get("/").Times(2).Reply(http.StatusOK).JSON(`{}`)
+	reply, err = server.Run(context.TODO(), &TestTask{
+		Kind: "git",
+		Data: "repo: " + repo + "\nref: v0.1.0\npath: ../../suites/simple.yaml",
+	})
+	if assert.Nil(t, err) {
+		assert.Empty(t, reply.Error)
+	}
+	assert.True(t, gock.IsDone())
+
+	_, err = server.Run(context.TODO(), &TestTask{
+		Kind: "git",
+		Data: `{"repo": "` + repo + `", "ref": "fake", "path": "suites/simple.yaml"}`,
+	})
+	assert.Contains(t, err.Error(), "failed to run git fetch")
+
+	pwned := filepath.Join(t.TempDir(), "pwned")
+	_, err = server.Run(context.TODO(), &TestTask{
+		Kind: "git",
+		Data: `{"repo": "` + repo + `", "ref": "--upload-pack=touch ` + pwned + `; git-upload-pack", "path": "suites/simple.yaml"}`,
+	})
+	assert.EqualError(t, err, "the repo and ref of the git task should not start with '-'")
+	assert.NoFileExists(t, pwned)
+
+	_, err = server.Run(context.TODO(), &TestTask{
+		Kind: "git",
+		Data: `{"repo": "` + repo + `", "ref": "feature", "path": "suites/link.yaml"}`,
+	})
+	assert.EqualError(t, err, "the path suites/link.yaml is out of the repository")
+
+	_, err = server.Run(context.TODO(), &TestTask{
+		Kind: "git",
+		Data: `{"repo": "` + repo + `", "path": "fake.yaml"}`,
+	})
+	assert.NotNil(t, err)
+
+	_, err = server.Run(context.TODO(), &TestTask{Kind: "git", Data: `{"repo": "` + repo + `"}`})
+	assert.EqualError(t, err, "the repo and path of the git task are required")
+}
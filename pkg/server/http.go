@@ -0,0 +1,44 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// NewHTTPHandler creates the HTTP handler of the runner server, it serves the routes below:
+//
+//	POST /runs {repo, ref, path}: runs the suite at the Git ref, it's the same as the git task
+func NewHTTPHandler(runner RunnerServer) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/runs", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		source := gitSource{}
+		if err := json.NewDecoder(req.Body).Decode(&source); err != nil {
+			writeReply(w, http.StatusBadRequest, &HelloReply{Error: err.Error()})
+			return
+		}
+		data, _ := json.Marshal(source)
+
+		reply, err := runner.Run(req.Context(), &TestTask{Kind: "git", Data: string(data)})
+		if err != nil {
+			writeReply(w, http.StatusInternalServerError, &HelloReply{Error: err.Error()})
+			return
+		}
+		status := http.StatusOK
+		if reply.Error != "" {
+			status = http.StatusUnprocessableEntity
+		}
+		writeReply(w, status, reply)
+	})
+	return mux
+}
+
+func writeReply(w http.ResponseWriter, status int, reply *HelloReply) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(reply)
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordServer struct {
+	UnimplementedRunnerServer
+	task  *TestTask
+	reply *HelloReply
+	err   error
+}
+
+func (s *recordServer) Run(ctx context.Context, task *TestTask) (*HelloReply, error) {
+	s.task = task
+	return s.reply, s.err
+}
+
+func TestHTTPHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		runner     *recordServer
+		expectCode int
+		expectBody string
+		expectData string
+	}{{
+		name:       "run the suite at the ref",
+		method:     http.MethodPost,
+		body:       `{"repo": "https://foo.com/bar.git", "ref": "feature", "path": "suites/simple.yaml"}`,
+		runner:     &recordServer{reply: &HelloReply{Message: "ok"}},
+		expectCode: http.StatusOK,
+		expectBody: `{"message":"ok"}`,
+		expectData: `{"repo":"https://foo.com/bar.git","ref":"feature","path":"suites/simple.yaml"}`,
+	}, {
+		name:       "the test case failed",
+		method:     http.MethodPost,
+		body:       `{"repo": "https://foo.com/bar.git", "path": "suites/simple.yaml"}`,
+		runner:     &recordServer{reply: &HelloReply{Error: "failed"}},
+		expectCode: http.StatusUnprocessableEntity,
+		expectBody: `{"error":"failed"}`,
+		expectData: `{"repo":"https://foo.com/bar.git","ref":"","path":"suites/simple.yaml"}`,
+	}, {
+		name:       "failed to run",
+		method:     http.MethodPost,
+		body:       `{"repo": "https://foo.com/bar.git"}`,
+		runner:     &recordServer{err: errors.New("the repo and path of the git task are required")},
+		expectCode: http.StatusInternalServerError,
+		expectBody: `{"error":"the repo and path of the git task are required"}`,
+		expectData: `{"repo":"https://foo.com/bar.git","ref":"","path":""}`,
+	}, {
+		name:       "invalid body",
+		method:     http.MethodPost,
+		body:       `repo`,
+		runner:     &recordServer{},
+		expectCode: http.StatusBadRequest,
+	}, {
+		name:       "method not allowed",
+		method:     http.MethodGet,
+		runner:     &recordServer{},
+		expectCode: http.StatusMethodNotAllowed,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/runs", strings.NewReader(tt.body))
+			resp := httptest.NewRecorder()
+			NewHTTPHandler(tt.runner).ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectCode, resp.Code)
+			if tt.expectBody != "" {
+				assert.JSONEq(t, tt.expectBody, resp.Body.String())
+			}
+			if tt.expectData == "" {
+				assert.Nil(t, tt.runner.task)
+			} else if assert.NotNil(t, tt.runner.task) {
+				assert.Equal(t, "git", tt.runner.task.Kind)
+				assert.JSONEq(t, tt.expectData, tt.runner.task.Data)
+			}
+		})
+	}
+}
@@ -17,11 +17,13 @@ import (
 
 type server struct {
 	UnimplementedRunnerServer
+	allowExec bool
 }
 
-// NewRemoteServer creates a remote server instance
-func NewRemoteServer() RunnerServer {
-	return &server{}
+// NewRemoteServer creates a remote server instance, the test cases which run the local commands
+// are refused unless allowExec is true
+func NewRemoteServer(allowExec bool) RunnerServer {
+	return &server{allowExec: allowExec}
 }
 
 func withDefaultValue(old, defVal any) any {
@@ -59,6 +61,17 @@ func (s *server) Run(ctx context.Context, task *TestTask) (reply *HelloReply, er
 			err = fmt.Errorf("no test suite found")
 			return
 		}
+	case "git":
+		var data []byte
+		if data, err = loadSuiteFromGit(ctx, task.Data); err != nil {
+			return
+		}
+		if suite, err = testing.ParseFromData(data); err != nil {
+			return
+		} else if suite == nil || suite.Items == nil {
+			err = fmt.Errorf("no test suite found")
+			return
+		}
 	case "testcase":
 		var testCase *testing.TestCase
 		if testCase, err = testing.ParseTestCaseFromData([]byte(task.Data)); err != nil {
@@ -96,9 +109,44 @@ func (s *server) Run(ctx context.Context, task *TestTask) (reply *HelloReply, er
 		return
 	}
 
+	if !s.allowExec {
+		if features := runner.SuiteExecFeatures(suite); len(features) > 0 {
+			err = fmt.Errorf("suite: %s, the %s is not allowed, start the server with --allow-exec to enable it",
+				suite.Name, strings.Join(features, ", "))
+			return
+		}
+		for _, testCase := range suite.Items {
+			// the signer of the suite is inherited
+			testCase.InheritSuite(suite)
+			if features := runner.ExecFeatures(&testCase); len(features) > 0 {
+				err = fmt.Errorf("case: %s, the %s is not allowed, start the server with --allow-exec to enable it",
+					testCase.Name, strings.Join(features, ", "))
+				return
+			}
+		}
+	}
+
 	fmt.Printf("prepare to run: %s, with level: %s\n", suite.Name, task.Level)
 	fmt.Printf("task kind: %s, %d to run\n", task.Kind, len(suite.Items))
 	dataContext := map[string]interface{}{}
+	if suite.Param != nil {
+		dataContext["param"] = suite.Param
+	}
+
+	reply = &HelloReply{}
+
+	// the environment of the suite is set up like the run command
+	var teardown func() error
+	if teardown, err = runner.SetupSuite(ctx, suite, nil, dataContext); err != nil {
+		reply.Error = err.Error()
+		err = nil
+		return
+	}
+	defer func() {
+		if tearErr := teardown(); tearErr != nil && reply.Error == "" {
+			reply.Error = tearErr.Error()
+		}
+	}()
 
 	var result string
 	if result, err = render.Render("base api", suite.API, dataContext); err == nil {
@@ -111,17 +159,16 @@ func (s *server) Run(ctx context.Context, task *TestTask) (reply *HelloReply, er
 	}
 
 	buf := new(bytes.Buffer)
-	reply = &HelloReply{}
 
 	for _, testCase := range suite.Items {
-		simpleRunner := runner.NewSimpleTestCaseRunner()
+		simpleRunner := runner.GetTestCaseRunner(&testCase)
 		simpleRunner.WithOutputWriter(buf)
 		simpleRunner.WithWriteLevel(task.Level)
 
 		// reuse the API prefix
-		if strings.HasPrefix(testCase.Request.API, "/") {
-			testCase.Request.API = fmt.Sprintf("%s%s", suite.API, testCase.Request.API)
-		}
+		testCase.Request.API = runner.JoinAPI(suite.API, testCase.Request.API)
+
+		testCase.InheritSuite(suite)
 
 		if output, testErr := simpleRunner.RunTestCase(&testCase, dataContext, ctx); testErr == nil {
 			dataContext[testCase.Name] = output
@@ -2,7 +2,14 @@ package server
 
 import (
 	"context"
+	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 
 	_ "embed"
@@ -17,7 +24,7 @@ const (
 )
 
 func TestRemoteServer(t *testing.T) {
-	server := NewRemoteServer()
+	server := NewRemoteServer(false)
 	_, err := server.Run(context.TODO(), &TestTask{
 		Kind: "fake",
 	})
@@ -63,6 +70,202 @@ func TestRemoteServer(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestRemoteServerExec(t *testing.T) {
+	if _, err := exec.LookPath("touch"); err != nil {
+		t.Skip("touch is not installed")
+	}
+	pwned := filepath.Join(t.TempDir(), "pwned")
+	execCase := `name: exec
+request:
+  exec:
+    command: touch
+    args: [` + pwned + `]
+expect:
+  verify:
+    - data.exitCode == 0`
+
+	_, err := NewRemoteServer(false).Run(context.TODO(), &TestTask{Kind: "testcase", Data: execCase})
+	assert.EqualError(t, err, "case: exec, the exec request is not allowed, start the server with --allow-exec to enable it")
+	assert.NoFileExists(t, pwned)
+
+	_, err = NewRemoteServer(false).Run(context.TODO(), &TestTask{Kind: "testcase", Data: `name: verifier
+request:
+  api: http://foo
+expect:
+  verifiers:
+    - type: exec
+      command: touch
+      args: [` + pwned + `]`})
+	assert.EqualError(t, err, "case: verifier, the exec verifier is not allowed, start the server with --allow-exec to enable it")
+	assert.NoFileExists(t, pwned)
+
+	// the signer is inherited from the suite
+	_, err = NewRemoteServer(false).Run(context.TODO(), &TestTask{Kind: "testcaseInSuite", CaseName: "signer", Data: `name: suite
+signing:
+  type: exec
+  command: touch
+  args: [` + pwned + `]
+items:
+- name: signer
+  request:
+    api: http://foo`})
+	assert.EqualError(t, err, "case: signer, the exec signer is not allowed, start the server with --allow-exec to enable it")
+	assert.NoFileExists(t, pwned)
+
+	_, err = NewRemoteServer(false).Run(context.TODO(), &TestTask{Kind: "testcase", Data: `name: sftp
+request:
+  api: sftp://-oProxyCommand=touch/upload
+  ftp:
+    action: list`})
+	assert.EqualError(t, err, "case: sftp, the sftp request is not allowed, start the server with --allow-exec to enable it")
+
+	_, err = NewRemoteServer(false).Run(context.TODO(), &TestTask{Kind: "testcase", Data: `name: files
+prepare:
+  files:
+    - url: ftp://localhost/upload/report.csv
+      bodyFromFile: /etc/passwd
+request:
+  api: http://foo`})
+	assert.EqualError(t, err, "case: files, the file step is not allowed, start the server with --allow-exec to enable it")
+
+	var reply *HelloReply
+	reply, err = NewRemoteServer(true).Run(context.TODO(), &TestTask{Kind: "testcase", Data: execCase})
+	if assert.Nil(t, err) {
+		assert.Empty(t, reply.Error)
+	}
+	assert.FileExists(t, pwned)
+}
+
+func TestRemoteServerSSHTunnel(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake ssh is a shell script")
+	}
+	// the fake ssh records the calls
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "ssh"), []byte("#!/bin/sh\necho \"$@\" >> "+calls+"\n"), 0755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	defer gock.Clean()
+	gock.New("http://localhost:18080").Get("/health").Reply(http.StatusOK).JSON(`{}`)
+	suite := `name: tunnel
+api: http://localhost:18080
+sshTunnel:
+  host: bastion
+  localPort: 18080
+  remoteAddr: 10.0.0.1:80
+items:
+- name: health
+  request:
+    api: /health`
+
+	_, err := NewRemoteServer(false).Run(context.TODO(), &TestTask{Kind: "suite", Data: suite})
+	assert.EqualError(t, err, "suite: tunnel, the SSH tunnel is not allowed, start the server with --allow-exec to enable it")
+	assert.NoFileExists(t, calls)
+
+	reply, err := NewRemoteServer(true).Run(context.TODO(), &TestTask{Kind: "suite", Data: suite})
+	if assert.Nil(t, err) {
+		assert.Empty(t, reply.Error)
+	}
+	data, err := os.ReadFile(calls)
+	if assert.Nil(t, err) {
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		if assert.Equal(t, 2, len(lines)) {
+			assert.Contains(t, lines[0], "-L 18080:10.0.0.1:80")
+			assert.Contains(t, lines[1], "-O exit")
+		}
+	}
+}
+
+func TestRemoteServerWebhookListener(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	assert.Nil(t, ln.Close())
+
+	suite := fmt.Sprintf(`name: webhook
+items:
+- name: listen
+  prepare:
+    webhookListener:
+      port: %d
+  request:
+    webhook:
+      port: %d
+      timeout: 1ms`, port, port)
+	reply, err := NewRemoteServer(false).Run(context.TODO(), &TestTask{Kind: "suite", Data: suite})
+	if assert.Nil(t, err) {
+		assert.Equal(t, "case: listen, expect 1 requests in 1ms, received 0", reply.Error)
+	}
+
+	// the listener is closed after the run
+	_, err = net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	assert.NotNil(t, err)
+}
+
+func TestRemoteServerTunnel(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake agent is a shell script")
+	}
+	agent := filepath.Join(t.TempDir(), "ngrok")
+	assert.Nil(t, os.WriteFile(agent, []byte(`#!/bin/sh
+echo '{"lvl":"info","msg":"started tunnel","url":"https://abc.ngrok.app"}'
+sleep 30
+`), 0755))
+
+	defer gock.Clean()
+	gock.New("http://tunnel").Get("/ping").MatchHeader("X-Tunnel", "^https://abc.ngrok.app$").
+		Reply(http.StatusOK).JSON("{}")
+	suite := `name: tunnel
+tunnel:
+  provider: ngrok
+  port: 9090
+  command: ` + agent + `
+items:
+- name: ping
+  request:
+    api: http://tunnel/ping
+    header:
+      X-Tunnel: "{{.tunnel.url}}"`
+
+	_, err := NewRemoteServer(false).Run(context.TODO(), &TestTask{Kind: "suite", Data: suite})
+	assert.EqualError(t, err, "suite: tunnel, the tunnel agent is not allowed, start the server with --allow-exec to enable it")
+
+	reply, err := NewRemoteServer(true).Run(context.TODO(), &TestTask{Kind: "suite", Data: suite})
+	if assert.Nil(t, err) {
+		assert.Empty(t, reply.Error)
+	}
+}
+
+func TestRemoteServerIdentities(t *testing.T) {
+	defer gock.Off()
+	gock.New("http://identity").Post("/users").Reply(http.StatusCreated).JSON(`{"id": "1"}`)
+	gock.New("http://identity").Get("/me").MatchHeader("X-User", "^1$").Reply(http.StatusOK).JSON("{}")
+	deleted := gock.New("http://identity").Delete("/users/1").Reply(http.StatusNoContent)
+
+	reply, err := NewRemoteServer(false).Run(context.TODO(), &TestTask{Kind: "suite", Data: `name: identity
+identities:
+- name: alice
+  provider: http
+  username: alice
+  create:
+    api: http://identity/users
+  delete:
+    api: http://identity/users/{{.id}}
+items:
+- name: me
+  request:
+    api: http://identity/me
+    header:
+      X-User: "{{.identity.alice.id}}"`})
+	if assert.Nil(t, err) {
+		assert.Empty(t, reply.Error)
+	}
+	assert.True(t, deleted.Mock.Done())
+}
+
 func TestFindParentTestCases(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -1,6 +1,7 @@
 package testing_test
 
 import (
+	"encoding/json"
 	"testing"
 
 	atesting "github.com/linuxsuren/api-testing/pkg/testing"
@@ -13,3 +14,47 @@ func TestInScope(t *testing.T) {
 	assert.True(t, testCase.InScope([]string{"foo"}))
 	assert.False(t, testCase.InScope([]string{"bar"}))
 }
+
+func TestInheritSuite(t *testing.T) {
+	suite := &atesting.TestSuite{
+		Transport:       &atesting.Transport{},
+		Signing:         &atesting.Signing{},
+		JOSE:            &atesting.JOSE{},
+		SecurityHeaders: "basic",
+	}
+
+	testCase := &atesting.TestCase{}
+	testCase.InheritSuite(suite)
+	assert.Same(t, suite.Transport, testCase.Request.Transport)
+	assert.Same(t, suite.Signing, testCase.Request.Signing)
+	assert.Same(t, suite.JOSE, testCase.Expect.JOSE)
+	assert.Equal(t, "basic", testCase.Expect.SecurityHeaders)
+
+	// the ones of the test case are kept
+	transport := &atesting.Transport{}
+	testCase = &atesting.TestCase{
+		Request: atesting.Request{Transport: transport},
+		Expect:  atesting.Response{SecurityHeaders: "none"},
+	}
+	testCase.InheritSuite(suite)
+	assert.Same(t, transport, testCase.Request.Transport)
+	assert.Equal(t, "none", testCase.Expect.SecurityHeaders)
+}
+
+func TestTestCaseJSON(t *testing.T) {
+	testCase := &atesting.TestCase{
+		Name:    "foo",
+		Prepare: atesting.Prepare{Kubernetes: []string{"deploy.yaml"}},
+		Clean:   atesting.Clean{CleanPrepare: true},
+	}
+
+	data, err := json.Marshal(testCase)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"prepare":{"kubernetes":["deploy.yaml"]}`)
+	assert.Contains(t, string(data), `"clean":{"cleanPrepare":true}`)
+	assert.NotContains(t, string(data), `"group"`)
+
+	result := &atesting.TestCase{}
+	assert.Nil(t, json.Unmarshal(data, result))
+	assert.Equal(t, testCase, result)
+}
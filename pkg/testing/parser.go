@@ -2,12 +2,14 @@ package testing
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"net/url"
 	"os"
+	"regexp"
 	"strings"
 
 	"github.com/ghodss/yaml"
@@ -98,8 +100,11 @@ func (r *Request) Render(ctx interface{}) (err error) {
 		}
 	}
 
-	// template the body
+	// template the body, the surrounding whitespaces are part of the raw TCP or UDP data, and the uploaded file
 	if result, err = render.Render("body", r.Body, ctx); err == nil {
+		if r.TCP != nil || r.UDP != nil || r.FTP != nil {
+			result = keepSurroundingSpaces(r.Body, result)
+		}
 		r.Body = result
 	} else {
 		return
@@ -114,11 +119,271 @@ func (r *Request) Render(ctx interface{}) (err error) {
 		}
 	}
 
+	if r.GraphQL != nil {
+		if err = r.renderGraphQL(ctx); err != nil {
+			return
+		}
+	}
+
+	if r.JSONRPC != nil {
+		if err = r.renderJSONRPC(ctx); err != nil {
+			return
+		}
+	}
+
+	if err = r.applyBodyType(); err != nil {
+		return
+	}
+
+	// template the WebSocket messages
+	if r.WebSocket != nil {
+		for i, message := range r.WebSocket.Messages {
+			if r.WebSocket.Messages[i], err = render.Render("message", message, ctx); err != nil {
+				return
+			}
+		}
+	}
+
+	// template the messages of the gRPC streaming
+	if r.GRPC != nil {
+		for i, message := range r.GRPC.Messages {
+			if r.GRPC.Messages[i], err = render.Render("message", message, ctx); err != nil {
+				return
+			}
+		}
+	}
+
+	// template the MQTT topics
+	if r.MQTT != nil {
+		if r.MQTT.Publish, err = render.Render("publish", r.MQTT.Publish, ctx); err != nil {
+			return
+		}
+		if r.MQTT.Subscribe, err = render.Render("subscribe", r.MQTT.Subscribe, ctx); err != nil {
+			return
+		}
+	}
+
+	// template the AMQP routing
+	if r.AMQP != nil {
+		if r.AMQP.RoutingKey, err = render.Render("routing key", r.AMQP.RoutingKey, ctx); err != nil {
+			return
+		}
+		if r.AMQP.Queue, err = render.Render("queue", r.AMQP.Queue, ctx); err != nil {
+			return
+		}
+	}
+
+	// template the NATS subjects
+	if r.NATS != nil {
+		for name, field := range map[string]*string{
+			"publish":   &r.NATS.Publish,
+			"subscribe": &r.NATS.Subscribe,
+			"request":   &r.NATS.Request,
+		} {
+			if *field, err = render.Render(name, *field, ctx); err != nil {
+				return
+			}
+		}
+	}
+
+	// template the command
+	if r.Exec != nil {
+		if r.Exec.Command, err = render.Render("command", r.Exec.Command, ctx); err != nil {
+			return
+		}
+		for i, arg := range r.Exec.Args {
+			if r.Exec.Args[i], err = render.Render("arg", arg, ctx); err != nil {
+				return
+			}
+		}
+		if r.Exec.Dir, err = render.Render("dir", r.Exec.Dir, ctx); err != nil {
+			return
+		}
+	}
+
+	// template the OIDC credentials
+	if r.OIDC != nil {
+		r.OIDC.GrantType = emptyThenDefault(r.OIDC.GrantType, "client_credentials")
+		for name, field := range map[string]*string{
+			"client id":     &r.OIDC.ClientID,
+			"client secret": &r.OIDC.ClientSecret,
+			"username":      &r.OIDC.Username,
+			"password":      &r.OIDC.Password,
+		} {
+			if *field, err = render.Render(name, *field, ctx); err != nil {
+				return
+			}
+		}
+	}
+
+	// template the FTP credentials
+	if r.FTP != nil {
+		if r.FTP.Username, err = render.Render("username", r.FTP.Username, ctx); err != nil {
+			return
+		}
+		if r.FTP.Password, err = render.Render("password", r.FTP.Password, ctx); err != nil {
+			return
+		}
+	}
+
 	// setting default values
 	r.Method = emptyThenDefault(r.Method, http.MethodGet)
 	return
 }
 
+// the body types of the request
+const (
+	BodyTypeJSON   = "json"
+	BodyTypeXML    = "xml"
+	BodyTypeSOAP   = "soap"
+	BodyTypeSOAP12 = "soap12"
+)
+
+var soapEnvelopes = map[string]string{
+	BodyTypeSOAP:   "http://schemas.xmlsoap.org/soap/envelope/",
+	BodyTypeSOAP12: "http://www.w3.org/2003/05/soap-envelope",
+}
+
+var soapEnvelopeReg = regexp.MustCompile(`<(\w+:)?Envelope\b`)
+
+var bodyTypeContentTypes = map[string]string{
+	BodyTypeJSON:   "application/json",
+	BodyTypeXML:    "application/xml",
+	BodyTypeSOAP:   "text/xml; charset=utf-8",
+	BodyTypeSOAP12: "application/soap+xml; charset=utf-8",
+}
+
+// IsXML indicates if the body type is XML or SOAP
+func (r *Request) IsXML() bool {
+	return r.BodyType == BodyTypeXML || r.BodyType == BodyTypeSOAP || r.BodyType == BodyTypeSOAP12
+}
+
+// applyBodyType sets the content type of the body type, and wraps the body with
+// the SOAP envelope if it's not an envelope
+func (r *Request) applyBodyType() (err error) {
+	if r.BodyType == "" {
+		return
+	}
+
+	contentType, ok := bodyTypeContentTypes[r.BodyType]
+	if !ok {
+		err = fmt.Errorf("not supported body type: '%s'", r.BodyType)
+		return
+	}
+	r.Header = util.MakeSureNotNil(r.Header)
+	if _, ok := r.Header[util.ContentType]; !ok {
+		r.Header[util.ContentType] = contentType
+	}
+
+	if namespace, ok := soapEnvelopes[r.BodyType]; ok {
+		if !soapEnvelopeReg.MatchString(r.Body) {
+			r.Body = fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
+<soap:Envelope xmlns:soap="%s">
+  <soap:Body>
+    %s
+  </soap:Body>
+</soap:Envelope>`, namespace, strings.TrimSpace(r.Body))
+		}
+		r.Method = emptyThenDefault(r.Method, http.MethodPost)
+	}
+	return
+}
+
+// renderGraphQL templates the GraphQL operation, then serializes it into the body of a POST request
+func (r *Request) renderGraphQL(ctx interface{}) (err error) {
+	graphQL := r.GraphQL
+	if graphQL.Query, err = render.Render("graphql query", graphQL.Query, ctx); err != nil {
+		return
+	}
+
+	payload := map[string]interface{}{
+		"query": graphQL.Query,
+	}
+	if graphQL.OperationName != "" {
+		payload["operationName"] = graphQL.OperationName
+	}
+	if len(graphQL.Variables) > 0 {
+		if payload["variables"], err = renderValues(graphQL.Variables, ctx); err != nil {
+			return
+		}
+	}
+
+	var data []byte
+	if data, err = json.Marshal(payload); err != nil {
+		return
+	}
+
+	r.Body = string(data)
+	r.Method = emptyThenDefault(r.Method, http.MethodPost)
+	r.Header = util.MakeSureNotNil(r.Header)
+	if _, ok := r.Header[util.ContentType]; !ok {
+		r.Header[util.ContentType] = "application/json"
+	}
+	return
+}
+
+// renderJSONRPC templates the params, then serializes the JSON-RPC envelope into the body of a POST request
+func (r *Request) renderJSONRPC(ctx interface{}) (err error) {
+	jsonRPC := r.JSONRPC
+	if jsonRPC.Method, err = render.Render("jsonrpc method", jsonRPC.Method, ctx); err != nil {
+		return
+	}
+	if jsonRPC.ID == nil {
+		jsonRPC.ID = 1
+	}
+
+	payload := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  jsonRPC.Method,
+		"id":      jsonRPC.ID,
+	}
+	if jsonRPC.Params != nil {
+		if payload["params"], err = renderValues(jsonRPC.Params, ctx); err != nil {
+			return
+		}
+	}
+
+	var data []byte
+	if data, err = json.Marshal(payload); err != nil {
+		return
+	}
+
+	r.Body = string(data)
+	r.Method = emptyThenDefault(r.Method, http.MethodPost)
+	r.Header = util.MakeSureNotNil(r.Header)
+	if _, ok := r.Header[util.ContentType]; !ok {
+		r.Header[util.ContentType] = "application/json"
+	}
+	return
+}
+
+// renderValues templates the string values of the nested maps and slices
+func renderValues(object interface{}, ctx interface{}) (result interface{}, err error) {
+	switch val := object.(type) {
+	case string:
+		result, err = render.Render("value", val, ctx)
+	case map[string]interface{}:
+		values := make(map[string]interface{}, len(val))
+		for key, item := range val {
+			if values[key], err = renderValues(item, ctx); err != nil {
+				return
+			}
+		}
+		result = values
+	case []interface{}:
+		values := make([]interface{}, len(val))
+		for i, item := range val {
+			if values[i], err = renderValues(item, ctx); err != nil {
+				return
+			}
+		}
+		result = values
+	default:
+		result = object
+	}
+	return
+}
+
 // GetBody returns the request body
 func (r *Request) GetBody() (reader io.Reader, err error) {
 	if len(r.Form) > 0 {
@@ -156,6 +421,17 @@ func (r *Response) Render(ctx interface{}) (err error) {
 	return
 }
 
+// LoadSchema reads the JSON schema from the file if there's no inline one
+func (r *Response) LoadSchema() (err error) {
+	if r.Schema == "" && r.SchemaFromFile != "" {
+		var data []byte
+		if data, err = os.ReadFile(r.SchemaFromFile); err == nil {
+			r.Schema = string(data)
+		}
+	}
+	return
+}
+
 func zeroThenDefault(val, defVal int) int {
 	if val == 0 {
 		val = defVal
@@ -169,3 +445,13 @@ func emptyThenDefault(val, defVal string) string {
 	}
 	return val
 }
+
+// keepSurroundingSpaces puts the leading and trailing whitespaces of the text around the rendered result
+func keepSurroundingSpaces(text, result string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	start := strings.Index(text, trimmed)
+	return text[:start] + result + text[start+len(trimmed):]
+}
@@ -3,6 +3,8 @@ package testing
 import (
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
 
 	_ "embed"
@@ -33,6 +35,22 @@ func TestParse(t *testing.T) {
 
 	_, err = Parse("testdata/invalid-testcase.yaml")
 	assert.NotNil(t, err)
+
+	// the api is required unless it's an exec step
+	suite, err = Parse("testdata/exec.yaml")
+	if assert.Nil(t, err) {
+		assert.Equal(t, &ExecRequest{Command: "echo", Args: []string{"token"}}, suite.Items[0].Request.Exec)
+	}
+	_, err = Parse("testdata/no-api.yaml")
+	assert.NotNil(t, err)
+
+	suite, err = Parse("testdata/jsonpath.yaml")
+	if assert.Nil(t, err) {
+		assert.Equal(t, map[string]interface{}{
+			"$.items[0].id":   float64(42),
+			"$.items[*].name": []interface{}{"rick", "morty"},
+		}, suite.Items[0].Expect.JSONPath)
+	}
 }
 
 func TestDuplicatedNames(t *testing.T) {
@@ -142,6 +160,155 @@ func TestRequestRender(t *testing.T) {
 			assert.Equal(t, "linuxsuren", req.Header["key"])
 		},
 		hasErr: false,
+	}, {
+		name: "graphql render",
+		request: &Request{
+			GraphQL: &GraphQLRequest{
+				Query:         "query user($name: String!) { user(name: $name) { id } }",
+				OperationName: "user",
+				Variables: map[string]interface{}{
+					"name":  "{{.Name}}",
+					"ids":   []interface{}{1, "{{.Name}}"},
+					"limit": 1,
+				},
+			},
+		},
+		ctx: TestCase{Name: "linuxsuren"},
+		verify: func(t *testing.T, req *Request) {
+			assert.Equal(t, http.MethodPost, req.Method)
+			assert.Equal(t, "application/json", req.Header["Content-Type"])
+			assert.JSONEq(t, `{"query":"query user($name: String!) { user(name: $name) { id } }",
+"operationName":"user","variables":{"name":"linuxsuren","ids":[1,"linuxsuren"],"limit":1}}`, req.Body)
+		},
+	}, {
+		name: "graphql with a custom method",
+		request: &Request{
+			Method:  http.MethodGet,
+			Header:  map[string]string{"Content-Type": "application/graphql+json"},
+			GraphQL: &GraphQLRequest{Query: "{ users { id } }"},
+		},
+		verify: func(t *testing.T, req *Request) {
+			assert.Equal(t, http.MethodGet, req.Method)
+			assert.Equal(t, "application/graphql+json", req.Header["Content-Type"])
+			assert.Equal(t, `{"query":"{ users { id } }"}`, req.Body)
+		},
+	}, {
+		name: "jsonrpc render",
+		request: &Request{
+			JSONRPC: &JSONRPCRequest{
+				Method: "user.get",
+				Params: map[string]interface{}{"name": "{{.Name}}", "limit": 1},
+			},
+		},
+		ctx: TestCase{Name: "linuxsuren"},
+		verify: func(t *testing.T, req *Request) {
+			assert.Equal(t, http.MethodPost, req.Method)
+			assert.Equal(t, "application/json", req.Header["Content-Type"])
+			assert.JSONEq(t, `{"jsonrpc":"2.0","method":"user.get","params":{"name":"linuxsuren","limit":1},"id":1}`, req.Body)
+		},
+	}, {
+		name: "jsonrpc with the positional params and a string id",
+		request: &Request{
+			JSONRPC: &JSONRPCRequest{
+				Method: "subtract",
+				Params: []interface{}{42, 23},
+				ID:     "abc",
+			},
+		},
+		verify: func(t *testing.T, req *Request) {
+			assert.JSONEq(t, `{"jsonrpc":"2.0","method":"subtract","params":[42,23],"id":"abc"}`, req.Body)
+		},
+	}, {
+		name: "oidc credentials render",
+		request: &Request{
+			OIDC: &OIDCRequest{ClientID: "api-testing", ClientSecret: "{{.Name}}"},
+		},
+		ctx: TestCase{Name: "secret"},
+		verify: func(t *testing.T, req *Request) {
+			assert.Equal(t, "client_credentials", req.OIDC.GrantType)
+			assert.Equal(t, "secret", req.OIDC.ClientSecret)
+		},
+	}, {
+		name: "ftp credentials render",
+		request: &Request{
+			Body: "id,name\n1,{{.Name}}\n",
+			FTP:  &FTPRequest{Action: "upload", Username: "{{.Name}}", Password: "{{.Name}}"},
+		},
+		ctx: TestCase{Name: "linuxsuren"},
+		verify: func(t *testing.T, req *Request) {
+			assert.Equal(t, "linuxsuren", req.FTP.Username)
+			assert.Equal(t, "linuxsuren", req.FTP.Password)
+			assert.Equal(t, "id,name\n1,linuxsuren\n", req.Body)
+		},
+	}, {
+		name: "tcp body with the line terminator",
+		request: &Request{
+			Body: "{{.Name}}\r\n",
+			TCP:  &TCPRequest{},
+		},
+		ctx: TestCase{Name: "PING"},
+		verify: func(t *testing.T, req *Request) {
+			assert.Equal(t, "PING\r\n", req.Body)
+		},
+	}, {
+		name: "soap body",
+		request: &Request{
+			BodyType: BodyTypeSOAP,
+			Body:     "<m:GetPrice><m:Item>{{.Name}}</m:Item></m:GetPrice>",
+		},
+		ctx: TestCase{Name: "apple"},
+		verify: func(t *testing.T, req *Request) {
+			assert.Equal(t, http.MethodPost, req.Method)
+			assert.Equal(t, "text/xml; charset=utf-8", req.Header["Content-Type"])
+			assert.Contains(t, req.Body, `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">`)
+			assert.Contains(t, req.Body, "<m:GetPrice><m:Item>apple</m:Item></m:GetPrice>")
+			assert.True(t, req.IsXML())
+		},
+	}, {
+		name: "soap 1.2 body with the envelope",
+		request: &Request{
+			BodyType: BodyTypeSOAP12,
+			Body:     `<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"></env:Envelope>`,
+		},
+		verify: func(t *testing.T, req *Request) {
+			assert.Equal(t, "application/soap+xml; charset=utf-8", req.Header["Content-Type"])
+			assert.Equal(t, `<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"></env:Envelope>`, req.Body)
+		},
+	}, {
+		name: "xml body",
+		request: &Request{
+			BodyType: BodyTypeXML,
+			Header:   map[string]string{"Content-Type": "text/xml"},
+			Body:     "<a/>",
+		},
+		verify: func(t *testing.T, req *Request) {
+			assert.Equal(t, http.MethodGet, req.Method)
+			assert.Equal(t, "text/xml", req.Header["Content-Type"])
+			assert.Equal(t, "<a/>", req.Body)
+			assert.True(t, req.IsXML())
+		},
+	}, {
+		name:    "not supported body type",
+		request: &Request{BodyType: "fake"},
+		hasErr:  true,
+	}, {
+		name: "failed with graphql variables render",
+		request: &Request{
+			GraphQL: &GraphQLRequest{
+				Query:     "{ users { id } }",
+				Variables: map[string]interface{}{"name": "{{.name}"},
+			},
+		},
+		hasErr: true,
+	}, {
+		name: "failed with jsonrpc params render",
+		request: &Request{
+			JSONRPC: &JSONRPCRequest{
+				Method: "user.get",
+				Params: []interface{}{"{{.name}"},
+			},
+		},
+		hasErr: true,
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -178,6 +345,25 @@ func TestResponseRender(t *testing.T) {
 	}
 }
 
+func TestLoadSchema(t *testing.T) {
+	schemaFile := filepath.Join(t.TempDir(), "projects.json")
+	assert.Nil(t, os.WriteFile(schemaFile, []byte(`{"type": "array"}`), 0644))
+
+	response := &Response{SchemaFromFile: schemaFile}
+	if assert.Nil(t, response.LoadSchema()) {
+		assert.Equal(t, `{"type": "array"}`, response.Schema)
+	}
+
+	// the inline schema has the higher priority
+	response = &Response{Schema: `{"type": "object"}`, SchemaFromFile: schemaFile}
+	if assert.Nil(t, response.LoadSchema()) {
+		assert.Equal(t, `{"type": "object"}`, response.Schema)
+	}
+
+	assert.NotNil(t, (&Response{SchemaFromFile: filepath.Join(t.TempDir(), "fake.json")}).LoadSchema())
+	assert.Nil(t, (&Response{}).LoadSchema())
+}
+
 func TestEmptyThenDefault(t *testing.T) {
 	tests := []struct {
 		name   string